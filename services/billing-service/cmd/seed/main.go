@@ -8,6 +8,7 @@ import (
 	"github.com/b2b-platform/billing-service/models"
 	"github.com/b2b-platform/billing-service/repository"
 	"github.com/b2b-platform/shared/database"
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 )
 
@@ -31,7 +32,7 @@ func main() {
 		Name:        "Starter Plan",
 		Code:        "starter",
 		Description: "Starter plan for small businesses",
-		Price:       99.00,
+		Price:       money.MustParse("99.00"),
 		Currency:    "USD",
 		BillingCycle: "monthly",
 		IsActive:    true,
@@ -85,7 +86,7 @@ func main() {
 		OrderID:         directOrderID,
 		PaymentIntentID: "pi_direct_001",
 		Provider:        "mock",
-		Amount:          1500.00,
+		Amount:          money.MustParse("1500.00"),
 		Currency:        "USD",
 		Status:          "succeeded",
 		PaymentMode:     "DIRECT",
@@ -106,7 +107,7 @@ func main() {
 		OrderID:         escrowOrderID,
 		PaymentIntentID: "pi_escrow_001",
 		Provider:        "mock",
-		Amount:          2500.00,
+		Amount:          money.MustParse("2500.00"),
 		Currency:        "USD",
 		Status:          "succeeded",
 		PaymentMode:     "ESCROW",
@@ -125,7 +126,7 @@ func main() {
 			PaymentID:       escrowPayment.ID,
 			OrderID:         escrowOrderID,
 			SupplierID:      supplierID,
-			Amount:          2500.00,
+			Amount:          money.MustParse("2500.00"),
 			Currency:        "USD",
 			Status:          "held",
 			AutoReleaseDays: 30,
@@ -44,6 +44,7 @@ func main() {
 	settlementRepo := repository.NewSettlementRepository(db)
 	refundRepo := repository.NewRefundRepository(db)
 	payoutRepo := repository.NewPayoutRepository(db)
+	fxRepo := repository.NewFXRepository(db)
 
 	// Payment provider (mock for local dev)
 	paymentProvider := service.NewMockPaymentProvider()
@@ -51,9 +52,11 @@ func main() {
 	billingService := service.NewBillingService(planRepo, subscriptionRepo, eventBus)
 	paymentService := service.NewPaymentService(paymentRepo, escrowRepo, settlementRepo, refundRepo, payoutRepo, paymentProvider, eventBus)
 	payoutService := service.NewPayoutService(payoutRepo)
+	fxService := service.NewFXService(fxRepo)
 
 	billingHandler := handlers.NewBillingHandler(billingService)
 	paymentHandler := handlers.NewPaymentHandler(paymentService, payoutService)
+	fxHandler := handlers.NewFXHandler(fxService)
 
 	r := gin.Default()
 
@@ -98,6 +101,13 @@ func main() {
 		api.GET("/plans/:id", billingHandler.GetPlan)
 		api.POST("/plans", billingHandler.CreatePlan)
 
+		// FX rate endpoints (rates are shared by all tenants)
+		api.GET("/fx-rates", fxHandler.ListRates)
+		api.POST("/fx-rates", fxHandler.SetRate)
+		api.POST("/fx-rates/import", fxHandler.ImportRates)
+		api.GET("/fx-rates/rate", fxHandler.GetRate)
+		api.GET("/fx-rates/convert", fxHandler.Convert)
+
 		// Subscription endpoints
 		api.Use(auth.TenantMiddleware())
 		api.GET("/subscriptions", billingHandler.GetSubscription)
@@ -105,6 +115,10 @@ func main() {
 		api.DELETE("/subscriptions/:id", billingHandler.CancelSubscription)
 		api.GET("/entitlements/check", billingHandler.CheckEntitlement)
 
+		// Tenant reporting currency
+		api.GET("/base-currency", fxHandler.GetBaseCurrency)
+		api.PUT("/base-currency", fxHandler.SetBaseCurrency)
+
 		// Payment endpoints
 		api.POST("/billing/v1/payments/intent", paymentHandler.CreatePaymentIntent)
 		api.GET("/billing/v1/payments/:id", paymentHandler.GetPayment)
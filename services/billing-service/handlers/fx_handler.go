@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/b2b-platform/billing-service/models"
+	"github.com/b2b-platform/billing-service/service"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/b2b-platform/shared/money"
+	"github.com/gin-gonic/gin"
+)
+
+type FXHandler struct {
+	service *service.FXService
+}
+
+func NewFXHandler(service *service.FXService) *FXHandler {
+	return &FXHandler{service: service}
+}
+
+// ListRates returns the latest rate of each pair. Query params: base, date (YYYY-MM-DD).
+func (h *FXHandler) ListRates(c *gin.Context) {
+	on, ok := rateDateParam(c)
+	if !ok {
+		return
+	}
+
+	rates, err := h.service.ListRates(c.Query("base"), on)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// SetRate records a rate. Rates are shared by every tenant, so only platform
+// admins may set them.
+func (h *FXHandler) SetRate(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	var rate models.FXRate
+	if err := c.ShouldBindJSON(&rate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.SetRate(&rate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+// ImportRates loads a CSV of daily rates sent either as the multipart "file"
+// field or as the raw request body. Admin only.
+func (h *FXHandler) ImportRates(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	result, err := h.service.ImportCSV(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetRate returns the rate between two currencies. Query params: from, to, date.
+func (h *FXHandler) GetRate(c *gin.Context) {
+	on, ok := rateDateParam(c)
+	if !ok {
+		return
+	}
+
+	rate, err := h.service.GetRate(c.Query("from"), c.Query("to"), on)
+	if err != nil {
+		writeFXError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// Convert converts an amount. Query params: amount, from, to, date.
+func (h *FXHandler) Convert(c *gin.Context) {
+	on, ok := rateDateParam(c)
+	if !ok {
+		return
+	}
+
+	amount, err := money.NewFromString(c.Query("amount"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid amount"})
+		return
+	}
+
+	conversion, err := h.service.Convert(money.New(amount, c.Query("from")), c.Query("to"), on)
+	if err != nil {
+		writeFXError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, conversion)
+}
+
+func (h *FXHandler) GetBaseCurrency(c *gin.Context) {
+	tenantID, _ := auth.GetTenantID(c)
+
+	currency, err := h.service.GetBaseCurrency(tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tenant_id": tenantID, "base_currency": currency})
+}
+
+func (h *FXHandler) SetBaseCurrency(c *gin.Context) {
+	var req struct {
+		BaseCurrency string `json:"base_currency" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+
+	setting, err := h.service.SetBaseCurrency(tenantID, req.BaseCurrency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// rateDateParam reads the optional date query param, defaulting to today
+func rateDateParam(c *gin.Context) (time.Time, bool) {
+	v := c.Query("date")
+	if v == "" {
+		return time.Now().UTC(), true
+	}
+	on, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date, expected YYYY-MM-DD"})
+		return time.Time{}, false
+	}
+	return on, true
+}
+
+func writeFXError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/b2b-platform/billing-service/models"
 	"github.com/b2b-platform/billing-service/service"
 	"github.com/b2b-platform/shared/auth"
+	"github.com/b2b-platform/shared/money"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -89,7 +90,7 @@ func (h *PaymentHandler) ReleaseEscrow(c *gin.Context) {
 func (h *PaymentHandler) CreateRefund(c *gin.Context) {
 	var req struct {
 		PaymentID uuid.UUID `json:"payment_id" binding:"required"`
-		Amount    float64   `json:"amount" binding:"required"`
+		Amount    money.Decimal `json:"amount" binding:"required"`
 		Reason    string    `json:"reason"`
 	}
 
@@ -0,0 +1,22 @@
+-- Daily FX rates
+CREATE TABLE IF NOT EXISTS billing.fx_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    base_currency VARCHAR(10) NOT NULL,
+    quote_currency VARCHAR(10) NOT NULL,
+    rate NUMERIC(24,12) NOT NULL,
+    rate_date DATE NOT NULL,
+    source VARCHAR(50) DEFAULT 'manual',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(base_currency, quote_currency, rate_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fx_rates_pair ON billing.fx_rates(base_currency, quote_currency, rate_date DESC);
+
+-- Reporting currency per tenant
+CREATE TABLE IF NOT EXISTS billing.tenant_currencies (
+    tenant_id UUID PRIMARY KEY,
+    base_currency VARCHAR(10) NOT NULL DEFAULT 'USD',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
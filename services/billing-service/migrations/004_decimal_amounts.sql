@@ -0,0 +1,15 @@
+-- Amounts are exact decimals; four places fit three-decimal currencies such as KWD
+ALTER TABLE billing.plans
+ALTER COLUMN price TYPE NUMERIC(19,4);
+
+ALTER TABLE billing.payments
+ALTER COLUMN amount TYPE NUMERIC(19,4);
+
+ALTER TABLE billing.escrow_holds
+ALTER COLUMN amount TYPE NUMERIC(19,4);
+
+ALTER TABLE billing.settlements
+ALTER COLUMN amount TYPE NUMERIC(19,4);
+
+ALTER TABLE billing.refunds
+ALTER COLUMN amount TYPE NUMERIC(19,4);
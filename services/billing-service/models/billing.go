@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -12,7 +13,7 @@ type Plan struct {
 	Name        string    `gorm:"type:varchar(255);not null;unique" json:"name"`
 	Code        string    `gorm:"type:varchar(100);not null;unique" json:"code"`
 	Description string    `gorm:"type:text" json:"description"`
-	Price       float64   `gorm:"not null" json:"price"`
+	Price       money.Decimal `gorm:"type:numeric(19,4);not null" json:"price"`
 	Currency    string    `gorm:"type:varchar(10);default:'USD'" json:"currency"`
 	BillingCycle string   `gorm:"type:varchar(50);not null" json:"billing_cycle"` // monthly, yearly
 	IsActive    bool      `gorm:"default:true" json:"is_active"`
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+)
+
+// FXRate is a daily exchange rate quoted as units of QuoteCurrency per one unit
+// of BaseCurrency
+type FXRate struct {
+	ID            uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BaseCurrency  string        `gorm:"type:varchar(10);not null;uniqueIndex:idx_fx_rate_pair_date" json:"base_currency"`
+	QuoteCurrency string        `gorm:"type:varchar(10);not null;uniqueIndex:idx_fx_rate_pair_date" json:"quote_currency"`
+	Rate          money.Decimal `gorm:"type:numeric(24,12);not null" json:"rate"`
+	RateDate      time.Time     `gorm:"type:date;not null;uniqueIndex:idx_fx_rate_pair_date" json:"rate_date"`
+	Source        string        `gorm:"type:varchar(50);default:'manual'" json:"source"` // manual, csv
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+func (FXRate) TableName() string {
+	return "billing.fx_rates"
+}
+
+// TenantCurrency holds the currency a tenant reports and budgets in
+type TenantCurrency struct {
+	TenantID     uuid.UUID `gorm:"type:uuid;primary_key" json:"tenant_id"`
+	BaseCurrency string    `gorm:"type:varchar(10);not null;default:'USD'" json:"base_currency"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (TenantCurrency) TableName() string {
+	return "billing.tenant_currencies"
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 )
 
@@ -12,7 +13,7 @@ type Payment struct {
 	OrderID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_id"` // PO ID
 	PaymentIntentID string     `gorm:"type:varchar(255);not null;uniqueIndex" json:"payment_intent_id"`
 	Provider        string     `gorm:"type:varchar(50);not null" json:"provider"` // stripe, mock, etc.
-	Amount          float64    `gorm:"not null" json:"amount"`
+	Amount          money.Decimal `gorm:"type:numeric(19,4);not null" json:"amount"`
 	Currency        string     `gorm:"type:varchar(10);default:'USD'" json:"currency"`
 	Status          string     `gorm:"type:varchar(50);default:'pending';index" json:"status"` // pending, succeeded, failed, cancelled
 	PaymentMode     string     `gorm:"type:varchar(50);not null;index" json:"payment_mode"` // DIRECT, ESCROW
@@ -37,7 +38,7 @@ type EscrowHold struct {
 	PaymentID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"payment_id"`
 	OrderID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_id"` // PO ID
 	SupplierID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"supplier_id"`
-	Amount            float64    `gorm:"not null" json:"amount"`
+	Amount            money.Decimal `gorm:"type:numeric(19,4);not null" json:"amount"`
 	Currency          string     `gorm:"type:varchar(10);default:'USD'" json:"currency"`
 	Status            string     `gorm:"type:varchar(50);default:'held';index" json:"status"` // held, released, refunded
 	AutoReleaseDays   int        `gorm:"default:30" json:"auto_release_days"`
@@ -63,7 +64,7 @@ type Settlement struct {
 	EscrowHoldID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"escrow_hold_id"`
 	SupplierID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"supplier_id"`
 	PayoutAccountID uuid.UUID  `gorm:"type:uuid;index" json:"payout_account_id,omitempty"`
-	Amount          float64    `gorm:"not null" json:"amount"`
+	Amount          money.Decimal `gorm:"type:numeric(19,4);not null" json:"amount"`
 	Currency        string     `gorm:"type:varchar(10);default:'USD'" json:"currency"`
 	Status          string     `gorm:"type:varchar(50);default:'pending';index" json:"status"` // pending, processing, completed, failed
 	ProviderPayoutID string    `gorm:"type:varchar(255)" json:"provider_payout_id,omitempty"`
@@ -87,7 +88,7 @@ type Refund struct {
 	PaymentID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"payment_id"`
 	OrderID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_id"`
 	RefundNumber    string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_tenant_refund" json:"refund_number"`
-	Amount          float64    `gorm:"not null" json:"amount"`
+	Amount          money.Decimal `gorm:"type:numeric(19,4);not null" json:"amount"`
 	Currency        string     `gorm:"type:varchar(10);default:'USD'" json:"currency"`
 	Reason          string     `gorm:"type:text" json:"reason"`
 	Status          string     `gorm:"type:varchar(50);default:'pending';index" json:"status"` // pending, processing, completed, failed
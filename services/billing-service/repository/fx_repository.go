@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/b2b-platform/billing-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FXRepository struct {
+	db *gorm.DB
+}
+
+func NewFXRepository(db *gorm.DB) *FXRepository {
+	return &FXRepository{db: db}
+}
+
+// Upsert stores a rate, replacing any rate already recorded for the pair and date
+func (r *FXRepository) Upsert(rate *models.FXRate) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base_currency"}, {Name: "quote_currency"}, {Name: "rate_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).Create(rate).Error
+}
+
+// FindLatest returns the most recent rate for a pair on or before a date, or nil
+// when no rate has been recorded
+func (r *FXRepository) FindLatest(base, quote string, on time.Time) (*models.FXRate, error) {
+	var rate models.FXRate
+	err := r.db.Where("base_currency = ? AND quote_currency = ? AND rate_date <= ?", base, quote, on).
+		Order("rate_date DESC").
+		First(&rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &rate, err
+}
+
+// List returns the latest rate of every pair on or before a date
+func (r *FXRepository) List(base string, on time.Time) ([]models.FXRate, error) {
+	var rates []models.FXRate
+	query := r.db.Select("DISTINCT ON (base_currency, quote_currency) *").
+		Where("rate_date <= ?", on)
+	if base != "" {
+		query = query.Where("base_currency = ?", base)
+	}
+	err := query.Order("base_currency, quote_currency, rate_date DESC").Find(&rates).Error
+	return rates, err
+}
+
+func (r *FXRepository) GetTenantCurrency(tenantID uuid.UUID) (*models.TenantCurrency, error) {
+	var setting models.TenantCurrency
+	err := r.db.Where("tenant_id = ?", tenantID).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &setting, err
+}
+
+func (r *FXRepository) SetTenantCurrency(setting *models.TenantCurrency) error {
+	return r.db.Save(setting).Error
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/b2b-platform/billing-service/models"
 	"github.com/b2b-platform/shared/events"
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 )
 
@@ -116,7 +117,7 @@ func TestBillingService_CreatePlan(t *testing.T) {
 		ID:   uuid.New(),
 		Code: "basic",
 		Name: "Basic Plan",
-		Price: 99.99,
+		Price: money.MustParse("99.99"),
 		Entitlements: []models.Entitlement{
 			{
 				Feature: "users",
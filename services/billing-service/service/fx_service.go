@@ -0,0 +1,303 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/billing-service/models"
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRateNotFound    = errors.New("no FX rate available for currency pair")
+	ErrInvalidCurrency = errors.New("currency must be a 3-letter ISO code")
+	ErrInvalidRate     = errors.New("rate must be greater than zero")
+)
+
+// pivotCurrencies are tried, in order, to derive a cross rate when a pair has
+// no direct or inverse rate
+var pivotCurrencies = []string{"USD", "EUR"}
+
+type FXRepository interface {
+	Upsert(rate *models.FXRate) error
+	FindLatest(base, quote string, on time.Time) (*models.FXRate, error)
+	List(base string, on time.Time) ([]models.FXRate, error)
+	GetTenantCurrency(tenantID uuid.UUID) (*models.TenantCurrency, error)
+	SetTenantCurrency(setting *models.TenantCurrency) error
+}
+
+// ConversionRate is the rate used to convert between two currencies. RateDate
+// is the date of the oldest rate involved.
+type ConversionRate struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Rate     money.Decimal `json:"rate"`
+	RateDate time.Time     `json:"rate_date"`
+	Method   string        `json:"method"` // identity, direct, inverse, cross
+}
+
+type Conversion struct {
+	From money.Money    `json:"from"`
+	To   money.Money    `json:"to"`
+	Rate ConversionRate `json:"rate"`
+}
+
+type FXImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type FXImportResult struct {
+	Imported int             `json:"imported"`
+	Errors   []FXImportError `json:"errors,omitempty"`
+}
+
+type FXService struct {
+	fxRepo FXRepository
+}
+
+func NewFXService(fxRepo FXRepository) *FXService {
+	return &FXService{fxRepo: fxRepo}
+}
+
+func (s *FXService) SetRate(rate *models.FXRate) error {
+	if err := normalizeRate(rate); err != nil {
+		return err
+	}
+	if rate.Source == "" {
+		rate.Source = "manual"
+	}
+	return s.fxRepo.Upsert(rate)
+}
+
+func (s *FXService) ListRates(base string, on time.Time) ([]models.FXRate, error) {
+	if base != "" {
+		base = money.NormalizeCurrency(base)
+	}
+	return s.fxRepo.List(base, on)
+}
+
+// ImportCSV loads daily rates from CSV with the header date,base,quote,rate.
+// Valid rows are imported; invalid rows are reported with their line number.
+func (s *FXService) ImportCSV(r io.Reader) (*FXImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "base", "quote", "rate"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV is missing the %q column", required)
+		}
+	}
+
+	result := &FXImportResult{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		line++
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, FXImportError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		rate, err := parseRateRecord(record, columns)
+		if err == nil {
+			rate.Source = "csv"
+			err = s.SetRate(rate)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, FXImportError{Line: line, Error: err.Error()})
+			continue
+		}
+		result.Imported++
+	}
+	return result, nil
+}
+
+// GetRate finds the rate to convert from one currency to another on a date,
+// using the latest rate on or before that date. Missing pairs fall back to the
+// inverse rate and then to a cross rate through a pivot currency.
+func (s *FXService) GetRate(from, to string, on time.Time) (*ConversionRate, error) {
+	from = money.NormalizeCurrency(from)
+	to = money.NormalizeCurrency(to)
+
+	if from == to {
+		return &ConversionRate{From: from, To: to, Rate: money.NewFromInt(1), RateDate: on, Method: "identity"}, nil
+	}
+
+	rate, err := s.pairRate(from, to, on)
+	if err != nil || rate != nil {
+		return rate, err
+	}
+
+	for _, pivot := range pivotCurrencies {
+		if pivot == from || pivot == to {
+			continue
+		}
+		first, err := s.pairRate(from, pivot, on)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			continue
+		}
+		second, err := s.pairRate(pivot, to, on)
+		if err != nil {
+			return nil, err
+		}
+		if second == nil {
+			continue
+		}
+
+		rateDate := first.RateDate
+		if second.RateDate.Before(rateDate) {
+			rateDate = second.RateDate
+		}
+		return &ConversionRate{
+			From:     from,
+			To:       to,
+			Rate:     first.Rate.Mul(second.Rate).Round(12),
+			RateDate: rateDate,
+			Method:   "cross",
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+}
+
+// Convert converts an amount and rounds it to the target currency's minor units
+func (s *FXService) Convert(amount money.Money, to string, on time.Time) (*Conversion, error) {
+	rate, err := s.GetRate(amount.Currency, to, on)
+	if err != nil {
+		return nil, err
+	}
+	return &Conversion{
+		From: money.New(amount.Amount, amount.Currency),
+		To:   amount.Convert(rate.Rate, to),
+		Rate: *rate,
+	}, nil
+}
+
+// GetBaseCurrency returns the tenant's reporting currency, USD when not configured
+func (s *FXService) GetBaseCurrency(tenantID uuid.UUID) (string, error) {
+	setting, err := s.fxRepo.GetTenantCurrency(tenantID)
+	if err != nil {
+		return "", err
+	}
+	if setting == nil {
+		return money.NormalizeCurrency(""), nil
+	}
+	return setting.BaseCurrency, nil
+}
+
+func (s *FXService) SetBaseCurrency(tenantID uuid.UUID, currency string) (*models.TenantCurrency, error) {
+	currency = money.NormalizeCurrency(currency)
+	if !isCurrencyCode(currency) {
+		return nil, ErrInvalidCurrency
+	}
+
+	setting := &models.TenantCurrency{TenantID: tenantID, BaseCurrency: currency}
+	if err := s.fxRepo.SetTenantCurrency(setting); err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
+
+// pairRate looks up a direct rate, then the inverse of the opposite pair
+func (s *FXService) pairRate(from, to string, on time.Time) (*ConversionRate, error) {
+	direct, err := s.fxRepo.FindLatest(from, to, on)
+	if err != nil {
+		return nil, err
+	}
+	if direct != nil {
+		return &ConversionRate{From: from, To: to, Rate: direct.Rate, RateDate: direct.RateDate, Method: "direct"}, nil
+	}
+
+	inverse, err := s.fxRepo.FindLatest(to, from, on)
+	if err != nil {
+		return nil, err
+	}
+	if inverse != nil {
+		return &ConversionRate{
+			From:     from,
+			To:       to,
+			Rate:     money.NewFromInt(1).Div(inverse.Rate),
+			RateDate: inverse.RateDate,
+			Method:   "inverse",
+		}, nil
+	}
+	return nil, nil
+}
+
+func parseRateRecord(record []string, columns map[string]int) (*models.FXRate, error) {
+	field := func(name string) string {
+		if i := columns[name]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	rateDate, err := time.Parse("2006-01-02", field("date"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", field("date"))
+	}
+	rate, err := money.NewFromString(field("rate"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.FXRate{
+		BaseCurrency:  field("base"),
+		QuoteCurrency: field("quote"),
+		Rate:          rate,
+		RateDate:      rateDate,
+	}, nil
+}
+
+func normalizeRate(rate *models.FXRate) error {
+	if strings.TrimSpace(rate.BaseCurrency) == "" || strings.TrimSpace(rate.QuoteCurrency) == "" {
+		return ErrInvalidCurrency
+	}
+	rate.BaseCurrency = money.NormalizeCurrency(rate.BaseCurrency)
+	rate.QuoteCurrency = money.NormalizeCurrency(rate.QuoteCurrency)
+	if !isCurrencyCode(rate.BaseCurrency) || !isCurrencyCode(rate.QuoteCurrency) {
+		return ErrInvalidCurrency
+	}
+	if rate.BaseCurrency == rate.QuoteCurrency {
+		return fmt.Errorf("base and quote currency must differ")
+	}
+	if rate.Rate.Sign() <= 0 {
+		return ErrInvalidRate
+	}
+	if rate.RateDate.IsZero() {
+		rate.RateDate = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	return nil
+}
+
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/b2b-platform/billing-service/models"
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+)
+
+// MockFXRepository for testing
+type MockFXRepository struct {
+	rates      []models.FXRate
+	currencies map[uuid.UUID]*models.TenantCurrency
+}
+
+func (m *MockFXRepository) Upsert(rate *models.FXRate) error {
+	for i, r := range m.rates {
+		if r.BaseCurrency == rate.BaseCurrency && r.QuoteCurrency == rate.QuoteCurrency && r.RateDate.Equal(rate.RateDate) {
+			m.rates[i] = *rate
+			return nil
+		}
+	}
+	m.rates = append(m.rates, *rate)
+	return nil
+}
+
+func (m *MockFXRepository) FindLatest(base, quote string, on time.Time) (*models.FXRate, error) {
+	var latest *models.FXRate
+	for i, r := range m.rates {
+		if r.BaseCurrency != base || r.QuoteCurrency != quote || r.RateDate.After(on) {
+			continue
+		}
+		if latest == nil || r.RateDate.After(latest.RateDate) {
+			latest = &m.rates[i]
+		}
+	}
+	return latest, nil
+}
+
+func (m *MockFXRepository) List(base string, on time.Time) ([]models.FXRate, error) {
+	return m.rates, nil
+}
+
+func (m *MockFXRepository) GetTenantCurrency(tenantID uuid.UUID) (*models.TenantCurrency, error) {
+	return m.currencies[tenantID], nil
+}
+
+func (m *MockFXRepository) SetTenantCurrency(setting *models.TenantCurrency) error {
+	if m.currencies == nil {
+		m.currencies = make(map[uuid.UUID]*models.TenantCurrency)
+	}
+	m.currencies[setting.TenantID] = setting
+	return nil
+}
+
+func date(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestImportCSV(t *testing.T) {
+	repo := &MockFXRepository{}
+	fxService := NewFXService(repo)
+
+	csv := strings.Join([]string{
+		"date,base,quote,rate",
+		"2024-03-01,USD,EUR,0.9215",
+		"2024-03-01,usd,jpy,150.12",
+		"2024-03-01,USD,GBP,-1",
+		"03/01/2024,USD,CHF,0.88",
+	}, "\n")
+
+	result, err := fxService.ImportCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+
+	if result.Imported != 2 {
+		t.Errorf("expected 2 rates imported, got %d", result.Imported)
+	}
+	if len(result.Errors) != 2 || result.Errors[0].Line != 4 || result.Errors[1].Line != 5 {
+		t.Errorf("expected errors on lines 4 and 5, got %+v", result.Errors)
+	}
+
+	rate, _ := repo.FindLatest("USD", "JPY", date("2024-03-01"))
+	if rate == nil || rate.Source != "csv" {
+		t.Errorf("expected lower-case currency codes to be normalized, got %+v", rate)
+	}
+}
+
+func TestGetRateUsesLatestRateOnOrBeforeDate(t *testing.T) {
+	repo := &MockFXRepository{}
+	fxService := NewFXService(repo)
+
+	fxService.SetRate(&models.FXRate{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: money.MustParse("0.90"), RateDate: date("2024-03-01")})
+	fxService.SetRate(&models.FXRate{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: money.MustParse("0.95"), RateDate: date("2024-03-05")})
+
+	rate, err := fxService.GetRate("USD", "EUR", date("2024-03-04"))
+	if err != nil {
+		t.Fatalf("GetRate failed: %v", err)
+	}
+	if !rate.Rate.Equal(money.MustParse("0.90")) || rate.Method != "direct" {
+		t.Errorf("expected direct rate 0.90, got %s (%s)", rate.Rate, rate.Method)
+	}
+
+	if _, err := fxService.GetRate("USD", "EUR", date("2024-02-28")); !errors.Is(err, ErrRateNotFound) {
+		t.Errorf("expected ErrRateNotFound before the first rate, got %v", err)
+	}
+}
+
+func TestGetRateInverseAndCross(t *testing.T) {
+	repo := &MockFXRepository{}
+	fxService := NewFXService(repo)
+
+	fxService.SetRate(&models.FXRate{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: money.MustParse("0.8"), RateDate: date("2024-03-01")})
+	fxService.SetRate(&models.FXRate{BaseCurrency: "USD", QuoteCurrency: "JPY", Rate: money.MustParse("150"), RateDate: date("2024-03-01")})
+
+	inverse, err := fxService.GetRate("EUR", "USD", date("2024-03-01"))
+	if err != nil {
+		t.Fatalf("GetRate failed: %v", err)
+	}
+	if !inverse.Rate.Equal(money.MustParse("1.25")) || inverse.Method != "inverse" {
+		t.Errorf("expected inverse rate 1.25, got %s (%s)", inverse.Rate, inverse.Method)
+	}
+
+	cross, err := fxService.GetRate("EUR", "JPY", date("2024-03-01"))
+	if err != nil {
+		t.Fatalf("GetRate failed: %v", err)
+	}
+	if !cross.Rate.Equal(money.MustParse("187.5")) || cross.Method != "cross" {
+		t.Errorf("expected cross rate 187.5, got %s (%s)", cross.Rate, cross.Method)
+	}
+}
+
+func TestConvertRoundsToTargetMinorUnits(t *testing.T) {
+	repo := &MockFXRepository{}
+	fxService := NewFXService(repo)
+
+	fxService.SetRate(&models.FXRate{BaseCurrency: "USD", QuoteCurrency: "JPY", Rate: money.MustParse("150.123"), RateDate: date("2024-03-01")})
+
+	conversion, err := fxService.Convert(money.New(money.MustParse("10.10"), "USD"), "JPY", date("2024-03-01"))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if conversion.To.String() != "1516 JPY" {
+		t.Errorf("expected 1516 JPY, got %s", conversion.To)
+	}
+}
+
+func TestBaseCurrencyDefaultsToUSD(t *testing.T) {
+	repo := &MockFXRepository{}
+	fxService := NewFXService(repo)
+	tenantID := uuid.New()
+
+	currency, _ := fxService.GetBaseCurrency(tenantID)
+	if currency != "USD" {
+		t.Errorf("expected default base currency USD, got %s", currency)
+	}
+
+	if _, err := fxService.SetBaseCurrency(tenantID, "eur"); err != nil {
+		t.Fatalf("SetBaseCurrency failed: %v", err)
+	}
+	currency, _ = fxService.GetBaseCurrency(tenantID)
+	if currency != "EUR" {
+		t.Errorf("expected base currency EUR, got %s", currency)
+	}
+
+	if _, err := fxService.SetBaseCurrency(tenantID, "EURO"); !errors.Is(err, ErrInvalidCurrency) {
+		t.Errorf("expected ErrInvalidCurrency, got %v", err)
+	}
+}
@@ -5,22 +5,23 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 )
 
 // PaymentProvider interface for payment processing
 type PaymentProvider interface {
-	CreatePaymentIntent(ctx context.Context, amount float64, currency string, orderID uuid.UUID, metadata map[string]interface{}) (*PaymentIntent, error)
+	CreatePaymentIntent(ctx context.Context, amount money.Decimal, currency string, orderID uuid.UUID, metadata map[string]interface{}) (*PaymentIntent, error)
 	ConfirmPayment(ctx context.Context, paymentIntentID string) (*PaymentResult, error)
 	HandleWebhook(ctx context.Context, payload []byte, signature string) (*WebhookEvent, error)
-	Refund(ctx context.Context, paymentIntentID string, amount float64, reason string) (*RefundResult, error)
+	Refund(ctx context.Context, paymentIntentID string, amount money.Decimal, reason string) (*RefundResult, error)
 }
 
 // PaymentIntent represents a payment intent
 type PaymentIntent struct {
 	ID            string
 	ClientSecret  string
-	Amount        float64
+	Amount        money.Decimal
 	Currency      string
 	Status        string
 	PaymentMethod string
@@ -31,7 +32,7 @@ type PaymentResult struct {
 	Success       bool
 	PaymentID     string
 	TransactionID string
-	Amount        float64
+	Amount        money.Decimal
 	Currency      string
 	Status        string
 	Metadata      map[string]interface{}
@@ -42,7 +43,7 @@ type WebhookEvent struct {
 	Type            string
 	PaymentIntentID string
 	Status          string
-	Amount          float64
+	Amount          money.Decimal
 	Metadata        map[string]interface{}
 }
 
@@ -50,7 +51,7 @@ type WebhookEvent struct {
 type RefundResult struct {
 	Success     bool
 	RefundID    string
-	Amount      float64
+	Amount      money.Decimal
 	Status      string
 	FailedReason string
 }
@@ -72,7 +73,7 @@ func (m *MockPaymentProvider) SetSimulateFailure(fail bool) {
 	m.simulateFailure = fail
 }
 
-func (m *MockPaymentProvider) CreatePaymentIntent(ctx context.Context, amount float64, currency string, orderID uuid.UUID, metadata map[string]interface{}) (*PaymentIntent, error) {
+func (m *MockPaymentProvider) CreatePaymentIntent(ctx context.Context, amount money.Decimal, currency string, orderID uuid.UUID, metadata map[string]interface{}) (*PaymentIntent, error) {
 	intentID := fmt.Sprintf("pi_mock_%s", uuid.New().String()[:8])
 	return &PaymentIntent{
 		ID:            intentID,
@@ -120,7 +121,7 @@ func (m *MockPaymentProvider) HandleWebhook(ctx context.Context, payload []byte,
 	}, nil
 }
 
-func (m *MockPaymentProvider) Refund(ctx context.Context, paymentIntentID string, amount float64, reason string) (*RefundResult, error) {
+func (m *MockPaymentProvider) Refund(ctx context.Context, paymentIntentID string, amount money.Decimal, reason string) (*RefundResult, error) {
 	if m.simulateFailure {
 		return &RefundResult{
 			Success:      false,
@@ -7,6 +7,7 @@ import (
 
 	"github.com/b2b-platform/billing-service/models"
 	"github.com/b2b-platform/shared/events"
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 )
 
@@ -77,7 +78,7 @@ func NewPaymentService(
 type CreatePaymentIntentRequest struct {
 	OrderID     uuid.UUID              `json:"order_id"`
 	SupplierID  uuid.UUID              `json:"supplier_id"` // Required for ESCROW mode
-	Amount      float64                `json:"amount"`
+	Amount      money.Decimal          `json:"amount"`
 	Currency    string                 `json:"currency"`
 	PaymentMode string                 `json:"payment_mode"` // DIRECT, ESCROW
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
@@ -86,7 +87,7 @@ type CreatePaymentIntentRequest struct {
 type CreatePaymentIntentResponse struct {
 	PaymentIntentID string `json:"payment_intent_id"`
 	ClientSecret    string `json:"client_secret"`
-	Amount          float64 `json:"amount"`
+	Amount          money.Decimal `json:"amount"`
 	Currency        string  `json:"currency"`
 }
 
@@ -332,7 +333,7 @@ func (s *PaymentService) ReleaseEscrow(ctx context.Context, escrowHoldID uuid.UU
 	return s.eventBus.Publish(ctx, event)
 }
 
-func (s *PaymentService) CreateRefund(ctx context.Context, tenantID uuid.UUID, paymentID uuid.UUID, amount float64, reason string, createdBy uuid.UUID) (*models.Refund, error) {
+func (s *PaymentService) CreateRefund(ctx context.Context, tenantID uuid.UUID, paymentID uuid.UUID, amount money.Decimal, reason string, createdBy uuid.UUID) (*models.Refund, error) {
 	payment, err := s.paymentRepo.GetByID(paymentID)
 	if err != nil {
 		return nil, fmt.Errorf("payment not found: %w", err)
@@ -346,6 +347,10 @@ func (s *PaymentService) CreateRefund(ctx context.Context, tenantID uuid.UUID, p
 		return nil, fmt.Errorf("can only refund succeeded payments")
 	}
 
+	if amount.Sign() <= 0 || amount.GreaterThan(payment.Amount) {
+		return nil, fmt.Errorf("refund amount must be positive and at most the payment amount")
+	}
+
 	// Create refund with provider
 	refundResult, err := s.provider.Refund(ctx, payment.PaymentIntentID, amount, reason)
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/b2b-platform/billing-service/models"
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 )
 
@@ -300,7 +301,7 @@ func TestPaymentService_CreatePaymentIntent_ESCROW(t *testing.T) {
 	req := CreatePaymentIntentRequest{
 		OrderID:     orderID,
 		SupplierID:  supplierID,
-		Amount:      1000.00,
+		Amount:      money.MustParse("1000.00"),
 		Currency:    "USD",
 		PaymentMode: "ESCROW",
 	}
@@ -356,7 +357,7 @@ func TestPaymentService_ReleaseEscrow_BlockedByDispute(t *testing.T) {
 		PaymentID:        uuid.New(),
 		OrderID:          uuid.New(),
 		SupplierID:       payoutAccount.SupplierID,
-		Amount:           1000.00,
+		Amount:           money.MustParse("1000.00"),
 		Status:           "held",
 		BlockedByDispute: true,
 	}
@@ -409,7 +410,7 @@ func TestPaymentService_ReleaseEscrow_Success(t *testing.T) {
 		PaymentID:        uuid.New(),
 		OrderID:          uuid.New(),
 		SupplierID:       supplierID,
-		Amount:           1000.00,
+		Amount:           money.MustParse("1000.00"),
 		Status:           "held",
 		BlockedByDispute: false,
 	}
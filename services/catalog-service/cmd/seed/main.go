@@ -7,6 +7,7 @@ import (
 
 	"github.com/b2b-platform/catalog-service/models"
 	"github.com/b2b-platform/catalog-service/repository"
+	"github.com/b2b-platform/catalog-service/service"
 	"github.com/b2b-platform/shared/database"
 	"github.com/google/uuid"
 )
@@ -100,6 +101,7 @@ func main() {
 	}
 
 	for _, part := range parts {
+		part.NormalizedPartNumber = service.NormalizePartNumber(part.PartNumber)
 		if err := partRepo.Create(&part); err != nil {
 			log.Printf("Part may already exist: %v", err)
 		} else {
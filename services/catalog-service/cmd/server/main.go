@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/b2b-platform/catalog-service/handlers"
 	"github.com/b2b-platform/catalog-service/repository"
@@ -41,16 +43,23 @@ func main() {
 	categoryRepo := repository.NewCategoryRepository(db)
 	partRepo := repository.NewPartRepository(db)
 	attributeRepo := repository.NewAttributeRepository(db)
+	importRepo := repository.NewImportRepository(db)
+	versionRepo := repository.NewVersionRepository(db)
 
 	catalogService := service.NewCatalogService(
 		manufacturerRepo,
 		categoryRepo,
 		partRepo,
 		attributeRepo,
+		versionRepo,
 		eventBus,
 	)
+	catalogService.UsePartReferences(service.NewEquipmentReferenceClient(), service.NewMarketplaceReferenceClient())
 	catalogHandler := handlers.NewCatalogHandler(catalogService)
 
+	importService := service.NewImportService(catalogService, importRepo)
+	importHandler := handlers.NewImportHandler(importService)
+
 	r := gin.Default()
 
 	
@@ -65,6 +74,20 @@ func main() {
 	r.GET("/health", healthChecker.Health)
 	r.GET("/ready", healthChecker.Ready)
 
+	// Retry moving references of merged parts that failed at merge time
+	reconcileInterval := 15 * time.Minute
+	if interval, err := time.ParseDuration(os.Getenv("MERGE_RECONCILE_INTERVAL")); err == nil && interval > 0 {
+		reconcileInterval = interval
+	}
+	reconcileCtx, cancelReconcile := context.WithCancel(context.Background())
+	defer cancelReconcile()
+
+	go func() {
+		if err := catalogService.StartMergeReconciler(reconcileCtx, reconcileInterval); err != nil && err != context.Canceled {
+			log.Printf("Merge reconciler error: %v", err)
+		}
+	}()
+
 	// Configure CORS
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:3000", "http://localhost:3002", "http://127.0.0.1:3000", "http://127.0.0.1:3002"}
@@ -95,20 +118,67 @@ func main() {
 
 		// Categories
 		api.GET("/categories", catalogHandler.ListCategories)
+		api.GET("/categories/tree", catalogHandler.GetCategoryTree)
 		api.GET("/categories/:id", catalogHandler.GetCategory)
+		api.GET("/categories/:id/subtree", catalogHandler.GetCategorySubtree)
+		api.GET("/categories/:id/breadcrumbs", catalogHandler.GetBreadcrumbs)
+		api.POST("/categories/:id/move", catalogHandler.MoveCategory)
+		api.POST("/categories/:id/retire", catalogHandler.RetireCategory)
 		api.POST("/categories", catalogHandler.CreateCategory)
+		api.GET("/categories/:id/attribute-template", catalogHandler.GetAttributeTemplate)
+		api.POST("/categories/:id/attributes", catalogHandler.SetTemplateAttribute)
+		api.DELETE("/categories/:id/attributes/:attributeId", catalogHandler.RemoveTemplateAttribute)
+		api.PUT("/categories/:id", catalogHandler.UpdateCategory)
+		api.GET("/categories/:id/history", catalogHandler.GetHistory(service.EntityCategory))
+		api.POST("/categories/:id/rollback", catalogHandler.Rollback(service.EntityCategory))
 
 		// Attributes
 		api.GET("/attributes", catalogHandler.ListAttributes)
 		api.POST("/attributes", catalogHandler.CreateAttribute)
+		api.PUT("/attributes/:id", catalogHandler.UpdateAttribute)
+		api.GET("/attributes/:id/history", catalogHandler.GetHistory(service.EntityAttribute))
+		api.POST("/attributes/:id/rollback", catalogHandler.Rollback(service.EntityAttribute))
 
 		// Parts
 		api.GET("/parts", catalogHandler.ListParts)
+		api.GET("/parts/export", importHandler.ExportParts)
+		api.GET("/parts/search-snapshot", catalogHandler.SearchSnapshot)
 		api.GET("/parts/:id", catalogHandler.GetPart)
 		api.POST("/parts", catalogHandler.CreatePart)
 		api.GET("/parts/pending", catalogHandler.GetPendingParts)
 		api.POST("/parts/:id/approve", catalogHandler.ApprovePart)
 		api.POST("/parts/:id/reject", catalogHandler.RejectPart)
+		api.POST("/parts/:id/attributes", catalogHandler.AddPartAttribute)
+		api.GET("/parts/:id/attribute-check", catalogHandler.ValidatePartAttributes)
+
+		// Change history and review of edits to approved parts
+		api.PUT("/parts/:id", catalogHandler.UpdatePart)
+		api.GET("/parts/:id/history", catalogHandler.GetHistory(service.EntityPart))
+		api.POST("/parts/:id/rollback", catalogHandler.Rollback(service.EntityPart))
+		api.GET("/parts/revisions", catalogHandler.ListPartRevisions)
+		api.POST("/parts/revisions/:id/approve", catalogHandler.ApprovePartRevision)
+		api.POST("/parts/revisions/:id/reject", catalogHandler.RejectPartRevision)
+
+		// Duplicate review
+		api.GET("/parts/duplicates", catalogHandler.ListDuplicateCandidates)
+		api.POST("/parts/duplicates/:id/dismiss", catalogHandler.DismissDuplicate)
+		api.POST("/parts/:id/detect-duplicates", catalogHandler.DetectDuplicates)
+		api.POST("/parts/:id/merge", catalogHandler.MergePart)
+		api.POST("/parts/merges/reconcile", catalogHandler.ReconcileMerges)
+
+		// Supersession and interchangeability
+		api.GET("/parts/:id/cross-references", catalogHandler.ListCrossReferences)
+		api.POST("/parts/:id/cross-references", catalogHandler.AddCrossReference)
+		api.DELETE("/cross-references/:id", catalogHandler.RemoveCrossReference)
+		api.GET("/parts/:id/interchange", catalogHandler.GetInterchange)
+		api.POST("/parts/interchange", catalogHandler.GetInterchanges)
+		api.POST("/parts/lookup", catalogHandler.LookupPartNumbers)
+
+		// Bulk import
+		api.POST("/imports", importHandler.StartImport)
+		api.GET("/imports", importHandler.ListImports)
+		api.GET("/imports/:id", importHandler.GetImport)
+		api.GET("/imports/:id/errors", importHandler.ListImportErrors)
 	}
 
 	port := os.Getenv("PORT")
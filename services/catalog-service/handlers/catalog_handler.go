@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/b2b-platform/shared/auth"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type CatalogHandler struct {
@@ -69,7 +71,9 @@ func (h *CatalogHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.CreateCategory(&category); err != nil {
+	userID, _ := auth.GetUserID(c)
+
+	if err := h.service.CreateCategory(&category, userID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -103,6 +107,129 @@ func (h *CatalogHandler) ListCategories(c *gin.Context) {
 	c.JSON(http.StatusOK, categories)
 }
 
+// GetCategoryTree returns the category hierarchy nested from the roots.
+// Query params: include_inactive (bool).
+func (h *CatalogHandler) GetCategoryTree(c *gin.Context) {
+	includeInactive, _ := strconv.ParseBool(c.DefaultQuery("include_inactive", "false"))
+
+	tree, err := h.service.GetCategoryTree(includeInactive)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// GetCategorySubtree returns a category with its descendants nested.
+// Query params: include_inactive (bool).
+func (h *CatalogHandler) GetCategorySubtree(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	includeInactive, _ := strconv.ParseBool(c.DefaultQuery("include_inactive", "false"))
+
+	subtree, err := h.service.GetCategorySubtree(id, includeInactive)
+	if err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subtree)
+}
+
+// GetBreadcrumbs returns the path from the root category down to this one
+func (h *CatalogHandler) GetBreadcrumbs(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	breadcrumbs, err := h.service.GetBreadcrumbs(id)
+	if err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, breadcrumbs)
+}
+
+// MoveCategory moves a category and its subtree under parent_id, or to the
+// root when parent_id is null
+func (h *CatalogHandler) MoveCategory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		ParentID *uuid.UUID `json:"parent_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	category, err := h.service.MoveCategory(id, req.ParentID, userID)
+	if err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// RetireCategory deactivates a category, moving its parts to
+// replacement_id and its subcategories up to its parent
+func (h *CatalogHandler) RetireCategory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		ReplacementID *uuid.UUID `json:"replacement_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	if err := h.service.RetireCategory(id, req.ReplacementID, userID); err != nil {
+		writeCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category retired"})
+}
+
+func writeCategoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, service.ErrCategoryCycle),
+		errors.Is(err, service.ErrCategoryTooDeep),
+		errors.Is(err, service.ErrInvalidReplacement):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCategoryInactive), errors.Is(err, service.ErrReplacementRequired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // Part endpoints
 func (h *CatalogHandler) CreatePart(c *gin.Context) {
 	var part models.LibraryPart
@@ -116,7 +243,7 @@ func (h *CatalogHandler) CreatePart(c *gin.Context) {
 	part.Status = "pending"
 
 	if err := h.service.CreatePart(&part); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAttributeError(c, err)
 		return
 	}
 
@@ -153,6 +280,39 @@ func (h *CatalogHandler) ListParts(c *gin.Context) {
 	c.JSON(http.StatusOK, parts)
 }
 
+// SearchSnapshot exports parts for a search reindex. Admin only.
+// Query params: after (cursor), limit (default 500, max 1000).
+func (h *CatalogHandler) SearchSnapshot(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	after := uuid.Nil
+	if raw := c.Query("after"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid after"})
+			return
+		}
+		after = parsed
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultSnapshotPageSize)))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return
+	}
+
+	page, err := h.service.SearchSnapshot(after, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
 func (h *CatalogHandler) ApprovePart(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -163,7 +323,7 @@ func (h *CatalogHandler) ApprovePart(c *gin.Context) {
 	userID, _ := auth.GetUserID(c)
 
 	if err := h.service.ApprovePart(id, userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAttributeError(c, err)
 		return
 	}
 
@@ -186,7 +346,9 @@ func (h *CatalogHandler) RejectPart(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.RejectPart(id, req.Reason); err != nil {
+	userID, _ := auth.GetUserID(c)
+
+	if err := h.service.RejectPart(id, req.Reason, userID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -204,6 +366,131 @@ func (h *CatalogHandler) GetPendingParts(c *gin.Context) {
 	c.JSON(http.StatusOK, parts)
 }
 
+// ListDuplicateCandidates is the catalog admin's duplicate review queue.
+// Query params: status (default pending), limit, offset.
+func (h *CatalogHandler) ListDuplicateCandidates(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	status := c.DefaultQuery("status", "pending")
+
+	candidates, err := h.service.ListDuplicateCandidates(status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, candidates)
+}
+
+// DetectDuplicates re-runs duplicate matching, e.g. after attributes were added
+func (h *CatalogHandler) DetectDuplicates(c *gin.Context) {
+	if !isCatalogAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "catalog admin access required"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	candidates, err := h.service.DetectDuplicates(id)
+	if err != nil {
+		writeDuplicateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, candidates)
+}
+
+func (h *CatalogHandler) MergePart(c *gin.Context) {
+	if !isCatalogAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "catalog admin access required"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		Into uuid.UUID `json:"into" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	if err := h.service.MergePart(id, req.Into, userID); err != nil {
+		if errors.Is(err, service.ErrReferencesNotRepointed) {
+			c.JSON(http.StatusAccepted, gin.H{"message": "part merged", "warning": err.Error()})
+			return
+		}
+		writeDuplicateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "part merged"})
+}
+
+// ReconcileMerges moves BOM, compatibility and listing references of merged
+// parts whose re-pointing failed when they were merged. Admin only.
+func (h *CatalogHandler) ReconcileMerges(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	reconciled, err := h.service.ReconcileMerges()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reconciled": reconciled})
+}
+
+func (h *CatalogHandler) DismissDuplicate(c *gin.Context) {
+	if !isCatalogAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "catalog admin access required"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	if err := h.service.DismissDuplicate(id, userID); err != nil {
+		writeDuplicateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "duplicate dismissed"})
+}
+
+func writeDuplicateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, service.ErrCannotMergeIntoSelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPartAlreadyMerged), errors.Is(err, service.ErrCandidateNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // Attribute endpoints
 func (h *CatalogHandler) CreateAttribute(c *gin.Context) {
 	var attribute models.Attribute
@@ -212,8 +499,10 @@ func (h *CatalogHandler) CreateAttribute(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.CreateAttribute(&attribute); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	userID, _ := auth.GetUserID(c)
+
+	if err := h.service.CreateAttribute(&attribute, userID); err != nil {
+		writeAttributeError(c, err)
 		return
 	}
 
@@ -229,3 +518,530 @@ func (h *CatalogHandler) ListAttributes(c *gin.Context) {
 
 	c.JSON(http.StatusOK, attributes)
 }
+
+// AddPartAttribute sets a part's value for an attribute. Values are validated
+// against the attribute's data type and converted to its unit. On an approved
+// part the change is returned as a revision awaiting review.
+func (h *CatalogHandler) AddPartAttribute(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var partAttribute models.PartAttribute
+	if err := c.ShouldBindJSON(&partAttribute); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	partAttribute.PartID = id
+
+	userID, _ := auth.GetUserID(c)
+
+	revision, err := h.service.AddPartAttribute(&partAttribute, userID)
+	if err != nil {
+		writeVersionError(c, err)
+		return
+	}
+	if revision != nil {
+		c.JSON(http.StatusAccepted, revision)
+		return
+	}
+
+	c.JSON(http.StatusOK, partAttribute)
+}
+
+// ValidatePartAttributes reports missing and invalid attributes for a part
+func (h *CatalogHandler) ValidatePartAttributes(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	validation, err := h.service.ValidatePartAttributes(id)
+	if err != nil {
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, validation)
+}
+
+// GetAttributeTemplate returns a category's attributes including those
+// inherited from parent categories
+func (h *CatalogHandler) GetAttributeTemplate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	template, err := h.service.GetAttributeTemplate(id)
+	if err != nil {
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (h *CatalogHandler) SetTemplateAttribute(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		AttributeID uuid.UUID `json:"attribute_id" binding:"required"`
+		IsRequired  *bool     `json:"is_required"`
+		SortOrder   int       `json:"sort_order"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	entry, err := h.service.SetTemplateAttribute(id, req.AttributeID, req.IsRequired, req.SortOrder, userID)
+	if err != nil {
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+func (h *CatalogHandler) RemoveTemplateAttribute(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	attributeID, err := uuid.Parse(c.Param("attributeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attribute id"})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	if err := h.service.RemoveTemplateAttribute(id, attributeID, userID); err != nil {
+		writeAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "attribute removed from template"})
+}
+
+func writeAttributeError(c *gin.Context, err error) {
+	var validationErr *service.AttributeValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":      err.Error(),
+			"validation": validationErr.Validation,
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, service.ErrInvalidAttribute),
+		errors.Is(err, service.ErrInvalidAttributeValue),
+		errors.Is(err, service.ErrAttributeNotInTemplate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// Cross reference endpoints
+func (h *CatalogHandler) ListCrossReferences(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	refs, err := h.service.ListCrossReferences(id)
+	if err != nil {
+		writeCrossReferenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, refs)
+}
+
+// AddCrossReference records how the part relates to related_part_id: it
+// supersedes it, is equivalent to it, or is an alternative under conditions
+func (h *CatalogHandler) AddCrossReference(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		RelatedPartID uuid.UUID `json:"related_part_id" binding:"required"`
+		RelationType  string    `json:"relation_type" binding:"required"`
+		Conditions    string    `json:"conditions"`
+		Notes         string    `json:"notes"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	ref := models.PartCrossReference{
+		PartID:        id,
+		RelatedPartID: req.RelatedPartID,
+		RelationType:  req.RelationType,
+		Conditions:    req.Conditions,
+		Notes:         req.Notes,
+		CreatedBy:     userID,
+	}
+	if err := h.service.AddCrossReference(&ref); err != nil {
+		writeCrossReferenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ref)
+}
+
+func (h *CatalogHandler) RemoveCrossReference(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.service.RemoveCrossReference(id); err != nil {
+		writeCrossReferenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "cross reference removed"})
+}
+
+// GetInterchange returns the part's current replacement and its alternatives
+func (h *CatalogHandler) GetInterchange(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	interchange, err := h.service.GetInterchange(id)
+	if err != nil {
+		writeCrossReferenceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, interchange)
+}
+
+// GetInterchanges resolves many parts at once, keyed by part ID
+func (h *CatalogHandler) GetInterchanges(c *gin.Context) {
+	var req struct {
+		PartIDs []uuid.UUID `json:"part_ids" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interchanges, err := h.service.GetInterchanges(req.PartIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, interchanges)
+}
+
+// LookupPartNumbers matches part numbers from external documents such as
+// BOM spreadsheets to approved catalog parts
+func (h *CatalogHandler) LookupPartNumbers(c *gin.Context) {
+	var req struct {
+		PartNumbers []string `json:"part_numbers" binding:"required,max=1000"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	matches, err := h.service.LookupPartNumbers(req.PartNumbers)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, matches)
+}
+
+func writeCrossReferenceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, service.ErrInvalidCrossReference):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCrossReferenceExists),
+		errors.Is(err, service.ErrAlreadySuperseded),
+		errors.Is(err, service.ErrSupersessionCycle),
+		errors.Is(err, service.ErrPartAlreadyMerged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// Change history endpoints
+
+// UpdatePart edits a part. Edits to approved parts are returned as a
+// revision awaiting review (202); others are applied directly.
+func (h *CatalogHandler) UpdatePart(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var update service.PartUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	revision, err := h.service.UpdatePart(id, &update, userID)
+	if err != nil {
+		writeVersionError(c, err)
+		return
+	}
+	if revision != nil {
+		c.JSON(http.StatusAccepted, revision)
+		return
+	}
+
+	part, err := h.service.GetPart(id)
+	if err != nil {
+		writeVersionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, part)
+}
+
+func (h *CatalogHandler) UpdateCategory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var update service.CategoryUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	category, err := h.service.UpdateCategory(id, &update, userID)
+	if err != nil {
+		writeVersionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *CatalogHandler) UpdateAttribute(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var update service.AttributeUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	attribute, err := h.service.UpdateAttribute(id, &update, userID)
+	if err != nil {
+		writeVersionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attribute)
+}
+
+// GetHistory returns a handler listing an entity's versions, newest first.
+// Query params: limit, offset.
+func (h *CatalogHandler) GetHistory(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+		versions, err := h.service.GetHistory(entityType, id, limit, offset)
+		if err != nil {
+			writeVersionError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, versions)
+	}
+}
+
+// Rollback returns a handler restoring an entity to an earlier version.
+// Catalog admins only; an approved part's rollback is held as a revision.
+func (h *CatalogHandler) Rollback(entityType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isCatalogAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "catalog admin access required"})
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		var req struct {
+			Version int `json:"version" binding:"required"`
+		}
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		userID, _ := auth.GetUserID(c)
+
+		revision, err := h.service.Rollback(entityType, id, req.Version, userID)
+		if err != nil {
+			writeVersionError(c, err)
+			return
+		}
+		if revision != nil {
+			c.JSON(http.StatusAccepted, revision)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "rolled back"})
+	}
+}
+
+// ListPartRevisions is the review queue for edits to approved parts.
+// Query params: status (default pending), limit, offset.
+func (h *CatalogHandler) ListPartRevisions(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	status := c.DefaultQuery("status", "pending")
+
+	revisions, err := h.service.ListPartRevisions(status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (h *CatalogHandler) ApprovePartRevision(c *gin.Context) {
+	if !isCatalogAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "catalog admin access required"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	if err := h.service.ApprovePartRevision(id, userID); err != nil {
+		writeVersionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "revision approved"})
+}
+
+func (h *CatalogHandler) RejectPartRevision(c *gin.Context) {
+	if !isCatalogAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "catalog admin access required"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	if err := h.service.RejectPartRevision(id, userID, req.Reason); err != nil {
+		writeVersionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "revision rejected"})
+}
+
+// isCatalogAdmin reports whether the user may review changes to the catalog
+func isCatalogAdmin(c *gin.Context) bool {
+	return auth.HasRole(c, "catalog_admin", "admin", "super_admin")
+}
+
+func writeVersionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSelfReview):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidEntityType),
+		errors.Is(err, service.ErrNoChanges),
+		errors.Is(err, service.ErrRetireRequired),
+		errors.Is(err, service.ErrCategoryTooDeep):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrRevisionNotPending),
+		errors.Is(err, service.ErrRevisionOutdated),
+		errors.Is(err, service.ErrPartChanged),
+		errors.Is(err, service.ErrCannotRestore),
+		errors.Is(err, service.ErrCategoryInactive),
+		errors.Is(err, service.ErrPartAlreadyMerged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		writeAttributeError(c, err)
+	}
+}
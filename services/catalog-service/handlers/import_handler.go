@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/b2b-platform/catalog-service/service"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImportHandler struct {
+	service *service.ImportService
+}
+
+func NewImportHandler(service *service.ImportService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+// StartImport accepts a multipart upload and queues it for processing.
+// Form fields: file (required), format (csv|xlsx, default from the file
+// extension), dry_run (bool) and mapping, a JSON object of header -> field.
+func (h *ImportHandler) StartImport(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	var mapping map[string]string
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mapping must be a JSON object of column to field"})
+			return
+		}
+	}
+
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	userID, _ := auth.GetUserID(c)
+
+	job, err := h.service.StartImport(fileHeader.Filename, c.PostForm("format"), file, mapping, dryRun, userID)
+	if err != nil {
+		writeImportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+func (h *ImportHandler) ListImports(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	jobs, err := h.service.ListJobs(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+func (h *ImportHandler) GetImport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	job, err := h.service.GetJob(id)
+	if err != nil {
+		writeImportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// ListImportErrors returns the per-row report of an import
+func (h *ImportHandler) ListImportErrors(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "500"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	if _, err := h.service.GetJob(id); err != nil {
+		writeImportError(c, err)
+		return
+	}
+
+	rowErrors, err := h.service.ListRowErrors(id, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rowErrors)
+}
+
+// ExportParts downloads the approved catalog. Query params: format (csv|xlsx, default csv).
+func (h *ImportHandler) ExportParts(c *gin.Context) {
+	format := c.DefaultQuery("format", service.FormatCSV)
+
+	contentType := "text/csv"
+	switch format {
+	case service.FormatCSV:
+	case service.FormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or xlsx"})
+		return
+	}
+
+	fileName := fmt.Sprintf("catalog-%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+	if err := h.service.ExportApprovedParts(format, c.Writer); err != nil {
+		// Headers may already be sent once rows are streamed
+		if !c.Writer.Written() {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Error(err)
+	}
+}
+
+func writeImportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, service.ErrInvalidImportFile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,30 @@
+-- Part numbers reduced to upper-case alphanumerics for duplicate matching
+ALTER TABLE catalog.library_parts
+ADD COLUMN IF NOT EXISTS normalized_part_number VARCHAR(255);
+
+UPDATE catalog.library_parts
+SET normalized_part_number = regexp_replace(upper(part_number), '[^A-Z0-9]', '', 'g')
+WHERE normalized_part_number IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_library_parts_normalized_part_number ON catalog.library_parts(normalized_part_number);
+
+-- Likely duplicates flagged for the catalog admin
+CREATE TABLE IF NOT EXISTS catalog.duplicate_candidates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    part_id UUID NOT NULL REFERENCES catalog.library_parts(id) ON DELETE CASCADE,
+    candidate_id UUID NOT NULL REFERENCES catalog.library_parts(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    part_number_score DOUBLE PRECISION,
+    manufacturer_score DOUBLE PRECISION,
+    name_score DOUBLE PRECISION,
+    attribute_score DOUBLE PRECISION,
+    status VARCHAR(50) DEFAULT 'pending',
+    reviewed_by UUID,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_duplicate_pair UNIQUE (part_id, candidate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_duplicate_candidates_status ON catalog.duplicate_candidates(status);
+CREATE INDEX IF NOT EXISTS idx_duplicate_candidates_score ON catalog.duplicate_candidates(score);
@@ -0,0 +1,29 @@
+-- Enum choices for attributes
+ALTER TABLE catalog.attributes
+ADD COLUMN IF NOT EXISTS allowed_values TEXT;
+
+-- Normalized part attribute values
+ALTER TABLE catalog.part_attributes
+ADD COLUMN IF NOT EXISTS raw_value TEXT;
+
+ALTER TABLE catalog.part_attributes
+ADD COLUMN IF NOT EXISTS numeric_value DOUBLE PRECISION;
+
+ALTER TABLE catalog.part_attributes
+ADD COLUMN IF NOT EXISTS numeric_max DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_part_attributes_numeric_value ON catalog.part_attributes(attribute_id, numeric_value);
+
+-- Attribute templates per category, inherited by subcategories
+CREATE TABLE IF NOT EXISTS catalog.category_attributes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    category_id UUID NOT NULL REFERENCES catalog.categories(id) ON DELETE CASCADE,
+    attribute_id UUID NOT NULL REFERENCES catalog.attributes(id) ON DELETE CASCADE,
+    is_required BOOLEAN DEFAULT false,
+    sort_order INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_category_attribute UNIQUE (category_id, attribute_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_attributes_category_id ON catalog.category_attributes(category_id);
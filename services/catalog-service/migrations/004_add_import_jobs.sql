@@ -0,0 +1,33 @@
+-- Bulk catalog import jobs
+CREATE TABLE IF NOT EXISTS catalog.import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    file_name VARCHAR(255),
+    format VARCHAR(10) NOT NULL,
+    column_mapping TEXT,
+    dry_run BOOLEAN DEFAULT false,
+    status VARCHAR(50) DEFAULT 'pending',
+    total_rows INTEGER DEFAULT 0,
+    processed_rows INTEGER DEFAULT 0,
+    created_count INTEGER DEFAULT 0,
+    updated_count INTEGER DEFAULT 0,
+    error_count INTEGER DEFAULT 0,
+    error_message TEXT,
+    created_by UUID NOT NULL,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status ON catalog.import_jobs(status);
+
+CREATE TABLE IF NOT EXISTS catalog.import_row_errors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_id UUID NOT NULL REFERENCES catalog.import_jobs(id) ON DELETE CASCADE,
+    row_number INTEGER NOT NULL,
+    "column" VARCHAR(255),
+    message TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_row_errors_job_id ON catalog.import_row_errors(job_id);
@@ -0,0 +1,20 @@
+-- Supersession and interchangeability between library parts
+CREATE TABLE IF NOT EXISTS catalog.part_cross_references (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    part_id UUID NOT NULL REFERENCES catalog.library_parts(id) ON DELETE CASCADE,
+    related_part_id UUID NOT NULL REFERENCES catalog.library_parts(id) ON DELETE CASCADE,
+    relation_type VARCHAR(50) NOT NULL,
+    conditions TEXT,
+    notes TEXT,
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_cross_reference UNIQUE (part_id, related_part_id, relation_type),
+    CONSTRAINT chk_cross_reference_distinct CHECK (part_id <> related_part_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_part_cross_references_related_part_id ON catalog.part_cross_references(related_part_id);
+
+-- A part is superseded by at most one part, so the chain to the current part is unambiguous
+CREATE UNIQUE INDEX IF NOT EXISTS idx_part_cross_references_superseded
+    ON catalog.part_cross_references(related_part_id) WHERE relation_type = 'supersedes';
@@ -0,0 +1,33 @@
+-- Change history for parts, attributes and categories
+CREATE TABLE IF NOT EXISTS catalog.catalog_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+    version INTEGER NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    snapshot JSONB,
+    diff JSONB,
+    actor_id UUID,
+    comment TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_catalog_version UNIQUE (entity_type, entity_id, version)
+);
+
+-- Edits to approved parts held for review
+CREATE TABLE IF NOT EXISTS catalog.part_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    part_id UUID NOT NULL REFERENCES catalog.library_parts(id) ON DELETE CASCADE,
+    base_version INTEGER NOT NULL,
+    changes JSONB,
+    diff JSONB,
+    status VARCHAR(50) DEFAULT 'pending',
+    submitted_by UUID NOT NULL,
+    reviewed_by UUID,
+    reviewed_at TIMESTAMP,
+    rejected_reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_part_revisions_part_id ON catalog.part_revisions(part_id);
+CREATE INDEX IF NOT EXISTS idx_part_revisions_status ON catalog.part_revisions(status);
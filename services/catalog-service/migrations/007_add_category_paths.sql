@@ -0,0 +1,22 @@
+-- Materialized category paths for subtree queries
+ALTER TABLE catalog.categories
+ADD COLUMN IF NOT EXISTS path TEXT;
+
+ALTER TABLE catalog.categories
+ADD COLUMN IF NOT EXISTS depth INTEGER DEFAULT 0;
+
+WITH RECURSIVE tree AS (
+    SELECT id, '/' || id::text || '/' AS path, 0 AS depth
+    FROM catalog.categories
+    WHERE parent_id IS NULL
+    UNION ALL
+    SELECT c.id, tree.path || c.id::text || '/', tree.depth + 1
+    FROM catalog.categories c
+    JOIN tree ON c.parent_id = tree.id
+)
+UPDATE catalog.categories c
+SET path = tree.path, depth = tree.depth
+FROM tree
+WHERE c.id = tree.id AND c.path IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_categories_path ON catalog.categories(path text_pattern_ops);
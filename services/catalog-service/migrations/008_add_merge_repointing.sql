@@ -0,0 +1,4 @@
+-- Tracks whether other services' references to a merged part were moved to its target.
+-- Parts merged before this column existed are left NULL so reconciliation re-points them.
+ALTER TABLE catalog.library_parts
+ADD COLUMN IF NOT EXISTS references_repointed_at TIMESTAMP;
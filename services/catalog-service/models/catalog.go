@@ -32,6 +32,8 @@ type Category struct {
 	Code        string    `gorm:"type:varchar(100);unique" json:"code"`
 	Description string    `gorm:"type:text" json:"description"`
 	ParentID    *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Path        string    `gorm:"type:text" json:"path"` // Materialized path of ancestor IDs and its own: "/<root>/.../<id>/"
+	Depth       int       `gorm:"default:0" json:"depth"`     // 0 for root categories
 	IsActive    bool      `gorm:"default:true" json:"is_active"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -50,8 +52,9 @@ type Attribute struct {
 	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Name        string    `gorm:"type:varchar(255);not null;unique" json:"name"`
 	Code        string    `gorm:"type:varchar(100);unique" json:"code"`
-	DataType    string    `gorm:"type:varchar(50);not null" json:"data_type"` // string, number, boolean, date
-	Unit        string    `gorm:"type:varchar(50)" json:"unit"`
+	DataType    string    `gorm:"type:varchar(50);not null" json:"data_type"` // string, number, boolean, date, enum, range
+	Unit        string    `gorm:"type:varchar(50)" json:"unit"` // Canonical unit that number and range values are converted to
+	AllowedValues string  `gorm:"type:text" json:"allowed_values,omitempty"` // Comma-separated choices for enum attributes
 	IsRequired  bool      `gorm:"default:false" json:"is_required"`
 	IsSearchable bool     `gorm:"default:true" json:"is_searchable"`
 	CreatedAt   time.Time `json:"created_at"`
@@ -68,6 +71,7 @@ func (Attribute) TableName() string {
 type LibraryPart struct {
 	ID              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	PartNumber      string         `gorm:"type:varchar(255);not null;uniqueIndex:idx_part_number_mfr" json:"part_number"`
+	NormalizedPartNumber string    `gorm:"type:varchar(255);index" json:"normalized_part_number"` // upper-case alphanumerics only
 	ManufacturerID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"manufacturer_id"`
 	CategoryID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"category_id"`
 	Name            string         `gorm:"type:varchar(255);not null" json:"name"`
@@ -78,6 +82,8 @@ type LibraryPart struct {
 	RejectedReason  string         `gorm:"type:text" json:"rejected_reason,omitempty"`
 	IsDuplicate     bool           `gorm:"default:false;index" json:"is_duplicate"`
 	DuplicateOf     *uuid.UUID     `gorm:"type:uuid;index" json:"duplicate_of,omitempty"`
+	// Set once BOMs, compatibility mappings and listings of a merged part point at its target
+	ReferencesRepointedAt *time.Time `json:"references_repointed_at,omitempty"`
 	CreatedBy       uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
@@ -97,7 +103,10 @@ type PartAttribute struct {
 	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	PartID      uuid.UUID `gorm:"type:uuid;not null;index" json:"part_id"`
 	AttributeID uuid.UUID `gorm:"type:uuid;not null;index" json:"attribute_id"`
-	Value       string    `gorm:"type:text;not null" json:"value"`
+	Value       string    `gorm:"type:text;not null" json:"value"` // Normalized value, in the attribute's unit
+	RawValue    string    `gorm:"type:text" json:"raw_value,omitempty"` // Value as submitted
+	NumericValue *float64 `json:"numeric_value,omitempty"` // number, or lower bound of a range
+	NumericMax  *float64  `json:"numeric_max,omitempty"` // upper bound of a range
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
@@ -109,3 +118,71 @@ type PartAttribute struct {
 func (PartAttribute) TableName() string {
 	return "catalog.part_attributes"
 }
+
+// CategoryAttribute puts an attribute on a category's template. Templates are
+// inherited by subcategories, which can override IsRequired.
+type CategoryAttribute struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CategoryID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_category_attribute" json:"category_id"`
+	AttributeID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_category_attribute" json:"attribute_id"`
+	IsRequired  bool      `gorm:"default:false" json:"is_required"`
+	SortOrder   int       `gorm:"default:0" json:"sort_order"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationships
+	Attribute Attribute `gorm:"foreignKey:AttributeID" json:"attribute,omitempty"`
+}
+
+func (CategoryAttribute) TableName() string {
+	return "catalog.category_attributes"
+}
+
+// DuplicateCandidate is a likely duplicate found when a part is submitted,
+// waiting for a catalog admin to merge or dismiss it
+type DuplicateCandidate struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PartID            uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_duplicate_pair" json:"part_id"`      // Newly submitted part
+	CandidateID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_duplicate_pair" json:"candidate_id"` // Existing part it resembles
+	Score             float64    `gorm:"not null;index" json:"score"`                                         // 0..1
+	PartNumberScore   float64    `json:"part_number_score"`
+	ManufacturerScore float64    `json:"manufacturer_score"`
+	NameScore         float64    `json:"name_score"`
+	AttributeScore    *float64   `json:"attribute_score,omitempty"` // nil when the parts share no attributes
+	Status            string     `gorm:"type:varchar(50);default:'pending';index" json:"status"` // pending, merged, dismissed
+	ReviewedBy        *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt        *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Relationships
+	Part      LibraryPart `gorm:"foreignKey:PartID" json:"part,omitempty"`
+	Candidate LibraryPart `gorm:"foreignKey:CandidateID" json:"candidate,omitempty"`
+}
+
+func (DuplicateCandidate) TableName() string {
+	return "catalog.duplicate_candidates"
+}
+
+// PartCrossReference links two library parts. The relation reads from PartID
+// to RelatedPartID: PartID supersedes RelatedPartID, is interchangeable with
+// it, or can replace it when Conditions hold.
+type PartCrossReference struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PartID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_cross_reference" json:"part_id"`
+	RelatedPartID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_cross_reference;index" json:"related_part_id"`
+	RelationType  string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_cross_reference" json:"relation_type"` // supersedes, equivalent, alternative
+	Conditions    string    `gorm:"type:text" json:"conditions,omitempty"`                                          // When an alternative applies, e.g. "serial numbers after 4500"
+	Notes         string    `gorm:"type:text" json:"notes,omitempty"`
+	CreatedBy     uuid.UUID `gorm:"type:uuid" json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Relationships
+	Part        LibraryPart `gorm:"foreignKey:PartID" json:"part,omitempty"`
+	RelatedPart LibraryPart `gorm:"foreignKey:RelatedPartID" json:"related_part,omitempty"`
+}
+
+func (PartCrossReference) TableName() string {
+	return "catalog.part_cross_references"
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportJob is a bulk load of parts, manufacturers, categories and attribute
+// values from a CSV or XLSX file. Rows are processed in the background.
+type ImportJob struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	FileName      string     `gorm:"type:varchar(255)" json:"file_name"`
+	Format        string     `gorm:"type:varchar(10);not null" json:"format"` // csv, xlsx
+	ColumnMapping string     `gorm:"type:text" json:"column_mapping"`         // JSON object of file header -> field
+	DryRun        bool       `gorm:"default:false" json:"dry_run"`
+	Status        string     `gorm:"type:varchar(50);default:'pending';index" json:"status"` // pending, running, completed, failed
+	TotalRows     int        `gorm:"default:0" json:"total_rows"`
+	ProcessedRows int        `gorm:"default:0" json:"processed_rows"`
+	CreatedCount  int        `gorm:"default:0" json:"created_count"` // parts created, or that would be in a dry run
+	UpdatedCount  int        `gorm:"default:0" json:"updated_count"`
+	ErrorCount    int        `gorm:"default:0" json:"error_count"` // rows skipped because of errors
+	ErrorMessage  string     `gorm:"type:text" json:"error_message,omitempty"`
+	CreatedBy     uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (ImportJob) TableName() string {
+	return "catalog.import_jobs"
+}
+
+// ImportRowError is a validation error on one row of an import file
+type ImportRowError struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	JobID     uuid.UUID `gorm:"type:uuid;not null;index" json:"job_id"`
+	RowNumber int       `gorm:"not null" json:"row_number"` // 1-based, counting the header row
+	Column    string    `gorm:"type:varchar(255)" json:"column,omitempty"`
+	Message   string    `gorm:"type:text;not null" json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (ImportRowError) TableName() string {
+	return "catalog.import_row_errors"
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CatalogVersion records one change to a part, attribute or category: the
+// entity's state afterwards, what changed and who changed it
+type CatalogVersion struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EntityType string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_catalog_version" json:"entity_type"` // part, attribute, category
+	EntityID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_catalog_version" json:"entity_id"`
+	Version    int       `gorm:"not null;uniqueIndex:idx_catalog_version" json:"version"`
+	Action     string    `gorm:"type:varchar(50);not null" json:"action"` // create, update, approve, reject, merge, rollback
+	Snapshot   string    `gorm:"type:jsonb" json:"snapshot"`              // Entity state after the change
+	Diff       string    `gorm:"type:jsonb" json:"diff"`                  // Changed fields: {"field": {"old": ..., "new": ...}}
+	ActorID    uuid.UUID `gorm:"type:uuid" json:"actor_id"`
+	Comment    string    `gorm:"type:text" json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (CatalogVersion) TableName() string {
+	return "catalog.catalog_versions"
+}
+
+// PartRevision is an edit to an approved part waiting for review. The live
+// part keeps its approved data until the revision is approved.
+type PartRevision struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PartID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"part_id"`
+	BaseVersion    int        `gorm:"not null" json:"base_version"` // Part version the edit was made against
+	Changes        string     `gorm:"type:jsonb" json:"changes"`    // Proposed part state
+	Diff           string     `gorm:"type:jsonb" json:"diff"`
+	Status         string     `gorm:"type:varchar(50);default:'pending';index" json:"status"` // pending, approved, rejected
+	SubmittedBy    uuid.UUID  `gorm:"type:uuid;not null" json:"submitted_by"`
+	ReviewedBy     *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	RejectedReason string     `gorm:"type:text" json:"rejected_reason,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relationships
+	Part LibraryPart `gorm:"foreignKey:PartID" json:"part,omitempty"`
+}
+
+func (PartRevision) TableName() string {
+	return "catalog.part_revisions"
+}
@@ -39,3 +39,55 @@ func (r *AttributeRepository) GetPartAttributes(partID uuid.UUID) ([]models.Part
 	err := r.db.Preload("Attribute").Where("part_id = ?", partID).Find(&partAttributes).Error
 	return partAttributes, err
 }
+
+// SetPartAttribute stores a part's value for an attribute, replacing any
+// value it already has
+func (r *AttributeRepository) SetPartAttribute(partAttribute *models.PartAttribute) error {
+	var existing models.PartAttribute
+	err := r.db.Where("part_id = ? AND attribute_id = ?", partAttribute.PartID, partAttribute.AttributeID).
+		First(&existing).Error
+	if err == gorm.ErrRecordNotFound {
+		return r.db.Create(partAttribute).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	partAttribute.ID = existing.ID
+	partAttribute.CreatedAt = existing.CreatedAt
+	return r.db.Save(partAttribute).Error
+}
+
+// FindByNameOrCode matches an attribute by code or name, ignoring case
+func (r *AttributeRepository) FindByNameOrCode(value string) (*models.Attribute, error) {
+	var attribute models.Attribute
+	err := r.db.Where("UPPER(code) = UPPER(?) OR UPPER(name) = UPPER(?)", value, value).
+		First(&attribute).Error
+	return &attribute, err
+}
+
+// ListUsedByPartStatus returns the attributes that have values on parts in a status
+func (r *AttributeRepository) ListUsedByPartStatus(status string) ([]models.Attribute, error) {
+	var attributes []models.Attribute
+	err := r.db.Where("id IN (?)",
+		r.db.Model(&models.PartAttribute{}).
+			Select("DISTINCT part_attributes.attribute_id").
+			Joins("JOIN catalog.library_parts ON library_parts.id = part_attributes.part_id").
+			Where("library_parts.status = ? AND library_parts.deleted_at IS NULL", status)).
+		Order("code ASC").
+		Find(&attributes).Error
+	return attributes, err
+}
+
+func (r *AttributeRepository) Update(attribute *models.Attribute) error {
+	return r.db.Save(attribute).Error
+}
+
+// DeletePartAttributesExcept removes a part's values for attributes not in keep
+func (r *AttributeRepository) DeletePartAttributesExcept(partID uuid.UUID, keep []uuid.UUID) error {
+	query := r.db.Where("part_id = ?", partID)
+	if len(keep) > 0 {
+		query = query.Where("attribute_id NOT IN ?", keep)
+	}
+	return query.Delete(&models.PartAttribute{}).Error
+}
@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"strings"
+
 	"github.com/b2b-platform/catalog-service/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CategoryRepository struct {
@@ -14,8 +17,25 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
+// Create inserts a category under its parent's path
 func (r *CategoryRepository) Create(category *models.Category) error {
-	return r.db.Create(category).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		parentPath, depth := "/", 0
+		if category.ParentID != nil {
+			var parent models.Category
+			if err := tx.Select("id", "path", "depth").Where("id = ?", *category.ParentID).First(&parent).Error; err != nil {
+				return err
+			}
+			parentPath, depth = parent.Path, parent.Depth+1
+		}
+
+		if category.ID == uuid.Nil {
+			category.ID = uuid.New()
+		}
+		category.Path = parentPath + category.ID.String() + "/"
+		category.Depth = depth
+		return tx.Create(category).Error
+	})
 }
 
 func (r *CategoryRepository) GetByID(id uuid.UUID) (*models.Category, error) {
@@ -35,3 +55,181 @@ func (r *CategoryRepository) GetByCode(code string) (*models.Category, error) {
 	err := r.db.Where("code = ?", code).First(&category).Error
 	return &category, err
 }
+
+// ListTemplateAttributes returns the template entries defined directly on the given categories
+func (r *CategoryRepository) ListTemplateAttributes(categoryIDs []uuid.UUID) ([]models.CategoryAttribute, error) {
+	var entries []models.CategoryAttribute
+	err := r.db.Preload("Attribute").
+		Where("category_id IN ?", categoryIDs).
+		Order("sort_order ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// SetTemplateAttribute adds an attribute to a category template or updates its settings
+func (r *CategoryRepository) SetTemplateAttribute(entry *models.CategoryAttribute) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category_id"}, {Name: "attribute_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"is_required", "sort_order", "updated_at"}),
+	}).Create(entry).Error
+}
+
+func (r *CategoryRepository) RemoveTemplateAttribute(categoryID, attributeID uuid.UUID) error {
+	result := r.db.Where("category_id = ? AND attribute_id = ?", categoryID, attributeID).
+		Delete(&models.CategoryAttribute{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindByNameOrCode matches a category by code or name, ignoring case
+func (r *CategoryRepository) FindByNameOrCode(value string) (*models.Category, error) {
+	var category models.Category
+	err := r.db.Where("UPPER(code) = UPPER(?) OR UPPER(name) = UPPER(?)", value, value).
+		First(&category).Error
+	return &category, err
+}
+
+func (r *CategoryRepository) Update(category *models.Category) error {
+	return r.db.Save(category).Error
+}
+
+// ReplaceTemplate swaps a category's own template entries for the given ones
+func (r *CategoryRepository) ReplaceTemplate(categoryID uuid.UUID, entries []models.CategoryAttribute) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("category_id = ?", categoryID).
+			Delete(&models.CategoryAttribute{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.Omit("Attribute").Create(&entries).Error
+	})
+}
+
+// ListAll returns every category ordered for tree building, parents before children
+func (r *CategoryRepository) ListAll(includeInactive bool) ([]models.Category, error) {
+	var categories []models.Category
+	query := r.db.Order("depth ASC, name ASC")
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Find(&categories).Error
+	return categories, err
+}
+
+// ListSubtree returns the categories under a path, including its root
+func (r *CategoryRepository) ListSubtree(path string, includeInactive bool) ([]models.Category, error) {
+	var categories []models.Category
+	query := r.db.Where("path LIKE ?", path+"%").Order("depth ASC, name ASC")
+	if !includeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Find(&categories).Error
+	return categories, err
+}
+
+func (r *CategoryRepository) GetByIDs(ids []uuid.UUID) ([]models.Category, error) {
+	var categories []models.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+func (r *CategoryRepository) CountParts(categoryID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.LibraryPart{}).Where("category_id = ?", categoryID).Count(&count).Error
+	return count, err
+}
+
+// MaxSubtreeDepth returns the depth of the deepest category under a path
+func (r *CategoryRepository) MaxSubtreeDepth(path string) (int, error) {
+	var depth int
+	err := r.db.Model(&models.Category{}).
+		Select("COALESCE(MAX(depth), 0)").
+		Where("path LIKE ?", path+"%").
+		Scan(&depth).Error
+	return depth, err
+}
+
+// Move puts a category under a new parent, rewriting the paths of its whole subtree
+func (r *CategoryRepository) Move(category *models.Category, parent *models.Category) error {
+	newPath, newDepth := "/", 0
+	var parentID *uuid.UUID
+	if parent != nil {
+		newPath, newDepth = parent.Path, parent.Depth+1
+		parentID = &parent.ID
+	}
+	newPath += category.ID.String() + "/"
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Category{}).
+			Where("id = ?", category.ID).
+			Update("parent_id", parentID).Error; err != nil {
+			return err
+		}
+		if err := moveSubtree(tx, category.Path, newPath, newDepth-category.Depth); err != nil {
+			return err
+		}
+		category.ParentID = parentID
+		category.Path = newPath
+		category.Depth = newDepth
+		return nil
+	})
+}
+
+// Retire deactivates a category. Its parts are reassigned to the replacement
+// and its children move up to its parent. Returns the reassigned part IDs.
+func (r *CategoryRepository) Retire(category *models.Category, replacementID *uuid.UUID) ([]uuid.UUID, error) {
+	var partIDs []uuid.UUID
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.LibraryPart{}).
+			Where("category_id = ?", category.ID).
+			Pluck("id", &partIDs).Error; err != nil {
+			return err
+		}
+		if len(partIDs) > 0 {
+			if err := tx.Model(&models.LibraryPart{}).
+				Where("id IN ?", partIDs).
+				Update("category_id", *replacementID).Error; err != nil {
+				return err
+			}
+		}
+
+		var children []models.Category
+		if err := tx.Where("parent_id = ?", category.ID).Find(&children).Error; err != nil {
+			return err
+		}
+		parentPath := strings.TrimSuffix(category.Path, category.ID.String()+"/")
+		for _, child := range children {
+			if err := tx.Model(&models.Category{}).
+				Where("id = ?", child.ID).
+				Update("parent_id", category.ParentID).Error; err != nil {
+				return err
+			}
+			if err := moveSubtree(tx, child.Path, parentPath+child.ID.String()+"/", -1); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Category{}).
+			Where("id = ?", category.ID).
+			Update("is_active", false).Error
+	})
+	return partIDs, err
+}
+
+// moveSubtree swaps the path prefix of every category under oldPath
+func moveSubtree(tx *gorm.DB, oldPath, newPath string, depthDelta int) error {
+	return tx.Exec(`UPDATE catalog.categories
+		SET path = @new || substr(path, length(@old) + 1), depth = depth + @delta, updated_at = NOW()
+		WHERE path LIKE @prefix`,
+		map[string]interface{}{"old": oldPath, "new": newPath, "delta": depthDelta, "prefix": oldPath + "%"}).Error
+}
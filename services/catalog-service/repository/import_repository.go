@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"github.com/b2b-platform/catalog-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImportRepository struct {
+	db *gorm.DB
+}
+
+func NewImportRepository(db *gorm.DB) *ImportRepository {
+	return &ImportRepository{db: db}
+}
+
+func (r *ImportRepository) Create(job *models.ImportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *ImportRepository) GetByID(id uuid.UUID) (*models.ImportJob, error) {
+	var job models.ImportJob
+	err := r.db.Where("id = ?", id).First(&job).Error
+	return &job, err
+}
+
+func (r *ImportRepository) List(limit, offset int) ([]models.ImportJob, error) {
+	var jobs []models.ImportJob
+	query := r.db.Order("created_at DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+
+	err := query.Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *ImportRepository) Update(job *models.ImportJob) error {
+	return r.db.Save(job).Error
+}
+
+func (r *ImportRepository) AddRowErrors(rowErrors []models.ImportRowError) error {
+	if len(rowErrors) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(&rowErrors, 500).Error
+}
+
+func (r *ImportRepository) ListRowErrors(jobID uuid.UUID, limit, offset int) ([]models.ImportRowError, error) {
+	var rowErrors []models.ImportRowError
+	query := r.db.Where("job_id = ?", jobID).Order("row_number ASC, created_at ASC")
+
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+
+	err := query.Find(&rowErrors).Error
+	return rowErrors, err
+}
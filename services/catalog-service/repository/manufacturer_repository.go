@@ -35,3 +35,11 @@ func (r *ManufacturerRepository) GetByCode(code string) (*models.Manufacturer, e
 	err := r.db.Where("code = ?", code).First(&manufacturer).Error
 	return &manufacturer, err
 }
+
+// FindByNameOrCode matches a manufacturer by code or name, ignoring case
+func (r *ManufacturerRepository) FindByNameOrCode(value string) (*models.Manufacturer, error) {
+	var manufacturer models.Manufacturer
+	err := r.db.Where("UPPER(code) = UPPER(?) OR UPPER(name) = UPPER(?)", value, value).
+		First(&manufacturer).Error
+	return &manufacturer, err
+}
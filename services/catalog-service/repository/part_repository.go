@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/b2b-platform/catalog-service/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PartRepository struct {
@@ -41,10 +44,49 @@ func (r *PartRepository) List(limit, offset int, status string) ([]models.Librar
 	return parts, err
 }
 
+// ListAfter returns up to limit parts with their category and attributes,
+// with IDs after a cursor, in ID order
+func (r *PartRepository) ListAfter(after uuid.UUID, limit int) ([]models.LibraryPart, error) {
+	var parts []models.LibraryPart
+	err := r.db.Preload("Category").Preload("PartAttributes.Attribute").Where("id > ?", after).
+		Order("id ASC").Limit(limit).Find(&parts).Error
+	return parts, err
+}
+
 func (r *PartRepository) Update(part *models.LibraryPart) error {
 	return r.db.Save(part).Error
 }
 
+// PartTx holds the repositories a part change is written through, all bound
+// to the same transaction
+type PartTx struct {
+	Parts      *PartRepository
+	Attributes *AttributeRepository
+	Versions   *VersionRepository
+}
+
+// WithLockedPart runs fn inside a transaction holding a row lock on the part,
+// so its fields, attribute values and version are committed together and
+// concurrent changes cannot race for the same version number
+func (r *PartRepository) WithLockedPart(partID uuid.UUID, fn func(tx PartTx, part *models.LibraryPart) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			Where("id = ?", partID).First(&models.LibraryPart{}).Error; err != nil {
+			return err
+		}
+		repos := PartTx{
+			Parts:      &PartRepository{db: tx},
+			Attributes: &AttributeRepository{db: tx},
+			Versions:   &VersionRepository{db: tx},
+		}
+		part, err := repos.Parts.GetByID(partID)
+		if err != nil {
+			return err
+		}
+		return fn(repos, part)
+	})
+}
+
 func (r *PartRepository) FindDuplicate(partNumber string, manufacturerID uuid.UUID) (*models.LibraryPart, error) {
 	var part models.LibraryPart
 	err := r.db.Where("part_number = ? AND manufacturer_id = ? AND is_duplicate = ?", 
@@ -64,9 +106,288 @@ func (r *PartRepository) MarkAsDuplicate(partID, duplicateOf uuid.UUID) error {
 		}).Error
 }
 
+// ListMergedNotRepointed returns merged parts whose references elsewhere have
+// not yet been moved to the part they were merged into, oldest first
+func (r *PartRepository) ListMergedNotRepointed(limit int) ([]models.LibraryPart, error) {
+	var parts []models.LibraryPart
+	err := r.db.Where("status = ? AND references_repointed_at IS NULL", "merged").
+		Order("updated_at ASC").Limit(limit).Find(&parts).Error
+	return parts, err
+}
+
+func (r *PartRepository) MarkReferencesRepointed(partID uuid.UUID) error {
+	return r.db.Model(&models.LibraryPart{}).
+		Where("id = ?", partID).
+		Update("references_repointed_at", time.Now()).Error
+}
+
 func (r *PartRepository) GetPendingApproval() ([]models.LibraryPart, error) {
 	var parts []models.LibraryPart
 	err := r.db.Preload("Manufacturer").Preload("Category").
 		Where("status = ?", "pending").Find(&parts).Error
 	return parts, err
 }
+
+// FindMatchCandidates returns existing parts worth scoring against a new part:
+// those with the same normalized part number from any manufacturer, and those
+// from the same manufacturer in the same category
+func (r *PartRepository) FindMatchCandidates(part *models.LibraryPart, limit int) ([]models.LibraryPart, error) {
+	var parts []models.LibraryPart
+	err := r.db.Preload("Manufacturer").Preload("PartAttributes").
+		Where("id <> ? AND status <> ?", part.ID, "merged").
+		Where("normalized_part_number = ? OR (manufacturer_id = ? AND category_id = ?)",
+			part.NormalizedPartNumber, part.ManufacturerID, part.CategoryID).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&parts).Error
+	return parts, err
+}
+
+// Merge folds a duplicate part into the part it duplicates. Attributes the
+// target lacks are moved across; the rest are dropped with the duplicate.
+func (r *PartRepository) Merge(duplicateID, targetID, reviewedBy uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("part_id = ? AND attribute_id IN (?)", duplicateID,
+			tx.Model(&models.PartAttribute{}).Select("attribute_id").Where("part_id = ?", targetID)).
+			Delete(&models.PartAttribute{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.PartAttribute{}).
+			Where("part_id = ?", duplicateID).
+			Update("part_id", targetID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.LibraryPart{}).
+			Where("id = ?", duplicateID).
+			Updates(map[string]interface{}{
+				"is_duplicate": true,
+				"duplicate_of": targetID,
+				"status":       "merged",
+			}).Error; err != nil {
+			return err
+		}
+
+		// Parts previously merged into the duplicate now point at the target
+		if err := tx.Model(&models.LibraryPart{}).
+			Where("duplicate_of = ?", duplicateID).
+			Update("duplicate_of", targetID).Error; err != nil {
+			return err
+		}
+
+		if err := mergeCrossReferences(tx, duplicateID, targetID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&models.DuplicateCandidate{}).
+			Where("status = ? AND (part_id = ? OR candidate_id = ?)", "pending", duplicateID, duplicateID).
+			Updates(map[string]interface{}{
+				"status":      "merged",
+				"reviewed_by": reviewedBy,
+				"reviewed_at": now,
+			}).Error
+	})
+}
+
+// UnmarkDuplicate clears the duplicate flag. Merged parts keep theirs.
+func (r *PartRepository) UnmarkDuplicate(partID uuid.UUID) error {
+	return r.db.Model(&models.LibraryPart{}).
+		Where("id = ? AND status <> ?", partID, "merged").
+		Updates(map[string]interface{}{
+			"is_duplicate": false,
+			"duplicate_of": nil,
+		}).Error
+}
+
+// SaveDuplicateCandidates records the matches found for a part, replacing any
+// still pending from an earlier run
+func (r *PartRepository) SaveDuplicateCandidates(partID uuid.UUID, candidates []models.DuplicateCandidate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("part_id = ? AND status = ?", partID, "pending").
+			Delete(&models.DuplicateCandidate{}).Error; err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&candidates).Error
+	})
+}
+
+func (r *PartRepository) GetDuplicateCandidate(id uuid.UUID) (*models.DuplicateCandidate, error) {
+	var candidate models.DuplicateCandidate
+	err := r.db.Where("id = ?", id).First(&candidate).Error
+	return &candidate, err
+}
+
+func (r *PartRepository) ListDuplicateCandidates(status string, limit, offset int) ([]models.DuplicateCandidate, error) {
+	var candidates []models.DuplicateCandidate
+	query := r.db.Preload("Part.Manufacturer").Preload("Candidate.Manufacturer")
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+
+	err := query.Order("score DESC").Find(&candidates).Error
+	return candidates, err
+}
+
+func (r *PartRepository) UpdateDuplicateCandidate(candidate *models.DuplicateCandidate) error {
+	return r.db.Save(candidate).Error
+}
+
+// ListPendingCandidates returns a part's unreviewed matches, best first
+func (r *PartRepository) ListPendingCandidates(partID uuid.UUID) ([]models.DuplicateCandidate, error) {
+	var candidates []models.DuplicateCandidate
+	err := r.db.Where("part_id = ? AND status = ?", partID, "pending").
+		Order("score DESC").Find(&candidates).Error
+	return candidates, err
+}
+
+// FindByManufacturerPartNumber finds a part by manufacturer and normalized
+// part number, the key bulk imports upsert on
+func (r *PartRepository) FindByManufacturerPartNumber(manufacturerID uuid.UUID, normalizedPartNumber string) (*models.LibraryPart, error) {
+	var part models.LibraryPart
+	err := r.db.Where("manufacturer_id = ? AND normalized_part_number = ? AND status <> ?",
+		manufacturerID, normalizedPartNumber, "merged").
+		Order("created_at ASC").
+		First(&part).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &part, err
+}
+
+// ForEachByStatus walks parts with their manufacturer, category and
+// attributes in batches, for exports
+func (r *PartRepository) ForEachByStatus(status string, batchSize int, fn func([]models.LibraryPart) error) error {
+	var batch []models.LibraryPart
+	return r.db.Preload("Manufacturer").Preload("Category").Preload("PartAttributes.Attribute").
+		Where("status = ?", status).
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
+func (r *PartRepository) CreateCrossReference(ref *models.PartCrossReference) error {
+	return r.db.Create(ref).Error
+}
+
+func (r *PartRepository) GetCrossReference(id uuid.UUID) (*models.PartCrossReference, error) {
+	var ref models.PartCrossReference
+	err := r.db.Where("id = ?", id).First(&ref).Error
+	return &ref, err
+}
+
+func (r *PartRepository) DeleteCrossReference(id uuid.UUID) error {
+	return r.db.Delete(&models.PartCrossReference{}, id).Error
+}
+
+// ListCrossReferences returns the references touching any of the parts, in either direction
+func (r *PartRepository) ListCrossReferences(partIDs []uuid.UUID) ([]models.PartCrossReference, error) {
+	var refs []models.PartCrossReference
+	if len(partIDs) == 0 {
+		return refs, nil
+	}
+	err := r.db.Where("part_id IN ? OR related_part_id IN ?", partIDs, partIDs).
+		Order("created_at ASC").
+		Find(&refs).Error
+	return refs, err
+}
+
+// FindCrossReference finds a reference of a type between two parts, in either direction
+func (r *PartRepository) FindCrossReference(partID, relatedPartID uuid.UUID, relationType string) (*models.PartCrossReference, error) {
+	var ref models.PartCrossReference
+	err := r.db.Where("relation_type = ? AND ((part_id = ? AND related_part_id = ?) OR (part_id = ? AND related_part_id = ?))",
+		relationType, partID, relatedPartID, relatedPartID, partID).
+		First(&ref).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &ref, err
+}
+
+// GetPartsByIDs loads parts with their manufacturer
+func (r *PartRepository) GetPartsByIDs(ids []uuid.UUID) ([]models.LibraryPart, error) {
+	var parts []models.LibraryPart
+	if len(ids) == 0 {
+		return parts, nil
+	}
+	err := r.db.Preload("Manufacturer").Where("id IN ?", ids).Find(&parts).Error
+	return parts, err
+}
+
+// mergeCrossReferences moves a merged part's cross references onto the part
+// it was merged into, dropping any the target already has or that would
+// become self references
+func mergeCrossReferences(tx *gorm.DB, duplicateID, targetID uuid.UUID) error {
+	statements := []string{
+		`DELETE FROM catalog.part_cross_references
+		 WHERE (part_id = @dup AND related_part_id = @target) OR (part_id = @target AND related_part_id = @dup)`,
+		`DELETE FROM catalog.part_cross_references d
+		 WHERE d.part_id = @dup AND EXISTS (
+		     SELECT 1 FROM catalog.part_cross_references t
+		     WHERE t.part_id = @target AND t.related_part_id = d.related_part_id AND t.relation_type = d.relation_type)`,
+		`DELETE FROM catalog.part_cross_references d
+		 WHERE d.related_part_id = @dup AND EXISTS (
+		     SELECT 1 FROM catalog.part_cross_references t
+		     WHERE t.related_part_id = @target AND t.part_id = d.part_id AND t.relation_type = d.relation_type)`,
+		// The target keeps its own successor if both parts were superseded
+		`DELETE FROM catalog.part_cross_references
+		 WHERE related_part_id = @dup AND relation_type = 'supersedes' AND EXISTS (
+		     SELECT 1 FROM catalog.part_cross_references
+		     WHERE related_part_id = @target AND relation_type = 'supersedes')`,
+		`UPDATE catalog.part_cross_references SET part_id = @target, updated_at = NOW() WHERE part_id = @dup`,
+		`UPDATE catalog.part_cross_references SET related_part_id = @target, updated_at = NOW() WHERE related_part_id = @dup`,
+	}
+
+	args := map[string]interface{}{"dup": duplicateID, "target": targetID}
+	for _, statement := range statements {
+		if err := tx.Exec(statement, args).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListPartCrossReferences returns a part's references with both parts loaded
+func (r *PartRepository) ListPartCrossReferences(partID uuid.UUID) ([]models.PartCrossReference, error) {
+	var refs []models.PartCrossReference
+	err := r.db.Preload("Part.Manufacturer").Preload("RelatedPart.Manufacturer").
+		Where("part_id = ? OR related_part_id = ?", partID, partID).
+		Order("relation_type ASC, created_at ASC").
+		Find(&refs).Error
+	return refs, err
+}
+
+// ForEachInCategoryTree walks parts of a status in a category or any of its
+// descendants, with their category loaded
+func (r *PartRepository) ForEachInCategoryTree(path, status string, batchSize int, fn func([]models.LibraryPart) error) error {
+	var batch []models.LibraryPart
+	return r.db.Preload("Category").
+		Where("status = ? AND category_id IN (?)", status,
+			r.db.Model(&models.Category{}).Select("id").Where("path LIKE ?", path+"%")).
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
+// FindByNormalizedPartNumbers returns approved parts matching any of the
+// normalized part numbers, with their manufacturer
+func (r *PartRepository) FindByNormalizedPartNumbers(normalized []string) ([]models.LibraryPart, error) {
+	var parts []models.LibraryPart
+	if len(normalized) == 0 {
+		return parts, nil
+	}
+	err := r.db.Preload("Manufacturer").
+		Where("normalized_part_number IN ? AND status = ?", normalized, "approved").
+		Order("created_at ASC").
+		Find(&parts).Error
+	return parts, err
+}
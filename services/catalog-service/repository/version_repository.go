@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"github.com/b2b-platform/catalog-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VersionRepository struct {
+	db *gorm.DB
+}
+
+func NewVersionRepository(db *gorm.DB) *VersionRepository {
+	return &VersionRepository{db: db}
+}
+
+// Create stores a version, numbering it after the entity's latest one
+func (r *VersionRepository) Create(version *models.CatalogVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&models.CatalogVersion{}).
+			Select("COALESCE(MAX(version), 0)").
+			Where("entity_type = ? AND entity_id = ?", version.EntityType, version.EntityID).
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+		version.Version = latest + 1
+		return tx.Create(version).Error
+	})
+}
+
+func (r *VersionRepository) Get(entityType string, entityID uuid.UUID, version int) (*models.CatalogVersion, error) {
+	var v models.CatalogVersion
+	err := r.db.Where("entity_type = ? AND entity_id = ? AND version = ?", entityType, entityID, version).
+		First(&v).Error
+	return &v, err
+}
+
+// Latest returns the entity's current version number, 0 if it has none
+func (r *VersionRepository) Latest(entityType string, entityID uuid.UUID) (int, error) {
+	var latest int
+	err := r.db.Model(&models.CatalogVersion{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Scan(&latest).Error
+	return latest, err
+}
+
+// List returns an entity's history, newest first
+func (r *VersionRepository) List(entityType string, entityID uuid.UUID, limit, offset int) ([]models.CatalogVersion, error) {
+	var versions []models.CatalogVersion
+	query := r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("version DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+
+	err := query.Find(&versions).Error
+	return versions, err
+}
+
+func (r *VersionRepository) CreateRevision(revision *models.PartRevision) error {
+	return r.db.Create(revision).Error
+}
+
+func (r *VersionRepository) GetRevision(id uuid.UUID) (*models.PartRevision, error) {
+	var revision models.PartRevision
+	err := r.db.Where("id = ?", id).First(&revision).Error
+	return &revision, err
+}
+
+func (r *VersionRepository) ListRevisions(status string, limit, offset int) ([]models.PartRevision, error) {
+	var revisions []models.PartRevision
+	query := r.db.Preload("Part.Manufacturer").Order("created_at ASC")
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+
+	err := query.Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *VersionRepository) UpdateRevision(revision *models.PartRevision) error {
+	return r.db.Save(revision).Error
+}
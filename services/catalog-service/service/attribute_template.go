@@ -0,0 +1,231 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/b2b-platform/catalog-service/models"
+	"github.com/google/uuid"
+)
+
+// maxCategoryDepth guards the ancestor walk against a ParentID cycle
+const maxCategoryDepth = 32
+
+// TemplateAttribute is an attribute in a category's effective template
+type TemplateAttribute struct {
+	Attribute     models.Attribute `json:"attribute"`
+	IsRequired    bool             `json:"is_required"`
+	SortOrder     int              `json:"sort_order"`
+	DefinedOn     uuid.UUID        `json:"defined_on"` // Category whose template entry applies
+	InheritedFrom *uuid.UUID       `json:"inherited_from,omitempty"`
+}
+
+// GetAttributeTemplate returns a category's effective attribute template: its
+// own entries plus those inherited from its ancestors, nearest category winning
+func (s *CatalogService) GetAttributeTemplate(categoryID uuid.UUID) ([]TemplateAttribute, error) {
+	chain, err := s.categoryChain(categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.categoryRepo.ListTemplateAttributes(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	depth := make(map[uuid.UUID]int, len(chain))
+	for i, id := range chain {
+		depth[id] = i
+	}
+
+	// chain runs from the category up to the root, so a lower depth is nearer
+	byAttribute := make(map[uuid.UUID]models.CategoryAttribute)
+	for _, entry := range entries {
+		current, ok := byAttribute[entry.AttributeID]
+		if !ok || depth[entry.CategoryID] < depth[current.CategoryID] {
+			byAttribute[entry.AttributeID] = entry
+		}
+	}
+
+	template := make([]TemplateAttribute, 0, len(byAttribute))
+	for _, entry := range byAttribute {
+		ta := TemplateAttribute{
+			Attribute:  entry.Attribute,
+			IsRequired: entry.IsRequired,
+			SortOrder:  entry.SortOrder,
+			DefinedOn:  entry.CategoryID,
+		}
+		if entry.CategoryID != categoryID {
+			inheritedFrom := entry.CategoryID
+			ta.InheritedFrom = &inheritedFrom
+		}
+		template = append(template, ta)
+	}
+
+	sort.Slice(template, func(i, j int) bool {
+		if template[i].SortOrder != template[j].SortOrder {
+			return template[i].SortOrder < template[j].SortOrder
+		}
+		return template[i].Attribute.Name < template[j].Attribute.Name
+	})
+	return template, nil
+}
+
+// categoryChain lists a category and its ancestors, nearest first
+func (s *CatalogService) categoryChain(categoryID uuid.UUID) ([]uuid.UUID, error) {
+	var chain []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+
+	id := &categoryID
+	for id != nil {
+		if seen[*id] || len(chain) >= maxCategoryDepth {
+			return nil, fmt.Errorf("category %s has a cyclic or too deep parent chain", categoryID)
+		}
+		seen[*id] = true
+
+		category, err := s.categoryRepo.GetByID(*id)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, category.ID)
+		id = category.ParentID
+	}
+	return chain, nil
+}
+
+// SetTemplateAttribute adds an attribute to a category's template. A nil
+// isRequired falls back to the attribute's own IsRequired.
+func (s *CatalogService) SetTemplateAttribute(categoryID, attributeID uuid.UUID, isRequired *bool, sortOrder int, actorID uuid.UUID) (*models.CategoryAttribute, error) {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.snapshotCategory(category)
+	if err != nil {
+		return nil, err
+	}
+	attribute, err := s.attributeRepo.GetByID(attributeID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.CategoryAttribute{
+		CategoryID:  categoryID,
+		AttributeID: attributeID,
+		IsRequired:  attribute.IsRequired,
+		SortOrder:   sortOrder,
+	}
+	if isRequired != nil {
+		entry.IsRequired = *isRequired
+	}
+
+	if err := s.categoryRepo.SetTemplateAttribute(entry); err != nil {
+		return nil, err
+	}
+	entry.Attribute = *attribute
+	return entry, s.recordTemplateChange(categoryID, before, actorID)
+}
+
+func (s *CatalogService) RemoveTemplateAttribute(categoryID, attributeID, actorID uuid.UUID) error {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return err
+	}
+	before, err := s.snapshotCategory(category)
+	if err != nil {
+		return err
+	}
+	if err := s.categoryRepo.RemoveTemplateAttribute(categoryID, attributeID); err != nil {
+		return err
+	}
+	return s.recordTemplateChange(categoryID, before, actorID)
+}
+
+// ValidatePartAttributes checks a part against its category template: every
+// required attribute must be present and every value must match its data type
+func (s *CatalogService) ValidatePartAttributes(partID uuid.UUID) (*AttributeValidation, error) {
+	part, err := s.partRepo.GetByID(partID)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := s.GetAttributeTemplate(part.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	validation := &AttributeValidation{
+		PartID:  part.ID,
+		Missing: []AttributeIssue{},
+		Invalid: []AttributeIssue{},
+	}
+
+	present := make(map[uuid.UUID]bool, len(part.PartAttributes))
+	for _, pa := range part.PartAttributes {
+		present[pa.AttributeID] = true
+
+		check := pa
+		check.RawValue = ""
+		if err := NormalizePartAttribute(&pa.Attribute, &check); err != nil {
+			validation.Invalid = append(validation.Invalid, AttributeIssue{
+				AttributeID: pa.AttributeID,
+				Code:        pa.Attribute.Code,
+				Name:        pa.Attribute.Name,
+				Value:       pa.Value,
+				Error:       err.Error(),
+			})
+		}
+	}
+
+	for _, ta := range template {
+		if ta.IsRequired && !present[ta.Attribute.ID] {
+			validation.Missing = append(validation.Missing, AttributeIssue{
+				AttributeID: ta.Attribute.ID,
+				Code:        ta.Attribute.Code,
+				Name:        ta.Attribute.Name,
+				Error:       "required attribute is missing",
+			})
+		}
+	}
+
+	validation.Valid = len(validation.Missing) == 0 && len(validation.Invalid) == 0
+	return validation, nil
+}
+
+// normalizePartAttributes validates the attributes submitted with a new part
+func (s *CatalogService) normalizePartAttributes(part *models.LibraryPart) error {
+	if len(part.PartAttributes) == 0 {
+		return nil
+	}
+
+	template, err := s.GetAttributeTemplate(part.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	for i := range part.PartAttributes {
+		pa := &part.PartAttributes[i]
+		attribute, err := s.templateAttribute(template, pa.AttributeID)
+		if err != nil {
+			return err
+		}
+		if err := NormalizePartAttribute(attribute, pa); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateAttribute finds an attribute in a category template. Categories
+// without a template accept any attribute.
+func (s *CatalogService) templateAttribute(template []TemplateAttribute, attributeID uuid.UUID) (*models.Attribute, error) {
+	for i := range template {
+		if template[i].Attribute.ID == attributeID {
+			return &template[i].Attribute, nil
+		}
+	}
+	if len(template) > 0 {
+		return nil, ErrAttributeNotInTemplate
+	}
+	return s.attributeRepo.GetByID(attributeID)
+}
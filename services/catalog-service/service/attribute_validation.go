@@ -0,0 +1,217 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/catalog-service/models"
+	"github.com/b2b-platform/shared/units"
+	"github.com/google/uuid"
+)
+
+// Attribute data types
+const (
+	DataTypeString  = "string"
+	DataTypeNumber  = "number"
+	DataTypeBoolean = "boolean"
+	DataTypeDate    = "date"
+	DataTypeEnum    = "enum"
+	DataTypeRange   = "range"
+)
+
+var (
+	ErrInvalidAttribute       = errors.New("invalid attribute")
+	ErrInvalidAttributeValue  = errors.New("invalid attribute value")
+	ErrAttributeNotInTemplate = errors.New("attribute is not part of the category template")
+)
+
+// Units of measure come from the shared units package, so that search
+// converts query values exactly as attribute values are converted here
+var rangePattern = regexp.MustCompile(`^(.+?)\s*(?:\.\.|–|\bto\b|-)\s*([-+]?(?:\d|\.\d).*)$`)
+
+// ValidateAttributeDefinition checks an attribute's data type, unit and enum choices
+func ValidateAttributeDefinition(attr *models.Attribute) error {
+	attr.DataType = strings.ToLower(strings.TrimSpace(attr.DataType))
+	switch attr.DataType {
+	case DataTypeString, DataTypeBoolean, DataTypeDate:
+	case DataTypeNumber, DataTypeRange:
+		if attr.Unit != "" {
+			if _, ok := units.Lookup(attr.Unit); !ok {
+				return fmt.Errorf("%w: unknown unit %q", ErrInvalidAttribute, attr.Unit)
+			}
+		}
+	case DataTypeEnum:
+		if len(splitList(attr.AllowedValues)) == 0 {
+			return fmt.Errorf("%w: enum attributes need allowed_values", ErrInvalidAttribute)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported data_type %q", ErrInvalidAttribute, attr.DataType)
+	}
+	return nil
+}
+
+// NormalizePartAttribute validates a submitted value against its attribute's
+// data type and rewrites it in canonical form. Number and range values are
+// converted to the attribute's unit, e.g. "1 in" becomes "25.4" for a mm attribute.
+func NormalizePartAttribute(attr *models.Attribute, pa *models.PartAttribute) error {
+	raw := strings.TrimSpace(pa.RawValue)
+	if raw == "" {
+		raw = strings.TrimSpace(pa.Value)
+	}
+	if raw == "" {
+		return fmt.Errorf("%w: %s is empty", ErrInvalidAttributeValue, attr.Name)
+	}
+
+	pa.RawValue = raw
+	pa.NumericValue = nil
+	pa.NumericMax = nil
+
+	switch strings.ToLower(attr.DataType) {
+	case DataTypeString, "":
+		pa.Value = raw
+
+	case DataTypeNumber:
+		v, err := quantityIn(raw, attr.Unit)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidAttributeValue, attr.Name, err)
+		}
+		pa.Value = formatNumber(v)
+		pa.NumericValue = &v
+
+	case DataTypeRange:
+		lo, hi, err := parseRange(raw, attr.Unit)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidAttributeValue, attr.Name, err)
+		}
+		pa.Value = formatNumber(lo) + ".." + formatNumber(hi)
+		pa.NumericValue = &lo
+		pa.NumericMax = &hi
+
+	case DataTypeBoolean:
+		switch strings.ToLower(raw) {
+		case "true", "yes", "y", "1", "on":
+			pa.Value = "true"
+		case "false", "no", "n", "0", "off":
+			pa.Value = "false"
+		default:
+			return fmt.Errorf("%w: %s: %q is not a boolean", ErrInvalidAttributeValue, attr.Name, raw)
+		}
+
+	case DataTypeDate:
+		d, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return fmt.Errorf("%w: %s: dates must be YYYY-MM-DD", ErrInvalidAttributeValue, attr.Name)
+		}
+		pa.Value = d.Format("2006-01-02")
+
+	case DataTypeEnum:
+		allowed := splitList(attr.AllowedValues)
+		matched := ""
+		for _, choice := range allowed {
+			if strings.EqualFold(choice, raw) {
+				matched = choice
+				break
+			}
+		}
+		if matched == "" {
+			return fmt.Errorf("%w: %s must be one of %s", ErrInvalidAttributeValue, attr.Name, strings.Join(allowed, ", "))
+		}
+		pa.Value = matched
+
+	default:
+		return fmt.Errorf("%w: %s has unsupported data type %q", ErrInvalidAttributeValue, attr.Name, attr.DataType)
+	}
+	return nil
+}
+
+// quantityIn parses "12.5", "12.5 mm" or "0.5in" into the target unit
+func quantityIn(s, targetUnit string) (float64, error) {
+	v, unitSymbol, err := units.ParseQuantity(s)
+	if err != nil {
+		return 0, err
+	}
+	if unitSymbol == "" {
+		return v, nil
+	}
+	if targetUnit == "" {
+		return 0, fmt.Errorf("unit %q given but the attribute has no unit", unitSymbol)
+	}
+	return units.Convert(v, unitSymbol, targetUnit)
+}
+
+// parseRange parses "10-20 mm", "10 mm .. 20 mm" or "0.5 to 1 in"
+func parseRange(s, targetUnit string) (float64, float64, error) {
+	m := rangePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, 0, fmt.Errorf("%q is not a range like 10-20", s)
+	}
+
+	lower, upper := strings.TrimSpace(m[1]), strings.TrimSpace(m[2])
+	// "10-20 mm" puts the unit on the upper bound only
+	if _, upperUnit, err := units.ParseQuantity(upper); err == nil && upperUnit != "" {
+		if _, lowerUnit, err := units.ParseQuantity(lower); err == nil && lowerUnit == "" {
+			lower += " " + upperUnit
+		}
+	}
+
+	lo, err := quantityIn(lower, targetUnit)
+	if err != nil {
+		return 0, 0, err
+	}
+	hi, err := quantityIn(upper, targetUnit)
+	if err != nil {
+		return 0, 0, err
+	}
+	if lo > hi {
+		return 0, 0, fmt.Errorf("lower bound %s is above upper bound %s", formatNumber(lo), formatNumber(hi))
+	}
+	return lo, hi, nil
+}
+
+// formatNumber drops float noise from unit conversion, keeping 6 decimals
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e6)/1e6, 'f', -1, 64)
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// AttributeIssue is a problem with one attribute on a part
+type AttributeIssue struct {
+	AttributeID uuid.UUID `json:"attribute_id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Value       string    `json:"value,omitempty"`
+	Error       string    `json:"error"`
+}
+
+// AttributeValidation reports a part's attributes against its category template
+type AttributeValidation struct {
+	PartID  uuid.UUID        `json:"part_id"`
+	Valid   bool             `json:"valid"`
+	Missing []AttributeIssue `json:"missing"`
+	Invalid []AttributeIssue `json:"invalid"`
+}
+
+// AttributeValidationError is returned when a part cannot be approved because
+// of missing or invalid attributes
+type AttributeValidationError struct {
+	Validation *AttributeValidation
+}
+
+func (e *AttributeValidationError) Error() string {
+	return fmt.Sprintf("part has %d missing and %d invalid attributes",
+		len(e.Validation.Missing), len(e.Validation.Invalid))
+}
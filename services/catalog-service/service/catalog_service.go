@@ -1,6 +1,11 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
 	"time"
 
 	"github.com/b2b-platform/catalog-service/models"
@@ -14,7 +19,9 @@ type CatalogService struct {
 	categoryRepo     *repository.CategoryRepository
 	partRepo         *repository.PartRepository
 	attributeRepo    *repository.AttributeRepository
+	versionRepo      *repository.VersionRepository
 	eventBus         events.EventBus
+	partReferences   []PartReferences
 }
 
 func NewCatalogService(
@@ -22,6 +29,7 @@ func NewCatalogService(
 	categoryRepo *repository.CategoryRepository,
 	partRepo *repository.PartRepository,
 	attributeRepo *repository.AttributeRepository,
+	versionRepo *repository.VersionRepository,
 	eventBus events.EventBus,
 ) *CatalogService {
 	return &CatalogService{
@@ -29,6 +37,7 @@ func NewCatalogService(
 		categoryRepo:     categoryRepo,
 		partRepo:         partRepo,
 		attributeRepo:    attributeRepo,
+		versionRepo:      versionRepo,
 		eventBus:         eventBus,
 	}
 }
@@ -45,8 +54,16 @@ func (s *CatalogService) ListManufacturers() ([]models.Manufacturer, error) {
 	return s.manufacturerRepo.List()
 }
 
-func (s *CatalogService) CreateCategory(category *models.Category) error {
-	return s.categoryRepo.Create(category)
+func (s *CatalogService) CreateCategory(category *models.Category, actorID uuid.UUID) error {
+	if err := s.categoryRepo.Create(category); err != nil {
+		return err
+	}
+
+	after, err := s.snapshotCategory(category)
+	if err != nil {
+		return err
+	}
+	return s.recordVersion(EntityCategory, category.ID, ActionCreate, nil, after, actorID, "")
 }
 
 func (s *CatalogService) GetCategory(id uuid.UUID) (*models.Category, error) {
@@ -57,8 +74,14 @@ func (s *CatalogService) ListCategories() ([]models.Category, error) {
 	return s.categoryRepo.List()
 }
 
-func (s *CatalogService) CreateAttribute(attribute *models.Attribute) error {
-	return s.attributeRepo.Create(attribute)
+func (s *CatalogService) CreateAttribute(attribute *models.Attribute, actorID uuid.UUID) error {
+	if err := ValidateAttributeDefinition(attribute); err != nil {
+		return err
+	}
+	if err := s.attributeRepo.Create(attribute); err != nil {
+		return err
+	}
+	return s.recordVersion(EntityAttribute, attribute.ID, ActionCreate, nil, snapshotAttribute(attribute), actorID, "")
 }
 
 func (s *CatalogService) ListAttributes() ([]models.Attribute, error) {
@@ -66,21 +89,26 @@ func (s *CatalogService) ListAttributes() ([]models.Attribute, error) {
 }
 
 func (s *CatalogService) CreatePart(part *models.LibraryPart) error {
-	// Check for duplicates
-	existing, err := s.partRepo.FindDuplicate(part.PartNumber, part.ManufacturerID)
-	if err != nil && err.Error() != "record not found" {
+	part.NormalizedPartNumber = NormalizePartNumber(part.PartNumber)
+
+	if err := s.normalizePartAttributes(part); err != nil {
 		return err
 	}
 
-	if existing != nil {
-		// Mark new part as duplicate
-		if err := s.partRepo.Create(part); err != nil {
-			return err
-		}
-		return s.partRepo.MarkAsDuplicate(part.ID, existing.ID)
+	if err := s.partRepo.Create(part); err != nil {
+		return err
+	}
+
+	if err := s.recordVersion(EntityPart, part.ID, ActionCreate, nil, snapshotPart(part), part.CreatedBy, ""); err != nil {
+		return err
+	}
+
+	if err := s.publishPartChanged(events.EventCatalogPartCreated, part); err != nil {
+		return err
 	}
 
-	return s.partRepo.Create(part)
+	_, err := s.DetectDuplicates(part.ID)
+	return err
 }
 
 func (s *CatalogService) GetPart(id uuid.UUID) (*models.LibraryPart, error) {
@@ -97,46 +125,466 @@ func (s *CatalogService) ApprovePart(partID, approvedBy uuid.UUID) error {
 		return err
 	}
 
-	now := time.Now()
-	part.Status = "approved"
-	part.ApprovedAt = &now
-	part.ApprovedBy = &approvedBy
+	validation, err := s.ValidatePartAttributes(part.ID)
+	if err != nil {
+		return err
+	}
+	if !validation.Valid {
+		return &AttributeValidationError{Validation: validation}
+	}
 
-	if err := s.partRepo.Update(part); err != nil {
+	err = s.withLockedPart(part.ID, func(tx *CatalogService, locked *models.LibraryPart) error {
+		part = locked
+		before := snapshotPart(part)
+
+		now := time.Now()
+		part.Status = "approved"
+		part.ApprovedAt = &now
+		part.ApprovedBy = &approvedBy
+
+		if err := tx.partRepo.Update(part); err != nil {
+			return err
+		}
+		return tx.recordVersion(EntityPart, part.ID, ActionApprove, before, snapshotPart(part), approvedBy, "")
+	})
+	if err != nil {
 		return err
 	}
 
-	// Publish event
-	event := events.NewEventEnvelope(
-		events.EventCatalogPartApproved,
+	return s.publishPartApproved(part)
+}
+
+// publishPartApproved (re)indexes an approved part for search
+func (s *CatalogService) publishPartApproved(part *models.LibraryPart) error {
+	if err := s.publishPartChanged(events.EventCatalogPartApproved, part); err != nil {
+		return err
+	}
+
+	// The approved event replaces the part's search document, so re-announce its cross references
+	return s.publishInterchangeUpdates([]uuid.UUID{part.ID})
+}
+
+// publishPartChanged announces a part's current state. Search keeps only
+// approved parts, so events for parts in any other status remove them.
+func (s *CatalogService) publishPartChanged(eventType events.EventType, part *models.LibraryPart) error {
+	partAttributes, err := s.attributeRepo.GetPartAttributes(part.ID)
+	if err != nil {
+		return err
+	}
+
+	event, err := s.partEvent(eventType, part, partAttributes)
+	if err != nil {
+		return err
+	}
+	return s.eventBus.Publish(nil, event)
+}
+
+func (s *CatalogService) partEvent(eventType events.EventType, part *models.LibraryPart, partAttributes []models.PartAttribute) (*events.EventEnvelope, error) {
+	category := &part.Category
+	if category.ID != part.CategoryID {
+		var err error
+		if category, err = s.categoryRepo.GetByID(part.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	return events.NewEventEnvelope(
+		eventType,
 		"catalog-service",
 		map[string]interface{}{
 			"part_id":     part.ID.String(),
 			"part_number": part.PartNumber,
 			"name":        part.Name,
+			"status":      part.Status,
 			"manufacturer_id": part.ManufacturerID.String(),
+			"category":      category.Name,
+			"category_id":   category.ID.String(),
+			"category_path": categoryPathIDs(category.Path),
+			"attributes":    searchableAttributes(partAttributes),
 		},
-	)
+	).WithVersion(events.VersionAt(part.UpdatedAt)), nil
+}
 
-	return s.eventBus.Publish(nil, event)
+// searchableAttributes lists the part's values for searchable attributes,
+// with their numbers in the attribute's unit, for parametric search
+func searchableAttributes(partAttributes []models.PartAttribute) []map[string]interface{} {
+	values := []map[string]interface{}{}
+	for _, pa := range partAttributes {
+		if !pa.Attribute.IsSearchable {
+			continue
+		}
+
+		code := pa.Attribute.Code
+		if code == "" {
+			code = pa.AttributeID.String()
+		}
+		value := map[string]interface{}{
+			"code":      code,
+			"name":      pa.Attribute.Name,
+			"data_type": pa.Attribute.DataType,
+			"unit":      pa.Attribute.Unit,
+			"value":     pa.Value,
+		}
+		if pa.NumericValue != nil {
+			// A number is a range with equal bounds
+			value["number"] = *pa.NumericValue
+			value["number_max"] = *pa.NumericValue
+			if pa.NumericMax != nil {
+				value["number_max"] = *pa.NumericMax
+			}
+		}
+		values = append(values, value)
+	}
+	return values
 }
 
-func (s *CatalogService) RejectPart(partID uuid.UUID, reason string) error {
-	part, err := s.partRepo.GetByID(partID)
+// Snapshot page sizes bound how much one export request reads
+const (
+	DefaultSnapshotPageSize = 500
+	MaxSnapshotPageSize     = 1000
+)
+
+// snapshotInterchangeChunk is how many cross referenced parts of a snapshot
+// page resolve their interchange together, leaving the bounded graph room
+// to reach their neighbours
+const snapshotInterchangeChunk = 25
+
+// SearchSnapshot exports parts, with their interchange, as the events
+// announcing their current state so search can rebuild its index. Pages
+// follow ID order from the after cursor.
+func (s *CatalogService) SearchSnapshot(after uuid.UUID, limit int) (*events.SnapshotPage, error) {
+	if limit <= 0 {
+		limit = DefaultSnapshotPageSize
+	}
+	if limit > MaxSnapshotPageSize {
+		limit = MaxSnapshotPageSize
+	}
+
+	parts, err := s.partRepo.ListAfter(after, limit)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(parts))
+	inPage := make(map[uuid.UUID]bool, len(parts))
+	for i, part := range parts {
+		ids[i] = part.ID
+		inPage[part.ID] = true
+	}
+
+	refs, err := s.partRepo.ListCrossReferences(ids)
+	if err != nil {
+		return nil, err
 	}
+	linked := []uuid.UUID{}
+	seen := make(map[uuid.UUID]bool)
+	for _, ref := range refs {
+		for _, id := range []uuid.UUID{ref.PartID, ref.RelatedPartID} {
+			if inPage[id] && !seen[id] {
+				seen[id] = true
+				linked = append(linked, id)
+			}
+		}
+	}
+
+	interchanges := make(map[uuid.UUID]*PartInterchange, len(linked))
+	for start := 0; start < len(linked); start += snapshotInterchangeChunk {
+		end := start + snapshotInterchangeChunk
+		if end > len(linked) {
+			end = len(linked)
+		}
+		resolved, err := s.GetInterchanges(linked[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for id, interchange := range resolved {
+			interchanges[id] = interchange
+		}
+	}
+
+	page := &events.SnapshotPage{Events: make([]*events.EventEnvelope, 0, len(parts))}
+	for i := range parts {
+		event, err := s.partEvent(events.EventCatalogPartUpdated, &parts[i], parts[i].PartAttributes)
+		if err != nil {
+			return nil, err
+		}
+		if interchange, ok := interchanges[parts[i].ID]; ok {
+			for key, value := range interchangePayload(interchange) {
+				event.Payload[key] = value
+			}
+		}
+		page.Events = append(page.Events, event)
+	}
+	if len(parts) == limit {
+		page.NextAfter = parts[len(parts)-1].ID.String()
+	}
+	return page, nil
+}
+
+func (s *CatalogService) RejectPart(partID uuid.UUID, reason string, rejectedBy uuid.UUID) error {
+	var part *models.LibraryPart
+	err := s.withLockedPart(partID, func(tx *CatalogService, locked *models.LibraryPart) error {
+		part = locked
+		before := snapshotPart(part)
+
+		part.Status = "rejected"
+		part.RejectedReason = reason
 
-	part.Status = "rejected"
-	part.RejectedReason = reason
+		if err := tx.partRepo.Update(part); err != nil {
+			return err
+		}
+		return tx.recordVersion(EntityPart, part.ID, ActionReject, before, snapshotPart(part), rejectedBy, reason)
+	})
+	if err != nil {
+		return err
+	}
 
-	return s.partRepo.Update(part)
+	// Takes a previously approved part out of search
+	return s.publishPartChanged(events.EventCatalogPartUpdated, part)
 }
 
 func (s *CatalogService) GetPendingParts() ([]models.LibraryPart, error) {
 	return s.partRepo.GetPendingApproval()
 }
 
-func (s *CatalogService) AddPartAttribute(partAttribute *models.PartAttribute) error {
-	return s.attributeRepo.AddPartAttribute(partAttribute)
+// AddPartAttribute validates and normalizes a value against the part's
+// category template, replacing any value the part already has for it. On an
+// approved part the change is held as a revision for review.
+func (s *CatalogService) AddPartAttribute(partAttribute *models.PartAttribute, actorID uuid.UUID) (*models.PartRevision, error) {
+	update := PartUpdate{Attributes: []models.PartAttribute{*partAttribute}}
+	revision, err := s.UpdatePart(partAttribute.PartID, &update, actorID)
+	if err != nil {
+		return nil, err
+	}
+	*partAttribute = update.Attributes[0]
+	return revision, nil
+}
+
+// maxMatchCandidates caps how many existing parts a submission is scored against
+const maxMatchCandidates = 500
+
+var (
+	ErrCannotMergeIntoSelf    = errors.New("cannot merge a part into itself")
+	ErrPartAlreadyMerged      = errors.New("part has already been merged")
+	ErrCandidateNotPending    = errors.New("duplicate candidate is not pending")
+	ErrReferencesNotRepointed = errors.New("part merged, but moving references to it failed and will be retried")
+)
+
+// DetectDuplicates scores a part against similar existing parts and flags it
+// for the catalog admin when the best match reaches DuplicateThreshold
+func (s *CatalogService) DetectDuplicates(partID uuid.UUID) ([]models.DuplicateCandidate, error) {
+	part, err := s.partRepo.GetByID(partID)
+	if err != nil {
+		return nil, err
+	}
+	if part.Status == "merged" {
+		return nil, ErrPartAlreadyMerged
+	}
+
+	existing, err := s.partRepo.FindMatchCandidates(part, maxMatchCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []DuplicateMatch
+	for i := range existing {
+		match := matchParts(part, &existing[i])
+		if match.Score >= DuplicateThreshold {
+			matches = append(matches, match)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	candidates := make([]models.DuplicateCandidate, 0, len(matches))
+	for _, m := range matches {
+		candidates = append(candidates, models.DuplicateCandidate{
+			PartID:            part.ID,
+			CandidateID:       m.CandidateID,
+			Score:             m.Score,
+			PartNumberScore:   m.PartNumberScore,
+			ManufacturerScore: m.ManufacturerScore,
+			NameScore:         m.NameScore,
+			AttributeScore:    m.AttributeScore,
+			Status:            "pending",
+		})
+	}
+
+	// Pairs an admin already dismissed are kept and not raised again
+	if err := s.partRepo.SaveDuplicateCandidates(part.ID, candidates); err != nil {
+		return nil, err
+	}
+
+	pending, err := s.partRepo.ListPendingCandidates(part.ID)
+	if err != nil {
+		return nil, err
+	}
+	return pending, s.flagBestCandidate(part.ID, pending)
+}
+
+// flagBestCandidate points a part's DuplicateOf at its best pending match, or
+// clears the flag when nothing is left to review
+func (s *CatalogService) flagBestCandidate(partID uuid.UUID, pending []models.DuplicateCandidate) error {
+	if len(pending) == 0 {
+		return s.partRepo.UnmarkDuplicate(partID)
+	}
+	return s.partRepo.MarkAsDuplicate(partID, pending[0].CandidateID)
+}
+
+func (s *CatalogService) ListDuplicateCandidates(status string, limit, offset int) ([]models.DuplicateCandidate, error) {
+	return s.partRepo.ListDuplicateCandidates(status, limit, offset)
+}
+
+// MergePart folds a duplicate part into the target part. Catalog attributes
+// move over here; services that reference parts (BOMs, listings) re-point
+// their references when they receive the merged event.
+func (s *CatalogService) MergePart(duplicateID, targetID, reviewedBy uuid.UUID) error {
+	if duplicateID == targetID {
+		return ErrCannotMergeIntoSelf
+	}
+
+	duplicate, err := s.partRepo.GetByID(duplicateID)
+	if err != nil {
+		return err
+	}
+	if duplicate.Status == "merged" {
+		return ErrPartAlreadyMerged
+	}
+
+	target, err := s.partRepo.GetByID(targetID)
+	if err != nil {
+		return err
+	}
+	if target.Status == "merged" {
+		return fmt.Errorf("%w: merge into %s instead", ErrPartAlreadyMerged, target.DuplicateOf)
+	}
+
+	if err := s.partRepo.Merge(duplicate.ID, target.ID, reviewedBy); err != nil {
+		return err
+	}
+
+	before := snapshotPart(duplicate)
+	after := before
+	after.Status = "merged"
+	if err := s.recordVersion(EntityPart, duplicate.ID, ActionMerge, before, after, reviewedBy, fmt.Sprintf("merged into %s", target.ID)); err != nil {
+		return err
+	}
+
+	// Publish event
+	event := events.NewEventEnvelope(
+		events.EventCatalogPartMerged,
+		"catalog-service",
+		map[string]interface{}{
+			"part_id":     duplicate.ID.String(),
+			"part_number": duplicate.PartNumber,
+			"merged_into": target.ID.String(),
+		},
+	).WithUserID(reviewedBy).WithVersion(events.VersionAt(time.Now()))
+
+	if err := s.eventBus.Publish(nil, event); err != nil {
+		return err
+	}
+
+	// The target picked up the duplicate's cross references
+	if err := s.publishInterchangeUpdates([]uuid.UUID{target.ID}); err != nil {
+		return err
+	}
+
+	if err := s.repointReferences(duplicate.ID, target.ID); err != nil {
+		return fmt.Errorf("%w: %v", ErrReferencesNotRepointed, err)
+	}
+	return nil
+}
+
+// UsePartReferences sets the services whose references to a part are moved
+// when it is merged into another
+func (s *CatalogService) UsePartReferences(references ...PartReferences) {
+	s.partReferences = references
+}
+
+// repointReferences moves every service's references from a merged part to
+// its target, then marks the part so reconciliation skips it
+func (s *CatalogService) repointReferences(partID, targetID uuid.UUID) error {
+	for _, references := range s.partReferences {
+		if _, err := references.ReplacePart(partID, targetID); err != nil {
+			return err
+		}
+	}
+	return s.partRepo.MarkReferencesRepointed(partID)
+}
+
+// maxReconcileMerges caps how many merged parts one reconciliation re-points
+const maxReconcileMerges = 500
+
+// ReconcileMerges retries moving references for merged parts whose re-pointing
+// failed at merge time, returning how many were completed. A part that still
+// fails is logged and retried on the next run.
+func (s *CatalogService) ReconcileMerges() (int, error) {
+	parts, err := s.partRepo.ListMergedNotRepointed(maxReconcileMerges)
+	if err != nil {
+		return 0, err
+	}
+
+	reconciled := 0
+	for _, part := range parts {
+		if part.DuplicateOf == nil {
+			continue
+		}
+		if err := s.repointReferences(part.ID, *part.DuplicateOf); err != nil {
+			log.Printf("Failed to re-point references of merged part %s: %v", part.ID, err)
+			continue
+		}
+		reconciled++
+	}
+	return reconciled, nil
+}
+
+// StartMergeReconciler reconciles merges now and then on every interval until
+// the context is cancelled
+func (s *CatalogService) StartMergeReconciler(ctx context.Context, interval time.Duration) error {
+	log.Printf("Starting merge reconciler, running every %s...", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if reconciled, err := s.ReconcileMerges(); err != nil {
+			log.Printf("Merge reconciler error: %v", err)
+		} else if reconciled > 0 {
+			log.Printf("Re-pointed references of %d merged parts", reconciled)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DismissDuplicate records that a flagged pair are distinct parts. The part
+// stays flagged against its next best match until none remain pending.
+func (s *CatalogService) DismissDuplicate(candidateID, reviewedBy uuid.UUID) error {
+	candidate, err := s.partRepo.GetDuplicateCandidate(candidateID)
+	if err != nil {
+		return err
+	}
+	if candidate.Status != "pending" {
+		return ErrCandidateNotPending
+	}
+
+	now := time.Now()
+	candidate.Status = "dismissed"
+	candidate.ReviewedBy = &reviewedBy
+	candidate.ReviewedAt = &now
+	if err := s.partRepo.UpdateDuplicateCandidate(candidate); err != nil {
+		return err
+	}
+
+	pending, err := s.partRepo.ListPendingCandidates(candidate.PartID)
+	if err != nil {
+		return err
+	}
+	return s.flagBestCandidate(candidate.PartID, pending)
 }
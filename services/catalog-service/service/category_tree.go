@@ -0,0 +1,318 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/b2b-platform/catalog-service/models"
+	"github.com/google/uuid"
+)
+
+// republishBatchSize bounds how many parts are loaded at once when a tree
+// change has to be pushed to search
+const republishBatchSize = 200
+
+var (
+	ErrCategoryCycle       = errors.New("category cannot be moved under itself or its descendants")
+	ErrCategoryTooDeep     = errors.New("category tree would be too deep")
+	ErrCategoryInactive    = errors.New("category is retired")
+	ErrReplacementRequired = errors.New("category has parts; a replacement category is required")
+	ErrInvalidReplacement  = errors.New("replacement must be another active category")
+	ErrRetireRequired      = errors.New("categories are deactivated by retiring them")
+)
+
+// categoryPathIDs splits a materialized path into category IDs, root first
+func categoryPathIDs(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+}
+
+// buildCategoryTree nests categories ordered parents first under their
+// parents. Categories whose parent is not in the list become roots.
+func buildCategoryTree(categories []models.Category) []*CategoryNode {
+	nodes := make(map[uuid.UUID]*CategoryNode, len(categories))
+	var roots []*CategoryNode
+	for _, category := range categories {
+		node := &CategoryNode{Category: category, Children: []*CategoryNode{}}
+		nodes[category.ID] = node
+
+		if category.ParentID != nil {
+			if parent, ok := nodes[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// CategoryNode is a category with its subcategories nested
+type CategoryNode struct {
+	models.Category
+	Children []*CategoryNode `json:"children"`
+}
+
+// GetCategoryTree returns the whole hierarchy as nested roots
+func (s *CatalogService) GetCategoryTree(includeInactive bool) ([]*CategoryNode, error) {
+	categories, err := s.categoryRepo.ListAll(includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories), nil
+}
+
+// GetCategorySubtree returns a category with all of its descendants nested
+func (s *CatalogService) GetCategorySubtree(categoryID uuid.UUID, includeInactive bool) (*CategoryNode, error) {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.categoryRepo.ListSubtree(category.Path, true)
+	if err != nil {
+		return nil, err
+	}
+	if !includeInactive {
+		// Drop retired categories, and with them anything only reachable through one
+		active := categories[:0]
+		for _, c := range categories {
+			if c.IsActive || c.ID == category.ID {
+				active = append(active, c)
+			}
+		}
+		categories = active
+	}
+
+	for _, root := range buildCategoryTree(categories) {
+		if root.ID == category.ID {
+			return root, nil
+		}
+	}
+	return &CategoryNode{Category: *category, Children: []*CategoryNode{}}, nil
+}
+
+// GetBreadcrumbs returns a category's ancestors and itself, root first
+func (s *CatalogService) GetBreadcrumbs(categoryID uuid.UUID) ([]models.Category, error) {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	for _, segment := range categoryPathIDs(category.Path) {
+		id, err := uuid.Parse(segment)
+		if err != nil {
+			return nil, fmt.Errorf("category %s has a malformed path: %w", categoryID, err)
+		}
+		ids = append(ids, id)
+	}
+
+	categories, err := s.categoryRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	breadcrumbs := make([]models.Category, 0, len(ids))
+	for _, id := range ids {
+		if c, ok := byID[id]; ok {
+			breadcrumbs = append(breadcrumbs, c)
+		}
+	}
+	return breadcrumbs, nil
+}
+
+// MoveCategory puts a category and its subtree under a new parent, or at the
+// root when parentID is nil
+func (s *CatalogService) MoveCategory(categoryID uuid.UUID, parentID *uuid.UUID, actorID uuid.UUID) (*models.Category, error) {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if !category.IsActive {
+		return nil, ErrCategoryInactive
+	}
+	if sameParent(category.ParentID, parentID) {
+		return category, nil
+	}
+
+	before, err := s.snapshotCategory(category)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.moveCategory(category, parentID); err != nil {
+		return nil, err
+	}
+
+	after := before
+	after.ParentID = category.ParentID
+	if err := s.recordVersion(EntityCategory, category.ID, ActionUpdate, before, after, actorID, "moved"); err != nil {
+		return nil, err
+	}
+
+	s.republishCategoryTree(category.Path)
+	return category, nil
+}
+
+// moveCategory validates and applies a new parent for a category
+func (s *CatalogService) moveCategory(category *models.Category, parentID *uuid.UUID) error {
+	var parent *models.Category
+	newDepth := 0
+	if parentID != nil {
+		var err error
+		if parent, err = s.categoryRepo.GetByID(*parentID); err != nil {
+			return err
+		}
+		if strings.HasPrefix(parent.Path, category.Path) {
+			return ErrCategoryCycle
+		}
+		if !parent.IsActive {
+			return fmt.Errorf("%w: %s", ErrCategoryInactive, parent.Name)
+		}
+		newDepth = parent.Depth + 1
+	}
+
+	deepest, err := s.categoryRepo.MaxSubtreeDepth(category.Path)
+	if err != nil {
+		return err
+	}
+	if newDepth+deepest-category.Depth >= maxCategoryDepth {
+		return ErrCategoryTooDeep
+	}
+
+	return s.categoryRepo.Move(category, parent)
+}
+
+// RetireCategory deactivates a category. Its parts move to the replacement
+// category and its subcategories move up to its parent.
+func (s *CatalogService) RetireCategory(categoryID uuid.UUID, replacementID *uuid.UUID, actorID uuid.UUID) error {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return err
+	}
+	if !category.IsActive {
+		return ErrCategoryInactive
+	}
+
+	var replacement *models.Category
+	if replacementID != nil {
+		if *replacementID == category.ID {
+			return ErrInvalidReplacement
+		}
+		if replacement, err = s.categoryRepo.GetByID(*replacementID); err != nil {
+			return err
+		}
+		if !replacement.IsActive {
+			return ErrInvalidReplacement
+		}
+	}
+
+	partCount, err := s.categoryRepo.CountParts(category.ID)
+	if err != nil {
+		return err
+	}
+	if partCount > 0 && replacement == nil {
+		return ErrReplacementRequired
+	}
+
+	before, err := s.snapshotCategory(category)
+	if err != nil {
+		return err
+	}
+	childBefore := make(map[uuid.UUID]CategorySnapshot, len(category.Children))
+	for i := range category.Children {
+		child := &category.Children[i]
+		if childBefore[child.ID], err = s.snapshotCategory(child); err != nil {
+			return err
+		}
+	}
+
+	partIDs, err := s.categoryRepo.Retire(category, replacementID)
+	if err != nil {
+		return err
+	}
+
+	comment := "retired"
+	if replacement != nil {
+		comment = fmt.Sprintf("retired, parts moved to %s", replacement.Name)
+	}
+	after := before
+	after.IsActive = false
+	if err := s.recordVersion(EntityCategory, category.ID, ActionUpdate, before, after, actorID, comment); err != nil {
+		return err
+	}
+
+	for childID, childSnapshot := range childBefore {
+		moved := childSnapshot
+		moved.ParentID = category.ParentID
+		if err := s.recordVersion(EntityCategory, childID, ActionUpdate, childSnapshot, moved, actorID,
+			fmt.Sprintf("moved up from retired category %s", category.Name)); err != nil {
+			return err
+		}
+
+		child, err := s.categoryRepo.GetByID(childID)
+		if err != nil {
+			return err
+		}
+		s.republishCategoryTree(child.Path)
+	}
+
+	if len(partIDs) == 0 {
+		return nil
+	}
+	return s.reassignPartVersions(partIDs, category.ID, replacement.ID, actorID, comment)
+}
+
+// reassignPartVersions records the category change of parts moved off a
+// retired category and reindexes the approved ones
+func (s *CatalogService) reassignPartVersions(partIDs []uuid.UUID, fromID, toID, actorID uuid.UUID, comment string) error {
+	for _, partID := range partIDs {
+		part, err := s.partRepo.GetByID(partID)
+		if err != nil {
+			return err
+		}
+
+		after := snapshotPart(part)
+		before := after
+		before.CategoryID = fromID
+		if err := s.recordVersion(EntityPart, part.ID, ActionUpdate, before, after, actorID, comment); err != nil {
+			return err
+		}
+
+		if part.Status == "approved" {
+			if err := s.publishPartApproved(part); err != nil {
+				log.Printf("Failed to reindex part %s after category change: %v", part.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// republishCategoryTree reindexes the approved parts under a moved category
+// so their category path facets stay current. Failures are logged; the
+// move itself has already been committed.
+func (s *CatalogService) republishCategoryTree(path string) {
+	err := s.partRepo.ForEachInCategoryTree(path, "approved", republishBatchSize, func(parts []models.LibraryPart) error {
+		for i := range parts {
+			if err := s.publishPartApproved(&parts[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to reindex parts under category path %s: %v", path, err)
+	}
+}
+
+func sameParent(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/b2b-platform/catalog-service/models"
+	"github.com/b2b-platform/shared/events"
+	"github.com/google/uuid"
+)
+
+// Cross reference relation types
+const (
+	RelationSupersedes  = "supersedes"
+	RelationEquivalent  = "equivalent"
+	RelationAlternative = "alternative"
+)
+
+// maxInterchangeParts bounds how much of the cross reference graph one lookup loads
+const maxInterchangeParts = 500
+
+var (
+	ErrInvalidCrossReference = errors.New("invalid cross reference")
+	ErrCrossReferenceExists  = errors.New("cross reference already exists")
+	ErrAlreadySuperseded     = errors.New("part is already superseded")
+	ErrSupersessionCycle     = errors.New("supersession would create a cycle")
+)
+
+// InterchangeOption is a part that can be used in place of another
+type InterchangeOption struct {
+	Part         models.LibraryPart `json:"part"`
+	RelationType string             `json:"relation_type"` // supersedes, equivalent, alternative
+	Conditions   string             `json:"conditions,omitempty"`
+	Superseded   bool               `json:"superseded"`
+}
+
+// PartInterchange resolves a part through the cross reference graph: the
+// part that currently replaces it and the parts usable instead of it
+type PartInterchange struct {
+	PartID            uuid.UUID           `json:"part_id"`
+	Superseded        bool                `json:"superseded"`
+	CurrentPart       models.LibraryPart  `json:"current_part"`       // End of the supersession chain, the part itself if not superseded
+	SupersessionChain []uuid.UUID         `json:"supersession_chain"` // From the part to the current part
+	Alternatives      []InterchangeOption `json:"alternatives"`
+	Replaces          []InterchangeOption `json:"replaces"` // Parts this part can stand in for
+}
+
+// AddCrossReference records how ref.PartID relates to ref.RelatedPartID
+func (s *CatalogService) AddCrossReference(ref *models.PartCrossReference) error {
+	ref.RelationType = strings.ToLower(strings.TrimSpace(ref.RelationType))
+	ref.Conditions = strings.TrimSpace(ref.Conditions)
+
+	switch ref.RelationType {
+	case RelationSupersedes, RelationEquivalent:
+	case RelationAlternative:
+		if ref.Conditions == "" {
+			return fmt.Errorf("%w: alternatives need the conditions under which they apply", ErrInvalidCrossReference)
+		}
+	default:
+		return fmt.Errorf("%w: relation_type must be supersedes, equivalent or alternative", ErrInvalidCrossReference)
+	}
+	if ref.PartID == ref.RelatedPartID {
+		return fmt.Errorf("%w: a part cannot reference itself", ErrInvalidCrossReference)
+	}
+
+	for _, id := range []uuid.UUID{ref.PartID, ref.RelatedPartID} {
+		part, err := s.partRepo.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if part.Status == "merged" {
+			return fmt.Errorf("%w: part %s was merged into %s", ErrPartAlreadyMerged, part.ID, part.DuplicateOf)
+		}
+	}
+
+	existing, err := s.partRepo.FindCrossReference(ref.PartID, ref.RelatedPartID, ref.RelationType)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrCrossReferenceExists
+	}
+
+	if ref.RelationType == RelationSupersedes {
+		graph, err := s.loadCrossReferenceGraph([]uuid.UUID{ref.PartID, ref.RelatedPartID})
+		if err != nil {
+			return err
+		}
+		if _, ok := graph.successor[ref.RelatedPartID]; ok {
+			return ErrAlreadySuperseded
+		}
+		// The new part must not already be replaced, directly or not, by the part it supersedes
+		for _, id := range graph.chain(ref.PartID) {
+			if id == ref.RelatedPartID {
+				return ErrSupersessionCycle
+			}
+		}
+	}
+
+	if err := s.partRepo.CreateCrossReference(ref); err != nil {
+		return err
+	}
+	return s.publishInterchangeUpdates([]uuid.UUID{ref.PartID, ref.RelatedPartID})
+}
+
+func (s *CatalogService) RemoveCrossReference(id uuid.UUID) error {
+	ref, err := s.partRepo.GetCrossReference(id)
+	if err != nil {
+		return err
+	}
+	if err := s.partRepo.DeleteCrossReference(ref.ID); err != nil {
+		return err
+	}
+	return s.publishInterchangeUpdates([]uuid.UUID{ref.PartID, ref.RelatedPartID})
+}
+
+// ListCrossReferences returns a part's direct references in both directions
+func (s *CatalogService) ListCrossReferences(partID uuid.UUID) ([]models.PartCrossReference, error) {
+	if _, err := s.partRepo.GetByID(partID); err != nil {
+		return nil, err
+	}
+	return s.partRepo.ListPartCrossReferences(partID)
+}
+
+func (s *CatalogService) GetInterchange(partID uuid.UUID) (*PartInterchange, error) {
+	if _, err := s.partRepo.GetByID(partID); err != nil {
+		return nil, err
+	}
+
+	interchanges, err := s.GetInterchanges([]uuid.UUID{partID})
+	if err != nil {
+		return nil, err
+	}
+	return interchanges[partID], nil
+}
+
+// GetInterchanges resolves several parts at once, e.g. all parts of a BOM.
+// Unknown part IDs are left out of the result.
+func (s *CatalogService) GetInterchanges(partIDs []uuid.UUID) (map[uuid.UUID]*PartInterchange, error) {
+	graph, err := s.loadCrossReferenceGraph(partIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.resolveInterchanges(graph, partIDs)
+}
+
+func (s *CatalogService) resolveInterchanges(graph *crossReferenceGraph, partIDs []uuid.UUID) (map[uuid.UUID]*PartInterchange, error) {
+	parts, err := s.partRepo.GetPartsByIDs(graph.partIDs())
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.LibraryPart, len(parts))
+	for _, part := range parts {
+		byID[part.ID] = part
+	}
+
+	interchanges := make(map[uuid.UUID]*PartInterchange, len(partIDs))
+	for _, id := range partIDs {
+		if _, ok := byID[id]; ok {
+			interchanges[id] = graph.resolve(id, byID)
+		}
+	}
+	return interchanges, nil
+}
+
+// publishInterchangeUpdates announces the resolved interchange of every part
+// connected to the given ones, so search can surface current and alternative parts
+func (s *CatalogService) publishInterchangeUpdates(partIDs []uuid.UUID) error {
+	graph, err := s.loadCrossReferenceGraph(partIDs)
+	if err != nil {
+		return err
+	}
+
+	interchanges, err := s.resolveInterchanges(graph, graph.partIDs())
+	if err != nil {
+		return err
+	}
+
+	for _, interchange := range interchanges {
+		event := events.NewEventEnvelope(
+			events.EventCatalogPartInterchangeUpdated,
+			"catalog-service",
+			interchangePayload(interchange),
+		)
+		if err := s.eventBus.Publish(nil, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// interchangePayload gives the search fields of a part's interchange
+func interchangePayload(interchange *PartInterchange) map[string]interface{} {
+	alternativeIDs := make([]string, 0, len(interchange.Alternatives))
+	for _, option := range interchange.Alternatives {
+		alternativeIDs = append(alternativeIDs, option.Part.ID.String())
+	}
+	replacesNumbers := make([]string, 0, len(interchange.Replaces))
+	for _, option := range interchange.Replaces {
+		replacesNumbers = append(replacesNumbers, option.Part.PartNumber)
+	}
+
+	return map[string]interface{}{
+		"part_id":                  interchange.PartID.String(),
+		"superseded":               interchange.Superseded,
+		"current_part_id":          interchange.CurrentPart.ID.String(),
+		"current_part_number":      interchange.CurrentPart.PartNumber,
+		"alternative_part_ids":     alternativeIDs,
+		"interchange_part_numbers": replacesNumbers,
+	}
+}
+
+// crossReferenceGraph is the part of the cross reference graph reachable from
+// a set of parts
+type crossReferenceGraph struct {
+	nodes        map[uuid.UUID]bool
+	successor    map[uuid.UUID]uuid.UUID   // Part -> the part superseding it
+	predecessors map[uuid.UUID][]uuid.UUID // Part -> parts it supersedes
+	equivalents  map[uuid.UUID][]uuid.UUID
+	alternatives map[uuid.UUID][]models.PartCrossReference // Part -> references naming alternatives to it
+	replaceable  map[uuid.UUID][]models.PartCrossReference // Alternative -> references naming parts it can replace
+}
+
+func (s *CatalogService) loadCrossReferenceGraph(start []uuid.UUID) (*crossReferenceGraph, error) {
+	graph := &crossReferenceGraph{
+		nodes:        make(map[uuid.UUID]bool),
+		successor:    make(map[uuid.UUID]uuid.UUID),
+		predecessors: make(map[uuid.UUID][]uuid.UUID),
+		equivalents:  make(map[uuid.UUID][]uuid.UUID),
+		alternatives: make(map[uuid.UUID][]models.PartCrossReference),
+		replaceable:  make(map[uuid.UUID][]models.PartCrossReference),
+	}
+
+	seenRefs := make(map[uuid.UUID]bool)
+	frontier := make([]uuid.UUID, 0, len(start))
+	for _, id := range start {
+		if !graph.nodes[id] {
+			graph.nodes[id] = true
+			frontier = append(frontier, id)
+		}
+	}
+
+	for len(frontier) > 0 && len(graph.nodes) < maxInterchangeParts {
+		refs, err := s.partRepo.ListCrossReferences(frontier)
+		if err != nil {
+			return nil, err
+		}
+
+		frontier = frontier[:0]
+		for _, ref := range refs {
+			if seenRefs[ref.ID] {
+				continue
+			}
+			seenRefs[ref.ID] = true
+			graph.add(ref)
+
+			for _, id := range []uuid.UUID{ref.PartID, ref.RelatedPartID} {
+				if !graph.nodes[id] {
+					graph.nodes[id] = true
+					frontier = append(frontier, id)
+				}
+			}
+		}
+	}
+	return graph, nil
+}
+
+func (g *crossReferenceGraph) add(ref models.PartCrossReference) {
+	switch ref.RelationType {
+	case RelationSupersedes:
+		g.successor[ref.RelatedPartID] = ref.PartID
+		g.predecessors[ref.PartID] = append(g.predecessors[ref.PartID], ref.RelatedPartID)
+	case RelationEquivalent:
+		g.equivalents[ref.PartID] = append(g.equivalents[ref.PartID], ref.RelatedPartID)
+		g.equivalents[ref.RelatedPartID] = append(g.equivalents[ref.RelatedPartID], ref.PartID)
+	case RelationAlternative:
+		g.alternatives[ref.RelatedPartID] = append(g.alternatives[ref.RelatedPartID], ref)
+		g.replaceable[ref.PartID] = append(g.replaceable[ref.PartID], ref)
+	}
+}
+
+func (g *crossReferenceGraph) partIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(g.nodes))
+	for id := range g.nodes {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// chain follows supersession from a part to the current part
+func (g *crossReferenceGraph) chain(partID uuid.UUID) []uuid.UUID {
+	chain := []uuid.UUID{partID}
+	seen := map[uuid.UUID]bool{partID: true}
+	for {
+		next, ok := g.successor[chain[len(chain)-1]]
+		if !ok || seen[next] {
+			return chain
+		}
+		seen[next] = true
+		chain = append(chain, next)
+	}
+}
+
+// equivalentsOf returns the parts equivalent to any of the given ones;
+// equivalence is transitive
+func (g *crossReferenceGraph) equivalentsOf(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+
+	var found []uuid.UUID
+	queue := append([]uuid.UUID(nil), ids...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range g.equivalents[id] {
+			if !seen[next] {
+				seen[next] = true
+				found = append(found, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+	return found
+}
+
+// ancestors returns every part a part supersedes, directly or not
+func (g *crossReferenceGraph) ancestors(partID uuid.UUID) []uuid.UUID {
+	seen := map[uuid.UUID]bool{partID: true}
+	var found []uuid.UUID
+	queue := []uuid.UUID{partID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, prev := range g.predecessors[id] {
+			if !seen[prev] {
+				seen[prev] = true
+				found = append(found, prev)
+				queue = append(queue, prev)
+			}
+		}
+	}
+	return found
+}
+
+func (g *crossReferenceGraph) resolve(partID uuid.UUID, parts map[uuid.UUID]models.LibraryPart) *PartInterchange {
+	chain := g.chain(partID)
+	currentID := chain[len(chain)-1]
+
+	interchange := &PartInterchange{
+		PartID:            partID,
+		Superseded:        currentID != partID,
+		CurrentPart:       parts[currentID],
+		SupersessionChain: chain,
+		Alternatives:      []InterchangeOption{},
+		Replaces:          []InterchangeOption{},
+	}
+
+	usable := func(id uuid.UUID) bool {
+		part, ok := parts[id]
+		return ok && part.Status != "merged" && part.Status != "rejected"
+	}
+
+	option := func(id uuid.UUID, relationType, conditions string) InterchangeOption {
+		_, superseded := g.successor[id]
+		return InterchangeOption{
+			Part:         parts[id],
+			RelationType: relationType,
+			Conditions:   conditions,
+			Superseded:   superseded,
+		}
+	}
+
+	listed := map[uuid.UUID]bool{partID: true, currentID: true}
+	addAlternative := func(id uuid.UUID, relationType, conditions string) {
+		if !listed[id] && usable(id) {
+			listed[id] = true
+			interchange.Alternatives = append(interchange.Alternatives, option(id, relationType, conditions))
+		}
+	}
+
+	// Equivalents of the part or its replacement, then conditional alternatives to any of them
+	base := []uuid.UUID{partID, currentID}
+	equivalents := g.equivalentsOf(base)
+	for _, id := range equivalents {
+		addAlternative(id, RelationEquivalent, "")
+	}
+	for _, id := range append(base, equivalents...) {
+		for _, ref := range g.alternatives[id] {
+			addAlternative(ref.PartID, RelationAlternative, ref.Conditions)
+		}
+	}
+
+	replaced := map[uuid.UUID]bool{partID: true}
+	addReplaced := func(id uuid.UUID, relationType, conditions string) {
+		if !replaced[id] && usable(id) {
+			replaced[id] = true
+			interchange.Replaces = append(interchange.Replaces, option(id, relationType, conditions))
+		}
+	}
+	for _, id := range g.ancestors(partID) {
+		addReplaced(id, RelationSupersedes, "")
+	}
+	for _, id := range g.equivalentsOf([]uuid.UUID{partID}) {
+		addReplaced(id, RelationEquivalent, "")
+	}
+	for _, ref := range g.replaceable[partID] {
+		addReplaced(ref.RelatedPartID, RelationAlternative, ref.Conditions)
+	}
+
+	return interchange
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Import file formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Fields a file column can be mapped to. Attribute values use
+// AttributeFieldPrefix followed by the attribute code, e.g. "attr:WEIGHT".
+const (
+	FieldPartNumber      = "part_number"
+	FieldManufacturer    = "manufacturer"
+	FieldCategory        = "category"
+	FieldParentCategory  = "parent_category"
+	FieldName            = "name"
+	FieldDescription     = "description"
+	AttributeFieldPrefix = "attr:"
+	FieldIgnore          = "ignore"
+)
+
+var requiredImportFields = []string{FieldPartNumber, FieldManufacturer, FieldCategory, FieldName}
+
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+// importFile is a parsed import file: a header row and data rows
+type importFile struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// detectFormat picks the file format from an explicit value or the file extension
+func detectFormat(format, fileName string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(fileName), ".")
+	}
+	format = strings.ToLower(format)
+	switch format {
+	case FormatCSV, FormatXLSX:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported format %q, use csv or xlsx", ErrInvalidImportFile, format)
+	}
+}
+
+func readImportFile(format string, r io.Reader) (*importFile, error) {
+	var records [][]string
+	switch format {
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		var err error
+		if records, err = reader.ReadAll(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+
+	case FormatXLSX:
+		workbook, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		defer workbook.Close()
+
+		sheets := workbook.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("%w: workbook has no sheets", ErrInvalidImportFile)
+		}
+		// Only the first sheet is imported
+		if records, err = workbook.GetRows(sheets[0]); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidImportFile)
+	}
+
+	headers := records[0]
+	for i := range headers {
+		headers[i] = strings.TrimSpace(strings.TrimPrefix(headers[i], "\ufeff"))
+	}
+
+	return &importFile{Headers: headers, Rows: records[1:]}, nil
+}
+
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalField turns a header such as "Part Number" into "part_number"
+func canonicalField(header string) string {
+	field := strings.ToLower(strings.TrimSpace(header))
+	if strings.HasPrefix(field, AttributeFieldPrefix) {
+		return AttributeFieldPrefix + strings.ToUpper(strings.TrimSpace(header[len(AttributeFieldPrefix):]))
+	}
+	field = strings.NewReplacer(" ", "_", "-", "_").Replace(field)
+	switch field {
+	case FieldPartNumber, FieldManufacturer, FieldCategory, FieldParentCategory, FieldName, FieldDescription:
+		return field
+	}
+	return ""
+}
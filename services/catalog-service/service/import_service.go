@@ -0,0 +1,640 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/catalog-service/models"
+	"github.com/b2b-platform/catalog-service/repository"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// importProgressInterval is how many rows are processed between progress saves
+const importProgressInterval = 100
+
+// exportBatchSize is how many parts are loaded at a time when exporting
+const exportBatchSize = 500
+
+// ImportService runs bulk catalog imports and exports
+type ImportService struct {
+	catalog    *CatalogService
+	importRepo *repository.ImportRepository
+}
+
+func NewImportService(catalog *CatalogService, importRepo *repository.ImportRepository) *ImportService {
+	return &ImportService{
+		catalog:    catalog,
+		importRepo: importRepo,
+	}
+}
+
+// StartImport parses the file, resolves its column mapping and queues the
+// rows for background processing. mapping maps file headers to fields;
+// headers it leaves out are matched to field names and attribute codes.
+func (s *ImportService) StartImport(fileName, format string, r io.Reader, mapping map[string]string, dryRun bool, createdBy uuid.UUID) (*models.ImportJob, error) {
+	format, err := detectFormat(format, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := readImportFile(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := s.resolveColumns(file.Headers, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]string, len(columns))
+	for i, field := range columns {
+		if field != "" {
+			resolved[file.Headers[i]] = field
+		}
+	}
+	mappingJSON, _ := json.Marshal(resolved)
+
+	job := &models.ImportJob{
+		FileName:      fileName,
+		Format:        format,
+		ColumnMapping: string(mappingJSON),
+		DryRun:        dryRun,
+		Status:        "pending",
+		TotalRows:     len(file.Rows),
+		CreatedBy:     createdBy,
+	}
+	if err := s.importRepo.Create(job); err != nil {
+		return nil, err
+	}
+
+	go s.run(job, file, columns)
+
+	return job, nil
+}
+
+func (s *ImportService) GetJob(id uuid.UUID) (*models.ImportJob, error) {
+	return s.importRepo.GetByID(id)
+}
+
+func (s *ImportService) ListJobs(limit, offset int) ([]models.ImportJob, error) {
+	return s.importRepo.List(limit, offset)
+}
+
+func (s *ImportService) ListRowErrors(jobID uuid.UUID, limit, offset int) ([]models.ImportRowError, error) {
+	return s.importRepo.ListRowErrors(jobID, limit, offset)
+}
+
+// resolveColumns returns the field for each file column, "" for ignored columns
+func (s *ImportService) resolveColumns(headers []string, mapping map[string]string) ([]string, error) {
+	explicit := make(map[string]string, len(mapping))
+	for header, field := range mapping {
+		explicit[strings.ToLower(strings.TrimSpace(header))] = field
+	}
+
+	columns := make([]string, len(headers))
+	mapped := make(map[string]bool)
+	for i, header := range headers {
+		field, ok := explicit[strings.ToLower(header)]
+		if ok {
+			if strings.EqualFold(field, FieldIgnore) || field == "" {
+				continue
+			}
+			target := field
+			if field = canonicalField(target); field == "" {
+				return nil, fmt.Errorf("%w: column %q is mapped to unknown field %q", ErrInvalidImportFile, header, target)
+			}
+		} else if field = canonicalField(header); field == "" {
+			// Columns named after an attribute carry its values
+			attribute, err := s.catalog.attributeRepo.FindByNameOrCode(header)
+			if err != nil {
+				continue
+			}
+			field = AttributeFieldPrefix + strings.ToUpper(attribute.Code)
+		}
+
+		if mapped[field] {
+			return nil, fmt.Errorf("%w: more than one column maps to %s", ErrInvalidImportFile, field)
+		}
+		mapped[field] = true
+		columns[i] = field
+	}
+
+	var missing []string
+	for _, field := range requiredImportFields {
+		if !mapped[field] {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: no column mapped to %s", ErrInvalidImportFile, strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+func (s *ImportService) run(job *models.ImportJob, file *importFile, columns []string) {
+	now := time.Now()
+	job.Status = "running"
+	job.StartedAt = &now
+	s.saveProgress(job, nil)
+
+	defer func() {
+		if r := recover(); r != nil {
+			job.Status = "failed"
+			job.ErrorMessage = fmt.Sprintf("import aborted: %v", r)
+			completed := time.Now()
+			job.CompletedAt = &completed
+			s.saveProgress(job, nil)
+		}
+	}()
+
+	importer := newRowImporter(s.catalog, job)
+	var pending []models.ImportRowError
+
+	for i, row := range file.Rows {
+		rowNumber := i + 2 // 1-based, after the header row
+		if !isBlankRow(row) {
+			rowErrors := importer.importRow(rowNumber, rowValues(row, columns))
+			if len(rowErrors) > 0 {
+				job.ErrorCount++
+				pending = append(pending, rowErrors...)
+			}
+		}
+
+		job.ProcessedRows++
+		if job.ProcessedRows%importProgressInterval == 0 {
+			s.saveProgress(job, pending)
+			pending = nil
+		}
+	}
+
+	completed := time.Now()
+	job.Status = "completed"
+	job.CompletedAt = &completed
+	s.saveProgress(job, pending)
+}
+
+func (s *ImportService) saveProgress(job *models.ImportJob, rowErrors []models.ImportRowError) {
+	if err := s.importRepo.AddRowErrors(rowErrors); err != nil {
+		log.Printf("Failed to save import %s row errors: %v", job.ID, err)
+	}
+	if err := s.importRepo.Update(job); err != nil {
+		log.Printf("Failed to save import %s progress: %v", job.ID, err)
+	}
+}
+
+func rowValues(row []string, columns []string) map[string]string {
+	values := make(map[string]string, len(columns))
+	for i, field := range columns {
+		if field != "" && i < len(row) {
+			values[field] = strings.TrimSpace(row[i])
+		}
+	}
+	return values
+}
+
+// rowImporter applies import rows, caching lookups across rows. In a dry run
+// it validates and counts without writing, remembering the manufacturers and
+// categories that would have been created so later rows see them.
+type rowImporter struct {
+	catalog *CatalogService
+	job     *models.ImportJob
+
+	manufacturers map[string]*uuid.UUID // nil ID: would be created in a dry run
+	categories    map[string]*uuid.UUID
+	attributes    map[string]*models.Attribute
+	templates     map[uuid.UUID][]TemplateAttribute
+}
+
+func newRowImporter(catalog *CatalogService, job *models.ImportJob) *rowImporter {
+	return &rowImporter{
+		catalog:       catalog,
+		job:           job,
+		manufacturers: make(map[string]*uuid.UUID),
+		categories:    make(map[string]*uuid.UUID),
+		attributes:    make(map[string]*models.Attribute),
+		templates:     make(map[uuid.UUID][]TemplateAttribute),
+	}
+}
+
+// importRow validates a row and, outside a dry run, upserts its part on
+// (manufacturer, normalized part number). Rows with errors are not written.
+func (ri *rowImporter) importRow(rowNumber int, values map[string]string) []models.ImportRowError {
+	var rowErrors []models.ImportRowError
+	addError := func(column, message string) {
+		rowErrors = append(rowErrors, models.ImportRowError{
+			JobID:     ri.job.ID,
+			RowNumber: rowNumber,
+			Column:    column,
+			Message:   message,
+		})
+	}
+
+	for _, field := range requiredImportFields {
+		if values[field] == "" {
+			addError(field, "value is required")
+		}
+	}
+	normalized := NormalizePartNumber(values[FieldPartNumber])
+	if values[FieldPartNumber] != "" && normalized == "" {
+		addError(FieldPartNumber, "part number has no letters or digits")
+	}
+	if len(rowErrors) > 0 {
+		return rowErrors
+	}
+
+	manufacturerID, err := ri.lookupManufacturer(values[FieldManufacturer])
+	if err != nil {
+		addError(FieldManufacturer, err.Error())
+	}
+	categoryID, err := ri.lookupCategory(values[FieldCategory])
+	if err != nil {
+		addError(FieldCategory, err.Error())
+	}
+	if values[FieldParentCategory] != "" {
+		if _, err := ri.lookupCategory(values[FieldParentCategory]); err != nil {
+			addError(FieldParentCategory, err.Error())
+		}
+	}
+
+	partAttributes := ri.attributeValues(categoryID, values, addError)
+	if len(rowErrors) > 0 {
+		return rowErrors
+	}
+
+	if ri.job.DryRun {
+		ri.countDryRun(manufacturerID, normalized)
+		ri.rememberNew(values)
+		return nil
+	}
+
+	if err := ri.writeRow(values, normalized, partAttributes); err != nil {
+		addError("", err.Error())
+	}
+	return rowErrors
+}
+
+// attributeValues validates the row's attribute columns against the category template
+func (ri *rowImporter) attributeValues(categoryID *uuid.UUID, values map[string]string, addError func(column, message string)) []models.PartAttribute {
+	var template []TemplateAttribute
+	if categoryID != nil {
+		var err error
+		if template, err = ri.template(*categoryID); err != nil {
+			addError(FieldCategory, err.Error())
+			return nil
+		}
+	}
+
+	var partAttributes []models.PartAttribute
+	for field, value := range values {
+		if !strings.HasPrefix(field, AttributeFieldPrefix) || value == "" {
+			continue
+		}
+
+		code := strings.TrimPrefix(field, AttributeFieldPrefix)
+		attribute, err := ri.lookupAttribute(code)
+		if err != nil {
+			addError(field, err.Error())
+			continue
+		}
+		if len(template) > 0 {
+			if attribute, err = ri.catalog.templateAttribute(template, attribute.ID); err != nil {
+				addError(field, err.Error())
+				continue
+			}
+		}
+
+		pa := models.PartAttribute{AttributeID: attribute.ID, Value: value}
+		if err := NormalizePartAttribute(attribute, &pa); err != nil {
+			addError(field, err.Error())
+			continue
+		}
+		partAttributes = append(partAttributes, pa)
+	}
+	return partAttributes
+}
+
+func (ri *rowImporter) writeRow(values map[string]string, normalized string, partAttributes []models.PartAttribute) error {
+	manufacturerID, err := ri.ensureManufacturer(values[FieldManufacturer])
+	if err != nil {
+		return err
+	}
+
+	var parentID *uuid.UUID
+	if values[FieldParentCategory] != "" {
+		id, err := ri.ensureCategory(values[FieldParentCategory], nil)
+		if err != nil {
+			return err
+		}
+		parentID = &id
+	}
+	categoryID, err := ri.ensureCategory(values[FieldCategory], parentID)
+	if err != nil {
+		return err
+	}
+
+	part, err := ri.catalog.partRepo.FindByManufacturerPartNumber(manufacturerID, normalized)
+	if err != nil {
+		return err
+	}
+
+	if part == nil {
+		part = &models.LibraryPart{
+			PartNumber:     values[FieldPartNumber],
+			ManufacturerID: manufacturerID,
+			CategoryID:     categoryID,
+			Name:           values[FieldName],
+			Description:    values[FieldDescription],
+			Status:         "pending",
+			CreatedBy:      ri.job.CreatedBy,
+			PartAttributes: partAttributes,
+		}
+		if err := ri.catalog.CreatePart(part); err != nil {
+			return err
+		}
+		ri.job.CreatedCount++
+		return nil
+	}
+
+	// Updates go through the catalog so they are versioned, and held for
+	// review when the part is already approved
+	update := &PartUpdate{
+		CategoryID: &categoryID,
+		Name:       stringPtr(values[FieldName]),
+		Attributes: partAttributes,
+	}
+	if description, ok := values[FieldDescription]; ok {
+		update.Description = &description
+	}
+	if _, err := ri.catalog.UpdatePart(part.ID, update, ri.job.CreatedBy); err != nil {
+		return err
+	}
+	ri.job.UpdatedCount++
+	return nil
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func (ri *rowImporter) countDryRun(manufacturerID *uuid.UUID, normalized string) {
+	if manufacturerID == nil {
+		ri.job.CreatedCount++
+		return
+	}
+
+	part, err := ri.catalog.partRepo.FindByManufacturerPartNumber(*manufacturerID, normalized)
+	if err == nil && part != nil {
+		ri.job.UpdatedCount++
+	} else {
+		ri.job.CreatedCount++
+	}
+}
+
+// rememberNew records manufacturers and categories a dry run would create
+func (ri *rowImporter) rememberNew(values map[string]string) {
+	if key := lookupKey(values[FieldManufacturer]); ri.manufacturers[key] == nil {
+		ri.manufacturers[key] = nil
+	}
+	for _, field := range []string{FieldCategory, FieldParentCategory} {
+		if values[field] == "" {
+			continue
+		}
+		if key := lookupKey(values[field]); ri.categories[key] == nil {
+			ri.categories[key] = nil
+		}
+	}
+}
+
+// lookupManufacturer returns the ID of an existing manufacturer, or nil when
+// the import would create it
+func (ri *rowImporter) lookupManufacturer(value string) (*uuid.UUID, error) {
+	key := lookupKey(value)
+	if id, ok := ri.manufacturers[key]; ok {
+		return id, nil
+	}
+
+	manufacturer, err := ri.catalog.manufacturerRepo.FindByNameOrCode(value)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ri.manufacturers[key] = &manufacturer.ID
+	return &manufacturer.ID, nil
+}
+
+func (ri *rowImporter) ensureManufacturer(value string) (uuid.UUID, error) {
+	id, err := ri.lookupManufacturer(value)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if id != nil {
+		return *id, nil
+	}
+
+	manufacturer := &models.Manufacturer{
+		Name:     value,
+		Code:     NormalizePartNumber(value),
+		IsActive: true,
+	}
+	if err := ri.catalog.manufacturerRepo.Create(manufacturer); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create manufacturer %q: %w", value, err)
+	}
+	ri.manufacturers[lookupKey(value)] = &manufacturer.ID
+	return manufacturer.ID, nil
+}
+
+// lookupCategory returns the ID of an existing category, or nil when the
+// import would create it
+func (ri *rowImporter) lookupCategory(value string) (*uuid.UUID, error) {
+	key := lookupKey(value)
+	if id, ok := ri.categories[key]; ok {
+		return id, nil
+	}
+
+	category, err := ri.catalog.categoryRepo.FindByNameOrCode(value)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ri.categories[key] = &category.ID
+	return &category.ID, nil
+}
+
+func (ri *rowImporter) ensureCategory(value string, parentID *uuid.UUID) (uuid.UUID, error) {
+	id, err := ri.lookupCategory(value)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if id != nil {
+		return *id, nil
+	}
+
+	category := &models.Category{
+		Name:     value,
+		Code:     NormalizePartNumber(value),
+		ParentID: parentID,
+		IsActive: true,
+	}
+	if err := ri.catalog.CreateCategory(category, ri.job.CreatedBy); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create category %q: %w", value, err)
+	}
+	ri.categories[lookupKey(value)] = &category.ID
+	return category.ID, nil
+}
+
+func (ri *rowImporter) lookupAttribute(code string) (*models.Attribute, error) {
+	key := lookupKey(code)
+	if attribute, ok := ri.attributes[key]; ok {
+		if attribute == nil {
+			return nil, fmt.Errorf("unknown attribute %q", code)
+		}
+		return attribute, nil
+	}
+
+	attribute, err := ri.catalog.attributeRepo.FindByNameOrCode(code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ri.attributes[key] = nil
+		return nil, fmt.Errorf("unknown attribute %q", code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ri.attributes[key] = attribute
+	return attribute, nil
+}
+
+func (ri *rowImporter) template(categoryID uuid.UUID) ([]TemplateAttribute, error) {
+	if template, ok := ri.templates[categoryID]; ok {
+		return template, nil
+	}
+	template, err := ri.catalog.GetAttributeTemplate(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	ri.templates[categoryID] = template
+	return template, nil
+}
+
+func lookupKey(value string) string {
+	return strings.ToUpper(strings.TrimSpace(value))
+}
+
+// Export
+
+// ExportApprovedParts writes the approved catalog in the same column layout
+// the importer reads, so an export can be edited and imported back
+func (s *ImportService) ExportApprovedParts(format string, w io.Writer) error {
+	attributes, err := s.catalog.attributeRepo.ListUsedByPartStatus("approved")
+	if err != nil {
+		return err
+	}
+
+	headers := []string{FieldPartNumber, FieldManufacturer, FieldCategory, FieldName, FieldDescription}
+	for _, attribute := range attributes {
+		headers = append(headers, AttributeFieldPrefix+attribute.Code)
+	}
+
+	toRow := func(part *models.LibraryPart) []string {
+		values := make(map[uuid.UUID]string, len(part.PartAttributes))
+		for _, pa := range part.PartAttributes {
+			values[pa.AttributeID] = pa.Value
+		}
+
+		row := []string{part.PartNumber, part.Manufacturer.Name, part.Category.Name, part.Name, part.Description}
+		for _, attribute := range attributes {
+			row = append(row, values[attribute.ID])
+		}
+		return row
+	}
+
+	switch format {
+	case FormatCSV:
+		return s.exportCSV(w, headers, toRow)
+	case FormatXLSX:
+		return s.exportXLSX(w, headers, toRow)
+	default:
+		return fmt.Errorf("%w: unsupported format %q, use csv or xlsx", ErrInvalidImportFile, format)
+	}
+}
+
+func (s *ImportService) exportCSV(w io.Writer, headers []string, toRow func(*models.LibraryPart) []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	err := s.catalog.partRepo.ForEachByStatus("approved", exportBatchSize, func(parts []models.LibraryPart) error {
+		for i := range parts {
+			if err := writer.Write(toRow(&parts[i])); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *ImportService) exportXLSX(w io.Writer, headers []string, toRow func(*models.LibraryPart) []string) error {
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+
+	sheet := workbook.GetSheetName(0)
+	stream, err := workbook.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	rowNumber := 1
+	writeRow := func(values []string) error {
+		cells := make([]interface{}, len(values))
+		for i, v := range values {
+			cells[i] = v
+		}
+		cell, err := excelize.CoordinatesToCellName(1, rowNumber)
+		if err != nil {
+			return err
+		}
+		rowNumber++
+		return stream.SetRow(cell, cells)
+	}
+
+	if err := writeRow(headers); err != nil {
+		return err
+	}
+
+	err = s.catalog.partRepo.ForEachByStatus("approved", exportBatchSize, func(parts []models.LibraryPart) error {
+		for i := range parts {
+			if err := writeRow(toRow(&parts[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	return workbook.Write(w)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/b2b-platform/catalog-service/models"
+	"github.com/google/uuid"
+)
+
+// DuplicateThreshold is the score at or above which a part is flagged as a
+// likely duplicate of an existing part
+const DuplicateThreshold = 0.85
+
+// Weights of each signal in the overall duplicate score. When the parts share
+// no attributes the attribute weight is spread over the other signals.
+const (
+	partNumberWeight   = 0.5
+	manufacturerWeight = 0.2
+	nameWeight         = 0.2
+	attributeWeight    = 0.1
+)
+
+// corporate suffixes ignored when comparing manufacturer names
+var manufacturerStopWords = map[string]bool{
+	"INC": true, "INCORPORATED": true, "CORP": true, "CORPORATION": true, "CO": true,
+	"COMPANY": true, "LTD": true, "LIMITED": true, "LLC": true, "GMBH": true, "AG": true,
+	"SA": true, "SRL": true, "BV": true, "NV": true, "PLC": true, "KG": true, "THE": true,
+}
+
+// NormalizePartNumber reduces a manufacturer part number to upper-case letters
+// and digits, so "ab-123 / x", "AB123X" and "Ab 123.x" compare equal
+func NormalizePartNumber(partNumber string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(partNumber) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// DuplicateMatch is how closely an existing part resembles a submitted one
+type DuplicateMatch struct {
+	CandidateID       uuid.UUID
+	Score             float64
+	PartNumberScore   float64
+	ManufacturerScore float64
+	NameScore         float64
+	AttributeScore    *float64
+}
+
+// matchParts scores an existing part against a submitted part on part number,
+// manufacturer, name and shared attribute values
+func matchParts(part, candidate *models.LibraryPart) DuplicateMatch {
+	match := DuplicateMatch{
+		CandidateID:     candidate.ID,
+		PartNumberScore: stringSimilarity(NormalizePartNumber(part.PartNumber), NormalizePartNumber(candidate.PartNumber)),
+		NameScore:       tokenSimilarity(part.Name, candidate.Name),
+	}
+
+	if part.ManufacturerID == candidate.ManufacturerID {
+		match.ManufacturerScore = 1
+	} else {
+		match.ManufacturerScore = manufacturerSimilarity(part.Manufacturer.Name, candidate.Manufacturer.Name)
+	}
+
+	weights := partNumberWeight + manufacturerWeight + nameWeight
+	score := match.PartNumberScore*partNumberWeight +
+		match.ManufacturerScore*manufacturerWeight +
+		match.NameScore*nameWeight
+
+	if attrScore, ok := attributeSimilarity(part.PartAttributes, candidate.PartAttributes); ok {
+		match.AttributeScore = &attrScore
+		weights += attributeWeight
+		score += attrScore * attributeWeight
+	}
+
+	match.Score = score / weights
+	return match
+}
+
+// stringSimilarity is 1 minus the Levenshtein distance over the longer length
+func stringSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// tokenSimilarity is the Dice coefficient of the two strings' word sets
+func tokenSimilarity(a, b string) float64 {
+	return diceCoefficient(tokenize(a, nil), tokenize(b, nil))
+}
+
+func manufacturerSimilarity(a, b string) float64 {
+	ta, tb := tokenize(a, manufacturerStopWords), tokenize(b, manufacturerStopWords)
+	if strings.Join(ta, "") == strings.Join(tb, "") && len(ta) > 0 {
+		return 1
+	}
+	return diceCoefficient(ta, tb)
+}
+
+func tokenize(s string, stopWords map[string]bool) []string {
+	fields := strings.FieldsFunc(strings.ToUpper(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := fields[:0]
+	for _, f := range fields {
+		if !stopWords[f] {
+			tokens = append(tokens, f)
+		}
+	}
+	return tokens
+}
+
+func diceCoefficient(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	set := make(map[string]bool, len(a))
+	for _, t := range a {
+		set[t] = true
+	}
+	seen := make(map[string]bool, len(b))
+	shared := 0
+	for _, t := range b {
+		if set[t] && !seen[t] {
+			shared++
+		}
+		seen[t] = true
+	}
+	return 2 * float64(shared) / float64(len(set)+len(seen))
+}
+
+// attributeSimilarity is the share of attributes present on both parts whose
+// values agree. ok is false when the parts have no attribute in common.
+func attributeSimilarity(a, b []models.PartAttribute) (score float64, ok bool) {
+	values := make(map[uuid.UUID]string, len(a))
+	for _, pa := range a {
+		values[pa.AttributeID] = normalizeAttributeValue(pa.Value)
+	}
+
+	shared, equal := 0, 0
+	for _, pb := range b {
+		va, found := values[pb.AttributeID]
+		if !found {
+			continue
+		}
+		shared++
+		if va == normalizeAttributeValue(pb.Value) {
+			equal++
+		}
+	}
+	if shared == 0 {
+		return 0, false
+	}
+	return float64(equal) / float64(shared), true
+}
+
+func normalizeAttributeValue(v string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(v)), " ")
+}
+
+// LookupPartNumbers finds approved parts for part numbers as written in
+// external documents, ignoring case and punctuation. Results are keyed by
+// the part number as given; numbers without a match are left out.
+func (s *CatalogService) LookupPartNumbers(partNumbers []string) (map[string][]models.LibraryPart, error) {
+	byNormalized := make(map[string][]string, len(partNumbers))
+	normalized := make([]string, 0, len(partNumbers))
+	for _, number := range partNumbers {
+		key := NormalizePartNumber(number)
+		if key == "" {
+			continue
+		}
+		if _, ok := byNormalized[key]; !ok {
+			normalized = append(normalized, key)
+		}
+		byNormalized[key] = append(byNormalized[key], number)
+	}
+
+	parts, err := s.partRepo.FindByNormalizedPartNumbers(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make(map[string][]models.LibraryPart)
+	for _, part := range parts {
+		for _, number := range byNormalized[part.NormalizedPartNumber] {
+			matches[number] = append(matches[number], part)
+		}
+	}
+	return matches, nil
+}
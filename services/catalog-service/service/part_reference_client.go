@@ -0,0 +1,98 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/b2b-platform/shared/auth"
+	"github.com/google/uuid"
+)
+
+// PartReferences is a service holding references to catalog parts, such as
+// equipment BOMs or marketplace listings, that must follow a merged part
+type PartReferences interface {
+	ReplacePart(oldPartID, newPartID uuid.UUID) (int64, error)
+}
+
+// PartReferenceClient re-points another service's references to a part
+type PartReferenceClient struct {
+	name       string
+	baseURL    string
+	httpClient *http.Client
+	jwtService *auth.JWTService
+}
+
+// NewEquipmentReferenceClient re-points equipment BOM nodes and compatibility mappings
+func NewEquipmentReferenceClient() *PartReferenceClient {
+	return newPartReferenceClient("equipment-service", "EQUIPMENT_SERVICE_URL", "http://localhost:8004")
+}
+
+// NewMarketplaceReferenceClient re-points marketplace listings
+func NewMarketplaceReferenceClient() *PartReferenceClient {
+	return newPartReferenceClient("marketplace-service", "MARKETPLACE_SERVICE_URL", "http://localhost:8005")
+}
+
+func newPartReferenceClient(name, urlEnv, defaultURL string) *PartReferenceClient {
+	baseURL := os.Getenv(urlEnv)
+	if baseURL == "" {
+		baseURL = defaultURL
+	}
+
+	return &PartReferenceClient{
+		name:    name,
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		jwtService: auth.NewJWTService(),
+	}
+}
+
+// ReplacePart moves the service's references from oldPartID to newPartID and
+// returns how many were moved. Merges span every tenant, so the client signs
+// in as the catalog service itself.
+func (c *PartReferenceClient) ReplacePart(oldPartID, newPartID uuid.UUID) (int64, error) {
+	token, err := c.jwtService.GenerateToken(uuid.Nil, uuid.Nil, "catalog-service", []string{"super_admin"})
+	if err != nil {
+		return 0, fmt.Errorf("failed to sign service token: %w", err)
+	}
+
+	reqBody, err := json.Marshal(map[string]uuid.UUID{
+		"part_id":     oldPartID,
+		"merged_into": newPartID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", c.baseURL+"/api/v1/parts/replace", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call %s: %w", c.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("%s returned status %d: %s", c.name, resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Updated int64 `json:"updated"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return response.Updated, nil
+}
@@ -0,0 +1,816 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/catalog-service/models"
+	"github.com/b2b-platform/catalog-service/repository"
+	"github.com/b2b-platform/shared/events"
+	"github.com/google/uuid"
+)
+
+// Versioned entity types
+const (
+	EntityPart      = "part"
+	EntityAttribute = "attribute"
+	EntityCategory  = "category"
+)
+
+// Version actions
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionApprove  = "approve"
+	ActionReject   = "reject"
+	ActionMerge    = "merge"
+	ActionRollback = "rollback"
+)
+
+var (
+	ErrInvalidEntityType  = errors.New("unknown entity type")
+	ErrNoChanges          = errors.New("nothing to change")
+	ErrRevisionNotPending = errors.New("revision is not pending")
+	ErrRevisionOutdated   = errors.New("part changed after the revision was submitted")
+	ErrCannotRestore      = errors.New("version can no longer be restored")
+	ErrPartChanged        = errors.New("part was changed concurrently, reload it and retry")
+	ErrSelfReview         = errors.New("revisions must be reviewed by someone other than their submitter")
+)
+
+// PartSnapshot is the versioned state of a library part
+type PartSnapshot struct {
+	PartNumber     string            `json:"part_number"`
+	ManufacturerID uuid.UUID         `json:"manufacturer_id"`
+	CategoryID     uuid.UUID         `json:"category_id"`
+	Name           string            `json:"name"`
+	Description    string            `json:"description"`
+	Status         string            `json:"status"`
+	RejectedReason string            `json:"rejected_reason"`
+	Attributes     map[string]string `json:"attributes"` // Attribute ID -> normalized value
+}
+
+// CategorySnapshot is the versioned state of a category and its own template entries
+type CategorySnapshot struct {
+	Name        string                           `json:"name"`
+	Code        string                           `json:"code"`
+	Description string                           `json:"description"`
+	ParentID    *uuid.UUID                       `json:"parent_id"`
+	IsActive    bool                             `json:"is_active"`
+	Template    map[string]TemplateEntrySnapshot `json:"template"` // Attribute ID -> settings
+}
+
+type TemplateEntrySnapshot struct {
+	IsRequired bool `json:"is_required"`
+	SortOrder  int  `json:"sort_order"`
+}
+
+// AttributeSnapshot is the versioned state of an attribute definition
+type AttributeSnapshot struct {
+	Name          string `json:"name"`
+	Code          string `json:"code"`
+	DataType      string `json:"data_type"`
+	Unit          string `json:"unit"`
+	AllowedValues string `json:"allowed_values"`
+	IsRequired    bool   `json:"is_required"`
+	IsSearchable  bool   `json:"is_searchable"`
+}
+
+// FieldChange is one changed field in a version diff
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// PartUpdate is an edit to a part. Nil fields are left unchanged; listed
+// attributes are set and the part's other attribute values are kept.
+type PartUpdate struct {
+	PartNumber     *string                `json:"part_number"`
+	ManufacturerID *uuid.UUID             `json:"manufacturer_id"`
+	CategoryID     *uuid.UUID             `json:"category_id"`
+	Name           *string                `json:"name"`
+	Description    *string                `json:"description"`
+	Attributes     []models.PartAttribute `json:"attributes"`
+}
+
+// CategoryUpdate is an edit to a category. Nil fields are left unchanged.
+type CategoryUpdate struct {
+	Name        *string `json:"name"`
+	Code        *string `json:"code"`
+	Description *string `json:"description"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// AttributeUpdate is an edit to an attribute definition. Nil fields are left unchanged.
+type AttributeUpdate struct {
+	Name          *string `json:"name"`
+	DataType      *string `json:"data_type"`
+	Unit          *string `json:"unit"`
+	AllowedValues *string `json:"allowed_values"`
+	IsRequired    *bool   `json:"is_required"`
+	IsSearchable  *bool   `json:"is_searchable"`
+}
+
+func snapshotPart(part *models.LibraryPart) PartSnapshot {
+	snapshot := PartSnapshot{
+		PartNumber:     part.PartNumber,
+		ManufacturerID: part.ManufacturerID,
+		CategoryID:     part.CategoryID,
+		Name:           part.Name,
+		Description:    part.Description,
+		Status:         part.Status,
+		RejectedReason: part.RejectedReason,
+		Attributes:     make(map[string]string, len(part.PartAttributes)),
+	}
+	for _, pa := range part.PartAttributes {
+		snapshot.Attributes[pa.AttributeID.String()] = pa.Value
+	}
+	return snapshot
+}
+
+func (s *CatalogService) snapshotCategory(category *models.Category) (CategorySnapshot, error) {
+	entries, err := s.categoryRepo.ListTemplateAttributes([]uuid.UUID{category.ID})
+	if err != nil {
+		return CategorySnapshot{}, err
+	}
+
+	snapshot := CategorySnapshot{
+		Name:        category.Name,
+		Code:        category.Code,
+		Description: category.Description,
+		ParentID:    category.ParentID,
+		IsActive:    category.IsActive,
+		Template:    make(map[string]TemplateEntrySnapshot, len(entries)),
+	}
+	for _, entry := range entries {
+		snapshot.Template[entry.AttributeID.String()] = TemplateEntrySnapshot{
+			IsRequired: entry.IsRequired,
+			SortOrder:  entry.SortOrder,
+		}
+	}
+	return snapshot, nil
+}
+
+func snapshotAttribute(attribute *models.Attribute) AttributeSnapshot {
+	return AttributeSnapshot{
+		Name:          attribute.Name,
+		Code:          attribute.Code,
+		DataType:      attribute.DataType,
+		Unit:          attribute.Unit,
+		AllowedValues: attribute.AllowedValues,
+		IsRequired:    attribute.IsRequired,
+		IsSearchable:  attribute.IsSearchable,
+	}
+}
+
+// diffSnapshots compares two snapshots field by field. Nested maps such as
+// part attributes are compared per key, e.g. "attributes.<id>". A nil before
+// reports every field as new.
+func diffSnapshots(before, after interface{}) (map[string]FieldChange, error) {
+	oldFields := make(map[string]interface{})
+	if before != nil {
+		if err := flattenSnapshot(before, oldFields); err != nil {
+			return nil, err
+		}
+	}
+	newFields := make(map[string]interface{})
+	if err := flattenSnapshot(after, newFields); err != nil {
+		return nil, err
+	}
+
+	diff := make(map[string]FieldChange)
+	for field, value := range newFields {
+		if old, ok := oldFields[field]; !ok || !reflect.DeepEqual(old, value) {
+			diff[field] = FieldChange{Old: oldFields[field], New: value}
+		}
+	}
+	for field, old := range oldFields {
+		if _, ok := newFields[field]; !ok {
+			diff[field] = FieldChange{Old: old, New: nil}
+		}
+	}
+	return diff, nil
+}
+
+func flattenSnapshot(snapshot interface{}, fields map[string]interface{}) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	flattenInto("", values, fields)
+	return nil
+}
+
+func flattenInto(prefix string, values map[string]interface{}, fields map[string]interface{}) {
+	for key, value := range values {
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenInto(prefix+key+".", nested, fields)
+			continue
+		}
+		fields[prefix+key] = value
+	}
+}
+
+// recordVersion appends a version with the entity's new state and what changed
+func (s *CatalogService) recordVersion(entityType string, entityID uuid.UUID, action string, before, after interface{}, actorID uuid.UUID, comment string) error {
+	diff, err := diffSnapshots(before, after)
+	if err != nil {
+		return err
+	}
+	diffJSON, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	snapshotJSON, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+
+	return s.versionRepo.Create(&models.CatalogVersion{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Snapshot:   string(snapshotJSON),
+		Diff:       string(diffJSON),
+		ActorID:    actorID,
+		Comment:    comment,
+	})
+}
+
+// GetHistory returns an entity's versions, newest first
+func (s *CatalogService) GetHistory(entityType string, entityID uuid.UUID, limit, offset int) ([]models.CatalogVersion, error) {
+	switch entityType {
+	case EntityPart, EntityAttribute, EntityCategory:
+	default:
+		return nil, ErrInvalidEntityType
+	}
+	return s.versionRepo.List(entityType, entityID, limit, offset)
+}
+
+// Rollback restores an entity to the state recorded in one of its versions,
+// recording the restore as a new version. Rolling back an approved part is an
+// edit like any other and is returned as a revision held for review.
+func (s *CatalogService) Rollback(entityType string, entityID uuid.UUID, version int, actorID uuid.UUID) (*models.PartRevision, error) {
+	target, err := s.versionRepo.Get(entityType, entityID, version)
+	if err != nil {
+		return nil, err
+	}
+	comment := fmt.Sprintf("rolled back to version %d", version)
+
+	switch entityType {
+	case EntityPart:
+		var snapshot PartSnapshot
+		if err := json.Unmarshal([]byte(target.Snapshot), &snapshot); err != nil {
+			return nil, err
+		}
+		return s.rollbackPart(entityID, snapshot, actorID, comment)
+	case EntityCategory:
+		var snapshot CategorySnapshot
+		if err := json.Unmarshal([]byte(target.Snapshot), &snapshot); err != nil {
+			return nil, err
+		}
+		return nil, s.rollbackCategory(entityID, snapshot, actorID, comment)
+	case EntityAttribute:
+		var snapshot AttributeSnapshot
+		if err := json.Unmarshal([]byte(target.Snapshot), &snapshot); err != nil {
+			return nil, err
+		}
+		return nil, s.rollbackAttribute(entityID, snapshot, actorID, comment)
+	default:
+		return nil, ErrInvalidEntityType
+	}
+}
+
+// Parts
+
+// UpdatePart edits a part. Pending and rejected parts are changed directly,
+// rejected ones going back into review. Edits to approved parts are held as
+// a revision, returned here, until a reviewer approves them.
+func (s *CatalogService) UpdatePart(partID uuid.UUID, update *PartUpdate, actorID uuid.UUID) (*models.PartRevision, error) {
+	part, err := s.partRepo.GetByID(partID)
+	if err != nil {
+		return nil, err
+	}
+	if part.Status == "merged" {
+		return nil, fmt.Errorf("%w: edit %s instead", ErrPartAlreadyMerged, part.DuplicateOf)
+	}
+
+	before := snapshotPart(part)
+	after := before
+	after.Attributes = make(map[string]string, len(before.Attributes))
+	for id, value := range before.Attributes {
+		after.Attributes[id] = value
+	}
+
+	if update.PartNumber != nil {
+		after.PartNumber = strings.TrimSpace(*update.PartNumber)
+	}
+	if update.Name != nil {
+		after.Name = *update.Name
+	}
+	if update.Description != nil {
+		after.Description = *update.Description
+	}
+	if update.ManufacturerID != nil {
+		if _, err := s.manufacturerRepo.GetByID(*update.ManufacturerID); err != nil {
+			return nil, err
+		}
+		after.ManufacturerID = *update.ManufacturerID
+	}
+	if update.CategoryID != nil {
+		if _, err := s.categoryRepo.GetByID(*update.CategoryID); err != nil {
+			return nil, err
+		}
+		after.CategoryID = *update.CategoryID
+	}
+
+	rawValues := make(map[string]string, len(update.Attributes))
+	if len(update.Attributes) > 0 {
+		template, err := s.GetAttributeTemplate(after.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range update.Attributes {
+			pa := &update.Attributes[i]
+			pa.PartID = part.ID
+			attribute, err := s.templateAttribute(template, pa.AttributeID)
+			if err != nil {
+				return nil, err
+			}
+			if err := NormalizePartAttribute(attribute, pa); err != nil {
+				return nil, err
+			}
+			after.Attributes[pa.AttributeID.String()] = pa.Value
+			rawValues[pa.AttributeID.String()] = pa.RawValue
+		}
+	}
+
+	return s.changePart(part, before, after, rawValues, actorID)
+}
+
+// changePart applies an edit directly, or holds it for review if the part is approved
+func (s *CatalogService) changePart(part *models.LibraryPart, before, after PartSnapshot, rawValues map[string]string, actorID uuid.UUID) (*models.PartRevision, error) {
+	comment := ""
+	if part.Status == "rejected" {
+		after.Status = "pending"
+		after.RejectedReason = ""
+		comment = "resubmitted for review"
+	}
+
+	diff, err := diffSnapshots(before, after)
+	if err != nil {
+		return nil, err
+	}
+	if len(diff) == 0 {
+		return nil, nil
+	}
+
+	if part.Status == "approved" {
+		return s.submitPartRevision(part.ID, after, diff, actorID)
+	}
+
+	err = s.withLockedPart(part.ID, func(tx *CatalogService, locked *models.LibraryPart) error {
+		changed, err := diffSnapshots(before, snapshotPart(locked))
+		if err != nil {
+			return err
+		}
+		if len(changed) > 0 {
+			return ErrPartChanged
+		}
+		if err := tx.applyPartSnapshot(locked, after, rawValues); err != nil {
+			return err
+		}
+		*part = *locked
+		return tx.recordVersion(EntityPart, part.ID, ActionUpdate, before, after, actorID, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nil, s.publishPartChanged(events.EventCatalogPartUpdated, part)
+}
+
+// submitPartRevision holds a change to an approved part for review, against
+// the part's current version
+func (s *CatalogService) submitPartRevision(partID uuid.UUID, after PartSnapshot, diff map[string]FieldChange, actorID uuid.UUID) (*models.PartRevision, error) {
+	baseVersion, err := s.versionRepo.Latest(EntityPart, partID)
+	if err != nil {
+		return nil, err
+	}
+	changesJSON, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+	diffJSON, err := json.Marshal(diff)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &models.PartRevision{
+		PartID:      partID,
+		BaseVersion: baseVersion,
+		Changes:     string(changesJSON),
+		Diff:        string(diffJSON),
+		Status:      "pending",
+		SubmittedBy: actorID,
+	}
+	if err := s.versionRepo.CreateRevision(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// withLockedPart runs fn with the part locked, passing a copy of the service
+// whose part, attribute and version repositories write in that transaction.
+// Events are published by the caller once it has committed.
+func (s *CatalogService) withLockedPart(partID uuid.UUID, fn func(tx *CatalogService, part *models.LibraryPart) error) error {
+	return s.partRepo.WithLockedPart(partID, func(repos repository.PartTx, part *models.LibraryPart) error {
+		tx := *s
+		tx.partRepo = repos.Parts
+		tx.attributeRepo = repos.Attributes
+		tx.versionRepo = repos.Versions
+		return fn(&tx, part)
+	})
+}
+
+// applyPartSnapshot writes a snapshot's fields and attribute values to a part.
+// Values are re-validated against the attribute definitions. rawValues holds
+// the values as entered, by attribute ID; unchanged values keep their own.
+func (s *CatalogService) applyPartSnapshot(part *models.LibraryPart, snapshot PartSnapshot, rawValues map[string]string) error {
+	raw := make(map[string]string, len(part.PartAttributes)+len(rawValues))
+	for _, pa := range part.PartAttributes {
+		if snapshot.Attributes[pa.AttributeID.String()] == pa.Value {
+			raw[pa.AttributeID.String()] = pa.RawValue
+		}
+	}
+	for id, value := range rawValues {
+		raw[id] = value
+	}
+
+	partAttributes := make([]models.PartAttribute, 0, len(snapshot.Attributes))
+	keep := make([]uuid.UUID, 0, len(snapshot.Attributes))
+	for id, value := range snapshot.Attributes {
+		attributeID, err := uuid.Parse(id)
+		if err != nil {
+			return err
+		}
+		attribute, err := s.attributeRepo.GetByID(attributeID)
+		if err != nil {
+			return err
+		}
+		pa := models.PartAttribute{PartID: part.ID, AttributeID: attributeID, Value: value, RawValue: raw[id]}
+		if err := NormalizePartAttribute(attribute, &pa); err != nil {
+			return err
+		}
+		partAttributes = append(partAttributes, pa)
+		keep = append(keep, attributeID)
+	}
+
+	part.PartNumber = snapshot.PartNumber
+	part.NormalizedPartNumber = NormalizePartNumber(snapshot.PartNumber)
+	part.ManufacturerID = snapshot.ManufacturerID
+	part.CategoryID = snapshot.CategoryID
+	part.Name = snapshot.Name
+	part.Description = snapshot.Description
+	part.Status = snapshot.Status
+	part.RejectedReason = snapshot.RejectedReason
+
+	// Loaded associations would be saved back over the new foreign keys and values
+	part.Manufacturer = models.Manufacturer{}
+	part.Category = models.Category{}
+	part.PartAttributes = nil
+
+	if err := s.partRepo.Update(part); err != nil {
+		return err
+	}
+
+	for i := range partAttributes {
+		if err := s.attributeRepo.SetPartAttribute(&partAttributes[i]); err != nil {
+			return err
+		}
+	}
+	if err := s.attributeRepo.DeletePartAttributesExcept(part.ID, keep); err != nil {
+		return err
+	}
+	part.PartAttributes = partAttributes
+	return nil
+}
+
+func (s *CatalogService) ListPartRevisions(status string, limit, offset int) ([]models.PartRevision, error) {
+	return s.versionRepo.ListRevisions(status, limit, offset)
+}
+
+// ApprovePartRevision applies a reviewed edit to an approved part
+func (s *CatalogService) ApprovePartRevision(revisionID, reviewerID uuid.UUID) error {
+	revision, err := s.versionRepo.GetRevision(revisionID)
+	if err != nil {
+		return err
+	}
+	if revision.Status != "pending" {
+		return ErrRevisionNotPending
+	}
+	if revision.SubmittedBy == reviewerID {
+		return ErrSelfReview
+	}
+
+	var after PartSnapshot
+	if err := json.Unmarshal([]byte(revision.Changes), &after); err != nil {
+		return err
+	}
+
+	var part *models.LibraryPart
+	err = s.withLockedPart(revision.PartID, func(tx *CatalogService, locked *models.LibraryPart) error {
+		part = locked
+		latest, err := tx.versionRepo.Latest(EntityPart, part.ID)
+		if err != nil {
+			return err
+		}
+		if latest != revision.BaseVersion || part.Status != "approved" {
+			return ErrRevisionOutdated
+		}
+
+		before := snapshotPart(part)
+		after.Status = before.Status
+		after.RejectedReason = before.RejectedReason
+
+		if err := tx.applyPartSnapshot(part, after, nil); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		revision.Status = "approved"
+		revision.ReviewedBy = &reviewerID
+		revision.ReviewedAt = &now
+		if err := tx.versionRepo.UpdateRevision(revision); err != nil {
+			return err
+		}
+
+		comment := fmt.Sprintf("revision %s submitted by %s", revision.ID, revision.SubmittedBy)
+		return tx.recordVersion(EntityPart, part.ID, ActionUpdate, before, after, reviewerID, comment)
+	})
+	if err != nil {
+		return err
+	}
+	return s.publishPartApproved(part)
+}
+
+func (s *CatalogService) RejectPartRevision(revisionID, reviewerID uuid.UUID, reason string) error {
+	revision, err := s.versionRepo.GetRevision(revisionID)
+	if err != nil {
+		return err
+	}
+	if revision.Status != "pending" {
+		return ErrRevisionNotPending
+	}
+
+	now := time.Now()
+	revision.Status = "rejected"
+	revision.ReviewedBy = &reviewerID
+	revision.ReviewedAt = &now
+	revision.RejectedReason = reason
+	return s.versionRepo.UpdateRevision(revision)
+}
+
+// rollbackPart restores a part's content. Its review status is kept, so an
+// approved part stays approved; restoring it is submitted as a revision, and
+// the earlier data goes live once someone else approves it.
+func (s *CatalogService) rollbackPart(partID uuid.UUID, snapshot PartSnapshot, actorID uuid.UUID, comment string) (*models.PartRevision, error) {
+	var part *models.LibraryPart
+	var revision *models.PartRevision
+	err := s.withLockedPart(partID, func(tx *CatalogService, locked *models.LibraryPart) error {
+		part = locked
+		if part.Status == "merged" {
+			return fmt.Errorf("%w: merged parts cannot be rolled back", ErrCannotRestore)
+		}
+
+		before := snapshotPart(part)
+		snapshot.Status = before.Status
+		snapshot.RejectedReason = before.RejectedReason
+		if snapshot.Attributes == nil {
+			snapshot.Attributes = map[string]string{}
+		}
+
+		diff, err := diffSnapshots(before, snapshot)
+		if err != nil {
+			return err
+		}
+		if len(diff) == 0 {
+			return ErrNoChanges
+		}
+
+		if part.Status == "approved" {
+			revision, err = tx.submitPartRevision(part.ID, snapshot, diff, actorID)
+			return err
+		}
+
+		if err := tx.applyPartSnapshot(part, snapshot, nil); err != nil {
+			return err
+		}
+		return tx.recordVersion(EntityPart, part.ID, ActionRollback, before, snapshot, actorID, comment)
+	})
+	if err != nil || revision != nil {
+		return revision, err
+	}
+	return nil, s.publishPartChanged(events.EventCatalogPartUpdated, part)
+}
+
+// Categories
+
+func (s *CatalogService) UpdateCategory(categoryID uuid.UUID, update *CategoryUpdate, actorID uuid.UUID) (*models.Category, error) {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.snapshotCategory(category)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		category.Name = *update.Name
+	}
+	if update.Code != nil {
+		category.Code = *update.Code
+	}
+	if update.Description != nil {
+		category.Description = *update.Description
+	}
+	if update.IsActive != nil {
+		if category.IsActive && !*update.IsActive {
+			return nil, ErrRetireRequired
+		}
+		category.IsActive = *update.IsActive
+	}
+
+	if err := s.saveCategory(category); err != nil {
+		return nil, err
+	}
+
+	after := before
+	after.Name, after.Code, after.Description, after.IsActive = category.Name, category.Code, category.Description, category.IsActive
+	return category, s.recordVersion(EntityCategory, category.ID, ActionUpdate, before, after, actorID, "")
+}
+
+// saveCategory saves a category's own columns, not its loaded relationships
+func (s *CatalogService) saveCategory(category *models.Category) error {
+	category.Parent = nil
+	category.Children = nil
+	category.Parts = nil
+	return s.categoryRepo.Update(category)
+}
+
+// recordTemplateChange records a category version after its template changed
+func (s *CatalogService) recordTemplateChange(categoryID uuid.UUID, before CategorySnapshot, actorID uuid.UUID) error {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return err
+	}
+	after, err := s.snapshotCategory(category)
+	if err != nil {
+		return err
+	}
+	return s.recordVersion(EntityCategory, categoryID, ActionUpdate, before, after, actorID, "attribute template changed")
+}
+
+func (s *CatalogService) rollbackCategory(categoryID uuid.UUID, snapshot CategorySnapshot, actorID uuid.UUID, comment string) error {
+	category, err := s.categoryRepo.GetByID(categoryID)
+	if err != nil {
+		return err
+	}
+	before, err := s.snapshotCategory(category)
+	if err != nil {
+		return err
+	}
+	if snapshot.Template == nil {
+		snapshot.Template = map[string]TemplateEntrySnapshot{}
+	}
+
+	diff, err := diffSnapshots(before, snapshot)
+	if err != nil {
+		return err
+	}
+	if len(diff) == 0 {
+		return ErrNoChanges
+	}
+
+	// Retiring also reassigns parts and subcategories, which a rollback cannot replay
+	if category.IsActive && !snapshot.IsActive {
+		return fmt.Errorf("%w: %v", ErrCannotRestore, ErrRetireRequired)
+	}
+
+	entries := make([]models.CategoryAttribute, 0, len(snapshot.Template))
+	for id, settings := range snapshot.Template {
+		attributeID, err := uuid.Parse(id)
+		if err != nil {
+			return err
+		}
+		if _, err := s.attributeRepo.GetByID(attributeID); err != nil {
+			return err
+		}
+		entries = append(entries, models.CategoryAttribute{
+			CategoryID:  categoryID,
+			AttributeID: attributeID,
+			IsRequired:  settings.IsRequired,
+			SortOrder:   settings.SortOrder,
+		})
+	}
+
+	moved := !sameParent(category.ParentID, snapshot.ParentID)
+	if moved {
+		if err := s.moveCategory(category, snapshot.ParentID); err != nil {
+			if errors.Is(err, ErrCategoryCycle) {
+				return fmt.Errorf("%w: the earlier parent is now inside this category", ErrCannotRestore)
+			}
+			return err
+		}
+	}
+
+	category.Name = snapshot.Name
+	category.Code = snapshot.Code
+	category.Description = snapshot.Description
+	category.IsActive = snapshot.IsActive
+	if err := s.saveCategory(category); err != nil {
+		return err
+	}
+	if err := s.categoryRepo.ReplaceTemplate(categoryID, entries); err != nil {
+		return err
+	}
+
+	if err := s.recordVersion(EntityCategory, categoryID, ActionRollback, before, snapshot, actorID, comment); err != nil {
+		return err
+	}
+	if moved {
+		s.republishCategoryTree(category.Path)
+	}
+	return nil
+}
+
+// Attributes
+
+func (s *CatalogService) UpdateAttribute(attributeID uuid.UUID, update *AttributeUpdate, actorID uuid.UUID) (*models.Attribute, error) {
+	attribute, err := s.attributeRepo.GetByID(attributeID)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshotAttribute(attribute)
+
+	if update.Name != nil {
+		attribute.Name = *update.Name
+	}
+	if update.DataType != nil {
+		attribute.DataType = *update.DataType
+	}
+	if update.Unit != nil {
+		attribute.Unit = *update.Unit
+	}
+	if update.AllowedValues != nil {
+		attribute.AllowedValues = *update.AllowedValues
+	}
+	if update.IsRequired != nil {
+		attribute.IsRequired = *update.IsRequired
+	}
+	if update.IsSearchable != nil {
+		attribute.IsSearchable = *update.IsSearchable
+	}
+
+	if err := ValidateAttributeDefinition(attribute); err != nil {
+		return nil, err
+	}
+	if err := s.attributeRepo.Update(attribute); err != nil {
+		return nil, err
+	}
+	return attribute, s.recordVersion(EntityAttribute, attribute.ID, ActionUpdate, before, snapshotAttribute(attribute), actorID, "")
+}
+
+func (s *CatalogService) rollbackAttribute(attributeID uuid.UUID, snapshot AttributeSnapshot, actorID uuid.UUID, comment string) error {
+	attribute, err := s.attributeRepo.GetByID(attributeID)
+	if err != nil {
+		return err
+	}
+	before := snapshotAttribute(attribute)
+	if before == snapshot {
+		return ErrNoChanges
+	}
+
+	attribute.Name = snapshot.Name
+	attribute.Code = snapshot.Code
+	attribute.DataType = snapshot.DataType
+	attribute.Unit = snapshot.Unit
+	attribute.AllowedValues = snapshot.AllowedValues
+	attribute.IsRequired = snapshot.IsRequired
+	attribute.IsSearchable = snapshot.IsSearchable
+
+	if err := ValidateAttributeDefinition(attribute); err != nil {
+		return err
+	}
+	if err := s.attributeRepo.Update(attribute); err != nil {
+		return err
+	}
+	return s.recordVersion(EntityAttribute, attribute.ID, ActionRollback, before, snapshot, actorID, comment)
+}
@@ -41,6 +41,7 @@ func main() {
 	messageRepo := repository.NewMessageRepository(db)
 	disputeRepo := repository.NewDisputeRepository(db)
 	ratingRepo := repository.NewRatingRepository(db)
+	rfqQuestionRepo := repository.NewRFQQuestionRepository(db)
 
 	collaborationService := service.NewCollaborationService(
 		threadRepo,
@@ -51,6 +52,15 @@ func main() {
 	)
 	collaborationHandler := handlers.NewCollaborationHandler(collaborationService)
 
+	rfqClarificationService := service.NewRFQClarificationService(
+		threadRepo,
+		messageRepo,
+		rfqQuestionRepo,
+		service.NewProcurementClient(),
+		eventBus,
+	)
+	rfqClarificationHandler := handlers.NewRFQClarificationHandler(rfqClarificationService)
+
 	r := gin.Default()
 
 	// Health endpoints
@@ -110,6 +120,12 @@ func main() {
 		api.GET("/ratings", collaborationHandler.GetRatings)
 		api.GET("/ratings/average", collaborationHandler.GetAverageRating)
 		api.POST("/ratings", collaborationHandler.CreateRating)
+
+		// RFQ clarification endpoints
+		api.GET("/rfqs/:id/questions", rfqClarificationHandler.ListQuestions)
+		api.POST("/rfqs/:id/questions", rfqClarificationHandler.AskQuestion)
+		api.GET("/rfqs/:id/clarifications", rfqClarificationHandler.ListClarifications)
+		api.POST("/rfq-questions/:id/answer", rfqClarificationHandler.AnswerQuestion)
 	}
 
 	port := os.Getenv("PORT")
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/b2b-platform/collaboration-service/service"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RFQClarificationHandler struct {
+	service *service.RFQClarificationService
+}
+
+func NewRFQClarificationHandler(service *service.RFQClarificationService) *RFQClarificationHandler {
+	return &RFQClarificationHandler{service: service}
+}
+
+// AskQuestion lets an invited supplier ask a private question on an RFQ.
+// The supplier is identified by the caller's tenant.
+func (h *RFQClarificationHandler) AskQuestion(c *gin.Context) {
+	rfqID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rfq id"})
+		return
+	}
+
+	var req struct {
+		Question string `json:"question" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+	userID, _ := auth.GetUserID(c)
+
+	question, err := h.service.AskQuestion(rfqID, tenantID, userID, req.Question, c.GetHeader("Authorization"))
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, question)
+}
+
+func (h *RFQClarificationHandler) ListQuestions(c *gin.Context) {
+	rfqID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rfq id"})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+
+	questions, err := h.service.ListQuestions(rfqID, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+func (h *RFQClarificationHandler) ListClarifications(c *gin.Context) {
+	rfqID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rfq id"})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+
+	clarifications, err := h.service.ListClarifications(rfqID, tenantID, c.GetHeader("Authorization"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, clarifications)
+}
+
+func (h *RFQClarificationHandler) AnswerQuestion(c *gin.Context) {
+	questionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid question id"})
+		return
+	}
+
+	var req service.AnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+	userID, _ := auth.GetUserID(c)
+
+	question, err := h.service.AnswerQuestion(questionID, tenantID, userID, req, c.GetHeader("Authorization"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrQuestionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNotRFQOwner):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrQuestionAnswered):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrAmendmentNeedsBroadcast):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
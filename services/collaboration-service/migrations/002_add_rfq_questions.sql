@@ -0,0 +1,25 @@
+-- RFQ Questions table (clarification Q&A on RFQs)
+CREATE TABLE IF NOT EXISTS collaboration.rfq_questions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    rfq_id UUID NOT NULL,
+    thread_id UUID NOT NULL REFERENCES collaboration.chat_threads(id) ON DELETE CASCADE,
+    supplier_id UUID NOT NULL,
+    asked_by UUID NOT NULL,
+    question TEXT NOT NULL,
+    answer TEXT,
+    status VARCHAR(50) DEFAULT 'open',
+    is_broadcast BOOLEAN DEFAULT false,
+    answered_by UUID,
+    answered_at TIMESTAMP,
+    amendment_id UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rfq_questions_tenant_id ON collaboration.rfq_questions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_rfq_questions_rfq_id ON collaboration.rfq_questions(rfq_id);
+CREATE INDEX IF NOT EXISTS idx_rfq_questions_thread_id ON collaboration.rfq_questions(thread_id);
+CREATE INDEX IF NOT EXISTS idx_rfq_questions_supplier_id ON collaboration.rfq_questions(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_rfq_questions_status ON collaboration.rfq_questions(status);
+CREATE INDEX IF NOT EXISTS idx_rfq_questions_is_broadcast ON collaboration.rfq_questions(is_broadcast);
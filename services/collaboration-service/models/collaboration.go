@@ -11,7 +11,7 @@ type ChatThread struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	TenantID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenant_id"`
 	Title       string         `gorm:"type:varchar(255)" json:"title"`
-	ThreadType  string         `gorm:"type:varchar(50);not null;index" json:"thread_type"` // order, rfq, quote, dispute, general
+	ThreadType  string         `gorm:"type:varchar(50);not null;index" json:"thread_type"` // order, rfq, rfq_question, rfq_broadcast, quote, dispute, general
 	ReferenceID *uuid.UUID     `gorm:"type:uuid;index" json:"reference_id,omitempty"` // ID of related order/RFQ/etc
 	IsArchived  bool           `gorm:"default:false" json:"is_archived"`
 	CreatedBy   uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
@@ -49,7 +49,7 @@ type ChatMessage struct {
 	ThreadID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"thread_id"`
 	SenderID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"sender_id"`
 	Message     string         `gorm:"type:text;not null" json:"message"`
-	MessageType string         `gorm:"type:varchar(50);default:'text'" json:"message_type"` // text, file, system
+	MessageType string         `gorm:"type:varchar(50);default:'text'" json:"message_type"` // text, file, system, question, answer, broadcast
 	IsRead      bool           `gorm:"default:false" json:"is_read"`
 	IsEdited    bool           `gorm:"default:false" json:"is_edited"`
 	IsDeleted   bool           `gorm:"default:false" json:"is_deleted"`
@@ -83,6 +83,32 @@ func (MessageFile) TableName() string {
 	return "collaboration.message_files"
 }
 
+// RFQQuestion is a supplier's clarification question on an RFQ. The question and
+// the buyer's reply are posted to a private rfq_question thread between the
+// buyer and that supplier. Broadcast answers are also posted, without the asking
+// supplier's identity, to the RFQ's shared rfq_broadcast thread.
+type RFQQuestion struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"` // Buyer tenant owning the RFQ
+	RFQID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"rfq_id"`
+	ThreadID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"thread_id"`
+	SupplierID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"supplier_id"`
+	AskedBy     uuid.UUID  `gorm:"type:uuid;not null" json:"asked_by"`
+	Question    string     `gorm:"type:text;not null" json:"question"`
+	Answer      string     `gorm:"type:text" json:"answer,omitempty"`
+	Status      string     `gorm:"type:varchar(50);default:'open';index" json:"status"` // open, answered
+	IsBroadcast bool       `gorm:"default:false;index" json:"is_broadcast"`
+	AnsweredBy  *uuid.UUID `gorm:"type:uuid" json:"answered_by,omitempty"`
+	AnsweredAt  *time.Time `json:"answered_at,omitempty"`
+	AmendmentID *uuid.UUID `gorm:"type:uuid" json:"amendment_id,omitempty"` // RFQ amendment raised with the answer
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (RFQQuestion) TableName() string {
+	return "collaboration.rfq_questions"
+}
+
 type Dispute struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	TenantID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenant_id"`
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"github.com/b2b-platform/collaboration-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RFQQuestionRepository struct {
+	db *gorm.DB
+}
+
+func NewRFQQuestionRepository(db *gorm.DB) *RFQQuestionRepository {
+	return &RFQQuestionRepository{db: db}
+}
+
+func (r *RFQQuestionRepository) Create(question *models.RFQQuestion) error {
+	return r.db.Create(question).Error
+}
+
+func (r *RFQQuestionRepository) GetByID(id uuid.UUID) (*models.RFQQuestion, error) {
+	var question models.RFQQuestion
+	err := r.db.Where("id = ?", id).First(&question).Error
+	return &question, err
+}
+
+func (r *RFQQuestionRepository) ListByRFQ(rfqID uuid.UUID) ([]models.RFQQuestion, error) {
+	var questions []models.RFQQuestion
+	err := r.db.Where("rfq_id = ?", rfqID).Order("created_at ASC").Find(&questions).Error
+	return questions, err
+}
+
+func (r *RFQQuestionRepository) ListBroadcast(rfqID uuid.UUID) ([]models.RFQQuestion, error) {
+	var questions []models.RFQQuestion
+	err := r.db.Where("rfq_id = ? AND is_broadcast = ?", rfqID, true).
+		Order("answered_at ASC").Find(&questions).Error
+	return questions, err
+}
+
+func (r *RFQQuestionRepository) Update(question *models.RFQQuestion) error {
+	return r.db.Save(question).Error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"errors"
+
 	"github.com/b2b-platform/collaboration-service/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -25,6 +27,19 @@ func (r *ThreadRepository) GetByID(id uuid.UUID) (*models.ChatThread, error) {
 	return &thread, err
 }
 
+// GetByReference returns the first thread of the given type attached to a
+// referenced entity, or nil if there is none yet
+func (r *ThreadRepository) GetByReference(threadType string, referenceID uuid.UUID) (*models.ChatThread, error) {
+	var thread models.ChatThread
+	err := r.db.Preload("Participants").
+		Where("thread_type = ? AND reference_id = ?", threadType, referenceID).
+		Order("created_at ASC").First(&thread).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &thread, err
+}
+
 func (r *ThreadRepository) List(tenantID uuid.UUID, limit, offset int) ([]models.ChatThread, error) {
 	var threads []models.ChatThread
 	query := r.db.Preload("Participants").Where("tenant_id = ?", tenantID)
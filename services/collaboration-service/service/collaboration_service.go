@@ -9,6 +9,7 @@ import (
 type ThreadRepository interface {
 	Create(thread *models.ChatThread) error
 	GetByID(id uuid.UUID) (*models.ChatThread, error)
+	GetByReference(threadType string, referenceID uuid.UUID) (*models.ChatThread, error)
 	List(tenantID uuid.UUID, limit, offset int) ([]models.ChatThread, error)
 	GetUserThreads(userID, tenantID uuid.UUID) ([]models.ChatThread, error)
 	AddParticipant(participant *models.ThreadParticipant) error
@@ -15,6 +15,9 @@ type MockThreadRepository struct {
 }
 
 func (m *MockThreadRepository) Create(thread *models.ChatThread) error {
+	if thread.ID == uuid.Nil {
+		thread.ID = uuid.New()
+	}
 	if m.threads == nil {
 		m.threads = make(map[uuid.UUID]*models.ChatThread)
 	}
@@ -29,6 +32,15 @@ func (m *MockThreadRepository) GetByID(id uuid.UUID) (*models.ChatThread, error)
 	return nil, nil
 }
 
+func (m *MockThreadRepository) GetByReference(threadType string, referenceID uuid.UUID) (*models.ChatThread, error) {
+	for _, thread := range m.threads {
+		if thread.ThreadType == threadType && thread.ReferenceID != nil && *thread.ReferenceID == referenceID {
+			return thread, nil
+		}
+	}
+	return nil, nil
+}
+
 func (m *MockThreadRepository) List(tenantID uuid.UUID, limit, offset int) ([]models.ChatThread, error) {
 	var result []models.ChatThread
 	for _, thread := range m.threads {
@@ -69,6 +81,9 @@ type MockMessageRepository struct {
 }
 
 func (m *MockMessageRepository) Create(message *models.ChatMessage) error {
+	if message.ID == uuid.Nil {
+		message.ID = uuid.New()
+	}
 	if m.messages == nil {
 		m.messages = make(map[uuid.UUID]*models.ChatMessage)
 	}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RFQClient is the subset of procurement-service used by RFQ clarifications
+type RFQClient interface {
+	GetSupplierRFQ(authToken string, rfqID uuid.UUID) (*RFQInfo, error)
+	GetRFQ(authToken string, rfqID uuid.UUID) (*RFQInfo, error)
+	AmendRFQ(authToken string, rfqID uuid.UUID, req AmendRFQRequest) (*RFQAmendment, error)
+}
+
+type ProcurementClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewProcurementClient() *ProcurementClient {
+	baseURL := os.Getenv("PROCUREMENT_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8006"
+	}
+
+	return &ProcurementClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+type RFQInvitationInfo struct {
+	SupplierID uuid.UUID `json:"supplier_id"`
+	Status     string    `json:"status"`
+}
+
+type RFQInfo struct {
+	ID          uuid.UUID           `json:"id"`
+	TenantID    uuid.UUID           `json:"tenant_id"`
+	RFQNumber   string              `json:"rfq_number"`
+	Status      string              `json:"status"`
+	DueDate     time.Time           `json:"due_date"`
+	Invitations []RFQInvitationInfo `json:"invitations"`
+}
+
+type AmendRFQRequest struct {
+	Description string     `json:"description"`
+	NewDueDate  *time.Time `json:"new_due_date,omitempty"`
+	ReferenceID *uuid.UUID `json:"reference_id,omitempty"`
+}
+
+type RFQAmendment struct {
+	ID              uuid.UUID  `json:"id"`
+	AmendmentNumber int        `json:"amendment_number"`
+	NewDueDate      *time.Time `json:"new_due_date,omitempty"`
+}
+
+// GetSupplierRFQ fetches the supplier view of an RFQ. It fails unless the caller's
+// company is on the RFQ's distribution list.
+func (c *ProcurementClient) GetSupplierRFQ(authToken string, rfqID uuid.UUID) (*RFQInfo, error) {
+	url := fmt.Sprintf("%s/api/v1/supplier/rfqs/%s", c.baseURL, rfqID.String())
+
+	var rfq RFQInfo
+	if err := c.do("GET", url, authToken, nil, http.StatusOK, &rfq); err != nil {
+		return nil, err
+	}
+	return &rfq, nil
+}
+
+// GetRFQ fetches the buyer view of an RFQ including its invitations
+func (c *ProcurementClient) GetRFQ(authToken string, rfqID uuid.UUID) (*RFQInfo, error) {
+	url := fmt.Sprintf("%s/api/v1/rfqs/%s", c.baseURL, rfqID.String())
+
+	var rfq RFQInfo
+	if err := c.do("GET", url, authToken, nil, http.StatusOK, &rfq); err != nil {
+		return nil, err
+	}
+	return &rfq, nil
+}
+
+func (c *ProcurementClient) AmendRFQ(authToken string, rfqID uuid.UUID, req AmendRFQRequest) (*RFQAmendment, error) {
+	url := fmt.Sprintf("%s/api/v1/rfqs/%s/amendments", c.baseURL, rfqID.String())
+
+	var amendment RFQAmendment
+	if err := c.do("POST", url, authToken, req, http.StatusCreated, &amendment); err != nil {
+		return nil, err
+	}
+	return &amendment, nil
+}
+
+func (c *ProcurementClient) do(method, url, authToken string, body interface{}, expectedStatus int, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reqBody = bytes.NewBuffer(data)
+	}
+
+	httpReq, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", authToken)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to call procurement service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("procurement service returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/b2b-platform/collaboration-service/models"
+	"github.com/b2b-platform/shared/events"
+	"github.com/google/uuid"
+)
+
+const (
+	ThreadTypeRFQQuestion  = "rfq_question"
+	ThreadTypeRFQBroadcast = "rfq_broadcast"
+)
+
+var (
+	ErrQuestionNotFound        = errors.New("question not found")
+	ErrNotRFQOwner             = errors.New("only the buyer who issued the RFQ can answer questions")
+	ErrQuestionAnswered        = errors.New("question has already been answered")
+	ErrAmendmentNeedsBroadcast = errors.New("an RFQ amendment requires the answer to be broadcast")
+)
+
+type RFQQuestionRepository interface {
+	Create(question *models.RFQQuestion) error
+	GetByID(id uuid.UUID) (*models.RFQQuestion, error)
+	ListByRFQ(rfqID uuid.UUID) ([]models.RFQQuestion, error)
+	ListBroadcast(rfqID uuid.UUID) ([]models.RFQQuestion, error)
+	Update(question *models.RFQQuestion) error
+}
+
+// AnswerRequest is the buyer's reply to a clarification question
+type AnswerRequest struct {
+	Answer    string `json:"answer" binding:"required"`
+	Broadcast bool   `json:"broadcast"`
+	// NewDueDate, when set, raises an RFQ amendment extending the due date.
+	// Only allowed for broadcast answers so every supplier gets the same deadline.
+	NewDueDate *time.Time `json:"new_due_date,omitempty"`
+}
+
+// RFQClarification is a broadcast question and answer with the asking supplier removed
+type RFQClarification struct {
+	ID          uuid.UUID  `json:"id"`
+	RFQID       uuid.UUID  `json:"rfq_id"`
+	Question    string     `json:"question"`
+	Answer      string     `json:"answer"`
+	AnsweredAt  *time.Time `json:"answered_at,omitempty"`
+	AmendmentID *uuid.UUID `json:"amendment_id,omitempty"`
+}
+
+type RFQClarificationService struct {
+	threadRepo   ThreadRepository
+	messageRepo  MessageRepository
+	questionRepo RFQQuestionRepository
+	rfqClient    RFQClient
+	eventBus     events.EventBus
+}
+
+func NewRFQClarificationService(
+	threadRepo ThreadRepository,
+	messageRepo MessageRepository,
+	questionRepo RFQQuestionRepository,
+	rfqClient RFQClient,
+	eventBus events.EventBus,
+) *RFQClarificationService {
+	return &RFQClarificationService{
+		threadRepo:   threadRepo,
+		messageRepo:  messageRepo,
+		questionRepo: questionRepo,
+		rfqClient:    rfqClient,
+		eventBus:     eventBus,
+	}
+}
+
+// AskQuestion posts a supplier question to its private thread with the buyer.
+// The supplier must be invited to the RFQ; procurement-service enforces this.
+func (s *RFQClarificationService) AskQuestion(rfqID, supplierID, askedBy uuid.UUID, text, authToken string) (*models.RFQQuestion, error) {
+	rfq, err := s.rfqClient.GetSupplierRFQ(authToken, rfqID)
+	if err != nil {
+		return nil, err
+	}
+
+	threadID, err := s.supplierThread(rfq, supplierID, askedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &models.ChatMessage{
+		ThreadID:    threadID,
+		SenderID:    askedBy,
+		Message:     text,
+		MessageType: "question",
+	}
+	if err := s.messageRepo.Create(message); err != nil {
+		return nil, err
+	}
+
+	question := &models.RFQQuestion{
+		TenantID:   rfq.TenantID,
+		RFQID:      rfqID,
+		ThreadID:   threadID,
+		SupplierID: supplierID,
+		AskedBy:    askedBy,
+		Question:   text,
+		Status:     "open",
+	}
+	if err := s.questionRepo.Create(question); err != nil {
+		return nil, err
+	}
+
+	event := events.NewEventEnvelope(
+		events.EventRFQQuestionAsked,
+		"collaboration-service",
+		map[string]interface{}{
+			"question_id": question.ID.String(),
+			"rfq_id":      rfqID.String(),
+			"rfq_number":  rfq.RFQNumber,
+			"thread_id":   threadID.String(),
+			"supplier_id": supplierID.String(),
+		},
+	).WithTenantID(rfq.TenantID)
+
+	if err := s.eventBus.Publish(nil, event); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// AnswerQuestion replies privately to the asking supplier and, when requested,
+// broadcasts the question and answer anonymously to every invited supplier
+func (s *RFQClarificationService) AnswerQuestion(questionID, tenantID, answeredBy uuid.UUID, req AnswerRequest, authToken string) (*models.RFQQuestion, error) {
+	question, err := s.questionRepo.GetByID(questionID)
+	if err != nil || question == nil {
+		return nil, ErrQuestionNotFound
+	}
+	if question.TenantID != tenantID {
+		return nil, ErrNotRFQOwner
+	}
+	if question.Status == "answered" {
+		return nil, ErrQuestionAnswered
+	}
+	if req.NewDueDate != nil && !req.Broadcast {
+		return nil, ErrAmendmentNeedsBroadcast
+	}
+
+	// The amendment is the step most likely to be refused, so it goes first:
+	// if it fails nothing has been posted and the question stays open
+	var rfq *RFQInfo
+	if req.Broadcast {
+		rfq, err = s.rfqClient.GetRFQ(authToken, question.RFQID)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.NewDueDate != nil {
+			amendment, err := s.rfqClient.AmendRFQ(authToken, question.RFQID, AmendRFQRequest{
+				Description: fmt.Sprintf("Clarification: %s", question.Question),
+				NewDueDate:  req.NewDueDate,
+				ReferenceID: &question.ID,
+			})
+			if err != nil {
+				return nil, err
+			}
+			question.AmendmentID = &amendment.ID
+		}
+	}
+
+	reply := &models.ChatMessage{
+		ThreadID:    question.ThreadID,
+		SenderID:    answeredBy,
+		Message:     req.Answer,
+		MessageType: "answer",
+	}
+	if err := s.messageRepo.Create(reply); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	question.Answer = req.Answer
+	question.Status = "answered"
+	question.AnsweredBy = &answeredBy
+	question.AnsweredAt = &now
+	question.IsBroadcast = req.Broadcast
+
+	recipients := []uuid.UUID{question.SupplierID}
+	if req.Broadcast {
+		if err := s.broadcast(rfq, question, answeredBy); err != nil {
+			return nil, err
+		}
+		recipients = invitedSuppliers(rfq)
+	}
+
+	if err := s.questionRepo.Update(question); err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"question_id":  question.ID.String(),
+		"rfq_id":       question.RFQID.String(),
+		"broadcast":    question.IsBroadcast,
+		"supplier_ids": uuidStrings(recipients),
+	}
+	if question.AmendmentID != nil {
+		payload["amendment_id"] = question.AmendmentID.String()
+	}
+	event := events.NewEventEnvelope(events.EventRFQQuestionAnswered, "collaboration-service", payload).
+		WithTenantID(question.TenantID)
+
+	if err := s.eventBus.Publish(nil, event); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// ListQuestions returns the questions visible to the caller: the buyer sees all
+// questions on the RFQ, a supplier sees only the ones it asked
+func (s *RFQClarificationService) ListQuestions(rfqID, tenantID uuid.UUID) ([]models.RFQQuestion, error) {
+	questions, err := s.questionRepo.ListByRFQ(rfqID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]models.RFQQuestion, 0, len(questions))
+	for _, q := range questions {
+		if q.TenantID == tenantID || q.SupplierID == tenantID {
+			visible = append(visible, q)
+		}
+	}
+	return visible, nil
+}
+
+// ListClarifications returns the broadcast Q&A for an RFQ. Callers other than the
+// buyer must be on the RFQ's distribution list.
+func (s *RFQClarificationService) ListClarifications(rfqID, tenantID uuid.UUID, authToken string) ([]RFQClarification, error) {
+	questions, err := s.questionRepo.ListBroadcast(rfqID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(questions) > 0 && questions[0].TenantID != tenantID {
+		if _, err := s.rfqClient.GetSupplierRFQ(authToken, rfqID); err != nil {
+			return nil, err
+		}
+	}
+
+	clarifications := make([]RFQClarification, 0, len(questions))
+	for _, q := range questions {
+		clarifications = append(clarifications, RFQClarification{
+			ID:          q.ID,
+			RFQID:       q.RFQID,
+			Question:    q.Question,
+			Answer:      q.Answer,
+			AnsweredAt:  q.AnsweredAt,
+			AmendmentID: q.AmendmentID,
+		})
+	}
+	return clarifications, nil
+}
+
+// supplierThread returns the private clarification thread between the buyer and
+// a supplier, creating it on the first question
+func (s *RFQClarificationService) supplierThread(rfq *RFQInfo, supplierID, userID uuid.UUID) (uuid.UUID, error) {
+	questions, err := s.questionRepo.ListByRFQ(rfq.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	for _, q := range questions {
+		if q.SupplierID == supplierID {
+			return q.ThreadID, nil
+		}
+	}
+
+	referenceID := rfq.ID
+	thread := &models.ChatThread{
+		TenantID:    rfq.TenantID,
+		Title:       fmt.Sprintf("Clarifications for %s", rfq.RFQNumber),
+		ThreadType:  ThreadTypeRFQQuestion,
+		ReferenceID: &referenceID,
+		CreatedBy:   userID,
+	}
+	if err := s.threadRepo.Create(thread); err != nil {
+		return uuid.Nil, err
+	}
+
+	participant := &models.ThreadParticipant{
+		ThreadID: thread.ID,
+		UserID:   userID,
+		TenantID: supplierID,
+		Role:     "supplier",
+		JoinedAt: time.Now(),
+	}
+	if err := s.threadRepo.AddParticipant(participant); err != nil {
+		return uuid.Nil, err
+	}
+	return thread.ID, nil
+}
+
+// broadcast posts an anonymised copy of the Q&A to the RFQ's shared thread
+func (s *RFQClarificationService) broadcast(rfq *RFQInfo, question *models.RFQQuestion, answeredBy uuid.UUID) error {
+	thread, err := s.threadRepo.GetByReference(ThreadTypeRFQBroadcast, rfq.ID)
+	if err != nil {
+		return err
+	}
+
+	if thread == nil {
+		referenceID := rfq.ID
+		thread = &models.ChatThread{
+			TenantID:    rfq.TenantID,
+			Title:       fmt.Sprintf("Clarifications for %s", rfq.RFQNumber),
+			ThreadType:  ThreadTypeRFQBroadcast,
+			ReferenceID: &referenceID,
+			CreatedBy:   answeredBy,
+		}
+		if err := s.threadRepo.Create(thread); err != nil {
+			return err
+		}
+	}
+
+	message := &models.ChatMessage{
+		ThreadID:    thread.ID,
+		SenderID:    answeredBy,
+		Message:     fmt.Sprintf("Q: %s\nA: %s", question.Question, question.Answer),
+		MessageType: "broadcast",
+	}
+	return s.messageRepo.Create(message)
+}
+
+// invitedSuppliers lists the suppliers that should receive broadcast answers
+func invitedSuppliers(rfq *RFQInfo) []uuid.UUID {
+	supplierIDs := make([]uuid.UUID, 0, len(rfq.Invitations))
+	for _, inv := range rfq.Invitations {
+		if inv.Status != "declined" {
+			supplierIDs = append(supplierIDs, inv.SupplierID)
+		}
+	}
+	return supplierIDs
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = id.String()
+	}
+	return result
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/b2b-platform/collaboration-service/models"
+	"github.com/b2b-platform/shared/events"
+	"github.com/google/uuid"
+)
+
+// MockRFQQuestionRepository for testing
+type MockRFQQuestionRepository struct {
+	questions map[uuid.UUID]*models.RFQQuestion
+}
+
+func (m *MockRFQQuestionRepository) Create(question *models.RFQQuestion) error {
+	if question.ID == uuid.Nil {
+		question.ID = uuid.New()
+	}
+	if m.questions == nil {
+		m.questions = make(map[uuid.UUID]*models.RFQQuestion)
+	}
+	m.questions[question.ID] = question
+	return nil
+}
+
+func (m *MockRFQQuestionRepository) GetByID(id uuid.UUID) (*models.RFQQuestion, error) {
+	if question, ok := m.questions[id]; ok {
+		return question, nil
+	}
+	return nil, nil
+}
+
+func (m *MockRFQQuestionRepository) ListByRFQ(rfqID uuid.UUID) ([]models.RFQQuestion, error) {
+	var result []models.RFQQuestion
+	for _, q := range m.questions {
+		if q.RFQID == rfqID {
+			result = append(result, *q)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockRFQQuestionRepository) ListBroadcast(rfqID uuid.UUID) ([]models.RFQQuestion, error) {
+	var result []models.RFQQuestion
+	for _, q := range m.questions {
+		if q.RFQID == rfqID && q.IsBroadcast {
+			result = append(result, *q)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockRFQQuestionRepository) Update(question *models.RFQQuestion) error {
+	m.questions[question.ID] = question
+	return nil
+}
+
+// MockRFQClient for testing
+type MockRFQClient struct {
+	rfq        *RFQInfo
+	invited    map[uuid.UUID]bool
+	amendments []AmendRFQRequest
+}
+
+func (m *MockRFQClient) GetSupplierRFQ(authToken string, rfqID uuid.UUID) (*RFQInfo, error) {
+	if !m.invited[uuid.MustParse(authToken)] {
+		return nil, errors.New("not found")
+	}
+	return m.rfq, nil
+}
+
+func (m *MockRFQClient) GetRFQ(authToken string, rfqID uuid.UUID) (*RFQInfo, error) {
+	return m.rfq, nil
+}
+
+func (m *MockRFQClient) AmendRFQ(authToken string, rfqID uuid.UUID, req AmendRFQRequest) (*RFQAmendment, error) {
+	m.amendments = append(m.amendments, req)
+	return &RFQAmendment{ID: uuid.New(), AmendmentNumber: len(m.amendments), NewDueDate: req.NewDueDate}, nil
+}
+
+func newClarificationFixture() (*RFQClarificationService, *MockThreadRepository, *MockMessageRepository, *MockRFQClient, *MockEventBus) {
+	buyerTenant := uuid.New()
+	supplierA := uuid.New()
+	supplierB := uuid.New()
+	supplierC := uuid.New()
+
+	rfqClient := &MockRFQClient{
+		rfq: &RFQInfo{
+			ID:        uuid.New(),
+			TenantID:  buyerTenant,
+			RFQNumber: "RFQ-1",
+			Status:    "sent",
+			DueDate:   time.Now().Add(72 * time.Hour),
+			Invitations: []RFQInvitationInfo{
+				{SupplierID: supplierA, Status: "viewed"},
+				{SupplierID: supplierB, Status: "invited"},
+				{SupplierID: supplierC, Status: "declined"},
+			},
+		},
+		invited: map[uuid.UUID]bool{supplierA: true, supplierB: true},
+	}
+
+	threadRepo := &MockThreadRepository{}
+	messageRepo := &MockMessageRepository{}
+	eventBus := &MockEventBus{}
+	service := NewRFQClarificationService(threadRepo, messageRepo, &MockRFQQuestionRepository{}, rfqClient, eventBus)
+	return service, threadRepo, messageRepo, rfqClient, eventBus
+}
+
+func TestRFQClarificationService_AskQuestion_CreatesPrivateThread(t *testing.T) {
+	service, threadRepo, _, rfqClient, eventBus := newClarificationFixture()
+	supplier := rfqClient.rfq.Invitations[0].SupplierID
+
+	question, err := service.AskQuestion(rfqClient.rfq.ID, supplier, uuid.New(), "Is PTFE acceptable?", supplier.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	thread := threadRepo.threads[question.ThreadID]
+	if thread == nil || thread.ThreadType != ThreadTypeRFQQuestion || *thread.ReferenceID != rfqClient.rfq.ID {
+		t.Errorf("expected a private rfq_question thread referencing the RFQ, got %+v", thread)
+	}
+	if question.TenantID != rfqClient.rfq.TenantID {
+		t.Errorf("expected question to belong to the buyer tenant")
+	}
+
+	// A second question from the same supplier reuses the thread
+	second, err := service.AskQuestion(rfqClient.rfq.ID, supplier, uuid.New(), "And FKM?", supplier.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.ThreadID != question.ThreadID {
+		t.Errorf("expected questions from one supplier to share a thread")
+	}
+
+	if len(eventBus.publishedEvents) != 2 || eventBus.publishedEvents[0].Type != events.EventRFQQuestionAsked {
+		t.Errorf("expected %s events to be published", events.EventRFQQuestionAsked)
+	}
+}
+
+func TestRFQClarificationService_AskQuestion_RequiresInvitation(t *testing.T) {
+	service, _, _, rfqClient, _ := newClarificationFixture()
+	outsider := uuid.New()
+
+	if _, err := service.AskQuestion(rfqClient.rfq.ID, outsider, uuid.New(), "Can we bid?", outsider.String()); err == nil {
+		t.Errorf("expected an error for a supplier that is not invited")
+	}
+}
+
+func TestRFQClarificationService_AnswerQuestion_BroadcastsAnonymously(t *testing.T) {
+	service, threadRepo, messageRepo, rfqClient, eventBus := newClarificationFixture()
+	supplier := rfqClient.rfq.Invitations[0].SupplierID
+
+	question, _ := service.AskQuestion(rfqClient.rfq.ID, supplier, uuid.New(), "Is PTFE acceptable?", supplier.String())
+
+	newDue := rfqClient.rfq.DueDate.Add(48 * time.Hour)
+	answered, err := service.AnswerQuestion(question.ID, rfqClient.rfq.TenantID, uuid.New(), AnswerRequest{
+		Answer:     "Yes, PTFE is acceptable",
+		Broadcast:  true,
+		NewDueDate: &newDue,
+	}, "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if answered.Status != "answered" || !answered.IsBroadcast || answered.AmendmentID == nil {
+		t.Errorf("expected answered broadcast question with an amendment, got %+v", answered)
+	}
+	if len(rfqClient.amendments) != 1 || !rfqClient.amendments[0].NewDueDate.Equal(newDue) {
+		t.Errorf("expected one amendment extending the due date")
+	}
+
+	broadcastThread, _ := threadRepo.GetByReference(ThreadTypeRFQBroadcast, rfqClient.rfq.ID)
+	if broadcastThread == nil {
+		t.Fatalf("expected a broadcast thread to be created")
+	}
+	for _, msg := range messageRepo.messages {
+		if msg.ThreadID == broadcastThread.ID && msg.SenderID == question.AskedBy {
+			t.Errorf("broadcast message must not reveal the asking supplier")
+		}
+	}
+
+	event := eventBus.publishedEvents[len(eventBus.publishedEvents)-1]
+	if event.Type != events.EventRFQQuestionAnswered {
+		t.Fatalf("expected %s, got %s", events.EventRFQQuestionAnswered, event.Type)
+	}
+	recipients := event.Payload["supplier_ids"].([]string)
+	if len(recipients) != 2 {
+		t.Errorf("expected answer to go to the 2 suppliers that have not declined, got %v", recipients)
+	}
+}
+
+func TestRFQClarificationService_AnswerQuestion_Validation(t *testing.T) {
+	service, _, _, rfqClient, _ := newClarificationFixture()
+	supplier := rfqClient.rfq.Invitations[0].SupplierID
+
+	question, _ := service.AskQuestion(rfqClient.rfq.ID, supplier, uuid.New(), "Is PTFE acceptable?", supplier.String())
+
+	if _, err := service.AnswerQuestion(question.ID, supplier, uuid.New(), AnswerRequest{Answer: "Yes"}, "token"); !errors.Is(err, ErrNotRFQOwner) {
+		t.Errorf("expected ErrNotRFQOwner, got %v", err)
+	}
+
+	newDue := time.Now().Add(96 * time.Hour)
+	req := AnswerRequest{Answer: "Yes", NewDueDate: &newDue}
+	if _, err := service.AnswerQuestion(question.ID, rfqClient.rfq.TenantID, uuid.New(), req, "token"); !errors.Is(err, ErrAmendmentNeedsBroadcast) {
+		t.Errorf("expected ErrAmendmentNeedsBroadcast, got %v", err)
+	}
+}
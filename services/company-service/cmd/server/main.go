@@ -79,11 +79,15 @@ func main() {
 	api.Use(auth.TenantMiddleware())
 	{
 		api.GET("/companies", companyHandler.List)
+		api.GET("/companies/search-snapshot", companyHandler.SearchSnapshot)
 		api.GET("/companies/:id", companyHandler.Get)
 		api.POST("/companies", companyHandler.Create)
 		api.PUT("/companies/:id", companyHandler.Update)
 		api.POST("/companies/:id/approve", companyHandler.Approve)
 		api.POST("/companies/:id/subdomain-request", companyHandler.RequestSubdomain)
+		api.GET("/companies/:id/tax-exemptions", companyHandler.ListTaxExemptions)
+		api.POST("/companies/:id/tax-exemptions", companyHandler.AddTaxExemption)
+		api.PUT("/companies/:id/tax-exemptions/:certId/review", companyHandler.ReviewTaxExemption)
 	}
 
 	port := os.Getenv("PORT")
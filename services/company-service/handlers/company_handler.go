@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/b2b-platform/company-service/models"
 	"github.com/b2b-platform/company-service/service"
 	"github.com/b2b-platform/shared/auth"
+	"github.com/b2b-platform/shared/geo"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type CompanyHandler struct {
@@ -20,19 +24,21 @@ func NewCompanyHandler(service *service.CompanyService) *CompanyHandler {
 
 func (h *CompanyHandler) Create(c *gin.Context) {
 	var req struct {
-		Name        string `json:"name" binding:"required"`
-		LegalName   string `json:"legal_name"`
-		TaxID       string `json:"tax_id"`
-		Address     string `json:"address"`
-		City        string `json:"city"`
-		State       string `json:"state"`
-		Country     string `json:"country"`
-		PostalCode  string `json:"postal_code"`
-		Phone       string `json:"phone"`
-		Email       string `json:"email"`
-		Website     string `json:"website"`
-		Industry    string `json:"industry"`
-		CompanyType string `json:"company_type"`
+		Name        string   `json:"name" binding:"required"`
+		LegalName   string   `json:"legal_name"`
+		TaxID       string   `json:"tax_id"`
+		Address     string   `json:"address"`
+		City        string   `json:"city"`
+		State       string   `json:"state"`
+		Country     string   `json:"country"`
+		PostalCode  string   `json:"postal_code"`
+		Phone       string   `json:"phone"`
+		Email       string   `json:"email"`
+		Website     string   `json:"website"`
+		Industry    string   `json:"industry"`
+		CompanyType string   `json:"company_type"`
+		Latitude    *float64 `json:"latitude"` // Geocoded from the postal code when not given
+		Longitude   *float64 `json:"longitude"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -49,6 +55,7 @@ func (h *CompanyHandler) Create(c *gin.Context) {
 		State:       req.State,
 		Country:     req.Country,
 		PostalCode:  req.PostalCode,
+		Site:        geo.Site{Latitude: req.Latitude, Longitude: req.Longitude},
 		Phone:       req.Phone,
 		Email:       req.Email,
 		Website:     req.Website,
@@ -58,6 +65,10 @@ func (h *CompanyHandler) Create(c *gin.Context) {
 	}
 
 	if err := h.service.Create(company); err != nil {
+		if errors.Is(err, geo.ErrInvalidLocation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -104,12 +115,19 @@ func (h *CompanyHandler) Update(c *gin.Context) {
 		return
 	}
 
+	located, _ := company.Point()
 	if err := c.ShouldBindJSON(&company); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// Coordinates given in the request replace geocoded ones
+	company.Moved(located)
 
 	if err := h.service.Update(company); err != nil {
+		if errors.Is(err, geo.ErrInvalidLocation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -117,6 +135,49 @@ func (h *CompanyHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, company)
 }
 
+// SearchSnapshot exports companies for a search reindex. Admin only.
+// Query params: after (cursor), tenant_id, limit (default 500, max 1000).
+func (h *CompanyHandler) SearchSnapshot(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	after := uuid.Nil
+	if raw := c.Query("after"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid after"})
+			return
+		}
+		after = parsed
+	}
+
+	var tenantID *uuid.UUID
+	if raw := c.Query("tenant_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tenant_id"})
+			return
+		}
+		tenantID = &parsed
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultSnapshotPageSize)))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return
+	}
+
+	page, err := h.service.SearchSnapshot(after, tenantID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
 func (h *CompanyHandler) Approve(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -159,3 +220,92 @@ func (h *CompanyHandler) RequestSubdomain(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, gin.H{"message": "subdomain request created"})
 }
+
+func (h *CompanyHandler) AddTaxExemption(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var cert models.TaxExemptCertificate
+	if err := c.ShouldBindJSON(&cert); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if cert.CertificateNumber == "" || cert.Country == "" || cert.ValidFrom.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "certificate_number, country and valid_from are required"})
+		return
+	}
+
+	if err := h.service.AddTaxExemption(id, &cert); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, cert)
+}
+
+func (h *CompanyHandler) ListTaxExemptions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	certs, err := h.service.ListTaxExemptions(id, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, certs)
+}
+
+// ReviewTaxExemption verifies or revokes a tax exemption certificate. Verified
+// certificates zero-rate purchases, so only platform admins outside the
+// company may review them.
+func (h *CompanyHandler) ReviewTaxExemption(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	certID, err := uuid.Parse(c.Param("certId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		Status string `json:"status" binding:"required"` // verified, revoked
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+	tenantID, _ := auth.GetTenantID(c)
+
+	cert, err := h.service.ReviewTaxExemption(id, certID, userID, tenantID, req.Status)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		if errors.Is(err, service.ErrOwnExemptionReview) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cert)
+}
@@ -0,0 +1,19 @@
+-- Tax-exempt certificates held by buyer companies
+CREATE TABLE IF NOT EXISTS company.tax_exempt_certificates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id UUID NOT NULL REFERENCES company.companies(id) ON DELETE CASCADE,
+    certificate_number VARCHAR(100) NOT NULL,
+    country VARCHAR(100) NOT NULL,
+    categories TEXT,
+    document_id UUID,
+    valid_from TIMESTAMP NOT NULL,
+    valid_to TIMESTAMP,
+    status VARCHAR(50) DEFAULT 'pending',
+    verified_by UUID,
+    verified_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_exempt_certificates_company_id ON company.tax_exempt_certificates(company_id);
+CREATE INDEX IF NOT EXISTS idx_tax_exempt_certificates_status ON company.tax_exempt_certificates(status);
@@ -0,0 +1,9 @@
+-- Company site coordinates, for searching suppliers near a site
+ALTER TABLE company.companies
+ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+
+ALTER TABLE company.companies
+ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+
+ALTER TABLE company.companies
+ADD COLUMN IF NOT EXISTS location_geocoded BOOLEAN DEFAULT FALSE;
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/b2b-platform/shared/geo"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -20,6 +21,7 @@ type Company struct {
 	State           string         `gorm:"type:varchar(100)" json:"state"`
 	Country         string         `gorm:"type:varchar(100)" json:"country"`
 	PostalCode      string         `gorm:"type:varchar(20)" json:"postal_code"`
+	geo.Site                       `gorm:"embedded"` // Coordinates of the company's site
 	Phone           string         `gorm:"type:varchar(50)" json:"phone"`
 	Email           string         `gorm:"type:varchar(255)" json:"email"`
 	Website         string         `gorm:"type:varchar(255)" json:"website"`
@@ -33,6 +35,7 @@ type Company struct {
 
 	// Relationships
 	Documents []CompanyDocument `gorm:"foreignKey:CompanyID" json:"documents,omitempty"`
+	TaxExemptions []TaxExemptCertificate `gorm:"foreignKey:CompanyID" json:"tax_exemptions,omitempty"`
 }
 
 func (Company) TableName() string {
@@ -81,3 +84,25 @@ type SubdomainRequest struct {
 func (SubdomainRequest) TableName() string {
 	return "company.subdomain_requests"
 }
+
+// TaxExemptCertificate exempts a company's purchases from tax in a country,
+// optionally limited to some product categories
+type TaxExemptCertificate struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CompanyID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"company_id"`
+	CertificateNumber string     `gorm:"type:varchar(100);not null" json:"certificate_number"`
+	Country           string     `gorm:"type:varchar(100);not null" json:"country"`
+	Categories        string     `gorm:"type:text" json:"categories"` // comma-separated; empty covers every category
+	DocumentID        *uuid.UUID `gorm:"type:uuid" json:"document_id,omitempty"`
+	ValidFrom         time.Time  `gorm:"not null" json:"valid_from"`
+	ValidTo           *time.Time `json:"valid_to,omitempty"`
+	Status            string     `gorm:"type:varchar(50);default:'pending';index" json:"status"` // pending, verified, revoked
+	VerifiedBy        *uuid.UUID `json:"verified_by,omitempty"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+func (TaxExemptCertificate) TableName() string {
+	return "company.tax_exempt_certificates"
+}
@@ -20,7 +20,7 @@ func (r *CompanyRepository) Create(company *models.Company) error {
 
 func (r *CompanyRepository) GetByID(id uuid.UUID) (*models.Company, error) {
 	var company models.Company
-	err := r.db.Preload("Documents").Where("id = ?", id).First(&company).Error
+	err := r.db.Preload("Documents").Preload("TaxExemptions").Where("id = ?", id).First(&company).Error
 	return &company, err
 }
 
@@ -34,6 +34,13 @@ func (r *CompanyRepository) List(limit, offset int) ([]models.Company, error) {
 	return companies, err
 }
 
+// ListAfter returns up to limit companies with IDs after a cursor, in ID order
+func (r *CompanyRepository) ListAfter(after uuid.UUID, limit int) ([]models.Company, error) {
+	var companies []models.Company
+	err := r.db.Where("id > ?", after).Order("id ASC").Limit(limit).Find(&companies).Error
+	return companies, err
+}
+
 func (r *CompanyRepository) Update(company *models.Company) error {
 	return r.db.Save(company).Error
 }
@@ -51,3 +58,27 @@ func (r *CompanyRepository) AddDocument(doc *models.CompanyDocument) error {
 func (r *CompanyRepository) CreateSubdomainRequest(req *models.SubdomainRequest) error {
 	return r.db.Create(req).Error
 }
+
+func (r *CompanyRepository) CreateTaxExemption(cert *models.TaxExemptCertificate) error {
+	return r.db.Create(cert).Error
+}
+
+func (r *CompanyRepository) GetTaxExemption(id uuid.UUID) (*models.TaxExemptCertificate, error) {
+	var cert models.TaxExemptCertificate
+	err := r.db.Where("id = ?", id).First(&cert).Error
+	return &cert, err
+}
+
+func (r *CompanyRepository) ListTaxExemptions(companyID uuid.UUID, status string) ([]models.TaxExemptCertificate, error) {
+	var certs []models.TaxExemptCertificate
+	query := r.db.Where("company_id = ?", companyID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("valid_from DESC").Find(&certs).Error
+	return certs, err
+}
+
+func (r *CompanyRepository) UpdateTaxExemption(cert *models.TaxExemptCertificate) error {
+	return r.db.Save(cert).Error
+}
@@ -1,14 +1,21 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/b2b-platform/company-service/models"
 	"github.com/b2b-platform/company-service/repository"
 	"github.com/b2b-platform/shared/events"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// ErrOwnExemptionReview is returned when a reviewer belongs to the company
+// whose exemption certificate is under review
+var ErrOwnExemptionReview = errors.New("a company's own users cannot review its tax exemptions")
+
 type CompanyService struct {
 	repo     *repository.CompanyRepository
 	eventBus events.EventBus
@@ -22,7 +29,13 @@ func NewCompanyService(repo *repository.CompanyRepository, eventBus events.Event
 }
 
 func (s *CompanyService) Create(company *models.Company) error {
-	return s.repo.Create(company)
+	if err := company.Locate(company.Country, company.PostalCode); err != nil {
+		return err
+	}
+	if err := s.repo.Create(company); err != nil {
+		return err
+	}
+	return s.publishCompanyChanged(events.EventCompanyCreated, company)
 }
 
 func (s *CompanyService) GetByID(id uuid.UUID) (*models.Company, error) {
@@ -34,7 +47,13 @@ func (s *CompanyService) List(limit, offset int) ([]models.Company, error) {
 }
 
 func (s *CompanyService) Update(company *models.Company) error {
-	return s.repo.Update(company)
+	if err := company.Locate(company.Country, company.PostalCode); err != nil {
+		return err
+	}
+	if err := s.repo.Update(company); err != nil {
+		return err
+	}
+	return s.publishCompanyChanged(events.EventCompanyUpdated, company)
 }
 
 func (s *CompanyService) Approve(companyID, approvedBy uuid.UUID) error {
@@ -62,11 +81,89 @@ func (s *CompanyService) Approve(companyID, approvedBy uuid.UUID) error {
 			"name":       company.Name,
 			"subdomain":  company.Subdomain,
 		},
-	).WithTenantID(companyID)
+	).WithTenantID(companyID).WithVersion(events.VersionAt(company.UpdatedAt))
 
 	return s.eventBus.Publish(nil, event)
 }
 
+// Snapshot page sizes bound how much one export request reads
+const (
+	DefaultSnapshotPageSize = 500
+	MaxSnapshotPageSize     = 1000
+)
+
+// SearchSnapshot exports companies as the events announcing their current
+// state so search can rebuild its index. A company is its own tenant, so a
+// tenant's snapshot is that one company. Pages follow ID order from the
+// after cursor.
+func (s *CompanyService) SearchSnapshot(after uuid.UUID, tenantID *uuid.UUID, limit int) (*events.SnapshotPage, error) {
+	if limit <= 0 {
+		limit = DefaultSnapshotPageSize
+	}
+	if limit > MaxSnapshotPageSize {
+		limit = MaxSnapshotPageSize
+	}
+
+	page := &events.SnapshotPage{Events: []*events.EventEnvelope{}}
+
+	if tenantID != nil {
+		if after != uuid.Nil {
+			return page, nil
+		}
+		company, err := s.repo.GetByID(*tenantID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return page, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		page.Events = append(page.Events, companyEvent(events.EventCompanyUpdated, company))
+		return page, nil
+	}
+
+	companies, err := s.repo.ListAfter(after, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range companies {
+		page.Events = append(page.Events, companyEvent(events.EventCompanyUpdated, &companies[i]))
+	}
+	if len(companies) == limit {
+		page.NextAfter = companies[len(companies)-1].ID.String()
+	}
+	return page, nil
+}
+
+// publishCompanyChanged announces a created or updated company with the
+// fields the search index keeps
+func (s *CompanyService) publishCompanyChanged(eventType events.EventType, company *models.Company) error {
+	return s.eventBus.Publish(nil, companyEvent(eventType, company))
+}
+
+func companyEvent(eventType events.EventType, company *models.Company) *events.EventEnvelope {
+	event := events.NewEventEnvelope(
+		eventType,
+		"company-service",
+		map[string]interface{}{
+			"company_id":   company.ID.String(),
+			"name":         company.Name,
+			"legal_name":   company.LegalName,
+			"subdomain":    company.Subdomain,
+			"status":       company.Status,
+			"industry":     company.Industry,
+			"company_type": company.CompanyType,
+			"city":         company.City,
+			"country":      company.Country,
+			"postal_code":  company.PostalCode,
+		},
+	).WithTenantID(company.ID).WithVersion(events.VersionAt(company.UpdatedAt))
+	if point, ok := company.Point(); ok {
+		event.Payload["latitude"] = point.Lat
+		event.Payload["longitude"] = point.Lon
+	}
+	return event
+}
+
 func (s *CompanyService) RequestSubdomain(companyID uuid.UUID, subdomain string, requestedBy uuid.UUID) error {
 	req := &models.SubdomainRequest{
 		CompanyID:   companyID,
@@ -76,3 +173,49 @@ func (s *CompanyService) RequestSubdomain(companyID uuid.UUID, subdomain string,
 	}
 	return s.repo.CreateSubdomainRequest(req)
 }
+
+// AddTaxExemption registers a tax-exempt certificate. It has no effect on tax
+// calculation until it is verified.
+func (s *CompanyService) AddTaxExemption(companyID uuid.UUID, cert *models.TaxExemptCertificate) error {
+	if cert.ValidTo != nil && !cert.ValidTo.After(cert.ValidFrom) {
+		return fmt.Errorf("valid_to must be after valid_from")
+	}
+
+	cert.CompanyID = companyID
+	cert.Status = "pending"
+	cert.VerifiedBy = nil
+	cert.VerifiedAt = nil
+	return s.repo.CreateTaxExemption(cert)
+}
+
+func (s *CompanyService) ListTaxExemptions(companyID uuid.UUID, status string) ([]models.TaxExemptCertificate, error) {
+	return s.repo.ListTaxExemptions(companyID, status)
+}
+
+// ReviewTaxExemption verifies or revokes a certificate
+func (s *CompanyService) ReviewTaxExemption(companyID, certID, reviewedBy, reviewerTenantID uuid.UUID, status string) (*models.TaxExemptCertificate, error) {
+	if status != "verified" && status != "revoked" {
+		return nil, fmt.Errorf("status must be verified or revoked")
+	}
+	// A company is the tenant of its own users
+	if reviewerTenantID == companyID {
+		return nil, ErrOwnExemptionReview
+	}
+
+	cert, err := s.repo.GetTaxExemption(certID)
+	if err != nil {
+		return nil, err
+	}
+	if cert.CompanyID != companyID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	now := time.Now()
+	cert.Status = status
+	cert.VerifiedBy = &reviewedBy
+	cert.VerifiedAt = &now
+	if err := s.repo.UpdateTaxExemption(cert); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/b2b-platform/equipment-service/handlers"
 	"github.com/b2b-platform/equipment-service/repository"
@@ -14,6 +16,7 @@ import (
 	"github.com/b2b-platform/shared/middleware"
 	"github.com/b2b-platform/shared/observability"
 	"github.com/b2b-platform/shared/database"
+	"github.com/b2b-platform/shared/events"
 	"github.com/b2b-platform/shared/redis"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -31,19 +34,32 @@ func main() {
 
 	equipmentRepo := repository.NewEquipmentRepository(db)
 	bomRepo := repository.NewBOMRepository(db)
+	bomRevisionRepo := repository.NewBOMRevisionRepository(db)
 	compatibilityRepo := repository.NewCompatibilityRepository(db)
+	maintenanceRepo := repository.NewMaintenanceRepository(db)
 
-	equipmentService := service.NewEquipmentService(equipmentRepo, bomRepo, compatibilityRepo)
+	// Equipment changes are published for search indexing when Redis is available
+	redisClient, err := redis.GetRedisClient()
+	if err != nil {
+		log.Printf("Warning: Failed to connect to Redis: %v", err)
+	}
+	var eventBus events.EventBus
+	if redisClient != nil {
+		eventBus = events.NewRedisEventBus(redisClient)
+	}
+
+	catalogClient := service.NewCatalogClient()
+	marketplaceClient := service.NewMarketplaceClient()
+	procurementClient := service.NewProcurementClient()
+	warehouseClient := service.NewWarehouseClient()
+
+	equipmentService := service.NewEquipmentService(equipmentRepo, bomRepo, bomRevisionRepo, compatibilityRepo, maintenanceRepo, catalogClient, marketplaceClient, procurementClient, warehouseClient, eventBus)
 	equipmentHandler := handlers.NewEquipmentHandler(equipmentService)
 
 	r := gin.Default()
 
 	
 	// Health endpoints
-	redisClient, err := redis.GetRedisClient()
-	if err != nil {
-		log.Printf("Warning: Failed to connect to Redis: %v", err)
-	}
 	if redisClient == nil {
 		redisClient, err = redis.GetRedisClient()
 		if err != nil {
@@ -54,6 +70,36 @@ func main() {
 	r.GET("/health", healthChecker.Health)
 	r.GET("/ready", healthChecker.Ready)
 
+	// Re-point part references when catalog parts are merged
+	if eventBus != nil {
+		eventConsumer := service.NewEventConsumer(equipmentService)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			if err := eventConsumer.StartEventConsumer(ctx, eventBus); err != nil {
+				if err != context.Canceled {
+					log.Printf("Event consumer error: %v", err)
+				}
+			}
+		}()
+	}
+
+	// Draft PRs for upcoming maintenance on plans that draft their own
+	if interval, err := time.ParseDuration(os.Getenv("MAINTENANCE_FORECAST_INTERVAL")); err == nil && interval > 0 {
+		scheduler := service.NewMaintenanceScheduler(equipmentService, auth.NewJWTService(), service.DefaultForecastHorizonDays)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			if err := scheduler.Start(ctx, interval); err != nil && err != context.Canceled {
+				log.Printf("Maintenance scheduler error: %v", err)
+			}
+		}()
+	} else {
+		log.Println("Maintenance scheduler disabled, set MAINTENANCE_FORECAST_INTERVAL (e.g. 6h) to enable")
+	}
+
 	// Configure CORS
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:3000", "http://localhost:3002", "http://127.0.0.1:3000", "http://127.0.0.1:3002"}
@@ -79,13 +125,48 @@ func main() {
 	api.Use(auth.TenantMiddleware())
 	{
 		api.GET("/equipment", equipmentHandler.List)
+		api.GET("/equipment/search-snapshot", equipmentHandler.SearchSnapshot)
 		api.GET("/equipment/:id", equipmentHandler.Get)
 		api.POST("/equipment", equipmentHandler.Create)
 		api.PUT("/equipment/:id", equipmentHandler.Update)
+		api.DELETE("/equipment/:id", equipmentHandler.Delete)
 
 		// BOM endpoints
 		api.GET("/equipment/:id/bom", equipmentHandler.GetBOM)
 		api.POST("/equipment/:id/bom", equipmentHandler.AddBOMNode)
+		api.GET("/equipment/:id/bom/explosion", equipmentHandler.ExplodeBOM)
+		api.GET("/equipment/:id/bom/cost", equipmentHandler.RollUpBOMCost)
+		api.POST("/equipment/:id/bom/purchase-request", equipmentHandler.CreatePRFromBOM)
+		api.POST("/equipment/:id/bom/import", equipmentHandler.ImportBOM)
+		api.GET("/equipment/:id/bom/export", equipmentHandler.ExportBOM)
+		api.GET("/equipment/:id/bom/unlinked", equipmentHandler.ListUnlinkedBOMNodes)
+		api.PUT("/equipment/:id/bom/nodes/:node_id/part", equipmentHandler.LinkBOMNodePart)
+
+		// BOM revisions
+		api.GET("/equipment/:id/bom/revisions", equipmentHandler.ListBOMRevisions)
+		api.POST("/equipment/:id/bom/revisions", equipmentHandler.CreateBOMRevision)
+		api.GET("/equipment/:id/bom/revisions/effective", equipmentHandler.GetEffectiveBOMRevision)
+		api.GET("/equipment/:id/bom/revisions/:revision_id", equipmentHandler.GetBOMRevision)
+		api.DELETE("/equipment/:id/bom/revisions/:revision_id", equipmentHandler.DeleteBOMRevision)
+		api.POST("/equipment/:id/bom/revisions/:revision_id/refresh", equipmentHandler.RefreshBOMRevision)
+		api.POST("/equipment/:id/bom/revisions/:revision_id/release", equipmentHandler.ReleaseBOMRevision)
+		api.GET("/equipment/:id/bom/revisions/:revision_id/diff", equipmentHandler.DiffBOMRevision)
+		api.GET("/parts/:part_id/where-used", equipmentHandler.WhereUsed)
+		api.POST("/parts/replace", equipmentHandler.ReplacePartReferences)
+
+		// Preventive maintenance
+		api.GET("/equipment/:id/maintenance-plans", equipmentHandler.ListMaintenancePlans)
+		api.POST("/equipment/:id/maintenance-plans", equipmentHandler.CreateMaintenancePlan)
+		api.GET("/equipment/:id/meter-readings", equipmentHandler.ListMeterReadings)
+		api.POST("/equipment/:id/meter-readings", equipmentHandler.RecordMeterReading)
+		api.GET("/equipment/:id/maintenance/forecast", equipmentHandler.ForecastMaintenance)
+		api.GET("/maintenance-plans/:plan_id", equipmentHandler.GetMaintenancePlan)
+		api.PUT("/maintenance-plans/:plan_id", equipmentHandler.UpdateMaintenancePlan)
+		api.DELETE("/maintenance-plans/:plan_id", equipmentHandler.DeleteMaintenancePlan)
+		api.GET("/maintenance-plans/:plan_id/records", equipmentHandler.ListMaintenanceRecords)
+		api.POST("/maintenance-plans/:plan_id/complete", equipmentHandler.CompleteMaintenance)
+		api.GET("/maintenance/forecast", equipmentHandler.ForecastMaintenance)
+		api.POST("/maintenance/forecast/run", equipmentHandler.RunMaintenanceForecast)
 
 		// Compatibility endpoints
 		api.GET("/equipment/:id/compatibility", equipmentHandler.GetCompatibilityMappings)
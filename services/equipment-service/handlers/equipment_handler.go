@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/b2b-platform/equipment-service/models"
 	"github.com/b2b-platform/equipment-service/service"
 	"github.com/b2b-platform/shared/auth"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type EquipmentHandler struct {
@@ -94,6 +100,77 @@ func (h *EquipmentHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, equipment)
 }
 
+func (h *EquipmentHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "equipment deleted"})
+}
+
+// SearchSnapshot exports equipment for a search reindex. Admin only.
+// Query params: after (cursor), tenant_id, limit (default 500, max 1000).
+func (h *EquipmentHandler) SearchSnapshot(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	after, tenantID, limit, ok := snapshotParams(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.SearchSnapshot(after, tenantID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func snapshotParams(c *gin.Context) (uuid.UUID, *uuid.UUID, int, bool) {
+	after := uuid.Nil
+	if raw := c.Query("after"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid after"})
+			return uuid.Nil, nil, 0, false
+		}
+		after = parsed
+	}
+
+	var tenantID *uuid.UUID
+	if raw := c.Query("tenant_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tenant_id"})
+			return uuid.Nil, nil, 0, false
+		}
+		tenantID = &parsed
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultSnapshotPageSize)))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return uuid.Nil, nil, 0, false
+	}
+
+	return after, tenantID, limit, true
+}
+
 func (h *EquipmentHandler) AddBOMNode(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -112,7 +189,7 @@ func (h *EquipmentHandler) AddBOMNode(c *gin.Context) {
 	node.EquipmentID = id
 
 	if err := h.service.AddBOMNode(&node); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeBOMError(c, err)
 		return
 	}
 
@@ -132,6 +209,8 @@ func (h *EquipmentHandler) GetBOM(c *gin.Context) {
 		return
 	}
 
+	h.service.AttachInterchange(nodes, requestToken(c))
+
 	c.JSON(http.StatusOK, nodes)
 }
 
@@ -190,18 +269,13 @@ func (h *EquipmentHandler) CheckCompatibility(c *gin.Context) {
 		return
 	}
 
-	mapping, err := h.service.CheckCompatibility(equipmentID, partID)
+	check, err := h.service.CheckPartCompatibility(equipmentID, partID, requestToken(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	if mapping == nil {
-		c.JSON(http.StatusOK, gin.H{"is_compatible": false, "message": "no mapping found"})
-		return
-	}
-
-	c.JSON(http.StatusOK, mapping)
+	c.JSON(http.StatusOK, check)
 }
 
 func (h *EquipmentHandler) GetCompatibilityMappings(c *gin.Context) {
@@ -219,3 +293,465 @@ func (h *EquipmentHandler) GetCompatibilityMappings(c *gin.Context) {
 
 	c.JSON(http.StatusOK, mappings)
 }
+
+// ExplodeBOM returns the BOM as a tree with accumulated quantities.
+// Query params: quantity, the number of equipment units (default 1).
+func (h *EquipmentHandler) ExplodeBOM(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	quantity, err := strconv.ParseFloat(c.DefaultQuery("quantity", "1"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quantity"})
+		return
+	}
+
+	explosion, err := h.service.ExplodeBOM(id, quantity)
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, explosion)
+}
+
+// RollUpBOMCost prices the BOM from current marketplace listings.
+// Query params: quantity (default 1), currency (default USD).
+func (h *EquipmentHandler) RollUpBOMCost(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	quantity, err := strconv.ParseFloat(c.DefaultQuery("quantity", "1"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quantity"})
+		return
+	}
+
+	rollup, err := h.service.RollUpCost(id, quantity, c.Query("currency"), requestToken(c))
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rollup)
+}
+
+// CreatePRFromBOM creates a draft purchase request from selected BOM nodes
+func (h *EquipmentHandler) CreatePRFromBOM(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req service.BOMPurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pr, err := h.service.CreatePRFromBOM(id, &req, requestToken(c))
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, pr)
+}
+
+// WhereUsed lists the tenant's equipment BOM positions that use a catalog part
+func (h *EquipmentHandler) WhereUsed(c *gin.Context) {
+	partID, err := uuid.Parse(c.Param("part_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid part_id"})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+
+	uses, err := h.service.WhereUsed(tenantID, partID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, uses)
+}
+
+// ReplacePartReferences re-points BOM nodes and compatibility mappings from a catalog part that was merged away
+// onto the part it was merged into. Called by catalog-service when it merges
+// parts; repeating it is harmless. Super admin only.
+// Body: part_id and merged_into.
+func (h *EquipmentHandler) ReplacePartReferences(c *gin.Context) {
+	if !auth.HasRole(c, "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "super admin access required"})
+		return
+	}
+
+	var req struct {
+		PartID     uuid.UUID `json:"part_id" binding:"required"`
+		MergedInto uuid.UUID `json:"merged_into" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.service.ReplacePartReferences(req.PartID, req.MergedInto)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// ImportBOM reads an indented BOM spreadsheet into the equipment's BOM.
+// Form fields: file (required), format (csv|xlsx, default from the file
+// extension), replace (bool, remove the existing BOM first) and dry_run (bool).
+func (h *EquipmentHandler) ImportBOM(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	replace, _ := strconv.ParseBool(c.DefaultPostForm("replace", "false"))
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportBOM(id, fileHeader.Filename, c.PostForm("format"), file, replace, dryRun, requestToken(c))
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	switch {
+	case result.Errors > 0:
+		c.JSON(http.StatusUnprocessableEntity, result)
+	case result.DryRun:
+		c.JSON(http.StatusOK, result)
+	default:
+		c.JSON(http.StatusCreated, result)
+	}
+}
+
+// ExportBOM downloads the BOM as an indented file. Query params: format (csv|xlsx, default csv).
+func (h *EquipmentHandler) ExportBOM(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	equipment, err := h.service.GetByID(id)
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	format := c.DefaultQuery("format", service.FormatCSV)
+	contentType := "text/csv"
+	switch format {
+	case service.FormatCSV:
+	case service.FormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or xlsx"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.service.ExportBOM(equipment.ID, format, &buf); err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("bom-%s.%s", equipment.EquipmentNumber, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// ListUnlinkedBOMNodes returns BOM lines not yet linked to a catalog part
+func (h *EquipmentHandler) ListUnlinkedBOMNodes(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	nodes, err := h.service.ListUnlinkedBOMNodes(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, nodes)
+}
+
+// LinkBOMNodePart links a BOM line to a catalog part
+func (h *EquipmentHandler) LinkBOMNodePart(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	nodeID, err := uuid.Parse(c.Param("node_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid node_id"})
+		return
+	}
+
+	var req struct {
+		PartID uuid.UUID `json:"part_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	node, err := h.service.LinkBOMNodePart(id, nodeID, req.PartID, requestToken(c))
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, node)
+}
+
+// ListBOMRevisions returns the equipment's BOM revisions, newest first
+func (h *EquipmentHandler) ListBOMRevisions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	revisions, err := h.service.ListBOMRevisions(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// CreateBOMRevision takes a draft revision from the working BOM
+func (h *EquipmentHandler) CreateBOMRevision(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		Description string `json:"description"`
+	}
+	// The body is optional
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	revision, err := h.service.CreateBOMRevision(id, req.Description, userID)
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, revision)
+}
+
+// GetEffectiveBOMRevision returns the released revision in effect.
+// Query params: date (YYYY-MM-DD, default today).
+func (h *EquipmentHandler) GetEffectiveBOMRevision(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	at := time.Now()
+	if date := c.Query("date"); date != "" {
+		if at, err = time.Parse("2006-01-02", date); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+			return
+		}
+	}
+
+	revision, err := h.service.GetEffectiveBOMRevision(id, at)
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+func (h *EquipmentHandler) GetBOMRevision(c *gin.Context) {
+	id, revisionID, ok := parseRevisionParams(c)
+	if !ok {
+		return
+	}
+
+	revision, err := h.service.GetBOMRevision(id, revisionID)
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// RefreshBOMRevision copies the working BOM into a draft revision again
+func (h *EquipmentHandler) RefreshBOMRevision(c *gin.Context) {
+	id, revisionID, ok := parseRevisionParams(c)
+	if !ok {
+		return
+	}
+
+	revision, err := h.service.RefreshBOMRevision(id, revisionID)
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// ReleaseBOMRevision freezes a draft revision, effective from effective_date (default today)
+func (h *EquipmentHandler) ReleaseBOMRevision(c *gin.Context) {
+	id, revisionID, ok := parseRevisionParams(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		EffectiveDate *time.Time `json:"effective_date"`
+	}
+	// The body is optional
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	revision, err := h.service.ReleaseBOMRevision(id, revisionID, req.EffectiveDate, userID)
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DeleteBOMRevision discards a draft revision
+func (h *EquipmentHandler) DeleteBOMRevision(c *gin.Context) {
+	id, revisionID, ok := parseRevisionParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteBOMRevision(id, revisionID); err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "revision deleted"})
+}
+
+// DiffBOMRevision compares a revision with another.
+// Query params: to, the revision to compare with (default the working BOM).
+func (h *EquipmentHandler) DiffBOMRevision(c *gin.Context) {
+	id, revisionID, ok := parseRevisionParams(c)
+	if !ok {
+		return
+	}
+
+	var toID *uuid.UUID
+	if raw := c.Query("to"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return
+		}
+		toID = &parsed
+	}
+
+	diff, err := h.service.DiffBOMRevisions(id, revisionID, toID)
+	if err != nil {
+		writeBOMError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+func parseRevisionParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	revisionID, err := uuid.Parse(c.Param("revision_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision_id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return id, revisionID, true
+}
+
+func writeBOMError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrUnknownRevision):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, service.ErrNoEffectiveRevision):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrRevisionReleased), errors.Is(err, service.ErrRevisionNotReleased):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrBOMCycle),
+		errors.Is(err, service.ErrInvalidParentNode),
+		errors.Is(err, service.ErrInvalidQuantity),
+		errors.Is(err, service.ErrNoNodesSelected),
+		errors.Is(err, service.ErrUnknownBOMNode),
+		errors.Is(err, service.ErrInvalidBOMFile),
+		errors.Is(err, service.ErrPartNotInCatalog):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// requestToken returns the caller's token for forwarding to other services
+func requestToken(c *gin.Context) string {
+	authToken := c.GetHeader("Authorization")
+	if authToken == "" {
+		// Try to get from context if available
+		if token, exists := c.Get("token"); exists {
+			authToken = token.(string)
+		}
+	}
+	return authToken
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/b2b-platform/equipment-service/models"
+	"github.com/b2b-platform/equipment-service/service"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (h *EquipmentHandler) ListMaintenancePlans(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	plans, err := h.service.ListMaintenancePlans(id)
+	if err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+func (h *EquipmentHandler) CreateMaintenancePlan(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var plan models.MaintenancePlan
+	if err := c.ShouldBindJSON(&plan); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+	plan.CreatedBy = userID
+
+	if err := h.service.CreateMaintenancePlan(id, &plan); err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+func (h *EquipmentHandler) GetMaintenancePlan(c *gin.Context) {
+	planID, err := uuid.Parse(c.Param("plan_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid plan_id"})
+		return
+	}
+
+	plan, err := h.service.GetMaintenancePlan(planID)
+	if err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *EquipmentHandler) UpdateMaintenancePlan(c *gin.Context) {
+	planID, err := uuid.Parse(c.Param("plan_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid plan_id"})
+		return
+	}
+
+	var changes models.MaintenancePlan
+	if err := c.ShouldBindJSON(&changes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.service.UpdateMaintenancePlan(planID, &changes)
+	if err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *EquipmentHandler) DeleteMaintenancePlan(c *gin.Context) {
+	planID, err := uuid.Parse(c.Param("plan_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid plan_id"})
+		return
+	}
+
+	if err := h.service.DeleteMaintenancePlan(planID); err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "maintenance plan deleted"})
+}
+
+// CompleteMaintenance records a plan as performed
+func (h *EquipmentHandler) CompleteMaintenance(c *gin.Context) {
+	planID, err := uuid.Parse(c.Param("plan_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid plan_id"})
+		return
+	}
+
+	var record models.MaintenanceRecord
+	// The body is optional
+	if err := c.ShouldBindJSON(&record); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+	record.PerformedBy = userID
+
+	created, err := h.service.CompleteMaintenance(planID, &record)
+	if err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+func (h *EquipmentHandler) ListMaintenanceRecords(c *gin.Context) {
+	planID, err := uuid.Parse(c.Param("plan_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid plan_id"})
+		return
+	}
+
+	records, err := h.service.ListMaintenanceRecords(planID)
+	if err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// ListMeterReadings returns an equipment's hour meter readings.
+// Query params: days (default 90).
+func (h *EquipmentHandler) ListMeterReadings(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil || days <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+		return
+	}
+
+	readings, err := h.service.ListMeterReadings(id, days)
+	if err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, readings)
+}
+
+func (h *EquipmentHandler) RecordMeterReading(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var reading models.MeterReading
+	if err := c.ShouldBindJSON(&reading); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+	reading.RecordedBy = &userID
+
+	if err := h.service.RecordMeterReading(id, &reading); err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reading)
+}
+
+// ForecastMaintenance projects maintenance services and spare-part demand
+// against stock. Query params: horizon_days (default 90).
+func (h *EquipmentHandler) ForecastMaintenance(c *gin.Context) {
+	var equipmentID *uuid.UUID
+	if param := c.Param("id"); param != "" {
+		id, err := uuid.Parse(param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+		equipmentID = &id
+	}
+
+	horizonDays, ok := horizonParam(c)
+	if !ok {
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+
+	forecast, err := h.service.ForecastMaintenance(tenantID, equipmentID, horizonDays, requestToken(c))
+	if err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+// RunMaintenanceForecast forecasts the tenant's maintenance and drafts PRs
+// for plans that draft their own. Query params: horizon_days (default 90).
+func (h *EquipmentHandler) RunMaintenanceForecast(c *gin.Context) {
+	horizonDays, ok := horizonParam(c)
+	if !ok {
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+
+	forecast, err := h.service.RunMaintenanceForecast(tenantID, horizonDays, requestToken(c))
+	if err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+func horizonParam(c *gin.Context) (int, bool) {
+	horizonDays, err := strconv.Atoi(c.DefaultQuery("horizon_days", strconv.Itoa(service.DefaultForecastHorizonDays)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "horizon_days must be an integer"})
+		return 0, false
+	}
+	return horizonDays, true
+}
+
+func writeMaintenanceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, service.ErrInvalidMaintenancePlan),
+		errors.Is(err, service.ErrInvalidMeterReading),
+		errors.Is(err, service.ErrInvalidHorizon),
+		errors.Is(err, service.ErrInvalidQuantity):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,41 @@
+-- BOM revisions: frozen copies of an equipment's BOM. Drafts can be
+-- refreshed from the working BOM until they are released.
+CREATE TABLE IF NOT EXISTS equipment.bom_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    equipment_id UUID NOT NULL REFERENCES equipment.equipment(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'draft',
+    description TEXT,
+    effective_date DATE,
+    created_by UUID NOT NULL,
+    released_by UUID,
+    released_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_equipment_bom_revision UNIQUE (equipment_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bom_revisions_tenant_id ON equipment.bom_revisions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_bom_revisions_equipment_status ON equipment.bom_revisions(equipment_id, status, effective_date);
+
+-- Lines keep the working BOM node IDs they were copied from so the tree can
+-- be rebuilt; they are not foreign keys as nodes may since have been removed
+CREATE TABLE IF NOT EXISTS equipment.bom_revision_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    revision_id UUID NOT NULL REFERENCES equipment.bom_revisions(id) ON DELETE CASCADE,
+    node_id UUID NOT NULL,
+    parent_node_id UUID,
+    part_id UUID,
+    part_number VARCHAR(255),
+    part_name VARCHAR(255) NOT NULL,
+    description TEXT,
+    quantity DECIMAL(15,2) NOT NULL DEFAULT 1,
+    unit VARCHAR(50),
+    position VARCHAR(100),
+    level INTEGER DEFAULT 0,
+    line_order INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_bom_revision_lines_revision_id ON equipment.bom_revision_lines(revision_id, line_order);
+CREATE INDEX IF NOT EXISTS idx_bom_revision_lines_part_id ON equipment.bom_revision_lines(part_id);
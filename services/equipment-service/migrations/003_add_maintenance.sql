@@ -0,0 +1,78 @@
+-- Preventive maintenance plans, triggered by calendar interval or by meter
+-- (operating hours) interval
+CREATE TABLE IF NOT EXISTS equipment.maintenance_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    equipment_id UUID NOT NULL REFERENCES equipment.equipment(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    trigger_type VARCHAR(20) NOT NULL,
+    interval_days INTEGER,
+    interval_hours DECIMAL(15,2),
+    lead_time_days INTEGER NOT NULL DEFAULT 14,
+    last_performed_at TIMESTAMP,
+    last_performed_meter DECIMAL(15,2),
+    auto_draft_pr BOOLEAN DEFAULT false,
+    is_active BOOLEAN DEFAULT true,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_plans_tenant_id ON equipment.maintenance_plans(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_maintenance_plans_equipment_id ON equipment.maintenance_plans(equipment_id);
+
+-- Parts consumed each time a plan's task is performed
+CREATE TABLE IF NOT EXISTS equipment.maintenance_plan_parts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    plan_id UUID NOT NULL REFERENCES equipment.maintenance_plans(id) ON DELETE CASCADE,
+    part_id UUID,
+    part_number VARCHAR(255),
+    part_name VARCHAR(255) NOT NULL,
+    quantity DECIMAL(15,2) NOT NULL DEFAULT 1,
+    unit VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_plan_parts_plan_id ON equipment.maintenance_plan_parts(plan_id);
+CREATE INDEX IF NOT EXISTS idx_maintenance_plan_parts_part_id ON equipment.maintenance_plan_parts(part_id);
+
+-- Meter (operating hour) readings per equipment
+CREATE TABLE IF NOT EXISTS equipment.meter_readings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    equipment_id UUID NOT NULL REFERENCES equipment.equipment(id) ON DELETE CASCADE,
+    reading DECIMAL(15,2) NOT NULL,
+    read_at TIMESTAMP NOT NULL,
+    recorded_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_meter_readings_equipment_read_at ON equipment.meter_readings(equipment_id, read_at);
+
+-- Maintenance performed against a plan
+CREATE TABLE IF NOT EXISTS equipment.maintenance_records (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    plan_id UUID NOT NULL REFERENCES equipment.maintenance_plans(id) ON DELETE CASCADE,
+    equipment_id UUID NOT NULL,
+    performed_at TIMESTAMP NOT NULL,
+    meter_reading DECIMAL(15,2),
+    notes TEXT,
+    performed_by UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_records_plan_id ON equipment.maintenance_records(plan_id);
+
+-- Purchase requests drafted ahead of a plan's upcoming service, one per due
+-- date. The row is claimed before the PR is created, so pr_id is set after.
+CREATE TABLE IF NOT EXISTS equipment.maintenance_pr_drafts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    plan_id UUID NOT NULL REFERENCES equipment.maintenance_plans(id) ON DELETE CASCADE,
+    due_date DATE NOT NULL,
+    pr_id UUID,
+    pr_number VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_maintenance_pr_draft UNIQUE (plan_id, due_date)
+);
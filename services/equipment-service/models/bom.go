@@ -0,0 +1,123 @@
+package models
+
+import (
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+)
+
+// ExplodedBOMNode is a BOM node with the quantity needed for one unit of the
+// equipment, i.e. its own quantity multiplied by those of all its parents
+type ExplodedBOMNode struct {
+	NodeID           uuid.UUID         `json:"node_id"`
+	ParentNodeID     *uuid.UUID        `json:"parent_node_id,omitempty"`
+	PartID           *uuid.UUID        `json:"part_id,omitempty"`
+	PartNumber       string            `json:"part_number"`
+	PartName         string            `json:"part_name"`
+	Position         string            `json:"position"`
+	Unit             string            `json:"unit"`
+	Level            int               `json:"level"`
+	Quantity         float64           `json:"quantity"`          // Per parent assembly
+	ExtendedQuantity float64           `json:"extended_quantity"` // Per equipment, times the requested build quantity
+	Children         []ExplodedBOMNode `json:"children"`
+}
+
+// PartRequirement totals one part across every place it appears in a BOM
+type PartRequirement struct {
+	PartID     *uuid.UUID `json:"part_id,omitempty"`
+	PartNumber string     `json:"part_number"`
+	PartName   string     `json:"part_name"`
+	Unit       string     `json:"unit"`
+	Quantity   float64    `json:"quantity"`
+	NodeCount  int        `json:"node_count"`
+}
+
+// BOMExplosion is an equipment's BOM as a tree with accumulated quantities
+// and the total requirement for each part
+type BOMExplosion struct {
+	EquipmentID  uuid.UUID         `json:"equipment_id"`
+	Quantity     float64           `json:"quantity"` // Units of equipment exploded
+	Nodes        []ExplodedBOMNode `json:"nodes"`
+	Requirements []PartRequirement `json:"requirements"`
+}
+
+// WhereUsed is one BOM position a part is used in
+type WhereUsed struct {
+	EquipmentID      uuid.UUID `json:"equipment_id"`
+	EquipmentNumber  string    `json:"equipment_number"`
+	EquipmentName    string    `json:"equipment_name"`
+	NodeID           uuid.UUID `json:"node_id"`
+	Position         string    `json:"position"`
+	Level            int       `json:"level"`
+	Quantity         float64   `json:"quantity"`          // Per parent assembly
+	ExtendedQuantity float64   `json:"extended_quantity"` // Per unit of equipment
+	Path             []string  `json:"path"`              // Part names from the top-level assembly down to this node
+}
+
+// PartPrice is marketplace-service's best current listing for a part
+type PartPrice struct {
+	PartID    uuid.UUID `json:"part_id"`
+	ListingID uuid.UUID `json:"listing_id"`
+	StoreID   uuid.UUID `json:"store_id"`
+	StoreName string    `json:"store_name"`
+	Price     float64   `json:"price"`
+	Currency  string    `json:"currency"`
+}
+
+// CostedBOMNode is a BOM node priced from current listings. Assemblies cost
+// the sum of their children; leaf nodes their own extended price.
+type CostedBOMNode struct {
+	NodeID           uuid.UUID       `json:"node_id"`
+	PartID           *uuid.UUID      `json:"part_id,omitempty"`
+	PartNumber       string          `json:"part_number"`
+	PartName         string          `json:"part_name"`
+	Level            int             `json:"level"`
+	ExtendedQuantity float64         `json:"extended_quantity"`
+	Price            *PartPrice      `json:"price,omitempty"`
+	ExtendedCost     money.Decimal   `json:"extended_cost"` // Price times extended quantity
+	RolledUpCost     money.Decimal   `json:"rolled_up_cost"`
+	Currency         string          `json:"currency"`
+	Complete         bool            `json:"complete"` // False when this node or a part under it has no price in the currency
+	Children         []CostedBOMNode `json:"children"`
+}
+
+// BOMCostRollup is the cost of building an equipment's BOM from current listings
+type BOMCostRollup struct {
+	EquipmentID   uuid.UUID       `json:"equipment_id"`
+	Quantity      float64         `json:"quantity"`
+	Currency      string          `json:"currency"`
+	TotalCost     money.Decimal   `json:"total_cost"`
+	Complete      bool            `json:"complete"`
+	UnpricedNodes []uuid.UUID     `json:"unpriced_nodes"` // Leaf nodes without a listing in the currency
+	Nodes         []CostedBOMNode `json:"nodes"`
+}
+
+// BOMImportLine reports how one row of an imported BOM file was read and matched
+type BOMImportLine struct {
+	Row        int           `json:"row"`   // Row in the file, the header being row 1
+	Level      int           `json:"level"` // Depth below the top-level lines
+	PartNumber string        `json:"part_number"`
+	PartName   string        `json:"part_name"`
+	Quantity   float64       `json:"quantity"`
+	NodeID     *uuid.UUID    `json:"node_id,omitempty"`
+	PartID     *uuid.UUID    `json:"part_id,omitempty"`
+	Status     string        `json:"status"` // matched, unmatched, ambiguous or error
+	Message    string        `json:"message,omitempty"`
+	Candidates []CatalogPart `json:"candidates,omitempty"` // Catalog parts an ambiguous line could be
+}
+
+// BOMImportResult is the outcome of importing a BOM file. Nothing is created
+// when the file has errors or on a dry run.
+type BOMImportResult struct {
+	EquipmentID uuid.UUID       `json:"equipment_id"`
+	FileName    string          `json:"file_name"`
+	Format      string          `json:"format"`
+	DryRun      bool            `json:"dry_run"`
+	Replace     bool            `json:"replace"` // Existing BOM nodes were (or would be) removed first
+	TotalLines  int             `json:"total_lines"`
+	Matched     int             `json:"matched"`
+	Unmatched   int             `json:"unmatched"`
+	Ambiguous   int             `json:"ambiguous"`
+	Errors      int             `json:"errors"`
+	Created     int             `json:"created"`
+	Lines       []BOMImportLine `json:"lines"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BOMRevision is a numbered copy of an equipment's BOM. A draft is refreshed
+// from the working BOM until it is released; released revisions never change
+// and the latest one whose effective date has passed is the effective BOM.
+type BOMRevision struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	EquipmentID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_equipment_bom_revision" json:"equipment_id"`
+	Revision      int        `gorm:"not null;uniqueIndex:idx_equipment_bom_revision" json:"revision"`
+	Status        string     `gorm:"type:varchar(50);not null;default:'draft'" json:"status"` // draft, released
+	Description   string     `gorm:"type:text" json:"description"`
+	EffectiveDate *time.Time `gorm:"type:date" json:"effective_date,omitempty"`
+	CreatedBy     uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	ReleasedBy    *uuid.UUID `gorm:"type:uuid" json:"released_by,omitempty"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Relationships
+	Lines []BOMRevisionLine `gorm:"foreignKey:RevisionID" json:"lines,omitempty"`
+}
+
+func (BOMRevision) TableName() string {
+	return "equipment.bom_revisions"
+}
+
+// BOMRevisionLine is a BOM node as it was when its revision was taken
+type BOMRevisionLine struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RevisionID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"revision_id"`
+	NodeID       uuid.UUID  `gorm:"type:uuid;not null" json:"node_id"` // Working BOM node the line was copied from
+	ParentNodeID *uuid.UUID `gorm:"type:uuid" json:"parent_node_id,omitempty"`
+	PartID       *uuid.UUID `gorm:"type:uuid;index" json:"part_id,omitempty"`
+	PartNumber   string     `gorm:"type:varchar(255)" json:"part_number"`
+	PartName     string     `gorm:"type:varchar(255);not null" json:"part_name"`
+	Description  string     `gorm:"type:text" json:"description"`
+	Quantity     float64    `gorm:"not null;default:1" json:"quantity"`
+	Unit         string     `gorm:"type:varchar(50)" json:"unit"`
+	Position     string     `gorm:"type:varchar(100)" json:"position"`
+	Level        int        `gorm:"default:0" json:"level"`
+	LineOrder    int        `gorm:"not null;default:0" json:"line_order"`
+}
+
+func (BOMRevisionLine) TableName() string {
+	return "equipment.bom_revision_lines"
+}
+
+// BOM diff change types
+const (
+	BOMChangeAdded           = "added"
+	BOMChangeRemoved         = "removed"
+	BOMChangeQuantityChanged = "quantity_changed"
+)
+
+// BOMDiffLine is one difference between two versions of a BOM
+type BOMDiffLine struct {
+	Change       string     `json:"change"` // added, removed, quantity_changed
+	Path         []string   `json:"path"`   // Part names from the top-level assembly down to the line
+	Level        int        `json:"level"`
+	PartID       *uuid.UUID `json:"part_id,omitempty"`
+	PartNumber   string     `json:"part_number"`
+	PartName     string     `json:"part_name"`
+	Position     string     `json:"position"`
+	Unit         string     `json:"unit"`
+	FromQuantity float64    `json:"from_quantity"`
+	ToQuantity   float64    `json:"to_quantity"`
+}
+
+// BOMDiff compares two revisions of a BOM, or a revision with the working
+// BOM when ToRevision is nil
+type BOMDiff struct {
+	EquipmentID     uuid.UUID     `json:"equipment_id"`
+	FromRevision    *BOMRevision  `json:"from_revision"`
+	ToRevision      *BOMRevision  `json:"to_revision,omitempty"`
+	Added           int           `json:"added"`
+	Removed         int           `json:"removed"`
+	QuantityChanged int           `json:"quantity_changed"`
+	Lines           []BOMDiffLine `json:"lines"`
+}
@@ -49,6 +49,9 @@ type BOMNode struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
+	// Current and alternative catalog parts, resolved when the BOM is read
+	Interchange *PartInterchange `gorm:"-" json:"interchange,omitempty"`
+
 	// Relationships
 	Equipment  Equipment  `gorm:"foreignKey:EquipmentID" json:"equipment,omitempty"`
 	ParentNode *BOMNode   `gorm:"foreignKey:ParentNodeID" json:"parent_node,omitempty"`
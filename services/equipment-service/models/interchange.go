@@ -0,0 +1,52 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// CatalogPart is the subset of a catalog library part shown alongside BOMs
+// and compatibility checks
+type CatalogPart struct {
+	ID           uuid.UUID `json:"id"`
+	PartNumber   string    `json:"part_number"`
+	Name         string    `json:"name"`
+	Status       string    `json:"status"`
+	Manufacturer string    `json:"manufacturer,omitempty"` // Set on part number lookups
+}
+
+// InterchangeOption is a catalog part usable in place of another
+type InterchangeOption struct {
+	Part         CatalogPart `json:"part"`
+	RelationType string      `json:"relation_type"` // supersedes, equivalent, alternative
+	Conditions   string      `json:"conditions,omitempty"`
+	Superseded   bool        `json:"superseded"`
+}
+
+// PartInterchange is catalog-service's resolution of a part through its
+// supersession and interchangeability cross references
+type PartInterchange struct {
+	PartID       uuid.UUID           `json:"part_id"`
+	Superseded   bool                `json:"superseded"`
+	CurrentPart  CatalogPart         `json:"current_part"`
+	Alternatives []InterchangeOption `json:"alternatives"`
+	Replaces     []InterchangeOption `json:"replaces"`
+}
+
+// CompatibilityCheck answers whether a part fits a piece of equipment, either
+// through its own mapping or through a part it supersedes or can stand in for
+type CompatibilityCheck struct {
+	EquipmentID  uuid.UUID               `json:"equipment_id"`
+	PartID       uuid.UUID               `json:"part_id"`
+	IsCompatible bool                    `json:"is_compatible"`
+	MatchedVia   string                  `json:"matched_via,omitempty"` // direct, supersedes, equivalent, alternative
+	Conditions   string                  `json:"conditions,omitempty"`
+	Mapping      *CompatibilityMapping   `json:"mapping,omitempty"`
+	CurrentPart  *CatalogPart            `json:"current_part,omitempty"` // Set when the part is superseded
+	Alternatives []CompatibleAlternative `json:"alternatives"`           // Interchangeable parts known to fit
+}
+
+// CompatibleAlternative is an interchangeable part with a compatible mapping
+type CompatibleAlternative struct {
+	InterchangeOption
+	MappingID uuid.UUID `json:"mapping_id"`
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaintenancePlan is a recurring preventive maintenance task for a piece of
+// equipment, due every IntervalDays (calendar) or every IntervalHours of
+// meter reading (meter) since it was last performed
+type MaintenancePlan struct {
+	ID                 uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	EquipmentID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"equipment_id"`
+	Name               string     `gorm:"type:varchar(255);not null" json:"name"`
+	Description        string     `gorm:"type:text" json:"description"`
+	TriggerType        string     `gorm:"type:varchar(20);not null" json:"trigger_type"` // calendar, meter
+	IntervalDays       *int       `json:"interval_days,omitempty"`
+	IntervalHours      *float64   `json:"interval_hours,omitempty"`
+	LeadTimeDays       int        `gorm:"not null;default:14" json:"lead_time_days"` // How far ahead of the due date parts are ordered
+	LastPerformedAt    *time.Time `json:"last_performed_at,omitempty"`
+	LastPerformedMeter *float64   `json:"last_performed_meter,omitempty"`
+	AutoDraftPR        bool       `gorm:"column:auto_draft_pr;default:false" json:"auto_draft_pr"`
+	IsActive           bool       `gorm:"default:true" json:"is_active"`
+	CreatedBy          uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+
+	// Relationships
+	Parts []MaintenancePlanPart `gorm:"foreignKey:PlanID" json:"parts"`
+}
+
+func (MaintenancePlan) TableName() string {
+	return "equipment.maintenance_plans"
+}
+
+// MaintenancePlanPart is a part consumed each time a plan's task is performed
+type MaintenancePlanPart struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PlanID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"plan_id"`
+	PartID     *uuid.UUID `gorm:"type:uuid;index" json:"part_id,omitempty"` // Reference to catalog library part
+	PartNumber string     `gorm:"type:varchar(255)" json:"part_number"`     // If not in library
+	PartName   string     `gorm:"type:varchar(255);not null" json:"part_name"`
+	Quantity   float64    `gorm:"not null;default:1" json:"quantity"`
+	Unit       string     `gorm:"type:varchar(50)" json:"unit"`
+}
+
+func (MaintenancePlanPart) TableName() string {
+	return "equipment.maintenance_plan_parts"
+}
+
+// MeterReading is an equipment's operating hour meter at a point in time
+type MeterReading struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID    uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	EquipmentID uuid.UUID  `gorm:"type:uuid;not null;index" json:"equipment_id"`
+	Reading     float64    `gorm:"not null" json:"reading"`
+	ReadAt      time.Time  `gorm:"not null" json:"read_at"`
+	RecordedBy  *uuid.UUID `gorm:"type:uuid" json:"recorded_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (MeterReading) TableName() string {
+	return "equipment.meter_readings"
+}
+
+// MaintenanceRecord is a completed run of a maintenance plan
+type MaintenanceRecord struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID     uuid.UUID `gorm:"type:uuid;not null" json:"tenant_id"`
+	PlanID       uuid.UUID `gorm:"type:uuid;not null;index" json:"plan_id"`
+	EquipmentID  uuid.UUID `gorm:"type:uuid;not null" json:"equipment_id"`
+	PerformedAt  time.Time `gorm:"not null" json:"performed_at"`
+	MeterReading *float64  `json:"meter_reading,omitempty"`
+	Notes        string    `gorm:"type:text" json:"notes"`
+	PerformedBy  uuid.UUID `gorm:"type:uuid;not null" json:"performed_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (MaintenanceRecord) TableName() string {
+	return "equipment.maintenance_records"
+}
+
+// MaintenancePRDraft is a purchase request drafted for one upcoming service
+// of a plan, so the same service is never drafted twice
+type MaintenancePRDraft struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID  uuid.UUID  `gorm:"type:uuid;not null" json:"tenant_id"`
+	PlanID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_maintenance_pr_draft" json:"plan_id"`
+	DueDate   time.Time  `gorm:"type:date;not null;uniqueIndex:idx_maintenance_pr_draft" json:"due_date"`
+	PRID      *uuid.UUID `gorm:"column:pr_id;type:uuid" json:"pr_id,omitempty"`
+	PRNumber  string     `gorm:"column:pr_number;type:varchar(50)" json:"pr_number,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (MaintenancePRDraft) TableName() string {
+	return "equipment.maintenance_pr_drafts"
+}
+
+// ScheduledService is one projected run of a maintenance plan within a
+// forecast horizon, with the parts it consumes
+type ScheduledService struct {
+	PlanID      uuid.UUID           `json:"plan_id"`
+	PlanName    string              `json:"plan_name"`
+	EquipmentID uuid.UUID           `json:"equipment_id"`
+	TriggerType string              `json:"trigger_type"`
+	DueDate     time.Time           `json:"due_date"`
+	OrderBy     time.Time           `json:"order_by"`  // Due date less the plan's lead time
+	Estimated   bool                `json:"estimated"` // Meter plans are projected from the recent usage rate
+	Overdue     bool                `json:"overdue"`
+	Parts       []ScheduledPartNeed `json:"parts"`
+	Drafted     bool                `json:"drafted"`         // A PR has been drafted for this service
+	PRID        *uuid.UUID          `json:"pr_id,omitempty"` // Missing while the draft is still being created
+	PRNumber    string              `json:"pr_number,omitempty"`
+}
+
+// ScheduledPartNeed is a part needed by a scheduled service and how much of
+// it is left uncovered once stock is allocated to earlier services
+type ScheduledPartNeed struct {
+	PartID     *uuid.UUID `json:"part_id,omitempty"`
+	PartNumber string     `json:"part_number"`
+	PartName   string     `json:"part_name"`
+	Unit       string     `json:"unit"`
+	Quantity   float64    `json:"quantity"`
+	FromStock  float64    `json:"from_stock"`
+	Shortfall  float64    `json:"shortfall"`
+}
+
+// PartDemand totals a part's projected consumption over a forecast horizon
+// against the tenant's warehouse stock
+type PartDemand struct {
+	PartID          *uuid.UUID `json:"part_id,omitempty"`
+	PartNumber      string     `json:"part_number"`
+	PartName        string     `json:"part_name"`
+	Unit            string     `json:"unit"`
+	Quantity        float64    `json:"quantity"`
+	FirstNeeded     time.Time  `json:"first_needed"`
+	OrderBy         *time.Time `json:"order_by,omitempty"` // Earliest order-by date of a service left short
+	Available       float64    `json:"available"`
+	SharedAvailable float64    `json:"shared_available"` // Offered by other tenants through the virtual warehouse
+	Shortfall       float64    `json:"shortfall"`
+}
+
+// SuggestedPR is a scheduled service whose order-by date has passed while
+// parts for it are short
+type SuggestedPR struct {
+	PlanID      uuid.UUID           `json:"plan_id"`
+	PlanName    string              `json:"plan_name"`
+	EquipmentID uuid.UUID           `json:"equipment_id"`
+	DueDate     time.Time           `json:"due_date"`
+	OrderBy     time.Time           `json:"order_by"`
+	Items       []ScheduledPartNeed `json:"items"`
+	AutoDraft   bool                `json:"auto_draft"`
+	Drafted     bool                `json:"drafted"`
+	PRID        *uuid.UUID          `json:"pr_id,omitempty"`
+	PRNumber    string              `json:"pr_number,omitempty"`
+}
+
+// UnscheduledPlan is an active plan that cannot be projected, such as a
+// meter plan without enough readings to estimate usage
+type UnscheduledPlan struct {
+	PlanID      uuid.UUID `json:"plan_id"`
+	PlanName    string    `json:"plan_name"`
+	EquipmentID uuid.UUID `json:"equipment_id"`
+	Reason      string    `json:"reason"`
+}
+
+// MaintenanceForecast projects spare-part demand from maintenance plans over
+// a horizon and compares it with stock
+type MaintenanceForecast struct {
+	GeneratedAt  time.Time          `json:"generated_at"`
+	HorizonDays  int                `json:"horizon_days"`
+	EquipmentID  *uuid.UUID         `json:"equipment_id,omitempty"`
+	Services     []ScheduledService `json:"services"`
+	Demand       []PartDemand       `json:"demand"`
+	SuggestedPRs []SuggestedPR      `json:"suggested_prs"`
+	Unscheduled  []UnscheduledPlan  `json:"unscheduled"`
+}
@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/b2b-platform/equipment-service/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BOMRepository struct {
@@ -39,3 +42,62 @@ func (r *BOMRepository) Update(node *models.BOMNode) error {
 func (r *BOMRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&models.BOMNode{}, id).Error
 }
+
+// ReplacePart re-points BOM nodes from one catalog part to another
+func (r *BOMRepository) ReplacePart(oldPartID, newPartID uuid.UUID) (int64, error) {
+	result := r.db.Model(&models.BOMNode{}).
+		Where("part_id = ?", oldPartID).
+		Update("part_id", newPartID)
+	return result.RowsAffected, result.Error
+}
+
+// ListByEquipment returns every node of an equipment's BOM, flat, top levels first
+func (r *BOMRepository) ListByEquipment(equipmentID uuid.UUID) ([]models.BOMNode, error) {
+	var nodes []models.BOMNode
+	err := r.db.Where("equipment_id = ?", equipmentID).
+		Order("level ASC, created_at ASC").
+		Find(&nodes).Error
+	return nodes, err
+}
+
+// ListByPart returns a tenant's BOM nodes that use a catalog part, with their equipment
+func (r *BOMRepository) ListByPart(tenantID, partID uuid.UUID) ([]models.BOMNode, error) {
+	var nodes []models.BOMNode
+	err := r.db.Preload("Equipment").
+		Where("tenant_id = ? AND part_id = ?", tenantID, partID).
+		Order("equipment_id ASC, level ASC").
+		Find(&nodes).Error
+	return nodes, err
+}
+
+// ImportTree writes an imported BOM in one transaction, optionally removing
+// the equipment's existing nodes first. Nodes must come parents first with
+// their IDs set; they are stamped in order so exports keep the file order.
+func (r *BOMRepository) ImportTree(equipmentID uuid.UUID, nodes []models.BOMNode, replace bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if replace {
+			if err := tx.Where("equipment_id = ?", equipmentID).Delete(&models.BOMNode{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(nodes) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		for i := range nodes {
+			nodes[i].CreatedAt = now.Add(time.Duration(i) * time.Microsecond)
+			nodes[i].UpdatedAt = nodes[i].CreatedAt
+		}
+		return tx.Omit(clause.Associations).CreateInBatches(nodes, 200).Error
+	})
+}
+
+// ListUnlinked returns an equipment's BOM nodes not linked to a catalog part
+func (r *BOMRepository) ListUnlinked(equipmentID uuid.UUID) ([]models.BOMNode, error) {
+	var nodes []models.BOMNode
+	err := r.db.Where("equipment_id = ? AND part_id IS NULL", equipmentID).
+		Order("level ASC, created_at ASC").
+		Find(&nodes).Error
+	return nodes, err
+}
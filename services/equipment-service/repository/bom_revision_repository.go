@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/b2b-platform/equipment-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BOMRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewBOMRevisionRepository(db *gorm.DB) *BOMRevisionRepository {
+	return &BOMRevisionRepository{db: db}
+}
+
+// Create numbers a new revision after the equipment's latest one and saves
+// it with its lines
+func (r *BOMRevisionRepository) Create(revision *models.BOMRevision, lines []models.BOMRevisionLine) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the equipment so concurrent revisions get distinct numbers
+		var equipment models.Equipment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").Where("id = ?", revision.EquipmentID).First(&equipment).Error; err != nil {
+			return err
+		}
+
+		var latest int
+		if err := tx.Model(&models.BOMRevision{}).
+			Where("equipment_id = ?", revision.EquipmentID).
+			Select("COALESCE(MAX(revision), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		revision.Revision = latest + 1
+
+		if err := tx.Omit(clause.Associations).Create(revision).Error; err != nil {
+			return err
+		}
+		return createRevisionLines(tx, revision.ID, lines)
+	})
+}
+
+func createRevisionLines(tx *gorm.DB, revisionID uuid.UUID, lines []models.BOMRevisionLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	for i := range lines {
+		lines[i].RevisionID = revisionID
+	}
+	return tx.CreateInBatches(lines, 200).Error
+}
+
+func (r *BOMRevisionRepository) GetByID(id uuid.UUID) (*models.BOMRevision, error) {
+	var revision models.BOMRevision
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("line_order ASC")
+	}).Where("id = ?", id).First(&revision).Error
+	return &revision, err
+}
+
+// ListByEquipment returns an equipment's revisions, newest first, without their lines
+func (r *BOMRevisionRepository) ListByEquipment(equipmentID uuid.UUID) ([]models.BOMRevision, error) {
+	var revisions []models.BOMRevision
+	err := r.db.Where("equipment_id = ?", equipmentID).
+		Order("revision DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// GetEffective returns the latest released revision in effect on a date
+func (r *BOMRevisionRepository) GetEffective(equipmentID uuid.UUID, at time.Time) (*models.BOMRevision, error) {
+	var revision models.BOMRevision
+	err := r.db.Where("equipment_id = ? AND status = ? AND effective_date <= ?", equipmentID, "released", at).
+		Order("effective_date DESC, revision DESC").
+		First(&revision).Error
+	return &revision, err
+}
+
+func (r *BOMRevisionRepository) Update(revision *models.BOMRevision) error {
+	return r.db.Omit(clause.Associations).Save(revision).Error
+}
+
+// ReplaceLines swaps a draft revision's lines for a fresh copy of the BOM
+func (r *BOMRevisionRepository) ReplaceLines(revision *models.BOMRevision, lines []models.BOMRevisionLine) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("revision_id = ?", revision.ID).Delete(&models.BOMRevisionLine{}).Error; err != nil {
+			return err
+		}
+		if err := createRevisionLines(tx, revision.ID, lines); err != nil {
+			return err
+		}
+		return tx.Model(revision).Update("updated_at", time.Now()).Error
+	})
+}
+
+func (r *BOMRevisionRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.BOMRevision{}, "id = ?", id).Error
+}
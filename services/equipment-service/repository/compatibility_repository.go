@@ -53,3 +53,11 @@ func (r *CompatibilityRepository) CheckCompatibility(equipmentID, partID uuid.UU
 	}
 	return &mapping, err
 }
+
+// ReplacePart re-points compatibility mappings from one catalog part to another
+func (r *CompatibilityRepository) ReplacePart(oldPartID, newPartID uuid.UUID) (int64, error) {
+	result := r.db.Model(&models.CompatibilityMapping{}).
+		Where("part_id = ?", oldPartID).
+		Update("part_id", newPartID)
+	return result.RowsAffected, result.Error
+}
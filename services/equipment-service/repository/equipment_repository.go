@@ -39,6 +39,22 @@ func (r *EquipmentRepository) Update(equipment *models.Equipment) error {
 	return r.db.Save(equipment).Error
 }
 
+func (r *EquipmentRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Equipment{}, "id = ?", id).Error
+}
+
+// ListAfter returns up to limit equipment with IDs after a cursor, in ID
+// order, optionally for one tenant
+func (r *EquipmentRepository) ListAfter(after uuid.UUID, tenantID *uuid.UUID, limit int) ([]models.Equipment, error) {
+	var equipment []models.Equipment
+	query := r.db.Where("id > ?", after)
+	if tenantID != nil {
+		query = query.Where("tenant_id = ?", *tenantID)
+	}
+	err := query.Order("id ASC").Limit(limit).Find(&equipment).Error
+	return equipment, err
+}
+
 func (r *EquipmentRepository) GetByEquipmentNumber(tenantID uuid.UUID, equipmentNumber string) (*models.Equipment, error) {
 	var equipment models.Equipment
 	err := r.db.Where("tenant_id = ? AND equipment_number = ?", tenantID, equipmentNumber).First(&equipment).Error
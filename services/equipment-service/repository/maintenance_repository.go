@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/b2b-platform/equipment-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MaintenanceRepository struct {
+	db *gorm.DB
+}
+
+func NewMaintenanceRepository(db *gorm.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+func (r *MaintenanceRepository) CreatePlan(plan *models.MaintenancePlan) error {
+	return r.db.Create(plan).Error
+}
+
+func (r *MaintenanceRepository) GetPlan(id uuid.UUID) (*models.MaintenancePlan, error) {
+	var plan models.MaintenancePlan
+	err := r.db.Preload("Parts").Where("id = ?", id).First(&plan).Error
+	return &plan, err
+}
+
+func (r *MaintenanceRepository) ListPlansByEquipment(equipmentID uuid.UUID) ([]models.MaintenancePlan, error) {
+	var plans []models.MaintenancePlan
+	err := r.db.Preload("Parts").
+		Where("equipment_id = ?", equipmentID).
+		Order("created_at ASC").
+		Find(&plans).Error
+	return plans, err
+}
+
+// ListActivePlans returns a tenant's active plans with their parts,
+// optionally for one piece of equipment
+func (r *MaintenanceRepository) ListActivePlans(tenantID uuid.UUID, equipmentID *uuid.UUID) ([]models.MaintenancePlan, error) {
+	var plans []models.MaintenancePlan
+	query := r.db.Preload("Parts").Where("tenant_id = ? AND is_active = ?", tenantID, true)
+	if equipmentID != nil {
+		query = query.Where("equipment_id = ?", *equipmentID)
+	}
+	err := query.Order("equipment_id ASC, created_at ASC").Find(&plans).Error
+	return plans, err
+}
+
+// ListAutoDraftPlans returns active plans, across tenants, that draft PRs
+// without being asked
+func (r *MaintenanceRepository) ListAutoDraftPlans() ([]models.MaintenancePlan, error) {
+	var plans []models.MaintenancePlan
+	err := r.db.Where("is_active = ? AND auto_draft_pr = ?", true, true).
+		Order("tenant_id ASC, created_at ASC").
+		Find(&plans).Error
+	return plans, err
+}
+
+// UpdatePlan saves a plan and replaces its parts
+func (r *MaintenanceRepository) UpdatePlan(plan *models.MaintenancePlan) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(plan).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("plan_id = ?", plan.ID).Delete(&models.MaintenancePlanPart{}).Error; err != nil {
+			return err
+		}
+		if len(plan.Parts) == 0 {
+			return nil
+		}
+		for i := range plan.Parts {
+			plan.Parts[i].ID = uuid.Nil
+			plan.Parts[i].PlanID = plan.ID
+		}
+		return tx.Create(&plan.Parts).Error
+	})
+}
+
+func (r *MaintenanceRepository) DeletePlan(id uuid.UUID) error {
+	return r.db.Delete(&models.MaintenancePlan{}, "id = ?", id).Error
+}
+
+func (r *MaintenanceRepository) CreateMeterReading(reading *models.MeterReading) error {
+	return r.db.Create(reading).Error
+}
+
+// ListMeterReadings returns an equipment's readings taken since a time, oldest first
+func (r *MaintenanceRepository) ListMeterReadings(equipmentID uuid.UUID, since time.Time) ([]models.MeterReading, error) {
+	var readings []models.MeterReading
+	err := r.db.Where("equipment_id = ? AND read_at >= ?", equipmentID, since).
+		Order("read_at ASC").
+		Find(&readings).Error
+	return readings, err
+}
+
+// LatestMeterReading returns an equipment's most recent reading
+func (r *MaintenanceRepository) LatestMeterReading(equipmentID uuid.UUID) (*models.MeterReading, error) {
+	var reading models.MeterReading
+	err := r.db.Where("equipment_id = ?", equipmentID).
+		Order("read_at DESC").
+		First(&reading).Error
+	return &reading, err
+}
+
+// CreateRecord saves a completed maintenance run and restarts its plan's
+// interval from it
+func (r *MaintenanceRepository) CreateRecord(record *models.MaintenanceRecord) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"last_performed_at": record.PerformedAt,
+			"updated_at":        time.Now(),
+		}
+		if record.MeterReading != nil {
+			updates["last_performed_meter"] = *record.MeterReading
+		}
+		return tx.Model(&models.MaintenancePlan{}).
+			Where("id = ? AND (last_performed_at IS NULL OR last_performed_at <= ?)", record.PlanID, record.PerformedAt).
+			Updates(updates).Error
+	})
+}
+
+func (r *MaintenanceRepository) ListRecords(planID uuid.UUID) ([]models.MaintenanceRecord, error) {
+	var records []models.MaintenanceRecord
+	err := r.db.Where("plan_id = ?", planID).
+		Order("performed_at DESC").
+		Find(&records).Error
+	return records, err
+}
+
+// ListDrafts returns the PR drafts for plans' services due on or after a date
+func (r *MaintenanceRepository) ListDrafts(planIDs []uuid.UUID, from time.Time) ([]models.MaintenancePRDraft, error) {
+	var drafts []models.MaintenancePRDraft
+	if len(planIDs) == 0 {
+		return drafts, nil
+	}
+	err := r.db.Where("plan_id IN ? AND due_date >= ?", planIDs, from).
+		Find(&drafts).Error
+	return drafts, err
+}
+
+// ClaimDraft reserves a plan's service for drafting. It reports false when
+// the service was already claimed, so only one PR is ever drafted for it.
+func (r *MaintenanceRepository) ClaimDraft(draft *models.MaintenancePRDraft) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(draft)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CompleteDraft records the PR created for a claimed draft
+func (r *MaintenanceRepository) CompleteDraft(id, prID uuid.UUID, prNumber string) error {
+	return r.db.Model(&models.MaintenancePRDraft{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"pr_id": prID, "pr_number": prNumber}).Error
+}
+
+// ReleaseDraft drops a claim whose PR could not be created
+func (r *MaintenanceRepository) ReleaseDraft(id uuid.UUID) error {
+	return r.db.Delete(&models.MaintenancePRDraft{}, "id = ?", id).Error
+}
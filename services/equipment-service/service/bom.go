@@ -0,0 +1,502 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/equipment-service/models"
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+)
+
+// maxBOMDepth guards BOM walks against a corrupted parent chain
+const maxBOMDepth = 50
+
+var (
+	ErrBOMCycle          = errors.New("a part cannot contain itself")
+	ErrInvalidParentNode = errors.New("parent node must belong to the same equipment")
+	ErrInvalidQuantity   = errors.New("quantity must be greater than zero")
+	ErrNoNodesSelected   = errors.New("select at least one BOM node")
+	ErrUnknownBOMNode    = errors.New("node is not part of this equipment's BOM")
+)
+
+// BOMPurchaseRequest selects BOM nodes to turn into a purchase request
+type BOMPurchaseRequest struct {
+	NodeIDs      []uuid.UUID `json:"node_ids" binding:"required"`
+	Quantity     float64     `json:"quantity"` // Units of equipment to buy for, default 1
+	Title        string      `json:"title"`
+	Description  string      `json:"description"`
+	Priority     string      `json:"priority"`
+	Currency     string      `json:"currency"`
+	RequiredDate *time.Time  `json:"required_date"`
+
+	// Released revision to source from instead of the working BOM. The PR
+	// and the POs raised from it keep a reference to the revision.
+	BOMRevisionID *uuid.UUID `json:"bom_revision_id"`
+}
+
+// AddBOMNode adds a node under its parent, deriving its level. A node may
+// not sit under an assembly of the same part, which would make the part
+// contain itself.
+func (s *EquipmentService) AddBOMNode(node *models.BOMNode) error {
+	if node.Quantity == 0 {
+		node.Quantity = 1
+	}
+	if node.Quantity < 0 {
+		return ErrInvalidQuantity
+	}
+
+	node.Level = 0
+	if node.ParentNodeID != nil {
+		parent, err := s.bomRepo.GetByID(*node.ParentNodeID)
+		if err != nil {
+			return err
+		}
+		if parent.EquipmentID != node.EquipmentID {
+			return ErrInvalidParentNode
+		}
+		if err := s.checkBOMCycle(parent, node); err != nil {
+			return err
+		}
+		node.Level = parent.Level + 1
+	}
+
+	return s.bomRepo.Create(node)
+}
+
+// checkBOMCycle walks up from the new node's parent looking for the node
+// itself or an assembly of the same part
+func (s *EquipmentService) checkBOMCycle(parent *models.BOMNode, node *models.BOMNode) error {
+	nodes, err := s.bomRepo.ListByEquipment(parent.EquipmentID)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uuid.UUID]*models.BOMNode, len(nodes))
+	for i := range nodes {
+		byID[nodes[i].ID] = &nodes[i]
+	}
+
+	current := byID[parent.ID]
+	for depth := 0; current != nil; depth++ {
+		if depth >= maxBOMDepth {
+			return fmt.Errorf("%w: BOM is deeper than %d levels", ErrBOMCycle, maxBOMDepth)
+		}
+		if node.ID != uuid.Nil && current.ID == node.ID {
+			return ErrBOMCycle
+		}
+		if samePart(current, node) {
+			return fmt.Errorf("%w: %s is already an assembly above this position", ErrBOMCycle, current.PartName)
+		}
+		if current.ParentNodeID == nil {
+			break
+		}
+		current = byID[*current.ParentNodeID]
+	}
+	return nil
+}
+
+// samePart reports whether two nodes refer to the same part, by catalog ID
+// or, for parts outside the catalog, by part number
+func samePart(a, b *models.BOMNode) bool {
+	if a.PartID != nil && b.PartID != nil {
+		return *a.PartID == *b.PartID
+	}
+	return a.PartNumber != "" && strings.EqualFold(a.PartNumber, b.PartNumber)
+}
+
+// ExplodeBOM returns the equipment's full BOM tree with the quantity of each
+// node needed to build the given number of units
+func (s *EquipmentService) ExplodeBOM(equipmentID uuid.UUID, quantity float64) (*models.BOMExplosion, error) {
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity < 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	nodes, err := s.bomRepo.ListByEquipment(equipmentID)
+	if err != nil {
+		return nil, err
+	}
+	return newBOMExplosion(equipmentID, nodes, quantity)
+}
+
+func newBOMExplosion(equipmentID uuid.UUID, nodes []models.BOMNode, quantity float64) (*models.BOMExplosion, error) {
+	roots, err := explodeBOM(nodes, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BOMExplosion{
+		EquipmentID:  equipmentID,
+		Quantity:     quantity,
+		Nodes:        roots,
+		Requirements: partRequirements(roots),
+	}, nil
+}
+
+// explodeBOM nests flat nodes into trees, multiplying quantities down each branch
+func explodeBOM(nodes []models.BOMNode, quantity float64) ([]models.ExplodedBOMNode, error) {
+	children := make(map[uuid.UUID][]models.BOMNode)
+	var roots []models.BOMNode
+	for _, node := range nodes {
+		if node.ParentNodeID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		children[*node.ParentNodeID] = append(children[*node.ParentNodeID], node)
+	}
+
+	var explode func(node models.BOMNode, level int, multiplier float64) (models.ExplodedBOMNode, error)
+	explode = func(node models.BOMNode, level int, multiplier float64) (models.ExplodedBOMNode, error) {
+		if level >= maxBOMDepth {
+			return models.ExplodedBOMNode{}, fmt.Errorf("%w: BOM is deeper than %d levels", ErrBOMCycle, maxBOMDepth)
+		}
+
+		exploded := models.ExplodedBOMNode{
+			NodeID:           node.ID,
+			ParentNodeID:     node.ParentNodeID,
+			PartID:           node.PartID,
+			PartNumber:       node.PartNumber,
+			PartName:         node.PartName,
+			Position:         node.Position,
+			Unit:             node.Unit,
+			Level:            level,
+			Quantity:         node.Quantity,
+			ExtendedQuantity: multiplier * node.Quantity,
+			Children:         []models.ExplodedBOMNode{},
+		}
+		for _, child := range children[node.ID] {
+			explodedChild, err := explode(child, level+1, exploded.ExtendedQuantity)
+			if err != nil {
+				return exploded, err
+			}
+			exploded.Children = append(exploded.Children, explodedChild)
+		}
+		return exploded, nil
+	}
+
+	exploded := make([]models.ExplodedBOMNode, 0, len(roots))
+	for _, root := range roots {
+		node, err := explode(root, 0, quantity)
+		if err != nil {
+			return nil, err
+		}
+		exploded = append(exploded, node)
+	}
+	return exploded, nil
+}
+
+// partRequirements totals the extended quantity of each part across the tree
+func partRequirements(roots []models.ExplodedBOMNode) []models.PartRequirement {
+	index := make(map[string]int)
+	var requirements []models.PartRequirement
+
+	var walk func(nodes []models.ExplodedBOMNode)
+	walk = func(nodes []models.ExplodedBOMNode) {
+		for _, node := range nodes {
+			key := requirementKey(node)
+			i, ok := index[key]
+			if !ok {
+				i = len(requirements)
+				index[key] = i
+				requirements = append(requirements, models.PartRequirement{
+					PartID:     node.PartID,
+					PartNumber: node.PartNumber,
+					PartName:   node.PartName,
+					Unit:       node.Unit,
+				})
+			}
+			requirements[i].Quantity += node.ExtendedQuantity
+			requirements[i].NodeCount++
+			walk(node.Children)
+		}
+	}
+	walk(roots)
+
+	sort.SliceStable(requirements, func(i, j int) bool {
+		return requirements[i].PartName < requirements[j].PartName
+	})
+	return requirements
+}
+
+func requirementKey(node models.ExplodedBOMNode) string {
+	if node.PartID != nil {
+		return node.PartID.String()
+	}
+	if node.PartNumber != "" {
+		return "number:" + strings.ToUpper(node.PartNumber)
+	}
+	return "node:" + node.NodeID.String()
+}
+
+// WhereUsed lists every BOM position in the tenant's equipment that uses a part
+func (s *EquipmentService) WhereUsed(tenantID, partID uuid.UUID) ([]models.WhereUsed, error) {
+	uses, err := s.bomRepo.ListByPart(tenantID, partID)
+	if err != nil {
+		return nil, err
+	}
+
+	boms := make(map[uuid.UUID]map[uuid.UUID]*models.BOMNode)
+	result := make([]models.WhereUsed, 0, len(uses))
+	for _, use := range uses {
+		bom, ok := boms[use.EquipmentID]
+		if !ok {
+			nodes, err := s.bomRepo.ListByEquipment(use.EquipmentID)
+			if err != nil {
+				return nil, err
+			}
+			bom = make(map[uuid.UUID]*models.BOMNode, len(nodes))
+			for i := range nodes {
+				bom[nodes[i].ID] = &nodes[i]
+			}
+			boms[use.EquipmentID] = bom
+		}
+
+		extended := use.Quantity
+		path := []string{use.PartName}
+		parentID := use.ParentNodeID
+		for depth := 0; parentID != nil && depth < maxBOMDepth; depth++ {
+			parent, ok := bom[*parentID]
+			if !ok {
+				break
+			}
+			extended *= parent.Quantity
+			path = append([]string{parent.PartName}, path...)
+			parentID = parent.ParentNodeID
+		}
+
+		result = append(result, models.WhereUsed{
+			EquipmentID:      use.EquipmentID,
+			EquipmentNumber:  use.Equipment.EquipmentNumber,
+			EquipmentName:    use.Equipment.Name,
+			NodeID:           use.ID,
+			Position:         use.Position,
+			Level:            len(path) - 1,
+			Quantity:         use.Quantity,
+			ExtendedQuantity: extended,
+			Path:             path,
+		})
+	}
+	return result, nil
+}
+
+// RollUpCost prices the BOM from current marketplace listings in a currency
+func (s *EquipmentService) RollUpCost(equipmentID uuid.UUID, quantity float64, currency, authToken string) (*models.BOMCostRollup, error) {
+	if currency == "" {
+		currency = "USD"
+	}
+
+	explosion, err := s.ExplodeBOM(equipmentID, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := s.bomPrices(explosion.Nodes, currency, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	rollup := &models.BOMCostRollup{
+		EquipmentID:   equipmentID,
+		Quantity:      explosion.Quantity,
+		Currency:      currency,
+		Complete:      true,
+		UnpricedNodes: []uuid.UUID{},
+		Nodes:         make([]models.CostedBOMNode, 0, len(explosion.Nodes)),
+	}
+	for _, node := range explosion.Nodes {
+		costed := costNode(node, prices, currency, &rollup.UnpricedNodes)
+		rollup.TotalCost = rollup.TotalCost.Add(costed.RolledUpCost)
+		rollup.Complete = rollup.Complete && costed.Complete
+		rollup.Nodes = append(rollup.Nodes, costed)
+	}
+	return rollup, nil
+}
+
+// bomPrices looks up listing prices for every catalog part in the tree
+func (s *EquipmentService) bomPrices(nodes []models.ExplodedBOMNode, currency, authToken string) (map[uuid.UUID]models.PartPrice, error) {
+	var partIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	var collect func(nodes []models.ExplodedBOMNode)
+	collect = func(nodes []models.ExplodedBOMNode) {
+		for _, node := range nodes {
+			if node.PartID != nil && !seen[*node.PartID] {
+				seen[*node.PartID] = true
+				partIDs = append(partIDs, *node.PartID)
+			}
+			collect(node.Children)
+		}
+	}
+	collect(nodes)
+
+	if len(partIDs) == 0 {
+		return map[uuid.UUID]models.PartPrice{}, nil
+	}
+	return s.marketplaceClient.GetPartPrices(authToken, partIDs, currency)
+}
+
+// costNode prices a node and its subtree. Assemblies roll up their children;
+// their own listing price is shown but not added, as buying the assembly and
+// its components would count them twice.
+func costNode(node models.ExplodedBOMNode, prices map[uuid.UUID]models.PartPrice, currency string, unpriced *[]uuid.UUID) models.CostedBOMNode {
+	costed := models.CostedBOMNode{
+		NodeID:           node.NodeID,
+		PartID:           node.PartID,
+		PartNumber:       node.PartNumber,
+		PartName:         node.PartName,
+		Level:            node.Level,
+		ExtendedQuantity: node.ExtendedQuantity,
+		Currency:         currency,
+		Complete:         true,
+		Children:         make([]models.CostedBOMNode, 0, len(node.Children)),
+	}
+
+	if price, ok := nodePrice(node, prices, currency); ok {
+		costed.Price = &price
+		costed.ExtendedCost = money.NewFromFloat(price.Price).Mul(money.NewFromFloat(node.ExtendedQuantity))
+	}
+
+	if len(node.Children) == 0 {
+		if costed.Price == nil {
+			costed.Complete = false
+			*unpriced = append(*unpriced, node.NodeID)
+		}
+		costed.RolledUpCost = costed.ExtendedCost
+		return costed
+	}
+
+	for _, child := range node.Children {
+		costedChild := costNode(child, prices, currency, unpriced)
+		costed.RolledUpCost = costed.RolledUpCost.Add(costedChild.RolledUpCost)
+		costed.Complete = costed.Complete && costedChild.Complete
+		costed.Children = append(costed.Children, costedChild)
+	}
+	return costed
+}
+
+// nodePrice returns the node's listing price if there is one in the currency
+func nodePrice(node models.ExplodedBOMNode, prices map[uuid.UUID]models.PartPrice, currency string) (models.PartPrice, bool) {
+	if node.PartID == nil {
+		return models.PartPrice{}, false
+	}
+	price, ok := prices[*node.PartID]
+	if !ok || !strings.EqualFold(price.Currency, currency) {
+		return models.PartPrice{}, false
+	}
+	return price, true
+}
+
+// CreatePRFromBOM turns selected BOM nodes into a draft purchase request.
+// Quantities are the nodes' extended quantities for the requested number of
+// units, and lines for the same part are combined. Selecting an assembly buys
+// the assembly itself, not its components. When a BOM revision is given, node
+// IDs are the node_id of the revision's lines.
+func (s *EquipmentService) CreatePRFromBOM(equipmentID uuid.UUID, req *BOMPurchaseRequest, authToken string) (*PurchaseRequest, error) {
+	if len(req.NodeIDs) == 0 {
+		return nil, ErrNoNodesSelected
+	}
+	if req.Currency == "" {
+		req.Currency = "USD"
+	}
+
+	equipment, err := s.equipmentRepo.GetByID(equipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var explosion *models.BOMExplosion
+	if req.BOMRevisionID != nil {
+		explosion, err = s.explodeReleasedRevision(equipmentID, *req.BOMRevisionID, req.Quantity)
+	} else {
+		explosion, err = s.ExplodeBOM(equipmentID, req.Quantity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]models.ExplodedBOMNode)
+	var index func(nodes []models.ExplodedBOMNode)
+	index = func(nodes []models.ExplodedBOMNode) {
+		for _, node := range nodes {
+			byID[node.NodeID] = node
+			index(node.Children)
+		}
+	}
+	index(explosion.Nodes)
+
+	selected := make([]models.ExplodedBOMNode, 0, len(req.NodeIDs))
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range req.NodeIDs {
+		node, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownBOMNode, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			// Only the node itself is bought, not its components
+			node.Children = nil
+			selected = append(selected, node)
+		}
+	}
+
+	prices, err := s.bomPrices(selected, req.Currency, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	pr := &PurchaseRequest{
+		EquipmentID:   &equipment.ID,
+		BOMRevisionID: req.BOMRevisionID,
+		Title:         req.Title,
+		Description:   req.Description,
+		Priority:      req.Priority,
+		Currency:      req.Currency,
+		RequiredDate:  req.RequiredDate,
+	}
+	if pr.Title == "" {
+		pr.Title = fmt.Sprintf("Parts for %s %s", equipment.EquipmentNumber, equipment.Name)
+	}
+
+	lines := make(map[string]int)
+	for _, node := range selected {
+		key := requirementKey(node)
+		spec := fmt.Sprintf("Equipment %s", equipment.EquipmentNumber)
+		if node.Position != "" {
+			spec += ", position " + node.Position
+		}
+
+		if i, ok := lines[key]; ok {
+			pr.Items[i].Quantity += node.ExtendedQuantity
+			pr.Items[i].Specifications += "; " + spec
+			continue
+		}
+
+		item := PRItem{
+			PartID:         node.PartID,
+			Description:    node.PartName,
+			Quantity:       node.ExtendedQuantity,
+			Unit:           node.Unit,
+			Specifications: spec,
+		}
+		if node.PartNumber != "" {
+			item.Description = fmt.Sprintf("%s (%s)", node.PartName, node.PartNumber)
+		}
+		if price, ok := nodePrice(node, prices, req.Currency); ok {
+			item.UnitPrice = money.NewFromFloat(price.Price)
+		}
+		lines[key] = len(pr.Items)
+		pr.Items = append(pr.Items, item)
+	}
+
+	for i := range pr.Items {
+		item := &pr.Items[i]
+		item.TotalPrice = item.UnitPrice.Mul(money.NewFromFloat(item.Quantity))
+		pr.Budget = pr.Budget.Add(item.TotalPrice)
+	}
+
+	return s.procurementClient.CreatePR(authToken, pr)
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// BOM file formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Columns of a BOM file. Headers are matched case-insensitively with spaces
+// and dashes read as underscores, so "Part Number" is part_number.
+const (
+	BOMFieldLevel         = "level"
+	BOMFieldPartNumber    = "part_number"
+	BOMFieldPartName      = "part_name"
+	BOMFieldDescription   = "description"
+	BOMFieldQuantity      = "quantity"
+	BOMFieldUnit          = "unit"
+	BOMFieldPosition      = "position"
+	BOMFieldManufacturer  = "manufacturer"
+	BOMFieldCatalogPartID = "catalog_part_id"
+)
+
+// bomFieldAliases maps other common spreadsheet and PLM headers to BOM columns
+var bomFieldAliases = map[string]string{
+	"lvl":          BOMFieldLevel,
+	"bom_level":    BOMFieldLevel,
+	"item_number":  BOMFieldPartNumber,
+	"part_no":      BOMFieldPartNumber,
+	"part":         BOMFieldPartNumber,
+	"name":         BOMFieldPartName,
+	"qty":          BOMFieldQuantity,
+	"uom":          BOMFieldUnit,
+	"find_number":  BOMFieldPosition,
+	"find_no":      BOMFieldPosition,
+	"mfr":          BOMFieldManufacturer,
+	"part_id":      BOMFieldCatalogPartID,
+	"reference_id": BOMFieldCatalogPartID,
+}
+
+// bomExportHeaders are the columns of an exported BOM, readable by the import
+var bomExportHeaders = []string{"Level", "Part Number", "Part Name", "Description", "Quantity", "Unit", "Position", "Catalog Part ID"}
+
+var ErrInvalidBOMFile = errors.New("invalid BOM file")
+
+// bomFile is a parsed BOM file: data rows keyed by column
+type bomFile struct {
+	Columns map[string]int
+	Rows    [][]string
+}
+
+// value returns a row's trimmed cell for a column, or "" when it is missing
+func (f *bomFile) value(row []string, field string) string {
+	i, ok := f.Columns[field]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// detectBOMFormat picks the file format from an explicit value or the file extension
+func detectBOMFormat(format, fileName string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(fileName), ".")
+	}
+	format = strings.ToLower(format)
+	switch format {
+	case FormatCSV, FormatXLSX:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported format %q, use csv or xlsx", ErrInvalidBOMFile, format)
+	}
+}
+
+func readBOMFile(format string, r io.Reader) (*bomFile, error) {
+	var records [][]string
+	switch format {
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		var err error
+		if records, err = reader.ReadAll(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBOMFile, err)
+		}
+
+	case FormatXLSX:
+		workbook, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBOMFile, err)
+		}
+		defer workbook.Close()
+
+		sheets := workbook.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("%w: workbook has no sheets", ErrInvalidBOMFile)
+		}
+		// Only the first sheet is imported
+		if records, err = workbook.GetRows(sheets[0]); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBOMFile, err)
+		}
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidBOMFile)
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		field := canonicalBOMField(strings.TrimPrefix(header, "\ufeff"))
+		if _, taken := columns[field]; field != "" && !taken {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns[BOMFieldLevel]; !ok {
+		return nil, fmt.Errorf("%w: a %s column is required", ErrInvalidBOMFile, BOMFieldLevel)
+	}
+	_, hasNumber := columns[BOMFieldPartNumber]
+	_, hasName := columns[BOMFieldPartName]
+	if !hasNumber && !hasName {
+		return nil, fmt.Errorf("%w: a %s or %s column is required", ErrInvalidBOMFile, BOMFieldPartNumber, BOMFieldPartName)
+	}
+
+	return &bomFile{Columns: columns, Rows: records[1:]}, nil
+}
+
+// canonicalBOMField turns a header such as "Part Number" or "Qty" into its column
+func canonicalBOMField(header string) string {
+	field := strings.ToLower(strings.TrimSpace(header))
+	field = strings.NewReplacer(" ", "_", "-", "_", ".", "").Replace(field)
+	switch field {
+	case BOMFieldLevel, BOMFieldPartNumber, BOMFieldPartName, BOMFieldDescription, BOMFieldQuantity,
+		BOMFieldUnit, BOMFieldPosition, BOMFieldManufacturer, BOMFieldCatalogPartID:
+		return field
+	}
+	return bomFieldAliases[field]
+}
+
+// parseBOMLevel reads the level of an indented BOM line. It accepts a plain
+// number ("2"), PLM dot indentation (".2", "..2" or "..") and outline
+// numbering ("1.2.1", whose level is its number of segments). Levels are
+// only compared with each other, so a file may start at 0 or 1.
+func parseBOMLevel(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, errors.New("level is required")
+	}
+
+	if strings.HasPrefix(value, ".") {
+		digits := strings.TrimLeft(value, ".")
+		if digits == "" {
+			return len(value), nil
+		}
+		value = digits
+	}
+
+	if strings.Contains(value, ".") {
+		segments := strings.Split(value, ".")
+		for _, segment := range segments {
+			if _, err := strconv.ParseUint(segment, 10, 32); err != nil {
+				return 0, fmt.Errorf("invalid level %q", value)
+			}
+		}
+		return len(segments), nil
+	}
+
+	level, err := strconv.Atoi(value)
+	if err != nil || level < 0 {
+		return 0, fmt.Errorf("invalid level %q", value)
+	}
+	return level, nil
+}
+
+// parseBOMQuantity reads a line quantity, defaulting to 1 when blank
+func parseBOMQuantity(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 1, nil
+	}
+	quantity, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q", value)
+	}
+	if quantity <= 0 {
+		return 0, ErrInvalidQuantity
+	}
+	return quantity, nil
+}
+
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func writeBOMCSV(w io.Writer, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(bomExportHeaders); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func writeBOMXLSX(w io.Writer, rows [][]string) error {
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+
+	sheet := workbook.GetSheetName(0)
+	stream, err := workbook.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	for i, values := range append([][]string{bomExportHeaders}, rows...) {
+		cells := make([]interface{}, len(values))
+		for j, v := range values {
+			cells[j] = v
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := stream.SetRow(cell, cells); err != nil {
+			return err
+		}
+	}
+
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	_, err = workbook.WriteTo(w)
+	return err
+}
@@ -0,0 +1,472 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/b2b-platform/equipment-service/models"
+	"github.com/google/uuid"
+)
+
+// Match status of an imported BOM line
+const (
+	LineMatched   = "matched"
+	LineUnmatched = "unmatched"
+	LineAmbiguous = "ambiguous"
+	LineError     = "error"
+)
+
+// maxBOMImportLines bounds the size of one imported BOM
+const maxBOMImportLines = 5000
+
+// bomLookupBatchSize is how many part numbers are sent to the catalog at once
+const bomLookupBatchSize = 500
+
+var ErrPartNotInCatalog = errors.New("part is not an approved catalog part")
+
+// bomImportRow is a validated line waiting to become a BOM node
+type bomImportRow struct {
+	line         *models.BOMImportLine
+	node         models.BOMNode
+	manufacturer string
+	catalogID    *uuid.UUID
+}
+
+// ImportBOM reads an indented BOM file into the equipment's BOM. Each line
+// becomes a node under the nearest line above it with a lower level. Lines
+// are linked to catalog parts by their catalog part ID or part number; lines
+// with no single match are still imported, unlinked, for manual linking.
+// Nothing is written if any line has an error, or on a dry run.
+func (s *EquipmentService) ImportBOM(equipmentID uuid.UUID, fileName, format string, r io.Reader, replace, dryRun bool, authToken string) (*models.BOMImportResult, error) {
+	equipment, err := s.equipmentRepo.GetByID(equipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if format, err = detectBOMFormat(format, fileName); err != nil {
+		return nil, err
+	}
+	file, err := readBOMFile(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.BOMImportResult{
+		EquipmentID: equipment.ID,
+		FileName:    fileName,
+		Format:      format,
+		DryRun:      dryRun,
+		Replace:     replace,
+		Lines:       make([]models.BOMImportLine, 0, len(file.Rows)),
+	}
+
+	rows, err := readBOMRows(file, equipment)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.matchBOMRows(rows, authToken); err != nil {
+		return nil, err
+	}
+	checkImportedCycles(rows)
+
+	for _, row := range rows {
+		result.TotalLines++
+		switch row.line.Status {
+		case LineMatched:
+			result.Matched++
+		case LineUnmatched:
+			result.Unmatched++
+		case LineAmbiguous:
+			result.Ambiguous++
+		case LineError:
+			result.Errors++
+		}
+	}
+	if dryRun || result.Errors > 0 {
+		for _, row := range rows {
+			result.Lines = append(result.Lines, *row.line)
+		}
+		return result, nil
+	}
+
+	nodes := make([]models.BOMNode, 0, len(rows))
+	for _, row := range rows {
+		nodes = append(nodes, row.node)
+	}
+	if err := s.bomRepo.ImportTree(equipment.ID, nodes, replace); err != nil {
+		return nil, err
+	}
+	result.Created = len(nodes)
+
+	for _, row := range rows {
+		row.line.NodeID = &row.node.ID
+		result.Lines = append(result.Lines, *row.line)
+	}
+	return result, nil
+}
+
+// readBOMRows validates each line and places it in the tree. Levels are
+// relative to the first line, and a line may only go one level deeper than
+// the line before it.
+func readBOMRows(file *bomFile, equipment *models.Equipment) ([]*bomImportRow, error) {
+	var rows []*bomImportRow
+	var parents []*bomImportRow // Open assemblies, one per level
+	baseLevel := -1
+
+	for i, record := range file.Rows {
+		if isBlankRow(record) {
+			continue
+		}
+		if len(rows) >= maxBOMImportLines {
+			return nil, fmt.Errorf("%w: at most %d lines can be imported at once", ErrInvalidBOMFile, maxBOMImportLines)
+		}
+
+		line := &models.BOMImportLine{
+			Row:        i + 2,
+			PartNumber: file.value(record, BOMFieldPartNumber),
+			PartName:   file.value(record, BOMFieldPartName),
+		}
+
+		row := &bomImportRow{
+			line:         line,
+			manufacturer: file.value(record, BOMFieldManufacturer),
+			node: models.BOMNode{
+				ID:          uuid.New(),
+				TenantID:    equipment.TenantID,
+				EquipmentID: equipment.ID,
+				PartNumber:  line.PartNumber,
+				PartName:    line.PartName,
+				Description: file.value(record, BOMFieldDescription),
+				Unit:        file.value(record, BOMFieldUnit),
+				Position:    file.value(record, BOMFieldPosition),
+			},
+		}
+		rows = append(rows, row)
+
+		level, err := parseBOMLevel(file.value(record, BOMFieldLevel))
+		if err != nil {
+			setLineError(line, err.Error())
+			continue
+		}
+		if baseLevel < 0 {
+			baseLevel = level
+		}
+		depth := level - baseLevel
+		line.Level = depth
+		switch {
+		case depth < 0:
+			setLineError(line, "level is above the first line of the BOM")
+			continue
+		case depth > len(parents):
+			setLineError(line, fmt.Sprintf("level %d skips a level below the line above", level))
+			continue
+		case depth >= maxBOMDepth:
+			setLineError(line, fmt.Sprintf("BOM is deeper than %d levels", maxBOMDepth))
+			continue
+		}
+
+		parents = parents[:depth]
+		if depth > 0 {
+			parent := parents[depth-1]
+			row.node.ParentNodeID = &parent.node.ID
+			if parent.line.Status == LineError {
+				setLineError(line, fmt.Sprintf("assembly on row %d has errors", parent.line.Row))
+			}
+		}
+		row.node.Level = depth
+		parents = append(parents, row)
+
+		if line.PartNumber == "" && line.PartName == "" {
+			setLineError(line, "part number or part name is required")
+			continue
+		}
+		if line.Quantity, err = parseBOMQuantity(file.value(record, BOMFieldQuantity)); err != nil {
+			setLineError(line, err.Error())
+			continue
+		}
+		row.node.Quantity = line.Quantity
+
+		if raw := file.value(record, BOMFieldCatalogPartID); raw != "" {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				setLineError(line, fmt.Sprintf("invalid catalog part ID %q", raw))
+				continue
+			}
+			row.catalogID = &id
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: file has no BOM lines", ErrInvalidBOMFile)
+	}
+	return rows, nil
+}
+
+// matchBOMRows links lines to catalog parts. A catalog part ID in the file
+// wins if the catalog still knows the part; otherwise the part number must
+// match exactly one approved part, narrowed by manufacturer when given.
+func (s *EquipmentService) matchBOMRows(rows []*bomImportRow, authToken string) error {
+	var partIDs []uuid.UUID
+	var partNumbers []string
+	seenNumbers := make(map[string]bool)
+	for _, row := range rows {
+		if row.line.Status == LineError {
+			continue
+		}
+		if row.catalogID != nil {
+			partIDs = append(partIDs, *row.catalogID)
+		}
+		if number := row.node.PartNumber; number != "" && !seenNumbers[number] {
+			seenNumbers[number] = true
+			partNumbers = append(partNumbers, number)
+		}
+	}
+
+	known := map[uuid.UUID]models.PartInterchange{}
+	if len(partIDs) > 0 {
+		var err error
+		if known, err = s.catalogClient.GetInterchanges(authToken, partIDs); err != nil {
+			return err
+		}
+	}
+	candidates := make(map[string][]models.CatalogPart)
+	for start := 0; start < len(partNumbers); start += bomLookupBatchSize {
+		end := start + bomLookupBatchSize
+		if end > len(partNumbers) {
+			end = len(partNumbers)
+		}
+		found, err := s.catalogClient.LookupPartNumbers(authToken, partNumbers[start:end])
+		if err != nil {
+			return err
+		}
+		for number, parts := range found {
+			candidates[number] = parts
+		}
+	}
+
+	for _, row := range rows {
+		line := row.line
+		if line.Status == LineError {
+			continue
+		}
+
+		if row.catalogID != nil {
+			if _, ok := known[*row.catalogID]; ok {
+				linkImportedRow(row, *row.catalogID, "")
+				continue
+			}
+		}
+
+		matches := filterByManufacturer(candidates[row.node.PartNumber], row.manufacturer)
+		switch {
+		case len(matches) == 1:
+			linkImportedRow(row, matches[0].ID, matches[0].Name)
+		case len(matches) > 1:
+			line.Status = LineAmbiguous
+			line.Message = fmt.Sprintf("%d catalog parts have this part number", len(matches))
+			line.Candidates = matches
+		default:
+			line.Status = LineUnmatched
+			line.Message = "no catalog part has this part number"
+		}
+
+		if line.Status != LineMatched && row.node.PartName == "" {
+			row.node.PartName = row.node.PartNumber
+		}
+	}
+	return nil
+}
+
+func linkImportedRow(row *bomImportRow, partID uuid.UUID, catalogName string) {
+	row.node.PartID = &partID
+	if row.node.PartName == "" {
+		row.node.PartName = catalogName
+	}
+	if row.node.PartName == "" {
+		row.node.PartName = row.node.PartNumber
+	}
+	row.line.PartID = &partID
+	row.line.Status = LineMatched
+}
+
+// filterByManufacturer keeps the candidates made by the given manufacturer.
+// Without a manufacturer, or if none match it, all candidates are kept.
+func filterByManufacturer(candidates []models.CatalogPart, manufacturer string) []models.CatalogPart {
+	if manufacturer == "" || len(candidates) < 2 {
+		return candidates
+	}
+	var matches []models.CatalogPart
+	for _, candidate := range candidates {
+		if strings.EqualFold(strings.TrimSpace(candidate.Manufacturer), manufacturer) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return candidates
+	}
+	return matches
+}
+
+// checkImportedCycles flags lines that sit under an assembly of the same part
+func checkImportedCycles(rows []*bomImportRow) {
+	byID := make(map[uuid.UUID]*bomImportRow, len(rows))
+	for _, row := range rows {
+		byID[row.node.ID] = row
+	}
+
+	for _, row := range rows {
+		if row.line.Status == LineError {
+			continue
+		}
+		for parentID := row.node.ParentNodeID; parentID != nil; {
+			parent := byID[*parentID]
+			if samePart(&parent.node, &row.node) {
+				setLineError(row.line, fmt.Sprintf("%v: assembly on row %d is the same part", ErrBOMCycle, parent.line.Row))
+				break
+			}
+			parentID = parent.node.ParentNodeID
+		}
+	}
+}
+
+func setLineError(line *models.BOMImportLine, message string) {
+	line.Status = LineError
+	line.Message = message
+}
+
+// ExportBOM writes the equipment's BOM as an indented file the import reads
+// back, with levels starting at 1 and each assembly followed by its parts
+func (s *EquipmentService) ExportBOM(equipmentID uuid.UUID, format string, w io.Writer) error {
+	nodes, err := s.bomRepo.ListByEquipment(equipmentID)
+	if err != nil {
+		return err
+	}
+
+	children := make(map[uuid.UUID][]models.BOMNode)
+	var roots []models.BOMNode
+	for _, node := range nodes {
+		if node.ParentNodeID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		children[*node.ParentNodeID] = append(children[*node.ParentNodeID], node)
+	}
+
+	var rows [][]string
+	var walk func(nodes []models.BOMNode, level int) error
+	walk = func(nodes []models.BOMNode, level int) error {
+		if level > maxBOMDepth {
+			return fmt.Errorf("%w: BOM is deeper than %d levels", ErrBOMCycle, maxBOMDepth)
+		}
+		for _, node := range nodes {
+			partID := ""
+			if node.PartID != nil {
+				partID = node.PartID.String()
+			}
+			rows = append(rows, []string{
+				strconv.Itoa(level),
+				node.PartNumber,
+				node.PartName,
+				node.Description,
+				strconv.FormatFloat(node.Quantity, 'f', -1, 64),
+				node.Unit,
+				node.Position,
+				partID,
+			})
+			if err := walk(children[node.ID], level+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(roots, 1); err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatCSV:
+		return writeBOMCSV(w, rows)
+	case FormatXLSX:
+		return writeBOMXLSX(w, rows)
+	default:
+		return fmt.Errorf("%w: unsupported format %q, use csv or xlsx", ErrInvalidBOMFile, format)
+	}
+}
+
+// ListUnlinkedBOMNodes returns the BOM lines still waiting to be linked to a catalog part
+func (s *EquipmentService) ListUnlinkedBOMNodes(equipmentID uuid.UUID) ([]models.BOMNode, error) {
+	return s.bomRepo.ListUnlinked(equipmentID)
+}
+
+// LinkBOMNodePart links a BOM node to a catalog part, typically an imported
+// line that could not be matched by part number
+func (s *EquipmentService) LinkBOMNodePart(equipmentID, nodeID, partID uuid.UUID, authToken string) (*models.BOMNode, error) {
+	node, err := s.bomRepo.GetByID(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if node.EquipmentID != equipmentID {
+		return nil, ErrUnknownBOMNode
+	}
+
+	known, err := s.catalogClient.GetInterchanges(authToken, []uuid.UUID{partID})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := known[partID]; !ok {
+		return nil, ErrPartNotInCatalog
+	}
+
+	linked := *node
+	linked.PartID = &partID
+	if node.ParentNodeID != nil {
+		if err := s.checkBOMCycle(node.ParentNode, &linked); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkBOMDescendants(&linked); err != nil {
+		return nil, err
+	}
+
+	node.PartID = &partID
+	node.ParentNode = nil
+	node.ChildNodes = nil
+	if err := s.bomRepo.Update(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// checkBOMDescendants makes sure no part below the node is the node's own part
+func (s *EquipmentService) checkBOMDescendants(node *models.BOMNode) error {
+	nodes, err := s.bomRepo.ListByEquipment(node.EquipmentID)
+	if err != nil {
+		return err
+	}
+	children := make(map[uuid.UUID][]*models.BOMNode)
+	for i := range nodes {
+		if nodes[i].ParentNodeID != nil {
+			children[*nodes[i].ParentNodeID] = append(children[*nodes[i].ParentNodeID], &nodes[i])
+		}
+	}
+
+	var walk func(id uuid.UUID, depth int) error
+	walk = func(id uuid.UUID, depth int) error {
+		if depth >= maxBOMDepth {
+			return fmt.Errorf("%w: BOM is deeper than %d levels", ErrBOMCycle, maxBOMDepth)
+		}
+		for _, child := range children[id] {
+			if samePart(child, node) {
+				return fmt.Errorf("%w: %s is already used below this position", ErrBOMCycle, child.PartName)
+			}
+			if err := walk(child.ID, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(node.ID, 0)
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/equipment-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BOM revision statuses
+const (
+	RevisionDraft    = "draft"
+	RevisionReleased = "released"
+)
+
+var (
+	ErrRevisionReleased    = errors.New("BOM revision is released and cannot be changed")
+	ErrRevisionNotReleased = errors.New("BOM revision is not released")
+	ErrUnknownRevision     = errors.New("BOM revision belongs to other equipment")
+	ErrNoEffectiveRevision = errors.New("no released BOM revision is in effect")
+)
+
+// CreateBOMRevision takes a draft revision from the equipment's working BOM
+func (s *EquipmentService) CreateBOMRevision(equipmentID uuid.UUID, description string, createdBy uuid.UUID) (*models.BOMRevision, error) {
+	equipment, err := s.equipmentRepo.GetByID(equipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := s.workingBOMLines(equipment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &models.BOMRevision{
+		TenantID:    equipment.TenantID,
+		EquipmentID: equipment.ID,
+		Status:      RevisionDraft,
+		Description: description,
+		CreatedBy:   createdBy,
+	}
+	if err := s.bomRevisionRepo.Create(revision, lines); err != nil {
+		return nil, err
+	}
+	revision.Lines = lines
+	return revision, nil
+}
+
+// workingBOMLines copies the working BOM into revision lines, each assembly
+// followed by its parts
+func (s *EquipmentService) workingBOMLines(equipmentID uuid.UUID) ([]models.BOMRevisionLine, error) {
+	nodes, err := s.bomRepo.ListByEquipment(equipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uuid.UUID][]models.BOMNode)
+	var roots []models.BOMNode
+	for _, node := range nodes {
+		if node.ParentNodeID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		children[*node.ParentNodeID] = append(children[*node.ParentNodeID], node)
+	}
+
+	lines := make([]models.BOMRevisionLine, 0, len(nodes))
+	var walk func(nodes []models.BOMNode, level int) error
+	walk = func(nodes []models.BOMNode, level int) error {
+		if level >= maxBOMDepth {
+			return fmt.Errorf("%w: BOM is deeper than %d levels", ErrBOMCycle, maxBOMDepth)
+		}
+		for _, node := range nodes {
+			lines = append(lines, models.BOMRevisionLine{
+				NodeID:       node.ID,
+				ParentNodeID: node.ParentNodeID,
+				PartID:       node.PartID,
+				PartNumber:   node.PartNumber,
+				PartName:     node.PartName,
+				Description:  node.Description,
+				Quantity:     node.Quantity,
+				Unit:         node.Unit,
+				Position:     node.Position,
+				Level:        level,
+				LineOrder:    len(lines),
+			})
+			if err := walk(children[node.ID], level+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(roots, 0); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// revisionNodes turns a revision's lines back into BOM nodes with the IDs
+// of the nodes they were copied from
+func revisionNodes(revision *models.BOMRevision) []models.BOMNode {
+	nodes := make([]models.BOMNode, 0, len(revision.Lines))
+	for _, line := range revision.Lines {
+		nodes = append(nodes, models.BOMNode{
+			ID:           line.NodeID,
+			TenantID:     revision.TenantID,
+			EquipmentID:  revision.EquipmentID,
+			ParentNodeID: line.ParentNodeID,
+			PartID:       line.PartID,
+			PartNumber:   line.PartNumber,
+			PartName:     line.PartName,
+			Description:  line.Description,
+			Quantity:     line.Quantity,
+			Unit:         line.Unit,
+			Position:     line.Position,
+			Level:        line.Level,
+		})
+	}
+	return nodes
+}
+
+// GetBOMRevision returns a revision of the equipment's BOM with its lines
+func (s *EquipmentService) GetBOMRevision(equipmentID, revisionID uuid.UUID) (*models.BOMRevision, error) {
+	revision, err := s.bomRevisionRepo.GetByID(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.EquipmentID != equipmentID {
+		return nil, ErrUnknownRevision
+	}
+	return revision, nil
+}
+
+func (s *EquipmentService) ListBOMRevisions(equipmentID uuid.UUID) ([]models.BOMRevision, error) {
+	return s.bomRevisionRepo.ListByEquipment(equipmentID)
+}
+
+// GetEffectiveBOMRevision returns the released revision in effect on a date
+func (s *EquipmentService) GetEffectiveBOMRevision(equipmentID uuid.UUID, at time.Time) (*models.BOMRevision, error) {
+	revision, err := s.bomRevisionRepo.GetEffective(equipmentID, at)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoEffectiveRevision
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.bomRevisionRepo.GetByID(revision.ID)
+}
+
+// RefreshBOMRevision replaces a draft's lines with the current working BOM
+func (s *EquipmentService) RefreshBOMRevision(equipmentID, revisionID uuid.UUID) (*models.BOMRevision, error) {
+	revision, err := s.GetBOMRevision(equipmentID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Status != RevisionDraft {
+		return nil, ErrRevisionReleased
+	}
+
+	lines, err := s.workingBOMLines(equipmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.bomRevisionRepo.ReplaceLines(revision, lines); err != nil {
+		return nil, err
+	}
+	revision.Lines = lines
+	return revision, nil
+}
+
+// ReleaseBOMRevision freezes a draft revision. It takes effect on the given
+// date, or today, and from then on is the effective BOM until a later
+// released revision takes effect.
+func (s *EquipmentService) ReleaseBOMRevision(equipmentID, revisionID uuid.UUID, effectiveDate *time.Time, releasedBy uuid.UUID) (*models.BOMRevision, error) {
+	revision, err := s.GetBOMRevision(equipmentID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Status != RevisionDraft {
+		return nil, ErrRevisionReleased
+	}
+
+	now := time.Now()
+	if effectiveDate == nil {
+		effectiveDate = &now
+	}
+	effective := time.Date(effectiveDate.Year(), effectiveDate.Month(), effectiveDate.Day(), 0, 0, 0, 0, time.UTC)
+
+	revision.Status = RevisionReleased
+	revision.EffectiveDate = &effective
+	revision.ReleasedBy = &releasedBy
+	revision.ReleasedAt = &now
+	if err := s.bomRevisionRepo.Update(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// DeleteBOMRevision discards a draft revision
+func (s *EquipmentService) DeleteBOMRevision(equipmentID, revisionID uuid.UUID) error {
+	revision, err := s.GetBOMRevision(equipmentID, revisionID)
+	if err != nil {
+		return err
+	}
+	if revision.Status != RevisionDraft {
+		return ErrRevisionReleased
+	}
+	return s.bomRevisionRepo.Delete(revision.ID)
+}
+
+// explodeReleasedRevision explodes a released revision of the equipment's BOM
+func (s *EquipmentService) explodeReleasedRevision(equipmentID, revisionID uuid.UUID, quantity float64) (*models.BOMExplosion, error) {
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity < 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	revision, err := s.GetBOMRevision(equipmentID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Status != RevisionReleased {
+		return nil, ErrRevisionNotReleased
+	}
+	return newBOMExplosion(equipmentID, revisionNodes(revision), quantity)
+}
+
+// DiffBOMRevisions compares two revisions of the equipment's BOM, or a
+// revision with the working BOM when toID is nil
+func (s *EquipmentService) DiffBOMRevisions(equipmentID, fromID uuid.UUID, toID *uuid.UUID) (*models.BOMDiff, error) {
+	from, err := s.GetBOMRevision(equipmentID, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.BOMDiff{EquipmentID: equipmentID}
+	var toNodes []models.BOMNode
+	if toID != nil {
+		to, err := s.GetBOMRevision(equipmentID, *toID)
+		if err != nil {
+			return nil, err
+		}
+		toNodes = revisionNodes(to)
+		diff.ToRevision = to
+	} else if toNodes, err = s.bomRepo.ListByEquipment(equipmentID); err != nil {
+		return nil, err
+	}
+
+	fromExplosion, err := explodeBOM(revisionNodes(from), 1)
+	if err != nil {
+		return nil, err
+	}
+	toExplosion, err := explodeBOM(toNodes, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	diff.Lines = diffBOMs(fromExplosion, toExplosion)
+	for _, line := range diff.Lines {
+		switch line.Change {
+		case models.BOMChangeAdded:
+			diff.Added++
+		case models.BOMChangeRemoved:
+			diff.Removed++
+		case models.BOMChangeQuantityChanged:
+			diff.QuantityChanged++
+		}
+	}
+
+	// The lines are in the diff; only the revision headers are returned
+	from.Lines = nil
+	diff.FromRevision = from
+	if diff.ToRevision != nil {
+		diff.ToRevision.Lines = nil
+	}
+	return diff, nil
+}
+
+// bomDiffEntry is a BOM line keyed by where it sits in the tree
+type bomDiffEntry struct {
+	key  string
+	path []string
+	node models.ExplodedBOMNode
+}
+
+// flattenForDiff lists a BOM's lines with keys that identify the same line
+// across revisions: the part and position of the line and of each assembly
+// above it. Node IDs are not used, as re-imported BOMs get new ones.
+// Repeated lines under the same assembly are told apart by occurrence.
+func flattenForDiff(roots []models.ExplodedBOMNode) []bomDiffEntry {
+	var entries []bomDiffEntry
+	var walk func(nodes []models.ExplodedBOMNode, parentKey string, parentPath []string)
+	walk = func(nodes []models.ExplodedBOMNode, parentKey string, parentPath []string) {
+		seen := make(map[string]int)
+		for _, node := range nodes {
+			lineKey := diffPartKey(node) + "@" + strings.ToUpper(node.Position)
+			seen[lineKey]++
+			key := parentKey + "/" + lineKey + "#" + strconv.Itoa(seen[lineKey])
+
+			path := append(append([]string{}, parentPath...), node.PartName)
+			entries = append(entries, bomDiffEntry{key: key, path: path, node: node})
+			walk(node.Children, key, path)
+		}
+	}
+	walk(roots, "", nil)
+	return entries
+}
+
+// diffPartKey identifies a line's part by catalog ID, part number or name
+func diffPartKey(node models.ExplodedBOMNode) string {
+	if node.PartID != nil {
+		return node.PartID.String()
+	}
+	if node.PartNumber != "" {
+		return "number:" + strings.ToUpper(node.PartNumber)
+	}
+	return "name:" + strings.ToUpper(node.PartName)
+}
+
+// diffBOMs lists lines added to and changed in the new BOM in its order,
+// then lines removed from the old BOM in theirs
+func diffBOMs(from, to []models.ExplodedBOMNode) []models.BOMDiffLine {
+	fromEntries := flattenForDiff(from)
+	toEntries := flattenForDiff(to)
+
+	fromByKey := make(map[string]bomDiffEntry, len(fromEntries))
+	for _, entry := range fromEntries {
+		fromByKey[entry.key] = entry
+	}
+	toKeys := make(map[string]bool, len(toEntries))
+
+	lines := []models.BOMDiffLine{}
+	for _, entry := range toEntries {
+		toKeys[entry.key] = true
+		old, existed := fromByKey[entry.key]
+		switch {
+		case !existed:
+			line := diffLine(models.BOMChangeAdded, entry)
+			line.ToQuantity = entry.node.Quantity
+			lines = append(lines, line)
+		case old.node.Quantity != entry.node.Quantity:
+			line := diffLine(models.BOMChangeQuantityChanged, entry)
+			line.FromQuantity = old.node.Quantity
+			line.ToQuantity = entry.node.Quantity
+			lines = append(lines, line)
+		}
+	}
+	for _, entry := range fromEntries {
+		if !toKeys[entry.key] {
+			line := diffLine(models.BOMChangeRemoved, entry)
+			line.FromQuantity = entry.node.Quantity
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func diffLine(change string, entry bomDiffEntry) models.BOMDiffLine {
+	return models.BOMDiffLine{
+		Change:     change,
+		Path:       entry.path,
+		Level:      entry.node.Level,
+		PartID:     entry.node.PartID,
+		PartNumber: entry.node.PartNumber,
+		PartName:   entry.node.PartName,
+		Position:   entry.node.Position,
+		Unit:       entry.node.Unit,
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/equipment-service/models"
+	"github.com/google/uuid"
+)
+
+// CatalogClient reads part cross references from catalog-service
+type CatalogClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewCatalogClient() *CatalogClient {
+	baseURL := os.Getenv("CATALOG_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8003"
+	}
+
+	return &CatalogClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// GetInterchanges resolves the current and alternative parts for each part.
+// Parts the catalog does not know are missing from the result.
+func (c *CatalogClient) GetInterchanges(authToken string, partIDs []uuid.UUID) (map[uuid.UUID]models.PartInterchange, error) {
+	url := fmt.Sprintf("%s/api/v1/parts/interchange", c.baseURL)
+
+	body, err := json.Marshal(map[string]interface{}{"part_ids": partIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if !strings.HasPrefix(authToken, "Bearer ") {
+		authToken = "Bearer " + authToken
+	}
+	httpReq.Header.Set("Authorization", authToken)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call catalog service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("catalog service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var interchanges map[uuid.UUID]models.PartInterchange
+	if err := json.NewDecoder(resp.Body).Decode(&interchanges); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return interchanges, nil
+}
+
+// catalogLookupPart is the part of catalog-service's part JSON used for matching
+type catalogLookupPart struct {
+	ID           uuid.UUID `json:"id"`
+	PartNumber   string    `json:"part_number"`
+	Name         string    `json:"name"`
+	Status       string    `json:"status"`
+	Manufacturer struct {
+		Name string `json:"name"`
+	} `json:"manufacturer"`
+}
+
+// LookupPartNumbers finds approved catalog parts for part numbers as written
+// in a BOM, keyed by the given number. Numbers without a match are missing.
+func (c *CatalogClient) LookupPartNumbers(authToken string, partNumbers []string) (map[string][]models.CatalogPart, error) {
+	url := fmt.Sprintf("%s/api/v1/parts/lookup", c.baseURL)
+
+	body, err := json.Marshal(map[string]interface{}{"part_numbers": partNumbers})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if !strings.HasPrefix(authToken, "Bearer ") {
+		authToken = "Bearer " + authToken
+	}
+	httpReq.Header.Set("Authorization", authToken)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call catalog service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("catalog service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var found map[string][]catalogLookupPart
+	if err := json.NewDecoder(resp.Body).Decode(&found); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	matches := make(map[string][]models.CatalogPart, len(found))
+	for number, parts := range found {
+		for _, part := range parts {
+			matches[number] = append(matches[number], models.CatalogPart{
+				ID:           part.ID,
+				PartNumber:   part.PartNumber,
+				Name:         part.Name,
+				Status:       part.Status,
+				Manufacturer: part.Manufacturer.Name,
+			})
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/b2b-platform/equipment-service/models"
+	"github.com/b2b-platform/shared/events"
+	"github.com/google/uuid"
+)
+
+const eventSource = "equipment-service"
+
+// Snapshot page sizes bound how much one export request reads
+const (
+	DefaultSnapshotPageSize = 500
+	MaxSnapshotPageSize     = 1000
+)
+
+// SearchSnapshot exports equipment, optionally one tenant's, as the events
+// announcing its current state so search can rebuild its index. Pages
+// follow ID order from the after cursor.
+func (s *EquipmentService) SearchSnapshot(after uuid.UUID, tenantID *uuid.UUID, limit int) (*events.SnapshotPage, error) {
+	if limit <= 0 {
+		limit = DefaultSnapshotPageSize
+	}
+	if limit > MaxSnapshotPageSize {
+		limit = MaxSnapshotPageSize
+	}
+
+	equipment, err := s.equipmentRepo.ListAfter(after, tenantID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &events.SnapshotPage{Events: make([]*events.EventEnvelope, 0, len(equipment))}
+	for i := range equipment {
+		page.Events = append(page.Events, equipmentEvent(events.EventEquipmentUpdated, &equipment[i]))
+	}
+	if len(equipment) == limit {
+		page.NextAfter = equipment[len(equipment)-1].ID.String()
+	}
+	return page, nil
+}
+
+// publishEquipmentChanged announces a created or updated equipment with its
+// full search document. Failures are logged; the change is already saved.
+func (s *EquipmentService) publishEquipmentChanged(eventType events.EventType, equipment *models.Equipment) {
+	s.publish(equipmentEvent(eventType, equipment))
+}
+
+func equipmentEvent(eventType events.EventType, equipment *models.Equipment) *events.EventEnvelope {
+	return events.NewEventEnvelope(eventType, eventSource, map[string]interface{}{
+		"equipment_id":     equipment.ID.String(),
+		"tenant_id":        equipment.TenantID.String(),
+		"equipment_number": equipment.EquipmentNumber,
+		"name":             equipment.Name,
+		"category":         equipment.Type,
+		"manufacturer":     equipment.Manufacturer,
+		"model":            equipment.Model,
+		"serial_number":    equipment.SerialNumber,
+		"year":             equipment.Year,
+		"status":           equipment.Status,
+		"location":         equipment.Location,
+		"description":      equipment.Notes,
+		// Equipment is a tenant's own asset, not a public catalog entry
+		"visibility": "private",
+	}).WithTenantID(equipment.TenantID).WithVersion(events.VersionAt(equipment.UpdatedAt))
+}
+
+func (s *EquipmentService) publishEquipmentDeleted(equipment *models.Equipment) {
+	event := events.NewEventEnvelope(events.EventEquipmentDeleted, eventSource, map[string]interface{}{
+		"equipment_id": equipment.ID.String(),
+	}).WithTenantID(equipment.TenantID).WithVersion(events.VersionAt(time.Now()))
+
+	s.publish(event)
+}
+
+func (s *EquipmentService) publish(event *events.EventEnvelope) {
+	if s.eventBus == nil {
+		return
+	}
+	if err := s.eventBus.Publish(nil, event); err != nil {
+		log.Printf("Failed to publish %s: %v", event.Type, err)
+	}
+}
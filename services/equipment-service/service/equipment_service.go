@@ -1,33 +1,60 @@
 package service
 
 import (
+	"log"
 	"time"
 
 	"github.com/b2b-platform/equipment-service/models"
 	"github.com/b2b-platform/equipment-service/repository"
+	"github.com/b2b-platform/shared/events"
 	"github.com/google/uuid"
 )
 
 type EquipmentService struct {
 	equipmentRepo      *repository.EquipmentRepository
 	bomRepo            *repository.BOMRepository
+	bomRevisionRepo    *repository.BOMRevisionRepository
 	compatibilityRepo  *repository.CompatibilityRepository
+	maintenanceRepo    *repository.MaintenanceRepository
+	catalogClient      *CatalogClient
+	marketplaceClient  *MarketplaceClient
+	procurementClient  *ProcurementClient
+	warehouseClient    *WarehouseClient
+	eventBus           events.EventBus
 }
 
 func NewEquipmentService(
 	equipmentRepo *repository.EquipmentRepository,
 	bomRepo *repository.BOMRepository,
+	bomRevisionRepo *repository.BOMRevisionRepository,
 	compatibilityRepo *repository.CompatibilityRepository,
+	maintenanceRepo *repository.MaintenanceRepository,
+	catalogClient *CatalogClient,
+	marketplaceClient *MarketplaceClient,
+	procurementClient *ProcurementClient,
+	warehouseClient *WarehouseClient,
+	eventBus events.EventBus,
 ) *EquipmentService {
 	return &EquipmentService{
 		equipmentRepo:     equipmentRepo,
 		bomRepo:           bomRepo,
+		bomRevisionRepo:   bomRevisionRepo,
 		compatibilityRepo:  compatibilityRepo,
+		maintenanceRepo:    maintenanceRepo,
+		catalogClient:      catalogClient,
+		marketplaceClient:  marketplaceClient,
+		procurementClient:  procurementClient,
+		warehouseClient:    warehouseClient,
+		eventBus:           eventBus,
 	}
 }
 
 func (s *EquipmentService) Create(equipment *models.Equipment) error {
-	return s.equipmentRepo.Create(equipment)
+	if err := s.equipmentRepo.Create(equipment); err != nil {
+		return err
+	}
+	s.publishEquipmentChanged(events.EventEquipmentCreated, equipment)
+	return nil
 }
 
 func (s *EquipmentService) GetByID(id uuid.UUID) (*models.Equipment, error) {
@@ -39,11 +66,23 @@ func (s *EquipmentService) List(tenantID uuid.UUID, limit, offset int) ([]models
 }
 
 func (s *EquipmentService) Update(equipment *models.Equipment) error {
-	return s.equipmentRepo.Update(equipment)
+	if err := s.equipmentRepo.Update(equipment); err != nil {
+		return err
+	}
+	s.publishEquipmentChanged(events.EventEquipmentUpdated, equipment)
+	return nil
 }
 
-func (s *EquipmentService) AddBOMNode(node *models.BOMNode) error {
-	return s.bomRepo.Create(node)
+func (s *EquipmentService) Delete(id uuid.UUID) error {
+	equipment, err := s.equipmentRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.equipmentRepo.Delete(equipment.ID); err != nil {
+		return err
+	}
+	s.publishEquipmentDeleted(equipment)
+	return nil
 }
 
 func (s *EquipmentService) GetBOM(equipmentID uuid.UUID) ([]models.BOMNode, error) {
@@ -75,3 +114,123 @@ func (s *EquipmentService) CheckCompatibility(equipmentID, partID uuid.UUID) (*m
 func (s *EquipmentService) GetCompatibilityMappings(equipmentID uuid.UUID) ([]models.CompatibilityMapping, error) {
 	return s.compatibilityRepo.GetByEquipment(equipmentID)
 }
+
+// ReplacePartReferences moves BOM nodes and compatibility mappings from a
+// catalog part that was merged away onto the part it was merged into
+func (s *EquipmentService) ReplacePartReferences(oldPartID, newPartID uuid.UUID) (int64, error) {
+	nodes, err := s.bomRepo.ReplacePart(oldPartID, newPartID)
+	if err != nil {
+		return 0, err
+	}
+
+	mappings, err := s.compatibilityRepo.ReplacePart(oldPartID, newPartID)
+	if err != nil {
+		return nodes, err
+	}
+	return nodes + mappings, nil
+}
+
+// AttachInterchange annotates BOM nodes and their children with the current
+// and alternative catalog parts. A BOM is still useful without them, so
+// catalog-service failures are logged rather than returned.
+func (s *EquipmentService) AttachInterchange(nodes []models.BOMNode, authToken string) {
+	var partIDs []uuid.UUID
+	collectBOMPartIDs(nodes, &partIDs)
+	if len(partIDs) == 0 {
+		return
+	}
+
+	interchanges, err := s.catalogClient.GetInterchanges(authToken, partIDs)
+	if err != nil {
+		log.Printf("Failed to resolve BOM part interchange: %v", err)
+		return
+	}
+	applyInterchange(nodes, interchanges)
+}
+
+func collectBOMPartIDs(nodes []models.BOMNode, partIDs *[]uuid.UUID) {
+	for _, node := range nodes {
+		if node.PartID != nil {
+			*partIDs = append(*partIDs, *node.PartID)
+		}
+		collectBOMPartIDs(node.ChildNodes, partIDs)
+	}
+}
+
+func applyInterchange(nodes []models.BOMNode, interchanges map[uuid.UUID]models.PartInterchange) {
+	for i := range nodes {
+		if nodes[i].PartID != nil {
+			if interchange, ok := interchanges[*nodes[i].PartID]; ok {
+				nodes[i].Interchange = &interchange
+			}
+		}
+		applyInterchange(nodes[i].ChildNodes, interchanges)
+	}
+}
+
+// CheckPartCompatibility checks a part against the equipment's mappings. A
+// part without its own mapping still fits when it supersedes, is equivalent
+// to, or is a conditional alternative for a compatible part. The check also
+// lists the part's current and alternative parts that are known to fit.
+func (s *EquipmentService) CheckPartCompatibility(equipmentID, partID uuid.UUID, authToken string) (*models.CompatibilityCheck, error) {
+	mappings, err := s.compatibilityRepo.GetByEquipment(equipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	byPart := make(map[uuid.UUID]*models.CompatibilityMapping, len(mappings))
+	for i := range mappings {
+		byPart[mappings[i].PartID] = &mappings[i]
+	}
+
+	check := &models.CompatibilityCheck{
+		EquipmentID:  equipmentID,
+		PartID:       partID,
+		Alternatives: []models.CompatibleAlternative{},
+	}
+	if mapping, ok := byPart[partID]; ok {
+		check.IsCompatible = mapping.IsCompatible
+		check.MatchedVia = "direct"
+		check.Mapping = mapping
+	}
+
+	interchanges, err := s.catalogClient.GetInterchanges(authToken, []uuid.UUID{partID})
+	if err != nil {
+		log.Printf("Failed to resolve interchange for part %s: %v", partID, err)
+		return check, nil
+	}
+	interchange, ok := interchanges[partID]
+	if !ok {
+		return check, nil
+	}
+
+	// An explicit mapping for the part itself, compatible or not, takes precedence
+	if check.Mapping == nil {
+		for _, option := range interchange.Replaces {
+			if mapping, ok := byPart[option.Part.ID]; ok && mapping.IsCompatible {
+				check.IsCompatible = true
+				check.MatchedVia = option.RelationType
+				check.Conditions = option.Conditions
+				check.Mapping = mapping
+				break
+			}
+		}
+	}
+
+	candidates := interchange.Alternatives
+	if interchange.Superseded {
+		current := interchange.CurrentPart
+		check.CurrentPart = &current
+		candidates = append([]models.InterchangeOption{{Part: current, RelationType: "supersedes"}}, candidates...)
+	}
+	for _, option := range candidates {
+		if mapping, ok := byPart[option.Part.ID]; ok && mapping.IsCompatible {
+			check.Alternatives = append(check.Alternatives, models.CompatibleAlternative{
+				InterchangeOption: option,
+				MappingID:         mapping.ID,
+			})
+		}
+	}
+
+	return check, nil
+}
@@ -0,0 +1,731 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/equipment-service/models"
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Maintenance plan triggers
+const (
+	TriggerCalendar = "calendar"
+	TriggerMeter    = "meter"
+)
+
+const (
+	DefaultForecastHorizonDays = 90
+	maxForecastHorizonDays     = 730
+	// Meter plans are projected from the usage rate over this window
+	meterRateWindow = 90 * 24 * time.Hour
+	// Guards against runaway projections from tiny intervals
+	maxServicesPerPlan = 500
+)
+
+var (
+	ErrInvalidMaintenancePlan = errors.New("invalid maintenance plan")
+	ErrInvalidMeterReading    = errors.New("invalid meter reading")
+	ErrInvalidHorizon         = fmt.Errorf("horizon must be between 1 and %d days", maxForecastHorizonDays)
+)
+
+// CreateMaintenancePlan adds a plan to an equipment. A meter plan with no
+// last performed reading starts counting from the equipment's latest reading.
+func (s *EquipmentService) CreateMaintenancePlan(equipmentID uuid.UUID, plan *models.MaintenancePlan) error {
+	equipment, err := s.equipmentRepo.GetByID(equipmentID)
+	if err != nil {
+		return err
+	}
+	if err := validateMaintenancePlan(plan); err != nil {
+		return err
+	}
+
+	plan.ID = uuid.Nil
+	plan.TenantID = equipment.TenantID
+	plan.EquipmentID = equipment.ID
+	plan.IsActive = true
+	for i := range plan.Parts {
+		plan.Parts[i].ID = uuid.Nil
+	}
+
+	if plan.TriggerType == TriggerMeter && plan.LastPerformedMeter == nil {
+		latest, err := s.maintenanceRepo.LatestMeterReading(equipment.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		start := 0.0
+		if err == nil {
+			start = latest.Reading
+		}
+		plan.LastPerformedMeter = &start
+	}
+
+	return s.maintenanceRepo.CreatePlan(plan)
+}
+
+func validateMaintenancePlan(plan *models.MaintenancePlan) error {
+	if strings.TrimSpace(plan.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidMaintenancePlan)
+	}
+	switch plan.TriggerType {
+	case TriggerCalendar:
+		if plan.IntervalDays == nil || *plan.IntervalDays <= 0 {
+			return fmt.Errorf("%w: calendar plans need interval_days greater than zero", ErrInvalidMaintenancePlan)
+		}
+		plan.IntervalHours = nil
+	case TriggerMeter:
+		if plan.IntervalHours == nil || *plan.IntervalHours <= 0 {
+			return fmt.Errorf("%w: meter plans need interval_hours greater than zero", ErrInvalidMaintenancePlan)
+		}
+		plan.IntervalDays = nil
+	default:
+		return fmt.Errorf("%w: trigger_type must be %s or %s", ErrInvalidMaintenancePlan, TriggerCalendar, TriggerMeter)
+	}
+	if plan.LeadTimeDays < 0 {
+		return fmt.Errorf("%w: lead_time_days cannot be negative", ErrInvalidMaintenancePlan)
+	}
+	for _, part := range plan.Parts {
+		if part.PartID == nil && strings.TrimSpace(part.PartNumber) == "" && strings.TrimSpace(part.PartName) == "" {
+			return fmt.Errorf("%w: each part needs a part_id, part_number or part_name", ErrInvalidMaintenancePlan)
+		}
+		if part.Quantity <= 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidQuantity, part.PartName)
+		}
+	}
+	for i := range plan.Parts {
+		if plan.Parts[i].PartName == "" {
+			plan.Parts[i].PartName = plan.Parts[i].PartNumber
+		}
+	}
+	return nil
+}
+
+func (s *EquipmentService) GetMaintenancePlan(id uuid.UUID) (*models.MaintenancePlan, error) {
+	return s.maintenanceRepo.GetPlan(id)
+}
+
+func (s *EquipmentService) ListMaintenancePlans(equipmentID uuid.UUID) ([]models.MaintenancePlan, error) {
+	return s.maintenanceRepo.ListPlansByEquipment(equipmentID)
+}
+
+// UpdateMaintenancePlan changes a plan's schedule, parts and drafting
+// settings. When it was last performed only changes through CompleteMaintenance.
+func (s *EquipmentService) UpdateMaintenancePlan(id uuid.UUID, changes *models.MaintenancePlan) (*models.MaintenancePlan, error) {
+	plan, err := s.maintenanceRepo.GetPlan(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMaintenancePlan(changes); err != nil {
+		return nil, err
+	}
+
+	plan.Name = changes.Name
+	plan.Description = changes.Description
+	plan.TriggerType = changes.TriggerType
+	plan.IntervalDays = changes.IntervalDays
+	plan.IntervalHours = changes.IntervalHours
+	plan.LeadTimeDays = changes.LeadTimeDays
+	plan.AutoDraftPR = changes.AutoDraftPR
+	plan.IsActive = changes.IsActive
+	plan.Parts = changes.Parts
+
+	if err := s.maintenanceRepo.UpdatePlan(plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (s *EquipmentService) DeleteMaintenancePlan(id uuid.UUID) error {
+	if _, err := s.maintenanceRepo.GetPlan(id); err != nil {
+		return err
+	}
+	return s.maintenanceRepo.DeletePlan(id)
+}
+
+// RecordMeterReading adds an hour meter reading. Meters only count up, so a
+// reading below the latest earlier one is rejected.
+func (s *EquipmentService) RecordMeterReading(equipmentID uuid.UUID, reading *models.MeterReading) error {
+	equipment, err := s.equipmentRepo.GetByID(equipmentID)
+	if err != nil {
+		return err
+	}
+	if reading.Reading < 0 {
+		return fmt.Errorf("%w: reading cannot be negative", ErrInvalidMeterReading)
+	}
+	if reading.ReadAt.IsZero() {
+		reading.ReadAt = time.Now()
+	}
+	if reading.ReadAt.After(time.Now().Add(time.Hour)) {
+		return fmt.Errorf("%w: read_at is in the future", ErrInvalidMeterReading)
+	}
+
+	latest, err := s.maintenanceRepo.LatestMeterReading(equipment.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && !reading.ReadAt.Before(latest.ReadAt) && reading.Reading < latest.Reading {
+		return fmt.Errorf("%w: %.2f is below the previous reading of %.2f", ErrInvalidMeterReading, reading.Reading, latest.Reading)
+	}
+
+	reading.ID = uuid.Nil
+	reading.TenantID = equipment.TenantID
+	reading.EquipmentID = equipment.ID
+	return s.maintenanceRepo.CreateMeterReading(reading)
+}
+
+// ListMeterReadings returns an equipment's readings over the last number of days
+func (s *EquipmentService) ListMeterReadings(equipmentID uuid.UUID, days int) ([]models.MeterReading, error) {
+	return s.maintenanceRepo.ListMeterReadings(equipmentID, time.Now().AddDate(0, 0, -days))
+}
+
+// CompleteMaintenance records a plan as performed, which restarts its
+// interval. Meter plans take the equipment's latest reading when none is given.
+func (s *EquipmentService) CompleteMaintenance(planID uuid.UUID, record *models.MaintenanceRecord) (*models.MaintenanceRecord, error) {
+	plan, err := s.maintenanceRepo.GetPlan(planID)
+	if err != nil {
+		return nil, err
+	}
+	if record.PerformedAt.IsZero() {
+		record.PerformedAt = time.Now()
+	}
+
+	if plan.TriggerType == TriggerMeter && record.MeterReading == nil {
+		latest, err := s.maintenanceRepo.LatestMeterReading(plan.EquipmentID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: meter_reading is required for meter plans", ErrInvalidMeterReading)
+		}
+		if err != nil {
+			return nil, err
+		}
+		record.MeterReading = &latest.Reading
+	}
+
+	record.ID = uuid.Nil
+	record.TenantID = plan.TenantID
+	record.PlanID = plan.ID
+	record.EquipmentID = plan.EquipmentID
+	if err := s.maintenanceRepo.CreateRecord(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (s *EquipmentService) ListMaintenanceRecords(planID uuid.UUID) ([]models.MaintenanceRecord, error) {
+	return s.maintenanceRepo.ListRecords(planID)
+}
+
+// ForecastMaintenance projects the tenant's maintenance services over the
+// horizon, optionally for one equipment, and the spare parts they need
+// against warehouse stock. Services whose order-by date has passed while
+// parts are short are suggested as purchase requests.
+func (s *EquipmentService) ForecastMaintenance(tenantID uuid.UUID, equipmentID *uuid.UUID, horizonDays int, authToken string) (*models.MaintenanceForecast, error) {
+	forecast, _, err := s.forecastMaintenance(tenantID, equipmentID, horizonDays, authToken)
+	return forecast, err
+}
+
+// RunMaintenanceForecast forecasts as ForecastMaintenance does, then drafts
+// a purchase request for each suggestion from a plan that drafts its own
+func (s *EquipmentService) RunMaintenanceForecast(tenantID uuid.UUID, horizonDays int, authToken string) (*models.MaintenanceForecast, error) {
+	return s.runMaintenanceForecast(tenantID, horizonDays, authToken, nil)
+}
+
+// runMaintenanceForecast drafts PRs with the token planToken returns for
+// each plan, or with authToken when it is nil
+func (s *EquipmentService) runMaintenanceForecast(tenantID uuid.UUID, horizonDays int, authToken string, planToken func(plan *models.MaintenancePlan) (string, error)) (*models.MaintenanceForecast, error) {
+	forecast, plans, err := s.forecastMaintenance(tenantID, nil, horizonDays, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range forecast.SuggestedPRs {
+		suggestion := &forecast.SuggestedPRs[i]
+		if !suggestion.AutoDraft || suggestion.Drafted {
+			continue
+		}
+		plan := plans[suggestion.PlanID]
+		token := authToken
+		if planToken != nil {
+			if token, err = planToken(plan); err != nil {
+				return nil, err
+			}
+		}
+		pr, err := s.draftMaintenancePR(plan, suggestion, token)
+		if err != nil {
+			log.Printf("Failed to draft PR for maintenance plan %s due %s: %v", suggestion.PlanID, suggestion.DueDate.Format("2006-01-02"), err)
+			continue
+		}
+		if pr == nil {
+			continue
+		}
+		log.Printf("Drafted PR %s for maintenance plan %s due %s", pr.PRNumber, plan.ID, suggestion.DueDate.Format("2006-01-02"))
+		suggestion.Drafted = true
+		suggestion.PRID = &pr.ID
+		suggestion.PRNumber = pr.PRNumber
+		for j := range forecast.Services {
+			service := &forecast.Services[j]
+			if service.PlanID == suggestion.PlanID && service.DueDate.Equal(suggestion.DueDate) {
+				service.Drafted = true
+				service.PRID = &pr.ID
+				service.PRNumber = pr.PRNumber
+			}
+		}
+	}
+	return forecast, nil
+}
+
+func (s *EquipmentService) forecastMaintenance(tenantID uuid.UUID, equipmentID *uuid.UUID, horizonDays int, authToken string) (*models.MaintenanceForecast, map[uuid.UUID]*models.MaintenancePlan, error) {
+	if horizonDays == 0 {
+		horizonDays = DefaultForecastHorizonDays
+	}
+	if horizonDays < 1 || horizonDays > maxForecastHorizonDays {
+		return nil, nil, ErrInvalidHorizon
+	}
+
+	plans, err := s.maintenanceRepo.ListActivePlans(tenantID, equipmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	end := now.AddDate(0, 0, horizonDays)
+	forecast := &models.MaintenanceForecast{
+		GeneratedAt:  now,
+		HorizonDays:  horizonDays,
+		EquipmentID:  equipmentID,
+		Services:     []models.ScheduledService{},
+		Demand:       []models.PartDemand{},
+		SuggestedPRs: []models.SuggestedPR{},
+		Unscheduled:  []models.UnscheduledPlan{},
+	}
+
+	byID := make(map[uuid.UUID]*models.MaintenancePlan, len(plans))
+	planIDs := make([]uuid.UUID, 0, len(plans))
+	cycleStart := now
+	usage := make(map[uuid.UUID]*meterUsage)
+	for i := range plans {
+		plan := &plans[i]
+		byID[plan.ID] = plan
+		planIDs = append(planIDs, plan.ID)
+		if start := planCycleStart(plan); start.Before(cycleStart) {
+			cycleStart = start
+		}
+
+		var due []time.Time
+		var estimated bool
+		var reason string
+		switch plan.TriggerType {
+		case TriggerCalendar:
+			due = projectCalendarPlan(plan, now, end)
+		case TriggerMeter:
+			meter, ok := usage[plan.EquipmentID]
+			if !ok {
+				if meter, err = s.meterUsage(plan.EquipmentID, now); err != nil {
+					return nil, nil, err
+				}
+				usage[plan.EquipmentID] = meter
+			}
+			due, reason = projectMeterPlan(plan, meter, now, end)
+			estimated = true
+		}
+		if reason != "" {
+			forecast.Unscheduled = append(forecast.Unscheduled, models.UnscheduledPlan{
+				PlanID:      plan.ID,
+				PlanName:    plan.Name,
+				EquipmentID: plan.EquipmentID,
+				Reason:      reason,
+			})
+			continue
+		}
+
+		for _, date := range due {
+			service := models.ScheduledService{
+				PlanID:      plan.ID,
+				PlanName:    plan.Name,
+				EquipmentID: plan.EquipmentID,
+				TriggerType: plan.TriggerType,
+				DueDate:     dateOnly(date),
+				OrderBy:     dateOnly(date).AddDate(0, 0, -plan.LeadTimeDays),
+				Estimated:   estimated,
+				Overdue:     date.Before(now),
+				Parts:       make([]models.ScheduledPartNeed, 0, len(plan.Parts)),
+			}
+			for _, part := range plan.Parts {
+				service.Parts = append(service.Parts, models.ScheduledPartNeed{
+					PartID:     part.PartID,
+					PartNumber: part.PartNumber,
+					PartName:   part.PartName,
+					Unit:       part.Unit,
+					Quantity:   part.Quantity,
+				})
+			}
+			forecast.Services = append(forecast.Services, service)
+		}
+	}
+
+	sort.SliceStable(forecast.Services, func(i, j int) bool {
+		return forecast.Services[i].DueDate.Before(forecast.Services[j].DueDate)
+	})
+
+	stock, err := s.maintenanceStock(forecast.Services, authToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	forecast.Demand = allocateStock(forecast.Services, stock)
+
+	drafts, err := s.maintenanceRepo.ListDrafts(planIDs, cycleStart)
+	if err != nil {
+		return nil, nil, err
+	}
+	matchDrafts(forecast.Services, byID, drafts)
+
+	today := dateOnly(now)
+	for _, service := range forecast.Services {
+		if service.OrderBy.After(today) {
+			continue
+		}
+		var items []models.ScheduledPartNeed
+		for _, part := range service.Parts {
+			if part.Shortfall > 0 {
+				items = append(items, part)
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+		forecast.SuggestedPRs = append(forecast.SuggestedPRs, models.SuggestedPR{
+			PlanID:      service.PlanID,
+			PlanName:    service.PlanName,
+			EquipmentID: service.EquipmentID,
+			DueDate:     service.DueDate,
+			OrderBy:     service.OrderBy,
+			Items:       items,
+			AutoDraft:   byID[service.PlanID].AutoDraftPR,
+			Drafted:     service.Drafted,
+			PRID:        service.PRID,
+			PRNumber:    service.PRNumber,
+		})
+	}
+
+	return forecast, byID, nil
+}
+
+// planCycleStart is when the plan's current interval started
+func planCycleStart(plan *models.MaintenancePlan) time.Time {
+	if plan.LastPerformedAt != nil {
+		return *plan.LastPerformedAt
+	}
+	return plan.CreatedAt
+}
+
+// projectCalendarPlan returns a calendar plan's due dates up to the end of
+// the horizon. An overdue service is listed once and the next ones follow on
+// from today.
+func projectCalendarPlan(plan *models.MaintenancePlan, now, end time.Time) []time.Time {
+	interval := *plan.IntervalDays
+	due := planCycleStart(plan).AddDate(0, 0, interval)
+
+	var dates []time.Time
+	for len(dates) < maxServicesPerPlan && !due.After(end) {
+		dates = append(dates, due)
+		if due.Before(now) {
+			due = now
+		}
+		due = due.AddDate(0, 0, interval)
+	}
+	return dates
+}
+
+// meterUsage is an equipment's latest meter reading and its recent rate of use
+type meterUsage struct {
+	latest      *models.MeterReading
+	hoursPerDay float64
+}
+
+func (s *EquipmentService) meterUsage(equipmentID uuid.UUID, now time.Time) (*meterUsage, error) {
+	readings, err := s.maintenanceRepo.ListMeterReadings(equipmentID, now.Add(-meterRateWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &meterUsage{}
+	if len(readings) == 0 {
+		latest, err := s.maintenanceRepo.LatestMeterReading(equipmentID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return usage, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		usage.latest = latest
+		return usage, nil
+	}
+
+	first, last := readings[0], readings[len(readings)-1]
+	usage.latest = &last
+	if days := last.ReadAt.Sub(first.ReadAt).Hours() / 24; days > 0 && last.Reading > first.Reading {
+		usage.hoursPerDay = (last.Reading - first.Reading) / days
+	}
+	return usage, nil
+}
+
+// projectMeterPlan estimates when a meter plan's interval will be used up at
+// the recent rate of use. It returns a reason instead when it cannot.
+func projectMeterPlan(plan *models.MaintenancePlan, usage *meterUsage, now, end time.Time) ([]time.Time, string) {
+	if usage.latest == nil {
+		return nil, "no meter readings recorded"
+	}
+
+	interval := *plan.IntervalHours
+	dueMeter := interval
+	if plan.LastPerformedMeter != nil {
+		dueMeter += *plan.LastPerformedMeter
+	}
+
+	var dates []time.Time
+	due := usage.latest.ReadAt
+	if remaining := dueMeter - usage.latest.Reading; remaining > 0 {
+		if usage.hoursPerDay <= 0 {
+			return nil, "not enough meter readings to estimate usage"
+		}
+		due = due.Add(daysToDuration(remaining / usage.hoursPerDay))
+	}
+	if usage.hoursPerDay <= 0 {
+		// Already due, but later services cannot be estimated
+		return []time.Time{due}, ""
+	}
+
+	step := daysToDuration(interval / usage.hoursPerDay)
+	for len(dates) < maxServicesPerPlan && !due.After(end) {
+		dates = append(dates, due)
+		if due.Before(now) {
+			due = now
+		}
+		due = due.Add(step)
+	}
+	return dates, ""
+}
+
+func daysToDuration(days float64) time.Duration {
+	return time.Duration(days * float64(24*time.Hour))
+}
+
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// maintenanceStock looks up warehouse stock for the catalog parts services need
+func (s *EquipmentService) maintenanceStock(services []models.ScheduledService, authToken string) (map[uuid.UUID]PartStock, error) {
+	var partIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, service := range services {
+		for _, part := range service.Parts {
+			if part.PartID != nil && !seen[*part.PartID] {
+				seen[*part.PartID] = true
+				partIDs = append(partIDs, *part.PartID)
+			}
+		}
+	}
+
+	if len(partIDs) == 0 {
+		return map[uuid.UUID]PartStock{}, nil
+	}
+	return s.warehouseClient.GetStock(authToken, partIDs)
+}
+
+// allocateStock hands the tenant's own stock to services in due order,
+// setting each part's shortfall, and totals demand per part. Parts that are
+// not in the catalog have no stock.
+func allocateStock(services []models.ScheduledService, stock map[uuid.UUID]PartStock) []models.PartDemand {
+	remaining := make(map[uuid.UUID]float64, len(stock))
+	for partID, s := range stock {
+		remaining[partID] = s.Available
+	}
+
+	demand := []models.PartDemand{}
+	index := make(map[string]int)
+	for i := range services {
+		service := &services[i]
+		for j := range service.Parts {
+			part := &service.Parts[j]
+			if part.PartID != nil {
+				part.FromStock = minFloat(part.Quantity, remaining[*part.PartID])
+				remaining[*part.PartID] -= part.FromStock
+			}
+			part.Shortfall = part.Quantity - part.FromStock
+
+			key := maintenancePartKey(part)
+			k, ok := index[key]
+			if !ok {
+				k = len(demand)
+				index[key] = k
+				total := models.PartDemand{
+					PartID:      part.PartID,
+					PartNumber:  part.PartNumber,
+					PartName:    part.PartName,
+					Unit:        part.Unit,
+					FirstNeeded: service.DueDate,
+				}
+				if part.PartID != nil {
+					total.Available = stock[*part.PartID].Available
+					total.SharedAvailable = stock[*part.PartID].SharedAvailable
+				}
+				demand = append(demand, total)
+			}
+
+			total := &demand[k]
+			total.Quantity += part.Quantity
+			if part.Shortfall > 0 {
+				if total.OrderBy == nil {
+					orderBy := service.OrderBy
+					total.OrderBy = &orderBy
+				}
+				total.Shortfall += part.Shortfall
+			}
+		}
+	}
+	return demand
+}
+
+func maintenancePartKey(part *models.ScheduledPartNeed) string {
+	if part.PartID != nil {
+		return part.PartID.String()
+	}
+	if part.PartNumber != "" {
+		return "pn:" + strings.ToUpper(part.PartNumber)
+	}
+	return "name:" + strings.ToLower(part.PartName)
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// matchDrafts marks services that already have a PR. Drafts made in a plan's
+// current interval belong to its services in due order; meter plans' due
+// dates shift as readings come in, so they are not matched by date.
+func matchDrafts(services []models.ScheduledService, plans map[uuid.UUID]*models.MaintenancePlan, drafts []models.MaintenancePRDraft) {
+	byPlan := make(map[uuid.UUID][]models.MaintenancePRDraft)
+	for _, draft := range drafts {
+		if draft.CreatedAt.Before(planCycleStart(plans[draft.PlanID])) {
+			continue
+		}
+		byPlan[draft.PlanID] = append(byPlan[draft.PlanID], draft)
+	}
+	for planID := range byPlan {
+		planDrafts := byPlan[planID]
+		sort.Slice(planDrafts, func(i, j int) bool {
+			return planDrafts[i].DueDate.Before(planDrafts[j].DueDate)
+		})
+	}
+
+	for i := range services {
+		service := &services[i]
+		planDrafts := byPlan[service.PlanID]
+		if len(planDrafts) == 0 {
+			continue
+		}
+		draft := planDrafts[0]
+		byPlan[service.PlanID] = planDrafts[1:]
+		service.Drafted = true
+		service.PRID = draft.PRID
+		service.PRNumber = draft.PRNumber
+	}
+}
+
+// draftMaintenancePR creates a draft purchase request for the shortfall of a
+// suggested service. It returns nil when the service was already drafted.
+func (s *EquipmentService) draftMaintenancePR(plan *models.MaintenancePlan, suggestion *models.SuggestedPR, authToken string) (*PurchaseRequest, error) {
+	equipment, err := s.equipmentRepo.GetByID(plan.EquipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	draft := &models.MaintenancePRDraft{
+		TenantID: plan.TenantID,
+		PlanID:   plan.ID,
+		DueDate:  suggestion.DueDate,
+	}
+	claimed, err := s.maintenanceRepo.ClaimDraft(draft)
+	if err != nil || !claimed {
+		return nil, err
+	}
+
+	pr, err := s.createMaintenancePR(plan, equipment, suggestion, authToken)
+	if err != nil {
+		if releaseErr := s.maintenanceRepo.ReleaseDraft(draft.ID); releaseErr != nil {
+			log.Printf("Failed to release PR draft claim %s: %v", draft.ID, releaseErr)
+		}
+		return nil, err
+	}
+
+	if err := s.maintenanceRepo.CompleteDraft(draft.ID, pr.ID, pr.PRNumber); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+func (s *EquipmentService) createMaintenancePR(plan *models.MaintenancePlan, equipment *models.Equipment, suggestion *models.SuggestedPR, authToken string) (*PurchaseRequest, error) {
+	const currency = "USD"
+	dueDate := suggestion.DueDate.Format("2006-01-02")
+
+	var partIDs []uuid.UUID
+	for _, item := range suggestion.Items {
+		if item.PartID != nil {
+			partIDs = append(partIDs, *item.PartID)
+		}
+	}
+	prices := map[uuid.UUID]models.PartPrice{}
+	if len(partIDs) > 0 {
+		var err error
+		if prices, err = s.marketplaceClient.GetPartPrices(authToken, partIDs, currency); err != nil {
+			return nil, err
+		}
+	}
+
+	priority := "normal"
+	if suggestion.DueDate.Before(time.Now()) {
+		priority = "urgent"
+	}
+	requiredDate := suggestion.DueDate
+	pr := &PurchaseRequest{
+		EquipmentID:  &equipment.ID,
+		Title:        fmt.Sprintf("%s for %s %s", plan.Name, equipment.EquipmentNumber, equipment.Name),
+		Description:  fmt.Sprintf("Parts for maintenance plan %s due %s", plan.Name, dueDate),
+		Priority:     priority,
+		Currency:     currency,
+		RequiredDate: &requiredDate,
+	}
+
+	for _, need := range suggestion.Items {
+		item := PRItem{
+			PartID:         need.PartID,
+			Description:    need.PartName,
+			Quantity:       need.Shortfall,
+			Unit:           need.Unit,
+			Specifications: fmt.Sprintf("Equipment %s, maintenance due %s", equipment.EquipmentNumber, dueDate),
+		}
+		if need.PartNumber != "" && need.PartNumber != need.PartName {
+			item.Description = fmt.Sprintf("%s (%s)", need.PartName, need.PartNumber)
+		}
+		if need.PartID != nil {
+			if price, ok := prices[*need.PartID]; ok && strings.EqualFold(price.Currency, currency) {
+				item.UnitPrice = money.NewFromFloat(price.Price)
+			}
+		}
+		item.TotalPrice = item.UnitPrice.Mul(money.NewFromFloat(item.Quantity))
+		pr.Budget = pr.Budget.Add(item.TotalPrice)
+		pr.Items = append(pr.Items, item)
+	}
+
+	return s.procurementClient.CreatePR(authToken, pr)
+}
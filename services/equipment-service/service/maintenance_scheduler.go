@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/b2b-platform/equipment-service/models"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/google/uuid"
+)
+
+// MaintenanceScheduler periodically runs the maintenance forecast for every
+// tenant with plans that draft their own purchase requests. PRs are drafted
+// in the name of the user who created each plan.
+type MaintenanceScheduler struct {
+	equipmentService *EquipmentService
+	jwtService       *auth.JWTService
+	horizonDays      int
+}
+
+func NewMaintenanceScheduler(equipmentService *EquipmentService, jwtService *auth.JWTService, horizonDays int) *MaintenanceScheduler {
+	return &MaintenanceScheduler{
+		equipmentService: equipmentService,
+		jwtService:       jwtService,
+		horizonDays:      horizonDays,
+	}
+}
+
+// Start runs the forecast now and then on every interval until the context
+// is cancelled
+func (ms *MaintenanceScheduler) Start(ctx context.Context, interval time.Duration) error {
+	log.Printf("Starting maintenance scheduler, running every %s...", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := ms.RunOnce(); err != nil {
+			log.Printf("Maintenance scheduler error: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce forecasts each tenant with auto-drafting plans and drafts the PRs
+// that are due. A tenant that fails is logged and skipped.
+func (ms *MaintenanceScheduler) RunOnce() error {
+	plans, err := ms.equipmentService.maintenanceRepo.ListAutoDraftPlans()
+	if err != nil {
+		return err
+	}
+
+	// Stock is read as the tenant's first auto-drafting plan's creator
+	tenants := make(map[uuid.UUID]uuid.UUID)
+	var order []uuid.UUID
+	for _, plan := range plans {
+		if _, ok := tenants[plan.TenantID]; !ok {
+			tenants[plan.TenantID] = plan.CreatedBy
+			order = append(order, plan.TenantID)
+		}
+	}
+
+	for _, tenantID := range order {
+		tokens := make(map[uuid.UUID]string)
+		planToken := func(plan *models.MaintenancePlan) (string, error) {
+			if token, ok := tokens[plan.CreatedBy]; ok {
+				return token, nil
+			}
+			token, err := ms.jwtService.GenerateToken(plan.CreatedBy, plan.TenantID, "", []string{"requester"})
+			if err != nil {
+				return "", err
+			}
+			tokens[plan.CreatedBy] = token
+			return token, nil
+		}
+
+		token, err := planToken(&models.MaintenancePlan{TenantID: tenantID, CreatedBy: tenants[tenantID]})
+		if err != nil {
+			return err
+		}
+
+		if _, err := ms.equipmentService.runMaintenanceForecast(tenantID, ms.horizonDays, token, planToken); err != nil {
+			log.Printf("Maintenance forecast failed for tenant %s: %v", tenantID, err)
+		}
+	}
+	return nil
+}
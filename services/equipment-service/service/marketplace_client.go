@@ -0,0 +1,74 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/equipment-service/models"
+	"github.com/google/uuid"
+)
+
+// MarketplaceClient reads current listing prices from marketplace-service
+type MarketplaceClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewMarketplaceClient() *MarketplaceClient {
+	baseURL := os.Getenv("MARKETPLACE_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8005"
+	}
+
+	return &MarketplaceClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// GetPartPrices returns the best active listing for each part, preferring the
+// given currency. Parts without a listing are missing from the result.
+func (c *MarketplaceClient) GetPartPrices(authToken string, partIDs []uuid.UUID, currency string) (map[uuid.UUID]models.PartPrice, error) {
+	url := fmt.Sprintf("%s/api/v1/listings/prices", c.baseURL)
+
+	body, err := json.Marshal(map[string]interface{}{"part_ids": partIDs, "currency": currency})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if !strings.HasPrefix(authToken, "Bearer ") {
+		authToken = "Bearer " + authToken
+	}
+	httpReq.Header.Set("Authorization", authToken)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call marketplace service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("marketplace service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var prices map[uuid.UUID]models.PartPrice
+	if err := json.NewDecoder(resp.Body).Decode(&prices); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return prices, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+)
+
+// ProcurementClient creates purchase requests in procurement-service
+type ProcurementClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewProcurementClient() *ProcurementClient {
+	baseURL := os.Getenv("PROCUREMENT_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8006"
+	}
+
+	return &ProcurementClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// PurchaseRequest is the subset of a procurement purchase request sent when
+// creating one from a BOM
+type PurchaseRequest struct {
+	ID            uuid.UUID     `json:"id,omitempty"`
+	PRNumber      string        `json:"pr_number,omitempty"`
+	EquipmentID   *uuid.UUID    `json:"equipment_id,omitempty"`
+	BOMRevisionID *uuid.UUID    `json:"bom_revision_id,omitempty"` // BOM revision the PR was sourced against
+	Title         string        `json:"title"`
+	Description   string        `json:"description"`
+	Status        string        `json:"status,omitempty"`
+	Priority      string        `json:"priority,omitempty"`
+	Currency      string        `json:"currency"`
+	Budget        money.Decimal `json:"budget"`
+	RequiredDate  *time.Time    `json:"required_date,omitempty"`
+	Items         []PRItem      `json:"items"`
+}
+
+type PRItem struct {
+	PartID         *uuid.UUID    `json:"part_id,omitempty"`
+	Description    string        `json:"description"`
+	Quantity       float64       `json:"quantity"`
+	Unit           string        `json:"unit"`
+	UnitPrice      money.Decimal `json:"unit_price"`
+	TotalPrice     money.Decimal `json:"total_price"`
+	Specifications string        `json:"specifications"`
+}
+
+// CreatePR creates a draft purchase request on behalf of the caller
+func (c *ProcurementClient) CreatePR(authToken string, pr *PurchaseRequest) (*PurchaseRequest, error) {
+	url := fmt.Sprintf("%s/api/v1/purchase-requests", c.baseURL)
+
+	body, err := json.Marshal(pr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if !strings.HasPrefix(authToken, "Bearer ") {
+		authToken = "Bearer " + authToken
+	}
+	httpReq.Header.Set("Authorization", authToken)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call procurement service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("procurement service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var created PurchaseRequest
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &created, nil
+}
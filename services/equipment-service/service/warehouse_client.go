@@ -0,0 +1,85 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WarehouseClient reads stock positions from virtual-warehouse-service
+type WarehouseClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewWarehouseClient() *WarehouseClient {
+	baseURL := os.Getenv("VIRTUAL_WAREHOUSE_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8011"
+	}
+
+	return &WarehouseClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// PartStock is the caller's unreserved stock of a part, and what other
+// tenants share of it
+type PartStock struct {
+	PartID          uuid.UUID `json:"part_id"`
+	Available       float64   `json:"available"`
+	SharedAvailable float64   `json:"shared_available"`
+}
+
+// GetStock returns the caller's stock position for each part
+func (c *WarehouseClient) GetStock(authToken string, partIDs []uuid.UUID) (map[uuid.UUID]PartStock, error) {
+	url := fmt.Sprintf("%s/api/v1/inventory/stock", c.baseURL)
+
+	body, err := json.Marshal(map[string]interface{}{"part_ids": partIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if !strings.HasPrefix(authToken, "Bearer ") {
+		authToken = "Bearer " + authToken
+	}
+	httpReq.Header.Set("Authorization", authToken)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call warehouse service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("warehouse service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var stock []PartStock
+	if err := json.NewDecoder(resp.Body).Decode(&stock); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	byPart := make(map[uuid.UUID]PartStock, len(stock))
+	for _, s := range stock {
+		byPart[s.PartID] = s
+	}
+	return byPart, nil
+}
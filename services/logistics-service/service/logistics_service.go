@@ -59,6 +59,11 @@ func (s *LogisticsService) UpdateTracking(shipmentID uuid.UUID, event *models.Tr
 				"shipment_id":    shipment.ID.String(),
 				"tracking_number": shipment.TrackingNumber,
 				"eta":            shipment.ETA,
+				"po_id":          shipment.POID.String(),
+				"status":         shipment.Status,
+				"carrier":        shipment.Carrier,
+				"origin":         shipment.Origin,
+				"destination":    shipment.Destination,
 			},
 		).WithTenantID(shipment.TenantID)
 
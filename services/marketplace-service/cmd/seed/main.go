@@ -7,6 +7,7 @@ import (
 	"github.com/b2b-platform/marketplace-service/models"
 	"github.com/b2b-platform/marketplace-service/repository"
 	"github.com/b2b-platform/shared/database"
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 )
 
@@ -49,7 +50,7 @@ func main() {
 				Description:     "High-quality engine oil filter for Caterpillar equipment",
 				SKU:             "CAT-FILTER-001",
 				Status:          "active",
-				Price:           25.00,
+				Price:           money.MustParse("25.00"),
 				Currency:        "USD",
 				StockQuantity:   100,
 				MinOrderQuantity: 1,
@@ -65,7 +66,7 @@ func main() {
 				Description:     "Professional maintenance service for heavy equipment",
 				SKU:             "SVC-MAINT-001",
 				Status:          "active",
-				Price:           500.00,
+				Price:           money.MustParse("500.00"),
 				Currency:        "USD",
 				StockQuantity:   0,
 				MinOrderQuantity: 1,
@@ -81,7 +82,7 @@ func main() {
 				Description:     "Surplus hydraulic pump in good condition",
 				SKU:             "SURP-PUMP-001",
 				Status:          "active",
-				Price:           1200.00,
+				Price:           money.MustParse("1200.00"),
 				Currency:        "USD",
 				StockQuantity:   5,
 				MinOrderQuantity: 1,
@@ -97,7 +98,7 @@ func main() {
 				Description:     "Heavy-duty hydraulic hose for construction equipment",
 				SKU:             "HYD-HOSE-001",
 				Status:          "active",
-				Price:           85.00,
+				Price:           money.MustParse("85.00"),
 				Currency:        "USD",
 				StockQuantity:   50,
 				MinOrderQuantity: 1,
@@ -113,7 +114,7 @@ func main() {
 				Description:     "Replacement air filter for heavy machinery",
 				SKU:             "AIR-FILTER-001",
 				Status:          "active",
-				Price:           45.00,
+				Price:           money.MustParse("45.00"),
 				Currency:        "USD",
 				StockQuantity:   75,
 				MinOrderQuantity: 1,
@@ -129,7 +130,7 @@ func main() {
 				Description:     "Track roller for excavator undercarriage",
 				SKU:             "TRACK-ROLL-001",
 				Status:          "active",
-				Price:           350.00,
+				Price:           money.MustParse("350.00"),
 				Currency:        "USD",
 				StockQuantity:   20,
 				MinOrderQuantity: 1,
@@ -145,7 +146,7 @@ func main() {
 				Description:     "Replacement bucket teeth for excavator",
 				SKU:             "BUCKET-TEETH-001",
 				Status:          "active",
-				Price:           125.00,
+				Price:           money.MustParse("125.00"),
 				Currency:        "USD",
 				StockQuantity:   30,
 				MinOrderQuantity: 1,
@@ -161,7 +162,7 @@ func main() {
 				Description:     "Comprehensive equipment inspection and safety check",
 				SKU:             "SVC-INSP-001",
 				Status:          "active",
-				Price:           300.00,
+				Price:           money.MustParse("300.00"),
 				Currency:        "USD",
 				StockQuantity:   0,
 				MinOrderQuantity: 1,
@@ -177,7 +178,7 @@ func main() {
 				Description:     "Primary fuel filter for diesel engines",
 				SKU:             "FUEL-FILTER-001",
 				Status:          "active",
-				Price:           35.00,
+				Price:           money.MustParse("35.00"),
 				Currency:        "USD",
 				StockQuantity:   60,
 				MinOrderQuantity: 1,
@@ -193,7 +194,7 @@ func main() {
 				Description:     "Pressure relief radiator cap",
 				SKU:             "RAD-CAP-001",
 				Status:          "active",
-				Price:           15.00,
+				Price:           money.MustParse("15.00"),
 				Currency:        "USD",
 				StockQuantity:   200,
 				MinOrderQuantity: 1,
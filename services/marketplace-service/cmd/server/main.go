@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -14,6 +15,7 @@ import (
 	"github.com/b2b-platform/shared/middleware"
 	"github.com/b2b-platform/shared/observability"
 	"github.com/b2b-platform/shared/database"
+	"github.com/b2b-platform/shared/events"
 	"github.com/b2b-platform/shared/redis"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -33,17 +35,23 @@ func main() {
 	listingRepo := repository.NewListingRepository(db)
 	mediaRepo := repository.NewMediaRepository(db)
 
-	marketplaceService := service.NewMarketplaceService(storeRepo, listingRepo, mediaRepo)
+	// Listing changes are published for search indexing when Redis is available
+	redisClient, err := redis.GetRedisClient()
+	if err != nil {
+		log.Printf("Warning: Failed to connect to Redis: %v", err)
+	}
+	var eventBus events.EventBus
+	if redisClient != nil {
+		eventBus = events.NewRedisEventBus(redisClient)
+	}
+
+	marketplaceService := service.NewMarketplaceService(storeRepo, listingRepo, mediaRepo, eventBus)
 	marketplaceHandler := handlers.NewMarketplaceHandler(marketplaceService)
 
 	r := gin.Default()
 
 	
 	// Health endpoints
-	redisClient, err := redis.GetRedisClient()
-	if err != nil {
-		log.Printf("Warning: Failed to connect to Redis: %v", err)
-	}
 	if redisClient == nil {
 		redisClient, err = redis.GetRedisClient()
 		if err != nil {
@@ -54,6 +62,21 @@ func main() {
 	r.GET("/health", healthChecker.Health)
 	r.GET("/ready", healthChecker.Ready)
 
+	// Re-point part references when catalog parts are merged
+	if eventBus != nil {
+		eventConsumer := service.NewEventConsumer(marketplaceService)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			if err := eventConsumer.StartEventConsumer(ctx, eventBus); err != nil {
+				if err != context.Canceled {
+					log.Printf("Event consumer error: %v", err)
+				}
+			}
+		}()
+	}
+
 	// Configure CORS
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:3000", "http://localhost:3002", "http://127.0.0.1:3000", "http://127.0.0.1:3002"}
@@ -87,9 +110,13 @@ func main() {
 
 		// Listing endpoints
 		api.GET("/listings", marketplaceHandler.ListListings)
+		api.GET("/listings/search-snapshot", marketplaceHandler.SearchSnapshot)
+		api.POST("/listings/prices", marketplaceHandler.GetPartPrices)
+		api.POST("/parts/replace", marketplaceHandler.ReplacePartReferences)
 		api.GET("/listings/:id", marketplaceHandler.GetListing)
 		api.POST("/listings", marketplaceHandler.CreateListing)
 		api.PUT("/listings/:id", marketplaceHandler.UpdateListing)
+		api.DELETE("/listings/:id", marketplaceHandler.DeleteListing)
 		api.PUT("/listings/:id/stock", marketplaceHandler.UpdateStock)
 
 		// Media endpoints
@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/b2b-platform/marketplace-service/models"
 	"github.com/b2b-platform/marketplace-service/service"
 	"github.com/b2b-platform/shared/auth"
+	"github.com/b2b-platform/shared/geo"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -31,6 +33,10 @@ func (h *MarketplaceHandler) CreateStore(c *gin.Context) {
 	store.TenantID = tenantID
 
 	if err := h.service.CreateStore(&store); err != nil {
+		if errors.Is(err, geo.ErrInvalidLocation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -82,12 +88,19 @@ func (h *MarketplaceHandler) UpdateStore(c *gin.Context) {
 		return
 	}
 
+	located, _ := store.Point()
 	if err := c.ShouldBindJSON(&store); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// Coordinates given in the request replace geocoded ones
+	store.Moved(located)
 
 	if err := h.service.UpdateStore(store); err != nil {
+		if errors.Is(err, geo.ErrInvalidLocation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -177,6 +190,69 @@ func (h *MarketplaceHandler) UpdateListing(c *gin.Context) {
 	c.JSON(http.StatusOK, listing)
 }
 
+func (h *MarketplaceHandler) DeleteListing(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if _, err := h.service.GetListing(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	if err := h.service.DeleteListing(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "listing deleted"})
+}
+
+// SearchSnapshot exports listings for a search reindex. Admin only.
+// Query params: after (cursor), tenant_id, limit (default 500, max 1000).
+func (h *MarketplaceHandler) SearchSnapshot(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	after := uuid.Nil
+	if raw := c.Query("after"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid after"})
+			return
+		}
+		after = parsed
+	}
+
+	var tenantID *uuid.UUID
+	if raw := c.Query("tenant_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tenant_id"})
+			return
+		}
+		tenantID = &parsed
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultSnapshotPageSize)))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return
+	}
+
+	page, err := h.service.SearchSnapshot(after, tenantID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
 func (h *MarketplaceHandler) UpdateStock(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -255,3 +331,54 @@ func (h *MarketplaceHandler) GetStoreListings(c *gin.Context) {
 
 	c.JSON(http.StatusOK, listings)
 }
+
+// GetPartPrices returns the best current listing price for each catalog part.
+// Body: part_ids and an optional preferred currency.
+func (h *MarketplaceHandler) GetPartPrices(c *gin.Context) {
+	var req struct {
+		PartIDs  []uuid.UUID `json:"part_ids" binding:"required"`
+		Currency string      `json:"currency"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prices, err := h.service.GetPartPrices(req.PartIDs, req.Currency)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prices)
+}
+
+// ReplacePartReferences re-points listings from a catalog part that was merged away
+// onto the part it was merged into. Called by catalog-service when it merges
+// parts; repeating it is harmless. Super admin only.
+// Body: part_id and merged_into.
+func (h *MarketplaceHandler) ReplacePartReferences(c *gin.Context) {
+	if !auth.HasRole(c, "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "super admin access required"})
+		return
+	}
+
+	var req struct {
+		PartID     uuid.UUID `json:"part_id" binding:"required"`
+		MergedInto uuid.UUID `json:"merged_into" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.service.ReplacePartReferences(req.PartID, req.MergedInto)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
@@ -0,0 +1,15 @@
+-- Where stores ship from, for searching listings near a site
+ALTER TABLE marketplace.stores
+ADD COLUMN IF NOT EXISTS country VARCHAR(100);
+
+ALTER TABLE marketplace.stores
+ADD COLUMN IF NOT EXISTS postal_code VARCHAR(20);
+
+ALTER TABLE marketplace.stores
+ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+
+ALTER TABLE marketplace.stores
+ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+
+ALTER TABLE marketplace.stores
+ADD COLUMN IF NOT EXISTS location_geocoded BOOLEAN DEFAULT FALSE;
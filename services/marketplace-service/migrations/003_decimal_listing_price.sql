@@ -0,0 +1,3 @@
+-- Listing prices are exact decimals; four places fit three-decimal currencies such as KWD
+ALTER TABLE marketplace.listings
+ALTER COLUMN price TYPE NUMERIC(19,4);
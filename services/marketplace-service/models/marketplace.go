@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"github.com/b2b-platform/shared/geo"
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -15,6 +17,10 @@ type Store struct {
 	Description string         `gorm:"type:text" json:"description"`
 	Status      string         `gorm:"type:varchar(50);default:'active';index" json:"status"` // active, suspended, closed
 	IsVerified  bool           `gorm:"default:false" json:"is_verified"`
+	// Where the store ships from, for searching listings near a site
+	Country     string         `gorm:"type:varchar(100)" json:"country"`
+	PostalCode  string         `gorm:"type:varchar(20)" json:"postal_code"`
+	geo.Site                   `gorm:"embedded"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -37,7 +43,7 @@ type Listing struct {
 	Description string         `gorm:"type:text" json:"description"`
 	SKU         string         `gorm:"type:varchar(100)" json:"sku"`
 	Status      string         `gorm:"type:varchar(50);default:'draft';index" json:"status"` // draft, active, sold_out, inactive
-	Price       float64        `gorm:"not null" json:"price"`
+	Price       money.Decimal  `gorm:"type:numeric(19,4);not null" json:"price"`
 	Currency    string         `gorm:"type:varchar(10);default:'USD'" json:"currency"`
 	StockQuantity float64      `gorm:"default:0" json:"stock_quantity"`
 	MinOrderQuantity float64   `gorm:"default:1" json:"min_order_quantity"`
@@ -49,6 +49,22 @@ func (r *ListingRepository) Update(listing *models.Listing) error {
 	return r.db.Save(listing).Error
 }
 
+func (r *ListingRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.Listing{}, "id = ?", id).Error
+}
+
+// ListAfter returns up to limit listings with their stores, with IDs after a
+// cursor, in ID order, optionally for one tenant
+func (r *ListingRepository) ListAfter(after uuid.UUID, tenantID *uuid.UUID, limit int) ([]models.Listing, error) {
+	var listings []models.Listing
+	query := r.db.Preload("Store").Where("id > ?", after)
+	if tenantID != nil {
+		query = query.Where("tenant_id = ?", *tenantID)
+	}
+	err := query.Order("id ASC").Limit(limit).Find(&listings).Error
+	return listings, err
+}
+
 func (r *ListingRepository) GetByStore(storeID uuid.UUID) ([]models.Listing, error) {
 	var listings []models.Listing
 	err := r.db.Preload("Media").Where("store_id = ?", storeID).Find(&listings).Error
@@ -60,3 +76,27 @@ func (r *ListingRepository) UpdateStock(listingID uuid.UUID, quantity float64) e
 		Where("id = ?", listingID).
 		Update("stock_quantity", quantity).Error
 }
+
+// ReplacePart re-points listings from one catalog part to another
+func (r *ListingRepository) ReplacePart(oldPartID, newPartID uuid.UUID) (int64, error) {
+	result := r.db.Model(&models.Listing{}).
+		Where("part_id = ?", oldPartID).
+		Update("part_id", newPartID)
+	return result.RowsAffected, result.Error
+}
+
+// ListActiveByParts returns the purchasable listings for catalog parts,
+// cheapest first, across all stores
+func (r *ListingRepository) ListActiveByParts(partIDs []uuid.UUID) ([]models.Listing, error) {
+	var listings []models.Listing
+	if len(partIDs) == 0 {
+		return listings, nil
+	}
+	err := r.db.Preload("Store").
+		Joins("JOIN marketplace.stores ON marketplace.stores.id = marketplace.listings.store_id").
+		Where("marketplace.listings.part_id IN ? AND marketplace.listings.status = ? AND marketplace.listings.is_active = ?", partIDs, "active", true).
+		Where("marketplace.stores.status = ? AND marketplace.stores.deleted_at IS NULL", "active").
+		Order("marketplace.listings.price ASC").
+		Find(&listings).Error
+	return listings, err
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/b2b-platform/shared/events"
+	"github.com/google/uuid"
+)
+
+// EventConsumer keeps marketplace listings in step with catalog changes
+type EventConsumer struct {
+	marketplaceService *MarketplaceService
+}
+
+func NewEventConsumer(marketplaceService *MarketplaceService) *EventConsumer {
+	return &EventConsumer{
+		marketplaceService: marketplaceService,
+	}
+}
+
+func (ec *EventConsumer) HandleEvent(event *events.EventEnvelope) error {
+	switch event.Type {
+	case events.EventCatalogPartMerged:
+		return ec.handlePartMerged(event)
+	default:
+		// Not relevant to the marketplace, skip
+		return nil
+	}
+}
+
+func (ec *EventConsumer) handlePartMerged(event *events.EventEnvelope) error {
+	oldPartID, newPartID, err := mergedPartIDs(event)
+	if err != nil {
+		return err
+	}
+
+	updated, err := ec.marketplaceService.ReplacePartReferences(oldPartID, newPartID)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Re-pointed %d listings from part %s to %s", updated, oldPartID, newPartID)
+	return nil
+}
+
+func mergedPartIDs(event *events.EventEnvelope) (uuid.UUID, uuid.UUID, error) {
+	partIDStr, _ := event.Payload["part_id"].(string)
+	mergedIntoStr, _ := event.Payload["merged_into"].(string)
+
+	partID, err := uuid.Parse(partIDStr)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid part_id: %w", err)
+	}
+	mergedInto, err := uuid.Parse(mergedIntoStr)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("invalid merged_into: %w", err)
+	}
+	return partID, mergedInto, nil
+}
+
+// StartEventConsumer starts listening to events
+func (ec *EventConsumer) StartEventConsumer(ctx context.Context, eventBus events.EventBus) error {
+	log.Println("Starting marketplace event consumer...")
+
+	return eventBus.Subscribe(ctx, events.EventCatalogPartMerged, func(event *events.EventEnvelope) error {
+		if err := ec.HandleEvent(event); err != nil {
+			log.Printf("Error handling event %s: %v", event.Type, err)
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/b2b-platform/marketplace-service/models"
+	"github.com/b2b-platform/shared/events"
+	"github.com/google/uuid"
+)
+
+const eventSource = "marketplace-service"
+
+// Snapshot page sizes bound how much one export request reads
+const (
+	DefaultSnapshotPageSize = 500
+	MaxSnapshotPageSize     = 1000
+)
+
+// SearchSnapshot exports listings, optionally one tenant's, as the events
+// announcing their current state so search can rebuild its index. Pages
+// follow ID order from the after cursor.
+func (s *MarketplaceService) SearchSnapshot(after uuid.UUID, tenantID *uuid.UUID, limit int) (*events.SnapshotPage, error) {
+	if limit <= 0 {
+		limit = DefaultSnapshotPageSize
+	}
+	if limit > MaxSnapshotPageSize {
+		limit = MaxSnapshotPageSize
+	}
+
+	listings, err := s.listingRepo.ListAfter(after, tenantID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &events.SnapshotPage{Events: make([]*events.EventEnvelope, 0, len(listings))}
+	for i := range listings {
+		page.Events = append(page.Events, listingEvent(events.EventListingUpdated, &listings[i]))
+	}
+	if len(listings) == limit {
+		page.NextAfter = listings[len(listings)-1].ID.String()
+	}
+	return page, nil
+}
+
+// republishListing reloads a listing with its store and announces it.
+// Failures are logged; the change is already saved.
+func (s *MarketplaceService) republishListing(eventType events.EventType, listingID uuid.UUID) {
+	if s.eventBus == nil {
+		return
+	}
+	listing, err := s.listingRepo.GetByID(listingID)
+	if err != nil {
+		log.Printf("Failed to load listing %s to publish %s: %v", listingID, eventType, err)
+		return
+	}
+	s.publishListingChanged(eventType, listing)
+}
+
+// publishListingChanged announces a created or updated listing with its full
+// search document. The listing's store must be loaded.
+func (s *MarketplaceService) publishListingChanged(eventType events.EventType, listing *models.Listing) {
+	s.publish(listingEvent(eventType, listing))
+}
+
+func listingEvent(eventType events.EventType, listing *models.Listing) *events.EventEnvelope {
+	payload := map[string]interface{}{
+		"listing_id":   listing.ID.String(),
+		"store_id":     listing.StoreID.String(),
+		"supplier_id":  listing.TenantID.String(),
+		"listing_type": listing.ListingType,
+		"title":        listing.Title,
+		"description":  listing.Description,
+		"sku":          listing.SKU,
+		"status":       listing.Status,
+		"is_active":    listing.IsActive,
+		"store_status": listing.Store.Status,
+		"price":        listing.Price,
+		"currency":     listing.Currency,
+		"stock":        listing.StockQuantity,
+		"eta":          listing.LeadTimeDays,
+	}
+	if listing.PartID != nil {
+		payload["part_id"] = listing.PartID.String()
+	}
+	if point, ok := listing.Store.Point(); ok {
+		payload["latitude"] = point.Lat
+		payload["longitude"] = point.Lon
+	}
+
+	return events.NewEventEnvelope(eventType, eventSource, payload).
+		WithTenantID(listing.TenantID).
+		WithVersion(events.VersionAt(listing.UpdatedAt))
+}
+
+func (s *MarketplaceService) publishListingDeleted(listing *models.Listing) {
+	event := events.NewEventEnvelope(events.EventListingDeleted, eventSource, map[string]interface{}{
+		"listing_id": listing.ID.String(),
+	}).WithTenantID(listing.TenantID).WithVersion(events.VersionAt(time.Now()))
+
+	s.publish(event)
+}
+
+func (s *MarketplaceService) publish(event *events.EventEnvelope) {
+	if s.eventBus == nil {
+		return
+	}
+	if err := s.eventBus.Publish(nil, event); err != nil {
+		log.Printf("Failed to publish %s: %v", event.Type, err)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"github.com/b2b-platform/marketplace-service/models"
 	"github.com/b2b-platform/marketplace-service/repository"
+	"github.com/b2b-platform/shared/events"
 	"github.com/google/uuid"
 )
 
@@ -10,21 +11,27 @@ type MarketplaceService struct {
 	storeRepo   *repository.StoreRepository
 	listingRepo *repository.ListingRepository
 	mediaRepo   *repository.MediaRepository
+	eventBus    events.EventBus
 }
 
 func NewMarketplaceService(
 	storeRepo *repository.StoreRepository,
 	listingRepo *repository.ListingRepository,
 	mediaRepo *repository.MediaRepository,
+	eventBus events.EventBus,
 ) *MarketplaceService {
 	return &MarketplaceService{
 		storeRepo:   storeRepo,
 		listingRepo: listingRepo,
 		mediaRepo:   mediaRepo,
+		eventBus:    eventBus,
 	}
 }
 
 func (s *MarketplaceService) CreateStore(store *models.Store) error {
+	if err := store.Locate(store.Country, store.PostalCode); err != nil {
+		return err
+	}
 	return s.storeRepo.Create(store)
 }
 
@@ -36,12 +43,33 @@ func (s *MarketplaceService) ListStores(tenantID uuid.UUID, limit, offset int) (
 	return s.storeRepo.List(tenantID, limit, offset)
 }
 
+// UpdateStore saves a store and republishes its listings, whose
+// searchability follows the store's status
 func (s *MarketplaceService) UpdateStore(store *models.Store) error {
-	return s.storeRepo.Update(store)
+	if err := store.Locate(store.Country, store.PostalCode); err != nil {
+		return err
+	}
+	if err := s.storeRepo.Update(store); err != nil {
+		return err
+	}
+
+	listings, err := s.listingRepo.GetByStore(store.ID)
+	if err != nil {
+		return err
+	}
+	for i := range listings {
+		listings[i].Store = *store
+		s.publishListingChanged(events.EventListingUpdated, &listings[i])
+	}
+	return nil
 }
 
 func (s *MarketplaceService) CreateListing(listing *models.Listing) error {
-	return s.listingRepo.Create(listing)
+	if err := s.listingRepo.Create(listing); err != nil {
+		return err
+	}
+	s.republishListing(events.EventListingCreated, listing.ID)
+	return nil
 }
 
 func (s *MarketplaceService) GetListing(id uuid.UUID) (*models.Listing, error) {
@@ -53,11 +81,31 @@ func (s *MarketplaceService) ListListings(tenantID uuid.UUID, limit, offset int,
 }
 
 func (s *MarketplaceService) UpdateListing(listing *models.Listing) error {
-	return s.listingRepo.Update(listing)
+	if err := s.listingRepo.Update(listing); err != nil {
+		return err
+	}
+	s.republishListing(events.EventListingUpdated, listing.ID)
+	return nil
 }
 
 func (s *MarketplaceService) UpdateStock(listingID uuid.UUID, quantity float64) error {
-	return s.listingRepo.UpdateStock(listingID, quantity)
+	if err := s.listingRepo.UpdateStock(listingID, quantity); err != nil {
+		return err
+	}
+	s.republishListing(events.EventListingUpdated, listingID)
+	return nil
+}
+
+func (s *MarketplaceService) DeleteListing(id uuid.UUID) error {
+	listing, err := s.listingRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.listingRepo.Delete(listing.ID); err != nil {
+		return err
+	}
+	s.publishListingDeleted(listing)
+	return nil
 }
 
 func (s *MarketplaceService) AddMedia(media *models.ListingMedia) error {
@@ -75,3 +123,9 @@ func (s *MarketplaceService) SetPrimaryMedia(listingID, mediaID uuid.UUID) error
 func (s *MarketplaceService) GetStoreListings(storeID uuid.UUID) ([]models.Listing, error) {
 	return s.listingRepo.GetByStore(storeID)
 }
+
+// ReplacePartReferences moves listings from a catalog part that was merged
+// away onto the part it was merged into
+func (s *MarketplaceService) ReplacePartReferences(oldPartID, newPartID uuid.UUID) (int64, error) {
+	return s.listingRepo.ReplacePart(oldPartID, newPartID)
+}
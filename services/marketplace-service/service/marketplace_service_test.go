@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/b2b-platform/marketplace-service/models"
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 )
 
@@ -174,7 +175,7 @@ func TestMarketplaceService_CreateListing(t *testing.T) {
 		StoreID:     storeID,
 		Type:        "product",
 		Name:        "Test Product",
-		Price:       99.99,
+		Price:       money.MustParse("99.99"),
 		StockQuantity: 100.0,
 		Status:      "active",
 	}
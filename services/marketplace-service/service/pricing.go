@@ -0,0 +1,58 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+)
+
+// PartPrice is the current best marketplace offer for a catalog part
+type PartPrice struct {
+	PartID           uuid.UUID     `json:"part_id"`
+	ListingID        uuid.UUID     `json:"listing_id"`
+	StoreID          uuid.UUID     `json:"store_id"`
+	StoreName        string        `json:"store_name"`
+	Price            money.Decimal `json:"price"`
+	Currency         string        `json:"currency"`
+	MinOrderQuantity float64       `json:"min_order_quantity"`
+	LeadTimeDays     int           `json:"lead_time_days"`
+	StockQuantity    float64       `json:"stock_quantity"`
+}
+
+// GetPartPrices returns the cheapest active listing for each part. Listings
+// in the preferred currency win over cheaper ones in other currencies, since
+// prices in different currencies are not comparable here. Parts without a
+// listing are missing from the result.
+func (s *MarketplaceService) GetPartPrices(partIDs []uuid.UUID, currency string) (map[uuid.UUID]PartPrice, error) {
+	listings, err := s.listingRepo.ListActiveByParts(partIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[uuid.UUID]PartPrice, len(partIDs))
+	for _, listing := range listings {
+		if listing.PartID == nil {
+			continue
+		}
+
+		current, ok := prices[*listing.PartID]
+		if ok && (currency == "" || strings.EqualFold(current.Currency, currency) || !strings.EqualFold(listing.Currency, currency)) {
+			// Listings come cheapest first, so the first in a currency is its best
+			continue
+		}
+
+		prices[*listing.PartID] = PartPrice{
+			PartID:           *listing.PartID,
+			ListingID:        listing.ID,
+			StoreID:          listing.StoreID,
+			StoreName:        listing.Store.Name,
+			Price:            listing.Price,
+			Currency:         listing.Currency,
+			MinOrderQuantity: listing.MinOrderQuantity,
+			LeadTimeDays:     listing.LeadTimeDays,
+			StockQuantity:    listing.StockQuantity,
+		}
+	}
+	return prices, nil
+}
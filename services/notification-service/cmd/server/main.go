@@ -53,6 +53,7 @@ func main() {
 
 	// Start event consumer in background
 	eventConsumer := service.NewEventConsumer(notificationService)
+	eventConsumer.UseUserDirectory(service.NewIdentityClient())
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 
@@ -13,6 +14,7 @@ import (
 // EventConsumer handles events and creates notifications
 type EventConsumer struct {
 	notificationService *NotificationService
+	users               UserDirectory // Resolves the users of supplier companies when set
 }
 
 func NewEventConsumer(notificationService *NotificationService) *EventConsumer {
@@ -21,6 +23,12 @@ func NewEventConsumer(notificationService *NotificationService) *EventConsumer {
 	}
 }
 
+// UseUserDirectory resolves the users of the supplier companies RFQ
+// notifications are addressed to
+func (ec *EventConsumer) UseUserDirectory(users UserDirectory) {
+	ec.users = users
+}
+
 func (ec *EventConsumer) HandleEvent(event *events.EventEnvelope) error {
 	switch event.Type {
 	case events.EventCompanyApproved:
@@ -29,8 +37,20 @@ func (ec *EventConsumer) HandleEvent(event *events.EventEnvelope) error {
 		return ec.handlePartApproved(event)
 	case events.EventPRApproved:
 		return ec.handlePRApproved(event)
+	case events.EventPRBudgetEscalated:
+		return ec.handlePRBudgetEscalated(event)
 	case events.EventRFQCreated:
 		return ec.handleRFQCreated(event)
+	case events.EventRFQSuppliersInvited:
+		return ec.handleRFQSuppliersInvited(event)
+	case events.EventRFQDeclined:
+		return ec.handleRFQDeclined(event)
+	case events.EventRFQAmended:
+		return ec.handleRFQAmended(event)
+	case events.EventRFQQuestionAsked:
+		return ec.handleRFQQuestionAsked(event)
+	case events.EventRFQQuestionAnswered:
+		return ec.handleRFQQuestionAnswered(event)
 	case events.EventQuoteSubmitted:
 		return ec.handleQuoteSubmitted(event)
 	case events.EventOrderPlaced:
@@ -41,6 +61,8 @@ func (ec *EventConsumer) HandleEvent(event *events.EventEnvelope) error {
 		return ec.handleChatMessageSent(event)
 	case events.EventSubscriptionStarted:
 		return ec.handleSubscriptionStarted(event)
+	case events.EventSavedSearchMatched:
+		return ec.handleSavedSearchMatched(event)
 	default:
 		// Unknown event, skip
 		return nil
@@ -101,20 +123,155 @@ func (ec *EventConsumer) handleRFQCreated(event *events.EventEnvelope) error {
 		return fmt.Errorf("tenant_id required")
 	}
 
-	// Notify suppliers (would need to fetch from RFQ in production)
+	return ec.notifyInvitedSuppliers(event, "rfq.created", "New RFQ Available",
+		fmt.Sprintf("New RFQ %s has been created", event.Payload["rfq_number"]))
+}
+
+func (ec *EventConsumer) handleRFQSuppliersInvited(event *events.EventEnvelope) error {
+	if event.TenantID == nil {
+		return fmt.Errorf("tenant_id required")
+	}
+
+	return ec.notifyInvitedSuppliers(event, "rfq.created", "You Have Been Invited to an RFQ",
+		fmt.Sprintf("You have been invited to quote on RFQ %s", event.Payload["rfq_number"]))
+}
+
+// notifyInvitedSuppliers notifies the users of each supplier company in the
+// event's distribution list. A supplier company is its own tenant, so its
+// users read the notification in that tenant rather than the buyer's.
+func (ec *EventConsumer) notifyInvitedSuppliers(event *events.EventEnvelope, notificationType, title, message string) error {
+	supplierIDs := payloadUUIDs(event.Payload["supplier_ids"])
+	if len(supplierIDs) == 0 {
+		log.Printf("RFQ %s has no invited suppliers, skipping notification", event.Payload["rfq_id"])
+		return nil
+	}
+	if ec.users == nil {
+		return fmt.Errorf("no user directory to find the users of RFQ %s's suppliers", event.Payload["rfq_id"])
+	}
+
+	dataJSON, _ := json.Marshal(map[string]interface{}{
+		"rfq_id":     event.Payload["rfq_id"],
+		"rfq_number": event.Payload["rfq_number"],
+	})
+
+	var firstErr error
+	for _, supplierID := range supplierIDs {
+		userIDs, err := ec.users.TenantUsers(supplierID)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to find users of supplier %s: %w", supplierID, err)
+			}
+			continue
+		}
+		if len(userIDs) == 0 {
+			log.Printf("Supplier %s has no active users to notify of RFQ %s", supplierID, event.Payload["rfq_id"])
+		}
+
+		for _, userID := range userIDs {
+			notification := &models.Notification{
+				TenantID: supplierID,
+				UserID:   userID,
+				Channel:  "in_app",
+				Type:     notificationType,
+				Title:    title,
+				Message:  message,
+				Data:     string(dataJSON),
+				Status:   "pending",
+			}
+
+			if err := ec.notificationService.SendNotification(notification); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+func (ec *EventConsumer) handlePRBudgetEscalated(event *events.EventEnvelope) error {
+	if event.TenantID == nil {
+		return fmt.Errorf("tenant_id required")
+	}
+
+	// Only the cost center's budget owner can approve the overrun
+	ownerID, err := uuid.Parse(fmt.Sprint(event.Payload["owner_id"]))
+	if err != nil {
+		log.Printf("PR %s was escalated but its cost center has no budget owner to notify", event.Payload["pr_number"])
+		return nil
+	}
+
+	notification := &models.Notification{
+		TenantID: *event.TenantID,
+		UserID:   ownerID,
+		Channel:  "in_app",
+		Type:     "pr.budget_escalated",
+		Title:    "PR Exceeds Budget",
+		Message: fmt.Sprintf("PR %s requests %v but only %v remains in the budget and needs your approval",
+			event.Payload["pr_number"], event.Payload["amount"], event.Payload["available"]),
+		Status: "pending",
+	}
+
+	return ec.notificationService.SendNotification(notification)
+}
+
+func (ec *EventConsumer) handleRFQDeclined(event *events.EventEnvelope) error {
+	if event.TenantID == nil {
+		return fmt.Errorf("tenant_id required")
+	}
+
 	notification := &models.Notification{
 		TenantID: *event.TenantID,
-		UserID:   uuid.MustParse("00000000-0000-0000-0000-000000000003"), // Supplier user
+		UserID:   uuid.MustParse("00000000-0000-0000-0000-000000000002"), // Buyer user
 		Channel:  "in_app",
-		Type:     "rfq.created",
-		Title:    "New RFQ Available",
-		Message:  fmt.Sprintf("New RFQ %s has been created", event.Payload["rfq_number"]),
+		Type:     "rfq.declined",
+		Title:    "Supplier Declined RFQ",
+		Message:  fmt.Sprintf("A supplier declined to quote on RFQ %s", event.Payload["rfq_id"]),
 		Status:   "pending",
 	}
 
 	return ec.notificationService.SendNotification(notification)
 }
 
+func (ec *EventConsumer) handleRFQAmended(event *events.EventEnvelope) error {
+	if event.TenantID == nil {
+		return fmt.Errorf("tenant_id required")
+	}
+
+	return ec.notifyInvitedSuppliers(event, "rfq.amended", "RFQ Amended",
+		fmt.Sprintf("RFQ %s has been amended, responses are now due %s", event.Payload["rfq_number"], event.Payload["due_date"]))
+}
+
+func (ec *EventConsumer) handleRFQQuestionAsked(event *events.EventEnvelope) error {
+	if event.TenantID == nil {
+		return fmt.Errorf("tenant_id required")
+	}
+
+	notification := &models.Notification{
+		TenantID: *event.TenantID,
+		UserID:   uuid.MustParse("00000000-0000-0000-0000-000000000002"), // Buyer user
+		Channel:  "in_app",
+		Type:     "rfq.question",
+		Title:    "New RFQ Question",
+		Message:  fmt.Sprintf("A supplier asked a question on RFQ %s", event.Payload["rfq_number"]),
+		Status:   "pending",
+	}
+
+	return ec.notificationService.SendNotification(notification)
+}
+
+func (ec *EventConsumer) handleRFQQuestionAnswered(event *events.EventEnvelope) error {
+	if event.TenantID == nil {
+		return fmt.Errorf("tenant_id required")
+	}
+
+	message := "Your question on an RFQ has been answered"
+	if broadcast, _ := event.Payload["broadcast"].(bool); broadcast {
+		message = "A new clarification has been published on an RFQ you are invited to"
+	}
+
+	return ec.notifyInvitedSuppliers(event, "rfq.answer", "RFQ Clarification", message)
+}
+
 func (ec *EventConsumer) handleQuoteSubmitted(event *events.EventEnvelope) error {
 	if event.TenantID == nil {
 		return fmt.Errorf("tenant_id required")
@@ -196,6 +353,65 @@ func (ec *EventConsumer) handleSubscriptionStarted(event *events.EventEnvelope)
 	return ec.notificationService.SendNotification(notification)
 }
 
+// handleSavedSearchMatched alerts a user that a part or listing now matches
+// one of their saved searches, with the match in the notification's data
+func (ec *EventConsumer) handleSavedSearchMatched(event *events.EventEnvelope) error {
+	if event.TenantID == nil {
+		return fmt.Errorf("tenant_id required")
+	}
+	userID, err := uuid.Parse(fmt.Sprint(event.Payload["user_id"]))
+	if err != nil {
+		return fmt.Errorf("user_id required")
+	}
+
+	match, _ := event.Payload["match"].(map[string]interface{})
+	dataJSON, _ := json.Marshal(map[string]interface{}{
+		"saved_search_id": event.Payload["saved_search_id"],
+		"query":           event.Payload["query"],
+		"match":           match,
+	})
+
+	notification := &models.Notification{
+		TenantID: *event.TenantID,
+		UserID:   userID,
+		Channel:  "in_app",
+		Type:     "search.alert",
+		Title:    fmt.Sprintf("New match for %s", event.Payload["saved_search_name"]),
+		Message:  fmt.Sprintf("%s now matches your saved search %s", match["title"], event.Payload["saved_search_name"]),
+		Data:     string(dataJSON),
+		Status:   "pending",
+	}
+
+	return ec.notificationService.SendNotification(notification)
+}
+
+// payloadUUIDs reads a list of UUID strings from a decoded event payload
+func payloadUUIDs(value interface{}) []uuid.UUID {
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case []string:
+		for _, str := range v {
+			items = append(items, str)
+		}
+	default:
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			continue
+		}
+		if id, err := uuid.Parse(str); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // StartEventConsumer starts listening to events
 func (ec *EventConsumer) StartEventConsumer(ctx context.Context, eventBus events.EventBus) error {
 	log.Println("Starting notification event consumer...")
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/b2b-platform/shared/auth"
+	"github.com/google/uuid"
+)
+
+// UserDirectory finds the users of a tenant, such as a supplier company
+// invited to an RFQ, so notifications reach people rather than companies
+type UserDirectory interface {
+	TenantUsers(tenantID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// IdentityClient reads users from identity-service
+type IdentityClient struct {
+	baseURL    string
+	httpClient *http.Client
+	jwtService *auth.JWTService
+}
+
+func NewIdentityClient() *IdentityClient {
+	baseURL := os.Getenv("IDENTITY_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8001"
+	}
+
+	return &IdentityClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		jwtService: auth.NewJWTService(),
+	}
+}
+
+type identityUser struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+	IsActive bool      `json:"is_active"`
+}
+
+// TenantUsers returns the active users of a tenant. Events carry no user
+// token, so the client signs in as the notification service itself.
+func (c *IdentityClient) TenantUsers(tenantID uuid.UUID) ([]uuid.UUID, error) {
+	token, err := c.jwtService.GenerateToken(uuid.Nil, uuid.Nil, "notification-service", []string{"admin"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign service token: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/users?tenant_id=%s", c.baseURL, tenantID)
+	httpReq, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call identity service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("identity service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var users []identityUser
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		// The tenant filter is checked again; a user of another tenant must
+		// never be notified of this one's RFQs
+		if user.IsActive && user.TenantID == tenantID {
+			userIDs = append(userIDs, user.ID)
+		}
+	}
+	return userIDs, nil
+}
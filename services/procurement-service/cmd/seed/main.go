@@ -8,6 +8,7 @@ import (
 	"github.com/b2b-platform/procurement-service/models"
 	"github.com/b2b-platform/procurement-service/repository"
 	"github.com/b2b-platform/shared/database"
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 )
 
@@ -37,7 +38,7 @@ func main() {
 		Priority:    "normal",
 		RequestedBy:  userID,
 		Department:  "Operations",
-		Budget:      5000.00,
+		Budget:      money.MustParse("5000.00"),
 		Currency:    "USD",
 	}
 
@@ -60,8 +61,8 @@ func main() {
 				Description:  "Printer Paper A4",
 				Quantity:     100,
 				Unit:         "ream",
-				UnitPrice:    25.00,
-				TotalPrice:   2500.00,
+				UnitPrice:    money.MustParse("25.00"),
+				TotalPrice:   money.MustParse("2500.00"),
 				Specifications: "80gsm, white",
 			},
 			{
@@ -69,8 +70,8 @@ func main() {
 				Description:  "Office Pens",
 				Quantity:     50,
 				Unit:         "box",
-				UnitPrice:    15.00,
-				TotalPrice:   750.00,
+				UnitPrice:    money.MustParse("15.00"),
+				TotalPrice:   money.MustParse("750.00"),
 			},
 		}
 
@@ -121,7 +122,7 @@ func main() {
 						SupplierID:  supplierID,
 						QuoteNumber: "QT-001",
 						Status:      "submitted",
-						TotalAmount: 3250.00,
+						TotalAmount: money.MustParse("3250.00"),
 						Currency:    "USD",
 						ValidUntil:  time.Now().Add(30 * 24 * time.Hour),
 						Notes:       "Best price guaranteed",
@@ -133,7 +134,7 @@ func main() {
 						SupplierID:  supplierID,
 						QuoteNumber: "QT-002",
 						Status:      "submitted",
-						TotalAmount: 3100.00,
+						TotalAmount: money.MustParse("3100.00"),
 						Currency:    "USD",
 						ValidUntil:  time.Now().Add(30 * 24 * time.Hour),
 						Notes:       "Competitive pricing",
@@ -148,7 +149,7 @@ func main() {
 						SupplierID:  supplierID,
 						QuoteNumber: "QT-003",
 						Status:      "submitted",
-						TotalAmount: 5500.00,
+						TotalAmount: money.MustParse("5500.00"),
 						Currency:    "USD",
 						ValidUntil:  time.Now().Add(30 * 24 * time.Hour),
 						Notes:       "Parts quote",
@@ -171,8 +172,8 @@ func main() {
 						PRItemID:    items[0].ID,
 						Description: "Printer Paper A4",
 						Quantity:    100,
-						UnitPrice:   24.00,
-						TotalPrice:  2400.00,
+						UnitPrice:   money.MustParse("24.00"),
+						TotalPrice:  money.MustParse("2400.00"),
 						LeadTime:    7,
 					},
 					{
@@ -180,8 +181,8 @@ func main() {
 						PRItemID:    items[1].ID,
 						Description: "Office Pens",
 						Quantity:    50,
-						UnitPrice:   14.00,
-						TotalPrice:  700.00,
+						UnitPrice:   money.MustParse("14.00"),
+						TotalPrice:  money.MustParse("700.00"),
 						LeadTime:    5,
 					},
 				}
@@ -201,7 +202,7 @@ func main() {
 						QuoteID:       quote.ID,
 						PONumber:      "PO-001",
 						Status:        "pending",
-						TotalAmount:   3100.00,
+						TotalAmount:   money.MustParse("3100.00"),
 						Currency:      "USD",
 						PaymentMode:   "DIRECT",
 						PaymentStatus: "succeeded",
@@ -221,16 +222,16 @@ func main() {
 								PRItemID:    items[0].ID,
 								Description: "Printer Paper A4",
 								Quantity:    100,
-								UnitPrice:   24.00,
-								TotalPrice:  2400.00,
+								UnitPrice:   money.MustParse("24.00"),
+								TotalPrice:  money.MustParse("2400.00"),
 							},
 							{
 								POID:        po.ID,
 								PRItemID:    items[1].ID,
 								Description: "Office Pens",
 								Quantity:    50,
-								UnitPrice:   14.00,
-								TotalPrice:  700.00,
+								UnitPrice:   money.MustParse("14.00"),
+								TotalPrice:  money.MustParse("700.00"),
 							},
 						}
 
@@ -253,7 +254,7 @@ func main() {
 				RFQID:         rfqs[1].ID,
 				PONumber:      "PO-002",
 				Status:        "pending",
-				TotalAmount:   5500.00,
+				TotalAmount:   money.MustParse("5500.00"),
 				Currency:      "USD",
 				PaymentMode:   "DIRECT",
 				PaymentStatus: "pending",
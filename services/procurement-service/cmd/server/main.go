@@ -16,6 +16,7 @@ import (
 	"github.com/b2b-platform/shared/database"
 	"github.com/b2b-platform/shared/events"
 	"github.com/b2b-platform/shared/redis"
+	"github.com/b2b-platform/shared/tax"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
@@ -41,9 +42,17 @@ func main() {
 	rfqRepo := repository.NewRFQRepository(db)
 	quoteRepo := repository.NewQuoteRepository(db)
 	poRepo := repository.NewPORepository(db)
-
-	procurementService := service.NewProcurementService(prRepo, rfqRepo, quoteRepo, poRepo, eventBus)
+	invitationRepo := repository.NewInvitationRepository(db)
+	qualifiedSupplierRepo := repository.NewQualifiedSupplierRepository(db)
+	budgetRepo := repository.NewBudgetRepository(db)
+	taxRepo := repository.NewTaxRepository(db)
+
+	budgetService := service.NewBudgetService(budgetRepo, service.NewBillingClient())
+	taxService := service.NewTaxService(tax.NewRuleEngine(taxRepo), taxRepo, service.NewCompanyClient())
+	procurementService := service.NewProcurementService(prRepo, rfqRepo, quoteRepo, poRepo, invitationRepo, qualifiedSupplierRepo, budgetService, taxService, eventBus)
 	procurementHandler := handlers.NewProcurementHandler(procurementService)
+	budgetHandler := handlers.NewBudgetHandler(budgetService)
+	taxHandler := handlers.NewTaxHandler(taxService)
 
 	r := gin.Default()
 
@@ -90,19 +99,55 @@ func main() {
 		api.POST("/purchase-requests", procurementHandler.CreatePR)
 		api.PUT("/purchase-requests/:id", procurementHandler.UpdatePR)
 		api.POST("/purchase-requests/:id/approve", procurementHandler.ApprovePR)
+		api.POST("/purchase-requests/:id/approve-overrun", procurementHandler.ApproveBudgetOverrun)
+		api.GET("/purchase-requests/:id/budget-check", procurementHandler.CheckPRBudget)
 		
 		// RFQ endpoints
 		api.POST("/rfqs", procurementHandler.CreateRFQ)
 		api.GET("/rfqs/:id", procurementHandler.GetRFQ)
+		api.POST("/rfqs/:id/invitations", procurementHandler.InviteSuppliers)
+		api.GET("/rfqs/:id/responses", procurementHandler.GetRFQResponses)
+		api.GET("/rfqs/:id/quote-comparison", procurementHandler.CompareQuotes)
+		api.GET("/rfqs/:id/amendments", procurementHandler.GetRFQAmendments)
+		api.POST("/rfqs/:id/amendments", procurementHandler.AmendRFQ)
+		api.GET("/rfqs/suggested-suppliers", procurementHandler.SuggestSuppliers)
+
+		// Supplier-side RFQ endpoints
+		api.GET("/supplier/rfqs", procurementHandler.ListSupplierRFQs)
+		api.GET("/supplier/rfqs/:id", procurementHandler.GetSupplierRFQ)
+		api.POST("/supplier/rfqs/:id/decline", procurementHandler.DeclineRFQ)
+
+		// Qualified supplier endpoints
+		api.GET("/qualified-suppliers", procurementHandler.ListQualifiedSuppliers)
+		api.POST("/qualified-suppliers", procurementHandler.CreateQualifiedSupplier)
 		
 		// Quote endpoints
 		api.POST("/quotes", procurementHandler.SubmitQuote)
 		api.GET("/quotes/:id", procurementHandler.GetQuote)
 		
 		// PO endpoints
+		api.GET("/purchase-orders", procurementHandler.ListPOs)
 		api.POST("/purchase-orders", procurementHandler.CreatePO)
 		api.GET("/purchase-orders/:id", procurementHandler.GetPO)
 		api.PUT("/purchase-orders/:id/payment-status", procurementHandler.UpdatePOPaymentStatus)
+		api.POST("/purchase-orders/:id/actuals", procurementHandler.RecordPOActual)
+
+		// Budget control
+		api.GET("/cost-centers", budgetHandler.ListCostCenters)
+		api.POST("/cost-centers", budgetHandler.CreateCostCenter)
+		api.GET("/gl-accounts", budgetHandler.ListGLAccounts)
+		api.POST("/gl-accounts", budgetHandler.CreateGLAccount)
+		api.POST("/budgets", budgetHandler.CreateBudget)
+		api.GET("/budgets/report", budgetHandler.GetBudgetReport)
+		api.GET("/budgets/:id", budgetHandler.GetBudget)
+		api.GET("/budgets/:id/entries", budgetHandler.GetBudgetEntries)
+
+		// Tax
+		api.GET("/tax-rules", taxHandler.ListTaxRules)
+		api.POST("/tax-rules", taxHandler.CreateTaxRule)
+		api.DELETE("/tax-rules/:id", taxHandler.DeleteTaxRule)
+		api.POST("/tax/calculate", taxHandler.CalculateTax)
+		api.GET("/tax-lines", taxHandler.GetTaxLines)
 	}
 
 	port := os.Getenv("PORT")
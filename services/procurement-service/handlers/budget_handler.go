@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/b2b-platform/procurement-service/models"
+	"github.com/b2b-platform/procurement-service/service"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BudgetHandler struct {
+	service *service.BudgetService
+}
+
+func NewBudgetHandler(service *service.BudgetService) *BudgetHandler {
+	return &BudgetHandler{service: service}
+}
+
+func (h *BudgetHandler) CreateCostCenter(c *gin.Context) {
+	var costCenter models.CostCenter
+	if err := c.ShouldBindJSON(&costCenter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+	costCenter.TenantID = tenantID
+
+	if err := h.service.CreateCostCenter(&costCenter); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, costCenter)
+}
+
+func (h *BudgetHandler) ListCostCenters(c *gin.Context) {
+	tenantID, _ := auth.GetTenantID(c)
+
+	costCenters, err := h.service.ListCostCenters(tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, costCenters)
+}
+
+func (h *BudgetHandler) CreateGLAccount(c *gin.Context) {
+	var account models.GLAccount
+	if err := c.ShouldBindJSON(&account); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+	account.TenantID = tenantID
+
+	if err := h.service.CreateGLAccount(&account); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+func (h *BudgetHandler) ListGLAccounts(c *gin.Context) {
+	tenantID, _ := auth.GetTenantID(c)
+
+	accounts, err := h.service.ListGLAccounts(tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	var budget models.Budget
+	if err := c.ShouldBindJSON(&budget); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+	userID, _ := auth.GetUserID(c)
+
+	budget.TenantID = tenantID
+	budget.CreatedBy = userID
+
+	if err := h.service.CreateBudget(&budget); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, budget)
+}
+
+func (h *BudgetHandler) GetBudget(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	budget, err := h.service.GetBudget(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+func (h *BudgetHandler) GetBudgetEntries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	entries, err := h.service.GetBudgetEntries(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetBudgetReport returns budget vs committed, encumbered and actual spend.
+// Query params: from, to (YYYY-MM-DD) and cost_center_id.
+func (h *BudgetHandler) GetBudgetReport(c *gin.Context) {
+	tenantID, _ := auth.GetTenantID(c)
+
+	var from, to time.Time
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date"})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date"})
+			return
+		}
+	}
+
+	var costCenterID *uuid.UUID
+	if v := c.Query("cost_center_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cost_center_id"})
+			return
+		}
+		costCenterID = &id
+	}
+
+	report, err := h.service.Report(tenantID, costCenterID, from, to, requestToken(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/b2b-platform/procurement-service/models"
 	"github.com/b2b-platform/procurement-service/service"
 	"github.com/b2b-platform/shared/auth"
+	"github.com/b2b-platform/shared/money"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -101,14 +103,72 @@ func (h *ProcurementHandler) ApprovePR(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.ApprovePR(id, uuid.New()); err != nil {
+	userID, _ := auth.GetUserID(c)
+
+	if err := h.service.ApprovePR(id, userID, requestToken(c)); err != nil {
+		writeApprovalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "PR approved"})
+}
+
+func (h *ProcurementHandler) CheckPRBudget(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	check, err := h.service.CheckPRBudget(id, requestToken(c))
+	if err != nil {
+		if errors.Is(err, service.ErrBudgetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if check == nil {
+		c.JSON(http.StatusOK, gin.H{"decision": service.BudgetDecisionApprove, "message": "PR has no cost center"})
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+// ApproveBudgetOverrun is used by the budget owner to release an escalated PR
+func (h *ProcurementHandler) ApproveBudgetOverrun(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	if err := h.service.ApproveBudgetOverrun(id, userID, requestToken(c)); err != nil {
+		writeApprovalError(c, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "PR approved"})
 }
 
+func writeApprovalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrBudgetEscalated):
+		c.JSON(http.StatusAccepted, gin.H{"message": err.Error(), "status": "budget_escalated"})
+	case errors.Is(err, service.ErrBudgetExceeded), errors.Is(err, service.ErrBudgetNotFound),
+		errors.Is(err, service.ErrPRNotApprovable), errors.Is(err, service.ErrPRNotEscalated):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotBudgetOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func (h *ProcurementHandler) CreateRFQ(c *gin.Context) {
 	var rfq models.RFQ
 	if err := c.ShouldBindJSON(&rfq); err != nil {
@@ -153,11 +213,20 @@ func (h *ProcurementHandler) SubmitQuote(c *gin.Context) {
 		return
 	}
 
+	// The quoting supplier is the caller's company, as for declines
 	tenantID, _ := auth.GetTenantID(c)
 	quote.TenantID = tenantID
-
-	if err := h.service.SubmitQuote(&quote); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	quote.SupplierID = tenantID
+
+	if err := h.service.SubmitQuote(&quote, requestToken(c)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrSupplierNotInvited):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrRFQNotOpen), errors.Is(err, service.ErrAlreadyResponded):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			writeServiceError(c, err)
+		}
 		return
 	}
 
@@ -193,21 +262,36 @@ func (h *ProcurementHandler) CreatePO(c *gin.Context) {
 	po.TenantID = tenantID
 	po.CreatedBy = userID
 
-	// Get auth token from header
-	authToken := c.GetHeader("Authorization")
-	if authToken == "" {
-		// Try to get from context if available
-		if token, exists := c.Get("token"); exists {
-			authToken = token.(string)
+	if err := h.service.CreatePO(&po, requestToken(c)); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, po)
+}
+
+// ListPOs returns the tenant's purchase orders.
+// Query params: bom_revision_id, to list orders sourced against a BOM revision.
+func (h *ProcurementHandler) ListPOs(c *gin.Context) {
+	tenantID, _ := auth.GetTenantID(c)
+
+	var bomRevisionID *uuid.UUID
+	if raw := c.Query("bom_revision_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bom_revision_id"})
+			return
 		}
+		bomRevisionID = &id
 	}
 
-	if err := h.service.CreatePO(&po, authToken); err != nil {
+	pos, err := h.service.ListPOs(tenantID, bomRevisionID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, po)
+	c.JSON(http.StatusOK, pos)
 }
 
 func (h *ProcurementHandler) GetPO(c *gin.Context) {
@@ -256,3 +340,324 @@ func (h *ProcurementHandler) UpdatePOPaymentStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, po)
 }
+
+func (h *ProcurementHandler) InviteSuppliers(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		SupplierIDs []uuid.UUID `json:"supplier_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+	userID, _ := auth.GetUserID(c)
+
+	invitations, err := h.service.InviteSuppliers(id, tenantID, req.SupplierIDs, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRFQNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		case errors.Is(err, service.ErrRFQNotOpen):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+func (h *ProcurementHandler) GetRFQResponses(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+
+	summary, err := h.service.GetRFQResponses(id, tenantID)
+	if err != nil {
+		if errors.Is(err, service.ErrRFQNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *ProcurementHandler) SuggestSuppliers(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Query("category_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category_id"})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+
+	supplierIDs, err := h.service.SuggestSuppliers(tenantID, categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"supplier_ids": supplierIDs})
+}
+
+func (h *ProcurementHandler) AmendRFQ(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var amendment models.RFQAmendment
+	if err := c.ShouldBindJSON(&amendment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+	userID, _ := auth.GetUserID(c)
+	amendment.CreatedBy = userID
+
+	if err := h.service.AmendRFQ(id, tenantID, &amendment); err != nil {
+		switch {
+		case errors.Is(err, service.ErrRFQNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		case errors.Is(err, service.ErrRFQNotOpen):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidDueDate):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, amendment)
+}
+
+func (h *ProcurementHandler) GetRFQAmendments(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+
+	amendments, err := h.service.GetRFQAmendments(id, tenantID)
+	if err != nil {
+		if errors.Is(err, service.ErrRFQNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, amendments)
+}
+
+// Supplier-side RFQ endpoints. The supplier is identified by the caller's tenant.
+
+func (h *ProcurementHandler) ListSupplierRFQs(c *gin.Context) {
+	supplierID, _ := auth.GetTenantID(c)
+
+	invitations, err := h.service.ListSupplierRFQs(supplierID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+func (h *ProcurementHandler) GetSupplierRFQ(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	supplierID, _ := auth.GetTenantID(c)
+
+	view, err := h.service.GetSupplierRFQ(id, supplierID)
+	if err != nil {
+		if errors.Is(err, service.ErrSupplierNotInvited) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, view)
+}
+
+func (h *ProcurementHandler) DeclineRFQ(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	supplierID, _ := auth.GetTenantID(c)
+
+	invitation, err := h.service.DeclineRFQ(id, supplierID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSupplierNotInvited):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		case errors.Is(err, service.ErrAlreadyResponded), errors.Is(err, service.ErrRFQNotOpen):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
+
+func (h *ProcurementHandler) CreateQualifiedSupplier(c *gin.Context) {
+	var qs models.QualifiedSupplier
+	if err := c.ShouldBindJSON(&qs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+	userID, _ := auth.GetUserID(c)
+
+	qs.TenantID = tenantID
+	qs.QualifiedBy = userID
+
+	if err := h.service.CreateQualifiedSupplier(&qs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, qs)
+}
+
+func (h *ProcurementHandler) ListQualifiedSuppliers(c *gin.Context) {
+	tenantID, _ := auth.GetTenantID(c)
+
+	var categoryID *uuid.UUID
+	if raw := c.Query("category_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category_id"})
+			return
+		}
+		categoryID = &id
+	}
+
+	suppliers, err := h.service.ListQualifiedSuppliers(tenantID, categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, suppliers)
+}
+
+// RecordPOActual books a goods receipt or invoice against the PO's budget.
+// The amount is net of tax; invoices respond with their tax lines.
+func (h *ProcurementHandler) RecordPOActual(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		SourceType string        `json:"source_type" binding:"required"`
+		SourceID   uuid.UUID     `json:"source_id" binding:"required"`
+		Amount     money.Decimal `json:"amount" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := auth.GetUserID(c)
+
+	taxLines, err := h.service.RecordPOActual(id, req.SourceType, req.SourceID, req.Amount, userID, requestToken(c))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidActual) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		writeServiceError(c, err)
+		return
+	}
+
+	response := gin.H{"message": "actual recorded"}
+	if taxLines != nil {
+		response["tax_lines"] = taxLines
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CompareQuotes ranks the quotes on an RFQ by their total in the tenant's base currency
+func (h *ProcurementHandler) CompareQuotes(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+
+	comparison, err := h.service.CompareQuotes(id, tenantID, requestToken(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// writeServiceError reports a failed request, as 503 when it failed because
+// billing-service is down so the client knows to retry
+func writeServiceError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrBillingUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": service.ErrBillingUnavailable.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// requestToken returns the caller's token for forwarding to other services
+func requestToken(c *gin.Context) string {
+	authToken := c.GetHeader("Authorization")
+	if authToken == "" {
+		// Try to get from context if available
+		if token, exists := c.Get("token"); exists {
+			authToken = token.(string)
+		}
+	}
+	return authToken
+}
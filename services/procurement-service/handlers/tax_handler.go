@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/b2b-platform/procurement-service/models"
+	"github.com/b2b-platform/procurement-service/service"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/b2b-platform/shared/tax"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TaxHandler struct {
+	service *service.TaxService
+}
+
+func NewTaxHandler(service *service.TaxService) *TaxHandler {
+	return &TaxHandler{service: service}
+}
+
+// CreateTaxRule adds a tax rule. Rules apply to every tenant, so only
+// platform admins may change them.
+func (h *TaxHandler) CreateTaxRule(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	var rule models.TaxRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.CreateRule(&rule); err != nil {
+		if errors.Is(err, service.ErrInvalidTaxRule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// ListTaxRules lists tax rules, optionally filtered by destination_country
+func (h *TaxHandler) ListTaxRules(c *gin.Context) {
+	rules, err := h.service.ListRules(c.Query("destination_country"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// DeleteTaxRule removes a tax rule. Admin only.
+func (h *TaxHandler) DeleteTaxRule(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.service.DeleteRule(id); err != nil {
+		if errors.Is(err, service.ErrTaxRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tax rule deleted"})
+}
+
+// CalculateTax previews the tax on an ad-hoc set of lines
+func (h *TaxHandler) CalculateTax(c *gin.Context) {
+	var req tax.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.Calculate(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetTaxLines returns the stored tax lines of a document.
+// Query params: document_type (quote, po, invoice) and document_id.
+func (h *TaxHandler) GetTaxLines(c *gin.Context) {
+	documentType := c.Query("document_type")
+	switch documentType {
+	case models.TaxDocumentQuote, models.TaxDocumentPO, models.TaxDocumentInvoice:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document_type"})
+		return
+	}
+
+	documentID, err := uuid.Parse(c.Query("document_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document_id"})
+		return
+	}
+
+	lines, err := h.service.GetDocumentTaxLines(documentType, documentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, lines)
+}
@@ -0,0 +1,44 @@
+-- Add catalog category to RFQs for supplier suggestions
+ALTER TABLE procurement.rfqs
+ADD COLUMN IF NOT EXISTS category_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_rfqs_category_id ON procurement.rfqs(category_id);
+
+-- RFQ Invitations table (per-supplier distribution list)
+CREATE TABLE IF NOT EXISTS procurement.rfq_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    rfq_id UUID NOT NULL REFERENCES procurement.rfqs(id) ON DELETE CASCADE,
+    supplier_id UUID NOT NULL,
+    source VARCHAR(50) DEFAULT 'manual',
+    status VARCHAR(50) DEFAULT 'invited',
+    decline_reason TEXT,
+    invited_by UUID NOT NULL,
+    viewed_at TIMESTAMP,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_rfq_supplier UNIQUE (rfq_id, supplier_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rfq_invitations_tenant_id ON procurement.rfq_invitations(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_rfq_invitations_supplier_id ON procurement.rfq_invitations(supplier_id);
+CREATE INDEX IF NOT EXISTS idx_rfq_invitations_status ON procurement.rfq_invitations(status);
+
+-- Qualified Suppliers table (approved vendor list by catalog category)
+CREATE TABLE IF NOT EXISTS procurement.qualified_suppliers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    supplier_id UUID NOT NULL,
+    category_id UUID NOT NULL,
+    status VARCHAR(50) DEFAULT 'qualified',
+    notes TEXT,
+    qualified_by UUID NOT NULL,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_tenant_supplier_category UNIQUE (tenant_id, supplier_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_qualified_suppliers_category_id ON procurement.qualified_suppliers(category_id);
+CREATE INDEX IF NOT EXISTS idx_qualified_suppliers_status ON procurement.qualified_suppliers(status);
@@ -0,0 +1,17 @@
+-- RFQ Amendments table
+CREATE TABLE IF NOT EXISTS procurement.rfq_amendments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    rfq_id UUID NOT NULL REFERENCES procurement.rfqs(id) ON DELETE CASCADE,
+    amendment_number INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    previous_due_date TIMESTAMP,
+    new_due_date TIMESTAMP,
+    reference_id UUID,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_rfq_amendment UNIQUE (rfq_id, amendment_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rfq_amendments_tenant_id ON procurement.rfq_amendments(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_rfq_amendments_reference_id ON procurement.rfq_amendments(reference_id);
@@ -0,0 +1,93 @@
+-- Cost Centers table
+CREATE TABLE IF NOT EXISTS procurement.cost_centers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    department VARCHAR(100),
+    owner_id UUID,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    CONSTRAINT idx_tenant_cost_center UNIQUE (tenant_id, code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cost_centers_deleted_at ON procurement.cost_centers(deleted_at);
+
+-- GL Accounts table
+CREATE TABLE IF NOT EXISTS procurement.gl_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    account_type VARCHAR(50) DEFAULT 'expense',
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    CONSTRAINT idx_tenant_gl_account UNIQUE (tenant_id, code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gl_accounts_deleted_at ON procurement.gl_accounts(deleted_at);
+
+-- Budgets table
+CREATE TABLE IF NOT EXISTS procurement.budgets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    cost_center_id UUID NOT NULL REFERENCES procurement.cost_centers(id),
+    gl_account_id UUID REFERENCES procurement.gl_accounts(id),
+    name VARCHAR(255),
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    currency VARCHAR(10) DEFAULT 'USD',
+    overrun_policy VARCHAR(50) DEFAULT 'block',
+    tolerance_percent DECIMAL(5,2) DEFAULT 0,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_budgets_tenant_id ON procurement.budgets(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_budgets_cost_center_id ON procurement.budgets(cost_center_id);
+CREATE INDEX IF NOT EXISTS idx_budgets_gl_account_id ON procurement.budgets(gl_account_id);
+CREATE INDEX IF NOT EXISTS idx_budgets_period ON procurement.budgets(period_start, period_end);
+CREATE INDEX IF NOT EXISTS idx_budgets_deleted_at ON procurement.budgets(deleted_at);
+
+-- Budget Entries table (commitment -> encumbrance -> actual ledger)
+CREATE TABLE IF NOT EXISTS procurement.budget_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    budget_id UUID NOT NULL REFERENCES procurement.budgets(id) ON DELETE CASCADE,
+    entry_type VARCHAR(50) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    source_type VARCHAR(50) NOT NULL,
+    source_id UUID NOT NULL,
+    pr_id UUID,
+    po_id UUID,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_entries_tenant_id ON procurement.budget_entries(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_budget_entries_budget_id ON procurement.budget_entries(budget_id);
+CREATE INDEX IF NOT EXISTS idx_budget_entries_entry_type ON procurement.budget_entries(entry_type);
+CREATE INDEX IF NOT EXISTS idx_budget_entry_source ON procurement.budget_entries(source_type, source_id);
+CREATE INDEX IF NOT EXISTS idx_budget_entries_pr_id ON procurement.budget_entries(pr_id);
+CREATE INDEX IF NOT EXISTS idx_budget_entries_po_id ON procurement.budget_entries(po_id);
+
+-- Link purchase requests to cost centers, GL accounts and the budget holding their commitment
+ALTER TABLE procurement.purchase_requests
+ADD COLUMN IF NOT EXISTS cost_center_id UUID;
+
+ALTER TABLE procurement.purchase_requests
+ADD COLUMN IF NOT EXISTS gl_account_id UUID;
+
+ALTER TABLE procurement.purchase_requests
+ADD COLUMN IF NOT EXISTS budget_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_pr_cost_center_id ON procurement.purchase_requests(cost_center_id);
+CREATE INDEX IF NOT EXISTS idx_pr_gl_account_id ON procurement.purchase_requests(gl_account_id);
+CREATE INDEX IF NOT EXISTS idx_pr_budget_id ON procurement.purchase_requests(budget_id);
@@ -0,0 +1,22 @@
+-- FX rate frozen on each PO when it is issued
+ALTER TABLE procurement.purchase_orders
+ADD COLUMN IF NOT EXISTS base_currency VARCHAR(10);
+
+ALTER TABLE procurement.purchase_orders
+ADD COLUMN IF NOT EXISTS fx_rate NUMERIC(24,12);
+
+ALTER TABLE procurement.purchase_orders
+ADD COLUMN IF NOT EXISTS fx_rate_date DATE;
+
+ALTER TABLE procurement.purchase_orders
+ADD COLUMN IF NOT EXISTS base_amount DECIMAL(15,2);
+
+-- Budget entries keep the source document amount and the rate used to convert it
+ALTER TABLE procurement.budget_entries
+ADD COLUMN IF NOT EXISTS source_amount DECIMAL(15,2);
+
+ALTER TABLE procurement.budget_entries
+ADD COLUMN IF NOT EXISTS source_currency VARCHAR(10);
+
+ALTER TABLE procurement.budget_entries
+ADD COLUMN IF NOT EXISTS fx_rate NUMERIC(24,12);
@@ -0,0 +1,53 @@
+-- Tax rates by destination country, optionally narrowed by origin country and product category
+CREATE TABLE IF NOT EXISTS procurement.tax_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    destination_country VARCHAR(100) NOT NULL,
+    origin_country VARCHAR(100),
+    category VARCHAR(100),
+    tax_type VARCHAR(20) NOT NULL,
+    rate NUMERIC(7,4) NOT NULL,
+    valid_from DATE NOT NULL,
+    valid_to DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_rules_destination ON procurement.tax_rules(destination_country);
+
+-- Tax calculated per document line. document_type is quote, po or invoice.
+CREATE TABLE IF NOT EXISTS procurement.tax_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    document_type VARCHAR(20) NOT NULL,
+    document_id UUID NOT NULL,
+    line_id UUID,
+    category VARCHAR(100),
+    tax_type VARCHAR(20),
+    jurisdiction VARCHAR(100),
+    rate NUMERIC(7,4),
+    taxable_amount DECIMAL(15,2),
+    tax_amount DECIMAL(15,2),
+    currency VARCHAR(10),
+    treatment VARCHAR(30),
+    exemption_certificate VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_line_document ON procurement.tax_lines(document_type, document_id);
+
+ALTER TABLE procurement.quote_items
+ADD COLUMN IF NOT EXISTS tax_category VARCHAR(100);
+
+ALTER TABLE procurement.po_items
+ADD COLUMN IF NOT EXISTS tax_category VARCHAR(100);
+
+ALTER TABLE procurement.quotes
+ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15,2) DEFAULT 0;
+
+ALTER TABLE procurement.quotes
+ADD COLUMN IF NOT EXISTS gross_amount DECIMAL(15,2);
+
+ALTER TABLE procurement.purchase_orders
+ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15,2) DEFAULT 0;
+
+ALTER TABLE procurement.purchase_orders
+ADD COLUMN IF NOT EXISTS gross_amount DECIMAL(15,2);
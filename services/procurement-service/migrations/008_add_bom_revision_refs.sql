@@ -0,0 +1,14 @@
+-- Purchase requests sourced from an equipment BOM, and the POs raised from
+-- them, keep the BOM revision they were sourced against
+ALTER TABLE procurement.purchase_requests
+ADD COLUMN IF NOT EXISTS equipment_id UUID;
+
+ALTER TABLE procurement.purchase_requests
+ADD COLUMN IF NOT EXISTS bom_revision_id UUID;
+
+ALTER TABLE procurement.purchase_orders
+ADD COLUMN IF NOT EXISTS bom_revision_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_purchase_requests_equipment_id ON procurement.purchase_requests(equipment_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_requests_bom_revision_id ON procurement.purchase_requests(bom_revision_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_orders_bom_revision_id ON procurement.purchase_orders(bom_revision_id);
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Budget entry types. Spend moves from commitment (approved PR) to encumbrance
+// (issued PO) to actual (goods receipt or invoice).
+const (
+	BudgetEntryCommitment  = "commitment"
+	BudgetEntryEncumbrance = "encumbrance"
+	BudgetEntryActual      = "actual"
+)
+
+type CostCenter struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID   uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_cost_center" json:"tenant_id"`
+	Code       string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_tenant_cost_center" json:"code"`
+	Name       string         `gorm:"type:varchar(255);not null" json:"name"`
+	Department string         `gorm:"type:varchar(100)" json:"department"`
+	OwnerID    *uuid.UUID     `gorm:"type:uuid" json:"owner_id,omitempty"` // Budget owner who can approve overruns
+	IsActive   bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (CostCenter) TableName() string {
+	return "procurement.cost_centers"
+}
+
+type GLAccount struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID    uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_gl_account" json:"tenant_id"`
+	Code        string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_tenant_gl_account" json:"code"`
+	Name        string         `gorm:"type:varchar(255);not null" json:"name"`
+	AccountType string         `gorm:"type:varchar(50);default:'expense'" json:"account_type"` // expense, capex
+	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (GLAccount) TableName() string {
+	return "procurement.gl_accounts"
+}
+
+// Budget is the amount available to a cost center (optionally per GL account)
+// for one period
+type Budget struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID         uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	CostCenterID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"cost_center_id"`
+	GLAccountID      *uuid.UUID     `gorm:"type:uuid;index" json:"gl_account_id,omitempty"`
+	Name             string         `gorm:"type:varchar(255)" json:"name"`
+	PeriodStart      time.Time      `gorm:"not null;index" json:"period_start"`
+	PeriodEnd        time.Time      `gorm:"not null;index" json:"period_end"`
+	Amount           money.Decimal  `gorm:"not null" json:"amount"`
+	Currency         string         `gorm:"type:varchar(10);default:'USD'" json:"currency"`
+	OverrunPolicy    string         `gorm:"type:varchar(50);default:'block'" json:"overrun_policy"` // block, escalate, allow
+	TolerancePercent float64        `gorm:"default:0" json:"tolerance_percent"`
+	CreatedBy        uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	CostCenter CostCenter `gorm:"foreignKey:CostCenterID" json:"cost_center,omitempty"`
+	GLAccount  *GLAccount `gorm:"foreignKey:GLAccountID" json:"gl_account,omitempty"`
+}
+
+func (Budget) TableName() string {
+	return "procurement.budgets"
+}
+
+// BudgetEntry is one movement against a budget. Amounts are signed so that a
+// commitment released when a PO is issued is recorded as a negative commitment.
+// Amount is in the budget's currency; the source document's own amount and the
+// rate used to convert it are kept alongside.
+type BudgetEntry struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID       uuid.UUID     `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	BudgetID       uuid.UUID     `gorm:"type:uuid;not null;index" json:"budget_id"`
+	EntryType      string        `gorm:"type:varchar(50);not null;index" json:"entry_type"` // commitment, encumbrance, actual
+	Amount         money.Decimal `gorm:"not null" json:"amount"`
+	SourceAmount   money.Decimal `json:"source_amount"`
+	SourceCurrency string        `gorm:"type:varchar(10)" json:"source_currency"`
+	FXRate         money.Decimal `gorm:"type:numeric(24,12)" json:"fx_rate"`
+	SourceType     string        `gorm:"type:varchar(50);not null;index:idx_budget_entry_source" json:"source_type"` // pr, po, receipt, invoice
+	SourceID       uuid.UUID     `gorm:"type:uuid;not null;index:idx_budget_entry_source" json:"source_id"`
+	PRID           *uuid.UUID    `gorm:"type:uuid;index" json:"pr_id,omitempty"`
+	POID           *uuid.UUID    `gorm:"type:uuid;index" json:"po_id,omitempty"`
+	CreatedBy      uuid.UUID     `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+func (BudgetEntry) TableName() string {
+	return "procurement.budget_entries"
+}
+
+// BudgetBalance summarizes the entries recorded against a budget
+type BudgetBalance struct {
+	BudgetID   uuid.UUID     `json:"budget_id"`
+	Committed  money.Decimal `json:"committed"`
+	Encumbered money.Decimal `json:"encumbered"`
+	Actual     money.Decimal `json:"actual"`
+}
+
+// Consumed is everything that has been reserved or spent against the budget
+func (b BudgetBalance) Consumed() money.Decimal {
+	return money.Sum(b.Committed, b.Encumbered, b.Actual)
+}
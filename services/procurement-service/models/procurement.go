@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -13,13 +14,19 @@ type PurchaseRequest struct {
 	PRNumber    string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_tenant_pr" json:"pr_number"`
 	Title       string         `gorm:"type:varchar(255);not null" json:"title"`
 	Description string         `gorm:"type:text" json:"description"`
-	Status      string         `gorm:"type:varchar(50);default:'draft';index" json:"status"` // draft, submitted, approved, rejected, cancelled
+	Status      string         `gorm:"type:varchar(50);default:'draft';index" json:"status"` // draft, submitted, budget_escalated, approved, rejected, cancelled
 	Priority    string         `gorm:"type:varchar(50);default:'normal'" json:"priority"` // low, normal, high, urgent
 	RequestedBy uuid.UUID      `gorm:"type:uuid;not null" json:"requested_by"`
 	Department  string         `gorm:"type:varchar(100)" json:"department"`
-	Budget      float64        `json:"budget"`
+	Budget      money.Decimal  `json:"budget"`
+	CostCenterID *uuid.UUID    `gorm:"type:uuid;index" json:"cost_center_id,omitempty"`
+	GLAccountID  *uuid.UUID    `gorm:"type:uuid;index" json:"gl_account_id,omitempty"`
+	BudgetID     *uuid.UUID    `gorm:"type:uuid;index" json:"budget_id,omitempty"` // Budget holding this PR's commitment
 	Currency    string         `gorm:"type:varchar(10);default:'USD'" json:"currency"`
 	RequiredDate *time.Time    `json:"required_date,omitempty"`
+	// Set when the PR was sourced from an equipment BOM
+	EquipmentID   *uuid.UUID   `gorm:"type:uuid;index" json:"equipment_id,omitempty"`
+	BOMRevisionID *uuid.UUID   `gorm:"type:uuid;index" json:"bom_revision_id,omitempty"`
 	ApprovedAt  *time.Time     `json:"approved_at,omitempty"`
 	ApprovedBy  *uuid.UUID     `json:"approved_by,omitempty"`
 	CreatedAt   time.Time      `json:"created_at"`
@@ -43,8 +50,8 @@ type PRItem struct {
 	Description string    `gorm:"type:text;not null" json:"description"`
 	Quantity    float64   `gorm:"not null" json:"quantity"`
 	Unit        string    `gorm:"type:varchar(50)" json:"unit"`
-	UnitPrice   float64   `json:"unit_price"`
-	TotalPrice  float64   `json:"total_price"`
+	UnitPrice   money.Decimal `json:"unit_price"`
+	TotalPrice  money.Decimal `json:"total_price"`
 	Specifications string `gorm:"type:text" json:"specifications"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -61,7 +68,7 @@ type PRApproval struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	PRID      uuid.UUID `gorm:"type:uuid;not null;index" json:"pr_id"`
 	ApproverID uuid.UUID `gorm:"type:uuid;not null" json:"approver_id"`
-	Status    string    `gorm:"type:varchar(50);default:'pending';index" json:"status"` // pending, approved, rejected
+	Status    string    `gorm:"type:varchar(50);default:'pending';index" json:"status"` // pending, approved, rejected, escalated
 	Comments  string    `gorm:"type:text" json:"comments"`
 	ApprovedAt *time.Time `json:"approved_at,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
@@ -84,19 +91,85 @@ type RFQ struct {
 	Description string    `gorm:"type:text" json:"description"`
 	Status      string    `gorm:"type:varchar(50);default:'draft';index" json:"status"` // draft, sent, closed, cancelled
 	DueDate     time.Time `gorm:"not null" json:"due_date"`
+	CategoryID  *uuid.UUID `gorm:"type:uuid;index" json:"category_id,omitempty"` // Catalog category used to suggest suppliers
 	CreatedBy   uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
+	// SupplierIDs is only used on create to pass the initial distribution list
+	SupplierIDs []uuid.UUID `gorm:"-" json:"supplier_ids,omitempty"`
+
 	// Relationships
-	PR     PurchaseRequest `gorm:"foreignKey:PRID" json:"pr,omitempty"`
-	Quotes []Quote         `gorm:"foreignKey:RFQID" json:"quotes,omitempty"`
+	PR          PurchaseRequest `gorm:"foreignKey:PRID" json:"pr,omitempty"`
+	Quotes      []Quote         `gorm:"foreignKey:RFQID" json:"quotes,omitempty"`
+	Invitations []RFQInvitation `gorm:"foreignKey:RFQID" json:"invitations,omitempty"`
+	Amendments  []RFQAmendment  `gorm:"foreignKey:RFQID" json:"amendments,omitempty"`
 }
 
 func (RFQ) TableName() string {
 	return "procurement.rfqs"
 }
 
+type RFQInvitation struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	RFQID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_rfq_supplier" json:"rfq_id"`
+	SupplierID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_rfq_supplier;index" json:"supplier_id"`
+	Source        string     `gorm:"type:varchar(50);default:'manual'" json:"source"` // manual, suggested
+	Status        string     `gorm:"type:varchar(50);default:'invited';index" json:"status"` // invited, viewed, declined, quoted
+	DeclineReason string     `gorm:"type:text" json:"decline_reason,omitempty"`
+	InvitedBy     uuid.UUID  `gorm:"type:uuid;not null" json:"invited_by"`
+	ViewedAt      *time.Time `json:"viewed_at,omitempty"`
+	RespondedAt   *time.Time `json:"responded_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Relationships
+	RFQ RFQ `gorm:"foreignKey:RFQID" json:"rfq,omitempty"`
+}
+
+func (RFQInvitation) TableName() string {
+	return "procurement.rfq_invitations"
+}
+
+// RFQAmendment records a change to an issued RFQ, such as a clarification that
+// extends the response deadline
+type RFQAmendment struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"tenant_id"`
+	RFQID           uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_rfq_amendment" json:"rfq_id"`
+	AmendmentNumber int        `gorm:"not null;uniqueIndex:idx_rfq_amendment" json:"amendment_number"`
+	Description     string     `gorm:"type:text;not null" json:"description"`
+	PreviousDueDate time.Time  `json:"previous_due_date"`
+	NewDueDate      *time.Time `json:"new_due_date,omitempty"`
+	ReferenceID     *uuid.UUID `gorm:"type:uuid;index" json:"reference_id,omitempty"` // e.g. the clarification question that triggered it
+	CreatedBy       uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+func (RFQAmendment) TableName() string {
+	return "procurement.rfq_amendments"
+}
+
+// QualifiedSupplier records a buyer's approval of a supplier for a catalog category.
+// It drives the supplier suggestions offered when an RFQ is created.
+type QualifiedSupplier struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TenantID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_supplier_category" json:"tenant_id"`
+	SupplierID  uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_supplier_category" json:"supplier_id"`
+	CategoryID  uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_tenant_supplier_category;index" json:"category_id"`
+	Status      string     `gorm:"type:varchar(50);default:'qualified';index" json:"status"` // qualified, suspended, disqualified
+	Notes       string     `gorm:"type:text" json:"notes"`
+	QualifiedBy uuid.UUID  `gorm:"type:uuid;not null" json:"qualified_by"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (QualifiedSupplier) TableName() string {
+	return "procurement.qualified_suppliers"
+}
+
 type Quote struct {
 	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	TenantID    uuid.UUID `gorm:"type:uuid;not null;index" json:"tenant_id"`
@@ -104,7 +177,9 @@ type Quote struct {
 	SupplierID  uuid.UUID `gorm:"type:uuid;not null;index" json:"supplier_id"`
 	QuoteNumber string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_tenant_quote" json:"quote_number"`
 	Status      string    `gorm:"type:varchar(50);default:'submitted';index" json:"status"` // submitted, accepted, rejected, expired
-	TotalAmount float64   `json:"total_amount"`
+	TotalAmount money.Decimal `json:"total_amount"`
+	TaxAmount   money.Decimal `json:"tax_amount"`
+	GrossAmount money.Decimal `json:"gross_amount"` // total including tax
 	Currency    string    `gorm:"type:varchar(10);default:'USD'" json:"currency"`
 	ValidUntil  time.Time `json:"valid_until"`
 	Notes       string    `gorm:"type:text" json:"notes"`
@@ -115,6 +190,7 @@ type Quote struct {
 	// Relationships
 	RFQ    RFQ      `gorm:"foreignKey:RFQID" json:"rfq,omitempty"`
 	Items  []QuoteItem `gorm:"foreignKey:QuoteID" json:"items,omitempty"`
+	TaxLines []TaxLine `gorm:"polymorphic:Document;polymorphicValue:quote" json:"tax_lines,omitempty"`
 }
 
 func (Quote) TableName() string {
@@ -127,8 +203,9 @@ type QuoteItem struct {
 	PRItemID    uuid.UUID `gorm:"type:uuid;not null;index" json:"pr_item_id"`
 	Description string    `gorm:"type:text;not null" json:"description"`
 	Quantity    float64   `gorm:"not null" json:"quantity"`
-	UnitPrice   float64   `gorm:"not null" json:"unit_price"`
-	TotalPrice  float64   `json:"total_price"`
+	UnitPrice   money.Decimal `gorm:"not null" json:"unit_price"`
+	TotalPrice  money.Decimal `json:"total_price"`
+	TaxCategory string    `gorm:"type:varchar(100)" json:"tax_category"`
 	LeadTime    int       `json:"lead_time"` // days
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -149,12 +226,21 @@ type PurchaseOrder struct {
 	QuoteID     uuid.UUID `gorm:"type:uuid;not null;index" json:"quote_id"`
 	PONumber    string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_tenant_po" json:"po_number"`
 	Status      string    `gorm:"type:varchar(50);default:'pending';index" json:"status"` // pending, confirmed, shipped, delivered, cancelled
-	TotalAmount    float64    `json:"total_amount"`
+	TotalAmount    money.Decimal `json:"total_amount"`
+	TaxAmount      money.Decimal `json:"tax_amount"`
+	GrossAmount    money.Decimal `json:"gross_amount"` // total including tax
 	Currency       string      `gorm:"type:varchar(10);default:'USD'" json:"currency"`
+	// FX rate frozen when the PO is issued, converting Currency into the tenant's base currency
+	BaseCurrency   string        `gorm:"type:varchar(10)" json:"base_currency,omitempty"`
+	FXRate         money.Decimal `gorm:"type:numeric(24,12)" json:"fx_rate"`
+	FXRateDate     *time.Time    `gorm:"type:date" json:"fx_rate_date,omitempty"`
+	BaseAmount     money.Decimal `json:"base_amount"`
 	PaymentMode    string      `gorm:"type:varchar(50);default:'DIRECT';index" json:"payment_mode"` // DIRECT, ESCROW
 	PaymentStatus  string      `gorm:"type:varchar(50);default:'pending';index" json:"payment_status"` // pending, processing, succeeded, failed
 	PaymentID      *uuid.UUID  `gorm:"type:uuid;index" json:"payment_id,omitempty"`
 	SupplierID     uuid.UUID   `gorm:"type:uuid;not null;index" json:"supplier_id"`
+	// BOM revision the order was sourced against, taken from the PR
+	BOMRevisionID  *uuid.UUID  `gorm:"type:uuid;index" json:"bom_revision_id,omitempty"`
 	CreatedBy   uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -164,6 +250,7 @@ type PurchaseOrder struct {
 	RFQ   RFQ             `gorm:"foreignKey:RFQID" json:"rfq,omitempty"`
 	Quote Quote           `gorm:"foreignKey:QuoteID" json:"quote,omitempty"`
 	Items []POItem       `gorm:"foreignKey:POID" json:"items,omitempty"`
+	TaxLines []TaxLine   `gorm:"polymorphic:Document;polymorphicValue:po" json:"tax_lines,omitempty"`
 }
 
 func (PurchaseOrder) TableName() string {
@@ -176,8 +263,9 @@ type POItem struct {
 	PRItemID    uuid.UUID `gorm:"type:uuid;not null;index" json:"pr_item_id"`
 	Description string    `gorm:"type:text;not null" json:"description"`
 	Quantity    float64   `gorm:"not null" json:"quantity"`
-	UnitPrice   float64   `gorm:"not null" json:"unit_price"`
-	TotalPrice  float64   `json:"total_price"`
+	UnitPrice   money.Decimal `gorm:"not null" json:"unit_price"`
+	TotalPrice  money.Decimal `json:"total_price"`
+	TaxCategory string    `gorm:"type:varchar(100)" json:"tax_category"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+)
+
+// Document types that carry tax lines
+const (
+	TaxDocumentQuote   = "quote"
+	TaxDocumentPO      = "po"
+	TaxDocumentInvoice = "invoice"
+)
+
+// TaxRule is a VAT/GST/sales tax rate for supplies into a destination country.
+// OriginCountry and Category narrow the rule; empty values match anything.
+type TaxRule struct {
+	ID                 uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	DestinationCountry string        `gorm:"type:varchar(100);not null;index" json:"destination_country"`
+	OriginCountry      string        `gorm:"type:varchar(100)" json:"origin_country"`
+	Category           string        `gorm:"type:varchar(100)" json:"category"`
+	TaxType            string        `gorm:"type:varchar(20);not null" json:"tax_type"` // VAT, GST, SALES_TAX
+	Rate               money.Decimal `gorm:"type:numeric(7,4);not null" json:"rate"`    // percent
+	ValidFrom          time.Time     `gorm:"type:date;not null" json:"valid_from"`
+	ValidTo            *time.Time    `gorm:"type:date" json:"valid_to,omitempty"`
+	CreatedAt          time.Time     `json:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+}
+
+func (TaxRule) TableName() string {
+	return "procurement.tax_rules"
+}
+
+// TaxLine is the tax calculated for one line of a quote, PO or invoice
+type TaxLine struct {
+	ID                   uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	DocumentType         string        `gorm:"type:varchar(20);not null;index:idx_tax_line_document" json:"document_type"` // quote, po, invoice
+	DocumentID           uuid.UUID     `gorm:"type:uuid;not null;index:idx_tax_line_document" json:"document_id"`
+	LineID               *uuid.UUID    `gorm:"type:uuid" json:"line_id,omitempty"`
+	Category             string        `gorm:"type:varchar(100)" json:"category"`
+	TaxType              string        `gorm:"type:varchar(20)" json:"tax_type"`
+	Jurisdiction         string        `gorm:"type:varchar(100)" json:"jurisdiction"`
+	Rate                 money.Decimal `gorm:"type:numeric(7,4)" json:"rate"` // percent
+	TaxableAmount        money.Decimal `json:"taxable_amount"`
+	TaxAmount            money.Decimal `json:"tax_amount"`
+	Currency             string        `gorm:"type:varchar(10)" json:"currency"`
+	Treatment            string        `gorm:"type:varchar(30)" json:"treatment"` // standard, reverse_charge, exempt, zero_rated, no_rule
+	ExemptionCertificate string        `gorm:"type:varchar(100)" json:"exemption_certificate,omitempty"`
+	CreatedAt            time.Time     `json:"created_at"`
+}
+
+func (TaxLine) TableName() string {
+	return "procurement.tax_lines"
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/b2b-platform/procurement-service/models"
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BudgetRepository struct {
+	db *gorm.DB
+}
+
+func NewBudgetRepository(db *gorm.DB) *BudgetRepository {
+	return &BudgetRepository{db: db}
+}
+
+func (r *BudgetRepository) CreateCostCenter(costCenter *models.CostCenter) error {
+	return r.db.Create(costCenter).Error
+}
+
+func (r *BudgetRepository) ListCostCenters(tenantID uuid.UUID) ([]models.CostCenter, error) {
+	var costCenters []models.CostCenter
+	err := r.db.Where("tenant_id = ?", tenantID).Order("code ASC").Find(&costCenters).Error
+	return costCenters, err
+}
+
+func (r *BudgetRepository) GetCostCenter(id uuid.UUID) (*models.CostCenter, error) {
+	var costCenter models.CostCenter
+	err := r.db.Where("id = ?", id).First(&costCenter).Error
+	return &costCenter, err
+}
+
+func (r *BudgetRepository) CreateGLAccount(account *models.GLAccount) error {
+	return r.db.Create(account).Error
+}
+
+func (r *BudgetRepository) ListGLAccounts(tenantID uuid.UUID) ([]models.GLAccount, error) {
+	var accounts []models.GLAccount
+	err := r.db.Where("tenant_id = ?", tenantID).Order("code ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *BudgetRepository) Create(budget *models.Budget) error {
+	return r.db.Create(budget).Error
+}
+
+func (r *BudgetRepository) GetByID(id uuid.UUID) (*models.Budget, error) {
+	var budget models.Budget
+	err := r.db.Preload("CostCenter").Preload("GLAccount").Where("id = ?", id).First(&budget).Error
+	return &budget, err
+}
+
+func (r *BudgetRepository) Update(budget *models.Budget) error {
+	return r.db.Save(budget).Error
+}
+
+// List returns budgets overlapping the given period. Zero times leave that end open.
+func (r *BudgetRepository) List(tenantID uuid.UUID, costCenterID *uuid.UUID, from, to time.Time) ([]models.Budget, error) {
+	var budgets []models.Budget
+	query := r.db.Preload("CostCenter").Preload("GLAccount").Where("tenant_id = ?", tenantID)
+	if costCenterID != nil {
+		query = query.Where("cost_center_id = ?", *costCenterID)
+	}
+	if !from.IsZero() {
+		query = query.Where("period_end >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("period_start <= ?", to)
+	}
+	err := query.Order("period_start ASC").Find(&budgets).Error
+	return budgets, err
+}
+
+// FindActive returns the budget covering a cost center and GL account on a date.
+// A budget without a GL account covers every account of its cost center, but a
+// GL-specific budget takes precedence.
+func (r *BudgetRepository) FindActive(tenantID, costCenterID uuid.UUID, glAccountID *uuid.UUID, at time.Time) (*models.Budget, error) {
+	var budget models.Budget
+	query := r.db.Where("tenant_id = ? AND cost_center_id = ?", tenantID, costCenterID).
+		Where("period_start <= ? AND period_end >= ?", at, at)
+	if glAccountID != nil {
+		query = query.Where("gl_account_id = ? OR gl_account_id IS NULL", *glAccountID).
+			Order("gl_account_id IS NULL ASC")
+	} else {
+		query = query.Where("gl_account_id IS NULL")
+	}
+	err := query.First(&budget).Error
+	return &budget, err
+}
+
+// WithLockedBudget runs fn inside a transaction holding a row lock on the budget,
+// so concurrent approvals cannot both pass the same balance check
+func (r *BudgetRepository) WithLockedBudget(budgetID uuid.UUID, fn func(repo *BudgetRepository, budget *models.Budget) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var budget models.Budget
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", budgetID).First(&budget).Error; err != nil {
+			return err
+		}
+		return fn(&BudgetRepository{db: tx}, &budget)
+	})
+}
+
+// DB returns the connection the repository runs on. Inside WithLockedBudget it
+// is the open transaction, so writes to other tables can join it.
+func (r *BudgetRepository) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *BudgetRepository) AddEntry(entry *models.BudgetEntry) error {
+	return r.db.Create(entry).Error
+}
+
+// GetBalance sums the entries recorded against a budget by entry type
+func (r *BudgetRepository) GetBalance(budgetID uuid.UUID) (models.BudgetBalance, error) {
+	balances, err := r.GetBalances([]uuid.UUID{budgetID})
+	if err != nil {
+		return models.BudgetBalance{BudgetID: budgetID}, err
+	}
+	return balances[budgetID], nil
+}
+
+func (r *BudgetRepository) GetBalances(budgetIDs []uuid.UUID) (map[uuid.UUID]models.BudgetBalance, error) {
+	balances := make(map[uuid.UUID]models.BudgetBalance, len(budgetIDs))
+	for _, id := range budgetIDs {
+		balances[id] = models.BudgetBalance{BudgetID: id}
+	}
+	if len(budgetIDs) == 0 {
+		return balances, nil
+	}
+
+	var rows []struct {
+		BudgetID  uuid.UUID
+		EntryType string
+		Total     money.Decimal
+	}
+	err := r.db.Model(&models.BudgetEntry{}).
+		Select("budget_id, entry_type, SUM(amount) as total").
+		Where("budget_id IN ?", budgetIDs).
+		Group("budget_id, entry_type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		balance := balances[row.BudgetID]
+		switch row.EntryType {
+		case models.BudgetEntryCommitment:
+			balance.Committed = row.Total
+		case models.BudgetEntryEncumbrance:
+			balance.Encumbered = row.Total
+		case models.BudgetEntryActual:
+			balance.Actual = row.Total
+		}
+		balances[row.BudgetID] = balance
+	}
+	return balances, nil
+}
+
+// SumEntries totals the entries of one type recorded for a PR or PO
+func (r *BudgetRepository) SumEntries(budgetID uuid.UUID, entryType, column string, id uuid.UUID) (money.Decimal, error) {
+	var total money.Decimal
+	err := r.db.Model(&models.BudgetEntry{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("budget_id = ? AND entry_type = ?", budgetID, entryType).
+		Where(clause.Eq{Column: clause.Column{Name: column}, Value: id}).
+		Row().Scan(&total)
+	return total, err
+}
+
+// HasSourceEntry reports whether an entry was already recorded for a source
+// document, so receipts and invoices are not counted twice
+func (r *BudgetRepository) HasSourceEntry(sourceType string, sourceID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.BudgetEntry{}).
+		Where("source_type = ? AND source_id = ?", sourceType, sourceID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *BudgetRepository) ListEntries(budgetID uuid.UUID) ([]models.BudgetEntry, error) {
+	var entries []models.BudgetEntry
+	err := r.db.Where("budget_id = ?", budgetID).Order("created_at ASC").Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"github.com/b2b-platform/procurement-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type InvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+// CreateAll stores several invitations at once; either all or none are added
+func (r *InvitationRepository) CreateAll(invitations []models.RFQInvitation) error {
+	if len(invitations) == 0 {
+		return nil
+	}
+	return r.db.Create(&invitations).Error
+}
+
+func (r *InvitationRepository) Create(invitation *models.RFQInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *InvitationRepository) GetByRFQAndSupplier(rfqID, supplierID uuid.UUID) (*models.RFQInvitation, error) {
+	var invitation models.RFQInvitation
+	err := r.db.Where("rfq_id = ? AND supplier_id = ?", rfqID, supplierID).First(&invitation).Error
+	return &invitation, err
+}
+
+func (r *InvitationRepository) ListByRFQ(rfqID uuid.UUID) ([]models.RFQInvitation, error) {
+	var invitations []models.RFQInvitation
+	err := r.db.Where("rfq_id = ?", rfqID).Order("created_at ASC").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *InvitationRepository) ListBySupplier(supplierID uuid.UUID, status string) ([]models.RFQInvitation, error) {
+	var invitations []models.RFQInvitation
+	query := r.db.Preload("RFQ").Where("supplier_id = ?", supplierID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *InvitationRepository) Update(invitation *models.RFQInvitation) error {
+	return r.db.Save(invitation).Error
+}
@@ -20,7 +20,7 @@ func (r *PORepository) Create(po *models.PurchaseOrder) error {
 
 func (r *PORepository) GetByID(id uuid.UUID) (*models.PurchaseOrder, error) {
 	var po models.PurchaseOrder
-	err := r.db.Preload("Items").Preload("PR").Preload("Quote").Where("id = ?", id).First(&po).Error
+	err := r.db.Preload("Items").Preload("TaxLines").Preload("PR").Preload("Quote").Where("id = ?", id).First(&po).Error
 	return &po, err
 }
 
@@ -30,6 +30,15 @@ func (r *PORepository) List(tenantID uuid.UUID) ([]models.PurchaseOrder, error)
 	return pos, err
 }
 
+// ListByBOMRevision returns the tenant's POs sourced against a BOM revision
+func (r *PORepository) ListByBOMRevision(tenantID, bomRevisionID uuid.UUID) ([]models.PurchaseOrder, error) {
+	var pos []models.PurchaseOrder
+	err := r.db.Where("tenant_id = ? AND bom_revision_id = ?", tenantID, bomRevisionID).
+		Order("created_at DESC").
+		Find(&pos).Error
+	return pos, err
+}
+
 func (r *PORepository) Update(po *models.PurchaseOrder) error {
 	return r.db.Save(po).Error
 }
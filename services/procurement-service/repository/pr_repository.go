@@ -38,6 +38,25 @@ func (r *PRRepository) Update(pr *models.PurchaseRequest) error {
 	return r.db.Save(pr).Error
 }
 
+// Transition moves a PR out of one of the from statuses, saving its status and
+// approval fields and recording the approval. It returns gorm.ErrRecordNotFound
+// when the PR is no longer in any of those statuses.
+func (r *PRRepository) Transition(pr *models.PurchaseRequest, from []string, approval *models.PRApproval) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(pr).
+			Where("status IN ?", from).
+			Select("status", "approved_at", "approved_by", "budget_id").
+			Updates(pr)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Create(approval).Error
+	})
+}
+
 func (r *PRRepository) AddApproval(approval *models.PRApproval) error {
 	return r.db.Create(approval).Error
 }
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/b2b-platform/procurement-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type QualifiedSupplierRepository struct {
+	db *gorm.DB
+}
+
+func NewQualifiedSupplierRepository(db *gorm.DB) *QualifiedSupplierRepository {
+	return &QualifiedSupplierRepository{db: db}
+}
+
+func (r *QualifiedSupplierRepository) Create(qs *models.QualifiedSupplier) error {
+	return r.db.Create(qs).Error
+}
+
+func (r *QualifiedSupplierRepository) GetByID(id uuid.UUID) (*models.QualifiedSupplier, error) {
+	var qs models.QualifiedSupplier
+	err := r.db.Where("id = ?", id).First(&qs).Error
+	return &qs, err
+}
+
+func (r *QualifiedSupplierRepository) List(tenantID uuid.UUID, categoryID *uuid.UUID) ([]models.QualifiedSupplier, error) {
+	var result []models.QualifiedSupplier
+	query := r.db.Where("tenant_id = ?", tenantID)
+	if categoryID != nil {
+		query = query.Where("category_id = ?", *categoryID)
+	}
+	err := query.Find(&result).Error
+	return result, err
+}
+
+// ListActiveForCategory returns suppliers currently qualified for a category
+func (r *QualifiedSupplierRepository) ListActiveForCategory(tenantID, categoryID uuid.UUID) ([]models.QualifiedSupplier, error) {
+	var result []models.QualifiedSupplier
+	err := r.db.Where("tenant_id = ? AND category_id = ? AND status = ?", tenantID, categoryID, "qualified").
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Find(&result).Error
+	return result, err
+}
+
+func (r *QualifiedSupplierRepository) Update(qs *models.QualifiedSupplier) error {
+	return r.db.Save(qs).Error
+}
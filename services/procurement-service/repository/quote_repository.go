@@ -20,7 +20,7 @@ func (r *QuoteRepository) Create(quote *models.Quote) error {
 
 func (r *QuoteRepository) GetByID(id uuid.UUID) (*models.Quote, error) {
 	var quote models.Quote
-	err := r.db.Preload("Items").Preload("RFQ").Where("id = ?", id).First(&quote).Error
+	err := r.db.Preload("Items").Preload("TaxLines").Preload("RFQ").Where("id = ?", id).First(&quote).Error
 	return &quote, err
 }
 
@@ -4,6 +4,7 @@ import (
 	"github.com/b2b-platform/procurement-service/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RFQRepository struct {
@@ -18,9 +19,29 @@ func (r *RFQRepository) Create(rfq *models.RFQ) error {
 	return r.db.Create(rfq).Error
 }
 
+// CreateWithInvitations stores an RFQ together with its distribution list, so
+// an RFQ is never left without the suppliers it was sent to
+func (r *RFQRepository) CreateWithInvitations(rfq *models.RFQ, invitations []models.RFQInvitation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(rfq).Error; err != nil {
+			return err
+		}
+		for i := range invitations {
+			invitations[i].RFQID = rfq.ID
+		}
+		if len(invitations) > 0 {
+			if err := tx.Create(&invitations).Error; err != nil {
+				return err
+			}
+		}
+		rfq.Invitations = invitations
+		return nil
+	})
+}
+
 func (r *RFQRepository) GetByID(id uuid.UUID) (*models.RFQ, error) {
 	var rfq models.RFQ
-	err := r.db.Preload("PR").Preload("Quotes").Where("id = ?", id).First(&rfq).Error
+	err := r.db.Preload("PR").Preload("Quotes").Preload("Invitations").Preload("Amendments").Where("id = ?", id).First(&rfq).Error
 	return &rfq, err
 }
 
@@ -29,3 +50,17 @@ func (r *RFQRepository) List(tenantID uuid.UUID) ([]models.RFQ, error) {
 	err := r.db.Where("tenant_id = ?", tenantID).Find(&rfqs).Error
 	return rfqs, err
 }
+
+func (r *RFQRepository) Update(rfq *models.RFQ) error {
+	return r.db.Save(rfq).Error
+}
+
+func (r *RFQRepository) AddAmendment(amendment *models.RFQAmendment) error {
+	return r.db.Create(amendment).Error
+}
+
+func (r *RFQRepository) GetAmendments(rfqID uuid.UUID) ([]models.RFQAmendment, error) {
+	var amendments []models.RFQAmendment
+	err := r.db.Where("rfq_id = ?", rfqID).Order("amendment_number ASC").Find(&amendments).Error
+	return amendments, err
+}
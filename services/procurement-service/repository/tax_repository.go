@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/b2b-platform/procurement-service/models"
+	"github.com/b2b-platform/shared/tax"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TaxRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRepository(db *gorm.DB) *TaxRepository {
+	return &TaxRepository{db: db}
+}
+
+func (r *TaxRepository) CreateRule(rule *models.TaxRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *TaxRepository) GetRule(id uuid.UUID) (*models.TaxRule, error) {
+	var rule models.TaxRule
+	err := r.db.Where("id = ?", id).First(&rule).Error
+	return &rule, err
+}
+
+func (r *TaxRepository) UpdateRule(rule *models.TaxRule) error {
+	return r.db.Save(rule).Error
+}
+
+// DeleteRule deletes a rule, returning gorm.ErrRecordNotFound if there is none
+func (r *TaxRepository) DeleteRule(id uuid.UUID) error {
+	result := r.db.Where("id = ?", id).Delete(&models.TaxRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *TaxRepository) ListRules(destinationCountry string) ([]models.TaxRule, error) {
+	var rules []models.TaxRule
+	query := r.db.Model(&models.TaxRule{})
+	if destinationCountry != "" {
+		query = query.Where("UPPER(destination_country) = UPPER(?)", destinationCountry)
+	}
+	err := query.Order("destination_country ASC, valid_from DESC").Find(&rules).Error
+	return rules, err
+}
+
+// RulesFor implements tax.RuleSource
+func (r *TaxRepository) RulesFor(destinationCountry string, on time.Time) ([]tax.Rule, error) {
+	var rules []models.TaxRule
+	err := r.db.Where("UPPER(destination_country) = UPPER(?)", destinationCountry).
+		Where("valid_from <= ?", on).
+		Where("valid_to IS NULL OR valid_to >= ?", on).
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]tax.Rule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, tax.Rule{
+			DestinationCountry: rule.DestinationCountry,
+			OriginCountry:      rule.OriginCountry,
+			Category:           rule.Category,
+			TaxType:            rule.TaxType,
+			Rate:               rule.Rate,
+			ValidFrom:          rule.ValidFrom,
+			ValidTo:            rule.ValidTo,
+		})
+	}
+	return result, nil
+}
+
+// ReplaceLines swaps the tax lines of a document for a freshly calculated set
+func (r *TaxRepository) ReplaceLines(documentType string, documentID uuid.UUID, lines []models.TaxLine) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_type = ? AND document_id = ?", documentType, documentID).
+			Delete(&models.TaxLine{}).Error; err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+		return tx.Create(&lines).Error
+	})
+}
+
+func (r *TaxRepository) ListLines(documentType string, documentID uuid.UUID) ([]models.TaxLine, error) {
+	var lines []models.TaxLine
+	err := r.db.Where("document_type = ? AND document_id = ?", documentType, documentID).
+		Order("created_at ASC").Find(&lines).Error
+	return lines, err
+}
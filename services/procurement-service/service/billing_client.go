@@ -4,17 +4,18 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
 )
 
 type BillingClient struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL        string
+	httpClient     *http.Client
+	baseCurrencies baseCurrencyCache
 }
 
 func NewBillingClient() *BillingClient {
@@ -34,17 +35,17 @@ func NewBillingClient() *BillingClient {
 type CreatePaymentIntentRequest struct {
 	OrderID     uuid.UUID              `json:"order_id"`
 	SupplierID  uuid.UUID              `json:"supplier_id"`
-	Amount      float64                `json:"amount"`
+	Amount      money.Decimal          `json:"amount"`
 	Currency    string                 `json:"currency"`
 	PaymentMode string                 `json:"payment_mode"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
 type CreatePaymentIntentResponse struct {
-	PaymentIntentID string  `json:"payment_intent_id"`
-	ClientSecret    string  `json:"client_secret"`
-	Amount          float64 `json:"amount"`
-	Currency        string  `json:"currency"`
+	PaymentIntentID string        `json:"payment_intent_id"`
+	ClientSecret    string        `json:"client_secret"`
+	Amount          money.Decimal `json:"amount"`
+	Currency        string        `json:"currency"`
 }
 
 func (c *BillingClient) CreatePaymentIntent(token string, req CreatePaymentIntentRequest) (*CreatePaymentIntentResponse, error) {
@@ -65,13 +66,12 @@ func (c *BillingClient) CreatePaymentIntent(token string, req CreatePaymentInten
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call billing service: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrBillingUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("billing service returned status %d: %s", resp.StatusCode, string(body))
+		return nil, billingStatusError(resp)
 	}
 
 	var response CreatePaymentIntentResponse
@@ -0,0 +1,516 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/b2b-platform/procurement-service/models"
+	"github.com/b2b-platform/procurement-service/repository"
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBudgetNotFound  = errors.New("no budget covers this cost center and period")
+	ErrBudgetExceeded  = errors.New("request exceeds the available budget")
+	ErrBudgetEscalated = errors.New("request exceeds the available budget and was escalated to the budget owner")
+	ErrNotBudgetOwner  = errors.New("only the cost center's budget owner can approve a budget overrun")
+	ErrInvalidActual   = errors.New("source_type must be receipt or invoice")
+	ErrPRNotApprovable = errors.New("only draft, pending or submitted PRs can be approved")
+	ErrPRNotEscalated  = errors.New("only PRs escalated for a budget overrun can have the overrun approved")
+)
+
+// Budget check outcomes
+const (
+	BudgetDecisionApprove  = "approve"
+	BudgetDecisionEscalate = "escalate"
+	BudgetDecisionBlock    = "block"
+)
+
+// BudgetCheck is the result of checking an amount against a budget. Amounts
+// are in the budget's currency.
+type BudgetCheck struct {
+	BudgetID  uuid.UUID     `json:"budget_id"`
+	Currency  string        `json:"currency"`
+	Amount    money.Decimal `json:"amount"`
+	Available money.Decimal `json:"available"`
+	Decision  string        `json:"decision"`
+	OwnerID   *uuid.UUID    `json:"owner_id,omitempty"` // Budget owner an escalated PR waits on
+}
+
+// BudgetReportLine is one row of the budget-vs-actual report. Budget figures are
+// in the budget's currency; the Base* figures are converted to the tenant's
+// base currency.
+type BudgetReportLine struct {
+	BudgetID       uuid.UUID     `json:"budget_id"`
+	Name           string        `json:"name"`
+	CostCenterID   uuid.UUID     `json:"cost_center_id"`
+	CostCenterCode string        `json:"cost_center_code"`
+	GLAccountID    *uuid.UUID    `json:"gl_account_id,omitempty"`
+	GLAccountCode  string        `json:"gl_account_code,omitempty"`
+	PeriodStart    time.Time     `json:"period_start"`
+	PeriodEnd      time.Time     `json:"period_end"`
+	Currency       string        `json:"currency"`
+	Budget         money.Decimal `json:"budget"`
+	Committed      money.Decimal `json:"committed"`
+	Encumbered     money.Decimal `json:"encumbered"`
+	Actual         money.Decimal `json:"actual"`
+	Available      money.Decimal `json:"available"`
+	Utilization    float64       `json:"utilization"` // percent of budget consumed
+	FXRate         money.Decimal `json:"fx_rate"`
+	BaseBudget     money.Decimal `json:"base_budget"`
+	BaseConsumed   money.Decimal `json:"base_consumed"`
+	BaseAvailable  money.Decimal `json:"base_available"`
+}
+
+// BudgetReportTotals sums the report lines in the tenant's base currency
+type BudgetReportTotals struct {
+	Budget     money.Decimal `json:"budget"`
+	Committed  money.Decimal `json:"committed"`
+	Encumbered money.Decimal `json:"encumbered"`
+	Actual     money.Decimal `json:"actual"`
+	Available  money.Decimal `json:"available"`
+}
+
+type BudgetReport struct {
+	BaseCurrency string             `json:"base_currency"`
+	Lines        []BudgetReportLine `json:"lines"`
+	Totals       BudgetReportTotals `json:"totals"`
+}
+
+type BudgetService struct {
+	budgetRepo *repository.BudgetRepository
+	fx         FXConverter
+}
+
+func NewBudgetService(budgetRepo *repository.BudgetRepository, fx FXConverter) *BudgetService {
+	return &BudgetService{budgetRepo: budgetRepo, fx: fx}
+}
+
+func (s *BudgetService) CreateCostCenter(costCenter *models.CostCenter) error {
+	return s.budgetRepo.CreateCostCenter(costCenter)
+}
+
+func (s *BudgetService) ListCostCenters(tenantID uuid.UUID) ([]models.CostCenter, error) {
+	return s.budgetRepo.ListCostCenters(tenantID)
+}
+
+func (s *BudgetService) CreateGLAccount(account *models.GLAccount) error {
+	return s.budgetRepo.CreateGLAccount(account)
+}
+
+func (s *BudgetService) ListGLAccounts(tenantID uuid.UUID) ([]models.GLAccount, error) {
+	return s.budgetRepo.ListGLAccounts(tenantID)
+}
+
+func (s *BudgetService) CreateBudget(budget *models.Budget) error {
+	if !budget.PeriodEnd.After(budget.PeriodStart) {
+		return fmt.Errorf("period_end must be after period_start")
+	}
+	if budget.OverrunPolicy == "" {
+		budget.OverrunPolicy = BudgetDecisionBlock
+	}
+	return s.budgetRepo.Create(budget)
+}
+
+func (s *BudgetService) GetBudget(id uuid.UUID) (*models.Budget, error) {
+	return s.budgetRepo.GetByID(id)
+}
+
+func (s *BudgetService) GetBudgetEntries(id uuid.UUID) ([]models.BudgetEntry, error) {
+	return s.budgetRepo.ListEntries(id)
+}
+
+// CheckPR evaluates a PR against its cost center budget without reserving anything.
+// It returns nil when the PR is not assigned to a cost center.
+func (s *BudgetService) CheckPR(pr *models.PurchaseRequest, authToken string) (*BudgetCheck, error) {
+	if pr.CostCenterID == nil {
+		return nil, nil
+	}
+
+	budget, err := s.findBudget(pr)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, _, err := s.prToBudget(pr, budget, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.budgetRepo.GetBalance(budget.ID)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateBudget(budget, balance, amount), nil
+}
+
+// ReserveCommitment records the PR amount as a commitment on its budget.
+// Overruns are rejected or escalated according to the budget's policy unless
+// the approver is the cost center owner and override is set. persist saves the
+// approved PR in the same transaction as the commitment, so a PR is never
+// committed twice or committed without being approved.
+func (s *BudgetService) ReserveCommitment(pr *models.PurchaseRequest, approverID uuid.UUID, override bool, authToken string, persist func(tx *gorm.DB) error) (*BudgetCheck, error) {
+	if pr.CostCenterID == nil {
+		return nil, persist(s.budgetRepo.DB())
+	}
+
+	budget, err := s.findBudget(pr)
+	if err != nil {
+		return nil, err
+	}
+
+	if override {
+		costCenter, err := s.budgetRepo.GetCostCenter(budget.CostCenterID)
+		if err != nil {
+			return nil, err
+		}
+		if costCenter.OwnerID == nil || *costCenter.OwnerID != approverID {
+			return nil, ErrNotBudgetOwner
+		}
+	}
+
+	// Convert before taking the lock so the row is not held during the FX lookup
+	amount, rate, err := s.prToBudget(pr, budget, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var check *BudgetCheck
+	err = s.budgetRepo.WithLockedBudget(budget.ID, func(repo *repository.BudgetRepository, locked *models.Budget) error {
+		balance, err := repo.GetBalance(locked.ID)
+		if err != nil {
+			return err
+		}
+
+		check = evaluateBudget(locked, balance, amount)
+		switch {
+		case check.Decision == BudgetDecisionBlock:
+			return ErrBudgetExceeded
+		case check.Decision == BudgetDecisionEscalate && !override:
+			return ErrBudgetEscalated
+		}
+
+		if err := repo.AddEntry(&models.BudgetEntry{
+			TenantID:       pr.TenantID,
+			BudgetID:       locked.ID,
+			EntryType:      models.BudgetEntryCommitment,
+			Amount:         amount,
+			SourceAmount:   prAmount(pr),
+			SourceCurrency: money.NormalizeCurrency(pr.Currency),
+			FXRate:         rate,
+			SourceType:     "pr",
+			SourceID:       pr.ID,
+			PRID:           &pr.ID,
+			CreatedBy:      approverID,
+		}); err != nil {
+			return err
+		}
+
+		pr.BudgetID = &locked.ID
+		return persist(repo.DB())
+	})
+	if errors.Is(err, ErrBudgetEscalated) {
+		costCenter, ccErr := s.budgetRepo.GetCostCenter(budget.CostCenterID)
+		if ccErr != nil {
+			return check, ccErr
+		}
+		check.OwnerID = costCenter.OwnerID
+	}
+	if err != nil {
+		pr.BudgetID = nil
+		return check, err
+	}
+	return check, nil
+}
+
+// Encumber converts the PR's commitment into an encumbrance for an issued PO.
+// The commitment released is capped at what remains committed for the PR.
+// persist saves the PO in the same transaction, so a PO is never issued
+// without its encumbrance.
+func (s *BudgetService) Encumber(pr *models.PurchaseRequest, po *models.PurchaseOrder, authToken string, persist func(tx *gorm.DB) error) error {
+	if pr.BudgetID == nil {
+		return persist(s.budgetRepo.DB())
+	}
+
+	budget, err := s.budgetRepo.GetByID(*pr.BudgetID)
+	if err != nil {
+		return err
+	}
+
+	amount, rate, err := s.poToBudget(po, budget, po.TotalAmount, authToken)
+	if err != nil {
+		return err
+	}
+
+	return s.budgetRepo.WithLockedBudget(budget.ID, func(repo *repository.BudgetRepository, budget *models.Budget) error {
+		if err := persist(repo.DB()); err != nil {
+			return err
+		}
+
+		remaining, err := repo.SumEntries(budget.ID, models.BudgetEntryCommitment, "pr_id", pr.ID)
+		if err != nil {
+			return err
+		}
+
+		if release := money.Min(remaining, amount); release.Sign() > 0 {
+			if err := repo.AddEntry(&models.BudgetEntry{
+				TenantID:   po.TenantID,
+				BudgetID:   budget.ID,
+				EntryType:  models.BudgetEntryCommitment,
+				Amount:     release.Neg(),
+				SourceType: "po",
+				SourceID:   po.ID,
+				PRID:       &pr.ID,
+				POID:       &po.ID,
+				CreatedBy:  po.CreatedBy,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return repo.AddEntry(&models.BudgetEntry{
+			TenantID:       po.TenantID,
+			BudgetID:       budget.ID,
+			EntryType:      models.BudgetEntryEncumbrance,
+			Amount:         amount,
+			SourceAmount:   po.TotalAmount,
+			SourceCurrency: money.NormalizeCurrency(po.Currency),
+			FXRate:         rate,
+			SourceType:     "po",
+			SourceID:       po.ID,
+			PRID:           &pr.ID,
+			POID:           &po.ID,
+			CreatedBy:      po.CreatedBy,
+		})
+	})
+}
+
+// RecordActual books spend from a goods receipt or invoice against the PO's
+// encumbrance. The amount is in the PO's currency. Each source document is only
+// recorded once.
+func (s *BudgetService) RecordActual(pr *models.PurchaseRequest, po *models.PurchaseOrder, sourceType string, sourceID uuid.UUID, sourceAmount money.Decimal, userID uuid.UUID, authToken string) error {
+	if pr.BudgetID == nil {
+		return nil
+	}
+	if sourceType != "receipt" && sourceType != "invoice" {
+		return ErrInvalidActual
+	}
+
+	budget, err := s.budgetRepo.GetByID(*pr.BudgetID)
+	if err != nil {
+		return err
+	}
+
+	amount, rate, err := s.poToBudget(po, budget, sourceAmount, authToken)
+	if err != nil {
+		return err
+	}
+
+	return s.budgetRepo.WithLockedBudget(budget.ID, func(repo *repository.BudgetRepository, budget *models.Budget) error {
+		recorded, err := repo.HasSourceEntry(sourceType, sourceID)
+		if err != nil || recorded {
+			return err
+		}
+
+		remaining, err := repo.SumEntries(budget.ID, models.BudgetEntryEncumbrance, "po_id", po.ID)
+		if err != nil {
+			return err
+		}
+
+		if release := money.Min(remaining, amount); release.Sign() > 0 {
+			if err := repo.AddEntry(&models.BudgetEntry{
+				TenantID:   po.TenantID,
+				BudgetID:   budget.ID,
+				EntryType:  models.BudgetEntryEncumbrance,
+				Amount:     release.Neg(),
+				SourceType: sourceType,
+				SourceID:   sourceID,
+				PRID:       &pr.ID,
+				POID:       &po.ID,
+				CreatedBy:  userID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return repo.AddEntry(&models.BudgetEntry{
+			TenantID:       po.TenantID,
+			BudgetID:       budget.ID,
+			EntryType:      models.BudgetEntryActual,
+			Amount:         amount,
+			SourceAmount:   sourceAmount,
+			SourceCurrency: money.NormalizeCurrency(po.Currency),
+			FXRate:         rate,
+			SourceType:     sourceType,
+			SourceID:       sourceID,
+			PRID:           &pr.ID,
+			POID:           &po.ID,
+			CreatedBy:      userID,
+		})
+	})
+}
+
+// Report builds the budget-vs-actual report for budgets overlapping a period,
+// with totals converted to the tenant's base currency at today's rates
+func (s *BudgetService) Report(tenantID uuid.UUID, costCenterID *uuid.UUID, from, to time.Time, authToken string) (*BudgetReport, error) {
+	budgets, err := s.budgetRepo.List(tenantID, costCenterID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(budgets))
+	for i, b := range budgets {
+		ids[i] = b.ID
+	}
+	balances, err := s.budgetRepo.GetBalances(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	baseCurrency, err := s.fx.GetBaseCurrency(tenantID, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BudgetReport{
+		BaseCurrency: baseCurrency,
+		Lines:        make([]BudgetReportLine, 0, len(budgets)),
+	}
+	rates := make(map[string]money.Decimal)
+	for _, b := range budgets {
+		balance := balances[b.ID]
+		consumed := balance.Consumed()
+		available := b.Amount.Sub(consumed)
+
+		rate, ok := rates[b.Currency]
+		if !ok {
+			fxRate, err := s.fx.GetFXRate(authToken, b.Currency, baseCurrency, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			rate = fxRate.Rate
+			rates[b.Currency] = rate
+		}
+		toBase := func(amount money.Decimal) money.Decimal {
+			return money.New(amount, b.Currency).Convert(rate, baseCurrency).Amount
+		}
+
+		line := BudgetReportLine{
+			BudgetID:       b.ID,
+			Name:           b.Name,
+			CostCenterID:   b.CostCenterID,
+			CostCenterCode: b.CostCenter.Code,
+			GLAccountID:    b.GLAccountID,
+			PeriodStart:    b.PeriodStart,
+			PeriodEnd:      b.PeriodEnd,
+			Currency:       b.Currency,
+			Budget:         b.Amount,
+			Committed:      balance.Committed,
+			Encumbered:     balance.Encumbered,
+			Actual:         balance.Actual,
+			Available:      available,
+			FXRate:         rate,
+			BaseBudget:     toBase(b.Amount),
+			BaseConsumed:   toBase(consumed),
+			BaseAvailable:  toBase(available),
+		}
+		if b.GLAccount != nil {
+			line.GLAccountCode = b.GLAccount.Code
+		}
+		if b.Amount.Sign() > 0 {
+			line.Utilization = consumed.Div(b.Amount).Mul(money.NewFromInt(100)).Round(2).Float64()
+		}
+		report.Lines = append(report.Lines, line)
+
+		report.Totals.Budget = report.Totals.Budget.Add(line.BaseBudget)
+		report.Totals.Committed = report.Totals.Committed.Add(toBase(balance.Committed))
+		report.Totals.Encumbered = report.Totals.Encumbered.Add(toBase(balance.Encumbered))
+		report.Totals.Actual = report.Totals.Actual.Add(toBase(balance.Actual))
+		report.Totals.Available = report.Totals.Available.Add(line.BaseAvailable)
+	}
+	return report, nil
+}
+
+func (s *BudgetService) findBudget(pr *models.PurchaseRequest) (*models.Budget, error) {
+	at := pr.CreatedAt
+	if pr.RequiredDate != nil {
+		at = *pr.RequiredDate
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	budget, err := s.budgetRepo.FindActive(pr.TenantID, *pr.CostCenterID, pr.GLAccountID, at)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBudgetNotFound
+	}
+	return budget, err
+}
+
+// prToBudget converts the PR amount into the budget's currency at today's rate
+func (s *BudgetService) prToBudget(pr *models.PurchaseRequest, budget *models.Budget, authToken string) (money.Decimal, money.Decimal, error) {
+	return convertAmount(s.fx, authToken, prAmount(pr), pr.Currency, budget.Currency, time.Now())
+}
+
+// poToBudget converts a PO amount into the budget's currency. When the budget is
+// kept in the tenant's base currency the rate frozen on the PO is reused, so the
+// encumbrance and the actuals that release it use the same rate.
+func (s *BudgetService) poToBudget(po *models.PurchaseOrder, budget *models.Budget, amount money.Decimal, authToken string) (money.Decimal, money.Decimal, error) {
+	if po.FXRate.Sign() > 0 && money.NormalizeCurrency(budget.Currency) == money.NormalizeCurrency(po.BaseCurrency) {
+		converted := money.New(amount, po.Currency).Convert(po.FXRate, budget.Currency)
+		return converted.Amount, po.FXRate, nil
+	}
+
+	on := time.Now()
+	if po.FXRateDate != nil {
+		on = *po.FXRateDate
+	}
+	return convertAmount(s.fx, authToken, amount, po.Currency, budget.Currency, on)
+}
+
+// evaluateBudget decides whether an amount fits the budget, allowing the
+// configured tolerance before the overrun policy applies
+func evaluateBudget(budget *models.Budget, balance models.BudgetBalance, amount money.Decimal) *BudgetCheck {
+	check := &BudgetCheck{
+		BudgetID:  budget.ID,
+		Currency:  budget.Currency,
+		Amount:    amount,
+		Available: budget.Amount.Sub(balance.Consumed()),
+		Decision:  BudgetDecisionApprove,
+	}
+
+	tolerance := budget.Amount.Mul(money.NewFromFloat(budget.TolerancePercent)).Div(money.NewFromInt(100))
+	if !amount.GreaterThan(check.Available.Add(tolerance)) {
+		return check
+	}
+
+	switch budget.OverrunPolicy {
+	case "allow":
+		check.Decision = BudgetDecisionApprove
+	case BudgetDecisionEscalate:
+		check.Decision = BudgetDecisionEscalate
+	default:
+		check.Decision = BudgetDecisionBlock
+	}
+	return check
+}
+
+// prAmount is the value of a PR in its own currency: the sum of its lines, or
+// the requested budget when lines are not priced
+func prAmount(pr *models.PurchaseRequest) money.Decimal {
+	total := money.Zero()
+	for _, item := range pr.Items {
+		if !item.TotalPrice.IsZero() {
+			total = total.Add(item.TotalPrice)
+		} else {
+			total = total.Add(item.UnitPrice.Mul(money.NewFromFloat(item.Quantity)))
+		}
+	}
+	if total.IsZero() {
+		return pr.Budget
+	}
+	return total
+}
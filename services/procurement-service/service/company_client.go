@@ -0,0 +1,82 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CompanyClient reads buyer and supplier company details from company-service
+type CompanyClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewCompanyClient() *CompanyClient {
+	baseURL := os.Getenv("COMPANY_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8002"
+	}
+
+	return &CompanyClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Company is the subset of a company-service company needed for tax
+type Company struct {
+	ID            uuid.UUID              `json:"id"`
+	Name          string                 `json:"name"`
+	TaxID         string                 `json:"tax_id"`
+	Country       string                 `json:"country"`
+	TaxExemptions []TaxExemptCertificate `json:"tax_exemptions"`
+}
+
+type TaxExemptCertificate struct {
+	CertificateNumber string     `json:"certificate_number"`
+	Country           string     `json:"country"`
+	Categories        string     `json:"categories"` // comma-separated
+	ValidFrom         time.Time  `json:"valid_from"`
+	ValidTo           *time.Time `json:"valid_to,omitempty"`
+	Status            string     `json:"status"` // pending, verified, revoked
+}
+
+func (c *CompanyClient) GetCompany(authToken string, id uuid.UUID) (*Company, error) {
+	url := fmt.Sprintf("%s/api/v1/companies/%s", c.baseURL, id)
+
+	httpReq, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if !strings.HasPrefix(authToken, "Bearer ") {
+		authToken = "Bearer " + authToken
+	}
+	httpReq.Header.Set("Authorization", authToken)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call company service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("company service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var company Company
+	if err := json.NewDecoder(resp.Body).Decode(&company); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &company, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+)
+
+// ErrBillingUnavailable is returned when billing-service cannot be reached or
+// fails, so callers can ask the user to retry rather than report a bad request
+var ErrBillingUnavailable = errors.New("billing service is unavailable, please retry later")
+
+// baseCurrencyTTL is how long a tenant's base currency is reused before it is
+// read from billing-service again. It rarely changes once set.
+const baseCurrencyTTL = 10 * time.Minute
+
+// FXConverter provides exchange rates and the tenant's reporting currency.
+// It is implemented by BillingClient, which owns the FX rate table.
+type FXConverter interface {
+	GetFXRate(authToken, from, to string, on time.Time) (*FXRate, error)
+	GetBaseCurrency(tenantID uuid.UUID, authToken string) (string, error)
+}
+
+// baseCurrencyCache holds the tenants' base currencies read from billing-service
+type baseCurrencyCache struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]cachedBaseCurrency
+}
+
+type cachedBaseCurrency struct {
+	currency  string
+	fetchedAt time.Time
+}
+
+// FXRate is a rate converting one unit of From into To
+type FXRate struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Rate     money.Decimal `json:"rate"`
+	RateDate time.Time     `json:"rate_date"`
+}
+
+// GetFXRate returns the latest rate on or before the given date
+func (c *BillingClient) GetFXRate(authToken, from, to string, on time.Time) (*FXRate, error) {
+	from = money.NormalizeCurrency(from)
+	to = money.NormalizeCurrency(to)
+	if from == to {
+		return &FXRate{From: from, To: to, Rate: money.NewFromInt(1), RateDate: on}, nil
+	}
+
+	query := url.Values{}
+	query.Set("from", from)
+	query.Set("to", to)
+	query.Set("date", on.Format("2006-01-02"))
+
+	var rate FXRate
+	if err := c.get(authToken, "/api/v1/fx-rates/rate?"+query.Encode(), &rate); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// GetBaseCurrency returns the reporting currency of the caller's tenant. It is
+// cached per tenant; while billing-service is down the last known one is used.
+func (c *BillingClient) GetBaseCurrency(tenantID uuid.UUID, authToken string) (string, error) {
+	c.baseCurrencies.mu.Lock()
+	cached, ok := c.baseCurrencies.entries[tenantID]
+	c.baseCurrencies.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < baseCurrencyTTL {
+		return cached.currency, nil
+	}
+
+	var resp struct {
+		BaseCurrency string `json:"base_currency"`
+	}
+	if err := c.get(authToken, "/api/v1/base-currency", &resp); err != nil {
+		if ok && errors.Is(err, ErrBillingUnavailable) {
+			return cached.currency, nil
+		}
+		return "", err
+	}
+
+	currency := money.NormalizeCurrency(resp.BaseCurrency)
+	c.baseCurrencies.mu.Lock()
+	if c.baseCurrencies.entries == nil {
+		c.baseCurrencies.entries = make(map[uuid.UUID]cachedBaseCurrency)
+	}
+	c.baseCurrencies.entries[tenantID] = cachedBaseCurrency{currency: currency, fetchedAt: time.Now()}
+	c.baseCurrencies.mu.Unlock()
+	return currency, nil
+}
+
+func (c *BillingClient) get(authToken, path string, out interface{}) error {
+	httpReq, err := http.NewRequest("GET", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if !strings.HasPrefix(authToken, "Bearer ") {
+		authToken = "Bearer " + authToken
+	}
+	httpReq.Header.Set("Authorization", authToken)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBillingUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return billingStatusError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// billingStatusError describes a failed billing-service response. Server
+// errors mean billing is unavailable rather than that the request was wrong.
+func billingStatusError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: status %d: %s", ErrBillingUnavailable, resp.StatusCode, string(body))
+	}
+	return fmt.Errorf("billing service returned status %d: %s", resp.StatusCode, string(body))
+}
+
+// convertAmount converts an amount between currencies, rounding to the target
+// currency's minor units, and returns the rate applied
+func convertAmount(fx FXConverter, authToken string, amount money.Decimal, from, to string, on time.Time) (money.Decimal, money.Decimal, error) {
+	if money.NormalizeCurrency(from) == money.NormalizeCurrency(to) {
+		return amount, money.NewFromInt(1), nil
+	}
+
+	rate, err := fx.GetFXRate(authToken, from, to, on)
+	if err != nil {
+		return money.Decimal{}, money.Decimal{}, err
+	}
+	converted := money.New(amount, from).Convert(rate.Rate, to)
+	return converted.Amount, rate.Rate, nil
+}
@@ -1,13 +1,17 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/b2b-platform/procurement-service/models"
 	"github.com/b2b-platform/procurement-service/repository"
 	"github.com/b2b-platform/shared/events"
+	"github.com/b2b-platform/shared/money"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ProcurementService struct {
@@ -15,6 +19,10 @@ type ProcurementService struct {
 	rfqRepo       *repository.RFQRepository
 	quoteRepo     *repository.QuoteRepository
 	poRepo        *repository.PORepository
+	invitationRepo        *repository.InvitationRepository
+	qualifiedSupplierRepo *repository.QualifiedSupplierRepository
+	budgetService         *BudgetService
+	taxService            *TaxService
 	eventBus      events.EventBus
 	billingClient *BillingClient
 }
@@ -24,6 +32,10 @@ func NewProcurementService(
 	rfqRepo *repository.RFQRepository,
 	quoteRepo *repository.QuoteRepository,
 	poRepo *repository.PORepository,
+	invitationRepo *repository.InvitationRepository,
+	qualifiedSupplierRepo *repository.QualifiedSupplierRepository,
+	budgetService *BudgetService,
+	taxService *TaxService,
 	eventBus events.EventBus,
 ) *ProcurementService {
 	return &ProcurementService{
@@ -31,6 +43,10 @@ func NewProcurementService(
 		rfqRepo:       rfqRepo,
 		quoteRepo:     quoteRepo,
 		poRepo:        poRepo,
+		invitationRepo:        invitationRepo,
+		qualifiedSupplierRepo: qualifiedSupplierRepo,
+		budgetService:         budgetService,
+		taxService:            taxService,
 		eventBus:      eventBus,
 		billingClient: NewBillingClient(),
 	}
@@ -50,29 +66,68 @@ func (s *ProcurementService) ListPRs(tenantID uuid.UUID, limit, offset int) ([]m
 	return s.prRepo.List(tenantID, limit, offset)
 }
 
-func (s *ProcurementService) ApprovePR(prID, approverID uuid.UUID) error {
+func (s *ProcurementService) ApprovePR(prID, approverID uuid.UUID, authToken string) error {
+	return s.approvePR(prID, approverID, false, authToken)
+}
+
+// CheckPRBudget previews how approving a PR would affect its cost center budget
+func (s *ProcurementService) CheckPRBudget(prID uuid.UUID, authToken string) (*BudgetCheck, error) {
 	pr, err := s.prRepo.GetByID(prID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return s.budgetService.CheckPR(pr, authToken)
+}
 
-	now := time.Now()
-	pr.Status = "approved"
-	pr.ApprovedAt = &now
-	pr.ApprovedBy = &approverID
+// ApproveBudgetOverrun lets the cost center's budget owner approve a PR that
+// was escalated for exceeding its budget
+func (s *ProcurementService) ApproveBudgetOverrun(prID, approverID uuid.UUID, authToken string) error {
+	return s.approvePR(prID, approverID, true, authToken)
+}
 
-	if err := s.prRepo.Update(pr); err != nil {
+func (s *ProcurementService) approvePR(prID, approverID uuid.UUID, overrideBudget bool, authToken string) error {
+	pr, err := s.prRepo.GetByID(prID)
+	if err != nil {
 		return err
 	}
 
-	// Add approval record
-	approval := &models.PRApproval{
-		PRID:       prID,
-		ApproverID: approverID,
-		Status:     "approved",
-		ApprovedAt: &now,
+	// A PR holds at most one commitment, so one already approved, or
+	// waiting on its budget owner, is not approved again
+	from, errNotApprovable := []string{"draft", "pending", "submitted"}, ErrPRNotApprovable
+	if overrideBudget {
+		from, errNotApprovable = []string{"budget_escalated"}, ErrPRNotEscalated
+	}
+	if !slices.Contains(from, pr.Status) {
+		return errNotApprovable
+	}
+
+	// Reserve the PR amount against its cost center budget and approve the PR
+	// in the same transaction. The status is checked again under the budget
+	// lock, so a concurrent approval neither reserves twice nor leaves a
+	// commitment behind.
+	check, err := s.budgetService.ReserveCommitment(pr, approverID, overrideBudget, authToken, func(tx *gorm.DB) error {
+		now := time.Now()
+		pr.Status = "approved"
+		pr.ApprovedAt = &now
+		pr.ApprovedBy = &approverID
+
+		err := repository.NewPRRepository(tx).Transition(pr, from, &models.PRApproval{
+			PRID:       prID,
+			ApproverID: approverID,
+			Status:     "approved",
+			ApprovedAt: &now,
+		})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errNotApprovable
+		}
+		return err
+	})
+	if errors.Is(err, ErrBudgetEscalated) {
+		return s.escalatePR(pr, approverID, check)
+	}
+	if err != nil {
+		return err
 	}
-	s.prRepo.AddApproval(approval)
 
 	// Publish event
 	event := events.NewEventEnvelope(
@@ -87,22 +142,81 @@ func (s *ProcurementService) ApprovePR(prID, approverID uuid.UUID) error {
 	return s.eventBus.Publish(nil, event)
 }
 
+// escalatePR parks a PR that overruns its budget until the budget owner decides
+func (s *ProcurementService) escalatePR(pr *models.PurchaseRequest, approverID uuid.UUID, check *BudgetCheck) error {
+	pr.Status = "budget_escalated"
+	if err := s.prRepo.Update(pr); err != nil {
+		return err
+	}
+
+	approval := &models.PRApproval{
+		PRID:       pr.ID,
+		ApproverID: approverID,
+		Status:     "escalated",
+		Comments:   fmt.Sprintf("Budget overrun: requested %s, available %s", money.New(check.Amount, check.Currency), money.New(check.Available, check.Currency)),
+	}
+	if err := s.prRepo.AddApproval(approval); err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"pr_id":     pr.ID.String(),
+		"pr_number": pr.PRNumber,
+		"budget_id": check.BudgetID.String(),
+		"currency":  check.Currency,
+		"amount":    check.Amount.String(),
+		"available": check.Available.String(),
+	}
+	if check.OwnerID != nil {
+		payload["owner_id"] = check.OwnerID.String()
+	}
+	event := events.NewEventEnvelope(
+		events.EventPRBudgetEscalated,
+		"procurement-service",
+		payload,
+	).WithTenantID(pr.TenantID)
+
+	if err := s.eventBus.Publish(nil, event); err != nil {
+		return err
+	}
+	return ErrBudgetEscalated
+}
+
 func (s *ProcurementService) CreateRFQ(rfq *models.RFQ) error {
 	rfq.RFQNumber = fmt.Sprintf("RFQ-%d", time.Now().Unix())
 	rfq.Status = "sent"
 
-	if err := s.rfqRepo.Create(rfq); err != nil {
+	// Fall back to the qualified suppliers for the category when no list is given
+	source := "manual"
+	supplierIDs := rfq.SupplierIDs
+	if len(supplierIDs) == 0 && rfq.CategoryID != nil {
+		suggested, err := s.SuggestSuppliers(rfq.TenantID, *rfq.CategoryID)
+		if err != nil {
+			return err
+		}
+		supplierIDs = suggested
+		source = "suggested"
+	}
+
+	invitations, invited := newInvitations(rfq, supplierIDs, source, rfq.CreatedBy)
+	if err := s.rfqRepo.CreateWithInvitations(rfq, invitations); err != nil {
 		return err
 	}
+	rfq.SupplierIDs = invited
 
 	// Publish event
 	event := events.NewEventEnvelope(
 		events.EventRFQCreated,
 		"procurement-service",
 		map[string]interface{}{
-			"rfq_id":    rfq.ID.String(),
-			"rfq_number": rfq.RFQNumber,
-			"pr_id":     rfq.PRID.String(),
+			"rfq_id":       rfq.ID.String(),
+			"rfq_number":   rfq.RFQNumber,
+			"pr_id":        rfq.PRID.String(),
+			"supplier_ids": uuidStrings(invited),
+			"title":        rfq.Title,
+			"description":  rfq.Description,
+			"status":       rfq.Status,
+			"due_date":     rfq.DueDate,
 		},
 	).WithTenantID(rfq.TenantID)
 
@@ -121,28 +235,80 @@ func (s *ProcurementService) GetPO(id uuid.UUID) (*models.PurchaseOrder, error)
 	return s.poRepo.GetByID(id)
 }
 
+// ListPOs returns the tenant's purchase orders, optionally only those sourced
+// against a BOM revision
+func (s *ProcurementService) ListPOs(tenantID uuid.UUID, bomRevisionID *uuid.UUID) ([]models.PurchaseOrder, error) {
+	if bomRevisionID != nil {
+		return s.poRepo.ListByBOMRevision(tenantID, *bomRevisionID)
+	}
+	return s.poRepo.List(tenantID)
+}
+
 func (s *ProcurementService) UpdatePO(po *models.PurchaseOrder) error {
 	return s.poRepo.Update(po)
 }
 
-func (s *ProcurementService) SubmitQuote(quote *models.Quote) error {
+func (s *ProcurementService) SubmitQuote(quote *models.Quote, authToken string) error {
 	quote.QuoteNumber = fmt.Sprintf("QT-%d", time.Now().Unix())
 	quote.Status = "submitted"
 	quote.SubmittedAt = time.Now()
 
+	rfq, err := s.rfqRepo.GetByID(quote.RFQID)
+	if err != nil {
+		return err
+	}
+	if rfq.Status != "sent" {
+		return ErrRFQNotOpen
+	}
+
+	// Only suppliers on the distribution list can quote, and not after declining
+	invitation, err := s.getInvitation(rfq.ID, quote.SupplierID)
+	if err != nil {
+		return err
+	}
+	if invitation.Status == "declined" {
+		return ErrAlreadyResponded
+	}
+
+	// The supplier sells to the tenant that issued the RFQ
+	if err := s.taxService.CalculateQuote(quote, rfq.TenantID, authToken); err != nil {
+		return fmt.Errorf("failed to calculate tax: %w", err)
+	}
+
 	if err := s.quoteRepo.Create(quote); err != nil {
 		return err
 	}
 
-	// Publish event
+	now := time.Now()
+	invitation.Status = "quoted"
+	invitation.RespondedAt = &now
+	if err := s.invitationRepo.Update(invitation); err != nil {
+		return err
+	}
+
+	// Publish event. The quote is searchable by the buyer that issued the
+	// RFQ as well as the supplier that submitted it.
+	items := make([]string, len(quote.Items))
+	for i, item := range quote.Items {
+		items[i] = item.Description
+	}
 	event := events.NewEventEnvelope(
 		events.EventQuoteSubmitted,
 		"procurement-service",
 		map[string]interface{}{
-			"quote_id":    quote.ID.String(),
-			"quote_number": quote.QuoteNumber,
-			"rfq_id":      quote.RFQID.String(),
-			"supplier_id": quote.SupplierID.String(),
+			"quote_id":        quote.ID.String(),
+			"quote_number":    quote.QuoteNumber,
+			"rfq_id":          quote.RFQID.String(),
+			"rfq_number":      rfq.RFQNumber,
+			"title":           rfq.Title,
+			"buyer_tenant_id": rfq.TenantID.String(),
+			"supplier_id":     quote.SupplierID.String(),
+			"status":          quote.Status,
+			"gross_amount":    quote.GrossAmount.String(),
+			"currency":        quote.Currency,
+			"valid_until":     quote.ValidUntil,
+			"notes":           quote.Notes,
+			"items":           items,
 		},
 	).WithTenantID(quote.TenantID)
 
@@ -163,16 +329,39 @@ func (s *ProcurementService) CreatePO(po *models.PurchaseOrder, authToken string
 		po.PaymentStatus = "pending"
 	}
 
-	if err := s.poRepo.Create(po); err != nil {
+	pr, err := s.prRepo.GetByID(po.PRID)
+	if err != nil {
+		return err
+	}
+
+	// Orders sourced from a BOM stay tied to the revision the PR was raised against
+	if po.BOMRevisionID == nil {
+		po.BOMRevisionID = pr.BOMRevisionID
+	}
+
+	if err := s.taxService.CalculatePO(po, authToken); err != nil {
+		return fmt.Errorf("failed to calculate tax: %w", err)
+	}
+
+	if err := s.freezeFXRate(po, authToken); err != nil {
 		return err
 	}
 
+	// Save the PO and move the PR's budget commitment to an encumbrance for it
+	// in one transaction
+	err = s.budgetService.Encumber(pr, po, authToken, func(tx *gorm.DB) error {
+		return repository.NewPORepository(tx).Create(po)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encumber budget: %w", err)
+	}
+
 	// If ESCROW mode, create payment intent with billing service
 	if po.PaymentMode == "ESCROW" {
 		paymentIntentReq := CreatePaymentIntentRequest{
 			OrderID:     po.ID,
 			SupplierID:  po.SupplierID,
-			Amount:      po.TotalAmount,
+			Amount:      po.GrossAmount,
 			Currency:    po.Currency,
 			PaymentMode: "ESCROW",
 			Metadata: map[string]interface{}{
@@ -197,18 +386,91 @@ func (s *ProcurementService) CreatePO(po *models.PurchaseOrder, authToken string
 	}
 
 	// Publish event
+	items := make([]string, len(po.Items))
+	for i, item := range po.Items {
+		items[i] = item.Description
+	}
+	payload := map[string]interface{}{
+		"po_id":          po.ID.String(),
+		"po_number":      po.PONumber,
+		"pr_id":          po.PRID.String(),
+		"rfq_id":         po.RFQID.String(),
+		"quote_id":       po.QuoteID.String(),
+		"supplier_id":    po.SupplierID.String(),
+		"title":          pr.Title,
+		"status":         po.Status,
+		"gross_amount":   po.GrossAmount.String(),
+		"currency":       po.Currency,
+		"items":          items,
+		"payment_mode":   po.PaymentMode,
+		"payment_status": po.PaymentStatus,
+	}
+	if po.BOMRevisionID != nil {
+		payload["bom_revision_id"] = po.BOMRevisionID.String()
+	}
 	event := events.NewEventEnvelope(
 		events.EventOrderPlaced,
 		"procurement-service",
-		map[string]interface{}{
-			"po_id":          po.ID.String(),
-			"po_number":      po.PONumber,
-			"pr_id":          po.PRID.String(),
-			"quote_id":       po.QuoteID.String(),
-			"payment_mode":   po.PaymentMode,
-			"payment_status": po.PaymentStatus,
-		},
+		payload,
 	).WithTenantID(po.TenantID)
 
 	return s.eventBus.Publish(nil, event)
 }
+
+// RecordPOActual books a goods receipt or invoice for a PO against its budget.
+// The amount is net of tax. Invoices are taxed and their tax lines returned.
+func (s *ProcurementService) RecordPOActual(poID uuid.UUID, sourceType string, sourceID uuid.UUID, amount money.Decimal, userID uuid.UUID, authToken string) ([]models.TaxLine, error) {
+	po, err := s.poRepo.GetByID(poID)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := s.prRepo.GetByID(po.PRID)
+	if err != nil {
+		return nil, err
+	}
+
+	var taxLines []models.TaxLine
+	if sourceType == "invoice" {
+		taxLines, err = s.taxService.TaxInvoice(po, sourceID, amount, authToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate tax: %w", err)
+		}
+	}
+
+	if err := s.budgetService.RecordActual(pr, po, sourceType, sourceID, amount, userID, authToken); err != nil {
+		return nil, err
+	}
+	return taxLines, nil
+}
+
+// freezeFXRate records the rate converting the PO currency into the tenant's
+// base currency on the day the PO is issued. Later rate changes do not affect it.
+// A PO in the base currency needs no rate and makes no further billing call.
+func (s *ProcurementService) freezeFXRate(po *models.PurchaseOrder, authToken string) error {
+	baseCurrency, err := s.billingClient.GetBaseCurrency(po.TenantID, authToken)
+	if err != nil {
+		return fmt.Errorf("failed to get base currency: %w", err)
+	}
+
+	po.Currency = money.NormalizeCurrency(po.Currency)
+	if po.Currency == baseCurrency {
+		now := time.Now()
+		po.BaseCurrency = baseCurrency
+		po.FXRate = money.NewFromInt(1)
+		po.FXRateDate = &now
+		po.BaseAmount = po.TotalAmount
+		return nil
+	}
+
+	rate, err := s.billingClient.GetFXRate(authToken, po.Currency, baseCurrency, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to get FX rate: %w", err)
+	}
+
+	po.BaseCurrency = baseCurrency
+	po.FXRate = rate.Rate
+	po.FXRateDate = &rate.RateDate
+	po.BaseAmount = money.New(po.TotalAmount, po.Currency).Convert(rate.Rate, baseCurrency).Amount
+	return nil
+}
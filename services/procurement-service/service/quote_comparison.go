@@ -0,0 +1,94 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/b2b-platform/procurement-service/models"
+	"github.com/b2b-platform/shared/money"
+	"github.com/google/uuid"
+)
+
+// ComparedQuote is a quote with its total converted to the tenant's base currency
+type ComparedQuote struct {
+	Rank        int           `json:"rank"`
+	QuoteID     uuid.UUID     `json:"quote_id"`
+	QuoteNumber string        `json:"quote_number"`
+	SupplierID  uuid.UUID     `json:"supplier_id"`
+	Status      string        `json:"status"`
+	TotalAmount money.Decimal `json:"total_amount"`
+	Currency    string        `json:"currency"`
+	FXRate      money.Decimal `json:"fx_rate"`
+	BaseAmount  money.Decimal `json:"base_amount"`
+	ValidUntil  time.Time     `json:"valid_until"`
+	MaxLeadTime int           `json:"max_lead_time"` // days
+	SubmittedAt time.Time     `json:"submitted_at"`
+}
+
+// QuoteComparison ranks the quotes received for an RFQ by base-currency total
+type QuoteComparison struct {
+	RFQID        uuid.UUID       `json:"rfq_id"`
+	BaseCurrency string          `json:"base_currency"`
+	RateDate     time.Time       `json:"rate_date"`
+	Quotes       []ComparedQuote `json:"quotes"`
+}
+
+// CompareQuotes converts every quote on an RFQ to the tenant's base currency at
+// today's rates and ranks them from cheapest to most expensive
+func (s *ProcurementService) CompareQuotes(rfqID, tenantID uuid.UUID, authToken string) (*QuoteComparison, error) {
+	quotes, err := s.quoteRepo.GetByRFQ(rfqID)
+	if err != nil {
+		return nil, err
+	}
+
+	baseCurrency, err := s.billingClient.GetBaseCurrency(tenantID, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	comparison := &QuoteComparison{
+		RFQID:        rfqID,
+		BaseCurrency: baseCurrency,
+		RateDate:     now,
+		Quotes:       make([]ComparedQuote, 0, len(quotes)),
+	}
+	for _, q := range quotes {
+		baseAmount, rate, err := convertAmount(s.billingClient, authToken, q.TotalAmount, q.Currency, baseCurrency, now)
+		if err != nil {
+			return nil, err
+		}
+
+		comparison.Quotes = append(comparison.Quotes, ComparedQuote{
+			QuoteID:     q.ID,
+			QuoteNumber: q.QuoteNumber,
+			SupplierID:  q.SupplierID,
+			Status:      q.Status,
+			TotalAmount: q.TotalAmount,
+			Currency:    money.NormalizeCurrency(q.Currency),
+			FXRate:      rate,
+			BaseAmount:  baseAmount,
+			ValidUntil:  q.ValidUntil,
+			MaxLeadTime: maxLeadTime(q.Items),
+			SubmittedAt: q.SubmittedAt,
+		})
+	}
+
+	sort.SliceStable(comparison.Quotes, func(i, j int) bool {
+		return comparison.Quotes[i].BaseAmount.LessThan(comparison.Quotes[j].BaseAmount)
+	})
+	for i := range comparison.Quotes {
+		comparison.Quotes[i].Rank = i + 1
+	}
+	return comparison, nil
+}
+
+func maxLeadTime(items []models.QuoteItem) int {
+	max := 0
+	for _, item := range items {
+		if item.LeadTime > max {
+			max = item.LeadTime
+		}
+	}
+	return max
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/b2b-platform/procurement-service/models"
+	"github.com/b2b-platform/shared/events"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRFQNotFound        = errors.New("RFQ not found")
+	ErrSupplierNotInvited = errors.New("supplier is not invited to this RFQ")
+	ErrRFQNotOpen         = errors.New("RFQ is not open for responses")
+	ErrAlreadyResponded   = errors.New("supplier has already responded to this RFQ")
+	ErrInvalidDueDate     = errors.New("amended due date must be later than the current due date")
+)
+
+// SupplierRFQView is the RFQ as seen by a single invited supplier. It omits the
+// distribution list and the quotes of competing suppliers.
+type SupplierRFQView struct {
+	ID          uuid.UUID            `json:"id"`
+	TenantID    uuid.UUID            `json:"tenant_id"`
+	RFQNumber   string               `json:"rfq_number"`
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Status      string               `json:"status"`
+	DueDate     time.Time            `json:"due_date"`
+	CategoryID  *uuid.UUID           `json:"category_id,omitempty"`
+	Items       []models.PRItem      `json:"items,omitempty"`
+	Invitation  models.RFQInvitation `json:"invitation"`
+	Quotes      []models.Quote       `json:"quotes,omitempty"`
+}
+
+// RFQResponseSummary gives the buyer an overview of how invited suppliers responded
+type RFQResponseSummary struct {
+	RFQID       uuid.UUID              `json:"rfq_id"`
+	Invited     int                    `json:"invited"`
+	Pending     int                    `json:"pending"`
+	Viewed      int                    `json:"viewed"`
+	Declined    int                    `json:"declined"`
+	Quoted      int                    `json:"quoted"`
+	Invitations []models.RFQInvitation `json:"invitations"`
+}
+
+// SuggestSuppliers returns the suppliers currently qualified for a catalog category
+func (s *ProcurementService) SuggestSuppliers(tenantID, categoryID uuid.UUID) ([]uuid.UUID, error) {
+	qualified, err := s.qualifiedSupplierRepo.ListActiveForCategory(tenantID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	supplierIDs := make([]uuid.UUID, 0, len(qualified))
+	for _, qs := range qualified {
+		supplierIDs = append(supplierIDs, qs.SupplierID)
+	}
+	return supplierIDs, nil
+}
+
+// InviteSuppliers adds suppliers to the distribution list of an open RFQ
+func (s *ProcurementService) InviteSuppliers(rfqID, tenantID uuid.UUID, supplierIDs []uuid.UUID, invitedBy uuid.UUID) ([]models.RFQInvitation, error) {
+	rfq, err := s.getTenantRFQ(rfqID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if rfq.Status != "sent" {
+		return nil, ErrRFQNotOpen
+	}
+
+	invitations, invited := newInvitations(rfq, supplierIDs, "manual", invitedBy)
+	if err := s.invitationRepo.CreateAll(invitations); err != nil {
+		return nil, fmt.Errorf("failed to invite suppliers: %w", err)
+	}
+
+	if len(invited) > 0 {
+		event := events.NewEventEnvelope(
+			events.EventRFQSuppliersInvited,
+			"procurement-service",
+			map[string]interface{}{
+				"rfq_id":       rfq.ID.String(),
+				"rfq_number":   rfq.RFQNumber,
+				"supplier_ids": uuidStrings(invited),
+			},
+		).WithTenantID(rfq.TenantID)
+
+		if err := s.eventBus.Publish(nil, event); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.invitationRepo.ListByRFQ(rfqID)
+}
+
+// GetRFQResponses returns response tracking for the buyer
+func (s *ProcurementService) GetRFQResponses(rfqID, tenantID uuid.UUID) (*RFQResponseSummary, error) {
+	if _, err := s.getTenantRFQ(rfqID, tenantID); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.invitationRepo.ListByRFQ(rfqID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &RFQResponseSummary{
+		RFQID:       rfqID,
+		Invited:     len(invitations),
+		Invitations: invitations,
+	}
+	for _, inv := range invitations {
+		switch inv.Status {
+		case "invited":
+			summary.Pending++
+		case "viewed":
+			summary.Viewed++
+		case "declined":
+			summary.Declined++
+		case "quoted":
+			summary.Quoted++
+		}
+	}
+	return summary, nil
+}
+
+// ListSupplierRFQs returns the RFQ invitations received by a supplier
+func (s *ProcurementService) ListSupplierRFQs(supplierID uuid.UUID, status string) ([]models.RFQInvitation, error) {
+	return s.invitationRepo.ListBySupplier(supplierID, status)
+}
+
+// GetSupplierRFQ returns the supplier-scoped view of an RFQ and records the first view
+func (s *ProcurementService) GetSupplierRFQ(rfqID, supplierID uuid.UUID) (*SupplierRFQView, error) {
+	invitation, err := s.getInvitation(rfqID, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	rfq, err := s.rfqRepo.GetByID(rfqID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation.ViewedAt == nil {
+		now := time.Now()
+		invitation.ViewedAt = &now
+		if invitation.Status == "invited" {
+			invitation.Status = "viewed"
+		}
+		if err := s.invitationRepo.Update(invitation); err != nil {
+			return nil, err
+		}
+	}
+
+	pr, err := s.prRepo.GetByID(rfq.PRID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &SupplierRFQView{
+		ID:          rfq.ID,
+		TenantID:    rfq.TenantID,
+		RFQNumber:   rfq.RFQNumber,
+		Title:       rfq.Title,
+		Description: rfq.Description,
+		Status:      rfq.Status,
+		DueDate:     rfq.DueDate,
+		CategoryID:  rfq.CategoryID,
+		Items:       pr.Items,
+		Invitation:  *invitation,
+	}
+	for _, quote := range rfq.Quotes {
+		if quote.SupplierID == supplierID {
+			view.Quotes = append(view.Quotes, quote)
+		}
+	}
+	return view, nil
+}
+
+// DeclineRFQ records that an invited supplier will not quote on an open RFQ
+func (s *ProcurementService) DeclineRFQ(rfqID, supplierID uuid.UUID, reason string) (*models.RFQInvitation, error) {
+	invitation, err := s.getInvitation(rfqID, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.Status == "declined" || invitation.Status == "quoted" {
+		return nil, ErrAlreadyResponded
+	}
+
+	rfq, err := s.rfqRepo.GetByID(rfqID)
+	if err != nil {
+		return nil, err
+	}
+	if rfq.Status != "sent" {
+		return nil, ErrRFQNotOpen
+	}
+
+	now := time.Now()
+	invitation.Status = "declined"
+	invitation.DeclineReason = reason
+	invitation.RespondedAt = &now
+	if err := s.invitationRepo.Update(invitation); err != nil {
+		return nil, err
+	}
+
+	event := events.NewEventEnvelope(
+		events.EventRFQDeclined,
+		"procurement-service",
+		map[string]interface{}{
+			"rfq_id":      rfqID.String(),
+			"supplier_id": supplierID.String(),
+			"reason":      reason,
+		},
+	).WithTenantID(invitation.TenantID)
+
+	if err := s.eventBus.Publish(nil, event); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// AmendRFQ records an amendment and optionally extends the RFQ due date. Only
+// the buyer tenant that issued the RFQ can amend it; all invited suppliers are
+// notified of the change.
+func (s *ProcurementService) AmendRFQ(rfqID, tenantID uuid.UUID, amendment *models.RFQAmendment) error {
+	rfq, err := s.getTenantRFQ(rfqID, tenantID)
+	if err != nil {
+		return err
+	}
+	if rfq.Status != "sent" {
+		return ErrRFQNotOpen
+	}
+	if amendment.NewDueDate != nil && !amendment.NewDueDate.After(rfq.DueDate) {
+		return ErrInvalidDueDate
+	}
+
+	amendment.TenantID = rfq.TenantID
+	amendment.RFQID = rfq.ID
+	amendment.AmendmentNumber = len(rfq.Amendments) + 1
+	amendment.PreviousDueDate = rfq.DueDate
+
+	if err := s.rfqRepo.AddAmendment(amendment); err != nil {
+		return err
+	}
+
+	if amendment.NewDueDate != nil {
+		rfq.DueDate = *amendment.NewDueDate
+		if err := s.rfqRepo.Update(rfq); err != nil {
+			return err
+		}
+	}
+
+	supplierIDs := make([]uuid.UUID, 0, len(rfq.Invitations))
+	for _, inv := range rfq.Invitations {
+		if inv.Status != "declined" {
+			supplierIDs = append(supplierIDs, inv.SupplierID)
+		}
+	}
+
+	payload := map[string]interface{}{
+		"rfq_id":           rfq.ID.String(),
+		"rfq_number":       rfq.RFQNumber,
+		"amendment_id":     amendment.ID.String(),
+		"amendment_number": amendment.AmendmentNumber,
+		"due_date":         rfq.DueDate.Format(time.RFC3339),
+		"supplier_ids":     uuidStrings(supplierIDs),
+	}
+	event := events.NewEventEnvelope(events.EventRFQAmended, "procurement-service", payload).
+		WithTenantID(rfq.TenantID)
+
+	return s.eventBus.Publish(nil, event)
+}
+
+func (s *ProcurementService) GetRFQAmendments(rfqID, tenantID uuid.UUID) ([]models.RFQAmendment, error) {
+	if _, err := s.getTenantRFQ(rfqID, tenantID); err != nil {
+		return nil, err
+	}
+	return s.rfqRepo.GetAmendments(rfqID)
+}
+
+// CreateQualifiedSupplier adds a supplier to the buyer's approved list for a category
+func (s *ProcurementService) CreateQualifiedSupplier(qs *models.QualifiedSupplier) error {
+	if qs.Status == "" {
+		qs.Status = "qualified"
+	}
+	return s.qualifiedSupplierRepo.Create(qs)
+}
+
+func (s *ProcurementService) ListQualifiedSuppliers(tenantID uuid.UUID, categoryID *uuid.UUID) ([]models.QualifiedSupplier, error) {
+	return s.qualifiedSupplierRepo.List(tenantID, categoryID)
+}
+
+// newInvitations builds invitations for the suppliers not yet invited to an
+// RFQ, returning them and the IDs of the suppliers they invite
+func newInvitations(rfq *models.RFQ, supplierIDs []uuid.UUID, source string, invitedBy uuid.UUID) ([]models.RFQInvitation, []uuid.UUID) {
+	seen := make(map[uuid.UUID]bool)
+	for _, inv := range rfq.Invitations {
+		seen[inv.SupplierID] = true
+	}
+
+	invitations := make([]models.RFQInvitation, 0, len(supplierIDs))
+	invited := make([]uuid.UUID, 0, len(supplierIDs))
+	for _, supplierID := range supplierIDs {
+		if supplierID == uuid.Nil || seen[supplierID] {
+			continue
+		}
+		seen[supplierID] = true
+
+		invitations = append(invitations, models.RFQInvitation{
+			TenantID:   rfq.TenantID,
+			RFQID:      rfq.ID,
+			SupplierID: supplierID,
+			Source:     source,
+			Status:     "invited",
+			InvitedBy:  invitedBy,
+		})
+		invited = append(invited, supplierID)
+	}
+	return invitations, invited
+}
+
+// getTenantRFQ loads an RFQ owned by the buying tenant. RFQs of other tenants
+// are reported as not found.
+func (s *ProcurementService) getTenantRFQ(rfqID, tenantID uuid.UUID) (*models.RFQ, error) {
+	rfq, err := s.rfqRepo.GetByID(rfqID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRFQNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rfq.TenantID != tenantID {
+		return nil, ErrRFQNotFound
+	}
+	return rfq, nil
+}
+
+func (s *ProcurementService) getInvitation(rfqID, supplierID uuid.UUID) (*models.RFQInvitation, error) {
+	invitation, err := s.invitationRepo.GetByRFQAndSupplier(rfqID, supplierID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSupplierNotInvited
+	}
+	return invitation, err
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = id.String()
+	}
+	return result
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/procurement-service/models"
+	"github.com/b2b-platform/procurement-service/repository"
+	"github.com/b2b-platform/shared/money"
+	"github.com/b2b-platform/shared/tax"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTaxRule  = errors.New("invalid tax rule")
+	ErrTaxRuleNotFound = errors.New("tax rule not found")
+)
+
+// TaxService calculates tax on quotes, POs and invoices with a pluggable tax.Engine and
+// keeps the tax lines on each document
+type TaxService struct {
+	engine        tax.Engine
+	taxRepo       *repository.TaxRepository
+	companyClient *CompanyClient
+}
+
+func NewTaxService(engine tax.Engine, taxRepo *repository.TaxRepository, companyClient *CompanyClient) *TaxService {
+	return &TaxService{
+		engine:        engine,
+		taxRepo:       taxRepo,
+		companyClient: companyClient,
+	}
+}
+
+func (s *TaxService) CreateRule(rule *models.TaxRule) error {
+	rule.DestinationCountry = strings.ToUpper(strings.TrimSpace(rule.DestinationCountry))
+	rule.OriginCountry = strings.ToUpper(strings.TrimSpace(rule.OriginCountry))
+	rule.TaxType = strings.ToUpper(strings.TrimSpace(rule.TaxType))
+
+	if rule.DestinationCountry == "" {
+		return fmt.Errorf("%w: destination_country is required", ErrInvalidTaxRule)
+	}
+	switch rule.TaxType {
+	case tax.TypeVAT, tax.TypeGST, tax.TypeSalesTax:
+	default:
+		return fmt.Errorf("%w: unsupported tax_type %q", ErrInvalidTaxRule, rule.TaxType)
+	}
+	if rule.Rate.Sign() < 0 || rule.Rate.GreaterThan(money.NewFromInt(100)) {
+		return fmt.Errorf("%w: rate must be between 0 and 100", ErrInvalidTaxRule)
+	}
+	if rule.ValidFrom.IsZero() {
+		rule.ValidFrom = time.Now().Truncate(24 * time.Hour)
+	}
+	if rule.ValidTo != nil && rule.ValidTo.Before(rule.ValidFrom) {
+		return fmt.Errorf("%w: valid_to is before valid_from", ErrInvalidTaxRule)
+	}
+
+	return s.taxRepo.CreateRule(rule)
+}
+
+func (s *TaxService) ListRules(destinationCountry string) ([]models.TaxRule, error) {
+	return s.taxRepo.ListRules(destinationCountry)
+}
+
+func (s *TaxService) DeleteRule(id uuid.UUID) error {
+	err := s.taxRepo.DeleteRule(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTaxRuleNotFound
+	}
+	return err
+}
+
+// Calculate runs the engine on an ad-hoc request without persisting anything
+func (s *TaxService) Calculate(req tax.Request) (*tax.Result, error) {
+	return s.engine.Calculate(req)
+}
+
+// GetDocumentTaxLines returns the tax lines stored for a quote, PO or invoice
+func (s *TaxService) GetDocumentTaxLines(documentType string, documentID uuid.UUID) ([]models.TaxLine, error) {
+	return s.taxRepo.ListLines(documentType, documentID)
+}
+
+// CalculateQuote taxes a quote supplied by its supplier to the buyer that
+// issued the RFQ. Item IDs are assigned up front so tax lines can reference them.
+func (s *TaxService) CalculateQuote(quote *models.Quote, buyerID uuid.UUID, authToken string) error {
+	lines := make([]tax.Line, 0, len(quote.Items))
+	for i := range quote.Items {
+		item := &quote.Items[i]
+		if item.ID == uuid.Nil {
+			item.ID = uuid.New()
+		}
+		lines = append(lines, tax.Line{
+			LineID:   item.ID.String(),
+			Category: item.TaxCategory,
+			Amount:   lineAmount(item.TotalPrice, item.UnitPrice, item.Quantity),
+		})
+	}
+
+	result, err := s.calculate(quote.SupplierID, buyerID, quote.Currency, quote.SubmittedAt, lines, authToken)
+	if err != nil {
+		return err
+	}
+
+	quote.TaxAmount = result.TotalTax
+	quote.GrossAmount = quote.TotalAmount.Add(result.TotalTax)
+	quote.TaxLines = toTaxLines(models.TaxDocumentQuote, result)
+	return nil
+}
+
+// CalculatePO taxes a PO issued by the buying tenant to its supplier
+func (s *TaxService) CalculatePO(po *models.PurchaseOrder, authToken string) error {
+	lines := make([]tax.Line, 0, len(po.Items))
+	for i := range po.Items {
+		item := &po.Items[i]
+		if item.ID == uuid.Nil {
+			item.ID = uuid.New()
+		}
+		lines = append(lines, tax.Line{
+			LineID:   item.ID.String(),
+			Category: item.TaxCategory,
+			Amount:   lineAmount(item.TotalPrice, item.UnitPrice, item.Quantity),
+		})
+	}
+	// A PO without itemised lines is taxed as a single uncategorised line
+	if len(lines) == 0 {
+		lines = append(lines, tax.Line{Amount: po.TotalAmount})
+	}
+
+	result, err := s.calculate(po.SupplierID, po.TenantID, po.Currency, time.Now(), lines, authToken)
+	if err != nil {
+		return err
+	}
+
+	po.TaxAmount = result.TotalTax
+	po.GrossAmount = po.TotalAmount.Add(result.TotalTax)
+	po.TaxLines = toTaxLines(models.TaxDocumentPO, result)
+	return nil
+}
+
+// TaxInvoice taxes a supplier invoice against a PO and stores its tax lines.
+// The invoiced net amount is spread over the PO's lines in proportion to
+// their amounts, so partial invoices keep each line's tax category.
+func (s *TaxService) TaxInvoice(po *models.PurchaseOrder, invoiceID uuid.UUID, amount money.Decimal, authToken string) ([]models.TaxLine, error) {
+	places := money.MinorUnits(po.Currency)
+	lines := make([]tax.Line, 0, len(po.Items))
+	if po.TotalAmount.Sign() > 0 {
+		share := amount.Div(po.TotalAmount)
+		allocated := money.Zero()
+		for _, item := range po.Items {
+			lineNet := lineAmount(item.TotalPrice, item.UnitPrice, item.Quantity).Mul(share).Round(places)
+			allocated = allocated.Add(lineNet)
+			lines = append(lines, tax.Line{
+				LineID:   item.ID.String(),
+				Category: item.TaxCategory,
+				Amount:   lineNet,
+			})
+		}
+		// Rounding differences go on the last line so the lines add up to the invoice
+		if len(lines) > 0 {
+			last := &lines[len(lines)-1]
+			last.Amount = last.Amount.Add(amount.Sub(allocated))
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, tax.Line{Amount: amount})
+	}
+
+	result, err := s.calculate(po.SupplierID, po.TenantID, po.Currency, time.Now(), lines, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	taxLines := toTaxLines(models.TaxDocumentInvoice, result)
+	for i := range taxLines {
+		taxLines[i].DocumentID = invoiceID
+	}
+	if err := s.taxRepo.ReplaceLines(models.TaxDocumentInvoice, invoiceID, taxLines); err != nil {
+		return nil, err
+	}
+	return taxLines, nil
+}
+
+func (s *TaxService) calculate(sellerID, buyerID uuid.UUID, currency string, on time.Time, lines []tax.Line, authToken string) (*tax.Result, error) {
+	seller, err := s.companyClient.GetCompany(authToken, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get supplier company: %w", err)
+	}
+	buyer, err := s.companyClient.GetCompany(authToken, buyerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buyer company: %w", err)
+	}
+
+	return s.engine.Calculate(tax.Request{
+		Seller:     tax.Party{Country: seller.Country, TaxID: seller.TaxID},
+		Buyer:      tax.Party{Country: buyer.Country, TaxID: buyer.TaxID},
+		Currency:   currency,
+		Date:       on,
+		Lines:      lines,
+		Exemptions: verifiedExemptions(buyer.TaxExemptions),
+	})
+}
+
+// verifiedExemptions drops certificates that have not been verified or were revoked
+func verifiedExemptions(certificates []TaxExemptCertificate) []tax.Exemption {
+	var exemptions []tax.Exemption
+	for _, cert := range certificates {
+		if cert.Status != "verified" {
+			continue
+		}
+
+		var categories []string
+		for _, category := range strings.Split(cert.Categories, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				categories = append(categories, category)
+			}
+		}
+
+		exemptions = append(exemptions, tax.Exemption{
+			CertificateNumber: cert.CertificateNumber,
+			Country:           cert.Country,
+			Categories:        categories,
+			ValidFrom:         cert.ValidFrom,
+			ValidTo:           cert.ValidTo,
+		})
+	}
+	return exemptions
+}
+
+func toTaxLines(documentType string, result *tax.Result) []models.TaxLine {
+	lines := make([]models.TaxLine, 0, len(result.Lines))
+	for _, l := range result.Lines {
+		line := models.TaxLine{
+			DocumentType:         documentType,
+			Category:             l.Category,
+			TaxType:              l.TaxType,
+			Jurisdiction:         l.Jurisdiction,
+			Rate:                 l.Rate,
+			TaxableAmount:        l.TaxableAmount,
+			TaxAmount:            l.TaxAmount,
+			Currency:             result.Currency,
+			Treatment:            l.Treatment,
+			ExemptionCertificate: l.ExemptionCertificate,
+		}
+		if id, err := uuid.Parse(l.LineID); err == nil {
+			line.LineID = &id
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func lineAmount(total, unitPrice money.Decimal, quantity float64) money.Decimal {
+	if !total.IsZero() {
+		return total
+	}
+	return unitPrice.Mul(money.NewFromFloat(quantity))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/search-indexer-service/service"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/google/uuid"
+)
+
+// Rebuilds search indices from their source services, e.g.
+//
+//	reindex -indices parts,listings
+//	reindex -tenant 6f1c... -indices equipment
+func main() {
+	indicesFlag := flag.String("indices", "", "comma separated indices to rebuild (default all)")
+	tenantFlag := flag.String("tenant", "", "only reindex this tenant's documents")
+	flag.Parse()
+
+	var indices []string
+	for _, index := range strings.Split(*indicesFlag, ",") {
+		if index = strings.TrimSpace(index); index != "" {
+			indices = append(indices, index)
+		}
+	}
+
+	var tenantID *uuid.UUID
+	if *tenantFlag != "" {
+		parsed, err := uuid.Parse(*tenantFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid tenant: %v\n", err)
+			os.Exit(1)
+		}
+		tenantID = &parsed
+	}
+
+	// Sources only export snapshots to admins
+	token, err := auth.NewJWTService().GenerateToken(uuid.Nil, uuid.Nil, "search-indexer", []string{"admin"})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create token: %v\n", err)
+		os.Exit(1)
+	}
+
+	reindexer := service.NewReindexer(service.NewOpenSearchBackend())
+	job, err := reindexer.Start(indices, tenantID, token)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start reindex: %v\n", err)
+		os.Exit(1)
+	}
+
+	for job.Status == service.ReindexRunning {
+		time.Sleep(2 * time.Second)
+		if job, err = reindexer.GetJob(job.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read reindex progress: %v\n", err)
+			os.Exit(1)
+		}
+		for _, progress := range job.Indices {
+			if progress.Status == service.ReindexRunning {
+				fmt.Printf("%s: %d fetched, %d indexed, %d stale, %d failed\n",
+					progress.Alias, progress.Fetched, progress.Indexed, progress.Stale, progress.Failed)
+			}
+		}
+	}
+
+	for _, progress := range job.Indices {
+		fmt.Printf("%s %s: %d fetched, %d indexed, %d unsearchable, %d stale, %d deleted, %d failed\n",
+			progress.Alias, progress.Status, progress.Fetched, progress.Indexed,
+			progress.Unsearchable, progress.Stale, progress.Deleted, progress.Failed)
+		if progress.Error != "" {
+			fmt.Printf("  %s\n", progress.Error)
+		}
+	}
+
+	if job.Status != service.ReindexCompleted {
+		fmt.Fprintf(os.Stderr, "Reindex failed: %s\n", job.Error)
+		os.Exit(1)
+	}
+	fmt.Println("Reindex completed")
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/b2b-platform/search-indexer-service/service"
+)
+
+// Scores search relevance on a fixed suite of documents and queries, failing
+// when it falls below the suite's thresholds, e.g.
+//
+//	relevance-eval
+//	relevance-eval -suite testdata/relevance/suite.json -fuzziness 1 -v
+func main() {
+	suitePath := flag.String("suite", "testdata/relevance/suite.json", "relevance suite to score")
+	fuzziness := flag.String("fuzziness", service.FuzzinessAuto, "edit distance words match within: AUTO, 0, 1 or 2")
+	verbose := flag.Bool("v", false, "list every query's results")
+	flag.Parse()
+
+	suite, err := service.LoadRelevanceSuite(*suitePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	report, err := service.RunRelevanceSuite(suite, *fuzziness)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to score relevance: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, score := range report.Cases {
+		fmt.Printf("%-40s ndcg %.3f  rr %.3f  recall %.3f  %q\n",
+			score.Name, score.NDCG, score.ReciprocalRank, score.Recall, score.Query)
+		if len(score.Missing) > 0 {
+			fmt.Printf("  missing: %s\n", strings.Join(score.Missing, ", "))
+		}
+		if *verbose {
+			fmt.Printf("  results: %s\n", strings.Join(score.Results, ", "))
+		}
+	}
+	fmt.Printf("\nmean ndcg %.3f (min %.3f)  mrr %.3f  mean recall %.3f (min %.3f)\n",
+		report.MeanNDCG, suite.MinNDCG, report.MRR, report.MeanRecall, suite.MinRecall)
+
+	if !report.Passed(suite) {
+		fmt.Fprintln(os.Stderr, "Relevance is below the suite's thresholds")
+		os.Exit(1)
+	}
+}
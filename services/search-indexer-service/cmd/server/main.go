@@ -25,8 +25,29 @@ func main() {
 	}
 
 	eventBus := events.NewRedisEventBus(redisClient)
-	indexerService := service.NewIndexerService()
-	searchService := service.NewSearchService()
+	backend := service.NewSearchBackend()
+	indexerService := service.NewIndexerService(backend)
+	searchService := service.NewSearchService(backend)
+	synonymService := service.NewSynonymService(backend)
+	if err := synonymService.Reload(); err != nil {
+		log.Printf("Searching without synonyms until they load: %v", err)
+	}
+	searchService.UseSynonyms(synonymService)
+	savedSearchService := service.NewSavedSearchService(backend, searchService)
+	indexerService.UseSearchAlerter(service.NewSearchAlerter(backend, searchService, eventBus))
+	searchAnalytics := service.NewSearchAnalytics(backend)
+	searchService.UseAnalytics(searchAnalytics)
+
+	// Batched writes and reindexing are OpenSearch's; the embedded backend writes in place
+	var bulkIndexer *service.BulkIndexer
+	var reindexer *service.Reindexer
+	if opensearch, ok := backend.(*service.OpenSearchBackend); ok {
+		bulkIndexer = service.NewBulkIndexer(opensearch, service.DefaultBulkConfig())
+		opensearch.UseBulkIndexer(bulkIndexer)
+		go bulkIndexer.Run()
+		reindexer = service.NewReindexer(opensearch)
+	}
+	go searchAnalytics.Run()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -48,7 +69,7 @@ func main() {
 	r.GET("/ready", healthChecker.Ready)
 
 	// Initialize handlers
-	searchHandler := handlers.NewSearchHandler(searchService)
+	searchHandler := handlers.NewSearchHandler(searchService, searchAnalytics)
 
 	// Search API routes (public, but JWT optional for enhanced access)
 	api := r.Group("/api/v1")
@@ -57,14 +78,44 @@ func main() {
 		// Public search endpoint (works without auth, but enhanced with auth)
 		api.GET("/search", searchHandler.Search)
 		api.GET("/search/autocomplete", searchHandler.Autocomplete)
+		api.POST("/search/clicks", searchHandler.RecordClick)
 	}
 
-	// Protected routes (optional - for admin operations like reindex)
-	protected := api.Group("/admin")
-	protected.Use(auth.OptionalAuthMiddleware(auth.NewJWTService()))
+	// Saved searches belong to signed in users
+	savedSearchHandler := handlers.NewSavedSearchHandler(savedSearchService)
+	savedSearches := api.Group("/saved-searches")
+	savedSearches.Use(auth.AuthMiddleware(auth.NewJWTService()))
 	{
-		// Future: reindex endpoint, stats, etc.
+		savedSearches.POST("", savedSearchHandler.CreateSavedSearch)
+		savedSearches.GET("", savedSearchHandler.ListSavedSearches)
+		savedSearches.GET("/:id", savedSearchHandler.GetSavedSearch)
+		savedSearches.PUT("/:id", savedSearchHandler.UpdateSavedSearch)
+		savedSearches.DELETE("/:id", savedSearchHandler.DeleteSavedSearch)
+		savedSearches.GET("/:id/results", savedSearchHandler.RunSavedSearch)
+	}
+
+	// Synonyms are managed by admins, and by company admins for their tenant
+	synonymHandler := handlers.NewSynonymHandler(synonymService)
+	synonyms := api.Group("/synonyms")
+	synonyms.Use(auth.AuthMiddleware(auth.NewJWTService()))
+	{
+		synonyms.GET("", synonymHandler.ListSynonymSets)
+		synonyms.POST("", synonymHandler.CreateSynonymSet)
+		synonyms.PUT("/:id", synonymHandler.UpdateSynonymSet)
+		synonyms.DELETE("/:id", synonymHandler.DeleteSynonymSet)
+	}
+
+	// Admin routes
+	protected := api.Group("/admin")
+	protected.Use(auth.AuthMiddleware(auth.NewJWTService()))
+	if reindexer != nil {
+		reindexHandler := handlers.NewReindexHandler(reindexer)
+		protected.POST("/reindex", reindexHandler.StartReindex)
+		protected.GET("/reindex", reindexHandler.ListReindexJobs)
+		protected.GET("/reindex/:id", reindexHandler.GetReindexJob)
 	}
+	searchAnalyticsHandler := handlers.NewSearchAnalyticsHandler(searchAnalytics)
+	protected.GET("/search-analytics", searchAnalyticsHandler.GetSearchAnalytics)
 
 	// Start HTTP server in goroutine
 	port := os.Getenv("PORT")
@@ -98,13 +149,19 @@ func main() {
 			log.Printf("Error handling event %s: %v", event.Type, err)
 			return err
 		}
-		fmt.Printf("Successfully indexed event: %s\n", event.Type)
+		fmt.Printf("Successfully queued event: %s\n", event.Type)
 		return nil
 	}
 
 	// Subscribe to all events
 	if err := eventBus.SubscribeAll(ctx, handler); err != nil {
 		if err == context.Canceled {
+			// Flush writes still queued for OpenSearch, analytics first as
+			// it writes through the bulk indexer
+			searchAnalytics.Close()
+			if bulkIndexer != nil {
+				bulkIndexer.Close()
+			}
 			fmt.Println("Search indexer stopped")
 			return
 		}
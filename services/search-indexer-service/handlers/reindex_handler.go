@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/b2b-platform/search-indexer-service/service"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReindexHandler struct {
+	reindexer *service.Reindexer
+}
+
+func NewReindexHandler(reindexer *service.Reindexer) *ReindexHandler {
+	return &ReindexHandler{
+		reindexer: reindexer,
+	}
+}
+
+type ReindexRequest struct {
+	Indices  []string `json:"indices"` // parts, companies, equipment, listings; all when empty
+	TenantID string   `json:"tenant_id"`
+}
+
+// StartReindex starts rebuilding search indices from their source services
+func (h *ReindexHandler) StartReindex(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	var req ReindexRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var tenantID *uuid.UUID
+	if req.TenantID != "" {
+		parsed, err := uuid.Parse(req.TenantID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tenant_id"})
+			return
+		}
+		tenantID = &parsed
+	}
+
+	// Sources are read with the caller's token, so they apply their own admin check
+	job, err := h.reindexer.Start(req.Indices, tenantID, c.GetHeader("Authorization"))
+	if err != nil {
+		respondReindexError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+func (h *ReindexHandler) GetReindexJob(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+
+	job, err := h.reindexer.GetJob(id)
+	if err != nil {
+		respondReindexError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *ReindexHandler) ListReindexJobs(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": h.reindexer.ListJobs()})
+}
+
+func respondReindexError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrReindexJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUnknownIndex), errors.Is(err, service.ErrNotTenantScoped):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReindexInProgress):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
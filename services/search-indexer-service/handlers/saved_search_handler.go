@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/b2b-platform/search-indexer-service/service"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type SavedSearchHandler struct {
+	savedSearchService *service.SavedSearchService
+}
+
+func NewSavedSearchHandler(savedSearchService *service.SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		savedSearchService: savedSearchService,
+	}
+}
+
+// searchUser reads the user a saved search belongs to from the request's token
+func searchUser(c *gin.Context) (userID, tenantID string, roles []string, ok bool) {
+	user, err := auth.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return "", "", nil, false
+	}
+	tenant, err := auth.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return "", "", nil, false
+	}
+	rolesVal, _ := c.Get(auth.RolesKey)
+	roles, _ = rolesVal.([]string)
+	return user.String(), tenant.String(), roles, true
+}
+
+// CreateSavedSearch saves a search, optionally alerting the user of new matches
+func (h *SavedSearchHandler) CreateSavedSearch(c *gin.Context) {
+	userID, tenantID, roles, ok := searchUser(c)
+	if !ok {
+		return
+	}
+
+	var input service.SavedSearchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search, err := h.savedSearchService.Create(userID, tenantID, roles, input)
+	if err != nil {
+		respondSavedSearchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+func (h *SavedSearchHandler) ListSavedSearches(c *gin.Context) {
+	userID, _, _, ok := searchUser(c)
+	if !ok {
+		return
+	}
+
+	searches, err := h.savedSearchService.List(userID)
+	if err != nil {
+		respondSavedSearchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"saved_searches": searches})
+}
+
+func (h *SavedSearchHandler) GetSavedSearch(c *gin.Context) {
+	userID, _, _, ok := searchUser(c)
+	if !ok {
+		return
+	}
+
+	search, err := h.savedSearchService.Get(c.Param("id"), userID)
+	if err != nil {
+		respondSavedSearchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+func (h *SavedSearchHandler) UpdateSavedSearch(c *gin.Context) {
+	userID, tenantID, roles, ok := searchUser(c)
+	if !ok {
+		return
+	}
+
+	var input service.SavedSearchInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search, err := h.savedSearchService.Update(c.Param("id"), userID, tenantID, roles, input)
+	if err != nil {
+		respondSavedSearchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+func (h *SavedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	userID, _, _, ok := searchUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.savedSearchService.Delete(c.Param("id"), userID); err != nil {
+		respondSavedSearchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "saved search deleted"})
+}
+
+// RunSavedSearch runs a saved search, paged and sorted as /search is
+func (h *SavedSearchHandler) RunSavedSearch(c *gin.Context) {
+	userID, tenantID, roles, ok := searchUser(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	search, results, total, facets, err := h.savedSearchService.Run(c.Param("id"), userID, tenantID, roles,
+		page, pageSize, c.DefaultQuery("sort", "relevance"))
+	if err != nil {
+		respondSavedSearchError(c, err)
+		return
+	}
+
+	searchResults := make([]SearchResult, len(results))
+	for i, r := range results {
+		searchResults[i] = SearchResult(r)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"saved_search": search,
+		"results":      searchResults,
+		"facets":       facets,
+		"total":        total,
+		"page":         page,
+		"page_size":    pageSize,
+	})
+}
+
+func respondSavedSearchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSavedSearchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidSavedSearch), errors.Is(err, service.ErrInvalidAttributeFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSavedSearchLimit):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
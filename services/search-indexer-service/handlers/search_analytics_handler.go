@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/b2b-platform/search-indexer-service/service"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SearchAnalyticsHandler struct {
+	analytics *service.SearchAnalytics
+}
+
+func NewSearchAnalyticsHandler(analytics *service.SearchAnalytics) *SearchAnalyticsHandler {
+	return &SearchAnalyticsHandler{
+		analytics: analytics,
+	}
+}
+
+// GetSearchAnalytics reports top and zero-result queries and click-through
+// per result position, for the last 7 days unless from and to (RFC 3339)
+// say otherwise
+func (h *SearchAnalyticsHandler) GetSearchAnalytics(c *gin.Context) {
+	if !auth.HasRole(c, "admin", "super_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return
+	}
+
+	var query service.AnalyticsQuery
+	for param, value := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if raw := c.Query(param); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + ", expected RFC 3339"})
+				return
+			}
+			*value = parsed
+		}
+	}
+	if tenantID := c.Query("tenant_id"); tenantID != "" {
+		if _, err := uuid.Parse(tenantID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tenant_id"})
+			return
+		}
+		query.TenantID = tenantID
+	}
+	query.Size, _ = strconv.Atoi(c.Query("size"))
+
+	report, err := h.analytics.Report(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
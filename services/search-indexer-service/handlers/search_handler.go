@@ -1,41 +1,56 @@
 package handlers
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
+	"errors"
 	"net/http"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/b2b-platform/search-indexer-service/service"
+	"github.com/b2b-platform/shared/geo"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type SearchHandler struct {
 	searchService *service.SearchService
+	analytics     *service.SearchAnalytics
 }
 
-func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+func NewSearchHandler(searchService *service.SearchService, analytics *service.SearchAnalytics) *SearchHandler {
 	return &SearchHandler{
 		searchService: searchService,
+		analytics:     analytics,
 	}
 }
 
 type SearchRequest struct {
 	Query    string            `form:"q" binding:"required"`
-	Type     string            `form:"type"` // all, part, equipment, company, listing, service
+	Type     string            `form:"type"` // all, part, equipment, company, listing, service; or procurement, rfq, quote, order, shipment
 	Page     int               `form:"page"`
 	PageSize int               `form:"page_size"`
 	Filters  map[string]string `form:"filters"`
-	Sort     string            `form:"sort"` // relevance, rating, price, eta
+	Sort     string            `form:"sort"` // relevance, rating, price, eta, distance, newest
+
+	// A site to search around, as "lat,lon" or a postal code and country,
+	// and how far from it results may be
+	Near           string  `form:"near"`
+	NearCountry    string  `form:"near_country"`
+	NearPostalCode string  `form:"near_postal_code"`
+	WithinKm       float64 `form:"within_km"`
+
+	// The period documents are dated in, as dates or RFC 3339 times; a
+	// procurement document is dated when it was issued
+	From string `form:"from"`
+	To   string `form:"to"`
 }
 
 type SearchResponse struct {
-	Results []SearchResult          `json:"results"`
-	Facets  map[string]interface{} `json:"facets"`
-	Total   int64                   `json:"total"`
-	Page    int                     `json:"page"`
+	SearchID string                 `json:"search_id"` // Identifies the search when recording a click on a result
+	Results  []SearchResult         `json:"results"`
+	Facets   map[string]interface{} `json:"facets"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
 	PageSize int                    `json:"page_size"`
 }
 
@@ -46,6 +61,16 @@ type SearchResult struct {
 	Description string                 `json:"description,omitempty"`
 	Fields      map[string]interface{} `json:"fields"`
 	Score       float64                `json:"score,omitempty"`
+	Position    int                    `json:"position"`       // Rank among all results, from 1
+	Link        string                 `json:"link,omitempty"` // API path of the result's document, for procurement documents
+}
+
+// SearchClickRequest records the result of a search a user opened
+type SearchClickRequest struct {
+	SearchID   string `json:"search_id" binding:"required"`
+	ResultID   string `json:"result_id" binding:"required"`
+	ResultType string `json:"result_type"`
+	Position   int    `json:"position" binding:"required"`
 }
 
 // Search handles the unified search endpoint
@@ -70,6 +95,22 @@ func (h *SearchHandler) Search(c *gin.Context) {
 		req.Sort = "relevance"
 	}
 
+	attributes, err := attributeFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	near, err := geoFilter(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dated, err := searchPeriod(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Get user context from JWT (if present)
 	userIDVal, _ := c.Get("user_id")
 	tenantIDVal, _ := c.Get("tenant_id")
@@ -101,6 +142,7 @@ func (h *SearchHandler) Search(c *gin.Context) {
 	}
 
 	// Perform search
+	started := time.Now()
 	results, total, facets, err := h.searchService.Search(
 		req.Query,
 		req.Type,
@@ -108,19 +150,51 @@ func (h *SearchHandler) Search(c *gin.Context) {
 		req.PageSize,
 		req.Sort,
 		req.Filters,
+		attributes,
+		near,
+		dated,
 		isGuest,
 		tenantID,
 		roles,
 	)
+	if errors.Is(err, service.ErrProcurementSearchForbidden) {
+		status := http.StatusForbidden
+		if isGuest {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed: " + err.Error()})
 		return
 	}
 
+	searchID := uuid.New().String()
+	entry := service.SearchLogEntry{
+		ID:          searchID,
+		Query:       req.Query,
+		Type:        req.Type,
+		Filters:     req.Filters,
+		Attributes:  attributeParams(c),
+		Sort:        req.Sort,
+		ResultCount: total,
+		LatencyMS:   float64(time.Since(started).Microseconds()) / 1000,
+		Page:        req.Page,
+		PageSize:    req.PageSize,
+		TenantID:    tenantID,
+		UserID:      userID,
+	}
+	if len(results) > 0 {
+		entry.FirstPosition = results[0].Position
+		entry.LastPosition = results[len(results)-1].Position
+	}
+	h.analytics.RecordSearch(entry)
+
 	// Filter sensitive fields for guests
 	searchResults := make([]SearchResult, len(results))
 	for i, r := range results {
-		searchResults[i] = r
+		searchResults[i] = SearchResult(r)
 		if isGuest {
 			// Remove sensitive fields
 			delete(searchResults[i].Fields, "stock")
@@ -128,7 +202,7 @@ func (h *SearchHandler) Search(c *gin.Context) {
 			delete(searchResults[i].Fields, "email")
 			delete(searchResults[i].Fields, "phone")
 			// Mask price if restricted
-			if price, ok := searchResults[i].Fields["price"].(float64); ok {
+			if _, ok := searchResults[i].Fields["price"].(float64); ok {
 				if restricted, _ := searchResults[i].Fields["price_restricted"].(bool); restricted {
 					searchResults[i].Fields["price"] = nil
 					searchResults[i].Fields["price_display"] = "Contact for pricing"
@@ -138,6 +212,7 @@ func (h *SearchHandler) Search(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, SearchResponse{
+		SearchID: searchID,
 		Results:  searchResults,
 		Facets:   facets,
 		Total:    total,
@@ -162,3 +237,121 @@ func (h *SearchHandler) Autocomplete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
 }
+
+// RecordClick records a click on a search result, for click-through reports
+func (h *SearchHandler) RecordClick(c *gin.Context) {
+	var req SearchClickRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	tenantID, _ := c.Get("tenant_id")
+	click := service.SearchClick{
+		SearchID:   req.SearchID,
+		ResultID:   req.ResultID,
+		ResultType: req.ResultType,
+		Position:   req.Position,
+	}
+	click.UserID, _ = userID.(string)
+	click.TenantID, _ = tenantID.(string)
+
+	if err := h.analytics.RecordClick(click); err != nil {
+		if errors.Is(err, service.ErrInvalidClick) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "click recorded"})
+}
+
+// attributeParams returns the attribute filters as given, to record with the search
+func attributeParams(c *gin.Context) map[string]string {
+	var params map[string]string
+	for key, values := range c.Request.URL.Query() {
+		if code, ok := strings.CutPrefix(key, "attr."); ok {
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[code] = strings.Join(values, ",")
+		}
+	}
+	return params
+}
+
+// geoFilter reads the site a search is centred on, if any
+func geoFilter(req SearchRequest) (*service.GeoFilter, error) {
+	origin, err := geo.ParseOrigin(req.Near, req.NearCountry, req.NearPostalCode)
+	if err != nil {
+		return nil, err
+	}
+	if req.WithinKm < 0 {
+		return nil, errors.New("within_km must not be negative")
+	}
+	if origin == nil {
+		if req.WithinKm > 0 {
+			return nil, errors.New("within_km needs near, or near_postal_code and near_country")
+		}
+		return nil, nil
+	}
+	return &service.GeoFilter{Origin: *origin, WithinKm: req.WithinKm}, nil
+}
+
+// searchPeriod reads the period results are dated in. A date given as "to"
+// includes the whole day.
+func searchPeriod(req SearchRequest) (service.Period, error) {
+	var period service.Period
+	if req.From != "" {
+		from, _, err := parseSearchTime(req.From)
+		if err != nil {
+			return period, errors.New("invalid from, expected a date or RFC 3339 time")
+		}
+		period.Since = &from
+	}
+	if req.To != "" {
+		to, isDate, err := parseSearchTime(req.To)
+		if err != nil {
+			return period, errors.New("invalid to, expected a date or RFC 3339 time")
+		}
+		if isDate {
+			to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		period.Until = &to
+	}
+	if period.Since != nil && period.Until != nil && period.Until.Before(*period.Since) {
+		return period, errors.New("to must not be before from")
+	}
+	return period, nil
+}
+
+func parseSearchTime(value string) (t time.Time, isDate bool, err error) {
+	if t, err = time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// attributeFilters reads part attribute filters, given as attr.<code>=<value>,
+// e.g. attr.bore_diameter=20..25mm&attr.voltage=24V
+func attributeFilters(c *gin.Context) ([]service.AttributeFilter, error) {
+	var filters []service.AttributeFilter
+	for key, values := range c.Request.URL.Query() {
+		code, ok := strings.CutPrefix(key, "attr.")
+		if !ok {
+			continue
+		}
+		for _, value := range values {
+			filter, err := service.ParseAttributeFilter(code, value)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, filter)
+		}
+	}
+	return filters, nil
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/b2b-platform/search-indexer-service/service"
+	"github.com/b2b-platform/shared/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type SynonymHandler struct {
+	synonymService *service.SynonymService
+}
+
+func NewSynonymHandler(synonymService *service.SynonymService) *SynonymHandler {
+	return &SynonymHandler{
+		synonymService: synonymService,
+	}
+}
+
+// synonymEditor reads who is managing synonyms. Admins manage every set,
+// company admins their tenant's.
+func synonymEditor(c *gin.Context) (tenantID string, admin bool, ok bool) {
+	admin = auth.HasRole(c, "admin", "super_admin")
+	if !admin && !auth.HasRole(c, "company_admin") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		return "", false, false
+	}
+
+	tenant, err := auth.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return "", false, false
+	}
+	return tenant.String(), admin, true
+}
+
+func (h *SynonymHandler) ListSynonymSets(c *gin.Context) {
+	tenantID, admin, ok := synonymEditor(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"synonym_sets": h.synonymService.List(tenantID, admin)})
+}
+
+// CreateSynonymSet adds synonyms, which searches use without a reindex
+func (h *SynonymHandler) CreateSynonymSet(c *gin.Context) {
+	tenantID, admin, ok := synonymEditor(c)
+	if !ok {
+		return
+	}
+
+	var input service.SynonymSetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	set, err := h.synonymService.Create(tenantID, admin, input)
+	if err != nil {
+		respondSynonymError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, set)
+}
+
+func (h *SynonymHandler) UpdateSynonymSet(c *gin.Context) {
+	tenantID, admin, ok := synonymEditor(c)
+	if !ok {
+		return
+	}
+
+	var input service.SynonymSetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	set, err := h.synonymService.Update(c.Param("id"), tenantID, admin, input)
+	if err != nil {
+		respondSynonymError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, set)
+}
+
+func (h *SynonymHandler) DeleteSynonymSet(c *gin.Context) {
+	tenantID, admin, ok := synonymEditor(c)
+	if !ok {
+		return
+	}
+
+	if err := h.synonymService.Delete(c.Param("id"), tenantID, admin); err != nil {
+		respondSynonymError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "synonym set deleted"})
+}
+
+func respondSynonymError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSynonymSetNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidSynonymSet):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSynonymSetForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
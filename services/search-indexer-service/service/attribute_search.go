@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/b2b-platform/shared/units"
+)
+
+// Parts carry their searchable attribute values as nested objects:
+//
+//	{"code": "bore_diameter", "unit": "mm", "value": "22", "number": 22, "number_max": 22,
+//	 "dimension": "length", "base_number": 0.022, "base_number_max": 0.022}
+//
+// A number is a range with equal bounds. Numbers are kept in the attribute's
+// unit and in the base unit of its dimension, so a filter given in any unit
+// of the dimension compares with them.
+
+var ErrInvalidAttributeFilter = errors.New("invalid attribute filter")
+
+// AttributeFilter narrows a part search by one attribute, to a value or to
+// numbers overlapping a range. A part whose value is itself a range matches
+// when the two ranges overlap.
+type AttributeFilter struct {
+	Code  string
+	Value string   // The value asked for, matched as text
+	Min   *float64 // Numeric bounds, either of which may be open
+	Max   *float64
+	// InBaseUnit is set when the bounds were given with a unit, and so are
+	// converted to the base unit of its dimension. Otherwise they are in the
+	// attribute's own unit.
+	InBaseUnit bool
+	// Dimension of the unit the bounds were given in, such as "length". Only
+	// attributes of that dimension match, so 5 kg never matches 5 m.
+	Dimension string
+}
+
+// ParseAttributeFilter reads an attribute filter: a value such as "24V",
+// "M8" or "stainless", or a range such as "20..25 mm", "20-25mm",
+// "20 to 25 mm", "20.." or "..25"
+func ParseAttributeFilter(code, raw string) (AttributeFilter, error) {
+	code = strings.TrimSpace(code)
+	raw = strings.TrimSpace(raw)
+	if code == "" || raw == "" {
+		return AttributeFilter{}, fmt.Errorf("%w: attribute and value are required", ErrInvalidAttributeFilter)
+	}
+
+	if lower, upper, ok := splitRange(raw); ok {
+		return parseRangeFilter(code, lower, upper)
+	}
+
+	filter := AttributeFilter{Code: code, Value: raw}
+	value, unit, err := units.ParseQuantity(raw)
+	if err != nil {
+		// Not a number, so only matched as text
+		return filter, nil
+	}
+	if unit == "" {
+		filter.Min, filter.Max = &value, &value
+		return filter, nil
+	}
+	if base, dimension, err := units.ToBase(value, unit); err == nil {
+		filter.Min, filter.Max = &base, &base
+		filter.InBaseUnit = true
+		filter.Dimension = dimension
+	}
+	// A number with an unknown unit, such as "3/4", is text too
+	return filter, nil
+}
+
+// splitRange splits a range into its bounds. A hyphen only separates bounds
+// that are both numbers, so "20-25mm" is a range but "M8-1.25" is not.
+func splitRange(raw string) (string, string, bool) {
+	for _, separator := range []string{"..", " to "} {
+		if lower, upper, ok := strings.Cut(raw, separator); ok {
+			return strings.TrimSpace(lower), strings.TrimSpace(upper), true
+		}
+	}
+
+	// Skip a leading sign
+	if i := strings.Index(raw[1:], "-"); i >= 0 {
+		lower, upper := strings.TrimSpace(raw[:i+1]), strings.TrimSpace(raw[i+2:])
+		if _, _, err := units.ParseQuantity(lower); err != nil {
+			return "", "", false
+		}
+		if _, _, err := units.ParseQuantity(upper); err != nil {
+			return "", "", false
+		}
+		return lower, upper, true
+	}
+	return "", "", false
+}
+
+func parseRangeFilter(code, lower, upper string) (AttributeFilter, error) {
+	filter := AttributeFilter{Code: code}
+
+	bounds := []string{lower, upper}
+	values := make([]*float64, 2)
+	boundUnits := make([]string, 2)
+	for i, bound := range bounds {
+		if bound == "" {
+			continue
+		}
+		value, unit, err := units.ParseQuantity(bound)
+		if err != nil {
+			return filter, fmt.Errorf("%w: %s: %v", ErrInvalidAttributeFilter, code, err)
+		}
+		values[i] = &value
+		boundUnits[i] = unit
+	}
+	if values[0] == nil && values[1] == nil {
+		return filter, fmt.Errorf("%w: %s: a range needs a bound", ErrInvalidAttributeFilter, code)
+	}
+
+	// "20-25 mm" puts the unit on the upper bound only
+	if boundUnits[0] == "" {
+		boundUnits[0] = boundUnits[1]
+	}
+	if boundUnits[1] == "" {
+		boundUnits[1] = boundUnits[0]
+	}
+
+	if boundUnits[0] != "" {
+		filter.InBaseUnit = true
+		for i, value := range values {
+			if value == nil {
+				continue
+			}
+			base, dimension, err := units.ToBase(*value, boundUnits[i])
+			if err != nil {
+				return filter, fmt.Errorf("%w: %s: %v", ErrInvalidAttributeFilter, code, err)
+			}
+			if filter.Dimension != "" && filter.Dimension != dimension {
+				return filter, fmt.Errorf("%w: %s: bounds are in units of %s and %s", ErrInvalidAttributeFilter, code, filter.Dimension, dimension)
+			}
+			filter.Dimension = dimension
+			values[i] = &base
+		}
+	}
+
+	filter.Min, filter.Max = values[0], values[1]
+	if filter.Min != nil && filter.Max != nil && *filter.Min > *filter.Max {
+		return filter, fmt.Errorf("%w: %s: lower bound is above upper bound", ErrInvalidAttributeFilter, code)
+	}
+	return filter, nil
+}
+
+// filter is the nested search filter for the attribute
+func (f AttributeFilter) filter() Filter {
+	code := Term("attributes.code", f.Code)
+	if f.Min == nil && f.Max == nil {
+		return NestedAll("attributes", code, Term("attributes.value", f.Value))
+	}
+
+	lowField, highField := "attributes.number", "attributes.number_max"
+	if f.InBaseUnit {
+		lowField, highField = "attributes.base_number", "attributes.base_number_max"
+	}
+
+	// The part's range overlaps the filter's: its top reaches the filter's
+	// bottom, and its bottom the filter's top. The bounds are widened past the
+	// noise unit conversion leaves, so 1 in still equals 25.4 mm.
+	overlap := []Filter{}
+	if f.InBaseUnit {
+		overlap = append(overlap, Term("attributes.dimension", f.Dimension))
+	}
+	if f.Min != nil {
+		min := *f.Min - conversionTolerance(*f.Min)
+		overlap = append(overlap, Between(highField, &min, nil))
+	}
+	if f.Max != nil {
+		max := *f.Max + conversionTolerance(*f.Max)
+		overlap = append(overlap, Between(lowField, nil, &max))
+	}
+
+	if f.Value == "" {
+		return NestedAll("attributes", append([]Filter{code}, overlap...)...)
+	}
+	// A single number may also be a text value, for attributes that are not numeric
+	return NestedAll("attributes", code, AnyOf(Term("attributes.value", f.Value), AllOf(overlap...)))
+}
+
+func conversionTolerance(value float64) float64 {
+	return math.Max(math.Abs(value), 1) * 1e-9
+}
+
+// partAttributes builds a part document's attribute values from its event
+func partAttributes(payload map[string]interface{}) []map[string]interface{} {
+	items, _ := payload["attributes"].([]interface{})
+	attributes := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		values, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		attribute := map[string]interface{}{
+			"code":      getString(values, "code"),
+			"name":      getString(values, "name"),
+			"data_type": getString(values, "data_type"),
+			"unit":      getString(values, "unit"),
+			"value":     getString(values, "value"),
+		}
+
+		if number, ok := values["number"].(float64); ok {
+			numberMax := number
+			if upper, ok := values["number_max"].(float64); ok {
+				numberMax = upper
+			}
+			attribute["number"] = number
+			attribute["number_max"] = numberMax
+
+			if unit := getString(values, "unit"); unit != "" {
+				if base, dimension, err := units.ToBase(number, unit); err == nil {
+					baseMax, _, _ := units.ToBase(numberMax, unit)
+					attribute["dimension"] = dimension
+					attribute["base_number"] = base
+					attribute["base_number_max"] = baseMax
+				}
+			}
+		}
+
+		attributes = append(attributes, attribute)
+	}
+	return attributes
+}
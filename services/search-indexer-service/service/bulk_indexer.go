@@ -0,0 +1,301 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Live writes are batched into _bulk requests, flushed when a batch is big
+// enough or old enough. The queue in front of the batcher is bounded, so a
+// slow or failing OpenSearch holds the event subscriber back instead of
+// buffering without limit.
+
+// BulkConfig sizes the batches of a BulkIndexer
+type BulkConfig struct {
+	FlushActions  int           // Flush once this many writes are buffered
+	FlushBytes    int           // or their request body reaches this size
+	FlushInterval time.Duration // or this long has passed
+	QueueSize     int           // Writes waiting for the batcher before Add blocks
+	MaxRetries    int           // Retries of writes OpenSearch could not take
+	RetryBackoff  time.Duration // Wait before the first retry, doubling after each
+}
+
+func DefaultBulkConfig() BulkConfig {
+	return BulkConfig{
+		FlushActions:  500,
+		FlushBytes:    5 << 20,
+		FlushInterval: time.Second,
+		QueueSize:     2000,
+		MaxRetries:    5,
+		RetryBackoff:  200 * time.Millisecond,
+	}
+}
+
+var (
+	errBulkIndexerClosed = errors.New("bulk indexer is closed")
+	// errBulkUnavailable marks bulk requests worth retrying: OpenSearch was
+	// unreachable, overloaded or failing
+	errBulkUnavailable = errors.New("opensearch unavailable")
+)
+
+// bulkAction is one write in a _bulk request
+type bulkAction struct {
+	op           string // index, delete or update
+	target       string
+	documentID   string
+	version      int64
+	requireAlias bool
+	// recreateIndex is the index a live write goes to. When its alias is
+	// gone the index is created again and the write retried.
+	recreateIndex string
+	body          map[string]interface{} // The document to index, or the fields to update
+	encoded       []byte
+}
+
+func newBulkAction(op, target, documentID string, version int64, requireAlias bool, body map[string]interface{}) (bulkAction, error) {
+	action := bulkAction{
+		op:           op,
+		target:       target,
+		documentID:   documentID,
+		version:      version,
+		requireAlias: requireAlias,
+		body:         body,
+	}
+
+	meta := map[string]interface{}{"_index": target, "_id": documentID}
+	if version > 0 {
+		meta["version"] = version
+		meta["version_type"] = "external_gte"
+	}
+	if requireAlias {
+		meta["require_alias"] = true
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	if err := encoder.Encode(map[string]interface{}{op: meta}); err != nil {
+		return action, fmt.Errorf("failed to marshal bulk action: %w", err)
+	}
+	switch op {
+	case "index":
+		if err := encoder.Encode(body); err != nil {
+			return action, fmt.Errorf("failed to marshal document: %w", err)
+		}
+	case "update":
+		if err := encoder.Encode(map[string]interface{}{"doc": body}); err != nil {
+			return action, fmt.Errorf("failed to marshal document: %w", err)
+		}
+	}
+	action.encoded = buf.Bytes()
+	return action, nil
+}
+
+// accepted reports whether a write's outcome leaves the index as intended,
+// with the same leniency as the single-document writes
+func (a bulkAction) accepted(status int) bool {
+	switch {
+	case status < 300:
+		return true
+	case status == http.StatusConflict && a.version > 0:
+		// A newer version is already indexed
+		return true
+	case status == http.StatusNotFound:
+		// Deleting or updating a document that is not indexed, or writing to
+		// a rebuild that finished
+		return a.op == "delete" || a.op == "update" || a.requireAlias
+	default:
+		return false
+	}
+}
+
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// bulkItem is the outcome of one write in a _bulk request
+type bulkItem struct {
+	Status int
+	Error  string
+}
+
+// sendBulk sends an NDJSON _bulk body and returns the outcome of each write
+// in order
+func (s *OpenSearchBackend) sendBulk(body []byte) ([]bulkItem, error) {
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/_bulk", s.opensearchURL), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to bulk index: %v", errBulkUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		if retryableStatus(resp.StatusCode) {
+			return nil, fmt.Errorf("%w: %s - %s", errBulkUnavailable, resp.Status, string(respBody))
+		}
+		return nil, fmt.Errorf("opensearch error: %s - %s", resp.Status, string(respBody))
+	}
+
+	var response struct {
+		Items []map[string]struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	items := make([]bulkItem, 0, len(response.Items))
+	for _, item := range response.Items {
+		// Each item holds a single action's outcome, keyed by the action
+		for _, outcome := range item {
+			items = append(items, bulkItem{Status: outcome.Status, Error: string(outcome.Error)})
+		}
+	}
+	return items, nil
+}
+
+// BulkIndexer batches live document writes into _bulk requests
+type BulkIndexer struct {
+	backend *OpenSearchBackend
+	config  BulkConfig
+	queue   chan bulkAction
+	stopped chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewBulkIndexer(backend *OpenSearchBackend, config BulkConfig) *BulkIndexer {
+	return &BulkIndexer{
+		backend: backend,
+		config:  config,
+		queue:   make(chan bulkAction, config.QueueSize),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Add queues a write, blocking while the queue is full
+func (b *BulkIndexer) Add(action bulkAction) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return errBulkIndexerClosed
+	}
+	b.queue <- action
+	return nil
+}
+
+// Run batches queued writes until the indexer is closed
+func (b *BulkIndexer) Run() {
+	defer close(b.stopped)
+
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []bulkAction
+	size := 0
+	for {
+		select {
+		case action, ok := <-b.queue:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			batch = append(batch, action)
+			size += len(action.encoded)
+			if len(batch) < b.config.FlushActions && size < b.config.FlushBytes {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		b.flush(batch)
+		batch = nil
+		size = 0
+	}
+}
+
+// Close stops taking writes and waits for the queued ones to be flushed
+func (b *BulkIndexer) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+	<-b.stopped
+}
+
+// flush writes a batch, retrying what OpenSearch could not take with backoff.
+// Writes still failing after the last retry are logged and dropped.
+func (b *BulkIndexer) flush(batch []bulkAction) {
+	backoff := b.config.RetryBackoff
+	for attempt := 0; len(batch) > 0; attempt++ {
+		if attempt > 0 {
+			if attempt > b.config.MaxRetries {
+				log.Printf("Dropped %d search writes after %d retries", len(batch), b.config.MaxRetries)
+				return
+			}
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		batch = b.send(batch)
+	}
+}
+
+// send writes a batch once and returns the writes worth retrying
+func (b *BulkIndexer) send(batch []bulkAction) []bulkAction {
+	var body bytes.Buffer
+	for _, action := range batch {
+		body.Write(action.encoded)
+	}
+
+	items, err := b.backend.sendBulk(body.Bytes())
+	if err != nil {
+		log.Printf("Bulk write of %d documents failed: %v", len(batch), err)
+		if errors.Is(err, errBulkUnavailable) {
+			return batch
+		}
+		return nil
+	}
+	if len(items) != len(batch) {
+		log.Printf("Bulk write of %d documents returned %d results", len(batch), len(items))
+		return nil
+	}
+
+	var retry []bulkAction
+	for i, item := range items {
+		action := batch[i]
+		switch {
+		case item.Status == http.StatusNotFound && action.recreateIndex != "":
+			b.backend.forgetIndex(action.recreateIndex)
+			if err := b.backend.createIndexIfNotExists(action.recreateIndex); err != nil {
+				log.Printf("Failed to create index %s again: %v", action.recreateIndex, err)
+			}
+			retry = append(retry, action)
+		case action.accepted(item.Status):
+		case retryableStatus(item.Status):
+			retry = append(retry, action)
+		default:
+			log.Printf("Failed to %s %s/%s: %d %s", action.op, action.target, action.documentID, item.Status, item.Error)
+		}
+	}
+	return retry
+}
@@ -0,0 +1,786 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/b2b-platform/shared/geo"
+)
+
+// EmbeddedBackend keeps search documents in memory and searches them in
+// process. It follows the OpenSearch backend closely enough for local runs
+// and tests: text is split into lowercase words, a document matches when one
+// field holds every word of the query or of one of its alternatives, or when
+// an identifier equals the query, and matches are scored by how often and how
+// rarely the words occur. Filters, sorts and facets compare stored
+// values as OpenSearch compares keyword fields.
+type EmbeddedBackend struct {
+	mu            sync.RWMutex
+	indices       map[string]*embeddedIndex
+	savedSearches map[string]embeddedSavedSearch
+	synonymSets   map[string]SynonymSet
+}
+
+type embeddedIndex struct {
+	documents map[string]*embeddedDocument
+	// Versions of deleted documents, so an older write cannot bring one back
+	deleted map[string]int64
+}
+
+type embeddedDocument struct {
+	source  map[string]interface{}
+	version int64
+}
+
+// autocompleteMinPrefix matches the autocomplete analyzer's shortest n-gram
+const autocompleteMinPrefix = 2
+
+func NewEmbeddedBackend() *EmbeddedBackend {
+	return &EmbeddedBackend{
+		indices:       make(map[string]*embeddedIndex),
+		savedSearches: make(map[string]embeddedSavedSearch),
+		synonymSets:   make(map[string]SynonymSet),
+	}
+}
+
+// index returns an index, creating it if needed. The caller holds the write lock.
+func (b *EmbeddedBackend) index(name string) *embeddedIndex {
+	idx, ok := b.indices[name]
+	if !ok {
+		idx = &embeddedIndex{
+			documents: make(map[string]*embeddedDocument),
+			deleted:   make(map[string]int64),
+		}
+		b.indices[name] = idx
+	}
+	return idx
+}
+
+func (b *EmbeddedBackend) IndexDocument(index, documentID string, document map[string]interface{}, version int64) error {
+	source, err := normalizeDocument(document)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	idx := b.index(index)
+	if version > 0 {
+		if existing, ok := idx.documents[documentID]; ok && existing.version > version {
+			return nil
+		}
+		if deletedAt, ok := idx.deleted[documentID]; ok && deletedAt > version {
+			return nil
+		}
+	}
+
+	delete(idx.deleted, documentID)
+	idx.documents[documentID] = &embeddedDocument{source: source, version: version}
+	return nil
+}
+
+func (b *EmbeddedBackend) DeleteDocument(index, documentID string, version int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	idx := b.index(index)
+	if version > 0 {
+		if existing, ok := idx.documents[documentID]; ok && existing.version > version {
+			return nil
+		}
+		idx.deleted[documentID] = version
+	}
+	delete(idx.documents, documentID)
+	return nil
+}
+
+func (b *EmbeddedBackend) UpdateDocument(index, documentID string, fields map[string]interface{}) error {
+	updates, err := normalizeDocument(fields)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	existing, ok := b.index(index).documents[documentID]
+	if !ok {
+		return nil
+	}
+	for key, value := range updates {
+		existing.source[key] = value
+	}
+	return nil
+}
+
+func (b *EmbeddedBackend) Search(query SearchQuery) (*SearchHits, error) {
+	matcher := newTextMatcher(query)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	matches := []SearchHit{}
+	for _, name := range query.Indices {
+		idx, ok := b.indices[name]
+		if !ok {
+			continue
+		}
+
+		type candidate struct {
+			documentID  string
+			source      map[string]interface{}
+			frequencies []map[string]float64
+		}
+		candidates := []candidate{}
+		documentFrequency := make(map[string]int, len(matcher.words))
+		for documentID, document := range idx.documents {
+			if !matchesFilter(document.source, query.Filter) {
+				continue
+			}
+
+			frequencies := matcher.frequencies(document.source)
+			for _, word := range matcher.words {
+				for _, fieldFrequencies := range frequencies {
+					if fieldFrequencies[word] > 0 {
+						documentFrequency[word]++
+						break
+					}
+				}
+			}
+			candidates = append(candidates, candidate{documentID: documentID, source: document.source, frequencies: frequencies})
+		}
+
+		for _, c := range candidates {
+			score, ok := matcher.score(c.source, c.frequencies, documentFrequency, len(candidates))
+			if !ok {
+				continue
+			}
+			matches = append(matches, SearchHit{Index: name, ID: c.documentID, Score: score, Source: c.source})
+		}
+	}
+
+	sortHits(matches, query.Sort)
+
+	hits := &SearchHits{
+		Total:  int64(len(matches)),
+		Facets: make(map[string][]FacetBucket, len(query.Facets)),
+	}
+	for name, field := range query.Facets {
+		hits.Facets[name] = countFacet(matches, field)
+	}
+	if query.AttributeFacets {
+		hits.Attributes = summarizeAttributes(matches)
+	}
+
+	from := query.From
+	if from > len(matches) {
+		from = len(matches)
+	}
+	to := from + query.Size
+	if to > len(matches) {
+		to = len(matches)
+	}
+	hits.Hits = copySources(matches[from:to])
+	return hits, nil
+}
+
+// Autocomplete matches documents with a word starting with a word of the
+// text, scored by how many words match and the boosts of their fields
+func (b *EmbeddedBackend) Autocomplete(query AutocompleteQuery) ([]SearchHit, error) {
+	prefixes := []string{}
+	for _, word := range tokenize(query.Text) {
+		if len(word) >= autocompleteMinPrefix {
+			prefixes = append(prefixes, word)
+		}
+	}
+	fields := parseBoostedFields(query.Fields)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	matches := []SearchHit{}
+	for _, name := range query.Indices {
+		idx, ok := b.indices[name]
+		if !ok {
+			continue
+		}
+
+		for documentID, document := range idx.documents {
+			score := 0.0
+			for _, field := range fields {
+				fieldWords := tokenize(fieldText(document.source[field.name]))
+				for _, prefix := range prefixes {
+					for _, word := range fieldWords {
+						if strings.HasPrefix(word, prefix) {
+							score += field.boost
+							break
+						}
+					}
+				}
+			}
+			if score > 0 {
+				matches = append(matches, SearchHit{Index: name, ID: documentID, Score: score, Source: document.source})
+			}
+		}
+	}
+
+	sortHits(matches, []SortField{{Field: ScoreField, Desc: true}})
+	if len(matches) > query.Size {
+		matches = matches[:query.Size]
+	}
+	return copySources(matches), nil
+}
+
+type boostedField struct {
+	name  string
+	boost float64
+}
+
+// parseBoostedFields reads fields written as "name^3"
+func parseBoostedFields(fields []string) []boostedField {
+	parsed := make([]boostedField, 0, len(fields))
+	for _, field := range fields {
+		name, rawBoost, boosted := strings.Cut(field, "^")
+		boost := 1.0
+		if boosted {
+			if value, err := strconv.ParseFloat(rawBoost, 64); err == nil {
+				boost = value
+			}
+		}
+		parsed = append(parsed, boostedField{name: name, boost: boost})
+	}
+	return parsed
+}
+
+// fuzzyMatchWeight is how much a word some edits away counts for, against
+// the word itself
+const fuzzyMatchWeight = 0.5
+
+// identifierMatchScore scores a document whose identifier is the query,
+// before the identifier field's boost
+const identifierMatchScore = 2.0
+
+// textMatcher matches documents to a query's text, the text's alternatives
+// and the query's identifiers
+type textMatcher struct {
+	phrasings   [][]string // Words of the text and of each alternative
+	words       []string   // Every word of the phrasings, once
+	fields      []boostedField
+	fuzziness   string
+	identifier  string // The text, normalized as identifiers are
+	identifiers []boostedField
+}
+
+func newTextMatcher(query SearchQuery) *textMatcher {
+	m := &textMatcher{
+		fields:      parseBoostedFields(query.Fields),
+		fuzziness:   query.Fuzziness,
+		identifier:  normalizeIdentifier(query.Text),
+		identifiers: parseBoostedFields(query.Identifiers),
+	}
+
+	seen := make(map[string]bool)
+	for _, text := range append([]string{query.Text}, query.Alternatives...) {
+		words := tokenize(text)
+		m.phrasings = append(m.phrasings, words)
+		for _, word := range words {
+			if !seen[word] {
+				seen[word] = true
+				m.words = append(m.words, word)
+			}
+		}
+	}
+	return m
+}
+
+// frequencies counts each word of the query in each field of a document. A
+// word within the query's fuzziness counts for less than the word itself.
+func (m *textMatcher) frequencies(source map[string]interface{}) []map[string]float64 {
+	frequencies := make([]map[string]float64, len(m.fields))
+	for i, field := range m.fields {
+		fieldWords := tokenize(fieldText(source[field.name]))
+		frequencies[i] = make(map[string]float64, len(m.words))
+		for _, word := range m.words {
+			edits := maxEdits(m.fuzziness, word)
+			for _, fieldWord := range fieldWords {
+				if fieldWord == word {
+					frequencies[i][word]++
+				} else if edits > 0 && withinEdits(word, fieldWord, edits) {
+					frequencies[i][word] += fuzzyMatchWeight
+				}
+			}
+		}
+	}
+	return frequencies
+}
+
+// score scores a document by its best matching wording or identifier,
+// reporting false when none matches
+func (m *textMatcher) score(source map[string]interface{}, frequencies []map[string]float64, documentFrequency map[string]int, documents int) (float64, bool) {
+	best := 0.0
+	matched := false
+	for _, words := range m.phrasings {
+		if score, ok := bestFieldScore(words, m.fields, frequencies, documentFrequency, documents); ok && (!matched || score > best) {
+			best = score
+			matched = true
+		}
+	}
+
+	if m.identifier != "" {
+		for _, identifier := range m.identifiers {
+			for _, value := range fieldValues(source[identifier.name]) {
+				if normalizeIdentifier(value) != m.identifier {
+					continue
+				}
+				if score := identifierMatchScore * identifier.boost; !matched || score > best {
+					best = score
+					matched = true
+				}
+			}
+		}
+	}
+	return best, matched
+}
+
+// bestFieldScore scores a document by its best field holding every word,
+// reporting false when no field does
+func bestFieldScore(words []string, fields []boostedField, frequencies []map[string]float64, documentFrequency map[string]int, documents int) (float64, bool) {
+	if len(words) == 0 {
+		return 1, true
+	}
+
+	best := 0.0
+	matched := false
+	for i, field := range fields {
+		score := 0.0
+		all := true
+		for _, word := range words {
+			frequency := frequencies[i][word]
+			if frequency == 0 {
+				all = false
+				break
+			}
+			df := float64(documentFrequency[word])
+			idf := math.Log(1 + (float64(documents)-df+0.5)/(df+0.5))
+			score += idf * frequency / (frequency + 1.2)
+		}
+		if !all {
+			continue
+		}
+
+		score *= field.boost
+		if !matched || score > best {
+			best = score
+			matched = true
+		}
+	}
+	return best, matched
+}
+
+// withinEdits reports whether two words are at most max insertions,
+// deletions, substitutions or transpositions apart. As with OpenSearch's
+// prefix length of 1, the first letter must match.
+func withinEdits(a, b string, max int) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 || ra[0] != rb[0] {
+		return false
+	}
+	if diff := len(ra) - len(rb); diff > max || -diff > max {
+		return false
+	}
+
+	// Optimal string alignment distance
+	previous2 := make([]int, len(rb)+1)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(rb)] <= max
+}
+
+// fieldValues lists the text values of a field holding one or many
+func fieldValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func matchesFilter(source map[string]interface{}, filter Filter) bool {
+	switch {
+	case filter.Nested != "":
+		items, _ := source[filter.Nested].([]interface{})
+		for _, item := range items {
+			object, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// Name the object's fields as the filters do
+			fields := make(map[string]interface{}, len(object))
+			for key, value := range object {
+				fields[filter.Nested+"."+key] = value
+			}
+			if matchesFilter(fields, AllOf(filter.All...)) {
+				return true
+			}
+		}
+		return false
+	case filter.Field != "" && filter.Near != nil:
+		point, ok := sourcePoint(source[filter.Field])
+		return ok && geo.DistanceKm(*filter.Near, point) <= filter.WithinKm
+	case filter.Field != "" && filter.isPeriod():
+		return inPeriod(source[filter.Field], filter.Since, filter.Until)
+	case filter.Field != "" && filter.isRange():
+		return inRange(source[filter.Field], filter.Min, filter.Max)
+	case filter.Field != "":
+		return hasValue(source[filter.Field], filter.Value)
+	case len(filter.All) > 0:
+		for _, f := range filter.All {
+			if !matchesFilter(source, f) {
+				return false
+			}
+		}
+		return true
+	case len(filter.Any) > 0:
+		for _, f := range filter.Any {
+			if matchesFilter(source, f) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// hasValue reports whether a stored value, or one of a list of them, equals want
+func hasValue(value, want interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case []interface{}:
+		for _, item := range v {
+			if hasValue(item, want) {
+				return true
+			}
+		}
+		return false
+	default:
+		return fmt.Sprint(v) == fmt.Sprint(want)
+	}
+}
+
+// inRange reports whether a stored number, or one of a list of them, lies
+// between min and max
+func inRange(value interface{}, min, max *float64) bool {
+	switch v := value.(type) {
+	case float64:
+		return (min == nil || v >= *min) && (max == nil || v <= *max)
+	case []interface{}:
+		for _, item := range v {
+			if inRange(item, min, max) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inPeriod reports whether a stored RFC 3339 date is within a period
+func inPeriod(value interface{}, since, until *time.Time) bool {
+	text, ok := value.(string)
+	if !ok {
+		return false
+	}
+	at, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return false
+	}
+	return (since == nil || !at.Before(*since)) && (until == nil || !at.After(*until))
+}
+
+// sortHits orders hits by the sort fields, then by index and ID so pages are
+// stable. Documents missing a sort field come last, as in OpenSearch.
+func sortHits(hits []SearchHit, fields []SortField) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		for _, field := range fields {
+			var order int
+			if field.Field == ScoreField {
+				order = compareValues(hits[i].Score, hits[j].Score)
+			} else if field.Near != nil {
+				a, aOK := sourcePoint(hits[i].Source[field.Field])
+				b, bOK := sourcePoint(hits[j].Source[field.Field])
+				switch {
+				case !aOK && !bOK:
+					continue
+				case !aOK:
+					return false
+				case !bOK:
+					return true
+				}
+				order = compareValues(geo.DistanceKm(*field.Near, a), geo.DistanceKm(*field.Near, b))
+			} else {
+				a, aOK := hits[i].Source[field.Field]
+				b, bOK := hits[j].Source[field.Field]
+				switch {
+				case !aOK && !bOK:
+					continue
+				case !aOK:
+					return false
+				case !bOK:
+					return true
+				}
+				order = compareValues(a, b)
+			}
+			if order == 0 {
+				continue
+			}
+			if field.Desc {
+				return order > 0
+			}
+			return order < 0
+		}
+		if hits[i].Index != hits[j].Index {
+			return hits[i].Index < hits[j].Index
+		}
+		return hits[i].ID < hits[j].ID
+	})
+}
+
+// sourcePoint reads a stored geo-point, kept as {"lat": ..., "lon": ...}
+func sourcePoint(value interface{}) (geo.Point, bool) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return geo.Point{}, false
+	}
+	lat, latOK := object["lat"].(float64)
+	lon, lonOK := object["lon"].(float64)
+	return geo.Point{Lat: lat, Lon: lon}, latOK && lonOK
+}
+
+func compareValues(a, b interface{}) int {
+	af, aNumber := a.(float64)
+	bf, bNumber := b.(float64)
+	if aNumber && bNumber {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// facetSize is how many buckets a facet returns, as an OpenSearch terms aggregation does
+const facetSize = 10
+
+// countFacet counts the hits holding each value of a field, most common first
+func countFacet(hits []SearchHit, field string) []FacetBucket {
+	counts := make(map[string]*FacetBucket)
+	for _, hit := range hits {
+		values, ok := hit.Source[field].([]interface{})
+		if !ok {
+			values = []interface{}{hit.Source[field]}
+		}
+
+		seen := make(map[string]bool)
+		for _, value := range values {
+			if value == nil || value == "" {
+				continue
+			}
+			key := fmt.Sprint(value)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if bucket, ok := counts[key]; ok {
+				bucket.DocCount++
+			} else {
+				counts[key] = &FacetBucket{Key: value, DocCount: 1}
+			}
+		}
+	}
+
+	return topBuckets(counts)
+}
+
+// topBuckets orders buckets most common first and keeps the first facetSize
+func topBuckets(counts map[string]*FacetBucket) []FacetBucket {
+	buckets := make([]FacetBucket, 0, len(counts))
+	for _, bucket := range counts {
+		buckets = append(buckets, *bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].DocCount != buckets[j].DocCount {
+			return buckets[i].DocCount > buckets[j].DocCount
+		}
+		return fmt.Sprint(buckets[i].Key) < fmt.Sprint(buckets[j].Key)
+	})
+	if len(buckets) > facetSize {
+		buckets = buckets[:facetSize]
+	}
+	return buckets
+}
+
+// summarizeAttributes builds the attribute facets of the hits, as the
+// OpenSearch backend's nested aggregation does
+func summarizeAttributes(hits []SearchHit) []AttributeFacet {
+	type summary struct {
+		facet  AttributeFacet
+		values map[string]*FacetBucket
+	}
+	summaries := make(map[string]*summary)
+
+	for _, hit := range hits {
+		items, _ := hit.Source["attributes"].([]interface{})
+		counted := make(map[string]bool)
+		for _, item := range items {
+			attribute, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			code := getString(attribute, "code")
+			if code == "" {
+				continue
+			}
+
+			sum, ok := summaries[code]
+			if !ok {
+				sum = &summary{
+					facet: AttributeFacet{
+						Code:     code,
+						Name:     getString(attribute, "name"),
+						DataType: getString(attribute, "data_type"),
+						Unit:     getString(attribute, "unit"),
+					},
+					values: make(map[string]*FacetBucket),
+				}
+				summaries[code] = sum
+			}
+			if !counted[code] {
+				sum.facet.Count++
+				counted[code] = true
+			}
+
+			if value := getString(attribute, "value"); value != "" {
+				if bucket, ok := sum.values[value]; ok {
+					bucket.DocCount++
+				} else {
+					sum.values[value] = &FacetBucket{Key: value, DocCount: 1}
+				}
+			}
+			if number, ok := attribute["number"].(float64); ok && (sum.facet.Min == nil || number < *sum.facet.Min) {
+				sum.facet.Min = &number
+			}
+			if number, ok := attribute["number_max"].(float64); ok && (sum.facet.Max == nil || number > *sum.facet.Max) {
+				sum.facet.Max = &number
+			}
+		}
+	}
+
+	facets := make([]AttributeFacet, 0, len(summaries))
+	for _, sum := range summaries {
+		sum.facet.Values = topBuckets(sum.values)
+		facets = append(facets, sum.facet)
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Code < facets[j].Code
+	})
+	if len(facets) > attributeFacetSize {
+		facets = facets[:attributeFacetSize]
+	}
+	return facets
+}
+
+// tokenize splits text into lowercase words of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// fieldText is the searchable text of a stored value
+func fieldText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fieldText(item))
+		}
+		return strings.Join(parts, " ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// copySources gives hits their own copies of the stored documents, which
+// callers are free to change
+func copySources(hits []SearchHit) []SearchHit {
+	for i := range hits {
+		source := make(map[string]interface{}, len(hits[i].Source))
+		for key, value := range hits[i].Source {
+			source[key] = value
+		}
+		hits[i].Source = source
+	}
+	return hits
+}
+
+// normalizeDocument copies a document through JSON, so stored values have
+// the types OpenSearch returns: float64 numbers and []interface{} lists
+func normalizeDocument(document map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %w", err)
+	}
+
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %w", err)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/b2b-platform/shared/geo"
+)
+
+var (
+	berlin = geo.Point{Lat: 52.520, Lon: 13.405}
+	munich = geo.Point{Lat: 48.137, Lon: 11.575}
+)
+
+// newTestBackend indexes a few parts of two tenants, as the indexer would
+func newTestBackend(t *testing.T) *EmbeddedBackend {
+	t.Helper()
+
+	attributes := func(values ...map[string]interface{}) []map[string]interface{} {
+		items := make([]interface{}, len(values))
+		for i, value := range values {
+			items[i] = value
+		}
+		return partAttributes(map[string]interface{}{"attributes": items})
+	}
+
+	parts := map[string]map[string]interface{}{
+		"ball": {
+			"id": "ball", "type": "part", "name": "Ball bearing", "tenant_id": "tenant-a",
+			"visibility": "public", "status": "approved", "company_status": "approved",
+			"category": "bearings", "price": 10.0, "location": berlin,
+			"attributes": attributes(
+				map[string]interface{}{"code": "bore_diameter", "unit": "mm", "value": "22", "number": 22.0},
+				map[string]interface{}{"code": "material", "value": "steel"},
+			),
+		},
+		"roller": {
+			"id": "roller", "type": "part", "name": "Roller bearing", "tenant_id": "tenant-b",
+			"visibility": "public", "status": "approved", "company_status": "pending",
+			"category": "bearings", "price": 30.0, "location": munich,
+			"attributes": attributes(
+				map[string]interface{}{"code": "bore_diameter", "unit": "mm", "value": "25", "number": 25.0},
+				map[string]interface{}{"code": "material", "value": "bronze"},
+			),
+		},
+		"custom": {
+			"id": "custom", "type": "part", "name": "Custom bearing", "tenant_id": "tenant-a",
+			"visibility": "private", "status": "draft",
+			"category": "custom", "price": 20.0,
+			"attributes": attributes(
+				map[string]interface{}{"code": "bore_diameter", "unit": "in", "value": "1", "number": 1.0},
+			),
+		},
+		"housing": {
+			"id": "housing", "type": "part", "name": "Bearing housing", "tenant_id": "tenant-b",
+			"visibility": "private", "status": "approved",
+			"category": "housings",
+		},
+	}
+
+	backend := NewEmbeddedBackend()
+	for id, part := range parts {
+		if err := backend.IndexDocument("parts", id, part, 1); err != nil {
+			t.Fatalf("failed to index %s: %v", id, err)
+		}
+	}
+	return backend
+}
+
+func hitIDs(hits []SearchHit) []string {
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	return ids
+}
+
+func TestEmbeddedBackend_Visibility(t *testing.T) {
+	search := NewSearchService(newTestBackend(t))
+
+	tests := []struct {
+		name     string
+		isGuest  bool
+		tenantID string
+		roles    []string
+		want     []string
+	}{
+		{
+			name:    "guest sees public parts of approved companies",
+			isGuest: true,
+			want:    []string{"ball"},
+		},
+		{
+			name:     "tenant sees public parts and its own",
+			tenantID: "tenant-a",
+			roles:    []string{"user"},
+			want:     []string{"ball", "custom", "roller"},
+		},
+		{
+			name:     "other tenant does not see another's private parts",
+			tenantID: "tenant-b",
+			roles:    []string{"user"},
+			want:     []string{"ball", "housing", "roller"},
+		},
+		{
+			name:     "admin sees everything",
+			tenantID: "tenant-a",
+			roles:    []string{"admin"},
+			want:     []string{"ball", "custom", "housing", "roller"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, total, _, err := search.Search("bearing", "part", 1, 10, "", nil, nil, nil, Period{}, tt.isGuest, tt.tenantID, tt.roles)
+			if err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+
+			ids := make([]string, len(results))
+			for i, result := range results {
+				ids[i] = result.ID
+			}
+			sort.Strings(ids)
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+			if total != int64(len(tt.want)) {
+				t.Errorf("got total %d, want %d", total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestEmbeddedBackend_Filters(t *testing.T) {
+	backend := newTestBackend(t)
+
+	attribute := func(code, raw string) Filter {
+		filter, err := ParseAttributeFilter(code, raw)
+		if err != nil {
+			t.Fatalf("failed to parse %s=%s: %v", code, raw, err)
+		}
+		return filter.filter()
+	}
+	price := func(min, max float64) Filter {
+		return Between("price", &min, &max)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{
+			name:   "attribute range in the attribute's unit",
+			filter: attribute("bore_diameter", "20..24 mm"),
+			want:   []string{"ball"},
+		},
+		{
+			name:   "attribute range converted across units",
+			filter: attribute("bore_diameter", "25-26 mm"),
+			want:   []string{"custom", "roller"},
+		},
+		{
+			name:   "attribute value in another unit",
+			filter: attribute("bore_diameter", "25.4mm"),
+			want:   []string{"custom"},
+		},
+		{
+			name:   "attribute value in another dimension",
+			filter: attribute("bore_diameter", "22 g"),
+			want:   []string{},
+		},
+		{
+			name:   "attribute text value",
+			filter: attribute("material", "steel"),
+			want:   []string{"ball"},
+		},
+		{
+			name:   "nested conditions hold for one attribute",
+			filter: NestedAll("attributes", Term("attributes.code", "bore_diameter"), Term("attributes.value", "steel")),
+			want:   []string{},
+		},
+		{
+			name:   "range",
+			filter: price(15, 30),
+			want:   []string{"custom", "roller"},
+		},
+		{
+			name:   "range skips documents without the field",
+			filter: price(0, 100),
+			want:   []string{"ball", "custom", "roller"},
+		},
+		{
+			name:   "within distance",
+			filter: WithinDistance("location", berlin, 50),
+			want:   []string{"ball"},
+		},
+		{
+			name:   "within a wider distance",
+			filter: WithinDistance("location", berlin, 600),
+			want:   []string{"ball", "roller"},
+		},
+		{
+			name:   "all of",
+			filter: AllOf(Term("category", "bearings"), price(20, 40)),
+			want:   []string{"roller"},
+		},
+		{
+			name:   "any of",
+			filter: AnyOf(Term("category", "housings"), Term("visibility", "private")),
+			want:   []string{"custom", "housing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := backend.Search(SearchQuery{
+				Indices: []string{"parts"},
+				Filter:  tt.filter,
+				Sort:    []SortField{{Field: "id"}},
+				Size:    10,
+			})
+			if err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+			if ids := hitIDs(hits.Hits); !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedBackend_Facets(t *testing.T) {
+	hits, err := newTestBackend(t).Search(SearchQuery{
+		Indices:         []string{"parts"},
+		Size:            10,
+		Facets:          map[string]string{"categories": "category"},
+		AttributeFacets: true,
+	})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	wantCategories := []FacetBucket{
+		{Key: "bearings", DocCount: 2},
+		{Key: "custom", DocCount: 1},
+		{Key: "housings", DocCount: 1},
+	}
+	if !reflect.DeepEqual(hits.Facets["categories"], wantCategories) {
+		t.Errorf("got categories %v, want %v", hits.Facets["categories"], wantCategories)
+	}
+
+	if len(hits.Attributes) != 2 {
+		t.Fatalf("got %d attribute facets, want 2", len(hits.Attributes))
+	}
+	bore := hits.Attributes[0]
+	if bore.Code != "bore_diameter" || bore.Count != 3 {
+		t.Errorf("got %s on %d parts, want bore_diameter on 3", bore.Code, bore.Count)
+	}
+	if bore.Min == nil || *bore.Min != 1 || bore.Max == nil || *bore.Max != 25 {
+		t.Errorf("got bore_diameter span %v..%v, want 1..25", bore.Min, bore.Max)
+	}
+	if material := hits.Attributes[1]; material.Code != "material" || material.Count != 2 {
+		t.Errorf("got %s on %d parts, want material on 2", material.Code, material.Count)
+	}
+}
+
+func TestEmbeddedBackend_Sort(t *testing.T) {
+	backend := newTestBackend(t)
+
+	tests := []struct {
+		name string
+		sort []SortField
+		want []string
+	}{
+		{
+			name: "ascending, missing last",
+			sort: []SortField{{Field: "price"}},
+			want: []string{"ball", "custom", "roller", "housing"},
+		},
+		{
+			name: "descending, missing last",
+			sort: []SortField{{Field: "price", Desc: true}},
+			want: []string{"roller", "custom", "ball", "housing"},
+		},
+		{
+			name: "by distance, missing last by id",
+			sort: []SortField{{Field: "location", Near: &munich}},
+			want: []string{"roller", "ball", "custom", "housing"},
+		},
+		{
+			name: "by a second field on ties",
+			sort: []SortField{{Field: "category"}, {Field: "price", Desc: true}},
+			want: []string{"roller", "ball", "custom", "housing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := backend.Search(SearchQuery{
+				Indices: []string{"parts"},
+				Sort:    tt.sort,
+				Size:    10,
+			})
+			if err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+			if ids := hitIDs(hits.Hits); !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+// Saved searches are kept with their queries and matched against a document
+// by running the query over that document alone
+
+type embeddedSavedSearch struct {
+	search SavedSearch
+	query  SearchQuery
+}
+
+func (b *EmbeddedBackend) SaveSearch(search *SavedSearch, query SearchQuery) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.savedSearches[search.ID] = embeddedSavedSearch{search: *search, query: query}
+	return nil
+}
+
+func (b *EmbeddedBackend) GetSearch(id string) (*SavedSearch, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	saved, ok := b.savedSearches[id]
+	if !ok {
+		return nil, ErrSavedSearchNotFound
+	}
+	search := saved.search
+	return &search, nil
+}
+
+func (b *EmbeddedBackend) ListSearches(userID string) ([]SavedSearch, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	searches := []SavedSearch{}
+	for _, saved := range b.savedSearches {
+		if saved.search.UserID == userID {
+			searches = append(searches, saved.search)
+		}
+	}
+	return searches, nil
+}
+
+func (b *EmbeddedBackend) DeleteSearch(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.savedSearches[id]; !ok {
+		return ErrSavedSearchNotFound
+	}
+	delete(b.savedSearches, id)
+	return nil
+}
+
+func (b *EmbeddedBackend) MatchSearches(index string, document map[string]interface{}) ([]SavedSearch, error) {
+	source, err := normalizeDocument(document)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	matches := []SavedSearch{}
+	for _, saved := range b.savedSearches {
+		if saved.search.Alerts && searchesIndex(saved.query, index) && matchesQuery(source, saved.query) {
+			matches = append(matches, saved.search)
+		}
+	}
+	return matches, nil
+}
+
+func searchesIndex(query SearchQuery, index string) bool {
+	for _, name := range query.Indices {
+		if name == index {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesQuery reports whether a document matches a query's text and filter
+func matchesQuery(source map[string]interface{}, query SearchQuery) bool {
+	if !matchesFilter(source, query.Filter) {
+		return false
+	}
+
+	matcher := newTextMatcher(query)
+	_, ok := matcher.score(source, matcher.frequencies(source), map[string]int{}, 1)
+	return ok
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// SearchAnalytics counts the period's searches and clicks among the
+// documents recorded in the analytics indices
+func (b *EmbeddedBackend) SearchAnalytics(query AnalyticsQuery) (*AnalyticsReport, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	report := &AnalyticsReport{}
+	queries := make(map[string]*queryTotals)
+	zeroResults := make(map[string]*queryTotals)
+	impressions := make([]int64, maxReportPositions+1)
+	latency := 0.0
+
+	for _, source := range b.analyticsRecords(searchLogsIndex, query) {
+		var entry SearchLogEntry
+		if err := convertRecord(source, &entry); err != nil {
+			return nil, err
+		}
+
+		report.Searches++
+		latency += entry.LatencyMS
+		addQueryTotals(queries, entry)
+		if entry.ResultCount == 0 {
+			report.ZeroResultSearches++
+			addQueryTotals(zeroResults, entry)
+		}
+		if entry.FirstPosition >= 1 {
+			for position := entry.FirstPosition; position <= entry.LastPosition && position <= maxReportPositions; position++ {
+				impressions[position]++
+			}
+		}
+	}
+	if report.Searches > 0 {
+		report.AverageLatencyMS = latency / float64(report.Searches)
+	}
+	report.TopQueries = topQueries(queries, query.Size)
+	report.ZeroResultQueries = topQueries(zeroResults, query.Size)
+
+	clickedSearches := make(map[string]bool)
+	clicks := make([]int64, maxReportPositions+1)
+	for _, source := range b.analyticsRecords(searchClicksIndex, query) {
+		var click SearchClick
+		if err := convertRecord(source, &click); err != nil {
+			return nil, err
+		}
+
+		report.Clicks++
+		clickedSearches[click.SearchID] = true
+		if click.Position >= 1 && click.Position <= maxReportPositions {
+			clicks[click.Position]++
+		}
+	}
+	report.ClickedSearches = int64(len(clickedSearches))
+
+	for position := 1; position <= maxReportPositions; position++ {
+		report.Positions = append(report.Positions, PositionStats{
+			Position:    position,
+			Impressions: impressions[position],
+			Clicks:      clicks[position],
+		})
+	}
+	return report, nil
+}
+
+// PopularQueries counts the searches with results for each query with the
+// prefix, keeping those enough tenants searched
+func (b *EmbeddedBackend) PopularQueries(prefix string, since time.Time, minTenants, size int) ([]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	searches := make(map[string]int)
+	tenants := make(map[string]map[string]bool)
+	for _, source := range b.analyticsRecords(searchLogsIndex, AnalyticsQuery{From: since}) {
+		var entry SearchLogEntry
+		if err := convertRecord(source, &entry); err != nil {
+			return nil, err
+		}
+		if entry.ResultCount == 0 || !strings.HasPrefix(entry.NormalizedQuery, prefix) {
+			continue
+		}
+
+		searches[entry.NormalizedQuery]++
+		if entry.TenantID != "" {
+			if tenants[entry.NormalizedQuery] == nil {
+				tenants[entry.NormalizedQuery] = make(map[string]bool)
+			}
+			tenants[entry.NormalizedQuery][entry.TenantID] = true
+		}
+	}
+
+	queries := make([]string, 0, len(searches))
+	for query := range searches {
+		if len(tenants[query]) >= minTenants {
+			queries = append(queries, query)
+		}
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		if searches[queries[i]] != searches[queries[j]] {
+			return searches[queries[i]] > searches[queries[j]]
+		}
+		return queries[i] < queries[j]
+	})
+	if len(queries) > size {
+		queries = queries[:size]
+	}
+	return queries, nil
+}
+
+// analyticsRecords returns the sources of an analytics index recorded in the
+// period, of one tenant when asked. A zero To leaves the period open. The
+// caller holds the read lock.
+func (b *EmbeddedBackend) analyticsRecords(index string, query AnalyticsQuery) []map[string]interface{} {
+	idx, ok := b.indices[index]
+	if !ok {
+		return nil
+	}
+
+	records := make([]map[string]interface{}, 0, len(idx.documents))
+	for _, document := range idx.documents {
+		timestamp, _ := document.source["timestamp"].(string)
+		at, err := time.Parse(time.RFC3339Nano, timestamp)
+		if err != nil || at.Before(query.From) || (!query.To.IsZero() && at.After(query.To)) {
+			continue
+		}
+		if query.TenantID != "" && document.source["tenant_id"] != query.TenantID {
+			continue
+		}
+		records = append(records, document.source)
+	}
+	return records
+}
+
+type queryTotals struct {
+	searches int64
+	results  int64
+	last     time.Time
+}
+
+func addQueryTotals(totals map[string]*queryTotals, entry SearchLogEntry) {
+	total, ok := totals[entry.NormalizedQuery]
+	if !ok {
+		total = &queryTotals{}
+		totals[entry.NormalizedQuery] = total
+	}
+	total.searches++
+	total.results += entry.ResultCount
+	if entry.Timestamp.After(total.last) {
+		total.last = entry.Timestamp
+	}
+}
+
+// topQueries lists the most searched queries, as a terms aggregation orders them
+func topQueries(totals map[string]*queryTotals, size int) []QueryStats {
+	stats := make([]QueryStats, 0, len(totals))
+	for query, total := range totals {
+		stats = append(stats, QueryStats{
+			Query:          query,
+			Searches:       total.searches,
+			AverageResults: float64(total.results) / float64(total.searches),
+			LastSearchedAt: total.last,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Searches != stats[j].Searches {
+			return stats[i].Searches > stats[j].Searches
+		}
+		return stats[i].Query < stats[j].Query
+	})
+	if len(stats) > size {
+		stats = stats[:size]
+	}
+	return stats
+}
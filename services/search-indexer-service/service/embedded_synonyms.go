@@ -0,0 +1,31 @@
+package service
+
+func (b *EmbeddedBackend) SaveSynonymSet(set *SynonymSet) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.synonymSets[set.ID] = *set
+	return nil
+}
+
+func (b *EmbeddedBackend) DeleteSynonymSet(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.synonymSets[id]; !ok {
+		return ErrSynonymSetNotFound
+	}
+	delete(b.synonymSets, id)
+	return nil
+}
+
+func (b *EmbeddedBackend) ListSynonymSets() ([]SynonymSet, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	sets := make([]SynonymSet, 0, len(b.synonymSets))
+	for _, set := range b.synonymSets {
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Searches and live writes go through an alias named after the index, e.g.
+// "parts", pointing at a versioned index such as "parts_v3". A rebuild loads
+// the next version and then moves the alias onto it in one step.
+
+// rebuildCheckInterval is how long the indexer trusts its last look at
+// whether an index is being rebuilt. A rebuild waits this long before it
+// loads anything, so every live write from then on reaches the new index.
+const rebuildCheckInterval = 10 * time.Second
+
+type rebuildCheck struct {
+	active    bool
+	checkedAt time.Time
+}
+
+// rebuildAlias names the write alias of the index being built for an alias
+// while it is rebuilt
+func rebuildAlias(alias string) string {
+	return alias + "_rebuild"
+}
+
+func versionedIndexName(alias string, version int) string {
+	return fmt.Sprintf("%s_v%d", alias, version)
+}
+
+// rebuilding reports whether an index is being rebuilt, so live writes must
+// go to the index being built as well
+func (s *OpenSearchBackend) rebuilding(indexName string) bool {
+	s.mu.Lock()
+	check, ok := s.rebuildChecks[indexName]
+	s.mu.Unlock()
+	if ok && time.Since(check.checkedAt) < rebuildCheckInterval {
+		return check.active
+	}
+
+	active, err := s.exists(rebuildAlias(indexName))
+	if err != nil {
+		// Writing to a rebuild that is not running is harmless; missing one is not
+		log.Printf("Failed to check whether %s is being rebuilt: %v", indexName, err)
+		return true
+	}
+
+	s.mu.Lock()
+	s.rebuildChecks[indexName] = rebuildCheck{active: active, checkedAt: time.Now()}
+	s.mu.Unlock()
+	return active
+}
+
+// exists reports whether an index or alias exists
+func (s *OpenSearchBackend) exists(name string) (bool, error) {
+	req, err := http.NewRequest("HEAD", fmt.Sprintf("%s/%s", s.opensearchURL, name), nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("opensearch error: %s", resp.Status)
+	}
+}
+
+// nextIndexVersion names the next versioned index for an alias, one past
+// the highest version that exists
+func (s *OpenSearchBackend) nextIndexVersion(alias string) (string, error) {
+	var indices []struct {
+		Index string `json:"index"`
+	}
+	url := fmt.Sprintf("%s/_cat/indices/%s_v*?format=json&h=index", s.opensearchURL, alias)
+	if err := s.getJSON(url, &indices); err != nil {
+		return "", err
+	}
+
+	highest := 0
+	for _, index := range indices {
+		version, err := strconv.Atoi(strings.TrimPrefix(index.Index, alias+"_v"))
+		if err == nil && version > highest {
+			highest = version
+		}
+	}
+	return versionedIndexName(alias, highest+1), nil
+}
+
+// createIndex creates an index with the mapping of mappingName, pointed to
+// by the given aliases
+func (s *OpenSearchBackend) createIndex(name, mappingName string, aliases ...string) error {
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(GetIndexMapping(mappingName)), &body); err != nil {
+		return fmt.Errorf("invalid mapping for %s: %w", mappingName, err)
+	}
+
+	settings, ok := body["settings"].(map[string]interface{})
+	if !ok {
+		settings = map[string]interface{}{}
+		body["settings"] = settings
+	}
+	// Versioned deletes are remembered this long, so an event older than a
+	// delete cannot bring the document back, during a rebuild or otherwise
+	settings["index.gc_deletes"] = "1h"
+
+	aliasBodies := make(map[string]interface{}, len(aliases))
+	for _, alias := range aliases {
+		aliasBodies[alias] = map[string]interface{}{}
+	}
+	body["aliases"] = aliasBodies
+
+	return s.sendJSON("PUT", fmt.Sprintf("%s/%s", s.opensearchURL, name), body, nil)
+}
+
+// aliasIndices returns the indices an alias points to. legacy is set when
+// the name is a plain index from before indices were versioned.
+func (s *OpenSearchBackend) aliasIndices(alias string) (indices []string, legacy bool, err error) {
+	var byIndex map[string]interface{}
+	url := fmt.Sprintf("%s/_alias/%s", s.opensearchURL, alias)
+	found, err := s.getJSONIfExists(url, &byIndex)
+	if err != nil {
+		return nil, false, err
+	}
+	if found {
+		for index := range byIndex {
+			indices = append(indices, index)
+		}
+		return indices, false, nil
+	}
+
+	legacy, err = s.exists(alias)
+	return nil, legacy, err
+}
+
+// swapAlias points an alias at a newly built index and drops the index's
+// rebuild alias, in one step. The indices the alias pointed to before are
+// returned; a legacy plain index of the alias's name is deleted by the swap.
+func (s *OpenSearchBackend) swapAlias(alias, index string) ([]string, error) {
+	previous, legacy, err := s.aliasIndices(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []map[string]interface{}{
+		{"add": map[string]interface{}{"index": index, "alias": alias}},
+		{"remove": map[string]interface{}{"index": index, "alias": rebuildAlias(alias)}},
+	}
+	for _, old := range previous {
+		if old != index {
+			actions = append(actions, map[string]interface{}{"remove": map[string]interface{}{"index": old, "alias": alias}})
+		}
+	}
+	if legacy {
+		actions = append(actions, map[string]interface{}{"remove_index": map[string]interface{}{"index": alias}})
+	}
+
+	url := fmt.Sprintf("%s/_aliases", s.opensearchURL)
+	if err := s.sendJSON("POST", url, map[string]interface{}{"actions": actions}, nil); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+func (s *OpenSearchBackend) deleteIndex(name string) error {
+	return s.sendJSON("DELETE", fmt.Sprintf("%s/%s", s.opensearchURL, name), nil, nil)
+}
+
+// refreshIndex makes everything written to an index visible to searches
+func (s *OpenSearchBackend) refreshIndex(name string) error {
+	return s.sendJSON("POST", fmt.Sprintf("%s/%s/_refresh", s.opensearchURL, name), nil, nil)
+}
+
+func (s *OpenSearchBackend) getJSON(url string, out interface{}) error {
+	return s.sendJSON("GET", url, nil, out)
+}
+
+// getJSONIfExists is getJSON reporting a missing resource as not found
+// rather than as an error
+func (s *OpenSearchBackend) getJSONIfExists(url string, out interface{}) (bool, error) {
+	err := s.sendJSON("GET", url, nil, out)
+	if errors.Is(err, errNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+var errNotFound = errors.New("opensearch: not found")
+
+// sendJSON sends a JSON request to OpenSearch and decodes the response into
+// out, when given
+func (s *OpenSearchBackend) sendJSON(method, url string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call opensearch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("opensearch error: %s - %s", resp.Status, string(respBody))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,13 @@
 package service
 
-// GetIndexMapping returns the OpenSearch mapping for a given index type
+import (
+	"encoding/json"
+)
+
+// GetIndexMapping returns the OpenSearch mapping for a given index type.
+// Part numbers, SKUs and models have a "normalized" field analyzed with
+// the identifier analyzer, which drops case, spaces and punctuation so that
+// 6205-2RS, 6205 2RS and 62052RS are the same value.
 func GetIndexMapping(indexType string) string {
 	switch indexType {
 	case "parts":
@@ -14,6 +21,19 @@ func GetIndexMapping(indexType string) string {
 							"type": "custom",
 							"tokenizer": "standard",
 							"filter": ["lowercase", "autocomplete_filter"]
+						},
+						"identifier": {
+							"type": "custom",
+							"char_filter": ["identifier_punctuation"],
+							"tokenizer": "keyword",
+							"filter": ["lowercase"]
+						}
+					},
+					"char_filter": {
+						"identifier_punctuation": {
+							"type": "pattern_replace",
+							"pattern": "[^\\p{L}\\p{N}]",
+							"replacement": ""
 						}
 					},
 					"filter": {
@@ -33,6 +53,7 @@ func GetIndexMapping(indexType string) string {
 						"type": "text",
 						"fields": {
 							"keyword": {"type": "keyword"},
+							"normalized": {"type": "text", "analyzer": "identifier"},
 							"autocomplete": {"type": "text", "analyzer": "autocomplete"}
 						}
 					},
@@ -40,6 +61,7 @@ func GetIndexMapping(indexType string) string {
 						"type": "text",
 						"fields": {
 							"keyword": {"type": "keyword"},
+							"normalized": {"type": "text", "analyzer": "identifier"},
 							"autocomplete": {"type": "text", "analyzer": "autocomplete"}
 						}
 					},
@@ -53,6 +75,8 @@ func GetIndexMapping(indexType string) string {
 					"manufacturer": {"type": "keyword"},
 					"manufacturer_id": {"type": "keyword"},
 					"category": {"type": "keyword"},
+					"category_id": {"type": "keyword"},
+					"category_path": {"type": "keyword"},
 					"visibility": {"type": "keyword"},
 					"status": {"type": "keyword"},
 					"company_status": {"type": "keyword"},
@@ -60,7 +84,35 @@ func GetIndexMapping(indexType string) string {
 					"currency": {"type": "keyword"},
 					"stock": {"type": "integer"},
 					"rating": {"type": "float"},
-					"timestamp": {"type": "date"}
+					"superseded": {"type": "boolean"},
+					"current_part_id": {"type": "keyword"},
+					"current_part_number": {"type": "keyword"},
+					"alternative_part_ids": {"type": "keyword"},
+					"interchange_part_numbers": {
+						"type": "text",
+						"fields": {
+							"keyword": {"type": "keyword"},
+							"normalized": {"type": "text", "analyzer": "identifier"}
+						}
+					},
+					"attributes": {
+						"type": "nested",
+						"properties": {
+							"code": {"type": "keyword"},
+							"name": {"type": "keyword"},
+							"data_type": {"type": "keyword"},
+							"unit": {"type": "keyword"},
+							"dimension": {"type": "keyword"},
+							"value": {"type": "keyword"},
+							"number": {"type": "double"},
+							"number_max": {"type": "double"},
+							"base_number": {"type": "double"},
+							"base_number_max": {"type": "double"}
+						}
+					},
+					"timestamp": {"type": "date"},
+					"indexed_at": {"type": "date"},
+					"reindex_job": {"type": "keyword"}
 				}
 			}
 		}`
@@ -75,6 +127,19 @@ func GetIndexMapping(indexType string) string {
 							"type": "custom",
 							"tokenizer": "standard",
 							"filter": ["lowercase", "autocomplete_filter"]
+						},
+						"identifier": {
+							"type": "custom",
+							"char_filter": ["identifier_punctuation"],
+							"tokenizer": "keyword",
+							"filter": ["lowercase"]
+						}
+					},
+					"char_filter": {
+						"identifier_punctuation": {
+							"type": "pattern_replace",
+							"pattern": "[^\\p{L}\\p{N}]",
+							"replacement": ""
 						}
 					},
 					"filter": {
@@ -94,6 +159,7 @@ func GetIndexMapping(indexType string) string {
 						"type": "text",
 						"fields": {
 							"keyword": {"type": "keyword"},
+							"normalized": {"type": "text", "analyzer": "identifier"},
 							"autocomplete": {"type": "text", "analyzer": "autocomplete"}
 						}
 					},
@@ -115,7 +181,14 @@ func GetIndexMapping(indexType string) string {
 					"currency": {"type": "keyword"},
 					"rating": {"type": "float"},
 					"eta": {"type": "integer"},
-					"timestamp": {"type": "date"}
+					"tenant_id": {"type": "keyword"},
+					"equipment_number": {"type": "keyword"},
+					"serial_number": {"type": "keyword"},
+					"location_name": {"type": "text"},
+					"year": {"type": "integer"},
+					"timestamp": {"type": "date"},
+					"indexed_at": {"type": "date"},
+					"reindex_job": {"type": "keyword"}
 				}
 			}
 		}`
@@ -151,12 +224,20 @@ func GetIndexMapping(indexType string) string {
 							"autocomplete": {"type": "text", "analyzer": "autocomplete"}
 						}
 					},
+					"legal_name": {"type": "text"},
 					"subdomain": {"type": "keyword"},
+					"industry": {"type": "keyword"},
+					"company_type": {"type": "keyword"},
+					"city": {"type": "keyword"},
+					"country": {"type": "keyword"},
+					"location": {"type": "geo_point"},
 					"visibility": {"type": "keyword"},
 					"status": {"type": "keyword"},
 					"company_status": {"type": "keyword"},
 					"rating": {"type": "float"},
-					"timestamp": {"type": "date"}
+					"timestamp": {"type": "date"},
+					"indexed_at": {"type": "date"},
+					"reindex_job": {"type": "keyword"}
 				}
 			}
 		}`
@@ -171,6 +252,19 @@ func GetIndexMapping(indexType string) string {
 							"type": "custom",
 							"tokenizer": "standard",
 							"filter": ["lowercase", "autocomplete_filter"]
+						},
+						"identifier": {
+							"type": "custom",
+							"char_filter": ["identifier_punctuation"],
+							"tokenizer": "keyword",
+							"filter": ["lowercase"]
+						}
+					},
+					"char_filter": {
+						"identifier_punctuation": {
+							"type": "pattern_replace",
+							"pattern": "[^\\p{L}\\p{N}]",
+							"replacement": ""
 						}
 					},
 					"filter": {
@@ -202,6 +296,7 @@ func GetIndexMapping(indexType string) string {
 						"type": "text",
 						"fields": {
 							"keyword": {"type": "keyword"},
+							"normalized": {"type": "text", "analyzer": "identifier"},
 							"autocomplete": {"type": "text", "analyzer": "autocomplete"}
 						}
 					},
@@ -209,6 +304,10 @@ func GetIndexMapping(indexType string) string {
 					"brand": {"type": "keyword"},
 					"category": {"type": "keyword"},
 					"supplier_id": {"type": "keyword"},
+					"store_id": {"type": "keyword"},
+					"part_id": {"type": "keyword"},
+					"listing_type": {"type": "keyword"},
+					"listing_status": {"type": "keyword"},
 					"visibility": {"type": "keyword"},
 					"status": {"type": "keyword"},
 					"company_status": {"type": "keyword"},
@@ -218,7 +317,126 @@ func GetIndexMapping(indexType string) string {
 					"stock": {"type": "integer"},
 					"rating": {"type": "float"},
 					"eta": {"type": "integer"},
-					"timestamp": {"type": "date"}
+					"location": {"type": "geo_point"},
+					"timestamp": {"type": "date"},
+					"indexed_at": {"type": "date"},
+					"reindex_job": {"type": "keyword"}
+				}
+			}
+		}`
+	case "rfqs", "quotes", "orders", "shipments":
+		// Procurement documents share one mapping. They are private: each
+		// is searched only by the tenant_id and supplier_id it names.
+		return `{
+			"settings": {
+				"number_of_shards": 1,
+				"number_of_replicas": 0,
+				"analysis": {
+					"analyzer": {
+						"identifier": {
+							"type": "custom",
+							"char_filter": ["identifier_punctuation"],
+							"tokenizer": "keyword",
+							"filter": ["lowercase"]
+						}
+					},
+					"char_filter": {
+						"identifier_punctuation": {
+							"type": "pattern_replace",
+							"pattern": "[^\\p{L}\\p{N}]",
+							"replacement": ""
+						}
+					}
+				}
+			},
+			"mappings": {
+				"properties": {
+					"id": {"type": "keyword"},
+					"type": {"type": "keyword"},
+					"number": {
+						"type": "text",
+						"fields": {
+							"keyword": {"type": "keyword"},
+							"normalized": {"type": "text", "analyzer": "identifier"}
+						}
+					},
+					"title": {"type": "text"},
+					"description": {"type": "text"},
+					"items": {"type": "text"},
+					"status": {"type": "keyword"},
+					"tenant_id": {"type": "keyword"},
+					"supplier_id": {"type": "keyword"},
+					"amount": {"type": "double"},
+					"currency": {"type": "keyword"},
+					"rfq_number": {"type": "keyword"},
+					"quote_number": {"type": "keyword"},
+					"po_number": {"type": "keyword"},
+					"tracking_number": {"type": "keyword"},
+					"pr_id": {"type": "keyword"},
+					"rfq_id": {"type": "keyword"},
+					"quote_id": {"type": "keyword"},
+					"po_id": {"type": "keyword"},
+					"payment_status": {"type": "keyword"},
+					"carrier": {"type": "keyword"},
+					"origin": {"type": "text"},
+					"destination": {"type": "text"},
+					"due_date": {"type": "date"},
+					"valid_until": {"type": "date"},
+					"eta": {"type": "date"},
+					"event_type": {"type": "keyword"},
+					"timestamp": {"type": "date"},
+					"indexed_at": {"type": "date"},
+					"reindex_job": {"type": "keyword"}
+				}
+			}
+		}`
+	case savedSearchesIndex:
+		return savedSearchesMapping()
+	case searchLogsIndex:
+		// Filters are kept with each search but not indexed; their keys vary
+		// with every attribute searched on
+		return `{
+			"settings": {
+				"number_of_shards": 1,
+				"number_of_replicas": 0
+			},
+			"mappings": {
+				"properties": {
+					"id": {"type": "keyword"},
+					"timestamp": {"type": "date"},
+					"query": {"type": "text"},
+					"normalized_query": {"type": "keyword"},
+					"type": {"type": "keyword"},
+					"filters": {"type": "object", "enabled": false},
+					"attributes": {"type": "object", "enabled": false},
+					"sort": {"type": "keyword"},
+					"result_count": {"type": "long"},
+					"latency_ms": {"type": "float"},
+					"page": {"type": "integer"},
+					"page_size": {"type": "integer"},
+					"first_position": {"type": "integer"},
+					"last_position": {"type": "integer"},
+					"tenant_id": {"type": "keyword"},
+					"user_id": {"type": "keyword"}
+				}
+			}
+		}`
+	case searchClicksIndex:
+		return `{
+			"settings": {
+				"number_of_shards": 1,
+				"number_of_replicas": 0
+			},
+			"mappings": {
+				"properties": {
+					"id": {"type": "keyword"},
+					"search_id": {"type": "keyword"},
+					"timestamp": {"type": "date"},
+					"result_id": {"type": "keyword"},
+					"result_type": {"type": "keyword"},
+					"position": {"type": "integer"},
+					"tenant_id": {"type": "keyword"},
+					"user_id": {"type": "keyword"}
 				}
 			}
 		}`
@@ -238,3 +456,48 @@ func GetIndexMapping(indexType string) string {
 		}`
 	}
 }
+
+// savedSearchesMapping maps saved searches for percolation. A percolator
+// query is parsed with the mapping of the index it is stored in, so the
+// fields of the documents searches alert on are mapped alongside the
+// search's own fields, which are kept apart under "search".
+func savedSearchesMapping() string {
+	var mapping struct {
+		Settings map[string]interface{} `json:"settings"`
+		Mappings struct {
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"mappings"`
+	}
+	json.Unmarshal([]byte(GetIndexMapping("parts")), &mapping)
+
+	var listings struct {
+		Mappings struct {
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"mappings"`
+	}
+	json.Unmarshal([]byte(GetIndexMapping("listings")), &listings)
+	for field, fieldMapping := range listings.Mappings.Properties {
+		if _, ok := mapping.Mappings.Properties[field]; !ok {
+			mapping.Mappings.Properties[field] = fieldMapping
+		}
+	}
+
+	// Searches may filter on fields no document has yet
+	mapping.Settings["index.percolator.map_unmapped_fields_as_text"] = true
+
+	mapping.Mappings.Properties["search"] = map[string]interface{}{
+		"properties": map[string]interface{}{
+			"id":         map[string]interface{}{"type": "keyword"},
+			"user_id":    map[string]interface{}{"type": "keyword"},
+			"tenant_id":  map[string]interface{}{"type": "keyword"},
+			"alerts":     map[string]interface{}{"type": "boolean"},
+			"created_at": map[string]interface{}{"type": "date"},
+		},
+		"dynamic": false,
+	}
+	mapping.Mappings.Properties["search_indices"] = map[string]interface{}{"type": "keyword"}
+	mapping.Mappings.Properties["search_query"] = map[string]interface{}{"type": "percolator"}
+
+	data, _ := json.Marshal(mapping)
+	return string(data)
+}
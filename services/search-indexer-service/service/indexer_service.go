@@ -1,39 +1,51 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"log"
+	"strconv"
 
 	"github.com/b2b-platform/shared/events"
+	"github.com/b2b-platform/shared/geo"
 )
 
 type IndexerService struct {
-	opensearchURL string
-	httpClient    *http.Client
+	backend SearchBackend
+	alerter *SearchAlerter // Alerts saved searches of new matches when set
 }
 
-func NewIndexerService() *IndexerService {
-	opensearchURL := os.Getenv("OPENSEARCH_URL")
-	if opensearchURL == "" {
-		opensearchURL = "http://localhost:9200"
-	}
-
+func NewIndexerService(backend SearchBackend) *IndexerService {
 	return &IndexerService{
-		opensearchURL: opensearchURL,
-		httpClient:    &http.Client{},
+		backend: backend,
 	}
 }
 
+// UseSearchAlerter checks the documents of live events against saved searches
+func (s *IndexerService) UseSearchAlerter(alerter *SearchAlerter) {
+	s.alerter = alerter
+}
+
 func (s *IndexerService) HandleEvent(event *events.EventEnvelope) error {
 	switch event.Type {
-	case events.EventCatalogPartApproved:
+	case events.EventCatalogPartApproved, events.EventCatalogPartCreated, events.EventCatalogPartUpdated:
 		return s.indexPart(event)
-	case events.EventCompanyApproved:
+	case events.EventCatalogPartDeleted, events.EventCatalogPartMerged:
+		// A merged part lives on as the part it was merged into
+		return s.deleteFromEvent(event, "parts", "part_id")
+	case events.EventCatalogPartInterchangeUpdated:
+		return s.updatePartInterchange(event)
+	case events.EventCompanyApproved, events.EventCompanyCreated, events.EventCompanyUpdated:
 		return s.indexCompany(event)
+	case events.EventCompanyDeleted:
+		return s.deleteFromEvent(event, "companies", "company_id")
+	case events.EventEquipmentCreated, events.EventEquipmentUpdated:
+		return s.indexEquipment(event)
+	case events.EventEquipmentDeleted:
+		return s.deleteFromEvent(event, "equipment", "equipment_id")
+	case events.EventListingCreated, events.EventListingUpdated:
+		return s.indexListing(event)
+	case events.EventListingDeleted:
+		return s.deleteFromEvent(event, "listings", "listing_id")
 	case events.EventOrderPlaced:
 		return s.indexOrder(event)
 	case events.EventRFQCreated:
@@ -74,6 +86,29 @@ func getFloat(payload map[string]interface{}, key string) float64 {
 	return 0
 }
 
+// getPoint reads the latitude and longitude of a payload, if it has both
+func getPoint(payload map[string]interface{}) (geo.Point, bool) {
+	lat, latOK := payload["latitude"].(float64)
+	lon, lonOK := payload["longitude"].(float64)
+	if !latOK || !lonOK {
+		return geo.Point{}, false
+	}
+	p := geo.Point{Lat: lat, Lon: lon}
+	return p, p.Valid()
+}
+
+// getAmount reads a money amount, which events give as a decimal string
+func getAmount(payload map[string]interface{}, key string) float64 {
+	switch val := payload[key].(type) {
+	case string:
+		amount, _ := strconv.ParseFloat(val, 64)
+		return amount
+	case float64:
+		return val
+	}
+	return 0
+}
+
 func getInt(payload map[string]interface{}, key string) int {
 	val, ok := payload[key]
 	if !ok {
@@ -88,6 +123,20 @@ func getInt(payload map[string]interface{}, key string) int {
 	return 0
 }
 
+func getStrings(payload map[string]interface{}, key string) []string {
+	values := []string{}
+	items, ok := payload[key].([]interface{})
+	if !ok {
+		return values
+	}
+	for _, item := range items {
+		if str, ok := item.(string); ok && str != "" {
+			values = append(values, str)
+		}
+	}
+	return values
+}
+
 func getBool(payload map[string]interface{}, key string) bool {
 	val, ok := payload[key]
 	if !ok {
@@ -100,12 +149,22 @@ func getBool(payload map[string]interface{}, key string) bool {
 }
 
 func (s *IndexerService) indexPart(event *events.EventEnvelope) error {
+	return s.applyDocument("parts", event, partDocument)
+}
+
+// partDocument builds a part's search document. Only approved parts are
+// searchable, so a part in any other status has none.
+func partDocument(event *events.EventEnvelope) (string, map[string]interface{}, error) {
 	partID, ok := event.Payload["part_id"].(string)
 	if !ok {
-		return fmt.Errorf("part_id not found in event payload")
+		return "", nil, fmt.Errorf("part_id not found in event payload")
+	}
+
+	status := getString(event.Payload, "status", "approved")
+	if status != "approved" {
+		return partID, nil, nil
 	}
 
-	indexName := "parts"
 	document := map[string]interface{}{
 		"id":              partID,
 		"type":            "part",
@@ -116,123 +175,229 @@ func (s *IndexerService) indexPart(event *events.EventEnvelope) error {
 		"manufacturer":    getString(event.Payload, "manufacturer"),
 		"manufacturer_id": getString(event.Payload, "manufacturer_id"),
 		"category":        getString(event.Payload, "category"),
+		"category_id":     getString(event.Payload, "category_id"),
+		"category_path":   getStrings(event.Payload, "category_path"),
 		"visibility":      getString(event.Payload, "visibility", "public"),
-		"status":          getString(event.Payload, "status", "approved"),
+		"status":          status,
 		"company_status":  getString(event.Payload, "company_status", "approved"),
 		"price":           getFloat(event.Payload, "price"),
 		"currency":        getString(event.Payload, "currency"),
 		"stock":           getInt(event.Payload, "stock"),
 		"rating":          getFloat(event.Payload, "rating"),
+		"attributes":      partAttributes(event.Payload),
 		"timestamp":      event.Timestamp,
 	}
 
-	return s.indexDocument(indexName, partID, document)
+	// Snapshot events carry the interchange too; live ones announce it separately
+	if _, ok := event.Payload["superseded"]; ok {
+		for key, value := range partInterchangeFields(event.Payload) {
+			document[key] = value
+		}
+	}
+
+	return partID, document, nil
+}
+
+// updatePartInterchange records a part's current replacement and the part
+// numbers it can stand in for, so searching an old or equivalent number finds it
+func (s *IndexerService) updatePartInterchange(event *events.EventEnvelope) error {
+	partID, ok := event.Payload["part_id"].(string)
+	if !ok {
+		return fmt.Errorf("part_id not found in event payload")
+	}
+
+	return s.backend.UpdateDocument("parts", partID, partInterchangeFields(event.Payload))
+}
+
+func partInterchangeFields(payload map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"superseded":               getBool(payload, "superseded"),
+		"current_part_id":          getString(payload, "current_part_id"),
+		"current_part_number":      getString(payload, "current_part_number"),
+		"alternative_part_ids":     getStrings(payload, "alternative_part_ids"),
+		"interchange_part_numbers": getStrings(payload, "interchange_part_numbers"),
+	}
 }
 
 func (s *IndexerService) indexCompany(event *events.EventEnvelope) error {
+	return s.applyDocument("companies", event, companyDocument)
+}
+
+// companyDocument builds a company's search document. Only approved
+// companies are searchable.
+func companyDocument(event *events.EventEnvelope) (string, map[string]interface{}, error) {
 	companyID, ok := event.Payload["company_id"].(string)
 	if !ok {
-		return fmt.Errorf("company_id not found in event payload")
+		return "", nil, fmt.Errorf("company_id not found in event payload")
+	}
+
+	status := getString(event.Payload, "status", "approved")
+	if status != "approved" {
+		return companyID, nil, nil
 	}
 
-	indexName := "companies"
 	document := map[string]interface{}{
 		"id":             companyID,
 		"type":           "company",
 		"name":           getString(event.Payload, "name"),
+		"legal_name":     getString(event.Payload, "legal_name"),
 		"subdomain":      getString(event.Payload, "subdomain"),
+		"industry":       getString(event.Payload, "industry"),
+		"company_type":   getString(event.Payload, "company_type"),
+		"city":           getString(event.Payload, "city"),
+		"country":        getString(event.Payload, "country"),
 		"visibility":     getString(event.Payload, "visibility", "public"),
-		"status":         getString(event.Payload, "status", "approved"),
-		"company_status": getString(event.Payload, "company_status", "approved"),
+		"status":         status,
+		"company_status": status,
 		"rating":         getFloat(event.Payload, "rating"),
 		"timestamp":      event.Timestamp,
 	}
+	// Events from before companies were located carry no coordinates, so
+	// their postal code places them
+	location, ok := getPoint(event.Payload)
+	if !ok {
+		location, ok = geo.Geocode(getString(event.Payload, "country"), getString(event.Payload, "postal_code"))
+	}
+	if ok {
+		document["location"] = location
+	}
 
-	return s.indexDocument(indexName, companyID, document)
+	return companyID, document, nil
 }
 
 func (s *IndexerService) indexOrder(event *events.EventEnvelope) error {
+	return s.applyDocument("orders", event, orderDocument)
+}
+
+// orderDocument builds a purchase order's search document, private to the
+// buying tenant and the supplier it was placed with
+func orderDocument(event *events.EventEnvelope) (string, map[string]interface{}, error) {
 	orderID, ok := event.Payload["po_id"].(string)
 	if !ok {
-		return fmt.Errorf("po_id not found in event payload")
+		return "", nil, fmt.Errorf("po_id not found in event payload")
 	}
 
-	indexName := "orders"
-	document := map[string]interface{}{
-		"id":         orderID,
-		"po_number":  event.Payload["po_number"],
-		"pr_id":      event.Payload["pr_id"],
-		"quote_id":   event.Payload["quote_id"],
-		"event_type": event.Type,
-		"timestamp":  event.Timestamp,
-	}
+	document := procurementDocument(event, orderID, "order", getString(event.Payload, "po_number"))
+	document["po_number"] = getString(event.Payload, "po_number")
+	document["pr_id"] = getString(event.Payload, "pr_id")
+	document["rfq_id"] = getString(event.Payload, "rfq_id")
+	document["quote_id"] = getString(event.Payload, "quote_id")
+	document["supplier_id"] = getString(event.Payload, "supplier_id")
+	document["payment_status"] = getString(event.Payload, "payment_status")
 
-	return s.indexDocument(indexName, orderID, document)
+	return orderID, document, nil
 }
 
 func (s *IndexerService) indexRFQ(event *events.EventEnvelope) error {
+	return s.applyDocument("rfqs", event, rfqDocument)
+}
+
+// rfqDocument builds an RFQ's search document, private to the buying tenant
+func rfqDocument(event *events.EventEnvelope) (string, map[string]interface{}, error) {
 	rfqID, ok := event.Payload["rfq_id"].(string)
 	if !ok {
-		return fmt.Errorf("rfq_id not found in event payload")
+		return "", nil, fmt.Errorf("rfq_id not found in event payload")
 	}
 
-	indexName := "rfqs"
-	document := map[string]interface{}{
-		"id":         rfqID,
-		"rfq_number": event.Payload["rfq_number"],
-		"pr_id":      event.Payload["pr_id"],
-		"event_type": event.Type,
-		"timestamp":  event.Timestamp,
-	}
+	document := procurementDocument(event, rfqID, "rfq", getString(event.Payload, "rfq_number"))
+	document["rfq_number"] = getString(event.Payload, "rfq_number")
+	document["pr_id"] = getString(event.Payload, "pr_id")
+	document["due_date"] = event.Payload["due_date"]
 
-	return s.indexDocument(indexName, rfqID, document)
+	return rfqID, document, nil
 }
 
 func (s *IndexerService) indexQuote(event *events.EventEnvelope) error {
+	return s.applyDocument("quotes", event, quoteDocument)
+}
+
+// quoteDocument builds a quote's search document, private to the tenant that
+// issued the RFQ and the supplier that submitted the quote. The event comes
+// from the supplier, so the buyer is named in its payload.
+func quoteDocument(event *events.EventEnvelope) (string, map[string]interface{}, error) {
 	quoteID, ok := event.Payload["quote_id"].(string)
 	if !ok {
-		return fmt.Errorf("quote_id not found in event payload")
+		return "", nil, fmt.Errorf("quote_id not found in event payload")
 	}
 
-	indexName := "quotes"
-	document := map[string]interface{}{
-		"id":          quoteID,
-		"quote_number": event.Payload["quote_number"],
-		"rfq_id":      event.Payload["rfq_id"],
-		"supplier_id": event.Payload["supplier_id"],
-		"event_type":  event.Type,
-		"timestamp":   event.Timestamp,
+	document := procurementDocument(event, quoteID, "quote", getString(event.Payload, "quote_number"))
+	delete(document, "tenant_id")
+	if buyerID := getString(event.Payload, "buyer_tenant_id"); buyerID != "" {
+		document["tenant_id"] = buyerID
 	}
+	document["quote_number"] = getString(event.Payload, "quote_number")
+	document["rfq_id"] = getString(event.Payload, "rfq_id")
+	document["rfq_number"] = getString(event.Payload, "rfq_number")
+	document["supplier_id"] = getString(event.Payload, "supplier_id")
+	document["description"] = getString(event.Payload, "notes")
+	document["valid_until"] = event.Payload["valid_until"]
 
-	return s.indexDocument(indexName, quoteID, document)
+	return quoteID, document, nil
 }
 
 func (s *IndexerService) indexShipment(event *events.EventEnvelope) error {
+	return s.applyDocument("shipments", event, shipmentDocument)
+}
+
+// shipmentDocument builds a shipment's search document, private to the
+// receiving tenant
+func shipmentDocument(event *events.EventEnvelope) (string, map[string]interface{}, error) {
 	shipmentID, ok := event.Payload["shipment_id"].(string)
 	if !ok {
-		return fmt.Errorf("shipment_id not found in event payload")
+		return "", nil, fmt.Errorf("shipment_id not found in event payload")
 	}
 
-	indexName := "shipments"
+	document := procurementDocument(event, shipmentID, "shipment", getString(event.Payload, "tracking_number"))
+	document["tracking_number"] = getString(event.Payload, "tracking_number")
+	document["po_id"] = getString(event.Payload, "po_id")
+	document["carrier"] = getString(event.Payload, "carrier")
+	document["origin"] = getString(event.Payload, "origin")
+	document["destination"] = getString(event.Payload, "destination")
+	document["eta"] = event.Payload["eta"]
+
+	return shipmentID, document, nil
+}
+
+// procurementDocument holds the fields procurement documents share. Number is
+// the document's own number, such as a PO or tracking number, which search
+// matches as an identifier; tenant_id is the tenant the event came from.
+func procurementDocument(event *events.EventEnvelope, documentID, documentType, number string) map[string]interface{} {
 	document := map[string]interface{}{
-		"id":             shipmentID,
-		"tracking_number": event.Payload["tracking_number"],
-		"eta":            event.Payload["eta"],
-		"event_type":     event.Type,
-		"timestamp":      event.Timestamp,
+		"id":          documentID,
+		"type":        documentType,
+		"number":      number,
+		"title":       getString(event.Payload, "title"),
+		"description": getString(event.Payload, "description"),
+		"status":      getString(event.Payload, "status"),
+		"items":       getStrings(event.Payload, "items"),
+		"event_type":  event.Type,
+		"timestamp":   event.Timestamp,
 	}
-
-	return s.indexDocument(indexName, shipmentID, document)
+	if event.TenantID != nil {
+		document["tenant_id"] = event.TenantID.String()
+	}
+	// RFQs and shipments carry no amount
+	if _, ok := event.Payload["gross_amount"]; ok {
+		document["amount"] = getAmount(event.Payload, "gross_amount")
+		document["currency"] = getString(event.Payload, "currency")
+	}
+	return document
 }
 
-// indexEquipment indexes equipment documents (called when equipment events are published)
 func (s *IndexerService) indexEquipment(event *events.EventEnvelope) error {
+	return s.applyDocument("equipment", event, equipmentDocument)
+}
+
+// equipmentDocument builds a tenant's equipment search document. Equipment
+// is private to its tenant, so it is kept whatever its status. Its free-text
+// location goes in location_name, as location is the geo_point that searches
+// across all indices filter and sort on.
+func equipmentDocument(event *events.EventEnvelope) (string, map[string]interface{}, error) {
 	equipmentID, ok := event.Payload["equipment_id"].(string)
 	if !ok {
-		return fmt.Errorf("equipment_id not found in event payload")
+		return "", nil, fmt.Errorf("equipment_id not found in event payload")
 	}
 
-	indexName := "equipment"
 	document := map[string]interface{}{
 		"id":             equipmentID,
 		"type":           "equipment",
@@ -250,20 +415,41 @@ func (s *IndexerService) indexEquipment(event *events.EventEnvelope) error {
 		"currency":       getString(event.Payload, "currency"),
 		"rating":         getFloat(event.Payload, "rating"),
 		"eta":            getInt(event.Payload, "eta"),
+		"tenant_id":        getString(event.Payload, "tenant_id"),
+		"equipment_number": getString(event.Payload, "equipment_number"),
+		"serial_number":    getString(event.Payload, "serial_number"),
+		"location_name":    getString(event.Payload, "location"),
+		"year":             getInt(event.Payload, "year"),
 		"timestamp":      event.Timestamp,
 	}
 
-	return s.indexDocument(indexName, equipmentID, document)
+	return equipmentID, document, nil
 }
 
-// indexListing indexes marketplace listing documents (called when listing events are published)
 func (s *IndexerService) indexListing(event *events.EventEnvelope) error {
+	return s.applyDocument("listings", event, listingDocument)
+}
+
+// listingDocument builds a marketplace listing's search document. Listings
+// are searchable while on sale: active or sold out, in an active store.
+func listingDocument(event *events.EventEnvelope) (string, map[string]interface{}, error) {
 	listingID, ok := event.Payload["listing_id"].(string)
 	if !ok {
-		return fmt.Errorf("listing_id not found in event payload")
+		return "", nil, fmt.Errorf("listing_id not found in event payload")
+	}
+
+	listingStatus := getString(event.Payload, "status", "active")
+	onSale := listingStatus == "active" || listingStatus == "sold_out"
+	if _, ok := event.Payload["is_active"]; ok && !getBool(event.Payload, "is_active") {
+		onSale = false
+	}
+	if getString(event.Payload, "store_status", "active") != "active" {
+		onSale = false
+	}
+	if !onSale {
+		return listingID, nil, nil
 	}
 
-	indexName := "listings"
 	document := map[string]interface{}{
 		"id":              listingID,
 		"type":            "listing",
@@ -274,8 +460,13 @@ func (s *IndexerService) indexListing(event *events.EventEnvelope) error {
 		"brand":           getString(event.Payload, "brand"),
 		"category":        getString(event.Payload, "category"),
 		"supplier_id":     getString(event.Payload, "supplier_id"),
+		"store_id":        getString(event.Payload, "store_id"),
+		"part_id":         getString(event.Payload, "part_id"),
+		"listing_type":    getString(event.Payload, "listing_type"),
+		"listing_status":  listingStatus,
 		"visibility":      getString(event.Payload, "visibility", "public"),
-		"status":          getString(event.Payload, "status", "approved"),
+		// Listings are indexed only while on sale, which search treats as approved
+		"status":          "approved",
 		"company_status":  getString(event.Payload, "company_status", "approved"),
 		"price":           getFloat(event.Payload, "price"),
 		"currency":        getString(event.Payload, "currency"),
@@ -285,83 +476,50 @@ func (s *IndexerService) indexListing(event *events.EventEnvelope) error {
 		"eta":             getInt(event.Payload, "eta"),
 		"timestamp":       event.Timestamp,
 	}
-
-	return s.indexDocument(indexName, listingID, document)
-}
-
-func (s *IndexerService) indexDocument(indexName, documentID string, document map[string]interface{}) error {
-	// Ensure index exists
-	if err := s.createIndexIfNotExists(indexName); err != nil {
-		return fmt.Errorf("failed to create index: %w", err)
-	}
-
-	// Index document
-	url := fmt.Sprintf("%s/%s/_doc/%s", s.opensearchURL, indexName, documentID)
-	
-	jsonData, err := json.Marshal(document)
-	if err != nil {
-		return fmt.Errorf("failed to marshal document: %w", err)
-	}
-
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to index document: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("opensearch error: %s - %s", resp.Status, string(body))
+	if location, ok := getPoint(event.Payload); ok {
+		document["location"] = location
 	}
 
-	return nil
+	return listingID, document, nil
 }
 
-func (s *IndexerService) createIndexIfNotExists(indexName string) error {
-	url := fmt.Sprintf("%s/%s", s.opensearchURL, indexName)
-	
-	req, err := http.NewRequest("HEAD", url, nil)
-	if err != nil {
-		return err
-	}
+// documentBuilder builds an entity's search document from the event
+// announcing its state. A nil document means the entity is not searchable.
+type documentBuilder func(event *events.EventEnvelope) (string, map[string]interface{}, error)
 
-	resp, err := s.httpClient.Do(req)
+// applyDocument indexes the entity an event describes, or removes it from
+// search when it is no longer searchable
+func (s *IndexerService) applyDocument(indexName string, event *events.EventEnvelope, build documentBuilder) error {
+	documentID, document, err := build(event)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	// Index exists
-	if resp.StatusCode == 200 {
-		return nil
+	if document == nil {
+		return s.backend.DeleteDocument(indexName, documentID, event.Version)
 	}
-
-	// Create index with proper mapping
-	mapping := GetIndexMapping(indexName)
-	createReq, err := http.NewRequest("PUT", url, bytes.NewBuffer([]byte(mapping)))
-	if err != nil {
+	if err := s.backend.IndexDocument(indexName, documentID, document, event.Version); err != nil {
 		return err
 	}
 
-	createReq.Header.Set("Content-Type", "application/json")
-
-	createResp, err := s.httpClient.Do(createReq)
-	if err != nil {
-		return err
+	// The document is indexed, so a failed alert is logged rather than
+	// failing the event and indexing it again
+	if s.alerter != nil {
+		if err := s.alerter.Check(indexName, documentID, document); err != nil {
+			log.Printf("Saved search alerts for %s %s failed: %v", indexName, documentID, err)
+		}
 	}
-	defer createResp.Body.Close()
+	return nil
+}
 
-	if createResp.StatusCode >= 400 {
-		body, _ := io.ReadAll(createResp.Body)
-		return fmt.Errorf("failed to create index: %s - %s", createResp.Status, string(body))
-	}
+func (s *IndexerService) indexDocument(indexName, documentID string, document map[string]interface{}) error {
+	return s.backend.IndexDocument(indexName, documentID, document, 0)
+}
 
-	return nil
+// deleteFromEvent removes the document named by an ID in the event payload
+func (s *IndexerService) deleteFromEvent(event *events.EventEnvelope, indexName, idKey string) error {
+	documentID, ok := event.Payload[idKey].(string)
+	if !ok {
+		return fmt.Errorf("%s not found in event payload", idKey)
+	}
+	return s.backend.DeleteDocument(indexName, documentID, event.Version)
 }
@@ -5,11 +5,10 @@ import (
 	"time"
 
 	"github.com/b2b-platform/shared/events"
-	"github.com/google/uuid"
 )
 
 func TestIndexerService_HandleEvent(t *testing.T) {
-	service := NewIndexerService()
+	service := NewIndexerService(NewOpenSearchBackend())
 
 	tests := []struct {
 		name    string
@@ -126,7 +125,7 @@ func TestIndexerService_HandleEvent(t *testing.T) {
 }
 
 func TestIndexerService_EventPayloadValidation(t *testing.T) {
-	service := NewIndexerService()
+	service := NewIndexerService(NewOpenSearchBackend())
 
 	// Test missing required fields
 	tests := []struct {
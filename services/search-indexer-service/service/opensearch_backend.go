@@ -0,0 +1,670 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenSearchBackend keeps search documents in OpenSearch
+type OpenSearchBackend struct {
+	opensearchURL string
+	httpClient    *http.Client
+
+	bulk *BulkIndexer // Batches writes when set
+
+	mu            sync.Mutex
+	rebuildChecks map[string]rebuildCheck // Index name -> whether it is being rebuilt
+	knownIndices  map[string]time.Time    // Index name -> when it was last seen to exist
+}
+
+func NewOpenSearchBackend() *OpenSearchBackend {
+	opensearchURL := os.Getenv("OPENSEARCH_URL")
+	if opensearchURL == "" {
+		opensearchURL = "http://localhost:9200"
+	}
+
+	return &OpenSearchBackend{
+		opensearchURL: opensearchURL,
+		httpClient:    &http.Client{},
+		rebuildChecks: make(map[string]rebuildCheck),
+		knownIndices:  make(map[string]time.Time),
+	}
+}
+
+// UseBulkIndexer sends document writes through a bulk indexer instead of
+// one request each
+func (s *OpenSearchBackend) UseBulkIndexer(bulk *BulkIndexer) {
+	s.bulk = bulk
+}
+
+// IndexDocument replaces a document. A non-zero version is the
+// entity's version from its event; an event older than the indexed document
+// is ignored, so events delivered out of order or twice leave the newest state.
+// While the index is being rebuilt the index being built gets the write too.
+func (s *OpenSearchBackend) IndexDocument(indexName, documentID string, document map[string]interface{}, version int64) error {
+	// Ensure index exists
+	if err := s.createIndexIfNotExists(indexName); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	err := s.putDocument(indexName, documentID, document, version, false)
+	if errors.Is(err, errAliasMissing) {
+		// The index was deleted since it was last seen to exist
+		s.forgetIndex(indexName)
+		if err := s.createIndexIfNotExists(indexName); err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+		err = s.putDocument(indexName, documentID, document, version, false)
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.rebuilding(indexName) {
+		err := s.putDocument(rebuildAlias(indexName), documentID, document, version, true)
+		if errors.Is(err, errAliasMissing) {
+			// The rebuild finished or was abandoned
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// withIndexedAt copies a document, adding when the indexer wrote it. Unlike
+// the source's timestamp it is on the indexer's clock, which a tenant reindex
+// compares its own start against.
+func withIndexedAt(document map[string]interface{}) map[string]interface{} {
+	stamped := make(map[string]interface{}, len(document)+1)
+	for field, value := range document {
+		stamped[field] = value
+	}
+	stamped["indexed_at"] = time.Now().UTC()
+	return stamped
+}
+
+// errAliasMissing is returned by a document write whose alias is gone
+var errAliasMissing = errors.New("index alias is missing")
+
+// putDocument writes a document through an alias. Writes require the alias,
+// so a write never creates an index under the alias's name; when it is gone
+// the write fails with errAliasMissing. A live write queued for a bulk
+// request creates the index again and is retried, while a write to the index
+// being rebuilt is dropped.
+func (s *OpenSearchBackend) putDocument(target, documentID string, document map[string]interface{}, version int64, rebuild bool) error {
+	document = withIndexedAt(document)
+	if s.bulk != nil {
+		action, err := newBulkAction("index", target, documentID, version, true, document)
+		if err != nil {
+			return err
+		}
+		if !rebuild {
+			action.recreateIndex = target
+		}
+		return s.bulk.Add(action)
+	}
+
+	url := versionedURL(fmt.Sprintf("%s/%s/_doc/%s", s.opensearchURL, target, documentID), version)
+	url += querySeparator(url) + "require_alias=true"
+
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %w", err)
+	}
+
+	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to index document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// A newer version is already indexed
+	if resp.StatusCode == http.StatusConflict && version > 0 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errAliasMissing
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("opensearch error: %s - %s", resp.Status, string(body))
+	}
+
+	return nil
+}
+
+// DeleteDocument removes a document, unless a version newer than the event's
+// is indexed. Deleting a document that is not indexed succeeds.
+func (s *OpenSearchBackend) DeleteDocument(indexName, documentID string, version int64) error {
+	if err := s.removeDocument(indexName, documentID, version); err != nil {
+		return err
+	}
+	if s.rebuilding(indexName) {
+		return s.removeDocument(rebuildAlias(indexName), documentID, version)
+	}
+	return nil
+}
+
+func (s *OpenSearchBackend) removeDocument(target, documentID string, version int64) error {
+	if s.bulk != nil {
+		return s.queueBulk("delete", target, documentID, version, false, nil)
+	}
+
+	url := versionedURL(fmt.Sprintf("%s/%s/_doc/%s", s.opensearchURL, target, documentID), version)
+
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode == http.StatusConflict && version > 0 {
+		return nil
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("opensearch error: %s - %s", resp.Status, string(body))
+	}
+
+	return nil
+}
+
+func (s *OpenSearchBackend) queueBulk(op, target, documentID string, version int64, requireAlias bool, body map[string]interface{}) error {
+	action, err := newBulkAction(op, target, documentID, version, requireAlias, body)
+	if err != nil {
+		return err
+	}
+	return s.bulk.Add(action)
+}
+
+// versionedURL applies external versioning to a document write. Equal
+// versions are accepted so that redelivering an event is harmless.
+func versionedURL(url string, version int64) string {
+	if version <= 0 {
+		return url
+	}
+	return fmt.Sprintf("%s?version=%d&version_type=external_gte", url, version)
+}
+
+func querySeparator(url string) string {
+	if strings.Contains(url, "?") {
+		return "&"
+	}
+	return "?"
+}
+
+// UpdateDocument merges fields into an indexed document. Documents not indexed
+// yet are skipped; they get the fields when they are next indexed in full.
+func (s *OpenSearchBackend) UpdateDocument(indexName, documentID string, fields map[string]interface{}) error {
+	if err := s.mergeDocument(indexName, documentID, fields); err != nil {
+		return err
+	}
+	if s.rebuilding(indexName) {
+		return s.mergeDocument(rebuildAlias(indexName), documentID, fields)
+	}
+	return nil
+}
+
+func (s *OpenSearchBackend) mergeDocument(target, documentID string, fields map[string]interface{}) error {
+	if s.bulk != nil {
+		return s.queueBulk("update", target, documentID, 0, false, fields)
+	}
+
+	url := fmt.Sprintf("%s/%s/_update/%s", s.opensearchURL, target, documentID)
+
+	jsonData, err := json.Marshal(map[string]interface{}{"doc": fields})
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("opensearch error: %s - %s", resp.Status, string(body))
+	}
+
+	return nil
+}
+
+// indexExistsTTL is how long an index seen to exist is trusted to still
+// exist, sparing a check before every write
+const indexExistsTTL = 5 * time.Minute
+
+// createIndexIfNotExists creates an index, as the first version behind an
+// alias of the index name so that it can later be rebuilt and swapped
+func (s *OpenSearchBackend) createIndexIfNotExists(indexName string) error {
+	s.mu.Lock()
+	seenAt, ok := s.knownIndices[indexName]
+	s.mu.Unlock()
+	if ok && time.Since(seenAt) < indexExistsTTL {
+		return nil
+	}
+
+	exists, err := s.exists(indexName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		versioned, err := s.nextIndexVersion(indexName)
+		if err != nil {
+			return err
+		}
+		if err := s.createIndex(versioned, indexName, indexName); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.knownIndices[indexName] = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// forgetIndex drops what is known of an index's existence, so the next write
+// checks for it again
+func (s *OpenSearchBackend) forgetIndex(indexName string) {
+	s.mu.Lock()
+	delete(s.knownIndices, indexName)
+	s.mu.Unlock()
+}
+
+func (s *OpenSearchBackend) Search(query SearchQuery) (*SearchHits, error) {
+	esQuery := map[string]interface{}{
+		"from":  query.From,
+		"size":  query.Size,
+		"query": queryClause(query),
+	}
+
+	if len(query.Sort) > 0 {
+		sort := make([]map[string]interface{}, 0, len(query.Sort))
+		for _, field := range query.Sort {
+			order := "asc"
+			if field.Desc {
+				order = "desc"
+			}
+			if field.Near != nil {
+				sort = append(sort, map[string]interface{}{
+					"_geo_distance": map[string]interface{}{
+						field.Field:       map[string]interface{}{"lat": field.Near.Lat, "lon": field.Near.Lon},
+						"order":           order,
+						"unit":            "km",
+						"ignore_unmapped": true,
+					},
+				})
+				continue
+			}
+			sort = append(sort, map[string]interface{}{field.Field: map[string]interface{}{"order": order}})
+		}
+		esQuery["sort"] = sort
+	}
+
+	aggs := make(map[string]interface{}, len(query.Facets)+1)
+	for name, field := range query.Facets {
+		aggs[name] = map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field": field,
+			},
+		}
+	}
+	if query.AttributeFacets {
+		aggs[attributeFacetsAggregation] = attributeAggregation()
+	}
+	if len(aggs) > 0 {
+		esQuery["aggs"] = aggs
+	}
+
+	var result struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []openSearchHit `json:"hits"`
+		} `json:"hits"`
+		Aggregations map[string]json.RawMessage `json:"aggregations"`
+	}
+	url := fmt.Sprintf("%s/%s/_search", s.opensearchURL, strings.Join(query.Indices, ","))
+	if err := s.sendJSON("POST", url, esQuery, &result); err != nil {
+		return nil, fmt.Errorf("opensearch search failed: %w", err)
+	}
+
+	hits := &SearchHits{
+		Total:  result.Hits.Total.Value,
+		Hits:   make([]SearchHit, 0, len(result.Hits.Hits)),
+		Facets: make(map[string][]FacetBucket, len(result.Aggregations)),
+	}
+	for _, hit := range result.Hits.Hits {
+		hits.Hits = append(hits.Hits, hit.searchHit())
+	}
+	for name := range query.Facets {
+		var terms termsAggregation
+		if raw, ok := result.Aggregations[name]; ok {
+			if err := json.Unmarshal(raw, &terms); err != nil {
+				return nil, fmt.Errorf("failed to decode facet %s: %w", name, err)
+			}
+		}
+		hits.Facets[name] = terms.Buckets
+	}
+	if raw, ok := result.Aggregations[attributeFacetsAggregation]; ok {
+		attributes, err := parseAttributeAggregation(raw)
+		if err != nil {
+			return nil, err
+		}
+		hits.Attributes = attributes
+	}
+	return hits, nil
+}
+
+// queryClause is the query matching a search's text and filter
+func queryClause(query SearchQuery) map[string]interface{} {
+	texts := append([]string{query.Text}, query.Alternatives...)
+	matches := make([]map[string]interface{}, 0, len(texts)+len(query.Identifiers))
+	for _, text := range texts {
+		multiMatch := map[string]interface{}{
+			"query":    text,
+			"fields":   query.Fields,
+			"type":     "best_fields",
+			"operator": "and",
+		}
+		if query.Fuzziness != "" {
+			multiMatch["fuzziness"] = query.Fuzziness
+			multiMatch["prefix_length"] = 1
+		}
+		matches = append(matches, map[string]interface{}{"multi_match": multiMatch})
+	}
+
+	// The identifier analyzer reduces the whole text to one normalized term
+	for _, identifier := range parseBoostedFields(query.Identifiers) {
+		matches = append(matches, map[string]interface{}{
+			"match": map[string]interface{}{
+				identifier.name + ".normalized": map[string]interface{}{
+					"query": query.Text,
+					"boost": identifier.boost,
+				},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must": []map[string]interface{}{
+				{
+					"bool": map[string]interface{}{
+						"should":               matches,
+						"minimum_should_match": 1,
+					},
+				},
+			},
+			"filter": []map[string]interface{}{filterClause(query.Filter)},
+		},
+	}
+}
+
+const attributeFacetsAggregation = "attribute_facets"
+
+type termsAggregation struct {
+	Buckets []FacetBucket `json:"buckets"`
+}
+
+// attributeAggregation summarizes nested part attributes per attribute code,
+// counting parts rather than attribute values
+func attributeAggregation() map[string]interface{} {
+	first := func(field string) map[string]interface{} {
+		return map[string]interface{}{"terms": map[string]interface{}{"field": field, "size": 1}}
+	}
+
+	return map[string]interface{}{
+		"nested": map[string]interface{}{"path": "attributes"},
+		"aggs": map[string]interface{}{
+			"codes": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "attributes.code",
+					"size":  attributeFacetSize,
+				},
+				"aggs": map[string]interface{}{
+					"name":      first("attributes.name"),
+					"data_type": first("attributes.data_type"),
+					"unit":      first("attributes.unit"),
+					"values": map[string]interface{}{
+						"terms": map[string]interface{}{"field": "attributes.value", "size": facetSize},
+					},
+					"min":   map[string]interface{}{"min": map[string]interface{}{"field": "attributes.number"}},
+					"max":   map[string]interface{}{"max": map[string]interface{}{"field": "attributes.number_max"}},
+					"parts": map[string]interface{}{"reverse_nested": map[string]interface{}{}},
+				},
+			},
+		},
+	}
+}
+
+func parseAttributeAggregation(raw json.RawMessage) ([]AttributeFacet, error) {
+	type metric struct {
+		Value *float64 `json:"value"`
+	}
+	var aggregation struct {
+		Codes struct {
+			Buckets []struct {
+				Key      string           `json:"key"`
+				Name     termsAggregation `json:"name"`
+				DataType termsAggregation `json:"data_type"`
+				Unit     termsAggregation `json:"unit"`
+				Values   termsAggregation `json:"values"`
+				Min      metric           `json:"min"`
+				Max      metric           `json:"max"`
+				Parts    struct {
+					DocCount int64 `json:"doc_count"`
+				} `json:"parts"`
+			} `json:"buckets"`
+		} `json:"codes"`
+	}
+	if err := json.Unmarshal(raw, &aggregation); err != nil {
+		return nil, fmt.Errorf("failed to decode attribute facets: %w", err)
+	}
+
+	firstKey := func(terms termsAggregation) string {
+		if len(terms.Buckets) == 0 {
+			return ""
+		}
+		key, _ := terms.Buckets[0].Key.(string)
+		return key
+	}
+
+	facets := make([]AttributeFacet, 0, len(aggregation.Codes.Buckets))
+	for _, bucket := range aggregation.Codes.Buckets {
+		facets = append(facets, AttributeFacet{
+			Code:     bucket.Key,
+			Name:     firstKey(bucket.Name),
+			DataType: firstKey(bucket.DataType),
+			Unit:     firstKey(bucket.Unit),
+			Count:    bucket.Parts.DocCount,
+			Values:   bucket.Values.Buckets,
+			Min:      bucket.Min.Value,
+			Max:      bucket.Max.Value,
+		})
+	}
+	return facets, nil
+}
+
+// Autocomplete matches word prefixes through the edge n-gram "autocomplete"
+// subfield of each field
+func (s *OpenSearchBackend) Autocomplete(query AutocompleteQuery) ([]SearchHit, error) {
+	fields := make([]string, 0, len(query.Fields))
+	source := make([]string, 0, len(query.Fields))
+	for _, field := range query.Fields {
+		name, boost, boosted := strings.Cut(field, "^")
+		if boosted {
+			fields = append(fields, name+".autocomplete^"+boost)
+		} else {
+			fields = append(fields, name+".autocomplete")
+		}
+		source = append(source, name)
+	}
+
+	esQuery := map[string]interface{}{
+		"size": query.Size,
+		"query": map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  query.Text,
+				"fields": fields,
+				"type":   "bool_prefix",
+			},
+		},
+		"_source": source,
+	}
+
+	var result struct {
+		Hits struct {
+			Hits []openSearchHit `json:"hits"`
+		} `json:"hits"`
+	}
+	url := fmt.Sprintf("%s/%s/_search", s.opensearchURL, strings.Join(query.Indices, ","))
+	if err := s.sendJSON("POST", url, esQuery, &result); err != nil {
+		return nil, fmt.Errorf("opensearch autocomplete failed: %w", err)
+	}
+
+	hits := make([]SearchHit, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		hits = append(hits, hit.searchHit())
+	}
+	return hits, nil
+}
+
+type openSearchHit struct {
+	Index  string                 `json:"_index"`
+	ID     string                 `json:"_id"`
+	Score  float64                `json:"_score"`
+	Source map[string]interface{} `json:"_source"`
+}
+
+func (h openSearchHit) searchHit() SearchHit {
+	return SearchHit{
+		Index:  h.Index,
+		ID:     h.ID,
+		Score:  h.Score,
+		Source: h.Source,
+	}
+}
+
+// filterClause translates a filter into an OpenSearch query clause
+func filterClause(filter Filter) map[string]interface{} {
+	switch {
+	case filter.Nested != "":
+		clauses := make([]map[string]interface{}, 0, len(filter.All))
+		for _, f := range filter.All {
+			clauses = append(clauses, filterClause(f))
+		}
+		return map[string]interface{}{
+			"nested": map[string]interface{}{
+				"path": filter.Nested,
+				// Indices without the nested field, such as listings, just don't match
+				"ignore_unmapped": true,
+				"query": map[string]interface{}{
+					"bool": map[string]interface{}{
+						"filter": clauses,
+					},
+				},
+			},
+		}
+	case filter.Field != "" && filter.Near != nil:
+		return map[string]interface{}{
+			"geo_distance": map[string]interface{}{
+				"distance":   fmt.Sprintf("%gkm", filter.WithinKm),
+				filter.Field: map[string]interface{}{"lat": filter.Near.Lat, "lon": filter.Near.Lon},
+				// Indices without the field, such as parts, just don't match
+				"ignore_unmapped": true,
+			},
+		}
+	case filter.Field != "" && filter.isPeriod():
+		bounds := map[string]interface{}{}
+		if filter.Since != nil {
+			bounds["gte"] = filter.Since.Format(time.RFC3339Nano)
+		}
+		if filter.Until != nil {
+			bounds["lte"] = filter.Until.Format(time.RFC3339Nano)
+		}
+		return map[string]interface{}{
+			"range": map[string]interface{}{
+				filter.Field: bounds,
+			},
+		}
+	case filter.Field != "" && filter.isRange():
+		bounds := map[string]interface{}{}
+		if filter.Min != nil {
+			bounds["gte"] = *filter.Min
+		}
+		if filter.Max != nil {
+			bounds["lte"] = *filter.Max
+		}
+		return map[string]interface{}{
+			"range": map[string]interface{}{
+				filter.Field: bounds,
+			},
+		}
+	case filter.Field != "":
+		return map[string]interface{}{
+			"term": map[string]interface{}{
+				filter.Field: filter.Value,
+			},
+		}
+	case len(filter.All) > 0:
+		clauses := make([]map[string]interface{}, 0, len(filter.All))
+		for _, f := range filter.All {
+			clauses = append(clauses, filterClause(f))
+		}
+		return map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": clauses,
+			},
+		}
+	case len(filter.Any) > 0:
+		clauses := make([]map[string]interface{}, 0, len(filter.Any))
+		for _, f := range filter.Any {
+			clauses = append(clauses, filterClause(f))
+		}
+		return map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should":               clauses,
+				"minimum_should_match": 1,
+			},
+		}
+	default:
+		return map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Saved searches are stored as percolator queries, so a document can be
+// matched against all of them in one request
+
+// percolatePageSize is how many matching searches are read per request
+const percolatePageSize = 500
+
+// maxPercolateMatches is as far as OpenSearch pages results
+const maxPercolateMatches = 10000
+
+type savedSearchDocument struct {
+	Search  SavedSearch            `json:"search"`
+	Indices []string               `json:"search_indices"`
+	Query   map[string]interface{} `json:"search_query"`
+}
+
+func (s *OpenSearchBackend) SaveSearch(search *SavedSearch, query SearchQuery) error {
+	if err := s.createIndexIfNotExists(savedSearchesIndex); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	document := savedSearchDocument{
+		Search:  *search,
+		Indices: query.Indices,
+		Query:   queryClause(query),
+	}
+	// Saved searches are written directly, and visible to the user's next request
+	url := fmt.Sprintf("%s/%s/_doc/%s?refresh=wait_for", s.opensearchURL, savedSearchesIndex, search.ID)
+	if err := s.sendJSON("PUT", url, document, nil); err != nil {
+		return fmt.Errorf("failed to save search: %w", err)
+	}
+	return nil
+}
+
+func (s *OpenSearchBackend) GetSearch(id string) (*SavedSearch, error) {
+	var result struct {
+		Source savedSearchDocument `json:"_source"`
+	}
+	found, err := s.getJSONIfExists(fmt.Sprintf("%s/%s/_doc/%s", s.opensearchURL, savedSearchesIndex, id), &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	if !found {
+		return nil, ErrSavedSearchNotFound
+	}
+	return &result.Source.Search, nil
+}
+
+func (s *OpenSearchBackend) ListSearches(userID string) ([]SavedSearch, error) {
+	query := map[string]interface{}{
+		"size": maxSavedSearches * 2,
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{"search.user_id": userID},
+		},
+		"_source": []string{"search"},
+	}
+
+	searches, err := s.searchSavedSearches(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	return searches, nil
+}
+
+func (s *OpenSearchBackend) DeleteSearch(id string) error {
+	url := fmt.Sprintf("%s/%s/_doc/%s?refresh=wait_for", s.opensearchURL, savedSearchesIndex, id)
+	err := s.sendJSON("DELETE", url, nil, nil)
+	if errors.Is(err, errNotFound) {
+		return ErrSavedSearchNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	return nil
+}
+
+// MatchSearches percolates the document through the saved searches of its
+// index that have alerts on
+func (s *OpenSearchBackend) MatchSearches(index string, document map[string]interface{}) ([]SavedSearch, error) {
+	matches := []SavedSearch{}
+	for from := 0; from < maxPercolateMatches; from += percolatePageSize {
+		query := map[string]interface{}{
+			"from": from,
+			"size": percolatePageSize,
+			"query": map[string]interface{}{
+				"bool": map[string]interface{}{
+					"filter": []map[string]interface{}{
+						{"term": map[string]interface{}{"search.alerts": true}},
+						{"term": map[string]interface{}{"search_indices": index}},
+						{"percolate": map[string]interface{}{"field": "search_query", "document": document}},
+					},
+				},
+			},
+			"_source": []string{"search"},
+		}
+
+		page, err := s.searchSavedSearches(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to percolate %s document: %w", index, err)
+		}
+		matches = append(matches, page...)
+		if len(page) < percolatePageSize {
+			break
+		}
+	}
+	return matches, nil
+}
+
+// searchSavedSearches runs a query over saved searches. There are none
+// until the first is saved.
+func (s *OpenSearchBackend) searchSavedSearches(query map[string]interface{}) ([]SavedSearch, error) {
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source savedSearchDocument `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	err := s.sendJSON("POST", fmt.Sprintf("%s/%s/_search", s.opensearchURL, savedSearchesIndex), query, &result)
+	if errors.Is(err, errNotFound) {
+		return []SavedSearch{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	searches := make([]SavedSearch, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		searches = append(searches, hit.Source.Search)
+	}
+	return searches, nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// popularQueryCandidates is how many of the most searched queries are checked
+// for enough tenants
+const popularQueryCandidates = 100
+
+type queryStatsBucket struct {
+	Key      string `json:"key"`
+	DocCount int64  `json:"doc_count"`
+	Results  struct {
+		Value float64 `json:"value"`
+	} `json:"results"`
+	Last struct {
+		Value float64 `json:"value"`
+	} `json:"last"`
+}
+
+type queryStatsAggregation struct {
+	Buckets []queryStatsBucket `json:"buckets"`
+}
+
+func (a queryStatsAggregation) stats() []QueryStats {
+	stats := make([]QueryStats, 0, len(a.Buckets))
+	for _, bucket := range a.Buckets {
+		stats = append(stats, QueryStats{
+			Query:          bucket.Key,
+			Searches:       bucket.DocCount,
+			AverageResults: bucket.Results.Value,
+			LastSearchedAt: time.UnixMilli(int64(bucket.Last.Value)).UTC(),
+		})
+	}
+	return stats
+}
+
+// SearchAnalytics aggregates the period's searches and, separately, its clicks
+func (s *OpenSearchBackend) SearchAnalytics(query AnalyticsQuery) (*AnalyticsReport, error) {
+	queryStats := func() map[string]interface{} {
+		return map[string]interface{}{
+			"terms": map[string]interface{}{"field": "normalized_query", "size": query.Size},
+			"aggs": map[string]interface{}{
+				"results": map[string]interface{}{"avg": map[string]interface{}{"field": "result_count"}},
+				"last":    map[string]interface{}{"max": map[string]interface{}{"field": "timestamp"}},
+			},
+		}
+	}
+
+	// A result position was seen by every search whose page included it
+	impressions := make(map[string]interface{}, maxReportPositions)
+	for position := 1; position <= maxReportPositions; position++ {
+		impressions[strconv.Itoa(position)] = map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []map[string]interface{}{
+					{"range": map[string]interface{}{"first_position": map[string]interface{}{"gte": 1, "lte": position}}},
+					{"range": map[string]interface{}{"last_position": map[string]interface{}{"gte": position}}},
+				},
+			},
+		}
+	}
+
+	searchesQuery := map[string]interface{}{
+		"size":             0,
+		"track_total_hits": true,
+		"query":            analyticsFilter(query),
+		"aggs": map[string]interface{}{
+			"latency": map[string]interface{}{"avg": map[string]interface{}{"field": "latency_ms"}},
+			"queries": queryStats(),
+			"zero_results": map[string]interface{}{
+				"filter": map[string]interface{}{"term": map[string]interface{}{"result_count": 0}},
+				"aggs":   map[string]interface{}{"queries": queryStats()},
+			},
+			"impressions": map[string]interface{}{
+				"filters": map[string]interface{}{"filters": impressions},
+			},
+		},
+	}
+
+	var searches struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+		} `json:"hits"`
+		Aggregations struct {
+			Latency struct {
+				Value float64 `json:"value"`
+			} `json:"latency"`
+			Queries     queryStatsAggregation `json:"queries"`
+			ZeroResults struct {
+				DocCount int64                 `json:"doc_count"`
+				Queries  queryStatsAggregation `json:"queries"`
+			} `json:"zero_results"`
+			Impressions struct {
+				Buckets map[string]struct {
+					DocCount int64 `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"impressions"`
+		} `json:"aggregations"`
+	}
+	err := s.sendJSON("POST", fmt.Sprintf("%s/%s/_search", s.opensearchURL, searchLogsIndex), searchesQuery, &searches)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("failed to aggregate searches: %w", err)
+	}
+
+	clicksQuery := map[string]interface{}{
+		"size":             0,
+		"track_total_hits": true,
+		"query":            analyticsFilter(query),
+		"aggs": map[string]interface{}{
+			"searches": map[string]interface{}{
+				"cardinality": map[string]interface{}{"field": "search_id", "precision_threshold": 40000},
+			},
+			"positions": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"range": map[string]interface{}{"position": map[string]interface{}{"gte": 1, "lte": maxReportPositions}},
+				},
+				"aggs": map[string]interface{}{
+					"positions": map[string]interface{}{
+						"terms": map[string]interface{}{"field": "position", "size": maxReportPositions},
+					},
+				},
+			},
+		},
+	}
+
+	var clicks struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+		} `json:"hits"`
+		Aggregations struct {
+			Searches struct {
+				Value int64 `json:"value"`
+			} `json:"searches"`
+			Positions struct {
+				Positions struct {
+					Buckets []struct {
+						Key      int   `json:"key"`
+						DocCount int64 `json:"doc_count"`
+					} `json:"buckets"`
+				} `json:"positions"`
+			} `json:"positions"`
+		} `json:"aggregations"`
+	}
+	err = s.sendJSON("POST", fmt.Sprintf("%s/%s/_search", s.opensearchURL, searchClicksIndex), clicksQuery, &clicks)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("failed to aggregate search clicks: %w", err)
+	}
+
+	report := &AnalyticsReport{
+		Searches:           searches.Hits.Total.Value,
+		ZeroResultSearches: searches.Aggregations.ZeroResults.DocCount,
+		AverageLatencyMS:   searches.Aggregations.Latency.Value,
+		Clicks:             clicks.Hits.Total.Value,
+		ClickedSearches:    clicks.Aggregations.Searches.Value,
+		TopQueries:         searches.Aggregations.Queries.stats(),
+		ZeroResultQueries:  searches.Aggregations.ZeroResults.Queries.stats(),
+	}
+
+	clicksAt := make(map[int]int64, maxReportPositions)
+	for _, bucket := range clicks.Aggregations.Positions.Positions.Buckets {
+		clicksAt[bucket.Key] = bucket.DocCount
+	}
+	for position := 1; position <= maxReportPositions; position++ {
+		report.Positions = append(report.Positions, PositionStats{
+			Position:    position,
+			Impressions: searches.Aggregations.Impressions.Buckets[strconv.Itoa(position)].DocCount,
+			Clicks:      clicksAt[position],
+		})
+	}
+	return report, nil
+}
+
+// PopularQueries reads the most searched queries with the prefix, dropping
+// those too few tenants searched
+func (s *OpenSearchBackend) PopularQueries(prefix string, since time.Time, minTenants, size int) ([]string, error) {
+	query := map[string]interface{}{
+		"size": 0,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []map[string]interface{}{
+					{"range": map[string]interface{}{"timestamp": map[string]interface{}{"gte": since}}},
+					{"range": map[string]interface{}{"result_count": map[string]interface{}{"gt": 0}}},
+					{"prefix": map[string]interface{}{"normalized_query": prefix}},
+				},
+			},
+		},
+		"aggs": map[string]interface{}{
+			"queries": map[string]interface{}{
+				"terms": map[string]interface{}{"field": "normalized_query", "size": popularQueryCandidates},
+				"aggs": map[string]interface{}{
+					"tenants": map[string]interface{}{"cardinality": map[string]interface{}{"field": "tenant_id"}},
+					"shared": map[string]interface{}{
+						"bucket_selector": map[string]interface{}{
+							"buckets_path": map[string]interface{}{"tenants": "tenants"},
+							"script":       fmt.Sprintf("params.tenants >= %d", minTenants),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var result struct {
+		Aggregations struct {
+			Queries struct {
+				Buckets []struct {
+					Key string `json:"key"`
+				} `json:"buckets"`
+			} `json:"queries"`
+		} `json:"aggregations"`
+	}
+	err := s.sendJSON("POST", fmt.Sprintf("%s/%s/_search", s.opensearchURL, searchLogsIndex), query, &result)
+	if errors.Is(err, errNotFound) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate popular queries: %w", err)
+	}
+
+	queries := make([]string, 0, size)
+	for _, bucket := range result.Aggregations.Queries.Buckets {
+		if len(queries) == size {
+			break
+		}
+		queries = append(queries, bucket.Key)
+	}
+	return queries, nil
+}
+
+// analyticsFilter selects the period's records, of one tenant when asked
+func analyticsFilter(query AnalyticsQuery) map[string]interface{} {
+	filters := []map[string]interface{}{
+		{"range": map[string]interface{}{"timestamp": map[string]interface{}{"gte": query.From, "lte": query.To}}},
+	}
+	if query.TenantID != "" {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"tenant_id": query.TenantID}})
+	}
+	return map[string]interface{}{"bool": map[string]interface{}{"filter": filters}}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxSynonymSets is how many synonym sets are read back
+const maxSynonymSets = 1000
+
+func (s *OpenSearchBackend) SaveSynonymSet(set *SynonymSet) error {
+	if err := s.createIndexIfNotExists(synonymSetsIndex); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/%s/_doc/%s?refresh=wait_for", s.opensearchURL, synonymSetsIndex, set.ID)
+	if err := s.sendJSON("PUT", url, set, nil); err != nil {
+		return fmt.Errorf("failed to save synonym set: %w", err)
+	}
+	return nil
+}
+
+func (s *OpenSearchBackend) DeleteSynonymSet(id string) error {
+	url := fmt.Sprintf("%s/%s/_doc/%s?refresh=wait_for", s.opensearchURL, synonymSetsIndex, id)
+	err := s.sendJSON("DELETE", url, nil, nil)
+	if errors.Is(err, errNotFound) {
+		return ErrSynonymSetNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete synonym set: %w", err)
+	}
+	return nil
+}
+
+// ListSynonymSets returns every synonym set. There are none until the first
+// is saved.
+func (s *OpenSearchBackend) ListSynonymSets() ([]SynonymSet, error) {
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source SynonymSet `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	query := map[string]interface{}{
+		"size":  maxSynonymSets,
+		"query": map[string]interface{}{"match_all": map[string]interface{}{}},
+	}
+	err := s.sendJSON("POST", fmt.Sprintf("%s/%s/_search", s.opensearchURL, synonymSetsIndex), query, &result)
+	if errors.Is(err, errNotFound) {
+		return []SynonymSet{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list synonym sets: %w", err)
+	}
+
+	sets := make([]SynonymSet, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		sets = append(sets, hit.Source)
+	}
+	return sets, nil
+}
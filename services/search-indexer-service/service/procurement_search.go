@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrProcurementSearchForbidden is returned when a guest, or a user outside
+// any tenant, searches procurement documents
+var ErrProcurementSearchForbidden = errors.New("procurement documents are only searchable by users of a tenant")
+
+// procurementIndices are the indices of procurement documents, by the type
+// of search, and of result, they are found by
+var procurementIndices = map[string]string{
+	"rfq":      "rfqs",
+	"quote":    "quotes",
+	"order":    "orders",
+	"shipment": "shipments",
+}
+
+// procurementLinks are the API paths procurement results link to, by type
+var procurementLinks = map[string]string{
+	"rfq":      "/api/v1/rfqs/",
+	"quote":    "/api/v1/quotes/",
+	"order":    "/api/v1/purchase-orders/",
+	"shipment": "/api/v1/shipments/",
+}
+
+// IsProcurementSearch reports whether a search type looks for procurement
+// documents: one kind of them, or all with "procurement"
+func IsProcurementSearch(searchType string) bool {
+	_, ok := procurementIndices[searchType]
+	return ok || searchType == "procurement"
+}
+
+// procurementSearchIndices returns the procurement indices a search type covers
+func procurementSearchIndices(searchType string) []string {
+	if index, ok := procurementIndices[searchType]; ok {
+		return []string{index}
+	}
+	return []string{"rfqs", "quotes", "orders", "shipments"}
+}
+
+// checkProcurementAccess refuses procurement searches by anyone a
+// procurementFilter could not scope to a tenant
+func (s *SearchService) checkProcurementAccess(isGuest bool, tenantID string, roles []string) error {
+	if s.hasRole(roles, "super_admin") {
+		return nil
+	}
+	if isGuest || tenantID == "" {
+		return ErrProcurementSearchForbidden
+	}
+	return nil
+}
+
+// procurementFilter limits a search to the procurement documents of the
+// user's tenant. Suppliers also see the quotes they submitted and the
+// orders placed with them, which belong to the buyer's tenant. Unlike the
+// catalog, tenant admins see no other tenant's documents.
+func (s *SearchService) procurementFilter(tenantID string, roles []string) Filter {
+	if s.hasRole(roles, "super_admin") {
+		return Filter{}
+	}
+
+	visible := []Filter{Term("tenant_id", tenantID)}
+	if s.hasRole(roles, "supplier") {
+		visible = append(visible, Term("supplier_id", tenantID))
+	}
+	return AnyOf(visible...)
+}
+
+// procurementTitle names a procurement document by its number and title,
+// e.g. "PO-1710412345 Pump seals for line 3"
+func procurementTitle(source map[string]interface{}) string {
+	number, _ := source["number"].(string)
+	title, _ := source["title"].(string)
+	return strings.TrimSpace(number + " " + title)
+}
+
+// documentLink is the API path of a result's document, for the procurement
+// documents search results link to
+func documentLink(resultType, id string) string {
+	path, ok := procurementLinks[resultType]
+	if !ok || id == "" {
+		return ""
+	}
+	return path + id
+}
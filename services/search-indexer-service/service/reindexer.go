@@ -0,0 +1,496 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b2b-platform/shared/events"
+	"github.com/google/uuid"
+)
+
+// Reindex job and index statuses
+const (
+	ReindexPending   = "pending"
+	ReindexRunning   = "running"
+	ReindexCompleted = "completed"
+	ReindexFailed    = "failed"
+)
+
+// reindexPageSize is how many entities are read from a source, and written
+// to OpenSearch, at a time
+const reindexPageSize = 500
+
+var (
+	ErrUnknownIndex       = errors.New("unknown index")
+	ErrNotTenantScoped    = errors.New("index is not split by tenant")
+	ErrReindexInProgress  = errors.New("index is already being reindexed")
+	ErrReindexJobNotFound = errors.New("reindex job not found")
+)
+
+// ReindexJob rebuilds search indices from the services that own their data.
+// A full reindex loads each index into a new version and swaps its alias;
+// a tenant reindex refreshes one tenant's documents in place.
+type ReindexJob struct {
+	ID         uuid.UUID        `json:"id"`
+	TenantID   *uuid.UUID       `json:"tenant_id,omitempty"`
+	Status     string           `json:"status"` // running, completed, failed
+	Indices    []*IndexProgress `json:"indices"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// IndexProgress counts what a reindex has done to one index
+type IndexProgress struct {
+	Alias        string `json:"alias"`
+	Index        string `json:"index,omitempty"` // Versioned index being built by a full reindex
+	Status       string `json:"status"`          // pending, running, completed, failed
+	Fetched      int    `json:"fetched"`
+	Indexed      int    `json:"indexed"`
+	Unsearchable int    `json:"unsearchable"` // Entities search does not keep, such as unapproved parts
+	Stale        int    `json:"stale"`        // Older than what a live event had already indexed
+	Deleted      int    `json:"deleted"`      // Tenant documents no longer at their source
+	Failed       int    `json:"failed"`
+	Error        string `json:"error,omitempty"`
+}
+
+func (j *ReindexJob) clone() *ReindexJob {
+	copied := *j
+	copied.Indices = make([]*IndexProgress, len(j.Indices))
+	for i, progress := range j.Indices {
+		p := *progress
+		copied.Indices[i] = &p
+	}
+	return &copied
+}
+
+// Reindexer runs reindex jobs and keeps their progress
+type Reindexer struct {
+	backend *OpenSearchBackend
+	client  *SnapshotClient
+	sources []SnapshotSource
+
+	mu     sync.Mutex
+	jobs   map[uuid.UUID]*ReindexJob
+	active map[string]bool // Indices a job is working on
+}
+
+func NewReindexer(backend *OpenSearchBackend) *Reindexer {
+	return &Reindexer{
+		backend: backend,
+		client:  NewSnapshotClient(),
+		sources: DefaultSnapshotSources(),
+		jobs:    make(map[uuid.UUID]*ReindexJob),
+		active:  make(map[string]bool),
+	}
+}
+
+// Start reindexes the given indices, all of them when none are given, in
+// the background. With a tenant only that tenant's documents are reindexed,
+// in the indices split by tenant. authToken must let the reindex read every
+// source's snapshot.
+func (r *Reindexer) Start(indices []string, tenantID *uuid.UUID, authToken string) (*ReindexJob, error) {
+	sources, err := r.selectSources(indices, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	for _, source := range sources {
+		if r.active[source.Index] {
+			r.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrReindexInProgress, source.Index)
+		}
+	}
+
+	job := &ReindexJob{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Status:    ReindexRunning,
+		StartedAt: time.Now(),
+	}
+	for _, source := range sources {
+		job.Indices = append(job.Indices, &IndexProgress{Alias: source.Index, Status: ReindexPending})
+		r.active[source.Index] = true
+	}
+	r.jobs[job.ID] = job
+	started := job.clone()
+	r.mu.Unlock()
+
+	go r.run(job, sources, authToken)
+	return started, nil
+}
+
+func (r *Reindexer) GetJob(id uuid.UUID) (*ReindexJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrReindexJobNotFound
+	}
+	return job.clone(), nil
+}
+
+// ListJobs returns the jobs run since the indexer started, newest first
+func (r *Reindexer) ListJobs() []*ReindexJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs := make([]*ReindexJob, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		jobs = append(jobs, job.clone())
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].StartedAt.After(jobs[j].StartedAt)
+	})
+	return jobs
+}
+
+func (r *Reindexer) selectSources(indices []string, tenantID *uuid.UUID) ([]SnapshotSource, error) {
+	if len(indices) == 0 {
+		sources := []SnapshotSource{}
+		for _, source := range r.sources {
+			if tenantID == nil || source.TenantField != "" {
+				sources = append(sources, source)
+			}
+		}
+		return sources, nil
+	}
+
+	sources := make([]SnapshotSource, 0, len(indices))
+	seen := make(map[string]bool)
+	for _, index := range indices {
+		if seen[index] {
+			continue
+		}
+		seen[index] = true
+
+		var found *SnapshotSource
+		for i := range r.sources {
+			if r.sources[i].Index == index {
+				found = &r.sources[i]
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownIndex, index)
+		}
+		if tenantID != nil && found.TenantField == "" {
+			return nil, fmt.Errorf("%w: %s", ErrNotTenantScoped, index)
+		}
+		sources = append(sources, *found)
+	}
+	return sources, nil
+}
+
+func (r *Reindexer) run(job *ReindexJob, sources []SnapshotSource, authToken string) {
+	failed := []string{}
+	for i, source := range sources {
+		progress := job.Indices[i]
+		r.record(progress, func(p *IndexProgress) { p.Status = ReindexRunning })
+
+		var err error
+		if job.TenantID != nil {
+			err = r.reindexTenant(source, progress, *job.TenantID, job.ID, authToken)
+		} else {
+			err = r.rebuild(source, progress, authToken)
+		}
+
+		r.mu.Lock()
+		if err != nil {
+			progress.Status = ReindexFailed
+			progress.Error = err.Error()
+			failed = append(failed, source.Index)
+		} else {
+			progress.Status = ReindexCompleted
+		}
+		delete(r.active, source.Index)
+		r.mu.Unlock()
+
+		if err != nil {
+			log.Printf("Reindex %s of %s failed: %v", job.ID, source.Index, err)
+		} else {
+			log.Printf("Reindex %s of %s completed", job.ID, source.Index)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	job.FinishedAt = &now
+	if len(failed) > 0 {
+		job.Status = ReindexFailed
+		job.Error = fmt.Sprintf("failed to reindex %s", strings.Join(failed, ", "))
+	} else {
+		job.Status = ReindexCompleted
+	}
+}
+
+// rebuild loads a source into the next version of its index and swaps the
+// index's alias onto it. Live events keep reaching the new index through
+// its rebuild alias while it loads, and versions keep the newer of a live
+// event and the snapshot.
+func (r *Reindexer) rebuild(source SnapshotSource, progress *IndexProgress, authToken string) error {
+	// The rebuild alias also keeps two processes from building one index
+	building, _, err := r.backend.aliasIndices(rebuildAlias(source.Index))
+	if err != nil {
+		return err
+	}
+	if len(building) > 0 {
+		return fmt.Errorf("%w: %s is being built; delete it if that reindex was abandoned", ErrReindexInProgress, strings.Join(building, ", "))
+	}
+
+	target, err := r.backend.nextIndexVersion(source.Index)
+	if err != nil {
+		return err
+	}
+	if err := r.backend.createIndex(target, source.Index, rebuildAlias(source.Index)); err != nil {
+		return fmt.Errorf("failed to create index %s: %w", target, err)
+	}
+	r.record(progress, func(p *IndexProgress) { p.Index = target })
+
+	// Let live writers notice the rebuild, so no change made while loading is missed
+	time.Sleep(rebuildCheckInterval + time.Second)
+
+	failed, err := r.load(source, progress, target, nil, "", authToken)
+	if err == nil && failed > 0 {
+		err = fmt.Errorf("%d documents failed to index", failed)
+	}
+	if err == nil {
+		err = r.backend.refreshIndex(target)
+	}
+	if err != nil {
+		if dropErr := r.backend.deleteIndex(target); dropErr != nil {
+			log.Printf("Failed to drop abandoned index %s: %v", target, dropErr)
+		}
+		return err
+	}
+
+	previous, err := r.backend.swapAlias(source.Index, target)
+	if err != nil {
+		return fmt.Errorf("failed to swap %s onto %s: %w", source.Index, target, err)
+	}
+	for _, old := range previous {
+		if old == target {
+			continue
+		}
+		if err := r.backend.deleteIndex(old); err != nil {
+			log.Printf("Failed to drop replaced index %s: %v", old, err)
+		}
+	}
+	return nil
+}
+
+// reindexTenant rewrites one tenant's documents in the live index from its
+// source, marking each with the job, and deletes the tenant's documents the
+// source no longer has: those neither marked nor written live since it started
+func (r *Reindexer) reindexTenant(source SnapshotSource, progress *IndexProgress, tenantID, jobID uuid.UUID, authToken string) error {
+	building, err := r.backend.exists(rebuildAlias(source.Index))
+	if err != nil {
+		return err
+	}
+	if building {
+		return fmt.Errorf("%w: %s is being rebuilt", ErrReindexInProgress, source.Index)
+	}
+
+	if err := r.backend.createIndexIfNotExists(source.Index); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	if err := r.backend.putReindexFields(source.Index); err != nil {
+		return fmt.Errorf("failed to map reindex fields: %w", err)
+	}
+
+	// Documents written from here on, by the load or by live events, are
+	// current. Both are stamped on the indexer's clock, never the source's.
+	startedAt := time.Now()
+
+	failed, err := r.load(source, progress, source.Index, &tenantID, jobID.String(), authToken)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		// Missing documents may be ones that failed, so nothing is deleted
+		return fmt.Errorf("%d documents failed to index", failed)
+	}
+
+	if err := r.backend.refreshIndex(source.Index); err != nil {
+		return err
+	}
+	deleted, err := r.backend.deleteUnreindexedTenantDocuments(source.Index, source.TenantField, tenantID.String(), jobID.String(), startedAt)
+	if err != nil {
+		return err
+	}
+	r.record(progress, func(p *IndexProgress) { p.Deleted = deleted })
+	return nil
+}
+
+// load pages through a source and bulk writes each page to target, marking
+// the documents with reindexJob when set. It returns how many documents
+// failed to write.
+func (r *Reindexer) load(source SnapshotSource, progress *IndexProgress, target string, tenantID *uuid.UUID, reindexJob string, authToken string) (int, error) {
+	failed := 0
+	after := ""
+	for {
+		page, err := r.client.FetchPage(source, authToken, after, tenantID, reindexPageSize)
+		if err != nil {
+			return failed, err
+		}
+
+		// A tenant reindex writes to a live index, where unsearchable entities may still be
+		result, err := r.backend.bulkWrite(target, source.build, page.Events, tenantID != nil, reindexJob)
+		if err != nil {
+			return failed, err
+		}
+		failed += result.Failed
+
+		fetched := 0
+		r.record(progress, func(p *IndexProgress) {
+			p.Fetched += len(page.Events)
+			fetched = p.Fetched
+			p.Indexed += result.Indexed
+			p.Unsearchable += result.Unsearchable
+			p.Stale += result.Stale
+			p.Failed += result.Failed
+			if p.Error == "" {
+				p.Error = result.FirstError
+			}
+		})
+		log.Printf("Reindexing %s into %s: %d fetched", source.Index, target, fetched)
+
+		if page.NextAfter == "" {
+			return failed, nil
+		}
+		after = page.NextAfter
+	}
+}
+
+func (r *Reindexer) record(progress *IndexProgress, update func(p *IndexProgress)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	update(progress)
+}
+
+// bulkResult counts the outcome of a bulk write
+type bulkResult struct {
+	Indexed      int
+	Unsearchable int
+	Stale        int
+	Failed       int
+	FirstError   string
+}
+
+// bulkWrite writes the documents for a batch of events in one request,
+// versioned like live writes. Entities search does not keep are deleted
+// when deleteUnsearchable is set, and otherwise left out. Documents are
+// marked with reindexJob when set.
+func (s *OpenSearchBackend) bulkWrite(target string, build documentBuilder, batch []*events.EventEnvelope, deleteUnsearchable bool, reindexJob string) (*bulkResult, error) {
+	result := &bulkResult{}
+
+	var body bytes.Buffer
+	actions := []bulkAction{}
+	for _, event := range batch {
+		documentID, document, err := build(event)
+		if err != nil {
+			result.Failed++
+			if result.FirstError == "" {
+				result.FirstError = err.Error()
+			}
+			continue
+		}
+		if document == nil && !deleteUnsearchable {
+			result.Unsearchable++
+			continue
+		}
+
+		op := "index"
+		if document == nil {
+			op = "delete"
+		} else {
+			document = withIndexedAt(document)
+			if reindexJob != "" {
+				document["reindex_job"] = reindexJob
+			}
+		}
+		action, err := newBulkAction(op, target, documentID, event.Version, false, document)
+		if err != nil {
+			return nil, err
+		}
+		body.Write(action.encoded)
+		actions = append(actions, action)
+	}
+	if len(actions) == 0 {
+		return result, nil
+	}
+
+	items, err := s.sendBulk(body.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range items {
+		if i >= len(actions) {
+			break
+		}
+		switch {
+		case item.Status == http.StatusConflict:
+			result.Stale++
+		case actions[i].op == "delete" && actions[i].accepted(item.Status):
+			result.Unsearchable++
+		case item.Status < 300:
+			result.Indexed++
+		default:
+			result.Failed++
+			if result.FirstError == "" {
+				result.FirstError = item.Error
+			}
+		}
+	}
+	return result, nil
+}
+
+// putReindexFields maps the fields a tenant reindex relies on in an index
+// created before they were part of its mapping
+func (s *OpenSearchBackend) putReindexFields(indexName string) error {
+	mapping := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"indexed_at":  map[string]interface{}{"type": "date"},
+			"reindex_job": map[string]interface{}{"type": "keyword"},
+		},
+	}
+	return s.sendJSON("PUT", fmt.Sprintf("%s/%s/_mapping", s.opensearchURL, indexName), mapping, nil)
+}
+
+// deleteUnreindexedTenantDocuments removes a tenant's documents that a
+// reindex job neither wrote nor saw written live since it started,
+// returning how many were deleted
+func (s *OpenSearchBackend) deleteUnreindexedTenantDocuments(indexName, tenantField, tenantID, reindexJob string, startedAt time.Time) (int, error) {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []map[string]interface{}{
+					{"term": map[string]interface{}{tenantField: tenantID}},
+				},
+				"must_not": []map[string]interface{}{
+					{"term": map[string]interface{}{"reindex_job": reindexJob}},
+					{"range": map[string]interface{}{"indexed_at": map[string]interface{}{"gte": startedAt.UTC().Format(time.RFC3339Nano)}}},
+				},
+			},
+		},
+	}
+
+	var result struct {
+		Deleted int `json:"deleted"`
+	}
+	url := fmt.Sprintf("%s/%s/_delete_by_query?conflicts=proceed&refresh=true", s.opensearchURL, indexName)
+	if err := s.sendJSON("POST", url, query, &result); err != nil {
+		return 0, err
+	}
+	return result.Deleted, nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// RelevanceSuite is a fixed set of documents, and of queries with the
+// documents each should find, graded 1 (related) to 3 (exactly what was
+// asked for). Scoring it before and after a relevance change shows what the
+// change did to results.
+type RelevanceSuite struct {
+	K         int                                 `json:"k"`          // Results scored per query
+	MinNDCG   float64                             `json:"min_ndcg"`   // Mean nDCG the suite must reach
+	MinRecall float64                             `json:"min_recall"` // Mean recall the suite must reach
+	Synonyms  []RelevanceSynonyms                 `json:"synonyms"`
+	Documents map[string][]map[string]interface{} `json:"documents"` // Index -> documents, each with an id
+	Cases     []RelevanceCase                     `json:"cases"`
+}
+
+// RelevanceSynonyms is a synonym set searched with, global without a tenant
+type RelevanceSynonyms struct {
+	TenantID string   `json:"tenant_id"`
+	Name     string   `json:"name"`
+	Rules    []string `json:"rules"`
+}
+
+// RelevanceCase is a query and the documents it should find
+type RelevanceCase struct {
+	Name     string         `json:"name"`
+	Query    string         `json:"query"`
+	Type     string         `json:"type"`
+	TenantID string         `json:"tenant_id"`
+	Relevant map[string]int `json:"relevant"` // Document ID -> grade
+}
+
+// RelevanceScore is how well a query's results did
+type RelevanceScore struct {
+	Name           string
+	Query          string
+	NDCG           float64 // Discounted gain of the results against the best possible order
+	ReciprocalRank float64 // 1/rank of the first relevant result, 0 when none is found
+	Recall         float64 // Share of relevant documents found
+	Results        []string
+	Missing        []string // Relevant documents not found
+}
+
+// RelevanceReport scores a suite
+type RelevanceReport struct {
+	Cases      []RelevanceScore
+	MeanNDCG   float64
+	MRR        float64
+	MeanRecall float64
+}
+
+// Passed reports whether the suite reached its thresholds
+func (r *RelevanceReport) Passed(suite *RelevanceSuite) bool {
+	return r.MeanNDCG >= suite.MinNDCG && r.MeanRecall >= suite.MinRecall
+}
+
+func LoadRelevanceSuite(path string) (*RelevanceSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read relevance suite: %w", err)
+	}
+
+	var suite RelevanceSuite
+	if err := json.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("invalid relevance suite: %w", err)
+	}
+	if suite.K <= 0 {
+		suite.K = 10
+	}
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("invalid relevance suite: no cases")
+	}
+	return &suite, nil
+}
+
+// RunRelevanceSuite searches the suite's documents with an embedded backend,
+// as an admin who sees every document, and scores each query's results
+func RunRelevanceSuite(suite *RelevanceSuite, fuzziness string) (*RelevanceReport, error) {
+	backend := NewEmbeddedBackend()
+	for index, documents := range suite.Documents {
+		for _, document := range documents {
+			id, _ := document["id"].(string)
+			if id == "" {
+				return nil, fmt.Errorf("invalid relevance suite: %s document without id", index)
+			}
+			if err := backend.IndexDocument(index, id, document, 0); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	synonyms := NewSynonymService(backend)
+	for _, set := range suite.Synonyms {
+		input := SynonymSetInput{Name: set.Name, Rules: set.Rules, Global: set.TenantID == ""}
+		if _, err := synonyms.Create(set.TenantID, true, input); err != nil {
+			return nil, fmt.Errorf("synonym set %s: %w", set.Name, err)
+		}
+	}
+
+	searchService := NewSearchService(backend)
+	searchService.UseSynonyms(synonyms)
+	if err := searchService.SetFuzziness(fuzziness); err != nil {
+		return nil, err
+	}
+
+	report := &RelevanceReport{}
+	for _, c := range suite.Cases {
+		searchType := c.Type
+		if searchType == "" {
+			searchType = "all"
+		}
+		results, _, _, err := searchService.Search(c.Query, searchType, 1, suite.K, "relevance",
+			nil, nil, nil, Period{}, false, c.TenantID, []string{"admin"})
+		if err != nil {
+			return nil, fmt.Errorf("case %s: %w", c.Name, err)
+		}
+
+		ids := make([]string, 0, len(results))
+		for _, result := range results {
+			ids = append(ids, result.ID)
+		}
+		report.Cases = append(report.Cases, scoreResults(c, ids, suite.K))
+	}
+
+	for _, score := range report.Cases {
+		report.MeanNDCG += score.NDCG
+		report.MRR += score.ReciprocalRank
+		report.MeanRecall += score.Recall
+	}
+	cases := float64(len(report.Cases))
+	report.MeanNDCG /= cases
+	report.MRR /= cases
+	report.MeanRecall /= cases
+	return report, nil
+}
+
+func scoreResults(c RelevanceCase, results []string, k int) RelevanceScore {
+	score := RelevanceScore{Name: c.Name, Query: c.Query, Results: results}
+
+	found := make(map[string]bool, len(results))
+	dcg := 0.0
+	for rank, id := range results {
+		found[id] = true
+		grade := c.Relevant[id]
+		if grade <= 0 {
+			continue
+		}
+		dcg += gain(grade, rank)
+		if score.ReciprocalRank == 0 {
+			score.ReciprocalRank = 1 / float64(rank+1)
+		}
+	}
+
+	// The best order puts the highest grades first
+	grades := make([]int, 0, len(c.Relevant))
+	for id, grade := range c.Relevant {
+		grades = append(grades, grade)
+		if !found[id] {
+			score.Missing = append(score.Missing, id)
+		}
+	}
+	sort.Strings(score.Missing)
+	sort.Sort(sort.Reverse(sort.IntSlice(grades)))
+	ideal := 0.0
+	for rank, grade := range grades {
+		if rank == k {
+			break
+		}
+		ideal += gain(grade, rank)
+	}
+
+	if ideal > 0 {
+		score.NDCG = dcg / ideal
+	} else if len(results) == 0 {
+		// Nothing should be found, and nothing was
+		score.NDCG = 1
+	}
+	if len(c.Relevant) > 0 {
+		score.Recall = float64(len(c.Relevant)-len(score.Missing)) / float64(len(c.Relevant))
+	} else {
+		score.Recall = 1
+	}
+	return score
+}
+
+func gain(grade, rank int) float64 {
+	return (math.Pow(2, float64(grade)) - 1) / math.Log2(float64(rank)+2)
+}
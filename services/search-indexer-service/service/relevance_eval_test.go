@@ -0,0 +1,28 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRelevanceSuite(t *testing.T) {
+	suite, err := LoadRelevanceSuite("../testdata/relevance/suite.json")
+	if err != nil {
+		t.Fatalf("failed to load suite: %v", err)
+	}
+
+	report, err := RunRelevanceSuite(suite, FuzzinessAuto)
+	if err != nil {
+		t.Fatalf("failed to score relevance: %v", err)
+	}
+
+	if !report.Passed(suite) {
+		for _, score := range report.Cases {
+			if len(score.Missing) > 0 {
+				t.Logf("%s: ndcg %.3f, missing %s", score.Name, score.NDCG, strings.Join(score.Missing, ", "))
+			}
+		}
+		t.Errorf("mean ndcg %.3f (min %.3f), mean recall %.3f (min %.3f)",
+			report.MeanNDCG, suite.MinNDCG, report.MeanRecall, suite.MinRecall)
+	}
+}
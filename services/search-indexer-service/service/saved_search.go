@@ -0,0 +1,213 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+	ErrInvalidSavedSearch  = errors.New("invalid saved search")
+	ErrSavedSearchLimit    = errors.New("saved search limit reached")
+)
+
+// maxSavedSearches is how many searches one user may save
+const maxSavedSearches = 50
+
+// savedSearchesIndex holds saved searches, as percolator queries on OpenSearch
+const savedSearchesIndex = "saved_searches"
+
+// SavedSearch is a search a user can run again, and be alerted of new
+// matches for
+type SavedSearch struct {
+	ID       string `json:"id"`
+	UserID   string `json:"user_id"`
+	TenantID string `json:"tenant_id"`
+	// Roles are the user's when the search was saved, which decide the
+	// documents it may match
+	Roles      []string          `json:"roles,omitempty"`
+	Name       string            `json:"name"`
+	Query      string            `json:"query"`
+	Type       string            `json:"type"`
+	Filters    map[string]string `json:"filters,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"` // Attribute code -> filter, as attr.<code> is searched
+	Alerts     bool              `json:"alerts"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// SavedSearchStore keeps saved searches and finds those a document matches
+type SavedSearchStore interface {
+	// SaveSearch stores a search, along with the query that decides its matches
+	SaveSearch(search *SavedSearch, query SearchQuery) error
+	GetSearch(id string) (*SavedSearch, error)
+	ListSearches(userID string) ([]SavedSearch, error)
+	DeleteSearch(id string) error
+	// MatchSearches returns the searches with alerts on that a document of
+	// the index matches
+	MatchSearches(index string, document map[string]interface{}) ([]SavedSearch, error)
+}
+
+// SavedSearchInput is a search to save, as /search takes it
+type SavedSearchInput struct {
+	Name       string            `json:"name" binding:"required"`
+	Query      string            `json:"q" binding:"required"`
+	Type       string            `json:"type"`
+	Filters    map[string]string `json:"filters"`
+	Attributes map[string]string `json:"attributes"`
+	Alerts     bool              `json:"alerts"`
+}
+
+var searchTypes = map[string]bool{
+	"all": true, "part": true, "equipment": true, "company": true, "listing": true, "service": true,
+}
+
+type SavedSearchService struct {
+	store         SavedSearchStore
+	searchService *SearchService
+}
+
+func NewSavedSearchService(store SavedSearchStore, searchService *SearchService) *SavedSearchService {
+	return &SavedSearchService{
+		store:         store,
+		searchService: searchService,
+	}
+}
+
+func (s *SavedSearchService) Create(userID, tenantID string, roles []string, input SavedSearchInput) (*SavedSearch, error) {
+	existing, err := s.store.ListSearches(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxSavedSearches {
+		return nil, fmt.Errorf("%w: at most %d searches can be saved", ErrSavedSearchLimit, maxSavedSearches)
+	}
+
+	now := time.Now()
+	search := &SavedSearch{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: now,
+	}
+	if err := s.save(search, tenantID, roles, input, now); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// Update replaces a saved search. It is saved with the user's current roles.
+func (s *SavedSearchService) Update(id, userID, tenantID string, roles []string, input SavedSearchInput) (*SavedSearch, error) {
+	search, err := s.Get(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(search, tenantID, roles, input, time.Now()); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+func (s *SavedSearchService) save(search *SavedSearch, tenantID string, roles []string, input SavedSearchInput, now time.Time) error {
+	search.TenantID = tenantID
+	search.Roles = roles
+	search.Name = strings.TrimSpace(input.Name)
+	search.Query = strings.TrimSpace(input.Query)
+	search.Type = input.Type
+	search.Filters = input.Filters
+	search.Attributes = input.Attributes
+	search.Alerts = input.Alerts
+	search.UpdatedAt = now
+
+	if search.Type == "" {
+		search.Type = "all"
+	}
+	if search.Name == "" || search.Query == "" {
+		return fmt.Errorf("%w: name and query are required", ErrInvalidSavedSearch)
+	}
+	if !searchTypes[search.Type] {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidSavedSearch, search.Type)
+	}
+
+	query, err := s.matchQuery(search)
+	if err != nil {
+		return err
+	}
+	return s.store.SaveSearch(search, query)
+}
+
+// matchQuery is the search a saved search runs, seen as the user who saved it
+func (s *SavedSearchService) matchQuery(search *SavedSearch) (SearchQuery, error) {
+	attributes, err := search.attributeFilters()
+	if err != nil {
+		return SearchQuery{}, err
+	}
+	return s.searchService.buildSearchQuery(search.Query, search.Type, 1, 1, "relevance",
+		search.Filters, attributes, nil, Period{}, false, search.TenantID, search.Roles), nil
+}
+
+func (search *SavedSearch) attributeFilters() ([]AttributeFilter, error) {
+	filters := make([]AttributeFilter, 0, len(search.Attributes))
+	for code, value := range search.Attributes {
+		filter, err := ParseAttributeFilter(code, value)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// Get returns a user's saved search
+func (s *SavedSearchService) Get(id, userID string) (*SavedSearch, error) {
+	search, err := s.store.GetSearch(id)
+	if err != nil {
+		return nil, err
+	}
+	if search.UserID != userID {
+		return nil, ErrSavedSearchNotFound
+	}
+	return search, nil
+}
+
+// List returns a user's saved searches, most recently created first
+func (s *SavedSearchService) List(userID string) ([]SavedSearch, error) {
+	searches, err := s.store.ListSearches(userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(searches, func(i, j int) bool {
+		return searches[i].CreatedAt.After(searches[j].CreatedAt)
+	})
+	return searches, nil
+}
+
+func (s *SavedSearchService) Delete(id, userID string) error {
+	if _, err := s.Get(id, userID); err != nil {
+		return err
+	}
+	return s.store.DeleteSearch(id)
+}
+
+// Run runs a saved search as the user now sees the index
+func (s *SavedSearchService) Run(id, userID, tenantID string, roles []string, page, pageSize int, sortBy string) (*SavedSearch, []SearchResult, int64, map[string]interface{}, error) {
+	search, err := s.Get(id, userID)
+	if err != nil {
+		return nil, nil, 0, nil, err
+	}
+	attributes, err := search.attributeFilters()
+	if err != nil {
+		return nil, nil, 0, nil, err
+	}
+
+	results, total, facets, err := s.searchService.Search(search.Query, search.Type, page, pageSize, sortBy,
+		search.Filters, attributes, nil, Period{}, false, tenantID, roles)
+	if err != nil {
+		return nil, nil, 0, nil, err
+	}
+	return search, results, total, facets, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/b2b-platform/shared/events"
+	"github.com/google/uuid"
+)
+
+// alertIndices are the indices whose new and changed documents are checked
+// against saved searches
+var alertIndices = map[string]bool{"parts": true, "listings": true}
+
+// alertRepeatInterval is how long a search is not alerted again about the
+// same document, however often it changes
+const alertRepeatInterval = 24 * time.Hour
+
+// SearchAlerter alerts users when a document written to search matches one
+// of their saved searches. The alerts are published for notification-service
+// to deliver.
+type SearchAlerter struct {
+	store         SavedSearchStore
+	searchService *SearchService
+	eventBus      events.EventBus
+
+	mu      sync.Mutex
+	alerted map[string]time.Time // Saved search and document -> when last alerted
+}
+
+func NewSearchAlerter(store SavedSearchStore, searchService *SearchService, eventBus events.EventBus) *SearchAlerter {
+	return &SearchAlerter{
+		store:         store,
+		searchService: searchService,
+		eventBus:      eventBus,
+		alerted:       make(map[string]time.Time),
+	}
+}
+
+// Check alerts the saved searches a new or changed document matches
+func (a *SearchAlerter) Check(index, documentID string, document map[string]interface{}) error {
+	if !alertIndices[index] {
+		return nil
+	}
+
+	searches, err := a.store.MatchSearches(index, document)
+	if err != nil {
+		return fmt.Errorf("failed to match saved searches: %w", err)
+	}
+
+	var firstErr error
+	for i := range searches {
+		search := &searches[i]
+		// Suppliers are not alerted about their own listings
+		if supplierID, _ := document["supplier_id"].(string); supplierID != "" && supplierID == search.TenantID {
+			continue
+		}
+		if !a.shouldAlert(search.ID, index, documentID) {
+			continue
+		}
+
+		if err := a.eventBus.Publish(nil, a.matchEvent(search, index, documentID, document)); err != nil {
+			a.forget(search.ID, index, documentID)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to publish alert for saved search %s: %w", search.ID, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// shouldAlert records an alert about a document, reporting false when the
+// search was alerted about it recently
+func (a *SearchAlerter) shouldAlert(searchID, index, documentID string) bool {
+	key := searchID + "/" + index + "/" + documentID
+	now := time.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if alertedAt, ok := a.alerted[key]; ok && now.Sub(alertedAt) < alertRepeatInterval {
+		return false
+	}
+	for k, alertedAt := range a.alerted {
+		if now.Sub(alertedAt) >= alertRepeatInterval {
+			delete(a.alerted, k)
+		}
+	}
+	a.alerted[key] = now
+	return true
+}
+
+func (a *SearchAlerter) forget(searchID, index, documentID string) {
+	a.mu.Lock()
+	delete(a.alerted, searchID+"/"+index+"/"+documentID)
+	a.mu.Unlock()
+}
+
+func (a *SearchAlerter) matchEvent(search *SavedSearch, index, documentID string, document map[string]interface{}) *events.EventEnvelope {
+	resultType := a.searchService.getTypeFromIndex(index)
+	match := map[string]interface{}{
+		"type":        resultType,
+		"id":          documentID,
+		"title":       a.searchService.extractTitle(document, resultType),
+		"description": a.searchService.extractDescription(document, resultType),
+	}
+	for _, field := range []string{"part_number", "sku", "manufacturer", "brand", "supplier_id", "price", "currency", "stock"} {
+		if value, ok := document[field]; ok {
+			match[field] = value
+		}
+	}
+
+	event := events.NewEventEnvelope(events.EventSavedSearchMatched, "search-indexer-service", map[string]interface{}{
+		"saved_search_id":   search.ID,
+		"saved_search_name": search.Name,
+		"user_id":           search.UserID,
+		"query":             search.Query,
+		"match":             match,
+	})
+	if tenantID, err := uuid.Parse(search.TenantID); err == nil {
+		event.WithTenantID(tenantID)
+	}
+	if userID, err := uuid.Parse(search.UserID); err == nil {
+		event.WithUserID(userID)
+	}
+	return event
+}
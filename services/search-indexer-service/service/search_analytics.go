@@ -0,0 +1,277 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidClick = errors.New("invalid search click")
+
+// Searches and clicks on their results are kept as documents of these indices
+const (
+	searchLogsIndex   = "search_logs"
+	searchClicksIndex = "search_clicks"
+)
+
+// analyticsQueueSize is how many records wait to be written before more are
+// dropped. Searches never wait on analytics.
+const analyticsQueueSize = 5000
+
+// maxReportPositions is how many result positions click-through is reported for
+const maxReportPositions = 20
+
+// Popular queries seed autocomplete once they found results for enough
+// tenants, so one tenant's searches are never suggested to another
+const (
+	popularQueryWindow     = 30 * 24 * time.Hour
+	popularQueryMinTenants = 3
+)
+
+// SearchLogEntry records one search
+type SearchLogEntry struct {
+	ID              string            `json:"id"`
+	Timestamp       time.Time         `json:"timestamp"`
+	Query           string            `json:"query"`
+	NormalizedQuery string            `json:"normalized_query"` // Lowercase, single spaced; queries are counted by it
+	Type            string            `json:"type"`
+	Filters         map[string]string `json:"filters,omitempty"`
+	Attributes      map[string]string `json:"attributes,omitempty"`
+	Sort            string            `json:"sort"`
+	ResultCount     int64             `json:"result_count"`
+	LatencyMS       float64           `json:"latency_ms"`
+	Page            int               `json:"page"`
+	PageSize        int               `json:"page_size"`
+	// Positions of the results shown, from 1 for the top result. Both are 0
+	// when nothing was shown.
+	FirstPosition int    `json:"first_position"`
+	LastPosition  int    `json:"last_position"`
+	TenantID      string `json:"tenant_id,omitempty"` // Guests search without one
+	UserID        string `json:"user_id,omitempty"`
+}
+
+// SearchClick records a click on a search result
+type SearchClick struct {
+	ID         string    `json:"id"`
+	SearchID   string    `json:"search_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	ResultID   string    `json:"result_id"`
+	ResultType string    `json:"result_type"`
+	Position   int       `json:"position"` // From 1 for the top result
+	TenantID   string    `json:"tenant_id,omitempty"`
+	UserID     string    `json:"user_id,omitempty"`
+}
+
+// AnalyticsQuery selects the searches reported on
+type AnalyticsQuery struct {
+	From     time.Time
+	To       time.Time
+	TenantID string // Only this tenant's searches, when set
+	Size     int    // Queries listed per report
+}
+
+// AnalyticsReport summarizes searches and clicks over a period
+type AnalyticsReport struct {
+	From               time.Time       `json:"from"`
+	To                 time.Time       `json:"to"`
+	Searches           int64           `json:"searches"`
+	ZeroResultSearches int64           `json:"zero_result_searches"`
+	ZeroResultRate     float64         `json:"zero_result_rate"`
+	AverageLatencyMS   float64         `json:"average_latency_ms"`
+	Clicks             int64           `json:"clicks"`
+	ClickedSearches    int64           `json:"clicked_searches"`
+	ClickThroughRate   float64         `json:"click_through_rate"` // Share of searches with a click
+	TopQueries         []QueryStats    `json:"top_queries"`
+	ZeroResultQueries  []QueryStats    `json:"zero_result_queries"` // What users look for and the catalog lacks
+	Positions          []PositionStats `json:"positions"`
+}
+
+// QueryStats counts the searches for one query
+type QueryStats struct {
+	Query          string    `json:"query"`
+	Searches       int64     `json:"searches"`
+	AverageResults float64   `json:"average_results"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+// PositionStats is click-through at one result position
+type PositionStats struct {
+	Position         int     `json:"position"`
+	Impressions      int64   `json:"impressions"` // Searches that showed a result there
+	Clicks           int64   `json:"clicks"`
+	ClickThroughRate float64 `json:"click_through_rate"`
+}
+
+// SearchAnalyticsStore reports on recorded searches and clicks
+type SearchAnalyticsStore interface {
+	// SearchAnalytics counts the searches and clicks of a period. Rates are
+	// left for the caller to work out.
+	SearchAnalytics(query AnalyticsQuery) (*AnalyticsReport, error)
+	// PopularQueries returns the queries starting with prefix most searched
+	// since a time, among those that found results for enough tenants
+	PopularQueries(prefix string, since time.Time, minTenants, size int) ([]string, error)
+}
+
+// normalizeQuery reduces a query to how it is counted: lowercase and single spaced
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+type analyticsRecord struct {
+	index    string
+	id       string
+	document interface{}
+}
+
+// SearchAnalytics records searches and clicks in the background and reports
+// on them
+type SearchAnalytics struct {
+	backend SearchBackend
+	records chan analyticsRecord
+	stopped chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewSearchAnalytics(backend SearchBackend) *SearchAnalytics {
+	return &SearchAnalytics{
+		backend: backend,
+		records: make(chan analyticsRecord, analyticsQueueSize),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Run writes records until analytics is closed
+func (a *SearchAnalytics) Run() {
+	defer close(a.stopped)
+
+	for record := range a.records {
+		var document map[string]interface{}
+		err := convertRecord(record.document, &document)
+		if err == nil {
+			err = a.backend.IndexDocument(record.index, record.id, document, 0)
+		}
+		if err != nil {
+			log.Printf("Failed to record search analytics: %v", err)
+		}
+	}
+}
+
+// Close writes the records still queued and stops Run
+func (a *SearchAnalytics) Close() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.records)
+	}
+	a.mu.Unlock()
+	<-a.stopped
+}
+
+func (a *SearchAnalytics) enqueue(record analyticsRecord) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return
+	}
+
+	select {
+	case a.records <- record:
+	default:
+		log.Printf("Search analytics queue full, dropped a %s record", record.index)
+	}
+}
+
+// RecordSearch records a search. Its ID, when set, is the search's ID
+// returned to the client for recording clicks.
+func (a *SearchAnalytics) RecordSearch(entry SearchLogEntry) {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	entry.NormalizedQuery = normalizeQuery(entry.Query)
+
+	a.enqueue(analyticsRecord{index: searchLogsIndex, id: entry.ID, document: entry})
+}
+
+// RecordClick records a click on a result of a search
+func (a *SearchAnalytics) RecordClick(click SearchClick) error {
+	if _, err := uuid.Parse(click.SearchID); err != nil {
+		return fmt.Errorf("%w: search_id must be the id of a search", ErrInvalidClick)
+	}
+	if click.ResultID == "" || click.Position < 1 {
+		return fmt.Errorf("%w: result_id and a position from 1 are required", ErrInvalidClick)
+	}
+
+	click.ID = uuid.New().String()
+	click.Timestamp = time.Now()
+	a.enqueue(analyticsRecord{index: searchClicksIndex, id: click.ID, document: click})
+	return nil
+}
+
+// Report summarizes a period's searches, by default the last 7 days
+func (a *SearchAnalytics) Report(query AnalyticsQuery) (*AnalyticsReport, error) {
+	if query.To.IsZero() {
+		query.To = time.Now()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-7 * 24 * time.Hour)
+	}
+	if query.Size <= 0 || query.Size > 100 {
+		query.Size = 20
+	}
+
+	report, err := a.backend.SearchAnalytics(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to report search analytics: %w", err)
+	}
+	report.From, report.To = query.From, query.To
+
+	if report.Searches > 0 {
+		report.ZeroResultRate = float64(report.ZeroResultSearches) / float64(report.Searches)
+		report.ClickThroughRate = float64(report.ClickedSearches) / float64(report.Searches)
+	}
+	for i := range report.Positions {
+		if report.Positions[i].Impressions > 0 {
+			report.Positions[i].ClickThroughRate = float64(report.Positions[i].Clicks) / float64(report.Positions[i].Impressions)
+		}
+	}
+	return report, nil
+}
+
+// PopularQueries suggests queries starting as the text does. Failures only
+// mean fewer suggestions.
+func (a *SearchAnalytics) PopularQueries(prefix string, size int) []string {
+	prefix = normalizeQuery(prefix)
+	if len(prefix) < autocompleteMinPrefix {
+		return nil
+	}
+
+	queries, err := a.backend.PopularQueries(prefix, time.Now().Add(-popularQueryWindow), popularQueryMinTenants, size)
+	if err != nil {
+		log.Printf("Failed to read popular queries: %v", err)
+		return nil
+	}
+	return queries
+}
+
+// convertRecord copies a record between its struct and its document
+func convertRecord(from, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return fmt.Errorf("failed to marshal search analytics record: %w", err)
+	}
+	if err := json.Unmarshal(data, to); err != nil {
+		return fmt.Errorf("failed to unmarshal search analytics record: %w", err)
+	}
+	return nil
+}
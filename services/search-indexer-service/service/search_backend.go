@@ -0,0 +1,236 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/b2b-platform/shared/geo"
+)
+
+// SearchBackend stores search documents and answers queries over them.
+// OpenSearch is the production backend; the embedded backend keeps documents
+// in memory so search runs without an OpenSearch container, locally and in
+// tests.
+type SearchBackend interface {
+	// IndexDocument replaces a document. A non-zero version is the entity's
+	// version from its event; writes older than the indexed document are ignored.
+	IndexDocument(index, documentID string, document map[string]interface{}, version int64) error
+	// DeleteDocument removes a document, unless a newer version is indexed
+	DeleteDocument(index, documentID string, version int64) error
+	// UpdateDocument merges fields into a document, if it is indexed
+	UpdateDocument(index, documentID string, fields map[string]interface{}) error
+	Search(query SearchQuery) (*SearchHits, error)
+	Autocomplete(query AutocompleteQuery) ([]SearchHit, error)
+	SavedSearchStore
+	SynonymStore
+	SearchAnalyticsStore
+}
+
+// NewSearchBackend returns the backend named by SEARCH_BACKEND: "opensearch",
+// the default, or "embedded"
+func NewSearchBackend() SearchBackend {
+	if os.Getenv("SEARCH_BACKEND") == "embedded" {
+		return NewEmbeddedBackend()
+	}
+	return NewOpenSearchBackend()
+}
+
+// SearchQuery is a full-text search over one or more indices
+type SearchQuery struct {
+	Indices []string
+	Text    string
+	// Fields are searched for Text, with an optional boost, e.g. "name^3".
+	// Every word of Text must be in one field for a document to match.
+	Fields []string
+	// Alternatives are other wordings of Text, such as with synonyms. A
+	// document matching any of them matches.
+	Alternatives []string
+	// Fuzziness lets a word of the text match a word this many edits away:
+	// "1", "2", or "AUTO" to scale with the word's length. Empty matches exactly.
+	Fuzziness string
+	// Identifiers are fields, such as part numbers, that match when they
+	// equal the whole of Text with case, spaces and punctuation ignored.
+	// They take an optional boost too.
+	Identifiers []string
+
+	Filter Filter
+	Sort   []SortField
+	From   int
+	Size   int
+	Facets map[string]string // Facet name -> field whose values are counted across all matches
+	// AttributeFacets summarizes the part attributes of all matches
+	AttributeFacets bool
+}
+
+// Fuzziness values
+const (
+	FuzzinessAuto = "AUTO"
+	NoFuzziness   = "0"
+)
+
+// ParseFuzziness checks an edit distance setting: AUTO, 0, 1 or 2
+func ParseFuzziness(value string) (string, error) {
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "", NoFuzziness:
+		return "", nil
+	case FuzzinessAuto:
+		return FuzzinessAuto, nil
+	case "1", "2":
+		return strings.TrimSpace(value), nil
+	default:
+		return "", fmt.Errorf("invalid fuzziness %q: use AUTO, 0, 1 or 2", value)
+	}
+}
+
+// maxEdits is how many edits a word may be from another to match it
+func maxEdits(fuzziness, word string) int {
+	switch fuzziness {
+	case FuzzinessAuto:
+		// As OpenSearch scales AUTO
+		length := utf8.RuneCountInString(word)
+		if length <= 2 {
+			return 0
+		}
+		if length <= 5 {
+			return 1
+		}
+		return 2
+	case "1":
+		return 1
+	case "2":
+		return 2
+	default:
+		return 0
+	}
+}
+
+// normalizeIdentifier keeps the letters and digits of a value, lowercased,
+// as the identifier analyzer does
+func normalizeIdentifier(value string) string {
+	var normalized strings.Builder
+	for _, r := range value {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			normalized.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return normalized.String()
+}
+
+// AutocompleteQuery finds documents with words starting as the text does
+type AutocompleteQuery struct {
+	Indices []string
+	Text    string
+	Fields  []string // Matched on word prefixes, with an optional boost
+	Size    int
+}
+
+// SortField orders results by a field, or by relevance with ScoreField. With
+// Near, a geo-point field is ordered by its distance from that point.
+type SortField struct {
+	Field string
+	Desc  bool
+	Near  *geo.Point
+}
+
+const ScoreField = "_score"
+
+// Filter is a condition on documents. A term filter holds when Field has
+// Value, or holds it among a list of values; a range filter when Field has a
+// number between Min and Max, either of which may be open. All holds when
+// every filter in it does, Any when at least one does. With Nested, All must
+// hold for a single object of that list of objects, whose fields are named
+// with the list's, e.g. "attributes.code". A distance filter holds when Field
+// is a geo-point within WithinKm of Near. The zero Filter matches everything.
+type Filter struct {
+	Field    string
+	Value    interface{}
+	Min      *float64
+	Max      *float64
+	Near     *geo.Point
+	WithinKm float64
+	Since    *time.Time
+	Until    *time.Time
+	All      []Filter
+	Any      []Filter
+	Nested   string
+}
+
+func Term(field string, value interface{}) Filter {
+	return Filter{Field: field, Value: value}
+}
+
+func Between(field string, min, max *float64) Filter {
+	return Filter{Field: field, Min: min, Max: max}
+}
+
+// WithinDistance matches documents whose geo-point field is within km of a point
+func WithinDistance(field string, near geo.Point, km float64) Filter {
+	return Filter{Field: field, Near: &near, WithinKm: km}
+}
+
+// During matches documents whose date field falls in a period; either end
+// may be open
+func During(field string, since, until *time.Time) Filter {
+	return Filter{Field: field, Since: since, Until: until}
+}
+
+func (f Filter) isPeriod() bool {
+	return f.Since != nil || f.Until != nil
+}
+
+func (f Filter) isRange() bool {
+	return f.Min != nil || f.Max != nil
+}
+
+func NestedAll(path string, filters ...Filter) Filter {
+	return Filter{Nested: path, All: filters}
+}
+
+func AllOf(filters ...Filter) Filter {
+	return Filter{All: filters}
+}
+
+func AnyOf(filters ...Filter) Filter {
+	return Filter{Any: filters}
+}
+
+// SearchHits are the results of a search
+type SearchHits struct {
+	Total      int64
+	Hits       []SearchHit
+	Facets     map[string][]FacetBucket
+	Attributes []AttributeFacet
+}
+
+// SearchHit is a matching document
+type SearchHit struct {
+	Index  string // The index searched, or a versioned index behind it
+	ID     string
+	Score  float64
+	Source map[string]interface{}
+}
+
+// FacetBucket counts the matches holding one value of a faceted field
+type FacetBucket struct {
+	Key      interface{} `json:"key"`
+	DocCount int64       `json:"doc_count"`
+}
+
+// attributeFacetSize is how many attributes are summarized, most common first
+const attributeFacetSize = 20
+
+// AttributeFacet summarizes one part attribute across the matches
+type AttributeFacet struct {
+	Code     string        `json:"code"`
+	Name     string        `json:"name"`
+	DataType string        `json:"data_type,omitempty"`
+	Unit     string        `json:"unit,omitempty"`
+	Count    int64         `json:"count"`         // Matching parts with a value
+	Values   []FacetBucket `json:"values"`        // Most common values
+	Min      *float64      `json:"min,omitempty"` // Span of numeric values, in Unit
+	Max      *float64      `json:"max,omitempty"`
+}
@@ -1,32 +1,64 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
+	"log"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/b2b-platform/shared/geo"
 )
 
+// autocompleteSize is how many suggestions autocomplete returns
+const autocompleteSize = 10
+
 type SearchService struct {
-	opensearchURL string
-	httpClient    *http.Client
+	backend   SearchBackend
+	synonyms  *SynonymService  // Rewords queries with synonyms when set
+	analytics *SearchAnalytics // Suggests popular queries when set
+	fuzziness string           // Edit distance words may match within
 }
 
-func NewSearchService() *SearchService {
-	opensearchURL := os.Getenv("OPENSEARCH_URL")
-	if opensearchURL == "" {
-		opensearchURL = "http://localhost:9200"
+// NewSearchService returns a search service matching words within the edit
+// distance set by SEARCH_FUZZINESS: AUTO, the default, or 0 to 2
+func NewSearchService(backend SearchBackend) *SearchService {
+	fuzziness := FuzzinessAuto
+	if value, ok := os.LookupEnv("SEARCH_FUZZINESS"); ok {
+		parsed, err := ParseFuzziness(value)
+		if err != nil {
+			log.Printf("Ignoring SEARCH_FUZZINESS: %v", err)
+		} else {
+			fuzziness = parsed
+		}
 	}
 
 	return &SearchService{
-		opensearchURL: opensearchURL,
-		httpClient:    &http.Client{},
+		backend:   backend,
+		fuzziness: fuzziness,
 	}
 }
 
+// UseSynonyms searches with the synonym sets of the service
+func (s *SearchService) UseSynonyms(synonyms *SynonymService) {
+	s.synonyms = synonyms
+}
+
+// UseAnalytics suggests queries other tenants often search, ahead of
+// matching documents
+func (s *SearchService) UseAnalytics(analytics *SearchAnalytics) {
+	s.analytics = analytics
+}
+
+// SetFuzziness sets the edit distance words may match within: AUTO, 0, 1 or 2
+func (s *SearchService) SetFuzziness(fuzziness string) error {
+	parsed, err := ParseFuzziness(fuzziness)
+	if err != nil {
+		return err
+	}
+	s.fuzziness = parsed
+	return nil
+}
+
 type SearchResult struct {
 	Type        string
 	ID          string
@@ -34,6 +66,22 @@ type SearchResult struct {
 	Description string
 	Fields      map[string]interface{}
 	Score       float64
+	Position    int    // Rank among all results, from 1
+	Link        string // API path of the result's document, for procurement documents
+}
+
+// GeoFilter centres a search on a site. Results are kept within WithinKm of
+// it when set, and sorting by distance orders them from it.
+type GeoFilter struct {
+	Origin   geo.Point
+	WithinKm float64
+}
+
+// Period limits a search to documents dated within it, such as procurement
+// documents by when they were issued. Either end may be open.
+type Period struct {
+	Since *time.Time
+	Until *time.Time
 }
 
 func (s *SearchService) Search(
@@ -43,144 +91,101 @@ func (s *SearchService) Search(
 	pageSize int,
 	sort string,
 	filters map[string]string,
+	attributes []AttributeFilter,
+	near *GeoFilter,
+	dated Period,
 	isGuest bool,
 	tenantID string,
 	roles []string,
 ) ([]SearchResult, int64, map[string]interface{}, error) {
-	// Build indices to search
-	indices := s.getIndicesForType(searchType)
-
-	// Build OpenSearch query
-	esQuery := s.buildSearchQuery(query, searchType, page, pageSize, sort, filters, isGuest, tenantID, roles)
-
-	// Execute multi-index search
-	url := fmt.Sprintf("%s/%s/_search", s.opensearchURL, strings.Join(indices, ","))
-	
-	jsonData, err := json.Marshal(esQuery)
-	if err != nil {
-		return nil, 0, nil, fmt.Errorf("failed to marshal query: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, 0, nil, fmt.Errorf("failed to create request: %w", err)
+	if IsProcurementSearch(searchType) {
+		if err := s.checkProcurementAccess(isGuest, tenantID, roles); err != nil {
+			return nil, 0, nil, err
+		}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := s.httpClient.Do(req)
+	hits, err := s.backend.Search(s.buildSearchQuery(query, searchType, page, pageSize, sort, filters, attributes, near, dated, isGuest, tenantID, roles))
 	if err != nil {
-		return nil, 0, nil, fmt.Errorf("opensearch request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, 0, nil, fmt.Errorf("opensearch error: %s - %s", resp.Status, string(body))
-	}
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, 0, nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, 0, nil, err
 	}
 
 	// Parse results
-	hits, _ := result["hits"].(map[string]interface{})
-	total, _ := hits["total"].(map[string]interface{})
-	totalValue, _ := total["value"].(float64)
-	
-	hitsArray, _ := hits["hits"].([]interface{})
-	results := make([]SearchResult, 0, len(hitsArray))
-
-	for _, hit := range hitsArray {
-		hitMap, _ := hit.(map[string]interface{})
-		source, _ := hitMap["_source"].(map[string]interface{})
-		score, _ := hitMap["_score"].(float64)
-		index, _ := hitMap["_index"].(string)
-
-		resultType := s.getTypeFromIndex(index)
-		resultID, _ := source["id"].(string)
-		
-		title := s.extractTitle(source, resultType)
-		description := s.extractDescription(source, resultType)
+	results := make([]SearchResult, 0, len(hits.Hits))
+	for i, hit := range hits.Hits {
+		resultType := s.getTypeFromIndex(hit.Index)
+		resultID, _ := hit.Source["id"].(string)
+
+		title := s.extractTitle(hit.Source, resultType)
+		description := s.extractDescription(hit.Source, resultType)
+		if near != nil {
+			if location, ok := sourcePoint(hit.Source["location"]); ok {
+				hit.Source["distance_km"] = geo.DistanceKm(near.Origin, location)
+			}
+		}
 
 		results = append(results, SearchResult{
 			Type:        resultType,
 			ID:          resultID,
 			Title:       title,
 			Description: description,
-			Fields:      source,
-			Score:       score,
+			Fields:      hit.Source,
+			Score:       hit.Score,
+			Position:    (page-1)*pageSize + i + 1,
+			Link:        documentLink(resultType, resultID),
 		})
 	}
 
-	// Extract facets
-	facets := s.extractFacets(result)
+	facets := make(map[string]interface{}, len(hits.Facets))
+	for name, buckets := range hits.Facets {
+		facets[name] = buckets
+	}
+	if len(hits.Attributes) > 0 {
+		facets["attributes"] = hits.Attributes
+	}
 
-	return results, int64(totalValue), facets, nil
+	return results, hits.Total, facets, nil
 }
 
 func (s *SearchService) Autocomplete(query string) ([]string, error) {
-	// Use edge ngram analyzer for autocomplete
-	url := fmt.Sprintf("%s/parts,equipment,companies,listings/_search", s.opensearchURL)
-	
-	esQuery := map[string]interface{}{
-		"size": 10,
-		"query": map[string]interface{}{
-			"multi_match": map[string]interface{}{
-				"query":  query,
-				"fields": []string{"name.autocomplete^2", "part_number.autocomplete^3", "model.autocomplete^2"},
-				"type":   "bool_prefix",
-			},
-		},
-		"_source": []string{"name", "part_number", "model"},
-	}
-
-	jsonData, err := json.Marshal(esQuery)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, err
+	var popular []string
+	if s.analytics != nil {
+		popular = s.analytics.PopularQueries(query, autocompleteSize)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := s.httpClient.Do(req)
+	hits, err := s.backend.Autocomplete(AutocompleteQuery{
+		Indices: []string{"parts", "equipment", "companies", "listings"},
+		Text:    query,
+		Fields:  []string{"name^2", "part_number^3", "model^2"},
+		Size:    autocompleteSize,
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("opensearch autocomplete error: %s", resp.Status)
-	}
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
 
-	hits, _ := result["hits"].(map[string]interface{})
-	hitsArray, _ := hits["hits"].([]interface{})
-	suggestions := make([]string, 0, len(hitsArray))
-
-	for _, hit := range hitsArray {
-		hitMap, _ := hit.(map[string]interface{})
-		source, _ := hitMap["_source"].(map[string]interface{})
-		
-		if name, ok := source["name"].(string); ok && name != "" {
+	suggestions := make([]string, 0, len(popular)+len(hits))
+	suggestions = append(suggestions, popular...)
+	for _, hit := range hits {
+		if name, ok := hit.Source["name"].(string); ok && name != "" {
 			suggestions = append(suggestions, name)
-		} else if partNumber, ok := source["part_number"].(string); ok && partNumber != "" {
+		} else if partNumber, ok := hit.Source["part_number"].(string); ok && partNumber != "" {
 			suggestions = append(suggestions, partNumber)
-		} else if model, ok := source["model"].(string); ok && model != "" {
+		} else if model, ok := hit.Source["model"].(string); ok && model != "" {
 			suggestions = append(suggestions, model)
 		}
 	}
 
-	return suggestions, nil
+	// A popular query may be a document's name too, in other case
+	seen := make(map[string]bool, len(suggestions))
+	unique := make([]string, 0, autocompleteSize)
+	for _, suggestion := range suggestions {
+		key := normalizeQuery(suggestion)
+		if seen[key] || len(unique) == autocompleteSize {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, suggestion)
+	}
+	return unique, nil
 }
 
 func (s *SearchService) getIndicesForType(searchType string) []string {
@@ -195,6 +200,8 @@ func (s *SearchService) getIndicesForType(searchType string) []string {
 		return []string{"listings"}
 	case "service":
 		return []string{"listings"} // Services are also in listings
+	case "rfq", "quote", "order", "shipment", "procurement":
+		return procurementSearchIndices(searchType)
 	default:
 		return []string{"parts", "equipment", "companies", "listings"}
 	}
@@ -207,145 +214,124 @@ func (s *SearchService) buildSearchQuery(
 	pageSize int,
 	sort string,
 	filters map[string]string,
+	attributes []AttributeFilter,
+	near *GeoFilter,
+	dated Period,
 	isGuest bool,
 	tenantID string,
 	roles []string,
-) map[string]interface{} {
-	// Build must clauses (required)
-	mustClauses := []map[string]interface{}{}
-
-	// Guest restrictions
-	if isGuest {
-		mustClauses = append(mustClauses, map[string]interface{}{
-			"term": map[string]interface{}{
-				"visibility": "public",
-			},
-		})
-		mustClauses = append(mustClauses, map[string]interface{}{
-			"term": map[string]interface{}{
-				"status": "approved",
-			},
-		})
-		mustClauses = append(mustClauses, map[string]interface{}{
-			"term": map[string]interface{}{
-				"company_status": "approved",
-			},
-		})
-	} else {
-		// Authenticated user: apply tenant/RBAC rules
-		shouldClauses := []map[string]interface{}{
-			// Public and approved
-			{
-				"bool": map[string]interface{}{
-					"must": []map[string]interface{}{
-						{"term": map[string]interface{}{"visibility": "public"}},
-						{"term": map[string]interface{}{"status": "approved"}},
-					},
-				},
-			},
-		}
-
-		// Buyers can see supplier listings
-		if s.hasRole(roles, "buyer") || s.hasRole(roles, "requester") || s.hasRole(roles, "procurement_manager") {
-			shouldClauses = append(shouldClauses, map[string]interface{}{
-				"term": map[string]interface{}{
-					"type": "listing",
-				},
-			})
-		}
-
-		// Suppliers see own listings
-		if s.hasRole(roles, "supplier") && tenantID != "" {
-			shouldClauses = append(shouldClauses, map[string]interface{}{
-				"bool": map[string]interface{}{
-					"must": []map[string]interface{}{
-						{"term": map[string]interface{}{"supplier_id": tenantID}},
-					},
-				},
-			})
-		}
-
-		// Admins see everything
-		if s.hasRole(roles, "admin") || s.hasRole(roles, "super_admin") {
-			shouldClauses = []map[string]interface{}{{"match_all": map[string]interface{}{}}}
-		}
-
-		mustClauses = append(mustClauses, map[string]interface{}{
-			"bool": map[string]interface{}{
-				"should": shouldClauses,
-				"minimum_should_match": 1,
-			},
-		})
+) SearchQuery {
+	visibility := s.visibilityFilter(isGuest, tenantID, roles)
+	if IsProcurementSearch(searchType) {
+		visibility = s.procurementFilter(tenantID, roles)
 	}
+	conditions := []Filter{visibility}
 
 	// Add filters
 	for key, value := range filters {
-		mustClauses = append(mustClauses, map[string]interface{}{
-			"term": map[string]interface{}{
-				key: value,
-			},
-		})
+		conditions = append(conditions, Term(key, value))
+	}
+	for _, attribute := range attributes {
+		conditions = append(conditions, attribute.filter())
+	}
+	if near != nil && near.WithinKm > 0 {
+		conditions = append(conditions, WithinDistance("location", near.Origin, near.WithinKm))
+	}
+	if dated.Since != nil || dated.Until != nil {
+		conditions = append(conditions, During("timestamp", dated.Since, dated.Until))
 	}
 
-	// Build query
-	esQuery := map[string]interface{}{
-		"from": (page - 1) * pageSize,
-		"size": pageSize,
-		"query": map[string]interface{}{
-			"bool": map[string]interface{}{
-				"must": []map[string]interface{}{
-					{
-						"multi_match": map[string]interface{}{
-							"query":  query,
-							"fields": s.getBoostedFields(searchType),
-							"type":   "best_fields",
-							"operator": "and",
-						},
-					},
-				},
-				"filter": mustClauses,
-			},
+	searchQuery := SearchQuery{
+		Indices:     s.getIndicesForType(searchType),
+		Text:        query,
+		Fields:      s.getBoostedFields(searchType),
+		Filter:      AllOf(conditions...),
+		Identifiers: s.getIdentifierFields(searchType),
+		Fuzziness:   s.fuzziness,
+		From:        (page - 1) * pageSize,
+		Size:        pageSize,
+		Facets: map[string]string{
+			"types":      "type",
+			"categories": "category",
 		},
 	}
+	if IsProcurementSearch(searchType) {
+		searchQuery.Facets = map[string]string{
+			"types":    "type",
+			"statuses": "status",
+		}
+	}
+	if s.synonyms != nil {
+		searchQuery.Alternatives = s.synonyms.Expand(query, tenantID)
+	}
+	for _, index := range searchQuery.Indices {
+		if index == "parts" {
+			searchQuery.AttributeFacets = true
+		}
+	}
 
 	// Add sorting
+	relevance := SortField{Field: ScoreField, Desc: true}
 	switch sort {
 	case "rating":
-		esQuery["sort"] = []map[string]interface{}{
-			{"rating": map[string]interface{}{"order": "desc"}},
-			{"_score": map[string]interface{}{"order": "desc"}},
-		}
+		searchQuery.Sort = []SortField{{Field: "rating", Desc: true}, relevance}
 	case "price":
-		esQuery["sort"] = []map[string]interface{}{
-			{"price": map[string]interface{}{"order": "asc"}},
-			{"_score": map[string]interface{}{"order": "desc"}},
-		}
+		searchQuery.Sort = []SortField{{Field: "price"}, relevance}
 	case "eta":
-		esQuery["sort"] = []map[string]interface{}{
-			{"eta": map[string]interface{}{"order": "asc"}},
-			{"_score": map[string]interface{}{"order": "desc"}},
+		searchQuery.Sort = []SortField{{Field: "eta"}, relevance}
+	case "newest":
+		searchQuery.Sort = []SortField{{Field: "timestamp", Desc: true}, relevance}
+	case "distance":
+		if near == nil {
+			searchQuery.Sort = []SortField{relevance}
+			break
 		}
+		searchQuery.Sort = []SortField{{Field: "location", Near: &near.Origin}, relevance}
 	default: // relevance
-		esQuery["sort"] = []map[string]interface{}{
-			{"_score": map[string]interface{}{"order": "desc"}},
-		}
+		searchQuery.Sort = []SortField{relevance}
 	}
 
-	// Add aggregations for facets
-	esQuery["aggs"] = map[string]interface{}{
-		"types": map[string]interface{}{
-			"terms": map[string]interface{}{
-				"field": "type",
-			},
-		},
-		"categories": map[string]interface{}{
-			"terms": map[string]interface{}{
-				"field": "category",
-			},
-		},
+	return searchQuery
+}
+
+// visibilityFilter limits a search to the documents its user may see
+func (s *SearchService) visibilityFilter(isGuest bool, tenantID string, roles []string) Filter {
+	// Guest restrictions
+	if isGuest {
+		return AllOf(
+			Term("visibility", "public"),
+			Term("status", "approved"),
+			Term("company_status", "approved"),
+		)
+	}
+
+	// Admins see everything
+	if s.hasRole(roles, "admin") || s.hasRole(roles, "super_admin") {
+		return Filter{}
+	}
+
+	// Authenticated user: apply tenant/RBAC rules
+	visible := []Filter{
+		// Public and approved
+		AllOf(Term("visibility", "public"), Term("status", "approved")),
 	}
 
-	return esQuery
+	// Buyers can see supplier listings
+	if s.hasRole(roles, "buyer") || s.hasRole(roles, "requester") || s.hasRole(roles, "procurement_manager") {
+		visible = append(visible, Term("type", "listing"))
+	}
+
+	// Users see their own tenant's private documents, such as equipment
+	if tenantID != "" {
+		visible = append(visible, Term("tenant_id", tenantID))
+	}
+
+	// Suppliers see own listings
+	if s.hasRole(roles, "supplier") && tenantID != "" {
+		visible = append(visible, Term("supplier_id", tenantID))
+	}
+
+	return AnyOf(visible...)
 }
 
 func (s *SearchService) getBoostedFields(searchType string) []string {
@@ -356,15 +342,36 @@ func (s *SearchService) getBoostedFields(searchType string) []string {
 
 	switch searchType {
 	case "part":
-		return append(baseFields, "part_number^5", "manufacturer_code^4", "manufacturer^2")
+		return append(baseFields, "part_number^5", "manufacturer_code^4", "interchange_part_numbers^4", "manufacturer^2")
 	case "equipment":
 		return append(baseFields, "model^4", "series^3", "manufacturer^2")
 	case "company":
 		return []string{"name^5", "subdomain^2"}
 	case "listing":
 		return append(baseFields, "sku^4", "brand^2")
+	case "rfq", "quote", "order", "shipment", "procurement":
+		return []string{"number^5", "title^3", "items^2", "description^1", "carrier^1", "origin^1", "destination^1"}
+	default:
+		return append(baseFields, "part_number^5", "interchange_part_numbers^4", "model^4", "sku^4")
+	}
+}
+
+// getIdentifierFields are the fields matched against the whole query as an
+// identifier, ignoring case, spaces and punctuation
+func (s *SearchService) getIdentifierFields(searchType string) []string {
+	switch searchType {
+	case "part":
+		return []string{"part_number^5", "manufacturer_code^4", "interchange_part_numbers^4"}
+	case "equipment":
+		return []string{"model^4"}
+	case "company":
+		return nil
+	case "listing", "service":
+		return []string{"sku^4"}
+	case "rfq", "quote", "order", "shipment", "procurement":
+		return []string{"number^5"}
 	default:
-		return append(baseFields, "part_number^5", "model^4", "sku^4")
+		return []string{"part_number^5", "interchange_part_numbers^4", "model^4", "sku^4"}
 	}
 }
 
@@ -395,6 +402,10 @@ func (s *SearchService) extractTitle(source map[string]interface{}, resultType s
 		if name, ok := source["name"].(string); ok {
 			return name
 		}
+	case "rfq", "quote", "order", "shipment":
+		if title := procurementTitle(source); title != "" {
+			return title
+		}
 	}
 	return "Untitled"
 }
@@ -403,6 +414,16 @@ func (s *SearchService) extractDescription(source map[string]interface{}, result
 	if desc, ok := source["description"].(string); ok && desc != "" {
 		return desc
 	}
+	// Orders and quotes are described by what they are for
+	if items, ok := source["items"].([]interface{}); ok {
+		descriptions := make([]string, 0, len(items))
+		for _, item := range items {
+			if description, ok := item.(string); ok && description != "" {
+				descriptions = append(descriptions, description)
+			}
+		}
+		return strings.Join(descriptions, ", ")
+	}
 	return ""
 }
 
@@ -416,30 +437,12 @@ func (s *SearchService) getTypeFromIndex(index string) string {
 	} else if strings.HasPrefix(index, "listing") {
 		return "listing"
 	}
-	return "unknown"
-}
-
-func (s *SearchService) extractFacets(result map[string]interface{}) map[string]interface{} {
-	facets := make(map[string]interface{})
-	
-	aggs, ok := result["aggregations"].(map[string]interface{})
-	if !ok {
-		return facets
-	}
-
-	if types, ok := aggs["types"].(map[string]interface{}); ok {
-		if buckets, ok := types["buckets"].([]interface{}); ok {
-			facets["types"] = buckets
+	for resultType, procurementIndex := range procurementIndices {
+		if strings.HasPrefix(index, procurementIndex) {
+			return resultType
 		}
 	}
-
-	if categories, ok := aggs["categories"].(map[string]interface{}); ok {
-		if buckets, ok := categories["buckets"].([]interface{}); ok {
-			facets["categories"] = buckets
-		}
-	}
-
-	return facets
+	return "unknown"
 }
 
 func (s *SearchService) hasRole(roles []string, role string) bool {
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/shared/events"
+	"github.com/google/uuid"
+)
+
+// SnapshotSource is a service that exports the entities behind a search
+// index, page by page, for a rebuild
+type SnapshotSource struct {
+	Index   string
+	Service string
+	URL     string
+	// TenantField is the document field holding the owning tenant; empty
+	// when the index is not split by tenant
+	TenantField string
+	build       documentBuilder
+}
+
+// DefaultSnapshotSources returns the sources of the rebuildable indices
+func DefaultSnapshotSources() []SnapshotSource {
+	return []SnapshotSource{
+		{
+			Index:   "parts",
+			Service: "catalog",
+			URL:     serviceURL("CATALOG_SERVICE_URL", "http://localhost:8003") + "/api/v1/parts/search-snapshot",
+			build:   partDocument,
+		},
+		{
+			Index:       "companies",
+			Service:     "company",
+			URL:         serviceURL("COMPANY_SERVICE_URL", "http://localhost:8002") + "/api/v1/companies/search-snapshot",
+			TenantField: "id", // A company is its own tenant
+			build:       companyDocument,
+		},
+		{
+			Index:       "equipment",
+			Service:     "equipment",
+			URL:         serviceURL("EQUIPMENT_SERVICE_URL", "http://localhost:8004") + "/api/v1/equipment/search-snapshot",
+			TenantField: "tenant_id",
+			build:       equipmentDocument,
+		},
+		{
+			Index:       "listings",
+			Service:     "marketplace",
+			URL:         serviceURL("MARKETPLACE_SERVICE_URL", "http://localhost:8005") + "/api/v1/listings/search-snapshot",
+			TenantField: "supplier_id",
+			build:       listingDocument,
+		},
+	}
+}
+
+func serviceURL(env, fallback string) string {
+	if baseURL := os.Getenv(env); baseURL != "" {
+		return baseURL
+	}
+	return fallback
+}
+
+// SnapshotClient reads entity snapshots from the services that own them
+type SnapshotClient struct {
+	httpClient *http.Client
+}
+
+func NewSnapshotClient() *SnapshotClient {
+	return &SnapshotClient{
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// FetchPage reads the page of a source's entities after a cursor,
+// optionally only one tenant's
+func (c *SnapshotClient) FetchPage(source SnapshotSource, authToken, after string, tenantID *uuid.UUID, limit int) (*events.SnapshotPage, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if after != "" {
+		query.Set("after", after)
+	}
+	if tenantID != nil {
+		query.Set("tenant_id", tenantID.String())
+	}
+
+	httpReq, err := http.NewRequest("GET", source.URL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if !strings.HasPrefix(authToken, "Bearer ") {
+		authToken = "Bearer " + authToken
+	}
+	httpReq.Header.Set("Authorization", authToken)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s service: %w", source.Service, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s service returned status %d: %s", source.Service, resp.StatusCode, string(body))
+	}
+
+	var page events.SnapshotPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &page, nil
+}
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSynonymSetNotFound  = errors.New("synonym set not found")
+	ErrInvalidSynonymSet   = errors.New("invalid synonym set")
+	ErrSynonymSetForbidden = errors.New("not allowed to manage this synonym set")
+)
+
+// synonymSetsIndex holds synonym sets on OpenSearch
+const synonymSetsIndex = "search_synonyms"
+
+// synonymReloadInterval is how often synonym sets are read again, picking up
+// changes made through other replicas
+const synonymReloadInterval = 30 * time.Second
+
+// maxAlternatives caps the rewordings of one query, which grow with every
+// word that has synonyms
+const maxAlternatives = 16
+
+// SynonymSet is a list of synonym rules. Global sets, with no tenant, apply
+// to every search; a tenant's sets to its users' searches. Rules are written
+// as OpenSearch writes them:
+//
+//	bearing, roller      either word also finds the other
+//	vlv, vv => valve     vlv and vv also find valve, but not the other way
+//
+// Synonyms are applied when searching, so a changed set takes effect without
+// reindexing.
+type SynonymSet struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	Name      string    `json:"name"`
+	Rules     []string  `json:"rules"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SynonymStore keeps synonym sets
+type SynonymStore interface {
+	SaveSynonymSet(set *SynonymSet) error
+	DeleteSynonymSet(id string) error
+	ListSynonymSets() ([]SynonymSet, error)
+}
+
+// SynonymSetInput is a synonym set to save. Global sets are admins' to manage.
+type SynonymSetInput struct {
+	Name   string   `json:"name" binding:"required"`
+	Rules  []string `json:"rules" binding:"required"`
+	Global bool     `json:"global"`
+}
+
+// synonymRules maps a phrase, as lowercase words joined by spaces, to the
+// phrases it is also searched as
+type synonymRules map[string][]string
+
+// SynonymService manages synonym sets and rewords queries with them
+type SynonymService struct {
+	store SynonymStore
+
+	mu       sync.RWMutex
+	sets     []SynonymSet
+	global   synonymRules
+	tenants  map[string]synonymRules
+	loadedAt time.Time
+}
+
+func NewSynonymService(store SynonymStore) *SynonymService {
+	return &SynonymService{
+		store:   store,
+		global:  synonymRules{},
+		tenants: make(map[string]synonymRules),
+	}
+}
+
+// Reload reads the synonym sets from the store
+func (s *SynonymService) Reload() error {
+	sets, err := s.store.ListSynonymSets()
+	if err != nil {
+		return fmt.Errorf("failed to load synonym sets: %w", err)
+	}
+
+	global := synonymRules{}
+	tenants := make(map[string]synonymRules)
+	for _, set := range sets {
+		rules := global
+		if set.TenantID != "" {
+			if tenants[set.TenantID] == nil {
+				tenants[set.TenantID] = synonymRules{}
+			}
+			rules = tenants[set.TenantID]
+		}
+		// Sets are checked when saved
+		rules.add(set.Rules)
+	}
+
+	s.mu.Lock()
+	s.sets = sets
+	s.global = global
+	s.tenants = tenants
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// reloadIfStale reloads synonym sets last read a while ago. A failed reload
+// keeps the sets already loaded.
+func (s *SynonymService) reloadIfStale() {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) >= synonymReloadInterval
+	s.mu.RUnlock()
+	if !stale {
+		return
+	}
+
+	if err := s.Reload(); err != nil {
+		log.Printf("Failed to reload synonyms: %v", err)
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// List returns the synonym sets a tenant's users search with, or every set
+// for an admin
+func (s *SynonymService) List(tenantID string, admin bool) []SynonymSet {
+	s.reloadIfStale()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sets := []SynonymSet{}
+	for _, set := range s.sets {
+		if admin || set.TenantID == "" || set.TenantID == tenantID {
+			sets = append(sets, set)
+		}
+	}
+	return sets
+}
+
+// Create saves a synonym set for the user's tenant, or a global set when an
+// admin asks for one
+func (s *SynonymService) Create(tenantID string, admin bool, input SynonymSetInput) (*SynonymSet, error) {
+	if input.Global && !admin {
+		return nil, ErrSynonymSetForbidden
+	}
+
+	set := &SynonymSet{ID: uuid.New().String()}
+	if !input.Global {
+		set.TenantID = tenantID
+	}
+	if err := s.save(set, input); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Update replaces a synonym set's rules. Tenants manage their own sets.
+func (s *SynonymService) Update(id, tenantID string, admin bool, input SynonymSetInput) (*SynonymSet, error) {
+	set, err := s.manageable(id, tenantID, admin)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(set, input); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func (s *SynonymService) Delete(id, tenantID string, admin bool) error {
+	if _, err := s.manageable(id, tenantID, admin); err != nil {
+		return err
+	}
+	if err := s.store.DeleteSynonymSet(id); err != nil {
+		return err
+	}
+	return s.Reload()
+}
+
+// manageable returns a synonym set the user may change
+func (s *SynonymService) manageable(id, tenantID string, admin bool) (*SynonymSet, error) {
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, set := range s.sets {
+		if set.ID != id {
+			continue
+		}
+		if !admin && set.TenantID != tenantID {
+			// Other tenants' sets are not disclosed
+			if set.TenantID != "" {
+				return nil, ErrSynonymSetNotFound
+			}
+			return nil, ErrSynonymSetForbidden
+		}
+		found := set
+		return &found, nil
+	}
+	return nil, ErrSynonymSetNotFound
+}
+
+func (s *SynonymService) save(set *SynonymSet, input SynonymSetInput) error {
+	set.Name = strings.TrimSpace(input.Name)
+	set.Rules = nil
+	for _, rule := range input.Rules {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			set.Rules = append(set.Rules, rule)
+		}
+	}
+	set.UpdatedAt = time.Now()
+
+	if set.Name == "" || len(set.Rules) == 0 {
+		return fmt.Errorf("%w: name and rules are required", ErrInvalidSynonymSet)
+	}
+	for _, rule := range set.Rules {
+		if _, _, err := parseSynonymRule(rule); err != nil {
+			return err
+		}
+	}
+
+	if err := s.store.SaveSynonymSet(set); err != nil {
+		return err
+	}
+	// Searches through this replica use the change at once
+	return s.Reload()
+}
+
+// Expand returns other wordings of a query, with a tenant's synonyms and the
+// global ones
+func (s *SynonymService) Expand(text, tenantID string) []string {
+	s.reloadIfStale()
+
+	s.mu.RLock()
+	global, tenant := s.global, s.tenants[tenantID]
+	s.mu.RUnlock()
+	if len(global) == 0 && len(tenant) == 0 {
+		return nil
+	}
+
+	words := tokenize(text)
+	wordings := [][]string{{}}
+	changed := false
+	for i := 0; i < len(words); {
+		length, replacements := longestSynonym(words[i:], global, tenant)
+		if length == 0 {
+			for j := range wordings {
+				wordings[j] = append(wordings[j], words[i])
+			}
+			i++
+			continue
+		}
+
+		phrase := strings.Join(words[i:i+length], " ")
+		options := append([]string{phrase}, replacements...)
+		next := make([][]string, 0, len(wordings)*len(options))
+		for _, wording := range wordings {
+			for _, option := range options {
+				if len(next) == maxAlternatives+1 {
+					break
+				}
+				extended := append(append([]string{}, wording...), strings.Fields(option)...)
+				next = append(next, extended)
+			}
+		}
+		wordings = next
+		changed = true
+		i += length
+	}
+	if !changed {
+		return nil
+	}
+
+	// The first wording is the query itself
+	alternatives := make([]string, 0, len(wordings)-1)
+	for _, wording := range wordings[1:] {
+		alternatives = append(alternatives, strings.Join(wording, " "))
+	}
+	return alternatives
+}
+
+// longestSynonym finds the longest phrase starting the words that has
+// synonyms, so "ball bearing" is replaced before "bearing". It returns the
+// phrase's length in words.
+func longestSynonym(words []string, ruleSets ...synonymRules) (int, []string) {
+	for length := len(words); length > 0; length-- {
+		phrase := strings.Join(words[:length], " ")
+		var replacements []string
+		for _, rules := range ruleSets {
+			replacements = append(replacements, rules[phrase]...)
+		}
+		if len(replacements) > 0 {
+			return length, uniqueStrings(replacements)
+		}
+	}
+	return 0, nil
+}
+
+func (r synonymRules) add(rules []string) {
+	for _, rule := range rules {
+		from, to, err := parseSynonymRule(rule)
+		if err != nil {
+			continue
+		}
+		for _, phrase := range from {
+			for _, synonym := range to {
+				if synonym != phrase {
+					r[phrase] = uniqueStrings(append(r[phrase], synonym))
+				}
+			}
+		}
+	}
+}
+
+// parseSynonymRule reads a rule into the phrases it applies to and the
+// phrases those are also searched as
+func parseSynonymRule(rule string) (from, to []string, err error) {
+	left, right, oneWay := strings.Cut(rule, "=>")
+	from = synonymPhrases(left)
+	if oneWay {
+		to = synonymPhrases(right)
+	} else {
+		to = from
+	}
+
+	if len(from) == 0 || len(to) == 0 || (!oneWay && len(from) < 2) {
+		return nil, nil, fmt.Errorf("%w: rule %q needs two or more synonyms", ErrInvalidSynonymSet, rule)
+	}
+	return from, to, nil
+}
+
+func synonymPhrases(list string) []string {
+	phrases := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if words := tokenize(item); len(words) > 0 {
+			phrases = append(phrases, strings.Join(words, " "))
+		}
+	}
+	return phrases
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := values[:0]
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
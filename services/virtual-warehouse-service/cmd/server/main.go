@@ -87,6 +87,7 @@ func main() {
 		// Inventory endpoints
 		api.GET("/inventory", warehouseHandler.ListInventory)
 		api.GET("/inventory/available", warehouseHandler.GetAvailable)
+		api.POST("/inventory/stock", warehouseHandler.GetStock)
 		api.POST("/inventory", warehouseHandler.CreateInventory)
 
 		// Equipment group endpoints
@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/b2b-platform/virtual-warehouse-service/models"
 	"github.com/b2b-platform/virtual-warehouse-service/service"
 	"github.com/b2b-platform/shared/auth"
+	"github.com/b2b-platform/shared/geo"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -31,6 +33,10 @@ func (h *WarehouseHandler) CreateInventory(c *gin.Context) {
 	inventory.TenantID = tenantID
 
 	if err := h.service.CreateInventory(&inventory); err != nil {
+		if errors.Is(err, geo.ErrInvalidLocation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -59,7 +65,15 @@ func (h *WarehouseHandler) GetAvailable(c *gin.Context) {
 
 	quantity, _ := strconv.ParseFloat(c.DefaultQuery("quantity", "1"), 64)
 
-	inventory, err := h.service.GetAvailable(partID, quantity)
+	// Stock near a site: near=lat,lon or near_postal_code with near_country
+	near, err := geo.ParseOrigin(c.Query("near"), c.Query("near_country"), c.Query("near_postal_code"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	withinKm, _ := strconv.ParseFloat(c.Query("within_km"), 64)
+
+	inventory, err := h.service.GetAvailable(partID, quantity, near, withinKm)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -68,6 +82,27 @@ func (h *WarehouseHandler) GetAvailable(c *gin.Context) {
 	c.JSON(http.StatusOK, inventory)
 }
 
+// GetStock returns the caller's stock position for each requested part
+func (h *WarehouseHandler) GetStock(c *gin.Context) {
+	var req struct {
+		PartIDs []uuid.UUID `json:"part_ids" binding:"required,max=1000"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenantID, _ := auth.GetTenantID(c)
+
+	stock, err := h.service.GetStock(tenantID, req.PartIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stock)
+}
+
 // Equipment group endpoints
 func (h *WarehouseHandler) CreateGroup(c *gin.Context) {
 	var group models.EquipmentGroup
@@ -0,0 +1,15 @@
+-- Where shared stock is, for finding stock near a site
+ALTER TABLE virtual_warehouse.shared_inventory
+ADD COLUMN IF NOT EXISTS country VARCHAR(100);
+
+ALTER TABLE virtual_warehouse.shared_inventory
+ADD COLUMN IF NOT EXISTS postal_code VARCHAR(20);
+
+ALTER TABLE virtual_warehouse.shared_inventory
+ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+
+ALTER TABLE virtual_warehouse.shared_inventory
+ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+
+ALTER TABLE virtual_warehouse.shared_inventory
+ADD COLUMN IF NOT EXISTS location_geocoded BOOLEAN DEFAULT FALSE;
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/b2b-platform/shared/geo"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -14,12 +15,19 @@ type SharedInventory struct {
 	EquipmentID *uuid.UUID     `gorm:"type:uuid;index" json:"equipment_id,omitempty"`
 	Quantity    float64        `gorm:"not null" json:"quantity"`
 	Location    string         `gorm:"type:varchar(255)" json:"location"`
+	// Where the stock is, for finding stock near a site
+	Country     string         `gorm:"type:varchar(100)" json:"country"`
+	PostalCode  string         `gorm:"type:varchar(20)" json:"postal_code"`
+	geo.Site                   `gorm:"embedded"`
 	IsAvailable bool           `gorm:"default:true;index" json:"is_available"`
 	ReservedQty float64        `gorm:"default:0" json:"reserved_qty"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// Distance from the site stock was looked up near
+	DistanceKm  *float64       `gorm:"-" json:"distance_km,omitempty"`
+
 	// Relationships
 }
 
@@ -27,6 +35,14 @@ func (SharedInventory) TableName() string {
 	return "virtual_warehouse.shared_inventory"
 }
 
+// PartStock is the unreserved quantity of a part in a tenant's own inventory
+// and in other tenants' inventory shared through the virtual warehouse
+type PartStock struct {
+	PartID          uuid.UUID `json:"part_id"`
+	Available       float64   `json:"available"`
+	SharedAvailable float64   `json:"shared_available"`
+}
+
 type EquipmentGroup struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	TenantID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"tenant_id"`
@@ -48,3 +48,18 @@ func (r *InventoryRepository) Release(inventoryID uuid.UUID, quantity float64) e
 		Where("id = ?", inventoryID).
 		UpdateColumn("reserved_qty", gorm.Expr("GREATEST(0, reserved_qty - ?)", quantity)).Error
 }
+
+// StockByParts totals the unreserved quantity of each part held by the tenant
+// and shared by other tenants. Parts with no stock are missing.
+func (r *InventoryRepository) StockByParts(tenantID uuid.UUID, partIDs []uuid.UUID) ([]models.PartStock, error) {
+	var stock []models.PartStock
+	err := r.db.Model(&models.SharedInventory{}).
+		Select(`part_id,
+			COALESCE(SUM(CASE WHEN tenant_id = ? THEN quantity - reserved_qty END), 0) AS available,
+			COALESCE(SUM(CASE WHEN tenant_id <> ? THEN quantity - reserved_qty END), 0) AS shared_available`,
+			tenantID, tenantID).
+		Where("part_id IN ? AND is_available = ? AND quantity > reserved_qty", partIDs, true).
+		Group("part_id").
+		Scan(&stock).Error
+	return stock, err
+}
@@ -1,8 +1,10 @@
 package service
 
 import (
+	"sort"
 	"time"
 
+	"github.com/b2b-platform/shared/geo"
 	"github.com/b2b-platform/virtual-warehouse-service/models"
 	"github.com/b2b-platform/virtual-warehouse-service/repository"
 	"github.com/google/uuid"
@@ -30,6 +32,9 @@ func NewWarehouseService(
 }
 
 func (s *WarehouseService) CreateInventory(inventory *models.SharedInventory) error {
+	if err := inventory.Locate(inventory.Country, inventory.PostalCode); err != nil {
+		return err
+	}
 	return s.inventoryRepo.Create(inventory)
 }
 
@@ -37,8 +42,64 @@ func (s *WarehouseService) ListInventory(tenantID uuid.UUID) ([]models.SharedInv
 	return s.inventoryRepo.List(tenantID)
 }
 
-func (s *WarehouseService) GetAvailable(partID uuid.UUID, quantity float64) ([]models.SharedInventory, error) {
-	return s.inventoryRepo.GetAvailable(partID, quantity)
+// GetAvailable finds stock of a part with enough unreserved. With a site to
+// look near, only stock within withinKm of it is returned, if withinKm is
+// set, nearest first; stock without coordinates is left out.
+func (s *WarehouseService) GetAvailable(partID uuid.UUID, quantity float64, near *geo.Point, withinKm float64) ([]models.SharedInventory, error) {
+	inventory, err := s.inventoryRepo.GetAvailable(partID, quantity)
+	if err != nil || near == nil {
+		return inventory, err
+	}
+
+	nearby := make([]models.SharedInventory, 0, len(inventory))
+	for _, item := range inventory {
+		point, ok := item.Point()
+		if !ok {
+			continue
+		}
+		distance := geo.DistanceKm(*near, point)
+		if withinKm > 0 && distance > withinKm {
+			continue
+		}
+		item.DistanceKm = &distance
+		nearby = append(nearby, item)
+	}
+	sort.SliceStable(nearby, func(i, j int) bool {
+		return *nearby[i].DistanceKm < *nearby[j].DistanceKm
+	})
+	return nearby, nil
+}
+
+// GetStock returns the tenant's stock position for each part, including
+// parts with none
+func (s *WarehouseService) GetStock(tenantID uuid.UUID, partIDs []uuid.UUID) ([]models.PartStock, error) {
+	if len(partIDs) == 0 {
+		return []models.PartStock{}, nil
+	}
+
+	found, err := s.inventoryRepo.StockByParts(tenantID, partIDs)
+	if err != nil {
+		return nil, err
+	}
+	byPart := make(map[uuid.UUID]models.PartStock, len(found))
+	for _, stock := range found {
+		byPart[stock.PartID] = stock
+	}
+
+	stock := make([]models.PartStock, 0, len(partIDs))
+	listed := make(map[uuid.UUID]bool, len(partIDs))
+	for _, partID := range partIDs {
+		if listed[partID] {
+			continue
+		}
+		listed[partID] = true
+		if found, ok := byPart[partID]; ok {
+			stock = append(stock, found)
+		} else {
+			stock = append(stock, models.PartStock{PartID: partID})
+		}
+	}
+	return stock, nil
 }
 
 func (s *WarehouseService) Reserve(inventoryID uuid.UUID, quantity float64) error {
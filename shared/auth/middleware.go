@@ -102,6 +102,26 @@ func GetUserID(c *gin.Context) (uuid.UUID, error) {
 	return id, nil
 }
 
+// HasRole reports whether the authenticated user holds any of the roles
+func HasRole(c *gin.Context, roles ...string) bool {
+	value, exists := c.Get(RolesKey)
+	if !exists {
+		return false
+	}
+	userRoles, ok := value.([]string)
+	if !ok {
+		return false
+	}
+	for _, userRole := range userRoles {
+		for _, role := range roles {
+			if userRole == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // OptionalAuthMiddleware extracts JWT if present but doesn't require it
 // Useful for endpoints that work for both guests and authenticated users
 func OptionalAuthMiddleware(jwtService *JWTService) gin.HandlerFunc {
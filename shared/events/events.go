@@ -14,13 +14,38 @@ type EventType string
 const (
 	// Company events
 	EventCompanyApproved EventType = "core.company.approved.v1"
+	EventCompanyCreated  EventType = "core.company.created.v1"
+	EventCompanyUpdated  EventType = "core.company.updated.v1"
+	EventCompanyDeleted  EventType = "core.company.deleted.v1"
 	
 	// Catalog events
+	EventCatalogPartCreated  EventType = "catalog.lib_part.created.v1"
+	EventCatalogPartUpdated  EventType = "catalog.lib_part.updated.v1"
+	EventCatalogPartDeleted  EventType = "catalog.lib_part.deleted.v1"
 	EventCatalogPartApproved EventType = "catalog.lib_part.approved.v1"
+	EventCatalogPartMerged   EventType = "catalog.lib_part.merged.v1"
+	EventCatalogPartInterchangeUpdated EventType = "catalog.lib_part.interchange_updated.v1"
+	
+	// Equipment events
+	EventEquipmentCreated EventType = "equipment.equipment.created.v1"
+	EventEquipmentUpdated EventType = "equipment.equipment.updated.v1"
+	EventEquipmentDeleted EventType = "equipment.equipment.deleted.v1"
+	
+	// Marketplace events
+	EventListingCreated EventType = "marketplace.listing.created.v1"
+	EventListingUpdated EventType = "marketplace.listing.updated.v1"
+	EventListingDeleted EventType = "marketplace.listing.deleted.v1"
+	
+	// Search events
+	EventSavedSearchMatched EventType = "search.saved_search.matched.v1"
 	
 	// Procurement events
 	EventPRApproved        EventType = "procurement.pr.approved.v1"
+	EventPRBudgetEscalated EventType = "procurement.pr.budget_escalated.v1"
 	EventRFQCreated       EventType = "procurement.rfq.created.v1"
+	EventRFQSuppliersInvited EventType = "procurement.rfq.suppliers_invited.v1"
+	EventRFQDeclined      EventType = "procurement.rfq.declined.v1"
+	EventRFQAmended       EventType = "procurement.rfq.amended.v1"
 	EventQuoteSubmitted   EventType = "procurement.quote.submitted.v1"
 	EventOrderPlaced      EventType = "procurement.order.placed.v1"
 	
@@ -29,6 +54,8 @@ const (
 	
 	// Collaboration events
 	EventChatMessageSent EventType = "collab.chat.message_sent.v1"
+	EventRFQQuestionAsked    EventType = "collab.rfq.question_asked.v1"
+	EventRFQQuestionAnswered EventType = "collab.rfq.question_answered.v1"
 	
 	// Billing events
 	EventSubscriptionStarted EventType = "billing.subscription.started.v1"
@@ -47,6 +74,7 @@ type EventEnvelope struct {
 	Timestamp time.Time              `json:"timestamp"`
 	TenantID  *uuid.UUID             `json:"tenant_id,omitempty"`
 	UserID    *uuid.UUID             `json:"user_id,omitempty"`
+	Version   int64                  `json:"version,omitempty"` // Version of the entity the event describes, see VersionAt
 	Payload   map[string]interface{} `json:"payload"`
 }
 
@@ -80,6 +108,29 @@ func (e *EventEnvelope) WithUserID(userID uuid.UUID) *EventEnvelope {
 	return e
 }
 
+// WithVersion sets the version of the entity the event describes. Consumers
+// keeping a copy of the entity ignore events older than the copy they hold.
+func (e *EventEnvelope) WithVersion(version int64) *EventEnvelope {
+	e.Version = version
+	return e
+}
+
+// VersionAt returns the entity version for a change made at t, usually the
+// entity's UpdatedAt. Versions are in microseconds so that a time read back
+// from the database gives the same version as the one it was saved with.
+func VersionAt(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+// SnapshotPage is one page of an entity export, each entity given as the
+// versioned event announcing its current state. Consumers rebuilding their
+// copy of the entities, such as a search reindex, read pages until NextAfter
+// is empty.
+type SnapshotPage struct {
+	Events    []*EventEnvelope `json:"events"`
+	NextAfter string           `json:"next_after,omitempty"` // Cursor for the next page
+}
+
 // Serialize converts the event to JSON bytes
 func (e *EventEnvelope) Serialize() ([]byte, error) {
 	return json.Marshal(e)
@@ -0,0 +1,133 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusKm is the mean radius of the earth
+const earthRadiusKm = 6371.0088
+
+// Point is a location in decimal degrees
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Valid reports whether the point is on the globe
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// At returns the point of an optional latitude and longitude, as models keep
+// them, if both are set
+func At(lat, lon *float64) (Point, bool) {
+	if lat == nil || lon == nil {
+		return Point{}, false
+	}
+	p := Point{Lat: *lat, Lon: *lon}
+	return p, p.Valid()
+}
+
+// DistanceKm is the great-circle distance between two points
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLon := radians(b.Lon - a.Lon)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// ParsePoint reads "lat,lon", e.g. "48.137,11.575"
+func ParsePoint(s string) (Point, error) {
+	latText, lonText, ok := strings.Cut(s, ",")
+	if !ok {
+		return Point{}, fmt.Errorf("%q is not lat,lon", s)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("%q is not lat,lon", s)
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonText), 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("%q is not lat,lon", s)
+	}
+
+	p := Point{Lat: lat, Lon: lon}
+	if !p.Valid() {
+		return Point{}, fmt.Errorf("%q is off the globe", s)
+	}
+	return p, nil
+}
+
+var ErrInvalidLocation = errors.New("latitude and longitude must be given together, in degrees")
+
+// Site is the optional location of a company, store or stock, as models
+// embed it. Coordinates are given, or geocoded from the site's postal code
+// while Geocoded is set.
+type Site struct {
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Geocoded  bool     `gorm:"column:location_geocoded;default:false" json:"location_geocoded"`
+}
+
+// Point returns the site's point, if it has one
+func (s Site) Point() (Point, bool) {
+	return At(s.Latitude, s.Longitude)
+}
+
+// Locate geocodes the site from a postal code, unless coordinates were
+// given for it. Geocoded coordinates follow the postal code as it changes.
+func (s *Site) Locate(country, postalCode string) error {
+	if s.Latitude != nil || s.Longitude != nil {
+		if _, ok := s.Point(); !ok {
+			return ErrInvalidLocation
+		}
+		if !s.Geocoded {
+			return nil
+		}
+	}
+
+	*s = Site{}
+	if p, ok := Geocode(country, postalCode); ok {
+		*s = Site{Latitude: &p.Lat, Longitude: &p.Lon, Geocoded: true}
+	}
+	return nil
+}
+
+// Moved marks the site's coordinates as given when an update changed them
+// from what they were before
+func (s *Site) Moved(before Point) {
+	if after, _ := s.Point(); after != before {
+		s.Geocoded = false
+	}
+}
+
+// ParseOrigin reads the point a search is centred on, given as "lat,lon" or
+// as a postal code and its country. It returns nil when neither is given.
+func ParseOrigin(near, country, postalCode string) (*Point, error) {
+	if near != "" {
+		p, err := ParsePoint(near)
+		if err != nil {
+			return nil, err
+		}
+		return &p, nil
+	}
+	if postalCode == "" {
+		return nil, nil
+	}
+
+	p, ok := Geocode(country, postalCode)
+	if !ok {
+		return nil, fmt.Errorf("postal code %q of %q is not in the geocoding dataset", postalCode, country)
+	}
+	return &p, nil
+}
@@ -0,0 +1,165 @@
+package geo
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDistanceKm(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Point
+		want float64
+	}{
+		{"same point", Point{Lat: 48.137, Lon: 11.575}, Point{Lat: 48.137, Lon: 11.575}, 0},
+		{"London to Paris", Point{Lat: 51.5074, Lon: -0.1278}, Point{Lat: 48.8566, Lon: 2.3522}, 343.557},
+		{"New York to Los Angeles", Point{Lat: 40.7128, Lon: -74.0060}, Point{Lat: 34.0522, Lon: -118.2437}, 3935.752},
+		{"across the antimeridian", Point{Lat: 0, Lon: 179.5}, Point{Lat: 0, Lon: -179.5}, 111.195},
+		{"antipodes", Point{Lat: 0, Lon: 0}, Point{Lat: 0, Lon: 180}, 20015.114},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DistanceKm(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("got %.3f km, want %.3f km", got, tt.want)
+			}
+			if back := DistanceKm(tt.b, tt.a); math.Abs(back-got) > 1e-9 {
+				t.Errorf("distance is not symmetric: %.6f and %.6f", got, back)
+			}
+		})
+	}
+}
+
+func TestParsePoint(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Point
+		wantErr bool
+	}{
+		{input: "48.137,11.575", want: Point{Lat: 48.137, Lon: 11.575}},
+		{input: " -33.87 , 151.21 ", want: Point{Lat: -33.87, Lon: 151.21}},
+		{input: "91,0", wantErr: true},
+		{input: "0,181", wantErr: true},
+		{input: "48.137", wantErr: true},
+		{input: "north,east", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePoint(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+const testPostalCodes = `# test dataset
+country,postal_code,lat,lon
+DE,80331,48.1374,11.5755
+DE,80333,48.1450,11.5650
+DE,10115,52.5323,13.3846
+GB,SW1A,51.5010,-0.1416
+US,10001,40.7506,-73.9972
+`
+
+func TestGeocoder_Geocode(t *testing.T) {
+	g, err := LoadGeocoder(strings.NewReader(testPostalCodes))
+	if err != nil {
+		t.Fatalf("failed to load dataset: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		country    string
+		postalCode string
+		want       Point
+		wantOK     bool
+	}{
+		{"listed code", "DE", "80331", Point{Lat: 48.1374, Lon: 11.5755}, true},
+		{"country by name", "Germany", "10115", Point{Lat: 52.5323, Lon: 13.3846}, true},
+		{"unlisted code falls back to the mean of its prefix", "DE", "80335", Point{Lat: 48.1412, Lon: 11.57025}, true},
+		{"shortest prefix", "de", "1", Point{}, false},
+		{"outward code of a full postcode", "UK", "sw1a 1aa", Point{Lat: 51.5010, Lon: -0.1416}, true},
+		{"ZIP+4", "US", "10001-1234", Point{Lat: 40.7506, Lon: -73.9972}, true},
+		{"no shared prefix", "DE", "99999", Point{}, false},
+		{"country not in the dataset", "FR", "75001", Point{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := g.Geocode(tt.country, tt.postalCode)
+			if ok != tt.wantOK {
+				t.Fatalf("got found %v, want %v", ok, tt.wantOK)
+			}
+			if math.Abs(got.Lat-tt.want.Lat) > 1e-9 || math.Abs(got.Lon-tt.want.Lon) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadGeocoder_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		dataset string
+	}{
+		{"coordinates off the globe", "DE,80331,98.1,11.5\n"},
+		{"coordinates not numbers", "DE,80331,north,east\n"},
+		{"missing column", "DE,80331,48.1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadGeocoder(strings.NewReader(tt.dataset)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestGeocode_Bundled(t *testing.T) {
+	p, ok := Geocode("United Kingdom", "SW1A 2AA")
+	if !ok {
+		t.Fatal("expected the bundled dataset to hold SW1A")
+	}
+	if math.Abs(p.Lat-51.5010) > 1e-9 || math.Abs(p.Lon+0.1416) > 1e-9 {
+		t.Errorf("got %v, want 51.5010,-0.1416", p)
+	}
+}
+
+func TestSite_Locate(t *testing.T) {
+	lat, lon := 1.0, 2.0
+
+	given := Site{Latitude: &lat, Longitude: &lon}
+	if err := given.Locate("GB", "SW1A 1AA"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p, _ := given.Point(); p != (Point{Lat: 1, Lon: 2}) || given.Geocoded {
+		t.Errorf("given coordinates were replaced: %v", p)
+	}
+
+	geocoded := Site{Latitude: &lat, Longitude: &lon, Geocoded: true}
+	if err := geocoded.Locate("GB", "SW1A 1AA"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p, _ := geocoded.Point(); p != (Point{Lat: 51.5010, Lon: -0.1416}) || !geocoded.Geocoded {
+		t.Errorf("geocoded coordinates did not follow the postal code: %v", p)
+	}
+
+	half := Site{Latitude: &lat}
+	if err := half.Locate("GB", "SW1A 1AA"); err != ErrInvalidLocation {
+		t.Errorf("got %v, want ErrInvalidLocation", err)
+	}
+}
@@ -0,0 +1,201 @@
+package geo
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// postalCodes is the bundled dataset: one row per postal code or postal code
+// prefix with the centroid of its area, as country,postal_code,lat,lon. It
+// covers the main industrial areas; GEO_POSTAL_CODES_FILE names a fuller file
+// of the same format, such as one converted from a GeoNames export.
+//
+//go:embed postal_codes.csv
+var postalCodes []byte
+
+// minPostalPrefix is the shortest prefix a postal code is looked up by
+const minPostalPrefix = 2
+
+// Geocoder finds coordinates for postal codes without calling out to a
+// geocoding service
+type Geocoder struct {
+	// Country -> normalized postal code or prefix -> centroid. A prefix not
+	// listed itself is the mean of the codes listed under it.
+	points map[string]map[string]Point
+}
+
+type prefixArea struct {
+	listed *Point
+	sum    Point
+	codes  int
+}
+
+// LoadGeocoder reads a postal code dataset
+func LoadGeocoder(r io.Reader) (*Geocoder, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 4
+
+	areas := make(map[string]map[string]*prefixArea)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid postal code dataset: %w", err)
+		}
+		if line == 1 && record[0] == "country" {
+			continue
+		}
+
+		lat, latErr := strconv.ParseFloat(record[2], 64)
+		lon, lonErr := strconv.ParseFloat(record[3], 64)
+		p := Point{Lat: lat, Lon: lon}
+		if latErr != nil || lonErr != nil || !p.Valid() {
+			return nil, fmt.Errorf("invalid postal code dataset: line %d has no valid coordinates", line)
+		}
+
+		country := CountryCode(record[0])
+		if areas[country] == nil {
+			areas[country] = make(map[string]*prefixArea)
+		}
+		code := normalizePostalCode(record[1])
+		for length := minPostalPrefix; length <= len(code); length++ {
+			area, ok := areas[country][code[:length]]
+			if !ok {
+				area = &prefixArea{}
+				areas[country][code[:length]] = area
+			}
+			area.sum.Lat += p.Lat
+			area.sum.Lon += p.Lon
+			area.codes++
+			if length == len(code) {
+				listed := p
+				area.listed = &listed
+			}
+		}
+	}
+
+	g := &Geocoder{points: make(map[string]map[string]Point, len(areas))}
+	for country, prefixes := range areas {
+		g.points[country] = make(map[string]Point, len(prefixes))
+		for prefix, area := range prefixes {
+			if area.listed != nil {
+				g.points[country][prefix] = *area.listed
+			} else {
+				g.points[country][prefix] = Point{Lat: area.sum.Lat / float64(area.codes), Lon: area.sum.Lon / float64(area.codes)}
+			}
+		}
+	}
+	return g, nil
+}
+
+// Geocode returns the centroid of a postal code's area. A code not in the
+// dataset falls back to its longest prefix shared with listed codes, so
+// "SW1A 1AA" finds "SW1A" and "80335" the area of "80331"; the point is then
+// only as precise as the prefix's area.
+func (g *Geocoder) Geocode(country, postalCode string) (Point, bool) {
+	codes := g.points[CountryCode(country)]
+	code := normalizePostalCode(postalCode)
+	for length := len(code); length >= minPostalPrefix; length-- {
+		if p, ok := codes[code[:length]]; ok {
+			return p, true
+		}
+	}
+	return Point{}, false
+}
+
+var (
+	defaultGeocoder     *Geocoder
+	defaultGeocoderOnce sync.Once
+)
+
+// Geocode finds a postal code in the dataset named by GEO_POSTAL_CODES_FILE,
+// or the bundled one
+func Geocode(country, postalCode string) (Point, bool) {
+	defaultGeocoderOnce.Do(func() {
+		defaultGeocoder = loadDefaultGeocoder()
+	})
+	return defaultGeocoder.Geocode(country, postalCode)
+}
+
+func loadDefaultGeocoder() *Geocoder {
+	if path := os.Getenv("GEO_POSTAL_CODES_FILE"); path != "" {
+		file, err := os.Open(path)
+		if err == nil {
+			defer file.Close()
+			var g *Geocoder
+			if g, err = LoadGeocoder(file); err == nil {
+				return g
+			}
+		}
+		log.Printf("Geocoding with the bundled postal codes: %v", err)
+	}
+
+	g, err := LoadGeocoder(bytes.NewReader(postalCodes))
+	if err != nil {
+		// The bundled dataset is checked in; it only fails if it was broken
+		panic(err)
+	}
+	return g
+}
+
+// normalizePostalCode keeps a postal code's letters and digits, uppercased
+func normalizePostalCode(code string) string {
+	var normalized strings.Builder
+	for _, r := range code {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			normalized.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return normalized.String()
+}
+
+// countryNames are the names addresses commonly give countries in, by
+// ISO 3166-1 alpha-2 code
+var countryNames = map[string][]string{
+	"US": {"united states", "united states of america", "usa"},
+	"CA": {"canada"},
+	"MX": {"mexico"},
+	"GB": {"united kingdom", "uk", "great britain", "england", "scotland", "wales"},
+	"DE": {"germany", "deutschland"},
+	"FR": {"france"},
+	"NL": {"netherlands", "the netherlands", "holland"},
+	"BE": {"belgium"},
+	"IT": {"italy", "italia"},
+	"ES": {"spain", "españa"},
+	"PL": {"poland", "polska"},
+	"AT": {"austria", "österreich"},
+	"CH": {"switzerland", "schweiz"},
+	"SE": {"sweden", "sverige"},
+}
+
+var countryCodes = func() map[string]string {
+	codes := make(map[string]string)
+	for code, names := range countryNames {
+		for _, name := range names {
+			codes[name] = code
+		}
+	}
+	return codes
+}()
+
+// CountryCode returns the ISO 3166-1 alpha-2 code of a country given by code
+// or common name, or the input uppercased when it is neither
+func CountryCode(country string) string {
+	country = strings.TrimSpace(country)
+	if code, ok := countryCodes[strings.ToLower(country)]; ok {
+		return code
+	}
+	return strings.ToUpper(country)
+}
@@ -0,0 +1,71 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minorUnits lists currencies that do not use two decimal places (ISO 4217)
+var minorUnits = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"IDR": 0,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"VND": 0,
+}
+
+// MinorUnits returns the number of decimal places used by a currency
+func MinorUnits(currency string) int {
+	if places, ok := minorUnits[NormalizeCurrency(currency)]; ok {
+		return places
+	}
+	return 2
+}
+
+// NormalizeCurrency upper-cases a currency code and defaults empty codes to USD,
+// matching the column defaults used across services
+func NormalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return "USD"
+	}
+	return currency
+}
+
+// Money is an amount in a specific currency
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+func New(amount Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: NormalizeCurrency(currency)}
+}
+
+// Convert applies an FX rate quoted as units of target per unit of m.Currency
+// and rounds to the target currency's minor units
+func (m Money) Convert(rate Decimal, target string) Money {
+	target = NormalizeCurrency(target)
+	if NormalizeCurrency(m.Currency) == target {
+		return New(m.Amount, target)
+	}
+	return New(m.Amount.Mul(rate).Round(MinorUnits(target)), target)
+}
+
+// Add sums two amounts in the same currency
+func (m Money) Add(o Money) (Money, error) {
+	if NormalizeCurrency(m.Currency) != NormalizeCurrency(o.Currency) {
+		return Money{}, fmt.Errorf("cannot add %s to %s without conversion", o.Currency, m.Currency)
+	}
+	return New(m.Amount.Add(o.Amount), m.Currency), nil
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Amount.StringFixed(MinorUnits(m.Currency)), NormalizeCurrency(m.Currency))
+}
@@ -0,0 +1,234 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// divisionScale is the number of decimal places kept when dividing, which is
+// enough for FX rates between any two currencies
+const divisionScale = 12
+
+// Decimal is an exact decimal number for monetary amounts and FX rates.
+// The zero value is 0. It stores as NUMERIC and marshals to a JSON number.
+type Decimal struct {
+	r *big.Rat
+}
+
+func Zero() Decimal {
+	return Decimal{}
+}
+
+func NewFromInt(v int64) Decimal {
+	return Decimal{r: new(big.Rat).SetInt64(v)}
+}
+
+// NewFromFloat converts a float using its shortest decimal representation, so
+// 0.1 becomes exactly 0.1 rather than the nearest binary fraction
+func NewFromFloat(v float64) Decimal {
+	d, _ := NewFromString(fmt.Sprintf("%v", v))
+	return d
+}
+
+// NewFromString parses a decimal string such as "1234.5600" or "-0.25"
+func NewFromString(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal: empty string")
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+	return Decimal{r: r}, nil
+}
+
+// MustParse is NewFromString for constants known to be valid
+func MustParse(s string) Decimal {
+	d, err := NewFromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) rat() *big.Rat {
+	if d.r == nil {
+		return new(big.Rat)
+	}
+	return d.r
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Add(d.rat(), o.rat())}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Sub(d.rat(), o.rat())}
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Mul(d.rat(), o.rat())}
+}
+
+// Div divides and rounds the result to 12 decimal places. Dividing by zero returns zero.
+func (d Decimal) Div(o Decimal) Decimal {
+	if o.IsZero() {
+		return Decimal{}
+	}
+	return Decimal{r: new(big.Rat).Quo(d.rat(), o.rat())}.Round(divisionScale)
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{r: new(big.Rat).Neg(d.rat())}
+}
+
+// Round rounds half away from zero to the given number of decimal places
+func (d Decimal) Round(places int) Decimal {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	scaled := new(big.Rat).Mul(d.rat(), new(big.Rat).SetInt(scale))
+
+	num := new(big.Int).Abs(scaled.Num())
+	q, rem := new(big.Int).QuoRem(num, scaled.Denom(), new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(scaled.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if scaled.Sign() < 0 {
+		q.Neg(q)
+	}
+	return Decimal{r: new(big.Rat).SetFrac(q, scale)}
+}
+
+func (d Decimal) Cmp(o Decimal) int {
+	return d.rat().Cmp(o.rat())
+}
+
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) GreaterThan(o Decimal) bool {
+	return d.Cmp(o) > 0
+}
+
+func (d Decimal) LessThan(o Decimal) bool {
+	return d.Cmp(o) < 0
+}
+
+func (d Decimal) Sign() int {
+	return d.rat().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Float64 is for display and ratios only; never feed the result back into amounts
+func (d Decimal) Float64() float64 {
+	f, _ := d.rat().Float64()
+	return f
+}
+
+// String returns the exact value without trailing zeros
+func (d Decimal) String() string {
+	r := d.rat()
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	// Values built from decimal strings or rounded by Div always terminate,
+	// so find the number of places needed to print them exactly
+	places := 0
+	for denom := new(big.Int).Set(r.Denom()); denom.Cmp(big.NewInt(1)) != 0 && places < 2*divisionScale; places++ {
+		g := new(big.Int).GCD(nil, nil, denom, big.NewInt(10))
+		if g.Cmp(big.NewInt(1)) == 0 {
+			places = 2 * divisionScale
+			break
+		}
+		denom.Quo(denom, g)
+	}
+	s := r.FloatString(places)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// StringFixed formats with exactly the given number of decimal places
+func (d Decimal) StringFixed(places int) string {
+	return d.Round(places).rat().FloatString(places)
+}
+
+func Sum(values ...Decimal) Decimal {
+	total := Decimal{}
+	for _, v := range values {
+		total = total.Add(v)
+	}
+	return total
+}
+
+func Min(a, b Decimal) Decimal {
+	if a.LessThan(b) {
+		return a
+	}
+	return b
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts both JSON numbers and quoted decimal strings
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		*d = Decimal{}
+		return nil
+	}
+	parsed, err := NewFromString(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d *Decimal) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = Decimal{}
+		return nil
+	case []byte:
+		return d.scanString(string(v))
+	case string:
+		return d.scanString(v)
+	case float64:
+		*d = NewFromFloat(v)
+		return nil
+	case int64:
+		*d = NewFromInt(v)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into money.Decimal", value)
+	}
+}
+
+func (d *Decimal) scanString(s string) error {
+	parsed, err := NewFromString(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// GormDataType maps the type to a NUMERIC column
+func (Decimal) GormDataType() string {
+	return "numeric"
+}
@@ -0,0 +1,158 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecimal_Round(t *testing.T) {
+	tests := []struct {
+		value  string
+		places int
+		want   string
+	}{
+		{"1.005", 2, "1.01"},
+		{"1.004", 2, "1"},
+		{"-1.005", 2, "-1.01"},
+		{"2.5", 0, "3"},
+		{"-2.5", 0, "-3"},
+		{"1234.56789", 3, "1234.568"},
+		{"0.0049", 2, "0"},
+		{"100", 2, "100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := MustParse(tt.value).Round(tt.places).String(); got != tt.want {
+				t.Errorf("Round(%s, %d) = %s, want %s", tt.value, tt.places, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{"adding tenths stays exact", Sum(MustParse("0.1"), MustParse("0.2")), "0.3"},
+		{"floats convert by their shortest form", NewFromFloat(0.1).Add(NewFromFloat(0.2)), "0.3"},
+		{"subtract", MustParse("10").Sub(MustParse("0.01")), "9.99"},
+		{"multiply", MustParse("19.99").Mul(MustParse("3")), "59.97"},
+		{"divide rounds to 12 places", MustParse("1").Div(MustParse("3")), "0.333333333333"},
+		{"divide by zero is zero", MustParse("5").Div(Zero()), "0"},
+		{"negate", MustParse("4.5").Neg(), "-4.5"},
+		{"min", Min(MustParse("2"), MustParse("-2")), "-2"},
+		{"zero value", Decimal{}.Add(Decimal{}), "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Interest-style compounding rounds each period to the currency, so errors
+// must not build up from period to period as they do with floats
+func TestDecimal_Compounding(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal string
+		rate      string
+		periods   int
+		places    int
+		want      string
+	}{
+		{"monthly 1% for a year", "1000.00", "0.01", 12, 2, "1126.84"},
+		{"daily 0.05% for 30 days", "250000.00", "0.0005", 30, 2, "253777.33"},
+		{"yen without minor units", "1000000", "0.015", 4, 0, "1061363"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount := MustParse(tt.principal)
+			factor := MustParse("1").Add(MustParse(tt.rate))
+			for i := 0; i < tt.periods; i++ {
+				amount = amount.Mul(factor).Round(tt.places)
+			}
+			if got := amount.StringFixed(tt.places); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_Convert(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount Money
+		rate   string
+		target string
+		want   string
+	}{
+		{"rounds to the target's minor units", New(MustParse("100"), "usd"), "0.923456", "EUR", "92.35 EUR"},
+		{"zero decimal currency", New(MustParse("100"), "USD"), "149.567", "JPY", "14957 JPY"},
+		{"three decimal currency", New(MustParse("100"), "USD"), "0.30712", "KWD", "30.712 KWD"},
+		{"same currency ignores the rate", New(MustParse("12.345"), "EUR"), "2", "eur", "12.35 EUR"},
+		{"empty currency is USD", New(MustParse("5"), ""), "1", "", "5.00 USD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.Convert(MustParse(tt.rate), tt.target).String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_Add(t *testing.T) {
+	sum, err := New(MustParse("1.10"), "EUR").Add(New(MustParse("2.20"), "eur"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := sum.String(); got != "3.30 EUR" {
+		t.Errorf("got %s, want 3.30 EUR", got)
+	}
+
+	if _, err := New(MustParse("1"), "EUR").Add(New(MustParse("1"), "USD")); err == nil {
+		t.Error("expected an error adding different currencies")
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`12.50`, "12.5"},
+		{`"0.1"`, "0.1"},
+		{`null`, "0"},
+		{`-3`, "-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var d Decimal
+			if err := json.Unmarshal([]byte(tt.input), &d); err != nil {
+				t.Fatalf("unmarshal failed: %v", err)
+			}
+			data, err := json.Marshal(d)
+			if err != nil {
+				t.Fatalf("marshal failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+		})
+	}
+
+	var d Decimal
+	if err := json.Unmarshal([]byte(`"abc"`), &d); err == nil {
+		t.Error("expected an error for a non-numeric string")
+	}
+}
@@ -0,0 +1,137 @@
+package tax
+
+import (
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/shared/money"
+)
+
+// Rule is a tax rate for supplies into a destination country. OriginCountry and
+// Category narrow the rule; empty values match anything.
+type Rule struct {
+	DestinationCountry string        `json:"destination_country"`
+	OriginCountry      string        `json:"origin_country,omitempty"`
+	Category           string        `json:"category,omitempty"`
+	TaxType            string        `json:"tax_type"`
+	Rate               money.Decimal `json:"rate"` // percent
+	ValidFrom          time.Time     `json:"valid_from"`
+	ValidTo            *time.Time    `json:"valid_to,omitempty"`
+}
+
+// RuleSource supplies the rules configured for a destination country
+type RuleSource interface {
+	RulesFor(destinationCountry string, on time.Time) ([]Rule, error)
+}
+
+// RuleEngine is the built-in Engine. Domestic and cross-border B2C supplies are
+// taxed at the destination country's rate; cross-border supplies to a
+// tax-registered buyer are reverse charged, so the buyer self-accounts for the tax.
+type RuleEngine struct {
+	source RuleSource
+}
+
+func NewRuleEngine(source RuleSource) *RuleEngine {
+	return &RuleEngine{source: source}
+}
+
+func (e *RuleEngine) Calculate(req Request) (*Result, error) {
+	origin := normalizeCountry(req.Seller.Country)
+	destination := normalizeCountry(req.Buyer.Country)
+	on := req.Date
+	if on.IsZero() {
+		on = time.Now()
+	}
+
+	rules, err := e.source.RulesFor(destination, on)
+	if err != nil {
+		return nil, err
+	}
+
+	crossBorder := origin != destination
+	reverseCharge := crossBorder && req.Buyer.IsBusiness() && req.Seller.IsBusiness()
+	places := money.MinorUnits(req.Currency)
+
+	result := &Result{
+		Currency: money.NormalizeCurrency(req.Currency),
+		Lines:    make([]LineTax, 0, len(req.Lines)),
+	}
+	for _, line := range req.Lines {
+		lineTax := LineTax{
+			LineID:        line.LineID,
+			Category:      line.Category,
+			Jurisdiction:  destination,
+			TaxableAmount: line.Amount,
+		}
+
+		rule := matchRule(rules, origin, line.Category, on)
+		exemption := exemptionFor(req.Exemptions, destination, line.Category, on)
+		switch {
+		case rule == nil:
+			lineTax.Treatment = TreatmentNoRule
+		case exemption != nil:
+			lineTax.TaxType = rule.TaxType
+			lineTax.Treatment = TreatmentExempt
+			lineTax.ExemptionCertificate = exemption.CertificateNumber
+		case reverseCharge:
+			lineTax.TaxType = rule.TaxType
+			lineTax.Treatment = TreatmentReverseCharge
+		case rule.Rate.IsZero():
+			lineTax.TaxType = rule.TaxType
+			lineTax.Treatment = TreatmentZeroRated
+		default:
+			lineTax.TaxType = rule.TaxType
+			lineTax.Treatment = TreatmentStandard
+			lineTax.Rate = rule.Rate
+			lineTax.TaxAmount = line.Amount.Mul(rule.Rate).Div(money.NewFromInt(100)).Round(places)
+		}
+
+		result.TotalTax = result.TotalTax.Add(lineTax.TaxAmount)
+		result.Lines = append(result.Lines, lineTax)
+	}
+	return result, nil
+}
+
+// matchRule picks the most specific rule in force. A category match outranks
+// an origin match, and both outrank a generic rule for the destination.
+func matchRule(rules []Rule, origin, category string, on time.Time) *Rule {
+	var best *Rule
+	bestScore := -1
+	for i, r := range rules {
+		if on.Before(r.ValidFrom) || (r.ValidTo != nil && on.After(*r.ValidTo)) {
+			continue
+		}
+
+		score := 0
+		if r.OriginCountry != "" {
+			if normalizeCountry(r.OriginCountry) != origin {
+				continue
+			}
+			score += 1
+		}
+		if r.Category != "" {
+			if !strings.EqualFold(r.Category, category) {
+				continue
+			}
+			score += 2
+		}
+		if score > bestScore {
+			best = &rules[i]
+			bestScore = score
+		}
+	}
+	return best
+}
+
+func exemptionFor(exemptions []Exemption, country, category string, on time.Time) *Exemption {
+	for i, e := range exemptions {
+		if e.Covers(country, category, on) {
+			return &exemptions[i]
+		}
+	}
+	return nil
+}
+
+func normalizeCountry(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
@@ -0,0 +1,222 @@
+package tax
+
+import (
+	"testing"
+	"time"
+
+	"github.com/b2b-platform/shared/money"
+)
+
+// staticRules serves the same rules for every destination they name
+type staticRules []Rule
+
+func (s staticRules) RulesFor(destinationCountry string, on time.Time) ([]Rule, error) {
+	rules := []Rule{}
+	for _, r := range s {
+		if r.DestinationCountry == destinationCountry {
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+var (
+	jan2024 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jul2024 = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func testRules() staticRules {
+	return staticRules{
+		{DestinationCountry: "DE", TaxType: TypeVAT, Rate: money.MustParse("19"), ValidFrom: jan2024},
+		{DestinationCountry: "DE", Category: "books", TaxType: TypeVAT, Rate: money.MustParse("7"), ValidFrom: jan2024},
+		{DestinationCountry: "DE", OriginCountry: "CH", TaxType: TypeVAT, Rate: money.MustParse("8.1"), ValidFrom: jan2024},
+		{DestinationCountry: "DE", Category: "medical", TaxType: TypeVAT, Rate: money.Zero(), ValidFrom: jan2024},
+		{DestinationCountry: "AU", TaxType: TypeGST, Rate: money.MustParse("10"), ValidFrom: jul2024},
+	}
+}
+
+func TestRuleEngine_Calculate(t *testing.T) {
+	certificate := Exemption{CertificateNumber: "EX-1", Country: "DE", Categories: []string{"tools"}, ValidFrom: jan2024}
+
+	tests := []struct {
+		name          string
+		seller        Party
+		buyer         Party
+		date          time.Time
+		category      string
+		amount        string
+		exemptions    []Exemption
+		wantTreatment string
+		wantRate      string
+		wantTax       string
+	}{
+		{
+			name:          "domestic standard rate",
+			seller:        Party{Country: "DE", TaxID: "DE1"},
+			buyer:         Party{Country: "de", TaxID: "DE2"},
+			category:      "tools",
+			amount:        "100.00",
+			wantTreatment: TreatmentStandard,
+			wantRate:      "19",
+			wantTax:       "19",
+		},
+		{
+			name:          "category rule outranks the generic rule",
+			seller:        Party{Country: "DE"},
+			buyer:         Party{Country: "DE"},
+			category:      "Books",
+			amount:        "10.00",
+			wantTreatment: TreatmentStandard,
+			wantRate:      "7",
+			wantTax:       "0.7",
+		},
+		{
+			name:          "origin rule for a cross-border B2C supply",
+			seller:        Party{Country: "CH", TaxID: "CHE1"},
+			buyer:         Party{Country: "DE"},
+			category:      "tools",
+			amount:        "100.00",
+			wantTreatment: TreatmentStandard,
+			wantRate:      "8.1",
+			wantTax:       "8.1",
+		},
+		{
+			name:          "cross-border B2B is reverse charged",
+			seller:        Party{Country: "FR", TaxID: "FR1"},
+			buyer:         Party{Country: "DE", TaxID: "DE2"},
+			category:      "tools",
+			amount:        "100.00",
+			wantTreatment: TreatmentReverseCharge,
+			wantRate:      "0",
+			wantTax:       "0",
+		},
+		{
+			name:          "zero rated category",
+			seller:        Party{Country: "DE"},
+			buyer:         Party{Country: "DE"},
+			category:      "medical",
+			amount:        "100.00",
+			wantTreatment: TreatmentZeroRated,
+			wantRate:      "0",
+			wantTax:       "0",
+		},
+		{
+			name:          "exemption certificate for the category",
+			seller:        Party{Country: "DE"},
+			buyer:         Party{Country: "DE"},
+			category:      "tools",
+			amount:        "100.00",
+			exemptions:    []Exemption{certificate},
+			wantTreatment: TreatmentExempt,
+			wantRate:      "0",
+			wantTax:       "0",
+		},
+		{
+			name:          "exemption certificate for another category",
+			seller:        Party{Country: "DE"},
+			buyer:         Party{Country: "DE"},
+			category:      "books",
+			amount:        "100.00",
+			exemptions:    []Exemption{certificate},
+			wantTreatment: TreatmentStandard,
+			wantRate:      "7",
+			wantTax:       "7",
+		},
+		{
+			name:          "no rule in force yet",
+			seller:        Party{Country: "AU"},
+			buyer:         Party{Country: "AU"},
+			date:          time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			category:      "tools",
+			amount:        "100.00",
+			wantTreatment: TreatmentNoRule,
+			wantRate:      "0",
+			wantTax:       "0",
+		},
+		{
+			name:          "tax rounds half up to the currency",
+			seller:        Party{Country: "DE"},
+			buyer:         Party{Country: "DE"},
+			category:      "tools",
+			amount:        "0.50",
+			wantTreatment: TreatmentStandard,
+			wantRate:      "19",
+			wantTax:       "0.1",
+		},
+	}
+
+	engine := NewRuleEngine(testRules())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date := tt.date
+			if date.IsZero() {
+				date = time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+			}
+
+			result, err := engine.Calculate(Request{
+				Seller:     tt.seller,
+				Buyer:      tt.buyer,
+				Currency:   "EUR",
+				Date:       date,
+				Lines:      []Line{{LineID: "1", Category: tt.category, Amount: money.MustParse(tt.amount)}},
+				Exemptions: tt.exemptions,
+			})
+			if err != nil {
+				t.Fatalf("calculate failed: %v", err)
+			}
+
+			line := result.Lines[0]
+			if line.Treatment != tt.wantTreatment {
+				t.Errorf("got treatment %s, want %s", line.Treatment, tt.wantTreatment)
+			}
+			if got := line.Rate.String(); got != tt.wantRate {
+				t.Errorf("got rate %s, want %s", got, tt.wantRate)
+			}
+			if got := line.TaxAmount.String(); got != tt.wantTax {
+				t.Errorf("got tax %s, want %s", got, tt.wantTax)
+			}
+			if !result.TotalTax.Equal(line.TaxAmount) {
+				t.Errorf("got total %s, want %s", result.TotalTax, line.TaxAmount)
+			}
+		})
+	}
+}
+
+// Each line's tax is rounded on its own and the total is the sum of the
+// rounded lines, so invoices add up line by line
+func TestRuleEngine_TotalOfRoundedLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		currency  string
+		amounts   []string
+		wantTotal string
+	}{
+		{"rounding accumulates across lines", "EUR", []string{"0.50", "0.50", "0.50"}, "0.3"},
+		{"exact lines", "EUR", []string{"100.00", "50.00"}, "28.5"},
+		{"zero decimal currency", "JPY", []string{"105", "105"}, "40"},
+	}
+
+	engine := NewRuleEngine(testRules())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := make([]Line, len(tt.amounts))
+			for i, amount := range tt.amounts {
+				lines[i] = Line{Category: "tools", Amount: money.MustParse(amount)}
+			}
+
+			result, err := engine.Calculate(Request{
+				Seller:   Party{Country: "DE"},
+				Buyer:    Party{Country: "DE"},
+				Currency: tt.currency,
+				Date:     jul2024,
+				Lines:    lines,
+			})
+			if err != nil {
+				t.Fatalf("calculate failed: %v", err)
+			}
+			if got := result.TotalTax.String(); got != tt.wantTotal {
+				t.Errorf("got total %s, want %s", got, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package tax
+
+import (
+	"strings"
+	"time"
+
+	"github.com/b2b-platform/shared/money"
+)
+
+// Tax types
+const (
+	TypeVAT      = "VAT"
+	TypeGST      = "GST"
+	TypeSalesTax = "SALES_TAX"
+)
+
+// Treatments explain how a line's tax was determined
+const (
+	TreatmentStandard      = "standard"
+	TreatmentReverseCharge = "reverse_charge"
+	TreatmentExempt        = "exempt"
+	TreatmentZeroRated     = "zero_rated"
+	TreatmentNoRule        = "no_rule"
+)
+
+// Engine calculates tax for a document. Implementations can be swapped for an
+// external tax provider without changing the callers.
+type Engine interface {
+	Calculate(req Request) (*Result, error)
+}
+
+// Party is the buyer or seller on a document
+type Party struct {
+	Country string `json:"country"`
+	TaxID   string `json:"tax_id,omitempty"` // VAT/GST registration number
+}
+
+// IsBusiness reports whether the party is tax-registered, which is what makes a
+// cross-border supply B2B for reverse-charge purposes
+func (p Party) IsBusiness() bool {
+	return strings.TrimSpace(p.TaxID) != ""
+}
+
+// Exemption is a buyer's tax-exempt certificate
+type Exemption struct {
+	CertificateNumber string     `json:"certificate_number"`
+	Country           string     `json:"country"`
+	Categories        []string   `json:"categories,omitempty"` // empty covers every category
+	ValidFrom         time.Time  `json:"valid_from"`
+	ValidTo           *time.Time `json:"valid_to,omitempty"`
+}
+
+// Covers reports whether the certificate applies to a category in a country on a date
+func (e Exemption) Covers(country, category string, on time.Time) bool {
+	if !strings.EqualFold(e.Country, country) {
+		return false
+	}
+	if on.Before(e.ValidFrom) || (e.ValidTo != nil && on.After(*e.ValidTo)) {
+		return false
+	}
+	if len(e.Categories) == 0 {
+		return true
+	}
+	for _, c := range e.Categories {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
+
+type Line struct {
+	LineID   string        `json:"line_id"`
+	Category string        `json:"category"`
+	Amount   money.Decimal `json:"amount"` // net amount of the line
+}
+
+type Request struct {
+	Seller     Party       `json:"seller"`
+	Buyer      Party       `json:"buyer"`
+	Currency   string      `json:"currency"`
+	Date       time.Time   `json:"date"`
+	Lines      []Line      `json:"lines"`
+	Exemptions []Exemption `json:"exemptions,omitempty"`
+}
+
+// LineTax is the tax computed for one document line
+type LineTax struct {
+	LineID               string        `json:"line_id"`
+	Category             string        `json:"category"`
+	TaxType              string        `json:"tax_type"`
+	Jurisdiction         string        `json:"jurisdiction"`
+	Rate                 money.Decimal `json:"rate"` // percent
+	TaxableAmount        money.Decimal `json:"taxable_amount"`
+	TaxAmount            money.Decimal `json:"tax_amount"`
+	Treatment            string        `json:"treatment"`
+	ExemptionCertificate string        `json:"exemption_certificate,omitempty"`
+}
+
+type Result struct {
+	Currency string        `json:"currency"`
+	Lines    []LineTax     `json:"lines"`
+	TotalTax money.Decimal `json:"total_tax"`
+}
@@ -0,0 +1,105 @@
+package units
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unit is a unit of measure within a dimension. A value in the unit is
+// converted to the dimension's base unit as value*Factor + Offset.
+type Unit struct {
+	Dimension string
+	Factor    float64
+	Offset    float64
+}
+
+// units are keyed by lower-case symbol or alias
+var units = map[string]Unit{
+	// length, base metre
+	"mm": {"length", 0.001, 0}, "cm": {"length", 0.01, 0}, "m": {"length", 1, 0}, "km": {"length", 1000, 0},
+	"in": {"length", 0.0254, 0}, "inch": {"length", 0.0254, 0}, "inches": {"length", 0.0254, 0}, "\"": {"length", 0.0254, 0},
+	"ft": {"length", 0.3048, 0}, "feet": {"length", 0.3048, 0}, "'": {"length", 0.3048, 0},
+
+	// mass, base kilogram
+	"mg": {"mass", 0.000001, 0}, "g": {"mass", 0.001, 0}, "kg": {"mass", 1, 0}, "t": {"mass", 1000, 0},
+	"lb": {"mass", 0.45359237, 0}, "lbs": {"mass", 0.45359237, 0}, "oz": {"mass", 0.028349523125, 0},
+
+	// volume, base litre
+	"ml": {"volume", 0.001, 0}, "l": {"volume", 1, 0}, "gal": {"volume", 3.785411784, 0}, "qt": {"volume", 0.946352946, 0},
+
+	// pressure, base pascal
+	"pa": {"pressure", 1, 0}, "kpa": {"pressure", 1000, 0}, "mpa": {"pressure", 1000000, 0},
+	"bar": {"pressure", 100000, 0}, "psi": {"pressure", 6894.757293168, 0},
+
+	// temperature, base kelvin
+	"k": {"temperature", 1, 0}, "c": {"temperature", 1, 273.15}, "°c": {"temperature", 1, 273.15},
+	"f": {"temperature", 5.0 / 9.0, 273.15 - 32*5.0/9.0}, "°f": {"temperature", 5.0 / 9.0, 273.15 - 32*5.0/9.0},
+
+	// electrical
+	"mv": {"voltage", 0.001, 0}, "v": {"voltage", 1, 0}, "kv": {"voltage", 1000, 0},
+	"ma": {"current", 0.001, 0}, "a": {"current", 1, 0},
+	"w": {"power", 1, 0}, "kw": {"power", 1000, 0}, "hp": {"power", 745.6998715822702, 0},
+
+	// torque, base newton-metre
+	"nm": {"torque", 1, 0}, "n·m": {"torque", 1, 0}, "lbft": {"torque", 1.3558179483314, 0}, "lb-ft": {"torque", 1.3558179483314, 0},
+
+	// rotational speed
+	"rpm": {"rotation", 1, 0},
+}
+
+// Lookup finds a unit by symbol or alias, ignoring case
+func Lookup(symbol string) (Unit, bool) {
+	u, ok := units[strings.ToLower(strings.TrimSpace(symbol))]
+	return u, ok
+}
+
+// Convert converts a value between two units of the same dimension
+func Convert(value float64, from, to string) (float64, error) {
+	if strings.EqualFold(from, to) {
+		return value, nil
+	}
+
+	fromUnit, ok := Lookup(from)
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", from)
+	}
+	toUnit, ok := Lookup(to)
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", to)
+	}
+	if fromUnit.Dimension != toUnit.Dimension {
+		return 0, fmt.Errorf("cannot convert %s (%s) to %s (%s)", from, fromUnit.Dimension, to, toUnit.Dimension)
+	}
+
+	base := value*fromUnit.Factor + fromUnit.Offset
+	return (base - toUnit.Offset) / toUnit.Factor, nil
+}
+
+// ToBase converts a value to the base unit of its unit's dimension, e.g.
+// millimetres to metres, so values given in different units compare directly
+func ToBase(value float64, symbol string) (float64, string, error) {
+	u, ok := Lookup(symbol)
+	if !ok {
+		return 0, "", fmt.Errorf("unknown unit %q", symbol)
+	}
+	return value*u.Factor + u.Offset, u.Dimension, nil
+}
+
+var quantityPattern = regexp.MustCompile(`^([-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?)\s*(.*)$`)
+
+// ParseQuantity splits "12.5 mm", "0.5in" or "24" into a number and the
+// unit symbol following it, if any
+func ParseQuantity(s string) (float64, string, error) {
+	m := quantityPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, "", fmt.Errorf("%q is not a number", s)
+	}
+
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("%q is not a number", s)
+	}
+	return v, strings.TrimSpace(m[2]), nil
+}
@@ -0,0 +1,123 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value   float64
+		from    string
+		to      string
+		want    float64
+		wantErr bool
+	}{
+		{value: 1, from: "in", to: "mm", want: 25.4},
+		{value: 12, from: "inches", to: "ft", want: 1},
+		{value: 1, from: "KG", to: "lb", want: 2.2046226218},
+		{value: 1, from: "bar", to: "psi", want: 14.5037737730},
+		{value: 1, from: "hp", to: "kW", want: 0.7456998716},
+		{value: 100, from: "Nm", to: "lb-ft", want: 73.7562149277},
+		{value: 5, from: "mm", to: "MM", want: 5},
+
+		// Temperatures convert with an offset as well as a factor
+		{value: 0, from: "°C", to: "K", want: 273.15},
+		{value: 212, from: "°F", to: "°C", want: 100},
+		{value: 32, from: "F", to: "C", want: 0},
+		{value: -40, from: "c", to: "f", want: -40},
+		{value: 0, from: "K", to: "°F", want: -459.67},
+
+		{value: 1, from: "mm", to: "kg", wantErr: true},
+		{value: 1, from: "furlong", to: "m", wantErr: true},
+		{value: 1, from: "m", to: "furlong", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			got, err := Convert(tt.value, tt.from, tt.to)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Convert(%v, %s, %s) = %v, want %v", tt.value, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToBase(t *testing.T) {
+	tests := []struct {
+		value         float64
+		unit          string
+		wantValue     float64
+		wantDimension string
+	}{
+		{22, "mm", 0.022, "length"},
+		{1, "\"", 0.0254, "length"},
+		{500, "g", 0.5, "mass"},
+		{25, "°C", 298.15, "temperature"},
+		{77, "°F", 298.15, "temperature"},
+		{24, "V", 24, "voltage"},
+		{2, "MPa", 2000000, "pressure"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			got, dimension, err := ToBase(tt.value, tt.unit)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got-tt.wantValue) > 1e-9 || dimension != tt.wantDimension {
+				t.Errorf("ToBase(%v, %s) = %v %s, want %v %s", tt.value, tt.unit, got, dimension, tt.wantValue, tt.wantDimension)
+			}
+		})
+	}
+
+	if _, _, err := ToBase(1, "parsec"); err == nil {
+		t.Error("expected an error for an unknown unit")
+	}
+}
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantValue float64
+		wantUnit  string
+		wantErr   bool
+	}{
+		{input: "12.5 mm", wantValue: 12.5, wantUnit: "mm"},
+		{input: "0.5in", wantValue: 0.5, wantUnit: "in"},
+		{input: "24", wantValue: 24},
+		{input: " -40 °F ", wantValue: -40, wantUnit: "°F"},
+		{input: ".75 l", wantValue: 0.75, wantUnit: "l"},
+		{input: "1e3 Pa", wantValue: 1000, wantUnit: "Pa"},
+		{input: "3/4", wantValue: 3, wantUnit: "/4"},
+		{input: "M8", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			value, unit, err := ParseQuantity(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v %q", value, unit)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value != tt.wantValue || unit != tt.wantUnit {
+				t.Errorf("ParseQuantity(%q) = %v %q, want %v %q", tt.input, value, unit, tt.wantValue, tt.wantUnit)
+			}
+		})
+	}
+}